@@ -36,9 +36,18 @@ var (
 	NodeClaimCRD []byte
 	//go:embed crds/karpenter.sh_nodeoverlays.yaml
 	NodeOverlayCRD []byte
-	CRDs           = []*apiextensionsv1.CustomResourceDefinition{
+	//go:embed crds/karpenter.sh_disruptioncommands.yaml
+	DisruptionCommandCRD []byte
+	//go:embed crds/karpenter.sh_disruptiondecisions.yaml
+	DisruptionDecisionCRD []byte
+	//go:embed crds/karpenter.sh_maintenancewindows.yaml
+	MaintenanceWindowCRD []byte
+	CRDs                 = []*apiextensionsv1.CustomResourceDefinition{
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodePoolCRD),
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodeClaimCRD),
 		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](NodeOverlayCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](DisruptionCommandCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](DisruptionDecisionCRD),
+		object.Unmarshal[apiextensionsv1.CustomResourceDefinition](MaintenanceWindowCRD),
 	}
 )
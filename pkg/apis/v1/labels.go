@@ -49,13 +49,48 @@ const (
 // Karpenter specific annotations
 const (
 	DoNotDisruptAnnotationKey                  = apis.Group + "/do-not-disrupt"
+	DoNotDisruptReasonsAnnotationKey           = apis.Group + "/do-not-disrupt-reasons"
 	ProviderCompatibilityAnnotationKey         = apis.CompatibilityGroup + "/provider"
 	NodePoolHashAnnotationKey                  = apis.Group + "/nodepool-hash"
 	NodePoolHashVersionAnnotationKey           = apis.Group + "/nodepool-hash-version"
 	NodeClaimTerminationTimestampAnnotationKey = apis.Group + "/nodeclaim-termination-timestamp"
 	NodeClaimMinValuesRelaxedAnnotationKey     = apis.Group + "/nodeclaim-min-values-relaxed"
+	RightsizingRecommendationAnnotationKey     = apis.Group + "/rightsizing-recommendation"
+	RightsizingEstimatedSavingsAnnotationKey   = apis.Group + "/rightsizing-estimated-savings"
+	ProvisioningFallbackNodePoolAnnotationKey  = apis.Group + "/provisioning-fallback-nodepool"
+	DriftApprovedAnnotationKey                 = apis.Group + "/drift-approved"
+	DriftProtectedAnnotationKey                = apis.Group + "/drift-protected"
+	DisruptAnnotationKey                       = apis.Group + "/disrupt"
+	InteractiveSessionUntilAnnotationKey       = apis.Group + "/interactive-session-until"
+	DefaultPodRequestsAnnotationKey            = apis.Group + "/default-pod-requests"
+	NodePoolRolloutTriggerAnnotationKey        = apis.Group + "/nodepool-rollout-trigger"
+	DriftCheckRequestedAnnotationKey           = apis.Group + "/drift-check-requested"
+	NodeClaimLifecycleStateAnnotationKey       = apis.Group + "/nodeclaim-lifecycle-state"
+	ExpirationReplacementAnnotationKey         = apis.Group + "/expiration-replacement"
+	NodeClaimLaunchPriceAnnotationKey          = apis.Group + "/nodeclaim-launch-price"
+	NodeClaimNominatedPodsAnnotationKey        = apis.Group + "/nominated-pods"
+	NodeClaimReplacesAnnotationKey             = apis.Group + "/replaces"
+	NodeClaimReplacedByAnnotationKey           = apis.Group + "/replaced-by"
+	DisruptionRetryRequestedAnnotationKey      = apis.Group + "/disruption-retry-requested"
+	ForcedTerminationGracePeriodAnnotationKey  = apis.Group + "/forced-termination-grace-period"
+	NodeAdoptAnnotationKey                     = apis.Group + "/adopt"
+	NodeClaimAdoptedInstanceAnnotationKey      = apis.Group + "/adopted-instance"
+	NodeClaimRebootedNodePoolHashAnnotationKey = apis.Group + "/rebooted-nodepool-hash"
 )
 
+// PreDrainHookAnnotationPrefix is the prefix of the annotations that register pre-drain hooks on a NodeClaim, e.g.
+// pre-drain.karpenter.sh/service-discovery. Termination doesn't evict the pods of the NodeClaim's Node until the
+// controllers that own the hooks remove their annotations, or the pre-drain hook timeout elapses.
+const PreDrainHookAnnotationPrefix = "pre-drain." + apis.Group + "/"
+
+// TerminationHoldFinalizerPrefix is the prefix of the finalizers that external controllers add to a NodeClaim to hold
+// its termination, e.g. termination-hold.karpenter.sh/backup until a backup of the node's local storage completes.
+// Termination doesn't terminate the instance of a drained NodeClaim until the controllers that own the holds remove
+// their finalizers, or the termination hold timeout elapses. Karpenter then removes the holds that timed out, so that
+// the NodeClaim can be deleted. The TerminationHoldsReleased status condition of the NodeClaim surfaces the state of
+// its holds.
+const TerminationHoldFinalizerPrefix = "termination-hold." + apis.Group + "/"
+
 // Karpenter specific finalizers
 const (
 	TerminationFinalizer = apis.Group + "/termination"
@@ -17,6 +17,10 @@ limitations under the License.
 package v1
 
 import (
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -64,6 +68,13 @@ type NodeClaimSpec struct {
 	// +kubebuilder:validation:Type="string"
 	// +optional
 	TerminationGracePeriod *metav1.Duration `json:"terminationGracePeriod,omitempty"`
+	// TerminationGracePeriodOverrides override the TerminationGracePeriod for nodes that are disrupted for specific reasons,
+	// e.g. to allow drifted nodes longer to drain than expired nodes. Never removes the TerminationGracePeriod for the reason.
+	// +listType=map
+	// +listMapKey=reason
+	// +kubebuilder:validation:MaxItems=7
+	// +optional
+	TerminationGracePeriodOverrides []TerminationGracePeriodOverride `json:"terminationGracePeriodOverrides,omitempty"`
 	// ExpireAfter is the duration the controller will wait
 	// before terminating a node, measured from when the node is created. This
 	// is useful to implement features like eventually consistent node upgrade,
@@ -74,6 +85,108 @@ type NodeClaimSpec struct {
 	// +kubebuilder:validation:Schemaless
 	// +optional
 	ExpireAfter NillableDuration `json:"expireAfter,omitempty"`
+	// ExpireAfterCost is the cumulative cost, in the currency units of the cloudprovider's hourly offering prices, that a
+	// node can accrue before it's terminated. The cost is computed from the price of the offering that the node was
+	// launched with. Nodes expire at whichever of ExpireAfter and ExpireAfterCost is reached first.
+	// +kubebuilder:validation:Pattern=`^\d+(\.\d+)?$`
+	// +optional
+	ExpireAfterCost *string `json:"expireAfterCost,omitempty"`
+	// RegistrationTimeout is how long the controller waits for the node of a launched NodeClaim to register before it
+	// deletes the NodeClaim so that a replacement can be launched. Increase it for nodes that legitimately take longer
+	// to join the cluster, e.g. GPU nodes with large images. Defaults to 15m.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	RegistrationTimeout *metav1.Duration `json:"registrationTimeout,omitempty" hash:"ignore"`
+	// InitializationTimeout is how long the controller waits for a registered node to initialize, i.e. to become ready,
+	// register its extended resources and have its startup taints removed, before it deletes the NodeClaim so that a
+	// replacement can be launched. If left undefined, the controller waits indefinitely.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	InitializationTimeout *metav1.Duration `json:"initializationTimeout,omitempty" hash:"ignore"`
+	// Kubelet defines args to be used when configuring kubelet on provisioned nodes. It's passed to the cloud provider
+	// when the NodeClaim is launched, and NodeClaims launched with a different configuration are drifted.
+	// +optional
+	Kubelet *KubeletConfiguration `json:"kubelet,omitempty"`
+}
+
+// TerminationGracePeriodFor returns the TerminationGracePeriod for the NodeClaim when it's disrupted for the reason,
+// taking into account the NodeClaim's TerminationGracePeriodOverrides
+func (in *NodeClaim) TerminationGracePeriodFor(reason string) *metav1.Duration {
+	override, ok := lo.Find(in.Spec.TerminationGracePeriodOverrides, func(o TerminationGracePeriodOverride) bool { return o.Reason == reason })
+	if !ok {
+		return in.Spec.TerminationGracePeriod
+	}
+	if override.TerminationGracePeriod.Duration == nil {
+		return nil
+	}
+	return &metav1.Duration{Duration: *override.TerminationGracePeriod.Duration}
+}
+
+// TerminationHolds returns the sorted names of the termination holds that external controllers placed on the NodeClaim
+// with TerminationHoldFinalizerPrefix finalizers
+func (in *NodeClaim) TerminationHolds() []string {
+	var holds []string
+	for _, f := range in.Finalizers {
+		if name, ok := strings.CutPrefix(f, TerminationHoldFinalizerPrefix); ok && name != "" {
+			holds = append(holds, name)
+		}
+	}
+	sort.Strings(holds)
+	return holds
+}
+
+// TerminationGracePeriodOverride overrides the TerminationGracePeriod for nodes disrupted for a reason
+type TerminationGracePeriodOverride struct {
+	// Reason is the disruption reason that the override applies to. Unlike disruption budgets, overrides can also
+	// apply to Expired and Interrupted nodes.
+	// +kubebuilder:validation:Enum:={Underutilized,Empty,Drifted,Requested,Unhealthy,Expired,Interrupted}
+	// +required
+	Reason string `json:"reason"`
+	// TerminationGracePeriod is the TerminationGracePeriod for nodes disrupted for the reason. If Never, the controller
+	// will wait indefinitely for pods to be drained.
+	// +kubebuilder:validation:Pattern=`^(([0-9]+(s|m|h))+|Never)$`
+	// +kubebuilder:validation:Type="string"
+	// +kubebuilder:validation:Schemaless
+	// +required
+	TerminationGracePeriod NillableDuration `json:"terminationGracePeriod"`
+}
+
+// KubeletConfiguration defines args to be used when configuring kubelet on provisioned nodes.
+// They are a subset of the upstream types, recognizing not all options may be supported.
+// Wherever possible, the types and names should reflect the upstream kubelet types.
+// https://pkg.go.dev/k8s.io/kubelet/config/v1beta1#KubeletConfiguration
+// +kubebuilder:validation:XValidation:message="evictionSoft key does not have a matching evictionSoftGracePeriod",rule="has(self.evictionSoft) ? (has(self.evictionSoftGracePeriod) && self.evictionSoft.all(e, e in self.evictionSoftGracePeriod)) : true"
+// +kubebuilder:validation:XValidation:message="evictionSoftGracePeriod key does not have a matching evictionSoft",rule="has(self.evictionSoftGracePeriod) ? (has(self.evictionSoft) && self.evictionSoftGracePeriod.all(e, e in self.evictionSoft)) : true"
+type KubeletConfiguration struct {
+	// MaxPods is an override for the maximum number of pods that can run on
+	// a worker node instance.
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	MaxPods *int32 `json:"maxPods,omitempty"`
+	// SystemReserved contains resources reserved for OS system daemons and kernel memory.
+	// +kubebuilder:validation:XValidation:message="valid keys for systemReserved are ['cpu','memory','ephemeral-storage','pid']",rule="self.all(x, x=='cpu' || x=='memory' || x=='ephemeral-storage' || x=='pid')"
+	// +kubebuilder:validation:XValidation:message="systemReserved value cannot be a negative resource quantity",rule="self.all(x, !self[x].startsWith('-'))"
+	// +optional
+	SystemReserved map[string]string `json:"systemReserved,omitempty"`
+	// KubeReserved contains resources reserved for Kubernetes system components.
+	// +kubebuilder:validation:XValidation:message="valid keys for kubeReserved are ['cpu','memory','ephemeral-storage','pid']",rule="self.all(x, x=='cpu' || x=='memory' || x=='ephemeral-storage' || x=='pid')"
+	// +kubebuilder:validation:XValidation:message="kubeReserved value cannot be a negative resource quantity",rule="self.all(x, !self[x].startsWith('-'))"
+	// +optional
+	KubeReserved map[string]string `json:"kubeReserved,omitempty"`
+	// EvictionHard is the map of signal names to quantities that define hard eviction thresholds
+	// +kubebuilder:validation:XValidation:message="valid keys for evictionHard are ['memory.available','nodefs.available','nodefs.inodesFree','imagefs.available','imagefs.inodesFree','pid.available']",rule="self.all(x, x in ['memory.available','nodefs.available','nodefs.inodesFree','imagefs.available','imagefs.inodesFree','pid.available'])"
+	// +optional
+	EvictionHard map[string]string `json:"evictionHard,omitempty"`
+	// EvictionSoft is the map of signal names to quantities that define soft eviction thresholds
+	// +kubebuilder:validation:XValidation:message="valid keys for evictionSoft are ['memory.available','nodefs.available','nodefs.inodesFree','imagefs.available','imagefs.inodesFree','pid.available']",rule="self.all(x, x in ['memory.available','nodefs.available','nodefs.inodesFree','imagefs.available','imagefs.inodesFree','pid.available'])"
+	// +optional
+	EvictionSoft map[string]string `json:"evictionSoft,omitempty"`
+	// EvictionSoftGracePeriod is the map of signal names to quantities that define grace periods for each eviction signal
+	// +kubebuilder:validation:XValidation:message="valid keys for evictionSoftGracePeriod are ['memory.available','nodefs.available','nodefs.inodesFree','imagefs.available','imagefs.inodesFree','pid.available']",rule="self.all(x, x in ['memory.available','nodefs.available','nodefs.inodesFree','imagefs.available','imagefs.inodesFree','pid.available'])"
+	// +optional
+	EvictionSoftGracePeriod map[string]metav1.Duration `json:"evictionSoftGracePeriod,omitempty"`
 }
 
 // A node selector requirement with min values is a selector that contains values, a key, an operator that relates the key and values
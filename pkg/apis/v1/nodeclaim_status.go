@@ -23,18 +23,37 @@ import (
 )
 
 const (
-	ConditionTypeLaunched             = "Launched"
-	ConditionTypeRegistered           = "Registered"
-	ConditionTypeInitialized          = "Initialized"
-	ConditionTypeConsolidatable       = "Consolidatable"
-	ConditionTypeDrifted              = "Drifted"
-	ConditionTypeDrained              = "Drained"
-	ConditionTypeVolumesDetached      = "VolumesDetached"
-	ConditionTypeInstanceTerminating  = "InstanceTerminating"
-	ConditionTypeConsistentStateFound = "ConsistentStateFound"
-	ConditionTypeDisruptionReason     = "DisruptionReason"
+	ConditionTypeLaunched                 = "Launched"
+	ConditionTypeRegistered               = "Registered"
+	ConditionTypeInitialized              = "Initialized"
+	ConditionTypeConsolidatable           = "Consolidatable"
+	ConditionTypeDrifted                  = "Drifted"
+	ConditionTypeUnhealthy                = "Unhealthy"
+	ConditionTypePreDrainHooksCompleted   = "PreDrainHooksCompleted"
+	ConditionTypeDrained                  = "Drained"
+	ConditionTypeVolumesDetached          = "VolumesDetached"
+	ConditionTypeTerminationHoldsReleased = "TerminationHoldsReleased"
+	ConditionTypePreTerminated            = "PreTerminated"
+	ConditionTypeInstanceTerminating      = "InstanceTerminating"
+	ConditionTypeConsistentStateFound     = "ConsistentStateFound"
+	ConditionTypeDisruptionReason         = "DisruptionReason"
+	ConditionTypeGarbageCollected         = "GarbageCollected"
 )
 
+// Reasons of the GarbageCollected condition, which records why Karpenter deleted a NodeClaim whose node never became
+// usable or whose instance disappeared
+const (
+	GarbageCollectedReasonLaunchFailed          = "LaunchFailed"
+	GarbageCollectedReasonRegistrationTimeout   = "RegistrationTimeout"
+	GarbageCollectedReasonInitializationTimeout = "InitializationTimeout"
+	GarbageCollectedReasonInstanceTerminated    = "InstanceTerminated"
+)
+
+// UnhealthyReasonStartupTaintsTimedOut is the reason of the Unhealthy condition of NodeClaims whose startup taints
+// weren't removed within the startup taint timeout. Unlike other unhealthy NodeClaims, these are replaced even though
+// their node never initialized.
+const UnhealthyReasonStartupTaintsTimedOut = "StartupTaintsTimedOut"
+
 // NodeClaimStatus defines the observed state of NodeClaim
 type NodeClaimStatus struct {
 	// NodeName is the name of the corresponding node object
@@ -60,6 +79,20 @@ type NodeClaimStatus struct {
 	// is also considered as removed.
 	// +optional
 	LastPodEventTime metav1.Time `json:"lastPodEventTime,omitempty"`
+	// TerminationDeadline is when the pods that block the eventual disruption of the NodeClaim, e.g. with
+	// PodDisruptionBudgets or the karpenter.sh/do-not-disrupt annotation, are forcibly evicted. It's only set for
+	// NodeClaims that are disrupted by an eventual disruption method, such as drift, with a terminationGracePeriod.
+	// +optional
+	TerminationDeadline *metav1.Time `json:"terminationDeadline,omitempty"`
+	// LaunchedAt is when the NodeClaim's instance was launched
+	// +optional
+	LaunchedAt *metav1.Time `json:"launchedAt,omitempty"`
+	// RegisteredAt is when the NodeClaim's node registered with the cluster
+	// +optional
+	RegisteredAt *metav1.Time `json:"registeredAt,omitempty"`
+	// InitializedAt is when the NodeClaim's node initialized and became available for pods to schedule to
+	// +optional
+	InitializedAt *metav1.Time `json:"initializedAt,omitempty"`
 }
 
 func (in *NodeClaim) StatusConditions() status.ConditionSet {
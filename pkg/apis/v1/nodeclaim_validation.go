@@ -20,10 +20,12 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -100,6 +102,72 @@ func validateTaintsField(taints []v1.Taint, existing map[taintKeyEffect]struct{}
 	return errs
 }
 
+func (in *NodeClaimTemplateSpec) validateKubelet() error {
+	if in.Kubelet == nil {
+		return nil
+	}
+	return in.Kubelet.validate()
+}
+
+func (in *KubeletConfiguration) validate() (errs error) {
+	if in.MaxPods != nil && *in.MaxPods < 0 {
+		errs = multierr.Append(errs, fmt.Errorf("invalid value: %d in kubelet.maxPods, must be non-negative", *in.MaxPods))
+	}
+	errs = multierr.Combine(errs, validateReservedResources(in.KubeReserved, "kubeReserved"), validateReservedResources(in.SystemReserved, "systemReserved"),
+		validateEvictionThresholds(in.EvictionHard, "evictionHard"), validateEvictionThresholds(in.EvictionSoft, "evictionSoft"))
+	for signal := range in.EvictionSoftGracePeriod {
+		if !SupportedEvictionSignals.Has(signal) {
+			errs = multierr.Append(errs, fmt.Errorf("invalid key: %q in kubelet.evictionSoftGracePeriod, expected one of %v", signal, SupportedEvictionSignals.List()))
+		}
+	}
+	for signal := range in.EvictionSoft {
+		if _, ok := in.EvictionSoftGracePeriod[signal]; !ok {
+			errs = multierr.Append(errs, fmt.Errorf("key %q in kubelet.evictionSoft does not have a matching evictionSoftGracePeriod", signal))
+		}
+	}
+	for signal := range in.EvictionSoftGracePeriod {
+		if _, ok := in.EvictionSoft[signal]; !ok {
+			errs = multierr.Append(errs, fmt.Errorf("key %q in kubelet.evictionSoftGracePeriod does not have a matching evictionSoft", signal))
+		}
+	}
+	return errs
+}
+
+func validateReservedResources(reserved map[string]string, fieldName string) (errs error) {
+	for name, value := range reserved {
+		if !SupportedReservedResources.Has(name) {
+			errs = multierr.Append(errs, fmt.Errorf("invalid key: %q in kubelet.%s, expected one of %v", name, fieldName, SupportedReservedResources.List()))
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("invalid value: %q for kubelet.%s[%s], %w", value, fieldName, name, err))
+		} else if quantity.Sign() < 0 {
+			errs = multierr.Append(errs, fmt.Errorf("invalid value: %q for kubelet.%s[%s], must be non-negative", value, fieldName, name))
+		}
+	}
+	return errs
+}
+
+// validateEvictionThresholds validates that eviction thresholds are either a percentage or a resource quantity
+func validateEvictionThresholds(thresholds map[string]string, fieldName string) (errs error) {
+	for signal, value := range thresholds {
+		if !SupportedEvictionSignals.Has(signal) {
+			errs = multierr.Append(errs, fmt.Errorf("invalid key: %q in kubelet.%s, expected one of %v", signal, fieldName, SupportedEvictionSignals.List()))
+		}
+		if strings.HasSuffix(value, "%") {
+			percentage, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || percentage < 0 || percentage > 100 {
+				errs = multierr.Append(errs, fmt.Errorf("invalid value: %q for kubelet.%s[%s], must be a percentage between 0 and 100", value, fieldName, signal))
+			}
+			continue
+		}
+		if quantity, err := resource.ParseQuantity(value); err != nil || quantity.Sign() < 0 {
+			errs = multierr.Append(errs, fmt.Errorf("invalid value: %q for kubelet.%s[%s], must be a percentage or a non-negative quantity", value, fieldName, signal))
+		}
+	}
+	return errs
+}
+
 // This function is used by the NodeClaim validation webhook to verify the nodepool requirements.
 // When this function is called, the nodepool's requirements do not include the requirements from labels.
 // NodeClaim requirements only support well known labels.
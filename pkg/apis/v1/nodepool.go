@@ -20,6 +20,10 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
+	// The time zone database is embedded so that the time zones of budget schedules can be loaded from images without
+	// one, e.g. distroless images
+	_ "time/tzdata"
 
 	"github.com/awslabs/operatorpkg/serrors"
 	"github.com/mitchellh/hashstructure/v2"
@@ -27,7 +31,10 @@ import (
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/clock"
 )
@@ -72,6 +79,30 @@ type NodePoolSpec struct {
 	// +kubebuilder:validation:Minimum:=0
 	// +optional
 	Replicas *int64 `json:"replicas,omitempty"`
+	// RolloutTrigger is an opaque value that rolls every NodeClaim of the NodePool when it's changed. NodeClaims
+	// record the value they were launched with, and NodeClaims whose value differs from a non-empty RolloutTrigger
+	// are marked as drifted so that they're replaced under the NodePool's disruption budgets.
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	RolloutTrigger string `json:"rolloutTrigger,omitempty"`
+	// ZoneSpread declares a floor on the number of zones that the NodePool's nodes span once the NodePool
+	// has at least minNodes nodes. Provisioning prefers zones that increase the NodePool's zonal diversity
+	// while the NodePool is below the floor, and consolidation won't execute commands that would reduce
+	// the number of zones below the floor.
+	// +optional
+	ZoneSpread *ZoneSpread `json:"zoneSpread,omitempty"`
+}
+
+type ZoneSpread struct {
+	// MinZones is the minimum number of zones that the NodePool's nodes should span.
+	// +kubebuilder:validation:Minimum:=2
+	// +required
+	MinZones int32 `json:"minZones"`
+	// MinNodes is the number of nodes the NodePool must have before the zone spread floor applies.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:default:=1
+	// +optional
+	MinNodes int32 `json:"minNodes,omitempty"`
 }
 
 type Disruption struct {
@@ -96,21 +127,243 @@ type Disruption struct {
 	// the most restrictive value. If left undefined,
 	// this will default to one budget with a value to 10%.
 	// +kubebuilder:validation:XValidation:message="'schedule' must be set with 'duration'",rule="self.all(x, has(x.schedule) == has(x.duration))"
+	// +kubebuilder:validation:XValidation:message="'driftReasons' can only be set on budgets that apply to 'Drifted'",rule="self.all(x, !has(x.driftReasons) || !has(x.reasons) || 'Drifted' in x.reasons)"
+	// +kubebuilder:validation:XValidation:message="'regions' can't be set with 'pods' or 'driftReasons'",rule="self.all(x, !has(x.regions) || (!has(x.pods) && !has(x.driftReasons)))"
+	// +kubebuilder:validation:XValidation:message="'timeZone' can only be set with 'schedule'",rule="self.all(x, !has(x.timeZone) || has(x.schedule))"
+	// +kubebuilder:validation:XValidation:message="'zones' can't be set with 'pods', 'driftReasons' or 'regions'",rule="self.all(x, !has(x.zones) || (!has(x.pods) && !has(x.driftReasons) && !has(x.regions)))"
+	// +kubebuilder:validation:XValidation:message="'resources' can't be set with 'driftReasons', 'regions' or 'zones'",rule="self.all(x, !has(x.resources) || (!has(x.driftReasons) && !has(x.regions) && !has(x.zones)))"
 	// +kubebuilder:default:={{nodes: "10%"}}
 	// +kubebuilder:validation:MaxItems=50
 	// +optional
 	Budgets []Budget `json:"budgets,omitempty" hash:"ignore"`
+	// NodeClassDriftPolicies classifies the drift reasons reported by the cloud provider for the NodeClass
+	// and decides whether each reason should cause NodeClaims to be marked as drifted. The first policy
+	// that matches a drift reason is used. Drift reasons that don't match any policy always drift.
+	// +kubebuilder:validation:XValidation:message="'schedule' must be set with 'duration'",rule="self.all(x, has(x.schedule) == has(x.duration))"
+	// +kubebuilder:validation:XValidation:message="'schedule' must be set if and only if 'action' is 'InMaintenanceWindow'",rule="self.all(x, has(x.schedule) == (has(x.action) && x.action == 'InMaintenanceWindow'))"
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	NodeClassDriftPolicies []NodeClassDriftPolicy `json:"nodeClassDriftPolicies,omitempty" hash:"ignore"`
+	// DriftRollout stages the replacement of drifted nodes. When set, Karpenter first replaces a canary
+	// percentage of the drifted nodes and waits for the replacements to be Ready for the soak duration
+	// before replacing the rest of the drifted nodes.
+	// +optional
+	DriftRollout *DriftRollout `json:"driftRollout,omitempty" hash:"ignore"`
+	// DriftFailureThreshold is the number of drift replacements that may fail to launch or initialize during a
+	// drift rollout before Karpenter pauses drift for this NodePool and sets the DriftPaused status condition.
+	// Drift resumes when the next rollout starts, or when the threshold is raised above the failure count.
+	// If left undefined, drift is never paused.
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	DriftFailureThreshold *int32 `json:"driftFailureThreshold,omitempty" hash:"ignore"`
+	// AutomatedDriftReasons restricts the drift reasons that Karpenter's Drift method acts on automatically.
+	// NodeClaims that drifted for a reason that isn't listed are only disrupted once they're approved with the
+	// karpenter.sh/drift-approved annotation. If left undefined, Karpenter acts on every drift reason.
+	// Well-known drift reasons include ImageDrifted, SecurityGroupDrifted, UserDataDrifted, NodePoolDrifted,
+	// RequirementsDrifted, RolloutTriggered and InstanceTypeNotFound.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	AutomatedDriftReasons []string `json:"automatedDriftReasons,omitempty" hash:"ignore"`
+	// RebootDriftReasons lists the drift reasons that Karpenter resolves by rebooting the instance in place rather than
+	// replacing it, e.g. kubelet configuration changes that are applied when the node boots. Rebooting requires
+	// support from the cloud provider. A NodeClaim is rebooted at most once per NodePool hash, and is replaced if it
+	// still drifted after the reboot. Nodes are drained before they're rebooted, and count against the disruption
+	// budgets until they're Ready again. If left undefined, drifted NodeClaims are always replaced.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	RebootDriftReasons []string `json:"rebootDriftReasons,omitempty" hash:"ignore"`
+	// DecisionPolicies are CEL expressions that are evaluated against a summary of each disruption command
+	// before it's executed. Every policy of every NodePool that owns a candidate of the command must approve
+	// it, otherwise the command is skipped.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	DecisionPolicies []DecisionPolicy `json:"decisionPolicies,omitempty" hash:"ignore"`
+	// DriftHashFields selects the NodePool template fields that drift NodeClaims when they change, so that
+	// e.g. label changes can be rolled out to new nodes without replacing the existing ones. If left undefined,
+	// every field drifts NodeClaims. Changing the selection drifts the NodeClaims whose hash is affected.
+	// +kubebuilder:validation:MaxItems=4
+	// +listType=set
+	// +optional
+	DriftHashFields []DriftHashField `json:"driftHashFields,omitempty" hash:"ignore"`
+	// DryRun overrides the disruption-dry-run setting of the controller for this NodePool. In dry-run mode every
+	// disruption method is evaluated, but the resulting commands are recorded as events, metrics and
+	// DisruptionDecisions instead of being executed. If left undefined, the controller setting is used.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty" hash:"ignore"`
+	// Paused stops Karpenter from disrupting the nodes of this NodePool for every disruption reason, and
+	// sets the DisruptionPaused status condition. Commands that are already in flight are not interrupted.
+	// +optional
+	Paused bool `json:"paused,omitempty" hash:"ignore"`
+	// IgnoredPodDisruptionBudgets selects PodDisruptionBudgets that eventual disruption ignores for the nodes of this
+	// NodePool. Pods that are only selected by ignored PodDisruptionBudgets are deleted right away when their node
+	// is drifted, rather than when the TerminationGracePeriod of the NodeClaim expires. This is meant for
+	// known-misconfigured PodDisruptionBudgets, e.g. maxUnavailable: 0 on single replica Deployments.
+	// +kubebuilder:validation:XValidation:message="exactly one of 'name' or 'selector' must be set",rule="self.all(x, has(x.name) != has(x.selector))"
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	IgnoredPodDisruptionBudgets []PodDisruptionBudgetSelector `json:"ignoredPodDisruptionBudgets,omitempty" hash:"ignore"`
+	// ExcludeNodes selects nodes of this NodePool by their labels that Karpenter doesn't disrupt for any disruption
+	// reason, like nodes with the karpenter.sh/do-not-disrupt annotation.
+	// +optional
+	ExcludeNodes *metav1.LabelSelector `json:"excludeNodes,omitempty" hash:"ignore"`
 }
 
+// PodDisruptionBudgetSelector selects PodDisruptionBudgets either by name or by their labels
+type PodDisruptionBudgetSelector struct {
+	// Namespace of the selected PodDisruptionBudgets. If left undefined, PodDisruptionBudgets in every namespace
+	// are selected.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the selected PodDisruptionBudgets.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Selector selects PodDisruptionBudgets by their labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// DriftHashField is a NodePool template field that can be selected to drift NodeClaims.
+// Taints covers both taints and startupTaints.
+// +kubebuilder:validation:Enum={Labels,Annotations,Taints,Requirements}
+type DriftHashField string
+
+const (
+	DriftHashFieldLabels       DriftHashField = "Labels"
+	DriftHashFieldAnnotations  DriftHashField = "Annotations"
+	DriftHashFieldTaints       DriftHashField = "Taints"
+	DriftHashFieldRequirements DriftHashField = "Requirements"
+)
+
+// IgnoresPodDisruptionBudget returns true if the PodDisruptionBudget is selected by the IgnoredPodDisruptionBudgets
+func (in *Disruption) IgnoresPodDisruptionBudget(pdb *policyv1.PodDisruptionBudget) bool {
+	return lo.SomeBy(in.IgnoredPodDisruptionBudgets, func(s PodDisruptionBudgetSelector) bool {
+		if s.Namespace != "" && s.Namespace != pdb.Namespace {
+			return false
+		}
+		if s.Selector == nil {
+			return s.Name == pdb.Name
+		}
+		selector, err := metav1.LabelSelectorAsSelector(s.Selector)
+		return err == nil && selector.Matches(labels.Set(pdb.Labels))
+	})
+}
+
+// ExcludesNode returns true if the node labels are selected by ExcludeNodes
+func (in *Disruption) ExcludesNode(nodeLabels map[string]string) bool {
+	if in.ExcludeNodes == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(in.ExcludeNodes)
+	return err == nil && selector.Matches(labels.Set(nodeLabels))
+}
+
+// IsDryRun returns true if disruption commands for the NodePool should be recorded instead of executed
+func (in *Disruption) IsDryRun(controllerDryRun bool) bool {
+	return lo.FromPtrOr(in.DryRun, controllerDryRun)
+}
+
+// IsDriftHashField returns true if changes to the field drift NodeClaims
+func (in *Disruption) IsDriftHashField(field DriftHashField) bool {
+	return len(in.DriftHashFields) == 0 || lo.Contains(in.DriftHashFields, field)
+}
+
+// DecisionPolicy is a CEL expression that approves or vetoes a disruption command. The expression must
+// evaluate to a bool, where true approves the command. The command is exposed as the "command" variable
+// with the fields reason, decision, candidateCount, podCount, replacementCount, priceDelta and candidates.
+// Each candidate has the fields name, nodePool, instanceType, capacityType, region, zone and podCount.
+type DecisionPolicy struct {
+	// Name identifies the policy in events and logs.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Name string `json:"name"`
+	// Expression is the CEL expression that's evaluated against the command.
+	// +kubebuilder:validation:MaxLength=4096
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Expression string `json:"expression"`
+}
+
+// DriftRollout defines the canary stage of a drift rollout.
+type DriftRollout struct {
+	// CanaryPercent is the percentage of drifted nodes that are replaced before the soak period begins.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	// +required
+	CanaryPercent int32 `json:"canaryPercent"`
+	// SoakDuration is how long the canary replacements must be Ready before the rest of the drifted
+	// nodes are replaced.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +required
+	SoakDuration metav1.Duration `json:"soakDuration"`
+}
+
+// NodeClassDriftPolicy defines how Karpenter treats a group of NodeClass drift reasons.
+type NodeClassDriftPolicy struct {
+	// Reasons is the list of drift reasons reported by the cloud provider that this policy applies to.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=50
+	// +required
+	Reasons []string `json:"reasons"`
+	// Action determines whether the drift reasons cause NodeClaims to be marked as drifted.
+	// Always marks the NodeClaims as drifted, Never ignores the drift and InMaintenanceWindow
+	// only marks the NodeClaims as drifted while the maintenance window is active.
+	// +kubebuilder:default:="Always"
+	// +kubebuilder:validation:Enum:={Always,Never,InMaintenanceWindow}
+	// +optional
+	Action NodeClassDriftAction `json:"action,omitempty"`
+	// Schedule specifies when the maintenance window begins, following
+	// the upstream cronjob syntax. Timezones are not supported.
+	// This field is required if Action is InMaintenanceWindow.
+	// +kubebuilder:validation:Pattern:=`^(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((.+)\s(.+)\s(.+)\s(.+)\s(.+))$`
+	// +optional
+	Schedule *string `json:"schedule,omitempty"`
+	// Duration determines how long the maintenance window is active since each Schedule hit.
+	// Only minutes and hours are accepted, as cron does not work in seconds.
+	// This is required if Schedule is set.
+	// +kubebuilder:validation:Pattern=`^((([0-9]+(h|m))|([0-9]+h[0-9]+m))(0s)?)$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+type NodeClassDriftAction string
+
+const (
+	NodeClassDriftActionAlways              NodeClassDriftAction = "Always"
+	NodeClassDriftActionNever               NodeClassDriftAction = "Never"
+	NodeClassDriftActionInMaintenanceWindow NodeClassDriftAction = "InMaintenanceWindow"
+)
+
 // Budget defines when Karpenter will restrict the
 // number of Node Claims that can be terminating simultaneously.
 type Budget struct {
 	// Reasons is a list of disruption methods that this budget applies to. If Reasons is not set, this budget applies to all methods.
 	// Otherwise, this will apply to each reason defined.
-	// allowed reasons are Underutilized, Empty, and Drifted.
+	// allowed reasons are Underutilized, Empty, Drifted, Requested, and Unhealthy.
 	// +kubebuilder:validation:MaxItems=50
 	// +optional
 	Reasons []DisruptionReason `json:"reasons,omitempty"`
+	// DriftReasons restricts the budget to NodeClaims that drifted for one of the listed drift reasons, e.g.
+	// ImageDrifted or NodePoolDrifted, so that each class of drift can be rolled out at its own pace. Budgets with
+	// DriftReasons only apply to Drifted, and are applied on top of the budgets without DriftReasons. Each drift
+	// reason is only limited by the NodeClaims being disrupted that drifted for that reason.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	DriftReasons []string `json:"driftReasons,omitempty" hash:"ignore"`
+	// Regions applies the budget to each of the listed regions independently, so that the regions of a NodePool that
+	// spans regions can be disrupted at their own pace. Percentages are calculated against the NodePool's nodes in the
+	// region. Budgets with Regions are applied on top of the budgets without Regions.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	Regions []string `json:"regions,omitempty" hash:"ignore"`
+	// Zones applies the budget to each of the listed topology zones independently, so that the disruptions of a
+	// NodePool that spans zones can't all land in one zone and break zonal spread. Percentages are calculated against
+	// the NodePool's nodes in the zone. Budgets with Zones are applied on top of the budgets without Zones.
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	Zones []string `json:"zones,omitempty" hash:"ignore"`
 	// Nodes dictates the maximum number of NodeClaims owned by this NodePool
 	// that can be terminating at once. This is calculated by counting nodes that
 	// have a deletion timestamp set, or are actively being deleted by Karpenter.
@@ -121,13 +374,44 @@ type Budget struct {
 	// +kubebuilder:validation:Pattern:="^((100|[0-9]{1,2})%|[0-9]+)$"
 	// +kubebuilder:default:="10%"
 	Nodes string `json:"nodes" hash:"ignore"`
+	// MinAvailable is the minimum number or percentage of the NodePool's nodes that must stay Ready while the budget
+	// is active. Allowed disruptions are calculated from the nodes that are Ready and not being disrupted, so NotReady
+	// nodes reduce how many nodes can be disrupted. When both Nodes and MinAvailable are set, the smaller allowance
+	// applies. Percentages are rounded up.
+	// +kubebuilder:validation:Pattern:="^((100|[0-9]{1,2})%|[0-9]+)$"
+	// +optional
+	MinAvailable *string `json:"minAvailable,omitempty" hash:"ignore"`
+	// Pods dictates the maximum number of reschedulable pods on NodeClaims owned by this
+	// NodePool that can be evicted by disruption at once. This is calculated by counting the
+	// reschedulable pods on nodes that are NotReady or actively being deleted by Karpenter, so
+	// pods count against the budget for as long as their node is being disrupted, not once per
+	// Schedule window. While none of the NodePool's nodes are being disrupted, a single node with
+	// more reschedulable pods than the budget can still be disrupted, unless the budget is 0.
+	// Empty nodes are not limited by this budget. If omitted, pod evictions are not limited.
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	Pods *int32 `json:"pods,omitempty" hash:"ignore"`
+	// Resources dictates the maximum capacity of NodeClaims owned by this NodePool that can be disrupted at once,
+	// e.g. cpu: 200, so that NodePools with heterogeneous instance sizes have a predictable capacity impact. This is
+	// calculated from the capacity of nodes that are NotReady or actively being deleted by Karpenter. Resources
+	// that aren't listed aren't limited. While none of the NodePool's nodes are being disrupted, a single node with
+	// more capacity than the budget can still be disrupted, unless the budget for one of its resources is 0.
+	// +optional
+	Resources v1.ResourceList `json:"resources,omitempty" hash:"ignore"`
 	// Schedule specifies when a budget begins being active, following
 	// the upstream cronjob syntax. If omitted, the budget is always active.
-	// Timezones are not supported.
+	// The schedule is evaluated in the TimeZone, or in UTC if TimeZone is omitted.
 	// This field is required if Duration is set.
 	// +kubebuilder:validation:Pattern:=`^(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((.+)\s(.+)\s(.+)\s(.+)\s(.+))$`
 	// +optional
 	Schedule *string `json:"schedule,omitempty" hash:"ignore"`
+	// TimeZone is the name of the IANA time zone that the Schedule is evaluated in, e.g. "America/New_York", so that
+	// schedules follow local time and daylight saving time. If omitted, the Schedule is evaluated in UTC.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	// +kubebuilder:validation:XValidation:message="'timeZone' must be an IANA time zone name, e.g. 'America/New_York'",rule="self != 'Local' && self.matches('^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$')"
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty" hash:"ignore"`
 	// Duration determines how long a Budget is active since each Schedule hit.
 	// Only minutes and hours are accepted, as cron does not work in seconds.
 	// If omitted, the budget is always active.
@@ -148,15 +432,35 @@ const (
 )
 
 // DisruptionReason defines valid reasons for disruption budgets.
-// +kubebuilder:validation:Enum={Underutilized,Empty,Drifted}
+// +kubebuilder:validation:Enum={Underutilized,Empty,Drifted,Requested,Unhealthy}
 type DisruptionReason string
 
 const (
 	DisruptionReasonUnderutilized DisruptionReason = "Underutilized"
 	DisruptionReasonEmpty         DisruptionReason = "Empty"
 	DisruptionReasonDrifted       DisruptionReason = "Drifted"
+	DisruptionReasonRequested     DisruptionReason = "Requested"
+	DisruptionReasonUnhealthy     DisruptionReason = "Unhealthy"
 )
 
+// WellKnownDisruptionReasons are the disruption reasons that budgets can rate-limit
+var WellKnownDisruptionReasons = []DisruptionReason{
+	DisruptionReasonRequested,
+	DisruptionReasonUnhealthy,
+	DisruptionReasonEmpty,
+	DisruptionReasonDrifted,
+	DisruptionReasonUnderutilized,
+}
+
+// DisruptionReasonExpired is the reason for nodes that are disrupted because they've expired. Expiration isn't
+// rate-limited by disruption budgets, so it isn't a valid budget reason.
+const DisruptionReasonExpired = "Expired"
+
+// DisruptionReasonInterrupted is the reason for nodes that are disrupted because their CloudProvider signaled that
+// their instance is going to be interrupted. The instance is reclaimed regardless of disruption budgets, so it isn't a
+// valid budget reason either.
+const DisruptionReasonInterrupted = "Interrupted"
+
 type Limits v1.ResourceList
 
 func (l Limits) ExceededBy(resources v1.ResourceList) error {
@@ -221,6 +525,13 @@ type NodeClaimTemplateSpec struct {
 	// +kubebuilder:validation:Type="string"
 	// +optional
 	TerminationGracePeriod *metav1.Duration `json:"terminationGracePeriod,omitempty"`
+	// TerminationGracePeriodOverrides override the TerminationGracePeriod for nodes that are disrupted for specific reasons,
+	// e.g. to allow drifted nodes longer to drain than expired nodes. Never removes the TerminationGracePeriod for the reason.
+	// +listType=map
+	// +listMapKey=reason
+	// +kubebuilder:validation:MaxItems=7
+	// +optional
+	TerminationGracePeriodOverrides []TerminationGracePeriodOverride `json:"terminationGracePeriodOverrides,omitempty"`
 	// ExpireAfter is the duration the controller will wait
 	// before terminating a node, measured from when the node is created. This
 	// is useful to implement features like eventually consistent node upgrade,
@@ -231,6 +542,30 @@ type NodeClaimTemplateSpec struct {
 	// +kubebuilder:validation:Schemaless
 	// +optional
 	ExpireAfter NillableDuration `json:"expireAfter,omitempty"`
+	// ExpireAfterCost is the cumulative cost, in the currency units of the cloudprovider's hourly offering prices, that a
+	// node can accrue before it's terminated. The cost is computed from the price of the offering that the node was
+	// launched with. Nodes expire at whichever of ExpireAfter and ExpireAfterCost is reached first.
+	// +kubebuilder:validation:Pattern=`^\d+(\.\d+)?$`
+	// +optional
+	ExpireAfterCost *string `json:"expireAfterCost,omitempty"`
+	// RegistrationTimeout is how long the controller waits for the node of a launched NodeClaim to register before it
+	// deletes the NodeClaim so that a replacement can be launched. Increase it for nodes that legitimately take longer
+	// to join the cluster, e.g. GPU nodes with large images. Defaults to 15m.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	RegistrationTimeout *metav1.Duration `json:"registrationTimeout,omitempty" hash:"ignore"`
+	// InitializationTimeout is how long the controller waits for a registered node to initialize, i.e. to become ready,
+	// register its extended resources and have its startup taints removed, before it deletes the NodeClaim so that a
+	// replacement can be launched. If left undefined, the controller waits indefinitely.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(s|m|h))+$`
+	// +kubebuilder:validation:Type="string"
+	// +optional
+	InitializationTimeout *metav1.Duration `json:"initializationTimeout,omitempty" hash:"ignore"`
+	// Kubelet defines args to be used when configuring kubelet on provisioned nodes. It's passed to the cloud provider
+	// when the NodeClaim is launched, and NodeClaims launched with a different configuration are drifted.
+	// +optional
+	Kubelet *KubeletConfiguration `json:"kubelet,omitempty"`
 }
 
 // This is used to convert between the NodeClaim's NodeClaimSpec to the Nodepool NodeClaimTemplate's NodeClaimSpec.
@@ -241,12 +576,17 @@ func (in *NodeClaimTemplate) ToNodeClaim() *NodeClaim {
 			Annotations: in.Annotations,
 		},
 		Spec: NodeClaimSpec{
-			Taints:                 in.Spec.Taints,
-			StartupTaints:          in.Spec.StartupTaints,
-			Requirements:           in.Spec.Requirements,
-			NodeClassRef:           in.Spec.NodeClassRef,
-			TerminationGracePeriod: in.Spec.TerminationGracePeriod,
-			ExpireAfter:            in.Spec.ExpireAfter,
+			Taints:                          in.Spec.Taints,
+			StartupTaints:                   in.Spec.StartupTaints,
+			Requirements:                    in.Spec.Requirements,
+			NodeClassRef:                    in.Spec.NodeClassRef,
+			TerminationGracePeriod:          in.Spec.TerminationGracePeriod,
+			TerminationGracePeriodOverrides: in.Spec.TerminationGracePeriodOverrides,
+			ExpireAfter:                     in.Spec.ExpireAfter,
+			ExpireAfterCost:                 in.Spec.ExpireAfterCost,
+			RegistrationTimeout:             in.Spec.RegistrationTimeout,
+			InitializationTimeout:           in.Spec.InitializationTimeout,
+			Kubelet:                         in.Spec.Kubelet,
 		},
 	}
 }
@@ -296,13 +636,41 @@ type NodePool struct {
 const NodePoolHashVersion = "v3"
 
 func (in *NodePool) Hash() string {
-	return fmt.Sprint(lo.Must(hashstructure.Hash(in.Spec.Template, hashstructure.FormatV2, &hashstructure.HashOptions{
+	// Fields that aren't selected by the NodePool's DriftHashFields are zeroed so that they don't contribute to the hash
+	template := in.Spec.Template.DeepCopy()
+	if !in.Spec.Disruption.IsDriftHashField(DriftHashFieldLabels) {
+		template.Labels = nil
+	}
+	if !in.Spec.Disruption.IsDriftHashField(DriftHashFieldAnnotations) {
+		template.Annotations = nil
+	}
+	if !in.Spec.Disruption.IsDriftHashField(DriftHashFieldTaints) {
+		template.Spec.Taints = nil
+		template.Spec.StartupTaints = nil
+	}
+	return fmt.Sprint(lo.Must(hashstructure.Hash(*template, hashstructure.FormatV2, &hashstructure.HashOptions{
 		SlicesAsSets:    true,
 		IgnoreZeroValue: true,
 		ZeroNil:         true,
 	})))
 }
 
+// Requirements returns the requirements of the NodePool's template. Instance-type requirements that have been widened
+// after consecutive provisioning failures are relaxed to Exists, keeping their minValues.
+func (in *NodePool) Requirements() []NodeSelectorRequirementWithMinValues {
+	return lo.Map(in.Spec.Template.Spec.Requirements, func(r NodeSelectorRequirementWithMinValues, _ int) NodeSelectorRequirementWithMinValues {
+		if r.Operator != v1.NodeSelectorOpIn || !lo.Contains(in.Status.WidenedRequirements, r.Key) {
+			return r
+		}
+		return NodeSelectorRequirementWithMinValues{NodeSelectorRequirement: v1.NodeSelectorRequirement{Key: r.Key, Operator: v1.NodeSelectorOpExists}, MinValues: r.MinValues}
+	})
+}
+
+// Weight returns the NodePool's weight, raised to its escalated weight while it's the fallback of a failing NodePool
+func (in *NodePool) Weight() int32 {
+	return lo.Max([]int32{lo.FromPtr(in.Spec.Weight), lo.FromPtr(in.Status.EscalatedWeight)})
+}
+
 // NodePoolList contains a list of NodePool
 // +kubebuilder:object:root=true
 type NodePoolList struct {
@@ -331,13 +699,103 @@ func (in *NodePool) GetAllowedDisruptionsByReason(c clock.Clock, numNodes int, r
 		if err != nil {
 			multiErr = multierr.Append(multiErr, err)
 		}
-		if budget.Reasons == nil || lo.Contains(budget.Reasons, reason) {
+		if budget.DriftReasons == nil && budget.Regions == nil && budget.Zones == nil && (budget.Reasons == nil || lo.Contains(budget.Reasons, reason)) {
 			allowedNodes = lo.Min([]int{allowedNodes, val})
 		}
 	}
 	return allowedNodes, multiErr
 }
 
+// MustGetAllowedDisruptionsByRegion calls GetAllowedDisruptionsByRegion and returns 0 if the error is not nil.
+func (in *NodePool) MustGetAllowedDisruptionsByRegion(c clock.Clock, numNodes int, reason DisruptionReason, region string) int {
+	allowedDisruptions, err := in.GetAllowedDisruptionsByRegion(c, numNodes, reason, region)
+	if err != nil {
+		return 0
+	}
+	return allowedDisruptions
+}
+
+// GetAllowedDisruptionsByRegion returns how many of the NodePool's nodes in the region can be disrupted for a given
+// reason, so that each region of a NodePool that spans regions is rolled out at its own pace. Percentages are
+// calculated against numNodes, the number of the NodePool's nodes in the region. This returns MAXINT if no active
+// budget targets the region.
+func (in *NodePool) GetAllowedDisruptionsByRegion(c clock.Clock, numNodes int, reason DisruptionReason, region string) (int, error) {
+	return in.getAllowedDisruptionsForBudgets(c, numNodes, func(budget Budget) bool {
+		return lo.Contains(budget.Regions, region) && (budget.Reasons == nil || lo.Contains(budget.Reasons, reason))
+	})
+}
+
+// Regions returns the regions that are targeted by the NodePool's budgets
+func (in *NodePool) Regions() []string {
+	return lo.Uniq(lo.FlatMap(in.Spec.Disruption.Budgets, func(b Budget, _ int) []string { return b.Regions }))
+}
+
+// MustGetAllowedDisruptionsByZone calls GetAllowedDisruptionsByZone and returns 0 if the error is not nil.
+func (in *NodePool) MustGetAllowedDisruptionsByZone(c clock.Clock, numNodes int, reason DisruptionReason, zone string) int {
+	allowedDisruptions, err := in.GetAllowedDisruptionsByZone(c, numNodes, reason, zone)
+	if err != nil {
+		return 0
+	}
+	return allowedDisruptions
+}
+
+// GetAllowedDisruptionsByZone returns how many of the NodePool's nodes in the zone can be disrupted for a given
+// reason, so that disruptions can't all land in one zone and break zonal spread. Percentages are calculated against
+// numNodes, the number of the NodePool's nodes in the zone. This returns MAXINT if no active budget targets the zone.
+func (in *NodePool) GetAllowedDisruptionsByZone(c clock.Clock, numNodes int, reason DisruptionReason, zone string) (int, error) {
+	return in.getAllowedDisruptionsForBudgets(c, numNodes, func(budget Budget) bool {
+		return lo.Contains(budget.Zones, zone) && (budget.Reasons == nil || lo.Contains(budget.Reasons, reason))
+	})
+}
+
+// Zones returns the zones that are targeted by the NodePool's budgets
+func (in *NodePool) Zones() []string {
+	return lo.Uniq(lo.FlatMap(in.Spec.Disruption.Budgets, func(b Budget, _ int) []string { return b.Zones }))
+}
+
+// MustGetAllowedDisruptionsByDriftReason calls GetAllowedDisruptionsByDriftReason and returns 0 if the error is not nil.
+func (in *NodePool) MustGetAllowedDisruptionsByDriftReason(c clock.Clock, numNodes int, driftReason string) int {
+	allowedDisruptions, err := in.GetAllowedDisruptionsByDriftReason(c, numNodes, driftReason)
+	if err != nil {
+		return 0
+	}
+	return allowedDisruptions
+}
+
+// GetAllowedDisruptionsByDriftReason returns the minimum allowed disruptions of NodeClaims that drifted for the drift
+// reason, across the budgets that apply to Drifted and the budgets that target the drift reason. Unlike regions and
+// zones, numNodes is the number of all of the NodePool's nodes, since a node's drift reason changes over its lifetime.
+func (in *NodePool) GetAllowedDisruptionsByDriftReason(c clock.Clock, numNodes int, driftReason string) (int, error) {
+	allowedNodes, multiErr := in.GetAllowedDisruptionsByReason(c, numNodes, DisruptionReasonDrifted)
+	allowedByDriftReason, err := in.getAllowedDisruptionsForBudgets(c, numNodes, func(budget Budget) bool {
+		return lo.Contains(budget.DriftReasons, driftReason) && budget.Regions == nil && budget.Zones == nil
+	})
+	return lo.Min([]int{allowedNodes, allowedByDriftReason}), multierr.Append(multiErr, err)
+}
+
+// DriftReasons returns the drift reasons that are targeted by the NodePool's budgets
+func (in *NodePool) DriftReasons() []string {
+	return lo.Uniq(lo.FlatMap(in.Spec.Disruption.Budgets, func(b Budget, _ int) []string { return b.DriftReasons }))
+}
+
+// getAllowedDisruptionsForBudgets returns the minimum allowed disruptions across the budgets that selects returns true
+// for, along with the errors of their schedules. This returns MAXINT if no active budget is selected.
+func (in *NodePool) getAllowedDisruptionsForBudgets(c clock.Clock, numNodes int, selects func(Budget) bool) (int, error) {
+	allowedNodes := math.MaxInt32
+	var multiErr error
+	for _, budget := range in.Spec.Disruption.Budgets {
+		if !selects(budget) {
+			continue
+		}
+		val, err := budget.GetAllowedDisruptions(c, numNodes)
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+		}
+		allowedNodes = lo.Min([]int{allowedNodes, val})
+	}
+	return allowedNodes, multiErr
+}
+
 // GetAllowedDisruptions returns an intstr.IntOrString that can be used a comparison
 // for calculating if a disruption action is allowed. It returns an error if the
 // schedule is invalid. This returns MAXINT if the value is unbounded.
@@ -362,9 +820,109 @@ func (in *Budget) GetAllowedDisruptions(c clock.Clock, numNodes int) (int, error
 		// they want here.
 		return 0, err
 	}
+	if in.MinAvailable != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(lo.ToPtr(GetIntStrFromValue(*in.MinAvailable)), numNodes, true)
+		if err != nil {
+			return 0, err
+		}
+		// Callers subtract the nodes that are NotReady or being disrupted from the allowed disruptions, which leaves
+		// the number of Ready nodes in excess of MinAvailable.
+		res = lo.Min([]int{res, lo.Max([]int{numNodes - minAvailable, 0})})
+	}
 	return res, nil
 }
 
+// MustGetAllowedPodDisruptions calls GetAllowedPodDisruptionsByReason and returns 0 if the error is not nil.
+func (in *NodePool) MustGetAllowedPodDisruptions(c clock.Clock, reason DisruptionReason) int {
+	allowedPods, err := in.GetAllowedPodDisruptionsByReason(c, reason)
+	if err != nil {
+		return 0
+	}
+	return allowedPods
+}
+
+// GetAllowedPodDisruptionsByReason returns the minimum allowed pod evictions across all disruption budgets for a given reason.
+// This returns MAXINT if no active budget limits pod evictions.
+func (in *NodePool) GetAllowedPodDisruptionsByReason(c clock.Clock, reason DisruptionReason) (int, error) {
+	allowedPods := math.MaxInt32
+	var multiErr error
+	for _, budget := range in.Spec.Disruption.Budgets {
+		val, err := budget.GetAllowedPodDisruptions(c)
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+		}
+		if budget.DriftReasons == nil && budget.Regions == nil && budget.Zones == nil && (budget.Reasons == nil || lo.Contains(budget.Reasons, reason)) {
+			allowedPods = lo.Min([]int{allowedPods, val})
+		}
+	}
+	return allowedPods, multiErr
+}
+
+// MustGetAllowedResourceDisruptions calls GetAllowedResourceDisruptionsByReason and returns zero quantities for the
+// resources that budgets limit if the error is not nil.
+func (in *NodePool) MustGetAllowedResourceDisruptions(c clock.Clock, reason DisruptionReason) v1.ResourceList {
+	allowedResources, err := in.GetAllowedResourceDisruptionsByReason(c, reason)
+	if err != nil {
+		return lo.SliceToMap(lo.FlatMap(in.Spec.Disruption.Budgets, func(b Budget, _ int) []v1.ResourceName { return lo.Keys(b.Resources) }),
+			func(name v1.ResourceName) (v1.ResourceName, resource.Quantity) { return name, resource.Quantity{} })
+	}
+	return allowedResources
+}
+
+// GetAllowedResourceDisruptionsByReason returns the minimum capacity that can be disrupted for each resource across
+// all disruption budgets for a given reason. Resources that no active budget limits are omitted, and nil is returned
+// if no active budget limits resources.
+func (in *NodePool) GetAllowedResourceDisruptionsByReason(c clock.Clock, reason DisruptionReason) (v1.ResourceList, error) {
+	var allowedResources v1.ResourceList
+	var multiErr error
+	for _, budget := range in.Spec.Disruption.Budgets {
+		val, err := budget.GetAllowedResourceDisruptions(c)
+		if err != nil {
+			multiErr = multierr.Append(multiErr, err)
+		}
+		if budget.DriftReasons != nil || budget.Regions != nil || budget.Zones != nil || (budget.Reasons != nil && !lo.Contains(budget.Reasons, reason)) {
+			continue
+		}
+		for name, quantity := range val {
+			if allowedResources == nil {
+				allowedResources = v1.ResourceList{}
+			}
+			if current, ok := allowedResources[name]; !ok || quantity.Cmp(current) < 0 {
+				allowedResources[name] = quantity
+			}
+		}
+	}
+	return allowedResources, multiErr
+}
+
+// GetAllowedResourceDisruptions returns the capacity that the budget allows to be disrupted. It returns an error if
+// the schedule is invalid. This returns nil if the budget is inactive or doesn't limit resources.
+func (in *Budget) GetAllowedResourceDisruptions(c clock.Clock) (v1.ResourceList, error) {
+	active, err := in.IsActive(c)
+	// If the budget is misconfigured, fail closed.
+	if err != nil {
+		return lo.MapValues(in.Resources, func(resource.Quantity, v1.ResourceName) resource.Quantity { return resource.Quantity{} }), err
+	}
+	if !active {
+		return nil, nil
+	}
+	return in.Resources, nil
+}
+
+// GetAllowedPodDisruptions returns the number of pods that the budget allows to be evicted. It returns an error if the
+// schedule is invalid. This returns MAXINT if the budget is inactive or doesn't limit pods.
+func (in *Budget) GetAllowedPodDisruptions(c clock.Clock) (int, error) {
+	active, err := in.IsActive(c)
+	// If the budget is misconfigured, fail closed.
+	if err != nil {
+		return 0, err
+	}
+	if !active || in.Pods == nil {
+		return math.MaxInt32, nil
+	}
+	return int(lo.FromPtr(in.Pods)), nil
+}
+
 // IsActive takes a clock as input and returns if a budget is active.
 // It walks back in time the time.Duration associated with the schedule,
 // and checks if the next time the schedule will hit is before the current time.
@@ -375,14 +933,70 @@ func (in *Budget) IsActive(c clock.Clock) (bool, error) {
 	if in.Schedule == nil && in.Duration == nil {
 		return true, nil
 	}
-	schedule, err := cron.ParseStandard(fmt.Sprintf("TZ=UTC %s", lo.FromPtr(in.Schedule)))
+	return isScheduleActive(c, lo.FromPtr(in.Schedule), lo.FromPtr(in.Duration).Duration, lo.FromPtrOr(in.TimeZone, "UTC"))
+}
+
+// ValidateBudgetTimeZones returns an error for each budget whose time zone can't be loaded. The API only validates
+// the format of time zone names, and budgets with unknown time zones fail closed by never allowing disruptions.
+func (in *Disruption) ValidateBudgetTimeZones() (errs error) {
+	for _, budget := range in.Budgets {
+		if budget.TimeZone == nil {
+			continue
+		}
+		if _, err := time.LoadLocation(*budget.TimeZone); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("invalid time zone %q, %w", *budget.TimeZone, err))
+		}
+	}
+	return errs
+}
+
+// ShouldDrift returns whether a drift reason reported by the cloud provider should mark a NodeClaim as drifted,
+// based on the first NodeClassDriftPolicy that matches the reason. It returns an error if the matching policy
+// has an invalid schedule.
+func (in *Disruption) ShouldDrift(c clock.Clock, reason string) (bool, error) {
+	policy, ok := lo.Find(in.NodeClassDriftPolicies, func(p NodeClassDriftPolicy) bool { return lo.Contains(p.Reasons, reason) })
+	if !ok {
+		return true, nil
+	}
+	switch policy.Action {
+	case NodeClassDriftActionNever:
+		return false, nil
+	case NodeClassDriftActionInMaintenanceWindow:
+		// If the maintenance window is misconfigured, fail closed.
+		if policy.Schedule == nil || policy.Duration == nil {
+			return false, nil
+		}
+		return isScheduleActive(c, lo.FromPtr(policy.Schedule), policy.Duration.Duration, "UTC")
+	default:
+		return true, nil
+	}
+}
+
+// IsDriftAutomated returns whether Karpenter should act on NodeClaims that drifted for the reason without approval
+func (in *Disruption) IsDriftAutomated(reason string) bool {
+	return len(in.AutomatedDriftReasons) == 0 || lo.Contains(in.AutomatedDriftReasons, reason)
+}
+
+// IsDriftRebootable returns whether Karpenter should reboot NodeClaims that drifted for the reason instead of replacing them
+func (in *Disruption) IsDriftRebootable(reason string) bool {
+	return lo.Contains(in.RebootDriftReasons, reason)
+}
+
+// isScheduleActive walks back in time the duration associated with the schedule,
+// and checks if the next time the schedule will hit is before the current time.
+// The schedule is evaluated in the named IANA time zone.
+func isScheduleActive(c clock.Clock, cronSchedule string, duration time.Duration, timeZone string) (bool, error) {
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return false, serrors.Wrap(fmt.Errorf("invalid time zone, %w", err), "timeZone", timeZone)
+	}
+	schedule, err := cron.ParseStandard(fmt.Sprintf("TZ=%s %s", timeZone, cronSchedule))
 	if err != nil {
 		// Should only occur if there's a discrepancy
 		// with the validation regex and the cron package.
 		return false, serrors.Wrap(fmt.Errorf("invariant violated, invalid cron, %w", err), "cron", schedule)
 	}
 	// Walk back in time for the duration associated with the schedule
-	checkPoint := c.Now().UTC().Add(-lo.FromPtr(in.Duration).Duration)
+	checkPoint := c.Now().UTC().Add(-duration)
 	nextHit := schedule.Next(checkPoint)
 	return !nextHit.After(c.Now().UTC()), nil
 }
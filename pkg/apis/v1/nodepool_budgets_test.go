@@ -25,6 +25,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clock "k8s.io/utils/clock/testing"
 
@@ -176,6 +178,163 @@ var _ = Describe("Budgets", func() {
 
 	})
 
+	Context("GetAllowedDisruptionsByDriftReason", func() {
+		BeforeEach(func() {
+			nodePool.Spec.Disruption.Budgets = append(nodePool.Spec.Disruption.Budgets, Budget{
+				Reasons:      []DisruptionReason{DisruptionReasonDrifted},
+				DriftReasons: []string{"NodePoolDrifted"},
+				Nodes:        "1",
+			})
+		})
+		It("should ignore drift reason budgets for other disruption reasons", func() {
+			for _, reason := range allKnownDisruptionReasons {
+				allowedDisruption, err := nodePool.GetAllowedDisruptionsByReason(fakeClock, 100, reason)
+				Expect(err).To(BeNil())
+				Expect(allowedDisruption).To(Equal(lo.Ternary(reason == DisruptionReasonDrifted, 5, 10)))
+			}
+		})
+		It("should get the minimum of the drift reason budget and the drift budget", func() {
+			allowedDisruption, err := nodePool.GetAllowedDisruptionsByDriftReason(fakeClock, 100, "NodePoolDrifted")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(1))
+		})
+		It("should return the drift budget for drift reasons without a budget", func() {
+			allowedDisruption, err := nodePool.GetAllowedDisruptionsByDriftReason(fakeClock, 100, "ImageDrifted")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(5))
+		})
+		It("should return the drift reasons targeted by budgets", func() {
+			Expect(nodePool.DriftReasons()).To(ConsistOf("NodePoolDrifted"))
+		})
+	})
+
+	Context("GetAllowedDisruptionsByRegion", func() {
+		BeforeEach(func() {
+			nodePool.Spec.Disruption.Budgets = append(nodePool.Spec.Disruption.Budgets, Budget{
+				Regions: []string{"test-region-1"},
+				Nodes:   "20%",
+			}, Budget{
+				Reasons: []DisruptionReason{DisruptionReasonDrifted},
+				Regions: []string{"test-region-1", "test-region-2"},
+				Nodes:   "1",
+			})
+		})
+		It("should ignore region budgets when calculating the NodePool's budget", func() {
+			for _, reason := range allKnownDisruptionReasons {
+				allowedDisruption, err := nodePool.GetAllowedDisruptionsByReason(fakeClock, 100, reason)
+				Expect(err).To(BeNil())
+				Expect(allowedDisruption).To(Equal(lo.Ternary(reason == DisruptionReasonDrifted, 5, 10)))
+			}
+		})
+		It("should get the minimum budget for each region and reason", func() {
+			allowedDisruption, err := nodePool.GetAllowedDisruptionsByRegion(fakeClock, 20, DisruptionReasonEmpty, "test-region-1")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(4))
+			allowedDisruption, err = nodePool.GetAllowedDisruptionsByRegion(fakeClock, 20, DisruptionReasonDrifted, "test-region-1")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(1))
+			allowedDisruption, err = nodePool.GetAllowedDisruptionsByRegion(fakeClock, 20, DisruptionReasonEmpty, "test-region-2")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(math.MaxInt32))
+		})
+		It("should return the regions targeted by budgets", func() {
+			Expect(nodePool.Regions()).To(ConsistOf("test-region-1", "test-region-2"))
+		})
+	})
+
+	Context("GetAllowedDisruptionsByZone", func() {
+		BeforeEach(func() {
+			nodePool.Spec.Disruption.Budgets = append(nodePool.Spec.Disruption.Budgets, Budget{
+				Zones: []string{"test-zone-1"},
+				Nodes: "20%",
+			}, Budget{
+				Reasons: []DisruptionReason{DisruptionReasonDrifted},
+				Zones:   []string{"test-zone-1", "test-zone-2"},
+				Nodes:   "1",
+			})
+		})
+		It("should ignore zone budgets when calculating the NodePool's budget", func() {
+			for _, reason := range allKnownDisruptionReasons {
+				allowedDisruption, err := nodePool.GetAllowedDisruptionsByReason(fakeClock, 100, reason)
+				Expect(err).To(BeNil())
+				Expect(allowedDisruption).To(Equal(lo.Ternary(reason == DisruptionReasonDrifted, 5, 10)))
+			}
+		})
+		It("should get the minimum budget for each zone and reason", func() {
+			allowedDisruption, err := nodePool.GetAllowedDisruptionsByZone(fakeClock, 20, DisruptionReasonEmpty, "test-zone-1")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(4))
+			allowedDisruption, err = nodePool.GetAllowedDisruptionsByZone(fakeClock, 20, DisruptionReasonDrifted, "test-zone-1")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(1))
+			allowedDisruption, err = nodePool.GetAllowedDisruptionsByZone(fakeClock, 20, DisruptionReasonEmpty, "test-zone-2")
+			Expect(err).To(BeNil())
+			Expect(allowedDisruption).To(Equal(math.MaxInt32))
+		})
+		It("should return the zones targeted by budgets", func() {
+			Expect(nodePool.Zones()).To(ConsistOf("test-zone-1", "test-zone-2"))
+		})
+	})
+
+	Context("GetAllowedResourceDisruptionsByReason", func() {
+		It("should return nil when no budget limits resources", func() {
+			for _, reason := range allKnownDisruptionReasons {
+				allowedResources, err := nodePool.GetAllowedResourceDisruptionsByReason(fakeClock, reason)
+				Expect(err).To(BeNil())
+				Expect(allowedResources).To(BeNil())
+			}
+		})
+		It("should return the minimum capacity for each resource across the budgets for the reason", func() {
+			budgets[0].Resources = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200"), corev1.ResourceMemory: resource.MustParse("1Ti")}
+			budgets[3].Resources = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100")}
+			allowedResources, err := nodePool.GetAllowedResourceDisruptionsByReason(fakeClock, budgets[3].Reasons[0])
+			Expect(err).To(BeNil())
+			Expect(allowedResources.Cpu().String()).To(Equal("100"))
+			Expect(allowedResources.Memory().String()).To(Equal("1Ti"))
+		})
+		It("should ignore inactive budgets", func() {
+			budgets[0].Resources = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200")}
+			budgets[0].Schedule = lo.ToPtr("@yearly")
+			allowedResources, err := nodePool.GetAllowedResourceDisruptionsByReason(fakeClock, DisruptionReasonEmpty)
+			Expect(err).To(BeNil())
+			Expect(allowedResources).To(BeNil())
+		})
+		It("should return zero capacity for the budgeted resources when a schedule is invalid", func() {
+			budgets[0].Resources = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200")}
+			budgets[0].Schedule = lo.ToPtr("@wrongly")
+			allowedResources := nodePool.MustGetAllowedResourceDisruptions(fakeClock, DisruptionReasonEmpty)
+			Expect(allowedResources.Cpu().IsZero()).To(BeTrue())
+		})
+	})
+
+	Context("GetAllowedPodDisruptionsByReason", func() {
+		It("should return MaxInt32 for all reasons when no budget limits pods", func() {
+			for _, reason := range allKnownDisruptionReasons {
+				allowedPods, err := nodePool.GetAllowedPodDisruptionsByReason(fakeClock, reason)
+				Expect(err).To(BeNil())
+				Expect(allowedPods).To(Equal(math.MaxInt32))
+			}
+		})
+		It("should get the minimum pod budget for each reason", func() {
+			budgets[0].Pods = lo.ToPtr[int32](200)
+			budgets[4].Pods = lo.ToPtr[int32](50)
+			budgets[5].Pods = lo.ToPtr[int32](0)
+
+			for _, reason := range allKnownDisruptionReasons {
+				allowedPods, err := nodePool.GetAllowedPodDisruptionsByReason(fakeClock, reason)
+				Expect(err).To(BeNil())
+				Expect(allowedPods).To(Equal(lo.Ternary(reason == DisruptionReasonDrifted, 50, 200)))
+			}
+		})
+		It("should return zero if a schedule is invalid", func() {
+			budgets[0].Pods = lo.ToPtr[int32](200)
+			budgets[0].Schedule = lo.ToPtr("@wrongly")
+			allowedPods, err := nodePool.GetAllowedPodDisruptionsByReason(fakeClock, DisruptionReasonDrifted)
+			Expect(err).ToNot(BeNil())
+			Expect(allowedPods).To(Equal(0))
+		})
+	})
+
 	Context("AllowedDisruptions", func() {
 		It("should return zero values if a schedule is invalid", func() {
 			budgets[0].Schedule = lo.ToPtr("@wrongly")
@@ -206,6 +365,30 @@ var _ = Describe("Budgets", func() {
 			Expect(err).To(Succeed())
 			Expect(val).To(BeNumerically("==", 100))
 		})
+		It("should keep the int value of minAvailable nodes", func() {
+			budgets[2].MinAvailable = lo.ToPtr("80")
+			val, err := budgets[2].GetAllowedDisruptions(fakeClock, 100)
+			Expect(err).To(Succeed())
+			Expect(val).To(BeNumerically("==", 20))
+		})
+		It("should keep the string value of minAvailable nodes, rounding up", func() {
+			budgets[2].MinAvailable = lo.ToPtr("85%")
+			val, err := budgets[2].GetAllowedDisruptions(fakeClock, 10)
+			Expect(err).To(Succeed())
+			Expect(val).To(BeNumerically("==", 1))
+		})
+		It("should use the smaller allowance of nodes and minAvailable", func() {
+			budgets[0].MinAvailable = lo.ToPtr("50")
+			val, err := budgets[0].GetAllowedDisruptions(fakeClock, 100)
+			Expect(err).To(Succeed())
+			Expect(val).To(BeNumerically("==", 10))
+		})
+		It("should return zero when minAvailable exceeds the number of nodes", func() {
+			budgets[2].MinAvailable = lo.ToPtr("20")
+			val, err := budgets[2].GetAllowedDisruptions(fakeClock, 10)
+			Expect(err).To(Succeed())
+			Expect(val).To(BeNumerically("==", 0))
+		})
 	})
 
 	Context("IsActive", func() {
@@ -219,6 +402,31 @@ var _ = Describe("Budgets", func() {
 			Expect(err).To(Succeed())
 			Expect(active).To(BeFalse())
 		})
+		It("should consider a schedule in its time zone", func() {
+			// Saturday 02:00 UTC is still Friday evening in New York
+			fakeClock = clock.NewFakeClock(time.Date(2024, time.June, 15, 2, 0, 0, 0, time.UTC))
+			budgets[0].Schedule = lo.ToPtr("0 0 * * 6")
+			budgets[0].Duration = lo.ToPtr(metav1.Duration{Duration: lo.Must(time.ParseDuration("48h"))})
+			active, err := budgets[0].IsActive(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(active).To(BeTrue())
+
+			budgets[0].TimeZone = lo.ToPtr("America/New_York")
+			active, err = budgets[0].IsActive(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(active).To(BeFalse())
+
+			// Saturday 06:00 UTC is Saturday 02:00 in New York
+			fakeClock.SetTime(time.Date(2024, time.June, 15, 6, 0, 0, 0, time.UTC))
+			active, err = budgets[0].IsActive(fakeClock)
+			Expect(err).To(Succeed())
+			Expect(active).To(BeTrue())
+		})
+		It("should return an error for an invalid time zone", func() {
+			budgets[0].TimeZone = lo.ToPtr("Not/AZone")
+			_, err := budgets[0].IsActive(fakeClock)
+			Expect(err).ToNot(Succeed())
+		})
 		It("should return that a schedule is active when schedule and duration are nil", func() {
 			budgets[0].Schedule = nil
 			budgets[0].Duration = nil
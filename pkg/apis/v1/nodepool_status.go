@@ -19,6 +19,7 @@ package v1
 import (
 	"github.com/awslabs/operatorpkg/status"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -29,6 +30,15 @@ const (
 	ConditionTypeNodeClassReady = "NodeClassReady"
 	// ConditionTypeNodeRegistrationHealthy = "NodeRegistrationHealthy" condition indicates if a misconfiguration exists that is preventing successful node launch/registrations that requires manual investigation
 	ConditionTypeNodeRegistrationHealthy = "NodeRegistrationHealthy"
+	// ConditionTypeDriftPaused = "DriftPaused" condition indicates that drift is paused for this NodePool because the
+	// drift replacements of the current rollout failed to launch or initialize more than the driftFailureThreshold allows
+	ConditionTypeDriftPaused = "DriftPaused"
+	// ConditionTypeDisruptionPaused = "DisruptionPaused" condition indicates that disruption is paused for this NodePool
+	// because spec.disruption.paused is set
+	ConditionTypeDisruptionPaused = "DisruptionPaused"
+	// ConditionTypeDisruptionBudgetsValid = "DisruptionBudgetsValid" condition indicates whether the time zones of the
+	// NodePool's disruption budgets can be loaded. Budgets with time zones that can't be loaded never allow disruptions.
+	ConditionTypeDisruptionBudgetsValid = "DisruptionBudgetsValid"
 )
 
 // NodePoolStatus defines the observed state of NodePool
@@ -44,11 +54,51 @@ type NodePoolStatus struct {
 	// the actual NodeClass Generation, NodeRegistrationHealthy status condition on the NodePool will be reset
 	// +optional
 	NodeClassObservedGeneration int64 `json:"nodeClassObservedGeneration,omitempty"`
+	// ConsecutiveProvisioningFailures is the number of NodeClaims for this NodePool that have failed to launch or register
+	// since a NodeClaim last registered successfully
+	// +optional
+	ConsecutiveProvisioningFailures int64 `json:"consecutiveProvisioningFailures,omitempty"`
+	// WidenedRequirements are the keys of the instance-type requirements that have been relaxed to Exists after
+	// consecutive provisioning failures. The NodePool's spec is left untouched, and the requirements are restored once a
+	// NodeClaim for this NodePool that satisfies the original requirements registers successfully.
+	// +optional
+	WidenedRequirements []string `json:"widenedRequirements,omitempty"`
+	// EscalatedWeight is the weight this NodePool has been raised to as the fallback of a NodePool that exceeded its
+	// consecutive provisioning failures. It takes precedence over spec.weight when higher, and is cleared once a NodeClaim
+	// for the NodePool in escalatedBy registers successfully.
+	// +optional
+	EscalatedWeight *int32 `json:"escalatedWeight,omitempty"`
+	// EscalatedBy is the name of the NodePool whose consecutive provisioning failures raised this NodePool's weight
+	// +optional
+	EscalatedBy string `json:"escalatedBy,omitempty"`
+	// DriftRollout is the progress of replacing the drifted nodes of this NodePool
+	// +optional
+	DriftRollout *DriftRolloutStatus `json:"driftRollout,omitempty"`
 	// Conditions contains signals for health and readiness
 	// +optional
 	Conditions []status.Condition `json:"conditions,omitempty"`
 }
 
+// DriftRolloutStatus is the progress of replacing the drifted nodes of a NodePool. A rollout starts when nodes drift
+// while no drifted nodes remain from the previous rollout.
+type DriftRolloutStatus struct {
+	// Drifted is the number of nodes that have drifted since the rollout started
+	// +optional
+	Drifted int64 `json:"drifted"`
+	// Replaced is the number of drifted nodes that have been disrupted since the rollout started
+	// +optional
+	Replaced int64 `json:"replaced"`
+	// Remaining is the number of drifted nodes that haven't been disrupted yet
+	// +optional
+	Remaining int64 `json:"remaining"`
+	// Failed is the number of drift replacements that failed to launch or initialize since the rollout started
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+	// LastReplacementTime is when a drifted node was last disrupted
+	// +optional
+	LastReplacementTime *metav1.Time `json:"lastReplacementTime,omitempty"`
+}
+
 func (in *NodePool) StatusConditions() status.ConditionSet {
 	return status.NewReadyConditions(
 		ConditionTypeValidationSucceeded,
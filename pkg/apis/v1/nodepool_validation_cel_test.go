@@ -178,6 +178,40 @@ var _ = Describe("CEL/Validation", func() {
 			}}
 			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
 		})
+		It("should succeed when creating a budget with a time zone", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:    "10",
+				Schedule: lo.ToPtr("0 0 * * 6"),
+				Duration: &metav1.Duration{Duration: lo.Must(time.ParseDuration("48h"))},
+				TimeZone: lo.ToPtr("America/New_York"),
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail when creating a budget with a time zone that isn't an IANA time zone name", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:    "10",
+				Schedule: lo.ToPtr("0 0 * * 6"),
+				Duration: &metav1.Duration{Duration: lo.Must(time.ParseDuration("48h"))},
+				TimeZone: lo.ToPtr("America/New York"),
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when creating a budget with the Local time zone", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:    "10",
+				Schedule: lo.ToPtr("0 0 * * 6"),
+				Duration: &metav1.Duration{Duration: lo.Must(time.ParseDuration("48h"))},
+				TimeZone: lo.ToPtr("Local"),
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when creating a budget with a time zone but no schedule", func() {
+			nodePool.Spec.Disruption.Budgets = []Budget{{
+				Nodes:    "10",
+				TimeZone: lo.ToPtr("America/New_York"),
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
 		It("should fail when creating a budget with a negative value int", func() {
 			nodePool.Spec.Disruption.Budgets = []Budget{{
 				Nodes: "-10",
@@ -308,6 +342,98 @@ var _ = Describe("CEL/Validation", func() {
 			}}
 			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
 		})
+		It("should succeed when creating a nodeclass drift policy with a maintenance window", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []NodeClassDriftPolicy{{
+				Reasons:  []string{"SubnetDrift"},
+				Action:   NodeClassDriftActionInMaintenanceWindow,
+				Schedule: lo.ToPtr("0 0 * * *"),
+				Duration: &metav1.Duration{Duration: lo.Must(time.ParseDuration("2h"))},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail when creating a nodeclass drift policy with a maintenance window but no schedule", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []NodeClassDriftPolicy{{
+				Reasons: []string{"SubnetDrift"},
+				Action:  NodeClassDriftActionInMaintenanceWindow,
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when creating a nodeclass drift policy with a schedule that isn't a maintenance window", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []NodeClassDriftPolicy{{
+				Reasons:  []string{"TagsDrift"},
+				Action:   NodeClassDriftActionNever,
+				Schedule: lo.ToPtr("0 0 * * *"),
+				Duration: &metav1.Duration{Duration: lo.Must(time.ParseDuration("2h"))},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when creating a nodeclass drift policy without reasons", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []NodeClassDriftPolicy{{
+				Action: NodeClassDriftActionNever,
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should succeed when ignoring pod disruption budgets by name or by selector", func() {
+			nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets = []PodDisruptionBudgetSelector{
+				{Namespace: "default", Name: "singleton"},
+				{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"misconfigured": "true"}}},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+		})
+		It("should fail when ignoring pod disruption budgets by both name and selector", func() {
+			nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets = []PodDisruptionBudgetSelector{{
+				Name:     "singleton",
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"misconfigured": "true"}},
+			}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+		It("should fail when ignoring pod disruption budgets without a name or selector", func() {
+			nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets = []PodDisruptionBudgetSelector{{Namespace: "default"}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+		})
+	})
+	Context("Kubelet", func() {
+		It("should succeed for a valid kubelet configuration", func() {
+			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{
+				MaxPods:                 lo.ToPtr[int32](110),
+				KubeReserved:            map[string]string{"cpu": "100m", "memory": "256Mi"},
+				SystemReserved:          map[string]string{"ephemeral-storage": "1Gi", "pid": "1000"},
+				EvictionHard:            map[string]string{"memory.available": "5%", "nodefs.available": "10Gi"},
+				EvictionSoft:            map[string]string{"memory.available": "10%"},
+				EvictionSoftGracePeriod: map[string]metav1.Duration{"memory.available": {Duration: time.Minute}},
+			}
+			Expect(env.Client.Create(ctx, nodePool)).To(Succeed())
+			Expect(nodePool.RuntimeValidate(ctx)).To(Succeed())
+		})
+		It("should fail for unsupported reserved resources", func() {
+			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{KubeReserved: map[string]string{"nvidia.com/gpu": "1"}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate(ctx)).ToNot(Succeed())
+		})
+		It("should fail for negative reserved resources", func() {
+			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{SystemReserved: map[string]string{"cpu": "-1"}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate(ctx)).ToNot(Succeed())
+		})
+		It("should fail for unsupported eviction signals", func() {
+			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{EvictionHard: map[string]string{"memory": "5%"}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate(ctx)).ToNot(Succeed())
+		})
+		It("should fail for soft eviction thresholds without a grace period", func() {
+			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{EvictionSoft: map[string]string{"memory.available": "10%"}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate(ctx)).ToNot(Succeed())
+		})
+		It("should fail for soft eviction grace periods without a threshold", func() {
+			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{EvictionSoftGracePeriod: map[string]metav1.Duration{"memory.available": {Duration: time.Minute}}}
+			Expect(env.Client.Create(ctx, nodePool)).ToNot(Succeed())
+			Expect(nodePool.RuntimeValidate(ctx)).ToNot(Succeed())
+		})
+		It("should fail at runtime for invalid eviction threshold values", func() {
+			nodePool.Spec.Template.Spec.Kubelet = &KubeletConfiguration{EvictionHard: map[string]string{"memory.available": "110%"}}
+			Expect(nodePool.RuntimeValidate(ctx)).ToNot(Succeed())
+		})
 	})
 	Context("Taints", func() {
 		It("should succeed for valid taints", func() {
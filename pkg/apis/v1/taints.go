@@ -24,8 +24,9 @@ import (
 
 // Karpenter specific taints
 const (
-	DisruptedTaintKey    = apis.Group + "/disrupted"
-	UnregisteredTaintKey = apis.Group + "/unregistered"
+	DisruptedTaintKey       = apis.Group + "/disrupted"
+	UnregisteredTaintKey    = apis.Group + "/unregistered"
+	DecommissioningTaintKey = apis.Group + "/decommissioning"
 )
 
 var (
@@ -39,4 +40,10 @@ var (
 		Key:    UnregisteredTaintKey,
 		Effect: v1.TaintEffectNoExecute,
 	}
+	// DecommissioningNoScheduleTaint is applied by the termination controller to nodes that are about to be drained, so
+	// that pods and external systems can react before the pods are evicted
+	DecommissioningNoScheduleTaint = v1.Taint{
+		Key:    DecommissioningTaintKey,
+		Effect: v1.TaintEffectNoSchedule,
+	}
 )
@@ -36,11 +36,48 @@ func (in *Budget) DeepCopyInto(out *Budget) {
 		*out = make([]DisruptionReason, len(*in))
 		copy(*out, *in)
 	}
+	if in.DriftReasons != nil {
+		in, out := &in.DriftReasons, &out.DriftReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(string)
+		**out = **in
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	if in.Schedule != nil {
 		in, out := &in.Schedule, &out.Schedule
 		*out = new(string)
 		**out = **in
 	}
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
 	if in.Duration != nil {
 		in, out := &in.Duration, &out.Duration
 		*out = new(metav1.Duration)
@@ -58,6 +95,21 @@ func (in *Budget) DeepCopy() *Budget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecisionPolicy) DeepCopyInto(out *DecisionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecisionPolicy.
+func (in *DecisionPolicy) DeepCopy() *DecisionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DecisionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Disruption) DeepCopyInto(out *Disruption) {
 	*out = *in
@@ -69,6 +121,60 @@ func (in *Disruption) DeepCopyInto(out *Disruption) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NodeClassDriftPolicies != nil {
+		in, out := &in.NodeClassDriftPolicies, &out.NodeClassDriftPolicies
+		*out = make([]NodeClassDriftPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DriftRollout != nil {
+		in, out := &in.DriftRollout, &out.DriftRollout
+		*out = new(DriftRollout)
+		**out = **in
+	}
+	if in.DriftFailureThreshold != nil {
+		in, out := &in.DriftFailureThreshold, &out.DriftFailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AutomatedDriftReasons != nil {
+		in, out := &in.AutomatedDriftReasons, &out.AutomatedDriftReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RebootDriftReasons != nil {
+		in, out := &in.RebootDriftReasons, &out.RebootDriftReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DecisionPolicies != nil {
+		in, out := &in.DecisionPolicies, &out.DecisionPolicies
+		*out = make([]DecisionPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.DriftHashFields != nil {
+		in, out := &in.DriftHashFields, &out.DriftHashFields
+		*out = make([]DriftHashField, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IgnoredPodDisruptionBudgets != nil {
+		in, out := &in.IgnoredPodDisruptionBudgets, &out.IgnoredPodDisruptionBudgets
+		*out = make([]PodDisruptionBudgetSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExcludeNodes != nil {
+		in, out := &in.ExcludeNodes, &out.ExcludeNodes
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Disruption.
@@ -102,6 +208,96 @@ func (in Limits) DeepCopy() Limits {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftRollout) DeepCopyInto(out *DriftRollout) {
+	*out = *in
+	out.SoakDuration = in.SoakDuration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftRollout.
+func (in *DriftRollout) DeepCopy() *DriftRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftRolloutStatus) DeepCopyInto(out *DriftRolloutStatus) {
+	*out = *in
+	if in.LastReplacementTime != nil {
+		in, out := &in.LastReplacementTime, &out.LastReplacementTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftRolloutStatus.
+func (in *DriftRolloutStatus) DeepCopy() *DriftRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeletConfiguration) DeepCopyInto(out *KubeletConfiguration) {
+	*out = *in
+	if in.MaxPods != nil {
+		in, out := &in.MaxPods, &out.MaxPods
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SystemReserved != nil {
+		in, out := &in.SystemReserved, &out.SystemReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KubeReserved != nil {
+		in, out := &in.KubeReserved, &out.KubeReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionHard != nil {
+		in, out := &in.EvictionHard, &out.EvictionHard
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionSoft != nil {
+		in, out := &in.EvictionSoft, &out.EvictionSoft
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionSoftGracePeriod != nil {
+		in, out := &in.EvictionSoftGracePeriod, &out.EvictionSoftGracePeriod
+		*out = make(map[string]metav1.Duration, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeletConfiguration.
+func (in *KubeletConfiguration) DeepCopy() *KubeletConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeletConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NillableDuration) DeepCopyInto(out *NillableDuration) {
 	*out = *in
@@ -221,7 +417,34 @@ func (in *NodeClaimSpec) DeepCopyInto(out *NodeClaimSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.TerminationGracePeriodOverrides != nil {
+		in, out := &in.TerminationGracePeriodOverrides, &out.TerminationGracePeriodOverrides
+		*out = make([]TerminationGracePeriodOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.ExpireAfter.DeepCopyInto(&out.ExpireAfter)
+	if in.ExpireAfterCost != nil {
+		in, out := &in.ExpireAfterCost, &out.ExpireAfterCost
+		*out = new(string)
+		**out = **in
+	}
+	if in.RegistrationTimeout != nil {
+		in, out := &in.RegistrationTimeout, &out.RegistrationTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.InitializationTimeout != nil {
+		in, out := &in.InitializationTimeout, &out.InitializationTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Kubelet != nil {
+		in, out := &in.Kubelet, &out.Kubelet
+		*out = new(KubeletConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimSpec.
@@ -259,6 +482,22 @@ func (in *NodeClaimStatus) DeepCopyInto(out *NodeClaimStatus) {
 		}
 	}
 	in.LastPodEventTime.DeepCopyInto(&out.LastPodEventTime)
+	if in.TerminationDeadline != nil {
+		in, out := &in.TerminationDeadline, &out.TerminationDeadline
+		*out = (*in).DeepCopy()
+	}
+	if in.LaunchedAt != nil {
+		in, out := &in.LaunchedAt, &out.LaunchedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.RegisteredAt != nil {
+		in, out := &in.RegisteredAt, &out.RegisteredAt
+		*out = (*in).DeepCopy()
+	}
+	if in.InitializedAt != nil {
+		in, out := &in.InitializedAt, &out.InitializedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimStatus.
@@ -322,7 +561,34 @@ func (in *NodeClaimTemplateSpec) DeepCopyInto(out *NodeClaimTemplateSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.TerminationGracePeriodOverrides != nil {
+		in, out := &in.TerminationGracePeriodOverrides, &out.TerminationGracePeriodOverrides
+		*out = make([]TerminationGracePeriodOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.ExpireAfter.DeepCopyInto(&out.ExpireAfter)
+	if in.ExpireAfterCost != nil {
+		in, out := &in.ExpireAfterCost, &out.ExpireAfterCost
+		*out = new(string)
+		**out = **in
+	}
+	if in.RegistrationTimeout != nil {
+		in, out := &in.RegistrationTimeout, &out.RegistrationTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.InitializationTimeout != nil {
+		in, out := &in.InitializationTimeout, &out.InitializationTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Kubelet != nil {
+		in, out := &in.Kubelet, &out.Kubelet
+		*out = new(KubeletConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClaimTemplateSpec.
@@ -335,6 +601,36 @@ func (in *NodeClaimTemplateSpec) DeepCopy() *NodeClaimTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeClassDriftPolicy) DeepCopyInto(out *NodeClassDriftPolicy) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(string)
+		**out = **in
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeClassDriftPolicy.
+func (in *NodeClassDriftPolicy) DeepCopy() *NodeClassDriftPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeClassDriftPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeClassReference) DeepCopyInto(out *NodeClassReference) {
 	*out = *in
@@ -431,6 +727,11 @@ func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.ZoneSpread != nil {
+		in, out := &in.ZoneSpread, &out.ZoneSpread
+		*out = new(ZoneSpread)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSpec.
@@ -458,6 +759,21 @@ func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.WidenedRequirements != nil {
+		in, out := &in.WidenedRequirements, &out.WidenedRequirements
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EscalatedWeight != nil {
+		in, out := &in.EscalatedWeight, &out.EscalatedWeight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DriftRollout != nil {
+		in, out := &in.DriftRollout, &out.DriftRollout
+		*out = new(DriftRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]status.Condition, len(*in))
@@ -527,6 +843,26 @@ func (in *ObjectMeta) DeepCopy() *ObjectMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSelector) DeepCopyInto(out *PodDisruptionBudgetSelector) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSelector.
+func (in *PodDisruptionBudgetSelector) DeepCopy() *PodDisruptionBudgetSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
 	*out = *in
@@ -548,3 +884,34 @@ func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TerminationGracePeriodOverride) DeepCopyInto(out *TerminationGracePeriodOverride) {
+	*out = *in
+	in.TerminationGracePeriod.DeepCopyInto(&out.TerminationGracePeriod)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerminationGracePeriodOverride.
+func (in *TerminationGracePeriodOverride) DeepCopy() *TerminationGracePeriodOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(TerminationGracePeriodOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneSpread) DeepCopyInto(out *ZoneSpread) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneSpread.
+func (in *ZoneSpread) DeepCopy() *ZoneSpread {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneSpread)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DisruptionCommandPhase is the orchestration state of an in-flight disruption command
+type DisruptionCommandPhase string
+
+const (
+	DisruptionCommandPhaseLaunching   DisruptionCommandPhase = "Launching"
+	DisruptionCommandPhaseTerminating DisruptionCommandPhase = "Terminating"
+)
+
+// DisruptionCommandSpec captures an in-flight disruption command so that it can be resumed if Karpenter restarts before
+// the command completes
+type DisruptionCommandSpec struct {
+	// Reason is the disruption reason that produced the command (e.g. Underutilized, Empty, Drifted)
+	// +required
+	Reason string `json:"reason"`
+	// Class is the disruption class of the command, either graceful or eventual
+	// +required
+	Class string `json:"class"`
+	// ConsolidationType is the consolidation method that produced the command, if it was produced by consolidation
+	// +optional
+	ConsolidationType string `json:"consolidationType,omitempty"`
+	// Candidates are the nodes that are being disrupted by the command
+	// +required
+	Candidates []DisruptionCommandCandidate `json:"candidates"`
+	// Replacements are the NodeClaims that were launched to replace the candidates
+	// +optional
+	Replacements []DisruptionCommandReplacement `json:"replacements,omitempty"`
+	// Reboot is true if the command reboots its candidates in place instead of deleting them
+	// +optional
+	Reboot bool `json:"reboot,omitempty"`
+	// StartTime is when the command was computed by the disruption controller
+	// +required
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// DisruptionCommandCandidate is a node that is being disrupted by a disruption command
+type DisruptionCommandCandidate struct {
+	// NodeClaim is the name of the candidate NodeClaim
+	// +required
+	NodeClaim string `json:"nodeClaim"`
+	// ProviderID is the provider ID of the candidate NodeClaim
+	// +required
+	ProviderID string `json:"providerID"`
+}
+
+// DisruptionCommandReplacement is a NodeClaim that was launched by a disruption command
+type DisruptionCommandReplacement struct {
+	// NodeClaim is the name of the replacement NodeClaim
+	// +required
+	NodeClaim string `json:"nodeClaim"`
+	// NodePool is the name of the NodePool that owns the replacement NodeClaim
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
+}
+
+// DisruptionCommandStatus defines the progress of the disruption command
+type DisruptionCommandStatus struct {
+	// Phase is Launching while the command waits for its replacements to initialize, and Terminating once the
+	// candidates are being deleted
+	// +kubebuilder:validation:Enum:={Launching,Terminating}
+	// +optional
+	Phase DisruptionCommandPhase `json:"phase,omitempty"`
+}
+
+// DisruptionCommand is a disruption command that Karpenter is executing. It's removed once the command completes.
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=disruptioncommands,scope=Cluster,categories=karpenter
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".spec.reason",description=""
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:subresource:status
+type DisruptionCommand struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DisruptionCommandSpec   `json:"spec"`
+	Status DisruptionCommandStatus `json:"status,omitempty"`
+}
+
+// DisruptionCommandList contains a list of DisruptionCommands
+// +kubebuilder:object:root=true
+type DisruptionCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DisruptionCommand `json:"items"`
+}
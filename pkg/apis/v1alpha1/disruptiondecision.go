@@ -0,0 +1,165 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DisruptionDecisionPhase is the execution state of the disruption command that a DisruptionDecision records
+type DisruptionDecisionPhase string
+
+const (
+	DisruptionDecisionPhaseExecuting DisruptionDecisionPhase = "Executing"
+	DisruptionDecisionPhaseSucceeded DisruptionDecisionPhase = "Succeeded"
+	DisruptionDecisionPhaseFailed    DisruptionDecisionPhase = "Failed"
+	DisruptionDecisionPhaseSkipped   DisruptionDecisionPhase = "Skipped"
+	DisruptionDecisionPhaseDryRun    DisruptionDecisionPhase = "DryRun"
+)
+
+// EvictionPrecheckOutcome is the result of the dry-run eviction pre-check of a disruption command
+type EvictionPrecheckOutcome string
+
+const (
+	EvictionPrecheckOutcomePassed     EvictionPrecheckOutcome = "Passed"
+	EvictionPrecheckOutcomeDowngraded EvictionPrecheckOutcome = "Downgraded"
+	EvictionPrecheckOutcomeSkipped    EvictionPrecheckOutcome = "Skipped"
+)
+
+// DisruptionDecisionSpec captures the details of a disruption command at the time that it was executed
+type DisruptionDecisionSpec struct {
+	// CommandID is the unique identifier of the disruption command
+	// +required
+	CommandID string `json:"commandID"`
+	// Reason is the disruption reason that produced the command (e.g. Underutilized, Empty, Drifted)
+	// +required
+	Reason string `json:"reason"`
+	// Decision is the action taken by the command, either delete, replace or reboot
+	// +required
+	Decision string `json:"decision"`
+	// ConsolidationType is the consolidation method that produced the command, if it was produced by consolidation
+	// +optional
+	ConsolidationType string `json:"consolidationType,omitempty"`
+	// Candidates are the nodes that were disrupted by the command
+	// +required
+	Candidates []DisruptionDecisionCandidate `json:"candidates"`
+	// Replacements are the NodeClaims that were launched to replace the candidates
+	// +optional
+	Replacements []DisruptionDecisionReplacement `json:"replacements,omitempty"`
+	// PodCount is the number of reschedulable pods that were running on the candidates
+	// +optional
+	PodCount int64 `json:"podCount,omitempty"`
+	// EstimatedSavings is the estimated difference in hourly price between the candidates and their replacements.
+	// It is omitted when the price of the candidates couldn't be determined.
+	// +optional
+	EstimatedSavings string `json:"estimatedSavings,omitempty"`
+	// StartTime is when the command was computed by the disruption controller
+	// +required
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// DisruptionDecisionCandidate is a node that was disrupted by a disruption command
+type DisruptionDecisionCandidate struct {
+	// NodeClaim is the name of the disrupted NodeClaim
+	// +required
+	NodeClaim string `json:"nodeClaim"`
+	// Node is the name of the disrupted Node
+	// +optional
+	Node string `json:"node,omitempty"`
+	// NodePool is the name of the NodePool that owns the disrupted NodeClaim
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
+	// InstanceType is the instance type of the disrupted node
+	// +optional
+	InstanceType string `json:"instanceType,omitempty"`
+	// CapacityType is the capacity type of the disrupted node
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+	// Zone is the zone of the disrupted node
+	// +optional
+	Zone string `json:"zone,omitempty"`
+}
+
+// DisruptionDecisionReplacement is a NodeClaim that was launched by a disruption command
+type DisruptionDecisionReplacement struct {
+	// NodeClaim is the name of the replacement NodeClaim
+	// +optional
+	NodeClaim string `json:"nodeClaim,omitempty"`
+	// CapacityType is the capacity type that the replacement was launched with
+	// +optional
+	CapacityType string `json:"capacityType,omitempty"`
+	// InstanceTypes are the cheapest instance types that the replacement could launch as
+	// +kubebuilder:validation:MaxItems:=20
+	// +optional
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+}
+
+// DisruptionDecisionStatus defines the outcome of the disruption command
+type DisruptionDecisionStatus struct {
+	// Phase is the execution state of the disruption command
+	// +kubebuilder:validation:Enum:={Executing,Succeeded,Failed,Skipped,DryRun}
+	// +optional
+	Phase DisruptionDecisionPhase `json:"phase,omitempty"`
+	// CompletionTime is when the disruption command succeeded or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message describes why the disruption command failed or was skipped
+	// +optional
+	Message string `json:"message,omitempty"`
+	// EvictionPrecheck is the outcome of the dry-run evictions issued for the command's pods before it was executed
+	// +optional
+	EvictionPrecheck *EvictionPrecheck `json:"evictionPrecheck,omitempty"`
+}
+
+// EvictionPrecheck records which pods of a disruption command would have been denied eviction
+type EvictionPrecheck struct {
+	// Outcome is Passed when every pod can be evicted, Downgraded when the candidates with pods that can't be
+	// evicted were removed from the command, and Skipped when the command wasn't executed
+	// +kubebuilder:validation:Enum:={Passed,Downgraded,Skipped}
+	// +required
+	Outcome EvictionPrecheckOutcome `json:"outcome"`
+	// BlockedPods are the pods, in namespace/name form, whose eviction would have been denied
+	// +kubebuilder:validation:MaxItems:=20
+	// +optional
+	BlockedPods []string `json:"blockedPods,omitempty"`
+}
+
+// DisruptionDecision is an audit record of a disruption command executed by Karpenter
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=disruptiondecisions,scope=Cluster,categories=karpenter,shortName=decisions
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".spec.reason",description=""
+// +kubebuilder:printcolumn:name="Decision",type="string",JSONPath=".spec.decision",description=""
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="Savings",type="string",JSONPath=".spec.estimatedSavings",priority=1,description=""
+// +kubebuilder:subresource:status
+type DisruptionDecision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DisruptionDecisionSpec   `json:"spec"`
+	Status DisruptionDecisionStatus `json:"status,omitempty"`
+}
+
+// DisruptionDecisionList contains a list of DisruptionDecisions
+// +kubebuilder:object:root=true
+type DisruptionDecisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DisruptionDecision `json:"items"`
+}
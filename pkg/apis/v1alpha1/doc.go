@@ -32,6 +32,12 @@ func init() {
 	gv := schema.GroupVersion{Group: apis.Group, Version: "v1alpha1"}
 	v1.AddToGroupVersion(scheme.Scheme, gv)
 	scheme.Scheme.AddKnownTypes(gv,
+		&DisruptionCommand{},
+		&DisruptionCommandList{},
+		&DisruptionDecision{},
+		&DisruptionDecisionList{},
+		&MaintenanceWindow{},
+		&MaintenanceWindowList{},
 		&NodeOverlay{},
 		&NodeOverlayList{},
 	)
@@ -0,0 +1,81 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+)
+
+// MaintenanceWindowSpec defines when the nodes selected by a MaintenanceWindow can be disrupted
+type MaintenanceWindowSpec struct {
+	// Schedule specifies when the maintenance window opens, following
+	// the upstream cronjob syntax. Timezones are not supported.
+	// +kubebuilder:validation:Pattern:=`^(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((.+)\s(.+)\s(.+)\s(.+)\s(.+))$`
+	// +required
+	Schedule string `json:"schedule"`
+	// Duration determines how long the maintenance window stays open after each Schedule hit.
+	// Only minutes and hours are accepted, as cron does not work in seconds.
+	// This regex has an optional 0s at the end since the duration.String() always adds
+	// a 0s at the end.
+	// +kubebuilder:validation:Pattern=`^((([0-9]+(h|m))|([0-9]+h[0-9]+m))(0s)?)$`
+	// +kubebuilder:validation:Type="string"
+	// +required
+	Duration metav1.Duration `json:"duration"`
+	// NodeSelector selects the nodes that the maintenance window applies to by their labels.
+	// If omitted, the maintenance window applies to every node.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+}
+
+// MaintenanceWindow restricts the voluntary disruption of the nodes that it selects to the times when it's open.
+// Nodes that are selected by multiple MaintenanceWindows can be disrupted when any of them is open.
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=maintenancewindows,scope=Cluster,categories=karpenter
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description=""
+// +kubebuilder:printcolumn:name="Duration",type="string",JSONPath=".spec.duration",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+type MaintenanceWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MaintenanceWindowSpec `json:"spec"`
+}
+
+// MaintenanceWindowList contains a list of MaintenanceWindows
+// +kubebuilder:object:root=true
+type MaintenanceWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MaintenanceWindow `json:"items"`
+}
+
+// IsOpen walks back in time the duration of the maintenance window,
+// and checks if the next time the schedule will hit is before the current time.
+func (in *MaintenanceWindow) IsOpen(c clock.Clock) (bool, error) {
+	schedule, err := cron.ParseStandard(fmt.Sprintf("TZ=UTC %s", in.Spec.Schedule))
+	if err != nil {
+		return false, fmt.Errorf("parsing schedule, %w", err)
+	}
+	checkPoint := c.Now().UTC().Add(-in.Spec.Duration.Duration)
+	nextHit := schedule.Next(checkPoint)
+	return !nextHit.After(c.Now().UTC()), nil
+}
@@ -68,6 +68,18 @@ type CloudProvider struct {
 	Drifted                   cloudprovider.DriftReason
 	NodeClassGroupVersionKind []schema.GroupVersionKind
 	RepairPolicy              []cloudprovider.RepairPolicy
+	// InterruptionsChan receives the interruptions that are surfaced through Interruptions
+	InterruptionsChan chan cloudprovider.Interruption
+	// PreTerminatePending makes PreTerminate report that the instances aren't ready to be terminated yet
+	PreTerminatePending bool
+	NextPreTerminateErr error
+	PreTerminateCalls   []*v1.NodeClaim
+	// NextAttestNodeErr is returned by the next AttestNode call
+	NextAttestNodeErr error
+	AttestNodeCalls   []*corev1.Node
+	// NextRebootErr is returned by the next Reboot call
+	NextRebootErr error
+	RebootCalls   []*v1.NodeClaim
 }
 
 func NewCloudProvider() *CloudProvider {
@@ -76,6 +88,7 @@ func NewCloudProvider() *CloudProvider {
 		CreatedNodeClaims:        map[string]*v1.NodeClaim{},
 		InstanceTypesForNodePool: map[string][]*cloudprovider.InstanceType{},
 		ErrorsForNodePool:        map[string]error{},
+		InterruptionsChan:        make(chan cloudprovider.Interruption, 100),
 	}
 }
 
@@ -95,6 +108,14 @@ func (c *CloudProvider) Reset() {
 	c.DeleteCalls = []*v1.NodeClaim{}
 	c.GetCalls = nil
 	c.Drifted = ""
+	c.InterruptionsChan = make(chan cloudprovider.Interruption, 100)
+	c.PreTerminatePending = false
+	c.NextPreTerminateErr = nil
+	c.PreTerminateCalls = nil
+	c.NextAttestNodeErr = nil
+	c.AttestNodeCalls = nil
+	c.NextRebootErr = nil
+	c.RebootCalls = nil
 	c.NodeClassGroupVersionKind = []schema.GroupVersionKind{
 		{
 			Group:   "",
@@ -111,6 +132,51 @@ func (c *CloudProvider) Reset() {
 	}
 }
 
+func (c *CloudProvider) Interruptions() <-chan cloudprovider.Interruption {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.InterruptionsChan
+}
+
+func (c *CloudProvider) PreTerminate(_ context.Context, nodeClaim *v1.NodeClaim) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.PreTerminateCalls = append(c.PreTerminateCalls, nodeClaim)
+	if c.NextPreTerminateErr != nil {
+		tempErr := c.NextPreTerminateErr
+		c.NextPreTerminateErr = nil
+		return false, tempErr
+	}
+	return !c.PreTerminatePending, nil
+}
+
+func (c *CloudProvider) AttestNode(_ context.Context, _ *v1.NodeClaim, node *corev1.Node) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.AttestNodeCalls = append(c.AttestNodeCalls, node)
+	if c.NextAttestNodeErr != nil {
+		tempErr := c.NextAttestNodeErr
+		c.NextAttestNodeErr = nil
+		return tempErr
+	}
+	return nil
+}
+
+func (c *CloudProvider) Reboot(_ context.Context, nodeClaim *v1.NodeClaim) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.RebootCalls = append(c.RebootCalls, nodeClaim)
+	if c.NextRebootErr != nil {
+		tempErr := c.NextRebootErr
+		c.NextRebootErr = nil
+		return tempErr
+	}
+	return nil
+}
+
 //nolint:gocyclo
 func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
 	c.mu.Lock()
@@ -175,6 +241,8 @@ func (c *CloudProvider) Create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v
 	for _, req := range offering.Requirements {
 		labels[req.Key] = req.Any()
 	}
+	// Propagate the offering's attributes so that pods selecting on them can schedule to the node
+	labels = lo.Assign(labels, offering.Attributes)
 
 	created := &v1.NodeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -218,6 +286,10 @@ func (c *CloudProvider) List(_ context.Context) ([]*v1.NodeClaim, error) {
 	}), nil
 }
 
+func (c *CloudProvider) ListClusterInstances(ctx context.Context) ([]*v1.NodeClaim, error) {
+	return c.List(ctx)
+}
+
 func (c *CloudProvider) GetInstanceTypes(_ context.Context, np *v1.NodePool) ([]*cloudprovider.InstanceType, error) {
 	if np != nil {
 		if err, ok := c.ErrorsForNodePool[np.Name]; ok {
@@ -127,6 +127,12 @@ func NewInstanceTypeWithCustomRequirement(options InstanceTypeOptions, customReq
 		scheduling.NewRequirement(ExoticInstanceLabelKey, corev1.NodeSelectorOpDoesNotExist),
 		scheduling.NewRequirement(IntegerInstanceLabelKey, corev1.NodeSelectorOpIn, fmt.Sprint(options.Resources.Cpu().Value())),
 	)
+	// Offerings that span regions constrain the instance type to their regions
+	if regions := lo.Uniq(lo.FilterMap(options.Offerings.Available(), func(o *cloudprovider.Offering, _ int) (string, bool) {
+		return o.Region(), o.Region() != ""
+	})); len(regions) > 0 {
+		requirements.Add(scheduling.NewRequirement(corev1.LabelTopologyRegion, corev1.NodeSelectorOpIn, regions...))
+	}
 	if customReq != nil {
 		requirements.Add(customReq)
 	}
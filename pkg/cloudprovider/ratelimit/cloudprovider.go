@@ -0,0 +1,167 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	opmetrics "github.com/awslabs/operatorpkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/utils/clock"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+const (
+	metricLabelCall = "call"
+
+	CallLaunch    = "launch"
+	CallDescribe  = "describe"
+	CallTerminate = "terminate"
+)
+
+var (
+	CallsTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "cloudprovider",
+			Name:      "nodepool_calls_total",
+			Help:      "Number of launch, describe and terminate cloud provider calls made on behalf of a NodePool. Labeled by NodePool and call.",
+		},
+		[]string{
+			metrics.NodePoolLabel,
+			metricLabelCall,
+		},
+	)
+	ThrottledTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "cloudprovider",
+			Name:      "nodepool_throttled_total",
+			Help:      "Number of cloud provider calls that were throttled because a NodePool exceeded its API rate budget. Labeled by NodePool and call.",
+		},
+		[]string{
+			metrics.NodePoolLabel,
+			metricLabelCall,
+		},
+	)
+)
+
+// decorator implements CloudProvider
+var _ cloudprovider.CloudProvider = (*decorator)(nil)
+
+type decorator struct {
+	cloudprovider.CloudProvider
+	clock clock.Clock
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// Decorate returns a new `CloudProvider` instance that will delegate all method calls to the argument, `cloudProvider`,
+// after charging the launch (Create), describe (IsDrifted) and terminate (Delete) calls against a rate budget for the
+// NodePool that owns the NodeClaim. Calls made once a NodePool has exhausted its budget are throttled and return a
+// ThrottledError so that a single NodePool's churn can't starve the other NodePools of the account's rate limits.
+// Calls that can't be attributed to a NodePool, like Get and List, are never throttled.
+func Decorate(cloudProvider cloudprovider.CloudProvider, clk clock.Clock) cloudprovider.CloudProvider {
+	return &decorator{CloudProvider: cloudProvider, clock: clk, limiters: map[string]*rate.Limiter{}}
+}
+
+func (d *decorator) Create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
+	if err := d.charge(ctx, nodeClaim, CallLaunch); err != nil {
+		return nil, cloudprovider.NewCreateError(err, "APIRateThrottled", err.Error())
+	}
+	return d.CloudProvider.Create(ctx, nodeClaim)
+}
+
+func (d *decorator) Delete(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	if err := d.charge(ctx, nodeClaim, CallTerminate); err != nil {
+		return err
+	}
+	return d.CloudProvider.Delete(ctx, nodeClaim)
+}
+
+func (d *decorator) IsDrifted(ctx context.Context, nodeClaim *v1.NodeClaim) (cloudprovider.DriftReason, error) {
+	if err := d.charge(ctx, nodeClaim, CallDescribe); err != nil {
+		return "", err
+	}
+	return d.CloudProvider.IsDrifted(ctx, nodeClaim)
+}
+
+// charge takes a token from the budget of the NodeClaim's NodePool, returning a ThrottledError if the budget is exhausted
+func (d *decorator) charge(ctx context.Context, nodeClaim *v1.NodeClaim, call string) error {
+	nodePoolName, ok := nodeClaim.Labels[v1.NodePoolLabelKey]
+	if !ok {
+		return nil
+	}
+	limiter := d.limiter(ctx, nodePoolName)
+	if limiter == nil {
+		return nil
+	}
+	labels := map[string]string{metrics.NodePoolLabel: nodePoolName, metricLabelCall: call}
+	if !limiter.AllowN(d.clock.Now(), 1) {
+		ThrottledTotal.Inc(labels)
+		return &ThrottledError{NodePoolName: nodePoolName, Call: call}
+	}
+	CallsTotal.Inc(labels)
+	return nil
+}
+
+// limiter returns the rate limiter for the NodePool, or nil if per-NodePool budgeting is disabled. Limiters are rebuilt
+// if the configured rate or burst changes.
+func (d *decorator) limiter(ctx context.Context, nodePoolName string) *rate.Limiter {
+	qps, burst := options.FromContext(ctx).NodePoolAPIQPS, options.FromContext(ctx).NodePoolAPIBurst
+	if qps <= 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	limiter, ok := d.limiters[nodePoolName]
+	if !ok || limiter.Limit() != rate.Limit(qps) || limiter.Burst() != burst {
+		limiter = rate.NewLimiter(rate.Limit(qps), burst)
+		d.limiters[nodePoolName] = limiter
+	}
+	return limiter
+}
+
+// ThrottledError is returned when a NodePool has exhausted its cloud provider API rate budget
+type ThrottledError struct {
+	NodePoolName string
+	Call         string
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("nodepool %q exceeded its api rate budget for %s calls", e.NodePoolName, e.Call)
+}
+
+func IsThrottledError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var tErr *ThrottledError
+	return errors.As(err, &tErr)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/ratelimit"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	ctx           context.Context
+	fakeClock     *clock.FakeClock
+	cloudProvider *fake.CloudProvider
+	decorated     cloudprovider.CloudProvider
+)
+
+func TestRateLimit(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RateLimit")
+}
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolAPIQPS: lo.ToPtr(1), NodePoolAPIBurst: lo.ToPtr(2)}))
+	fakeClock = clock.NewFakeClock(time.Now())
+	cloudProvider = fake.NewCloudProvider()
+	decorated = ratelimit.Decorate(cloudProvider, fakeClock)
+})
+
+func nodeClaimFor(nodePoolName string) *v1.NodeClaim {
+	return test.NodeClaim(v1.NodeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{v1.NodePoolLabelKey: nodePoolName},
+		},
+	})
+}
+
+var _ = Describe("RateLimit", func() {
+	It("should throttle calls once a NodePool exceeds its burst", func() {
+		nodeClaim := nodeClaimFor("default")
+		for range 2 {
+			_, err := decorated.IsDrifted(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		_, err := decorated.IsDrifted(ctx, nodeClaim)
+		Expect(ratelimit.IsThrottledError(err)).To(BeTrue())
+	})
+	It("should not forward throttled calls to the cloud provider", func() {
+		nodeClaim := nodeClaimFor("default")
+		for range 3 {
+			_ = decorated.Delete(ctx, nodeClaim)
+		}
+		Expect(cloudProvider.DeleteCalls).To(HaveLen(2))
+	})
+	It("should surface throttled launches as create errors", func() {
+		nodeClaim := nodeClaimFor("default")
+		for range 2 {
+			_, err := decorated.IsDrifted(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		_, err := decorated.Create(ctx, nodeClaim)
+		Expect(ratelimit.IsThrottledError(err)).To(BeTrue())
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+	})
+	It("should refill the budget over time", func() {
+		nodeClaim := nodeClaimFor("default")
+		for range 2 {
+			_, err := decorated.IsDrifted(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		fakeClock.Step(time.Second)
+		_, err := decorated.IsDrifted(ctx, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("should budget each NodePool independently", func() {
+		for range 2 {
+			_, err := decorated.IsDrifted(ctx, nodeClaimFor("noisy"))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		_, err := decorated.IsDrifted(ctx, nodeClaimFor("noisy"))
+		Expect(ratelimit.IsThrottledError(err)).To(BeTrue())
+		_, err = decorated.IsDrifted(ctx, nodeClaimFor("quiet"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("should not throttle NodeClaims without a NodePool", func() {
+		nodeClaim := test.NodeClaim()
+		for range 5 {
+			_, err := decorated.IsDrifted(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+	It("should not throttle when per-NodePool budgeting is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodePoolAPIQPS: lo.ToPtr(0)}))
+		nodeClaim := nodeClaimFor("default")
+		for range 20 {
+			_, err := decorated.IsDrifted(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,57 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonly
+
+import (
+	"context"
+	"errors"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/utils/readonly"
+)
+
+// ReadOnlyError is returned for calls that would mutate the cloud provider while Karpenter is in read-only mode
+var ReadOnlyError = errors.New("karpenter is in read-only mode")
+
+// decorator implements CloudProvider
+var _ cloudprovider.CloudProvider = (*decorator)(nil)
+
+type decorator struct {
+	cloudprovider.CloudProvider
+}
+
+// Decorate returns a new `CloudProvider` instance that will delegate all method calls to the argument, `cloudProvider`,
+// except for Create and Delete while Karpenter is in read-only mode. This guarantees that no capacity is created or
+// deleted in read-only mode, even by the controllers that don't check for it themselves.
+func Decorate(cloudProvider cloudprovider.CloudProvider) cloudprovider.CloudProvider {
+	return &decorator{CloudProvider: cloudProvider}
+}
+
+func (d *decorator) Create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
+	if readonly.Enabled(ctx) {
+		return nil, cloudprovider.NewCreateError(ReadOnlyError, "ReadOnlyMode", ReadOnlyError.Error())
+	}
+	return d.CloudProvider.Create(ctx, nodeClaim)
+}
+
+func (d *decorator) Delete(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	if readonly.Enabled(ctx) {
+		return ReadOnlyError
+	}
+	return d.CloudProvider.Delete(ctx, nodeClaim)
+}
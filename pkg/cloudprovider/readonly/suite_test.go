@@ -0,0 +1,117 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonly_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/readonly"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	readonlyutils "sigs.k8s.io/karpenter/pkg/utils/readonly"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	ctx           context.Context
+	cloudProvider *fake.CloudProvider
+	decorated     cloudprovider.CloudProvider
+	modeFile      string
+)
+
+func TestReadOnly(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ReadOnly")
+}
+
+var _ = BeforeEach(func() {
+	modeFile = filepath.Join(GinkgoT().TempDir(), "read-only")
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ReadOnlyModeFile: lo.ToPtr(modeFile)}))
+	cloudProvider = fake.NewCloudProvider()
+	decorated = readonly.Decorate(cloudProvider)
+})
+
+var _ = Describe("ReadOnly", func() {
+	It("should forward calls when the read-only mode file doesn't exist", func() {
+		_, err := decorated.Create(ctx, test.NodeClaim())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+	})
+	It("should forward calls when the read-only mode file isn't true", func() {
+		Expect(os.WriteFile(modeFile, []byte("false"), 0600)).To(Succeed())
+		_, err := decorated.Create(ctx, test.NodeClaim())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+	})
+	It("should block creates and deletes when the read-only mode file is true", func() {
+		Expect(os.WriteFile(modeFile, []byte("true\n"), 0600)).To(Succeed())
+		_, err := decorated.Create(ctx, test.NodeClaim())
+		var createErr *cloudprovider.CreateError
+		Expect(errors.As(err, &createErr)).To(BeTrue())
+		Expect(createErr.ConditionReason).To(Equal("ReadOnlyMode"))
+		Expect(decorated.Delete(ctx, test.NodeClaim())).To(MatchError(readonly.ReadOnlyError))
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		Expect(cloudProvider.DeleteCalls).To(BeEmpty())
+	})
+	It("should switch modes at runtime", func() {
+		Expect(os.WriteFile(modeFile, []byte("true"), 0600)).To(Succeed())
+		_, err := decorated.Create(ctx, test.NodeClaim())
+		Expect(err).To(HaveOccurred())
+
+		Expect(os.WriteFile(modeFile, []byte("false"), 0600)).To(Succeed())
+		_, err = decorated.Create(ctx, test.NodeClaim())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+	})
+	It("should block creates and deletes when the read-only mode file can't be parsed", func() {
+		Expect(os.WriteFile(modeFile, []byte("yes"), 0600)).To(Succeed())
+		_, err := decorated.Create(ctx, test.NodeClaim())
+		Expect(err).To(HaveOccurred())
+		Expect(decorated.Delete(ctx, test.NodeClaim())).To(MatchError(readonly.ReadOnlyError))
+		Expect(cloudProvider.CreateCalls).To(BeEmpty())
+		Expect(cloudProvider.DeleteCalls).To(BeEmpty())
+	})
+	It("should only re-read the read-only mode file once the cache expires", func() {
+		fakeClock := clock.NewFakeClock(time.Now())
+		ctx = readonlyutils.ToContext(ctx, readonlyutils.NewCache(fakeClock))
+		Expect(os.WriteFile(modeFile, []byte("true"), 0600)).To(Succeed())
+		_, err := decorated.Create(ctx, test.NodeClaim())
+		Expect(err).To(HaveOccurred())
+
+		Expect(os.WriteFile(modeFile, []byte("false"), 0600)).To(Succeed())
+		_, err = decorated.Create(ctx, test.NodeClaim())
+		Expect(err).To(HaveOccurred())
+
+		fakeClock.Step(readonlyutils.CacheTTL)
+		_, err = decorated.Create(ctx, test.NodeClaim())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+	})
+})
@@ -25,7 +25,9 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
+	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
 )
 
 func TestCloudProvider(t *testing.T) {
@@ -143,3 +145,36 @@ var _ = Describe("Instance Type", func() {
 type BaseError struct {
 	error
 }
+
+var _ = Describe("Offering", func() {
+	const bandwidthLabel = "example.com/network-bandwidth"
+	BeforeEach(func() {
+		cloudprovider.RegisterOfferingAttributes(bandwidthLabel)
+	})
+	AfterEach(func() {
+		cloudprovider.OfferingAttributeLabels.Delete(bandwidthLabel)
+		karpv1.WellKnownLabels.Delete(bandwidthLabel)
+	})
+	It("should be compatible with requirements that match its attributes", func() {
+		offering := &cloudprovider.Offering{Requirements: scheduling.NewRequirements(), Attributes: map[string]string{bandwidthLabel: "25"}}
+		Expect(offering.IsCompatible(scheduling.NewRequirements(scheduling.NewRequirement(bandwidthLabel, v1.NodeSelectorOpIn, "25", "50")))).To(BeTrue())
+		Expect(offering.IsCompatible(scheduling.NewRequirements(scheduling.NewRequirement(bandwidthLabel, v1.NodeSelectorOpIn, "50")))).To(BeFalse())
+		Expect(offering.IsCompatible(scheduling.NewRequirements(scheduling.NewRequirement(bandwidthLabel, v1.NodeSelectorOpGt, "10")))).To(BeTrue())
+	})
+	It("should treat an undefined attribute as not existing", func() {
+		offering := &cloudprovider.Offering{Requirements: scheduling.NewRequirements()}
+		Expect(offering.IsCompatible(scheduling.NewRequirements(scheduling.NewRequirement(bandwidthLabel, v1.NodeSelectorOpExists)))).To(BeFalse())
+		Expect(offering.IsCompatible(scheduling.NewRequirements(scheduling.NewRequirement(bandwidthLabel, v1.NodeSelectorOpDoesNotExist)))).To(BeTrue())
+		Expect(offering.IsCompatible(scheduling.NewRequirements())).To(BeTrue())
+	})
+	It("should filter offerings by their attributes", func() {
+		offerings := cloudprovider.Offerings{
+			{Requirements: scheduling.NewRequirements(), Attributes: map[string]string{bandwidthLabel: "25"}},
+			{Requirements: scheduling.NewRequirements(), Attributes: map[string]string{bandwidthLabel: "50"}},
+			{Requirements: scheduling.NewRequirements()},
+		}
+		compatible := offerings.Compatible(scheduling.NewRequirements(scheduling.NewRequirement(bandwidthLabel, v1.NodeSelectorOpIn, "50")))
+		Expect(compatible).To(HaveLen(1))
+		Expect(compatible[0].Attributes).To(HaveKeyWithValue(bandwidthLabel, "50"))
+	})
+})
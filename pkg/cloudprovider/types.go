@@ -54,10 +54,30 @@ var (
 	// ReservedCapacityLabels is the set of additional labels that are associated with reserved offerings. Each reserved
 	// offering should define a requirement for these labels, and all other offerings should define a DoesNotExist requirement.
 	ReservedCapacityLabels = sets.New[string]()
+
+	// OfferingAttributeLabels is the set of cloudprovider-specific attributes (e.g. network bandwidth or placement group
+	// support) that offerings may define in their Attributes. These are treated as well known labels, so NodePool
+	// requirements and pod node selectors can match against them. Use RegisterOfferingAttributes to add to this set.
+	OfferingAttributeLabels = sets.New[string]()
 )
 
+// RegisterOfferingAttributes registers the keys of the attributes that the cloudprovider's offerings define. It should
+// be called before any instance types are returned, typically when the cloudprovider is constructed.
+func RegisterOfferingAttributes(keys ...string) {
+	OfferingAttributeLabels.Insert(keys...)
+	v1.WellKnownLabels.Insert(keys...)
+}
+
 type DriftReason string
 
+// Well-known DriftReasons that CloudProviders report when a NodeClaim has drifted from its NodeClass. CloudProviders
+// should prefer these over provider-specific reasons so that NodePools can restrict the drift that Karpenter acts on.
+const (
+	ImageDrifted         DriftReason = "ImageDrifted"
+	SecurityGroupDrifted DriftReason = "SecurityGroupDrifted"
+	UserDataDrifted      DriftReason = "UserDataDrifted"
+)
+
 type RepairPolicy struct {
 	// ConditionType of unhealthy state that is found on the node
 	ConditionType corev1.NodeConditionType
@@ -99,6 +119,69 @@ type CloudProvider interface {
 	GetSupportedNodeClasses() []status.Object
 }
 
+// InterruptionProvider is implemented by CloudProviders that are notified before their instances are interrupted, e.g.
+// when spot capacity is reclaimed or the instance is scheduled for maintenance. Karpenter drains and replaces the
+// NodeClaims of interrupted instances, so that every CloudProvider doesn't need to implement interruption handling.
+type InterruptionProvider interface {
+	// Interruptions returns the channel that the CloudProvider sends the interruptions of its instances to
+	Interruptions() <-chan Interruption
+}
+
+// PreTerminator is implemented by CloudProviders that need to prepare their instances for termination once their
+// nodes have drained, e.g. by deregistering the instances from load balancers and waiting for their connections to
+// drain. Karpenter retries the hook until it completes, up to the pre-terminate timeout.
+type PreTerminator interface {
+	// PreTerminate prepares the NodeClaim's instance for termination, returning true once the instance can be deleted.
+	// It's called repeatedly until then, so it must be idempotent.
+	PreTerminate(context.Context, *v1.NodeClaim) (bool, error)
+}
+
+// NodeAttestor is implemented by CloudProviders that can attest the identity of the Nodes that join the cluster, e.g. by
+// validating an instance identity document that the kubelet presented. Karpenter only registers a Node for a NodeClaim,
+// removing its unregistered taint, once its identity is attested.
+type NodeAttestor interface {
+	// AttestNode verifies that the Node runs on the NodeClaim's instance. It returns a NodeAttestationError if the Node
+	// doesn't, other errors are retried.
+	AttestNode(context.Context, *v1.NodeClaim, *corev1.Node) error
+}
+
+// ClusterInstanceLister is implemented by CloudProviders that can list every instance attributed to the cluster, e.g.
+// through a cluster tag, including the instances that were leaked by a launch that was never recorded on a NodeClaim.
+// Karpenter reports the instances that have no NodeClaim or Node as orphaned, and optionally deletes them.
+type ClusterInstanceLister interface {
+	// ListClusterInstances returns the instances attributed to the cluster as NodeClaims with their provider id, labels
+	// and creation timestamp resolved
+	ListClusterInstances(context.Context) ([]*v1.NodeClaim, error)
+}
+
+// Rebooter is implemented by CloudProviders that can reboot an instance in place. Karpenter reboots drifted NodeClaims
+// instead of replacing them when their NodePool lists the drift reason in its rebootDriftReasons, e.g. for kubelet
+// configuration changes that are applied when the instance boots.
+type Rebooter interface {
+	// Reboot reboots the NodeClaim's instance, applying its current configuration. It returns a NodeClaimNotFoundError
+	// if the instance doesn't exist.
+	Reboot(context.Context, *v1.NodeClaim) error
+}
+
+type InterruptionKind string
+
+// Well-known InterruptionKinds that CloudProviders signal
+const (
+	SpotInterruption     InterruptionKind = "SpotInterruption"
+	ScheduledMaintenance InterruptionKind = "ScheduledMaintenance"
+	InstanceStopping     InterruptionKind = "InstanceStopping"
+	InstanceTerminating  InterruptionKind = "InstanceTerminating"
+)
+
+// Interruption is a CloudProvider signal that an instance is going to be interrupted
+type Interruption struct {
+	// ProviderID of the interrupted instance
+	ProviderID string
+	Kind       InterruptionKind
+	// Deadline is when the CloudProvider expects to interrupt the instance, or zero if it isn't known
+	Deadline time.Time
+}
+
 // InstanceType describes the properties of a potential node (either concrete attributes of an instance of this type
 // or supported options in the case of arrays)
 // +k8s:deepcopy-gen=true
@@ -225,12 +308,12 @@ func (its InstanceTypes) OrderByPrice(reqs scheduling.Requirements) InstanceType
 		jPrice := math.MaxFloat64
 
 		for _, of := range its[i].Offerings {
-			if of.Available && reqs.IsCompatible(of.Requirements, scheduling.AllowUndefinedWellKnownLabels) && of.Price < iPrice {
+			if of.Available && of.IsCompatible(reqs) && of.Price < iPrice {
 				iPrice = of.Price
 			}
 		}
 		for _, of := range its[j].Offerings {
-			if of.Available && reqs.IsCompatible(of.Requirements, scheduling.AllowUndefinedWellKnownLabels) && of.Price < jPrice {
+			if of.Available && of.IsCompatible(reqs) && of.Price < jPrice {
 				jPrice = of.Price
 			}
 		}
@@ -351,9 +434,13 @@ func (i InstanceTypeOverhead) Total() corev1.ResourceList {
 // may be tightly coupled (e.g. the availability of an instance type in some zone is scoped to a capacity type) and
 // these properties are captured with labels in Requirements.
 // Requirements are required to contain the keys v1.CapacityTypeLabelKey and corev1.LabelTopologyZone.
+// CloudProviders whose instance types are offered in multiple regions also include corev1.LabelTopologyRegion.
 // +k8s:deepcopy-gen=true
 type Offering struct {
-	Requirements        scheduling.Requirements
+	Requirements scheduling.Requirements
+	// Attributes are the values of the registered OfferingAttributeLabels for this offering. An offering that doesn't
+	// define a registered attribute is only compatible with requirements that allow the attribute to not exist.
+	Attributes          map[string]string
 	Price               float64
 	Available           bool
 	ReservationCapacity int
@@ -404,6 +491,24 @@ func (o *Offering) IsPriceOverlaid() bool {
 	return o.priceOverlayApplied
 }
 
+// IsCompatible returns whether the offering, including its attributes, is compatible with the requirements
+func (o *Offering) IsCompatible(reqs scheduling.Requirements) bool {
+	if !reqs.IsCompatible(o.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
+		return false
+	}
+	for key := range OfferingAttributeLabels {
+		if !reqs.Has(key) {
+			continue
+		}
+		value, ok := o.Attributes[key]
+		attribute := lo.Ternary(ok, scheduling.NewRequirement(key, corev1.NodeSelectorOpIn, value), scheduling.NewRequirement(key, corev1.NodeSelectorOpDoesNotExist))
+		if reqs.Intersects(scheduling.NewRequirements(attribute)) != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func (o *Offering) CapacityType() string {
 	return o.Requirements.Get(v1.CapacityTypeLabelKey).Any()
 }
@@ -412,6 +517,11 @@ func (o *Offering) Zone() string {
 	return o.Requirements.Get(corev1.LabelTopologyZone).Any()
 }
 
+// Region returns the region of the offering, or "" if the offering doesn't carry a region
+func (o *Offering) Region() string {
+	return o.Requirements.Get(corev1.LabelTopologyRegion).Any()
+}
+
 func (o *Offering) ReservationID() string {
 	return o.Requirements.Get(ReservationIDLabel).Any()
 }
@@ -429,14 +539,14 @@ func (ofs Offerings) Available() Offerings {
 // Compatible returns the offerings based on the passed requirements
 func (ofs Offerings) Compatible(reqs scheduling.Requirements) Offerings {
 	return lo.Filter(ofs, func(offering *Offering, _ int) bool {
-		return reqs.IsCompatible(offering.Requirements, scheduling.AllowUndefinedWellKnownLabels)
+		return offering.IsCompatible(reqs)
 	})
 }
 
 // HasCompatible returns whether there is a compatible offering based on the passed requirements
 func (ofs Offerings) HasCompatible(reqs scheduling.Requirements) bool {
 	for _, of := range ofs {
-		if reqs.IsCompatible(of.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
+		if of.IsCompatible(reqs) {
 			return true
 		}
 	}
@@ -510,6 +620,9 @@ func IgnoreNodeClaimNotFoundError(err error) error {
 // InsufficientCapacityError is an error type returned by CloudProviders when a launch fails due to a lack of capacity from NodeClaim requirements
 type InsufficientCapacityError struct {
 	error
+	// UnavailableOfferings are the offerings that lacked capacity, if the CloudProvider knows them. The launch is
+	// retried with them removed from the NodeClaim's requirements.
+	UnavailableOfferings []UnavailableOffering
 }
 
 func NewInsufficientCapacityError(err error) *InsufficientCapacityError {
@@ -518,6 +631,14 @@ func NewInsufficientCapacityError(err error) *InsufficientCapacityError {
 	}
 }
 
+// NewInsufficientCapacityErrorForOfferings returns an InsufficientCapacityError for the offerings that lacked capacity
+func NewInsufficientCapacityErrorForOfferings(err error, offerings ...UnavailableOffering) *InsufficientCapacityError {
+	return &InsufficientCapacityError{
+		error:                err,
+		UnavailableOfferings: offerings,
+	}
+}
+
 func (e *InsufficientCapacityError) Error() string {
 	return fmt.Sprintf("insufficient capacity, %s", e.error)
 }
@@ -534,6 +655,30 @@ func IsInsufficientCapacityError(err error) bool {
 	return errors.As(err, &icErr)
 }
 
+// UnavailableOfferingsFromError returns the offerings that lacked capacity for an InsufficientCapacityError
+func UnavailableOfferingsFromError(err error) []UnavailableOffering {
+	var icErr *InsufficientCapacityError
+	if !errors.As(err, &icErr) {
+		return nil
+	}
+	return icErr.UnavailableOfferings
+}
+
+// UnavailableOffering identifies an offering that lacked capacity. Empty fields match any value, e.g. an
+// UnavailableOffering that only sets the Zone marks every offering in the zone as unavailable.
+type UnavailableOffering struct {
+	InstanceType string
+	Zone         string
+	CapacityType string
+}
+
+// Matches returns whether the offering of the instance type is unavailable
+func (u UnavailableOffering) Matches(instanceType string, offering *Offering) bool {
+	return (u.InstanceType == "" || u.InstanceType == instanceType) &&
+		(u.Zone == "" || u.Zone == offering.Zone()) &&
+		(u.CapacityType == "" || u.CapacityType == offering.CapacityType())
+}
+
 // NodeClassNotReadyError is an error type returned by CloudProviders when a NodeClass that is used by the launch process doesn't have all its resolved fields
 type NodeClassNotReadyError struct {
 	error
@@ -561,6 +706,34 @@ func IsNodeClassNotReadyError(err error) bool {
 	return errors.As(err, &nrError)
 }
 
+// NodeAttestationError is an error type returned by CloudProviders when a Node that claims a NodeClaim's instance
+// doesn't run on it
+type NodeAttestationError struct {
+	error
+}
+
+func NewNodeAttestationError(err error) *NodeAttestationError {
+	return &NodeAttestationError{
+		error: err,
+	}
+}
+
+func (e *NodeAttestationError) Error() string {
+	return fmt.Sprintf("attesting node, %s", e.error)
+}
+
+func (e *NodeAttestationError) Unwrap() error {
+	return e.error
+}
+
+func IsNodeAttestationError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var naError *NodeAttestationError
+	return errors.As(err, &naError)
+}
+
 // CreateError is an error type returned by CloudProviders when instance creation fails
 type CreateError struct {
 	error
@@ -90,6 +90,13 @@ func (in *Offering) DeepCopyInto(out *Offering) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Offering.
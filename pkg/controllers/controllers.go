@@ -21,6 +21,7 @@ import (
 
 	"github.com/awslabs/operatorpkg/controller"
 	"github.com/awslabs/operatorpkg/object"
+	"github.com/awslabs/operatorpkg/option"
 	"github.com/awslabs/operatorpkg/status"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/samber/lo"
@@ -32,10 +33,14 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/readonly"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	disruptionaudit "sigs.k8s.io/karpenter/pkg/controllers/disruption/audit"
+	disruptionpricing "sigs.k8s.io/karpenter/pkg/controllers/disruption/pricing"
 	metricsnode "sigs.k8s.io/karpenter/pkg/controllers/metrics/node"
 	metricsnodepool "sigs.k8s.io/karpenter/pkg/controllers/metrics/nodepool"
 	metricspod "sigs.k8s.io/karpenter/pkg/controllers/metrics/pod"
+	nodeadoption "sigs.k8s.io/karpenter/pkg/controllers/node/adoption"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
 	nodehydration "sigs.k8s.io/karpenter/pkg/controllers/node/hydration"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
@@ -45,21 +50,29 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/expiration"
 	nodeclaimgarbagecollection "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/garbagecollection"
 	nodeclaimhydration "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/hydration"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/interruption"
 	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/orphan"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/podevents"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/rightsizing"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeoverlay"
 	nodepoolcounter "sigs.k8s.io/karpenter/pkg/controllers/nodepool/counter"
+	nodepooldisruptionpause "sigs.k8s.io/karpenter/pkg/controllers/nodepool/disruptionpause"
+	nodepooldriftrollout "sigs.k8s.io/karpenter/pkg/controllers/nodepool/driftrollout"
 	nodepoolhash "sigs.k8s.io/karpenter/pkg/controllers/nodepool/hash"
+	nodepoolprovisioningfailure "sigs.k8s.io/karpenter/pkg/controllers/nodepool/provisioningfailure"
 	nodepoolreadiness "sigs.k8s.io/karpenter/pkg/controllers/nodepool/readiness"
 	nodepoolregistrationhealth "sigs.k8s.io/karpenter/pkg/controllers/nodepool/registrationhealth"
 	nodepoolvalidation "sigs.k8s.io/karpenter/pkg/controllers/nodepool/validation"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/stream"
 	staticdeprovisioning "sigs.k8s.io/karpenter/pkg/controllers/static/deprovisioning"
 	staticprovisioning "sigs.k8s.io/karpenter/pkg/controllers/static/provisioning"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/utils/shard"
 )
 
 func NewControllers(
@@ -73,35 +86,77 @@ func NewControllers(
 	cluster *state.Cluster,
 	instanceTypeStore *nodeoverlay.InstanceTypeStore,
 ) []controller.Controller {
+	// Capacity is never created or deleted in read-only mode, even by the controllers that don't check for it themselves
+	cloudProvider = readonly.Decorate(cloudProvider)
 	p := provisioning.NewProvisioner(kubeClient, recorder, cloudProvider, cluster, clock)
 	evictionQueue := terminator.NewQueue(kubeClient, recorder)
-	disruptionQueue := disruption.NewQueue(kubeClient, recorder, cluster, clock, p)
 
-	controllers := []controller.Controller{
-		p, evictionQueue, disruptionQueue,
-		disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue),
+	// The cloud provider may reboot drifted instances in place instead of replacing them. Rebooted instances are drained
+	// through the eviction queue first.
+	disruptionOpts := []option.Function[disruption.ControllerOptions]{disruption.WithRateLimitReader(mgr.GetAPIReader())}
+	var disruptionQueueOpts []option.Function[disruption.QueueOptions]
+	if rebooter, ok := overlayUndecoratedCloudProvider.(cloudprovider.Rebooter); ok {
+		disruptionOpts = append(disruptionOpts, disruption.WithRebooter(rebooter))
+		disruptionQueueOpts = append(disruptionQueueOpts, disruption.WithQueueRebooter(rebooter, evictionQueue))
+	}
+	disruptionQueue := disruption.NewQueue(kubeClient, recorder, cluster, clock, p, disruptionQueueOpts...)
+
+	// The cloud provider may need to prepare its instances for termination once their nodes have drained
+	var terminationOpts []option.Function[termination.ControllerOptions]
+	if preTerminator, ok := overlayUndecoratedCloudProvider.(cloudprovider.PreTerminator); ok {
+		terminationOpts = append(terminationOpts, termination.WithPreTerminator(preTerminator))
+	}
+
+	// The cloud provider may attest the identity of nodes before they're registered
+	var lifecycleOpts []option.Function[nodeclaimlifecycle.ControllerOptions]
+	if nodeAttestor, ok := overlayUndecoratedCloudProvider.(cloudprovider.NodeAttestor); ok {
+		lifecycleOpts = append(lifecycleOpts, nodeclaimlifecycle.WithNodeAttestor(nodeAttestor))
+	}
+
+	// The provisioning and disruption loops are split across shards by NodePool, while the rest of the controllers are run
+	// by the leader of the operator
+	sharded := []controller.Controller{
+		p, disruptionQueue,
+		disruption.NewController(clock, kubeClient, p, cloudProvider, recorder, cluster, disruptionQueue, disruptionOpts...),
 		provisioning.NewPodController(kubeClient, p, cluster),
 		provisioning.NewNodeController(kubeClient, p),
-		nodepoolhash.NewController(kubeClient, cloudProvider),
-		expiration.NewController(clock, kubeClient, cloudProvider),
+	}
+	// Cluster state and the eviction queue back the sharded loops, so they're run by every replica when the work is sharded
+	unelected := []controller.Controller{
+		evictionQueue,
 		informer.NewDaemonSetController(kubeClient, cluster),
 		informer.NewNodeController(kubeClient, cluster),
 		informer.NewPodController(kubeClient, cluster),
+		informer.NewPodDisruptionBudgetController(kubeClient, cluster),
 		informer.NewNodePoolController(kubeClient, cloudProvider, cluster),
 		informer.NewNodeClaimController(kubeClient, cloudProvider, cluster),
-		termination.NewController(clock, kubeClient, cloudProvider, terminator.NewTerminator(clock, kubeClient, evictionQueue, recorder), recorder),
+	}
+	if options.FromContext(ctx).ShardCount > 1 {
+		elector := shard.NewElector(mgr.GetConfig())
+		sharded = append([]controller.Controller{elector}, elector.Sharded(sharded...)...)
+		unelected = shard.Unelected(unelected...)
+	}
+
+	controllers := append(sharded, unelected...)
+	controllers = append(controllers,
+		nodepoolhash.NewController(kubeClient, cloudProvider),
+		expiration.NewController(clock, kubeClient, cloudProvider, cluster, p, recorder),
+		termination.NewController(clock, kubeClient, cloudProvider, terminator.NewTerminator(clock, kubeClient, evictionQueue, recorder), recorder, terminationOpts...),
 		nodepoolreadiness.NewController(kubeClient, cloudProvider),
 		nodepoolregistrationhealth.NewController(kubeClient, cloudProvider),
 		nodepoolcounter.NewController(kubeClient, cloudProvider, cluster),
+		nodepooldriftrollout.NewController(kubeClient, cloudProvider),
+		nodepooldisruptionpause.NewController(kubeClient, cloudProvider),
 		nodepoolvalidation.NewController(kubeClient, cloudProvider),
 		podevents.NewController(clock, kubeClient, cloudProvider),
 		nodeclaimconsistency.NewController(clock, kubeClient, cloudProvider, recorder),
-		nodeclaimlifecycle.NewController(clock, kubeClient, cloudProvider, recorder),
+		nodeclaimlifecycle.NewController(clock, kubeClient, cloudProvider, recorder, lifecycleOpts...),
 		nodeclaimgarbagecollection.NewController(clock, kubeClient, cloudProvider),
 		nodeclaimdisruption.NewController(clock, kubeClient, cloudProvider),
 		nodeclaimhydration.NewController(kubeClient, cloudProvider),
 		nodehydration.NewController(kubeClient, cloudProvider),
-	}
+		nodeadoption.NewController(kubeClient, cloudProvider, recorder),
+	)
 
 	if !options.FromContext(ctx).DisableClusterStateObservability {
 		controllers = append(controllers,
@@ -129,11 +184,21 @@ func NewControllers(
 		)
 	}
 
-	// The cloud provider must define status conditions for the node repair controller to use to detect unhealthy nodes
-	if len(cloudProvider.RepairPolicies()) != 0 && options.FromContext(ctx).FeatureGates.NodeRepair {
+	// The cloud provider or the operator must define status conditions for the node repair controller to use to detect unhealthy nodes
+	if (len(cloudProvider.RepairPolicies()) != 0 || len(options.FromContext(ctx).NodeRepairConditions) != 0) && options.FromContext(ctx).FeatureGates.NodeRepair {
 		controllers = append(controllers, health.NewController(kubeClient, cloudProvider, clock, recorder))
 	}
 
+	// The cloud provider must surface instance interruptions for the interruption controller to act on them
+	if interruptionProvider, ok := overlayUndecoratedCloudProvider.(cloudprovider.InterruptionProvider); ok {
+		controllers = append(controllers, interruption.NewController(kubeClient, cloudProvider, interruptionProvider, recorder))
+	}
+
+	// The cloud provider must list the instances of the cluster for the orphan controller to find the leaked ones
+	if clusterInstanceLister, ok := overlayUndecoratedCloudProvider.(cloudprovider.ClusterInstanceLister); ok {
+		controllers = append(controllers, orphan.NewController(clock, kubeClient, cloudProvider, clusterInstanceLister))
+	}
+
 	if options.FromContext(ctx).FeatureGates.StaticCapacity {
 		controllers = append(controllers, staticprovisioning.NewController(kubeClient, cluster, recorder, cloudProvider, p, clock))
 		controllers = append(controllers, staticdeprovisioning.NewController(kubeClient, cluster, cloudProvider, clock))
@@ -143,5 +208,27 @@ func NewControllers(
 		controllers = append(controllers, nodeoverlay.NewController(kubeClient, overlayUndecoratedCloudProvider, instanceTypeStore, cluster))
 	}
 
+	if webhookURL := options.FromContext(ctx).StateStreamWebhookURL; webhookURL != "" {
+		publisher := stream.NewWebhook(webhookURL)
+		cluster.SetPublisher(publisher)
+		controllers = append(controllers, publisher)
+	}
+
+	if options.FromContext(ctx).FeatureGates.NodeRightsizing {
+		controllers = append(controllers, rightsizing.NewController(kubeClient, cloudProvider, cluster))
+	}
+
+	if options.FromContext(ctx).DisruptionDecisionRetention > 0 {
+		controllers = append(controllers, disruptionaudit.NewController(clock, kubeClient))
+	}
+
+	if options.FromContext(ctx).PriceChangeThreshold > 0 {
+		controllers = append(controllers, disruptionpricing.NewController(clock, kubeClient, cloudProvider, cluster))
+	}
+
+	if options.FromContext(ctx).ProvisioningFailureThreshold > 0 {
+		controllers = append(controllers, nodepoolprovisioningfailure.NewController(kubeClient, cloudProvider, recorder))
+	}
+
 	return controllers
 }
@@ -0,0 +1,189 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/hashstructure/v2"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// maxDecisionInstanceTypes is the number of replacement instance types recorded on a DisruptionDecision
+const maxDecisionInstanceTypes = 20
+
+// recordDecision writes a DisruptionDecision audit record for a command that has started executing, or that wasn't
+// executed, along with a message explaining why. A command that isn't executed is computed again on every loop, so its
+// record is updated rather than created again. Failing to write the record is logged, but never blocks the command.
+func (q *Queue) recordDecision(ctx context.Context, cmd *Command, phase v1alpha1.DisruptionDecisionPhase, message string) {
+	if options.FromContext(ctx).DisruptionDecisionRetention == 0 {
+		return
+	}
+	decision := NewDisruptionDecision(cmd)
+	decision.Name = decisionName(cmd, phase)
+	if err := q.kubeClient.Create(ctx, decision); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			log.FromContext(ctx).Error(err, "failed recording disruption decision")
+			return
+		}
+		existing := &v1alpha1.DisruptionDecision{}
+		if err = q.kubeClient.Get(ctx, client.ObjectKeyFromObject(decision), existing); err != nil {
+			log.FromContext(ctx).Error(err, "failed recording disruption decision")
+			return
+		}
+		stored := existing.DeepCopy()
+		existing.Spec = decision.Spec
+		if err = q.kubeClient.Patch(ctx, existing, client.MergeFrom(stored)); err != nil {
+			log.FromContext(ctx).Error(client.IgnoreNotFound(err), "failed recording disruption decision")
+			return
+		}
+		decision = existing
+	}
+	stored := decision.DeepCopy()
+	decision.Status.Phase = phase
+	decision.Status.EvictionPrecheck = cmd.evictionPrecheck
+	decision.Status.Message = message
+	if phase == v1alpha1.DisruptionDecisionPhaseSkipped || phase == v1alpha1.DisruptionDecisionPhaseDryRun {
+		decision.Status.CompletionTime = lo.ToPtr(metav1.NewTime(q.clock.Now()))
+	}
+	if err := q.kubeClient.Status().Patch(ctx, decision, client.MergeFrom(stored)); err != nil {
+		log.FromContext(ctx).Error(client.IgnoreNotFound(err), "failed recording disruption decision")
+	}
+}
+
+// completeDecision records the outcome of a command on its DisruptionDecision
+func (q *Queue) completeDecision(ctx context.Context, cmd *Command, cmdErr error) {
+	if options.FromContext(ctx).DisruptionDecisionRetention == 0 {
+		return
+	}
+	decision := &v1alpha1.DisruptionDecision{}
+	if err := q.kubeClient.Get(ctx, types.NamespacedName{Name: cmd.ID.String()}, decision); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			log.FromContext(ctx).Error(err, "failed recording disruption decision outcome")
+		}
+		return
+	}
+	stored := decision.DeepCopy()
+	decision.Status.Phase = v1alpha1.DisruptionDecisionPhaseSucceeded
+	decision.Status.CompletionTime = lo.ToPtr(metav1.NewTime(q.clock.Now()))
+	if cmdErr != nil {
+		decision.Status.Phase = v1alpha1.DisruptionDecisionPhaseFailed
+		decision.Status.Message = cmdErr.Error()
+	}
+	if err := q.kubeClient.Status().Patch(ctx, decision, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+		log.FromContext(ctx).Error(err, "failed recording disruption decision outcome")
+	}
+}
+
+// decisionName returns the name of the DisruptionDecision that records the command in the phase. Commands that execute
+// are recorded under their ID. Commands that don't execute are recorded under their candidates, reason and phase, so
+// that the same command being skipped on every loop is recorded once.
+func decisionName(cmd *Command, phase v1alpha1.DisruptionDecisionPhase) string {
+	if phase == v1alpha1.DisruptionDecisionPhaseExecuting {
+		return cmd.ID.String()
+	}
+	return fmt.Sprintf("%s-%d", strings.ToLower(string(phase)), lo.Must(hashstructure.Hash(struct {
+		Reason     v1.DisruptionReason
+		Candidates []string
+	}{
+		Reason:     cmd.Reason(),
+		Candidates: lo.Map(cmd.Candidates, func(c *Candidate, _ int) string { return c.NodeClaim.Name }),
+	}, hashstructure.FormatV2, &hashstructure.HashOptions{SlicesAsSets: true})))
+}
+
+// NewDisruptionDecision converts a command into a DisruptionDecision audit record, named after the command's ID
+func NewDisruptionDecision(cmd *Command) *v1alpha1.DisruptionDecision {
+	decision := &v1alpha1.DisruptionDecision{
+		ObjectMeta: metav1.ObjectMeta{Name: cmd.ID.String()},
+		Spec: v1alpha1.DisruptionDecisionSpec{
+			CommandID:         cmd.ID.String(),
+			Reason:            string(cmd.Reason()),
+			Decision:          string(cmd.Decision()),
+			ConsolidationType: cmd.ConsolidationType(),
+			Candidates: lo.Map(cmd.Candidates, func(c *Candidate, _ int) v1alpha1.DisruptionDecisionCandidate {
+				candidate := v1alpha1.DisruptionDecisionCandidate{
+					NodeClaim:    c.NodeClaim.Name,
+					NodePool:     c.NodePool.Name,
+					InstanceType: c.Labels()[corev1.LabelInstanceTypeStable],
+					CapacityType: c.Labels()[v1.CapacityTypeLabelKey],
+					Zone:         c.Labels()[corev1.LabelTopologyZone],
+				}
+				if c.Node != nil {
+					candidate.Node = c.Node.Name
+				}
+				return candidate
+			}),
+			Replacements: lo.Map(cmd.Replacements, func(r *Replacement, _ int) v1alpha1.DisruptionDecisionReplacement {
+				ct := r.Requirements.Get(v1.CapacityTypeLabelKey)
+				instanceTypes := lo.Map(r.InstanceTypeOptions.OrderByPrice(r.Requirements), func(it *cloudprovider.InstanceType, _ int) string { return it.Name })
+				return v1alpha1.DisruptionDecisionReplacement{
+					NodeClaim: r.Name,
+					CapacityType: lo.If(
+						ct.Has(v1.CapacityTypeReserved), v1.CapacityTypeReserved,
+					).ElseIf(
+						ct.Has(v1.CapacityTypeSpot), v1.CapacityTypeSpot,
+					).Else(v1.CapacityTypeOnDemand),
+					InstanceTypes: lo.Slice(instanceTypes, 0, maxDecisionInstanceTypes),
+				}
+			}),
+			PodCount:  int64(lo.SumBy(cmd.Candidates, func(c *Candidate) int { return len(c.reschedulablePods) })),
+			StartTime: metav1.NewTime(cmd.CreationTimestamp),
+		},
+	}
+	if savings, ok := estimatedSavings(cmd); ok {
+		decision.Spec.EstimatedSavings = strconv.FormatFloat(savings, 'f', 4, 64)
+	}
+	return decision
+}
+
+// estimatedSavings is the hourly price of the candidates less the cheapest available price of each replacement
+func estimatedSavings(cmd *Command) (float64, bool) {
+	if lo.ContainsBy(cmd.Candidates, func(c *Candidate) bool { return c.instanceType == nil }) {
+		return 0, false
+	}
+	candidatePrice, err := getCandidatePrices(cmd.Candidates)
+	if err != nil {
+		return 0, false
+	}
+	var replacementPrice float64
+	for _, r := range cmd.Replacements {
+		instanceTypes := r.InstanceTypeOptions.OrderByPrice(r.Requirements)
+		if len(instanceTypes) == 0 {
+			return 0, false
+		}
+		offerings := instanceTypes[0].Offerings.Available().Compatible(r.Requirements)
+		if len(offerings) == 0 {
+			return 0, false
+		}
+		replacementPrice += offerings.Cheapest().Price
+	}
+	return candidatePrice - replacementPrice, true
+}
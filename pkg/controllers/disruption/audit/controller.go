@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// Controller garbage collects DisruptionDecision audit records once they are older than the configured retention
+type Controller struct {
+	clock      clock.Clock
+	kubeClient client.Client
+}
+
+func NewController(c clock.Clock, kubeClient client.Client) *Controller {
+	return &Controller{
+		clock:      c,
+		kubeClient: kubeClient,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, "disruption.audit")
+
+	retention := options.FromContext(ctx).DisruptionDecisionRetention
+	if retention == 0 {
+		return reconciler.Result{}, nil
+	}
+	decisions := &v1alpha1.DisruptionDecisionList{}
+	if err := c.kubeClient.List(ctx, decisions); err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing disruption decisions, %w", err)
+	}
+	expired := lo.Filter(decisions.Items, func(d v1alpha1.DisruptionDecision, _ int) bool {
+		return c.clock.Since(d.CreationTimestamp.Time) > retention
+	})
+	var errs error
+	for i := range expired {
+		if err := c.kubeClient.Delete(ctx, &expired[i]); client.IgnoreNotFound(err) != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		log.FromContext(ctx).WithValues("DisruptionDecision", klog.KObj(&expired[i])).V(1).Info("garbage collecting expired disruption decision")
+	}
+	if errs != nil {
+		return reconciler.Result{}, errs
+	}
+	return reconciler.Result{RequeueAfter: time.Minute * 10}, nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("disruption.audit").
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/controllers/disruption/audit"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	ctx        context.Context
+	env        *test.Environment
+	fakeClock  *clock.FakeClock
+	controller *audit.Controller
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Audit")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...))
+	fakeClock = clock.NewFakeClock(time.Now())
+	controller = audit.NewController(fakeClock, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDecisionRetention: lo.ToPtr(time.Hour)}))
+	fakeClock.SetTime(time.Now())
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("Audit", func() {
+	var decision *v1alpha1.DisruptionDecision
+
+	BeforeEach(func() {
+		decision = &v1alpha1.DisruptionDecision{
+			ObjectMeta: metav1.ObjectMeta{Name: test.RandomName()},
+			Spec: v1alpha1.DisruptionDecisionSpec{
+				CommandID:  test.RandomName(),
+				Reason:     "Underutilized",
+				Decision:   "delete",
+				Candidates: []v1alpha1.DisruptionDecisionCandidate{{NodeClaim: test.RandomName()}},
+				StartTime:  metav1.Now(),
+			},
+		}
+	})
+	It("should keep disruption decisions within the retention period", func() {
+		ExpectApplied(ctx, env.Client, decision)
+		fakeClock.Step(30 * time.Minute)
+		ExpectSingletonReconciled(ctx, controller)
+		ExpectExists(ctx, env.Client, decision)
+	})
+	It("should delete disruption decisions older than the retention period", func() {
+		ExpectApplied(ctx, env.Client, decision)
+		fakeClock.Step(2 * time.Hour)
+		ExpectSingletonReconciled(ctx, controller)
+		ExpectNotFound(ctx, env.Client, decision)
+	})
+	It("should not delete disruption decisions when recording is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDecisionRetention: lo.ToPtr(time.Duration(0))}))
+		ExpectApplied(ctx, env.Client, decision)
+		fakeClock.Step(2 * time.Hour)
+		ExpectSingletonReconciled(ctx, controller)
+		ExpectExists(ctx, env.Client, decision)
+	})
+})
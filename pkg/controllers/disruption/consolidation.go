@@ -86,7 +86,7 @@ func (c *consolidation) markConsolidated() {
 }
 
 // ShouldDisrupt is a predicate used to filter candidates
-func (c *consolidation) ShouldDisrupt(_ context.Context, cn *Candidate) bool {
+func (c *consolidation) ShouldDisrupt(ctx context.Context, cn *Candidate) bool {
 	// Disable consolidation for static NodePool
 	if cn.OwnedByStaticNodePool() {
 		return false
@@ -119,25 +119,48 @@ func (c *consolidation) ShouldDisrupt(_ context.Context, cn *Candidate) bool {
 		c.recorder.Publish(disruptionevents.Unconsolidatable(cn.Node, cn.NodeClaim, fmt.Sprintf("NodePool %q has non-empty consolidation disabled", cn.NodePool.Name))...)
 		return false
 	}
+	// Moving pods with large amounts of node-local storage is far more expensive than the scheduling simulation assumes
+	if exceedsLocalStorageThreshold(ctx, cn) && options.FromContext(ctx).LocalStoragePolicy == options.LocalStoragePolicySkip {
+		threshold := options.FromContext(ctx).LocalStorageThreshold
+		c.recorder.Publish(disruptionevents.Unconsolidatable(cn.Node, cn.NodeClaim, fmt.Sprintf("Pods use %s of local storage, exceeding the consolidation threshold of %s", cn.LocalStorage.String(), threshold.String()))...)
+		return false
+	}
 	// return true if consolidatable
 	return cn.NodeClaim.StatusConditions().Get(v1.ConditionTypeConsolidatable).IsTrue()
 }
 
 // sortCandidates sorts candidates by disruption cost (where the lowest disruption cost is first) and returns the result
-func (c *consolidation) sortCandidates(candidates []*Candidate) []*Candidate {
+func (c *consolidation) sortCandidates(ctx context.Context, candidates []*Candidate) []*Candidate {
 	sort.Slice(candidates, func(i int, j int) bool {
-		return candidates[i].DisruptionCost < candidates[j].DisruptionCost
+		return lessDisruptive(ctx, candidates[i], candidates[j])
 	})
 	return candidates
 }
 
+// lessDisruptive orders candidates by disruption cost. When the local storage policy is Deprioritize, candidates whose
+// pods exceed the local storage threshold are always ordered after those that don't.
+func lessDisruptive(ctx context.Context, a, b *Candidate) bool {
+	if options.FromContext(ctx).LocalStoragePolicy == options.LocalStoragePolicyDeprioritize {
+		if aExceeds, bExceeds := exceedsLocalStorageThreshold(ctx, a), exceedsLocalStorageThreshold(ctx, b); aExceeds != bExceeds {
+			return bExceeds
+		}
+	}
+	return a.DisruptionCost < b.DisruptionCost
+}
+
+// exceedsLocalStorageThreshold returns true if the candidate's pods use more node-local storage than the configured threshold
+func exceedsLocalStorageThreshold(ctx context.Context, cn *Candidate) bool {
+	threshold := options.FromContext(ctx).LocalStorageThreshold
+	return !threshold.IsZero() && cn.LocalStorage.Cmp(threshold) > 0
+}
+
 // computeConsolidation computes a consolidation action to take
 //
 // nolint:gocyclo
 func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...*Candidate) (Command, error) {
 	var err error
 	// Run scheduling simulation to compute consolidation option
-	results, err := SimulateScheduling(ctx, c.kubeClient, c.cluster, c.provisioner, candidates...)
+	results, err := SimulateScheduling(ctx, c.kubeClient, c.cluster, c.provisioner, c.clock, candidates...)
 	if err != nil {
 		// if a candidate node is now deleting, just retry
 		if errors.Is(err, errCandidateDeleting) {
@@ -150,11 +173,20 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 	if !results.AllNonPendingPodsScheduled() {
 		// This method is used by multi-node consolidation as well, so we'll only report in the single node case
 		if len(candidates) == 1 {
+			recordSkipped(ctx, skipReasonSimulation, 1)
 			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, pretty.Sentence(results.NonPendingPodSchedulingErrors()))...)
 		}
 		return Command{}, nil
 	}
 
+	// we won't reduce the zonal diversity of a NodePool below its zone spread floor
+	if reducesZoneSpread(c.cluster, candidates, results.NewNodeClaims) {
+		if len(candidates) == 1 {
+			c.recorder.Publish(disruptionevents.Unconsolidatable(candidates[0].Node, candidates[0].NodeClaim, fmt.Sprintf("Removing would reduce the zones spanned by NodePool %q below its zone spread floor", candidates[0].NodePool.Name))...)
+		}
+		return Command{}, nil
+	}
+
 	// were we able to schedule all the pods on the inflight candidates?
 	if len(results.NewNodeClaims) == 0 {
 		return Command{
@@ -185,6 +217,10 @@ func (c *consolidation) computeConsolidation(ctx context.Context, candidates ...
 		}
 	}
 
+	// keep the replacement in the candidates' region so that its price is compared against offerings in the same region,
+	// and so that consolidation doesn't move workloads across the regions of a stretched cluster
+	pinToRegion(candidates, results.NewNodeClaims[0])
+
 	// sort the instanceTypes by price before we take any actions like truncation for spot-to-spot consolidation or finding the nodeclaim
 	// that meets the minimum requirement after filteringByPrice
 	results.NewNodeClaims[0].InstanceTypeOptions = results.NewNodeClaims[0].InstanceTypeOptions.OrderByPrice(results.NewNodeClaims[0].Requirements)
@@ -310,6 +346,21 @@ func (c *consolidation) computeSpotToSpotConsolidation(ctx context.Context, cand
 	}, nil
 }
 
+// pinToRegion constrains the replacement to the region of the candidates when they all share a region that the
+// replacement's pods can schedule to
+func pinToRegion(candidates []*Candidate, replacement *pscheduling.NodeClaim) {
+	regions := lo.Uniq(lo.Map(candidates, func(c *Candidate, _ int) string { return c.region }))
+	if len(regions) != 1 || regions[0] == "" {
+		return
+	}
+	regionReq := scheduling.NewRequirement(corev1.LabelTopologyRegion, corev1.NodeSelectorOpIn, regions[0])
+	if replacement.Requirements.Compatible(scheduling.NewRequirements(regionReq), scheduling.AllowUndefinedWellKnownLabels) != nil {
+		return
+	}
+	replacement.Requirements.Add(regionReq)
+	replacement.InstanceTypeOptions = replacement.InstanceTypeOptions.Compatible(replacement.Requirements)
+}
+
 // getCandidatePrices returns the sum of the prices of the given candidates
 func getCandidatePrices(candidates []*Candidate) (float64, error) {
 	var price float64
@@ -324,7 +375,7 @@ func getCandidatePrices(candidates []*Candidate) (float64, error) {
 			if reqs.Get(v1.CapacityTypeLabelKey).Has(v1.CapacityTypeReserved) {
 				return 0.0, nil
 			}
-			return 0.0, serrors.Wrap(fmt.Errorf("unable to determine offering"), "instance-type", c.instanceType.Name, "capacity-type", c.capacityType, "zone", c.zone)
+			return 0.0, serrors.Wrap(fmt.Errorf("unable to determine offering"), "instance-type", c.instanceType.Name, "capacity-type", c.capacityType, "region", c.region, "zone", c.zone)
 		}
 		price += compatibleOfferings.Cheapest().Price
 	}
@@ -288,6 +288,7 @@ var _ = Describe("Consolidation", func() {
 			Entry("when candidates are blocked by budgets", WithUnderutilizedBlockingBudget()),
 			Entry("when candidates are filtered out due to pod churn", WithUnderutilizedChurn()),
 			Entry("when candidates are filtered out due to candidate being nominated", WithUnderutilizedNodeNomination()),
+			Entry("when the eviction of a candidate's pods is denied", WithUnderutilizedDeniedEviction()),
 		)
 		DescribeTable("should correctly report invalidated commands for single node disruption", func(validatorOpt TestConsolidationValidatorOption) {
 			rs := test.ReplicaSet()
@@ -331,6 +332,7 @@ var _ = Describe("Consolidation", func() {
 			Entry("when a candidate is blocked by budgets", WithUnderutilizedBlockingBudget()),
 			Entry("when candidates are filtered out due to pod churn", WithUnderutilizedChurn()),
 			Entry("when candidates are filtered out due to candidate being nominated", WithUnderutilizedNodeNomination()),
+			Entry("when the eviction of a candidate's pods is denied", WithUnderutilizedDeniedEviction()),
 		)
 	})
 	Context("Budgets", func() {
@@ -1852,6 +1854,7 @@ var _ = Describe("Consolidation", func() {
 				})
 
 				ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaim, node, nodePool, pdb)
+				ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(pdb))
 
 				// bind pods to node
 				ExpectManualBinding(ctx, env.Client, pods[0], node)
@@ -1912,6 +1915,7 @@ var _ = Describe("Consolidation", func() {
 				pdb.Spec.UnhealthyPodEvictionPolicy = &alwaysAllow
 
 				ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaim, node, nodePool, pdb)
+				ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(pdb))
 
 				// bind pods to node
 				ExpectManualBinding(ctx, env.Client, pods[0], node)
@@ -1998,6 +2002,7 @@ var _ = Describe("Consolidation", func() {
 
 				// bind pods to node
 				ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool, namespace, pdb)
+				ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(pdb))
 				ExpectManualBinding(ctx, env.Client, pod, node)
 
 				// inform cluster state about nodes and nodeclaims
@@ -2448,6 +2453,79 @@ var _ = Describe("Consolidation", func() {
 			// and delete the old one
 			ExpectNotFound(ctx, env.Client, nodeClaims[1], nodes[1])
 		})
+		Context("Local Storage", func() {
+			var pods []*corev1.Pod
+			BeforeEach(func() {
+				rs := test.ReplicaSet()
+				ExpectApplied(ctx, env.Client, rs)
+				pods = test.Pods(3, test.PodOptions{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels,
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         "apps/v1",
+								Kind:               "ReplicaSet",
+								Name:               rs.Name,
+								UID:                rs.UID,
+								Controller:         lo.ToPtr(true),
+								BlockOwnerDeletion: lo.ToPtr(true),
+							},
+						}}})
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{LocalStorageThreshold: lo.ToPtr(resource.MustParse("1Gi"))}))
+			})
+			It("should not consolidate nodes whose pods exceed the local storage threshold", func() {
+				for _, p := range pods {
+					p.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: lo.ToPtr(resource.MustParse("5Gi"))}}}}
+				}
+				ExpectApplied(ctx, env.Client, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+				ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+				ExpectManualBinding(ctx, env.Client, pods[1], nodes[0])
+				ExpectManualBinding(ctx, env.Client, pods[2], nodes[1])
+
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0], nodes[1]}, []*v1.NodeClaim{nodeClaims[0], nodeClaims[1]})
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				Expect(queue.GetCommands()).To(BeEmpty())
+				Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+				Expect(recorder.DetectedEvent("Pods use 10Gi of local storage, exceeding the consolidation threshold of 1Gi")).To(BeTrue())
+			})
+			It("should ignore memory-backed emptyDir volumes when computing local storage", func() {
+				for _, p := range pods {
+					p.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: lo.ToPtr(resource.MustParse("5Gi"))}}}}
+				}
+				ExpectApplied(ctx, env.Client, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+				ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+				ExpectManualBinding(ctx, env.Client, pods[1], nodes[0])
+				ExpectManualBinding(ctx, env.Client, pods[2], nodes[1])
+
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0], nodes[1]}, []*v1.NodeClaim{nodeClaims[0], nodeClaims[1]})
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				cmds := queue.GetCommands()
+				Expect(cmds).To(HaveLen(1))
+				Expect(cmds[0].Candidates[0].NodeClaim.Name).To(Equal(nodeClaims[1].Name))
+			})
+			It("should consolidate nodes without local storage first when the policy is Deprioritize", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{
+					LocalStorageThreshold: lo.ToPtr(resource.MustParse("1Gi")),
+					LocalStoragePolicy:    lo.ToPtr(options.LocalStoragePolicyDeprioritize),
+				}))
+				// The cheapest node to disrupt only has a single pod, but that pod uses a large hostPath volume
+				pods[2].Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/data"}}}}
+				pods[2].Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("20Gi")}
+				ExpectApplied(ctx, env.Client, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool)
+				ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
+				ExpectManualBinding(ctx, env.Client, pods[1], nodes[0])
+				ExpectManualBinding(ctx, env.Client, pods[2], nodes[1])
+
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{nodes[0], nodes[1]}, []*v1.NodeClaim{nodeClaims[0], nodeClaims[1]})
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				cmds := queue.GetCommands()
+				Expect(cmds).To(HaveLen(1))
+				Expect(cmds[0].Candidates).To(HaveLen(1))
+				Expect(cmds[0].Candidates[0].NodeClaim.Name).To(Equal(nodeClaims[0].Name))
+			})
+		})
 		It("does not delete nodes with pod churn, deletes nodes without pod churn", func() {
 			// create our RS so we can link a pod to it
 			ExpectApplied(ctx, env.Client, nodePool)
@@ -2606,6 +2684,7 @@ var _ = Describe("Consolidation", func() {
 				},
 			})
 			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], nodePool, pdb)
+			ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(pdb))
 
 			// two pods on node 1
 			ExpectManualBinding(ctx, env.Client, pods[0], nodes[0])
@@ -2790,6 +2869,7 @@ var _ = Describe("Consolidation", func() {
 			nodeClaims[1].Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
 
 			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodePool, budget)
+			ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 			ExpectApplied(ctx, env.Client, nodeClaims[0], nodes[0], nodeClaims[1], nodes[1])
 
 			// bind pods to node
@@ -3506,6 +3586,7 @@ var _ = Describe("Consolidation", func() {
 						MaxUnavailable: fromInt(0),
 					})
 					ExpectApplied(ctx, env.Client, blockingPDBPod, pdb)
+					ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(pdb))
 					ExpectManualBinding(ctx, env.Client, blockingPDBPod, node)
 
 					// we would normally be able to replace a node, but we are blocked by the PDB during validation
@@ -3582,6 +3663,7 @@ var _ = Describe("Consolidation", func() {
 						MaxUnavailable: fromInt(0),
 					})
 					ExpectApplied(ctx, env.Client, blockingPDBPods[0], blockingPDBPods[1], pdb)
+					ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(pdb))
 					ExpectManualBinding(ctx, env.Client, blockingPDBPods[0], nodes[0])
 					ExpectManualBinding(ctx, env.Client, blockingPDBPods[1], nodes[1])
 
@@ -21,6 +21,7 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -42,34 +43,44 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+	"sigs.k8s.io/karpenter/pkg/utils/readonly"
 )
 
 type Controller struct {
-	queue         *Queue
-	kubeClient    client.Client
-	cluster       *state.Cluster
-	provisioner   *provisioning.Provisioner
-	recorder      events.Recorder
-	clock         clock.Clock
-	cloudProvider cloudprovider.CloudProvider
-	methods       []Method
-	mu            sync.Mutex
-	lastRun       map[string]time.Time
+	queue            *Queue
+	kubeClient       client.Client
+	cluster          *state.Cluster
+	provisioner      *provisioning.Provisioner
+	recorder         events.Recorder
+	clock            clock.Clock
+	cloudProvider    cloudprovider.CloudProvider
+	methods          []Method
+	candidateFilters []CandidateFilter
+	rateLimiter      *RateLimiter
+	mu               sync.Mutex
+	lastRun          map[string]time.Time
+	candidateOffsets map[string]int
 }
 
 // pollingPeriod that we inspect cluster to look for opportunities to disrupt
 const pollingPeriod = 10 * time.Second
 
 type ControllerOptions struct {
-	methods []Method
+	methods          []Method
+	candidateFilters []CandidateFilter
+	rebooter         cloudprovider.Rebooter
+	reader           client.Reader
 }
 
 func WithMethods(methods ...Method) option.Function[ControllerOptions] {
@@ -78,32 +89,68 @@ func WithMethods(methods ...Method) option.Function[ControllerOptions] {
 	}
 }
 
+// WithCandidateFilters registers additional filters that every candidate must pass, on top of the filter of the
+// disruption method. This lets distributions exclude nodes from disruption with their own logic, e.g. nodes that are
+// labeled for compliance capture.
+func WithCandidateFilters(filters ...CandidateFilter) option.Function[ControllerOptions] {
+	return func(o *ControllerOptions) {
+		o.candidateFilters = append(o.candidateFilters, filters...)
+	}
+}
+
+// WithRebooter lets drifted candidates be rebooted in place when their NodePool lists the drift reason in its
+// rebootDriftReasons, instead of being replaced. The queue must be created with WithQueueRebooter to execute them.
+func WithRebooter(rebooter cloudprovider.Rebooter) option.Function[ControllerOptions] {
+	return func(o *ControllerOptions) {
+		o.rebooter = rebooter
+	}
+}
+
+// WithRateLimitReader sets the reader that the disruption rate limit reads its Lease with. The Lease isn't held by the
+// informer cache, so the reader should read from the API server. Defaults to the kube client.
+func WithRateLimitReader(reader client.Reader) option.Function[ControllerOptions] {
+	return func(o *ControllerOptions) {
+		o.reader = reader
+	}
+}
+
 func NewController(clk clock.Clock, kubeClient client.Client, provisioner *provisioning.Provisioner,
 	cp cloudprovider.CloudProvider, recorder events.Recorder, cluster *state.Cluster, queue *Queue, opts ...option.Function[ControllerOptions]) *Controller {
 
-	o := option.Resolve(append([]option.Function[ControllerOptions]{WithMethods(NewMethods(clk, cluster, kubeClient, provisioner, cp, recorder, queue)...)}, opts...)...)
+	o := option.Resolve(opts...)
+	if o.methods == nil {
+		o.methods = NewMethods(clk, cluster, kubeClient, provisioner, cp, recorder, queue, WithDriftRebooter(o.rebooter))
+	}
 	return &Controller{
-		queue:         queue,
-		clock:         clk,
-		kubeClient:    kubeClient,
-		cluster:       cluster,
-		provisioner:   provisioner,
-		recorder:      recorder,
-		cloudProvider: cp,
-		lastRun:       map[string]time.Time{},
-		methods:       o.methods,
+		queue:            queue,
+		clock:            clk,
+		kubeClient:       kubeClient,
+		cluster:          cluster,
+		provisioner:      provisioner,
+		recorder:         recorder,
+		cloudProvider:    cp,
+		lastRun:          map[string]time.Time{},
+		candidateOffsets: map[string]int{},
+		methods:          o.methods,
+		candidateFilters: o.candidateFilters,
+		rateLimiter:      NewRateLimiter(clk, kubeClient, lo.Ternary[client.Reader](o.reader != nil, o.reader, kubeClient)),
 	}
 }
 
-func NewMethods(clk clock.Clock, cluster *state.Cluster, kubeClient client.Client, provisioner *provisioning.Provisioner, cp cloudprovider.CloudProvider, recorder events.Recorder, queue *Queue) []Method {
+func NewMethods(clk clock.Clock, cluster *state.Cluster, kubeClient client.Client, provisioner *provisioning.Provisioner, cp cloudprovider.CloudProvider, recorder events.Recorder, queue *Queue,
+	driftOpts ...option.Function[DriftOptions]) []Method {
 	c := MakeConsolidation(clk, cluster, kubeClient, provisioner, cp, recorder, queue)
 	return []Method{
+		// Gracefully replace any NodeClaims that operators have requested be disrupted.
+		NewRequested(clk, kubeClient, cluster, provisioner, recorder),
+		// Replace any NodeClaims that node repair has found to be unhealthy.
+		NewRepair(clk, kubeClient, cluster, provisioner, recorder),
 		// Delete any empty NodeClaims as there is zero cost in terms of disruption.
 		NewEmptiness(c),
 		// Terminate and create replacement for drifted NodeClaims in Static NodePool
 		NewStaticDrift(cluster, provisioner, cp),
 		// Terminate any NodeClaims that have drifted from provisioning specifications, allowing the pods to reschedule.
-		NewDrift(kubeClient, cluster, provisioner, recorder),
+		NewDrift(clk, kubeClient, cluster, provisioner, recorder, driftOpts...),
 		// Attempt to identify multiple NodeClaims that we can consolidate simultaneously to reduce pod churn
 		NewMultiNodeConsolidation(c),
 		// And finally fall back our single NodeClaim consolidation to further reduce cluster cost.
@@ -111,7 +158,10 @@ func NewMethods(clk clock.Clock, cluster *state.Cluster, kubeClient client.Clien
 	}
 }
 
-func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	if err := m.AddMetricsServerExtraHandler("/debug/disruption/candidates", c.candidatesHandler(ctx)); err != nil {
+		return err
+	}
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("disruption").
 		WatchesRawSource(singleton.Source()).
@@ -136,6 +186,16 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 	if !c.cluster.Synced(ctx) {
 		return reconciler.Result{RequeueAfter: time.Second}, nil
 	}
+	// Candidates are validated against the PodDisruptionBudgets in cluster state, so they must all be tracked as well
+	if !c.cluster.PodDisruptionBudgetsSynced(ctx) {
+		return reconciler.Result{RequeueAfter: time.Second}, nil
+	}
+
+	// Resume the commands that were in flight when Karpenter last stopped, so that their candidates aren't treated as
+	// outdated below
+	if err := c.queue.Recover(ctx); err != nil {
+		return reconciler.Result{}, fmt.Errorf("recovering disruption commands, %w", err)
+	}
 
 	// Karpenter taints nodes with a karpenter.sh/disruption taint as part of the disruption process while it progresses in memory.
 	// If Karpenter restarts or fails with an error during a disruption action, some nodes can be left tainted.
@@ -156,10 +216,27 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 		return reconciler.Result{}, serrors.Wrap(fmt.Errorf("removing condition from nodeclaims, %w", err), "condition", v1.ConditionTypeDisruptionReason)
 	}
 
+	// Every method of this loop is evaluated against the same snapshot of the cluster
+	ctx = withClusterSnapshot(ctx, newClusterSnapshot(c.cluster))
+
+	// Release the dead-lettered candidates whose dead-letter TTL elapsed or whose retry was requested
+	c.queue.DeadLetters.Release(ctx, lo.FilterMap(snapshotNodes(ctx, c.cluster), func(n *state.StateNode, _ int) (*v1.NodeClaim, bool) {
+		return n.NodeClaim, n.NodeClaim != nil
+	})...)
+
+	// Summarize the evaluation of each method once the loop completes, rather than logging as each candidate is skipped
+	var summaries []any
+	defer func() {
+		log.FromContext(ctx).V(1).WithValues(summaries...).Info("evaluated disruption methods")
+	}()
+
 	// Attempt different disruption methods. We'll only let one method perform an action
-	for _, m := range c.methods {
+	for _, m := range prioritizeMethods(c.methods, options.FromContext(ctx).DisruptionReasonPriority) {
 		c.recordRun(fmt.Sprintf("%T", m))
-		success, err := c.disrupt(ctx, m)
+		summary := newEvaluationSummary()
+		success, err := c.disrupt(withEvaluationSummary(ctx, summary), m, summary)
+		summary.observe(m)
+		summaries = append(summaries, strings.TrimPrefix(fmt.Sprintf("%T", m), "*disruption."), summary.LogValues())
 		if err != nil {
 			if errors.IsConflict(err) {
 				return reconciler.Result{Requeue: true}, nil
@@ -175,15 +252,23 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 	return reconciler.Result{RequeueAfter: pollingPeriod}, nil
 }
 
-func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, error) {
+func (c *Controller) disrupt(ctx context.Context, disruption Method, summary *evaluationSummary) (bool, error) {
 	defer metrics.Measure(EvaluationDurationSeconds, map[string]string{
 		metrics.ReasonLabel:    strings.ToLower(string(disruption.Reason())),
 		ConsolidationTypeLabel: disruption.ConsolidationType(),
 	})()
-	candidates, err := GetCandidates(ctx, c.cluster, c.kubeClient, c.recorder, c.clock, c.cloudProvider, disruption.ShouldDisrupt, disruption.Class(), c.queue)
+	shouldDisrupt := allowsReason(disruption.Reason(), func(ctx context.Context, cn *Candidate) bool {
+		return disruption.ShouldDisrupt(ctx, cn) && lo.EveryBy(c.candidateFilters, func(filter CandidateFilter) bool { return filter(ctx, cn) })
+	})
+	candidates, blocked, err := getCandidates(ctx, c.candidateWindow(ctx, fmt.Sprintf("%T", disruption), snapshotNodes(ctx, c.cluster)), snapshotPDBs(ctx, c.cluster), c.kubeClient, c.recorder, c.clock, c.cloudProvider, shouldDisrupt, disruption.Class(), c.queue)
 	if err != nil {
 		return false, fmt.Errorf("determining candidates, %w", err)
 	}
+	summary.evaluated = len(candidates)
+	recordSkipped(ctx, skipReasonPDB, lo.CountBy(blocked, func(b blockedCandidate) bool { return state.IsPodBlockEvictionError(b.err) }))
+	for _, b := range blocked {
+		recordBlocked(ctx, b.nodePool, state.BlockedReason(b.err), 1)
+	}
 	EligibleNodes.Set(float64(len(candidates)), map[string]string{
 		metrics.ReasonLabel: strings.ToLower(string(disruption.Reason())),
 	})
@@ -196,6 +281,9 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 	if err != nil {
 		return false, fmt.Errorf("building disruption budgets, %w", err)
 	}
+	if err := c.gateLowerPriorityReason(ctx, disruption.Reason(), disruptionBudgetMapping); err != nil {
+		return false, fmt.Errorf("gating disruption budgets, %w", err)
+	}
 	// Determine the disruption action
 	cmds, err := disruption.ComputeCommands(ctx, disruptionBudgetMapping, candidates...)
 	if err != nil {
@@ -207,6 +295,7 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 	}
 
 	errs := make([]error, len(cmds))
+	skipped := make([]bool, len(cmds))
 	workqueue.ParallelizeUntil(ctx, len(cmds), len(cmds), func(i int) {
 		cmd := cmds[i]
 
@@ -215,15 +304,148 @@ func (c *Controller) disrupt(ctx context.Context, disruption Method) (bool, erro
 		cmd.ID = uuid.New()
 		cmd.Method = disruption
 
+		// Skip commands with pods that would be denied eviction, since they would stall the drain of the candidates
+		if options.FromContext(ctx).EvictionPrecheck && !c.precheckEvictions(ctx, &cmd) {
+			log.FromContext(ctx).WithValues(cmd.LogValues()...).Info("skipping disruption, pods would be denied eviction")
+			c.queue.recordDecision(ctx, &cmd, v1alpha1.DisruptionDecisionPhaseSkipped, "pods on the candidates would be denied eviction")
+			skipped[i] = true
+			return
+		}
+		// Skip commands that are vetoed by the decision policies of the candidates' NodePools
+		if !c.evaluateDecisionPolicies(ctx, &cmd) {
+			log.FromContext(ctx).WithValues(cmd.LogValues()...).Info("skipping disruption, vetoed by decision policy")
+			c.queue.recordDecision(ctx, &cmd, v1alpha1.DisruptionDecisionPhaseSkipped, "a decision policy of the candidates' NodePools vetoed the command")
+			skipped[i] = true
+			return
+		}
+		// Record commands in dry-run mode instead of executing them, so that enabling disruption can be evaluated safely
+		if c.isDryRun(ctx, &cmd) {
+			c.recordDryRun(ctx, &cmd)
+			skipped[i] = true
+			return
+		}
+		// Skip every command in read-only mode, after it's been recorded so that the disruption decisions are still reported
+		if readonly.Enabled(ctx) {
+			log.FromContext(ctx).WithValues(cmd.LogValues()...).Info("skipping disruption, read-only mode is enabled")
+			c.queue.recordDecision(ctx, &cmd, v1alpha1.DisruptionDecisionPhaseSkipped, "read-only mode is enabled, the command was not executed")
+			skipped[i] = true
+			return
+		}
+		// Limit how quickly nodes are disrupted across the cluster. Delete commands are trimmed to the candidates that the
+		// rate limit allows, while replace commands are only executed when every candidate is allowed.
+		taken, release, err := c.rateLimiter.Take(ctx, len(cmd.Candidates), cmd.Decision() == DeleteDecision)
+		if err != nil {
+			errs[i] = fmt.Errorf("taking disruption rate limit tokens, %w", err)
+			return
+		}
+		if taken == 0 {
+			log.FromContext(ctx).WithValues(cmd.LogValues()...).Info("skipping disruption, cluster-wide disruption rate limit reached")
+			recordSkipped(ctx, skipReasonRateLimit, len(cmd.Candidates))
+			skipped[i] = true
+			return
+		}
+		if taken < len(cmd.Candidates) {
+			recordSkipped(ctx, skipReasonRateLimit, len(cmd.Candidates)-taken)
+			cmd.Candidates = cmd.Candidates[:taken]
+		}
 		// Attempt to disrupt
 		if err := c.queue.StartCommand(ctx, &cmd); err != nil {
+			release()
+			c.queue.DeadLetters.RecordFailure(ctx, &cmd, err)
 			errs[i] = fmt.Errorf("disrupting candidates, %w", err)
 		}
 	})
 	if err = multierr.Combine(errs...); err != nil {
 		return false, fmt.Errorf("disrupting candidates, %w", err)
 	}
-	return true, nil
+	summary.commandsSkipped = lo.Count(skipped, true)
+	summary.enqueued = len(cmds) - summary.commandsSkipped
+	// If every command was skipped, allow the next disruption method to run
+	return lo.Contains(skipped, false), nil
+}
+
+// prioritizeMethods orders the methods by the priority of their disruption reasons. Methods whose reasons aren't
+// prioritized keep their relative order after the prioritized methods.
+func prioritizeMethods(methods []Method, priority []string) []Method {
+	if len(priority) == 0 {
+		return methods
+	}
+	rank := func(m Method) int {
+		if i := lo.IndexOf(priority, string(m.Reason())); i >= 0 {
+			return i
+		}
+		return len(priority)
+	}
+	prioritized := slices.Clone(methods)
+	slices.SortStableFunc(prioritized, func(a, b Method) int { return rank(a) - rank(b) })
+	return prioritized
+}
+
+// gateLowerPriorityReason removes the budget of a reason from the NodePools whose budgets are saturated by the in-flight
+// commands of a higher priority reason, so that lower priority reasons don't compete with them for the NodePools' nodes
+func (c *Controller) gateLowerPriorityReason(ctx context.Context, reason v1.DisruptionReason, mapping DisruptionBudgetMapping) error {
+	priority := options.FromContext(ctx).DisruptionReasonPriority
+	i := lo.IndexOf(priority, string(reason))
+	if i < 0 {
+		i = len(priority)
+	}
+	for _, higher := range priority[:i] {
+		nodePools := c.queue.NodePoolsWithCommands(v1.DisruptionReason(higher))
+		if len(nodePools) == 0 {
+			continue
+		}
+		higherMapping, err := c.snapshotBudgets(ctx, v1.DisruptionReason(higher))
+		if err != nil {
+			return err
+		}
+		for nodePool := range nodePools {
+			if budget, ok := mapping[nodePool]; ok && higherMapping[nodePool].Nodes <= 0 {
+				budget.Nodes = 0
+				mapping[nodePool] = budget
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotBudgets returns the disruption budgets of the reason, which are built once per disruption loop and shared
+// by its methods. The returned mapping must not be modified.
+func (c *Controller) snapshotBudgets(ctx context.Context, reason v1.DisruptionReason) (DisruptionBudgetMapping, error) {
+	snapshot, ok := ctx.Value(clusterSnapshotKey{}).(*clusterSnapshot)
+	if ok && snapshot != nil {
+		if mapping, ok := snapshot.budgets[reason]; ok {
+			return mapping, nil
+		}
+	}
+	mapping, err := BuildDisruptionBudgetMapping(ctx, c.cluster, c.clock, c.kubeClient, c.cloudProvider, c.recorder, reason)
+	if err != nil {
+		return nil, err
+	}
+	if ok && snapshot != nil {
+		snapshot.budgets[reason] = mapping
+	}
+	return mapping, nil
+}
+
+// isDryRun returns true if the NodePool of any of the command's candidates is in disruption dry-run mode
+func (c *Controller) isDryRun(ctx context.Context, cmd *Command) bool {
+	return lo.SomeBy(cmd.Candidates, func(candidate *Candidate) bool {
+		return candidate.NodePool.Spec.Disruption.IsDryRun(options.FromContext(ctx).DisruptionDryRun)
+	})
+}
+
+// recordDryRun reports a command that would have been executed as events, metrics and a DisruptionDecision
+func (c *Controller) recordDryRun(ctx context.Context, cmd *Command) {
+	log.FromContext(ctx).WithValues(cmd.LogValues()...).Info("skipping disruption, dry-run mode is enabled")
+	for _, candidate := range cmd.Candidates {
+		c.recorder.Publish(disruptionevents.DryRun(candidate.Node, candidate.NodeClaim, string(cmd.Reason()), string(cmd.Decision()))...)
+	}
+	DryRunDecisionsTotal.Inc(map[string]string{
+		decisionLabel:          string(cmd.Decision()),
+		metrics.ReasonLabel:    strings.ToLower(string(cmd.Reason())),
+		ConsolidationTypeLabel: cmd.ConsolidationType(),
+	})
+	c.queue.recordDecision(ctx, cmd, v1alpha1.DisruptionDecisionPhaseDryRun, "disruption dry-run mode is enabled, the command was not executed")
 }
 
 func (c *Controller) recordRun(s string) {
@@ -232,6 +454,23 @@ func (c *Controller) recordRun(s string) {
 	c.lastRun[s] = c.clock.Now()
 }
 
+// candidateWindow returns the nodes that the method builds candidates for in this evaluation. When the candidate limit
+// is set, each evaluation of a method takes the next nodes in name order and wraps around, so that every node is
+// eventually evaluated without building candidates for the whole cluster in a single loop.
+func (c *Controller) candidateWindow(ctx context.Context, method string, nodes state.StateNodes) state.StateNodes {
+	limit := options.FromContext(ctx).DisruptionCandidateLimit
+	if limit == 0 || len(nodes) <= limit {
+		return nodes
+	}
+	nodes = slices.Clone(nodes)
+	slices.SortFunc(nodes, func(a, b *state.StateNode) int { return strings.Compare(a.Name(), b.Name()) })
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	offset := c.candidateOffsets[method] % len(nodes)
+	c.candidateOffsets[method] = (offset + limit) % len(nodes)
+	return slices.Concat(nodes[offset:min(offset+limit, len(nodes))], nodes[:max(0, offset+limit-len(nodes))])
+}
+
 func (c *Controller) logAbnormalRuns(ctx context.Context) {
 	const AbnormalTimeLimit = 15 * time.Minute
 	c.mu.Lock()
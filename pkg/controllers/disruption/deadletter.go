@@ -0,0 +1,172 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+)
+
+// CommandFailure is a failed attempt to execute a disruption command that included a candidate
+type CommandFailure struct {
+	CommandID uuid.UUID           `json:"commandID"`
+	Reason    v1.DisruptionReason `json:"reason"`
+	Time      time.Time           `json:"time"`
+	Error     string              `json:"error"`
+}
+
+// DeadLetter is a candidate that's excluded from disruption because the commands that included it failed repeatedly.
+// It's retried once the dead-letter TTL elapses, or once an operator sets the karpenter.sh/disruption-retry-requested
+// annotation on its NodeClaim to a new value.
+type DeadLetter struct {
+	NodeClaim      string           `json:"nodeClaim"`
+	ProviderID     string           `json:"providerID"`
+	DeadLetteredAt time.Time        `json:"deadLetteredAt"`
+	Failures       []CommandFailure `json:"failures"`
+	retryRequested string
+}
+
+// DeadLetters tracks the failed commands of each candidate and dead-letters the candidates that reach the
+// disruption-command-max-failures.
+type DeadLetters struct {
+	sync.RWMutex
+	clock       clock.Clock
+	failures    map[string][]CommandFailure // providerID -> failed commands since the candidate's last successful command
+	deadLetters map[string]*DeadLetter      // providerID -> dead-lettered candidate
+}
+
+func NewDeadLetters(clk clock.Clock) *DeadLetters {
+	return &DeadLetters{
+		clock:       clk,
+		failures:    map[string][]CommandFailure{},
+		deadLetters: map[string]*DeadLetter{},
+	}
+}
+
+// RecordFailure adds the failed command to the history of each of its candidates, dead-lettering the candidates
+// whose commands failed disruption-command-max-failures times
+func (d *DeadLetters) RecordFailure(ctx context.Context, cmd *Command, err error) {
+	maxFailures := options.FromContext(ctx).DisruptionCommandMaxFailures
+	if maxFailures == 0 {
+		return
+	}
+	d.Lock()
+	defer d.Unlock()
+	failure := CommandFailure{CommandID: cmd.ID, Reason: cmd.Reason(), Time: d.clock.Now(), Error: err.Error()}
+	for _, c := range cmd.Candidates {
+		failures := append(d.failures[c.ProviderID()], failure)
+		if len(failures) < maxFailures {
+			d.failures[c.ProviderID()] = failures
+			continue
+		}
+		delete(d.failures, c.ProviderID())
+		d.deadLetters[c.ProviderID()] = &DeadLetter{
+			NodeClaim:      c.NodeClaim.Name,
+			ProviderID:     c.ProviderID(),
+			DeadLetteredAt: d.clock.Now(),
+			Failures:       failures,
+			retryRequested: c.NodeClaim.Annotations[v1.DisruptionRetryRequestedAnnotationKey],
+		}
+		log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(c.NodeClaim), "failures", len(failures)).Info("dead-lettered disruption candidate after repeated command failures")
+	}
+	d.updateMetrics()
+}
+
+// RecordSuccess clears the failure history of the candidates of a successful command
+func (d *DeadLetters) RecordSuccess(cmd *Command) {
+	d.Lock()
+	defer d.Unlock()
+	for _, c := range cmd.Candidates {
+		delete(d.failures, c.ProviderID())
+	}
+}
+
+// IsDeadLettered returns true if the candidate is dead-lettered. It doesn't change the dead-letter list, so that
+// candidates can be evaluated without side effects, e.g. for reports.
+func (d *DeadLetters) IsDeadLettered(ctx context.Context, nodeClaim *v1.NodeClaim) bool {
+	if nodeClaim == nil {
+		return false
+	}
+	d.RLock()
+	defer d.RUnlock()
+	deadLetter, ok := d.deadLetters[nodeClaim.Status.ProviderID]
+	return ok && !d.releasable(ctx, nodeClaim, deadLetter)
+}
+
+// Release removes the candidates from the dead-letter list, with a clean failure history, once the dead-letter TTL
+// elapses or a retry is requested on their NodeClaim
+func (d *DeadLetters) Release(ctx context.Context, nodeClaims ...*v1.NodeClaim) {
+	d.Lock()
+	defer d.Unlock()
+	for _, nodeClaim := range nodeClaims {
+		deadLetter, ok := d.deadLetters[nodeClaim.Status.ProviderID]
+		if !ok || !d.releasable(ctx, nodeClaim, deadLetter) {
+			continue
+		}
+		delete(d.deadLetters, nodeClaim.Status.ProviderID)
+		log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(nodeClaim)).Info("released disruption candidate from the dead-letter list")
+	}
+	d.updateMetrics()
+}
+
+func (d *DeadLetters) releasable(ctx context.Context, nodeClaim *v1.NodeClaim, deadLetter *DeadLetter) bool {
+	ttl := options.FromContext(ctx).DisruptionDeadLetterTTL
+	expired := ttl > 0 && d.clock.Since(deadLetter.DeadLetteredAt) >= ttl
+	return expired || nodeClaim.Annotations[v1.DisruptionRetryRequestedAnnotationKey] != deadLetter.retryRequested
+}
+
+// List returns the dead-lettered candidates ordered by when they were dead-lettered
+func (d *DeadLetters) List() []DeadLetter {
+	d.RLock()
+	defer d.RUnlock()
+	deadLetters := lo.Map(lo.Values(d.deadLetters), func(dl *DeadLetter, _ int) DeadLetter { return *dl })
+	sort.Slice(deadLetters, func(i, j int) bool { return deadLetters[i].DeadLetteredAt.Before(deadLetters[j].DeadLetteredAt) })
+	return deadLetters
+}
+
+// ServeHTTP exposes the dead-lettered candidates with their full failure history as JSON
+func (d *DeadLetters) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(d.List()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// updateMetrics must be called with the lock held
+func (d *DeadLetters) updateMetrics() {
+	DeadLetteredCandidates.Reset()
+	for reason, deadLetters := range lo.GroupBy(lo.Values(d.deadLetters), func(dl *DeadLetter) v1.DisruptionReason {
+		return dl.Failures[len(dl.Failures)-1].Reason
+	}) {
+		DeadLetteredCandidates.Set(float64(len(deadLetters)), map[string]string{metrics.ReasonLabel: pretty.ToSnakeCase(string(reason))})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+// NodePoolReport lists the disruption candidates of a NodePool along with its remaining disruption budgets
+type NodePoolReport struct {
+	NodePool string `json:"nodePool"`
+	// AllowedDisruptions is the number of nodes that can still be disrupted for each disruption reason
+	AllowedDisruptions map[v1.DisruptionReason]int `json:"allowedDisruptions"`
+	Candidates         []CandidateReport           `json:"candidates"`
+}
+
+// CandidateReport describes whether a node is a disruption candidate, and if not, why
+type CandidateReport struct {
+	NodeClaim      string  `json:"nodeClaim"`
+	Node           string  `json:"node,omitempty"`
+	DisruptionCost float64 `json:"disruptionCost,omitempty"`
+	// Reasons are the disruption reasons whose methods consider the node for disruption
+	Reasons []v1.DisruptionReason `json:"reasons,omitempty"`
+	// BlockedReasons are the disruption reasons that the node or its pods block with karpenter.sh/do-not-disrupt-reasons
+	BlockedReasons []string `json:"blockedReasons,omitempty"`
+	// Blocked is why the node can't be disrupted for any reason, e.g. a pod with karpenter.sh/do-not-disrupt
+	Blocked string `json:"blocked,omitempty"`
+}
+
+// Report evaluates the nodes of every NodePool as disruption candidates. The report is computed on demand, so that it
+// reflects the cluster state that the next disruption loop will see.
+func (c *Controller) Report(ctx context.Context) ([]NodePoolReport, error) {
+	// Reporting shouldn't publish the events that evaluating candidates and budgets publishes during disruption
+	recorder := nopRecorder{}
+	nodePoolMap, nodePoolToInstanceTypesMap, err := BuildNodePoolMap(ctx, c.kubeClient, c.cloudProvider)
+	if err != nil {
+		return nil, err
+	}
+	pdbs := c.cluster.PodDisruptionBudgetLimits()
+	reports := map[string]*NodePoolReport{}
+	for name := range nodePoolMap {
+		reports[name] = &NodePoolReport{NodePool: name, AllowedDisruptions: map[v1.DisruptionReason]int{}, Candidates: []CandidateReport{}}
+	}
+	for _, reason := range lo.Uniq(lo.Map(c.methods, func(m Method, _ int) v1.DisruptionReason { return m.Reason() })) {
+		mapping, err := BuildDisruptionBudgetMapping(ctx, c.cluster, c.clock, c.kubeClient, c.cloudProvider, recorder, reason)
+		if err != nil {
+			return nil, err
+		}
+		for name, budget := range mapping {
+			if report, ok := reports[name]; ok {
+				report.AllowedDisruptions[reason] = budget.Nodes
+			}
+		}
+	}
+	for _, n := range c.cluster.SnapshotNodes() {
+		if n.NodeClaim == nil {
+			continue
+		}
+		report, ok := reports[n.Labels()[v1.NodePoolLabelKey]]
+		if !ok {
+			continue
+		}
+		candidateReport := CandidateReport{NodeClaim: n.NodeClaim.Name}
+		if n.Node != nil {
+			candidateReport.Node = n.Node.Name
+		}
+		// Each method evaluates its candidates with its own disruption class, e.g. drifted nodes with a
+		// TerminationGracePeriod are candidates even if their pods block eviction
+		candidates := map[string]*Candidate{}
+		var errs []error
+		for _, class := range lo.Uniq(lo.Map(c.methods, func(m Method, _ int) string { return m.Class() })) {
+			cn, err := NewCandidate(ctx, c.kubeClient, recorder, c.clock, n, pdbs, nodePoolMap, nodePoolToInstanceTypesMap, c.queue, class)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			candidates[class] = cn
+		}
+		if len(candidates) == 0 {
+			candidateReport.Blocked = errs[0].Error()
+			report.Candidates = append(report.Candidates, candidateReport)
+			continue
+		}
+		cn, ok := candidates[GracefulDisruptionClass]
+		if !ok {
+			cn = candidates[EventualDisruptionClass]
+		}
+		candidateReport.DisruptionCost = cn.DisruptionCost
+		candidateReport.BlockedReasons = sets.List(cn.doNotDisruptReasons)
+		candidateReport.Reasons = lo.Uniq(lo.FilterMap(c.methods, func(m Method, _ int) (v1.DisruptionReason, bool) {
+			cn, ok := candidates[m.Class()]
+			return m.Reason(), ok && allowsReason(m.Reason(), m.ShouldDisrupt)(ctx, cn) &&
+				lo.EveryBy(c.candidateFilters, func(filter CandidateFilter) bool { return filter(ctx, cn) })
+		}))
+		report.Candidates = append(report.Candidates, candidateReport)
+	}
+	nodePoolReports := lo.Map(lo.Values(reports), func(r *NodePoolReport, _ int) NodePoolReport {
+		sort.Slice(r.Candidates, func(i, j int) bool { return r.Candidates[i].NodeClaim < r.Candidates[j].NodeClaim })
+		return *r
+	})
+	sort.Slice(nodePoolReports, func(i, j int) bool { return nodePoolReports[i].NodePool < nodePoolReports[j].NodePool })
+	return nodePoolReports, nil
+}
+
+// candidatesHandler exposes the disruption candidates of every NodePool as JSON. The handler is served outside of the
+// manager's contexts, so it evaluates the candidates with the context that the controller was registered with.
+func (c *Controller) candidatesHandler(ctx context.Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Stop evaluating the candidates if the request is cancelled
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		defer context.AfterFunc(r.Context(), cancel)()
+		reports, err := c.Report(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(reports); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) Publish(...events.Event) {}
@@ -19,48 +19,83 @@ package disruption
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"slices"
 	"sort"
 
+	"github.com/awslabs/operatorpkg/option"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 )
 
 // Drift is a subreconciler that deletes drifted candidates.
 type Drift struct {
+	clock       clock.Clock
 	kubeClient  client.Client
 	cluster     *state.Cluster
 	provisioner *provisioning.Provisioner
 	recorder    events.Recorder
+	comparator  DriftCandidateComparator
+	rebooter    cloudprovider.Rebooter
 }
 
-func NewDrift(kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Drift {
+func NewDrift(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder,
+	opts ...option.Function[DriftOptions]) *Drift {
+	o := option.Resolve(opts...)
 	return &Drift{
+		clock:       clk,
 		kubeClient:  kubeClient,
 		cluster:     cluster,
 		provisioner: provisioner,
 		recorder:    recorder,
+		comparator:  o.comparator,
+		rebooter:    o.rebooter,
 	}
 }
 
 // ShouldDisrupt is a predicate used to filter candidates
 func (d *Drift) ShouldDisrupt(ctx context.Context, c *Candidate) bool {
-	return !c.OwnedByStaticNodePool() && c.NodeClaim.StatusConditions().Get(string(d.Reason())).IsTrue()
+	if c.OwnedByStaticNodePool() {
+		return false
+	}
+	cond := c.NodeClaim.StatusConditions().Get(string(d.Reason()))
+	if !cond.IsTrue() {
+		return false
+	}
+	// Drift protected nodes are excluded from drift, but can still be disrupted by the other methods
+	if c.Annotations()[v1.DriftProtectedAnnotationKey] == "true" || c.NodeClaim.Annotations[v1.DriftProtectedAnnotationKey] == "true" {
+		d.recorder.Publish(disruptionevents.Blocked(c.Node, c.NodeClaim, fmt.Sprintf("Node is protected from drift by the %s annotation (%s)", v1.DriftProtectedAnnotationKey, driftDetails(c.NodeClaim)))...)
+		return false
+	}
+	// Drift reasons that the NodePool doesn't automate are only acted on once the NodeClaim is approved
+	if !c.NodePool.Spec.Disruption.IsDriftAutomated(cond.Reason) && c.NodeClaim.Annotations[v1.DriftApprovedAnnotationKey] != "true" {
+		d.recorder.Publish(disruptionevents.Blocked(c.Node, c.NodeClaim, fmt.Sprintf("Drift reason %q requires approval with the %s annotation (%s)", cond.Reason, v1.DriftApprovedAnnotationKey, driftDetails(c.NodeClaim)))...)
+		return false
+	}
+	return true
 }
 
 // ComputeCommand generates a disruption command given candidates
-func (d *Drift) ComputeCommands(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) ([]Command, error) {
-	sort.Slice(candidates, func(i int, j int) bool {
-		return candidates[i].NodeClaim.StatusConditions().Get(string(d.Reason())).LastTransitionTime.Time.Before(
-			candidates[j].NodeClaim.StatusConditions().Get(string(d.Reason())).LastTransitionTime.Time)
+func (d *Drift) ComputeCommands(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) ([]Command, error) {
+	comparator := d.driftComparator(ctx)
+	sort.SliceStable(candidates, func(i int, j int) bool {
+		return comparator.Less(candidates[i], candidates[j])
 	})
 
 	emptyCandidates, nonEmptyCandidates := lo.FilterReject(candidates, func(c *Candidate, _ int) bool {
@@ -70,15 +105,25 @@ func (d *Drift) ComputeCommands(ctx context.Context, disruptionBudgetMapping map
 	// Prioritize empty candidates since we want them to get priority over non-empty candidates if the budget is constrained.
 	// Disrupting empty candidates first also helps reduce the overall churn because if a non-empty candidate is disrupted first,
 	// the pods from that node can reschedule on the empty nodes and will need to move again when those nodes get disrupted.
+	// Up to DriftBatchSize candidates are batched into a single command, with a combined scheduling simulation so that
+	// the replacements account for the pods of every candidate in the batch.
+	batchSize := options.FromContext(ctx).DriftBatchSize
+	budgets := maps.Clone(disruptionBudgetMapping)
+	d.stageRollouts(budgets, candidates)
+	cmds := []Command{}
+	var batch []*Candidate
+	var batchResults scheduling.Results
 	for _, candidate := range slices.Concat(emptyCandidates, nonEmptyCandidates) {
 		// If the disruption budget doesn't allow this candidate to be disrupted,
-		// continue to the next candidate. We don't need to decrement any budget
-		// counter since drift commands can only have one candidate.
-		if disruptionBudgetMapping[candidate.NodePool.Name] == 0 {
+		// continue to the next candidate.
+		if !budgets.Allows(candidate) {
+			recordBudgetSkipped(ctx, candidate.NodePool.Name, 1)
 			continue
 		}
+		// Candidates that are rebooted in place are drained before their reboot, so their pods are simulated on their own
+		reboot := d.shouldReboot(candidate)
 		// Check if we need to create any NodeClaims.
-		results, err := SimulateScheduling(ctx, d.kubeClient, d.cluster, d.provisioner, candidate)
+		results, err := SimulateScheduling(ctx, d.kubeClient, d.cluster, d.provisioner, d.clock, lo.Ternary(reboot, []*Candidate{candidate}, append(slices.Clone(batch), candidate))...)
 		if err != nil {
 			// if a candidate is now deleting, just retry
 			if errors.Is(err, errCandidateDeleting) {
@@ -86,21 +131,112 @@ func (d *Drift) ComputeCommands(ctx context.Context, disruptionBudgetMapping map
 			}
 			return []Command{}, err
 		}
-		// Emit an event that we couldn't reschedule the pods on the node.
 		if !results.AllNonPendingPodsScheduled() {
-			d.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, pretty.Sentence(results.NonPendingPodSchedulingErrors()))...)
+			// Emit an event that we couldn't reschedule the pods on the node. We only know that this candidate is
+			// blocked on its own when it's the first in the batch, otherwise we'll try it again in a later loop.
+			if len(batch) == 0 || reboot {
+				recordSkipped(ctx, skipReasonSimulation, 1)
+				d.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, fmt.Sprintf("%s (%s)", pretty.Sentence(results.NonPendingPodSchedulingErrors()), driftDetails(candidate.NodeClaim)))...)
+				publishPodsBlocked(d.recorder, candidate, d.Reason(), results)
+			}
 			continue
 		}
-
-		cmd := Command{
-			Candidates:   []*Candidate{candidate},
-			Replacements: replacementsFromNodeClaims(results.NewNodeClaims...),
-			Results:      results,
+		budgets.Consume(candidate)
+		if reboot {
+			cmds = append(cmds, Command{
+				Candidates:   []*Candidate{candidate},
+				Replacements: replacementsFromNodeClaims(results.NewNodeClaims...),
+				Results:      results,
+				Reboot:       true,
+			})
+			continue
+		}
+		batch = append(batch, candidate)
+		batchResults = results
+		if len(batch) >= batchSize {
+			break
 		}
-		return []Command{cmd}, nil
+	}
+	if len(batch) == 0 {
+		return cmds, nil
+	}
+	return append(cmds, Command{
+		Candidates:   batch,
+		Replacements: replacementsFromNodeClaims(batchResults.NewNodeClaims...),
+		Results:      batchResults,
+	}), nil
+}
+
+// shouldReboot returns true if the candidate drifted for a reason that its NodePool resolves by rebooting. Candidates
+// are rebooted at most once per NodePool hash, so that a candidate that's still drifted after its reboot is replaced.
+func (d *Drift) shouldReboot(c *Candidate) bool {
+	if d.rebooter == nil {
+		return false
+	}
+	if !c.NodePool.Spec.Disruption.IsDriftRebootable(c.driftReason()) {
+		return false
+	}
+	rebootedHash, ok := c.NodeClaim.Annotations[v1.NodeClaimRebootedNodePoolHashAnnotationKey]
+	return !ok || rebootedHash != c.NodePool.Annotations[v1.NodePoolHashAnnotationKey]
+}
 
+// stageRollouts limits the disruption budgets of NodePools that stage their drift rollout, and zeroes the budgets of
+// NodePools whose drift is paused. Replacements are the
+// NodeClaims in the NodePool that aren't drifted and were created after the rollout started. Until enough of the
+// replacements have been Ready for the soak duration, only enough drifted nodes to launch the canary replacements
+// can be disrupted.
+func (d *Drift) stageRollouts(budgets DisruptionBudgetMapping, candidates []*Candidate) {
+	nodePools := lo.UniqBy(lo.Map(candidates, func(c *Candidate, _ int) *v1.NodePool { return c.NodePool }), func(np *v1.NodePool) string {
+		return np.Name
+	})
+	nodes := d.cluster.SnapshotNodes()
+	for _, nodePool := range nodePools {
+		// Drift is paused after too many replacements failed, so that a bad rollout doesn't drain the NodePool
+		if nodePool.StatusConditions().IsTrue(v1.ConditionTypeDriftPaused) {
+			budget := budgets[nodePool.Name]
+			budget.Nodes = 0
+			budgets[nodePool.Name] = budget
+			d.recorder.Publish(disruptionevents.NodePoolDriftPaused(nodePool))
+			continue
+		}
+		rollout := nodePool.Spec.Disruption.DriftRollout
+		if rollout == nil {
+			continue
+		}
+		nodePoolNodes := lo.Filter(nodes, func(n *state.StateNode, _ int) bool {
+			return n.NodeClaim != nil && n.Labels()[v1.NodePoolLabelKey] == nodePool.Name
+		})
+		drifted, others := lo.FilterReject(nodePoolNodes, func(n *state.StateNode, _ int) bool {
+			return n.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()
+		})
+		if len(drifted) == 0 {
+			continue
+		}
+		rolloutStart := lo.MinBy(drifted, func(a, b *state.StateNode) bool {
+			return a.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).LastTransitionTime.Before(
+				&b.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).LastTransitionTime)
+		}).NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).LastTransitionTime
+		replacements := lo.Filter(others, func(n *state.StateNode, _ int) bool {
+			return !n.MarkedForDeletion() && !n.NodeClaim.CreationTimestamp.Before(&rolloutStart)
+		})
+		canaries := int(math.Ceil(float64(len(drifted)+len(replacements)) * float64(rollout.CanaryPercent) / 100))
+		soaked := lo.CountBy(replacements, func(n *state.StateNode) bool {
+			if n.Node == nil {
+				return false
+			}
+			ready := nodeutils.GetCondition(n.Node, corev1.NodeReady)
+			return ready.Status == corev1.ConditionTrue && d.clock.Since(ready.LastTransitionTime.Time) >= rollout.SoakDuration.Duration
+		})
+		if soaked >= canaries {
+			continue
+		}
+		budget := budgets[nodePool.Name]
+		budget.Nodes = lo.Clamp(canaries-len(replacements), 0, budget.Nodes)
+		budgets[nodePool.Name] = budget
+		if budget.Nodes == 0 {
+			d.recorder.Publish(disruptionevents.NodePoolDriftRolloutSoaking(nodePool, canaries))
+		}
 	}
-	return []Command{}, nil
 }
 
 func (d *Drift) Reason() v1.DisruptionReason {
@@ -114,3 +250,15 @@ func (d *Drift) Class() string {
 func (d *Drift) ConsolidationType() string {
 	return ""
 }
+
+// driftDetails describes why the NodeClaim drifted using its Drifted status condition
+func driftDetails(nodeClaim *v1.NodeClaim) string {
+	cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)
+	if cond == nil {
+		return ""
+	}
+	if cond.Message == "" || cond.Message == cond.Reason {
+		return cond.Reason
+	}
+	return fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
+}
@@ -31,10 +31,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -103,6 +108,61 @@ var _ = Describe("Drift", func() {
 			ExpectSingletonReconciled(ctx, disruptionController)
 			ExpectMetricGaugeValue(disruption.EligibleNodes, 1, eligibleNodesLabels)
 		})
+		It("should report nodes that are blocked by do-not-disrupt pods", func() {
+			blockedLabels := map[string]string{
+				metrics.NodePoolLabel: nodePool.Name,
+				metrics.ReasonLabel:   "drifted",
+				"blocked_reason":      "do_not_disrupt",
+			}
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.DoNotDisruptAnnotationKey: "true",
+					},
+				},
+			})
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectMetricGaugeValue(disruption.BlockedCandidates, 1, blockedLabels)
+
+			// once the node is no longer blocked, it's removed from the metric
+			pod.SetAnnotations(map[string]string{})
+			ExpectApplied(ctx, env.Client, pod)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			_, found := FindMetricWithLabelValues("karpenter_voluntary_disruption_blocked_candidates", blockedLabels)
+			Expect(found).To(BeFalse())
+		})
+		It("should report nodes that are blocked by PDBs", func() {
+			podLabels := map[string]string{"test": "value"}
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+			})
+			budget := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: fromInt(0),
+			})
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget)
+			ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectMetricGaugeValue(disruption.BlockedCandidates, 1, map[string]string{
+				metrics.NodePoolLabel: nodePool.Name,
+				metrics.ReasonLabel:   "drifted",
+				"blocked_reason":      "pdb",
+			})
+		})
 	})
 	Context("Budgets", func() {
 		var numNodes = 10
@@ -152,6 +212,278 @@ var _ = Describe("Drift", func() {
 				metrics.ReasonLabel:   string(v1.DisruptionReasonDrifted),
 			})
 		})
+		It("should only consider the ready nodes in excess of a minAvailable budget", func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%", MinAvailable: lo.ToPtr("7")}}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			ExpectMetricGaugeValue(disruption.NodePoolAllowedDisruptions, 3, map[string]string{
+				metrics.NodePoolLabel: nodePool.Name,
+				metrics.ReasonLabel:   string(v1.DisruptionReasonDrifted),
+			})
+		})
+		It("should record the candidates that were skipped because of budgets", func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "0"}}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			ExpectMetricCounterValue(disruption.CandidatesSkippedTotal, float64(numNodes), map[string]string{
+				metrics.ReasonLabel:               string(v1.DisruptionReasonDrifted),
+				disruption.ConsolidationTypeLabel: "",
+				"skip_reason":                     "budget",
+			})
+			ExpectMetricGaugeValue(disruption.BlockedCandidates, float64(numNodes), map[string]string{
+				metrics.NodePoolLabel: nodePool.Name,
+				metrics.ReasonLabel:   "drifted",
+				"blocked_reason":      "budget_exhausted",
+			})
+		})
+		It("should respect budgets for the drift reason of the candidates", func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{
+				{Nodes: "100%"},
+				{Reasons: []v1.DisruptionReason{v1.DisruptionReasonDrifted}, DriftReasons: []string{string(cloudprovider.ImageDrifted)}, Nodes: "0"},
+			}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(cloudprovider.ImageDrifted), string(cloudprovider.ImageDrifted))
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectMetricCounterValue(disruption.CandidatesSkippedTotal, float64(numNodes), map[string]string{
+				metrics.ReasonLabel:               string(v1.DisruptionReasonDrifted),
+				disruption.ConsolidationTypeLabel: "",
+				"skip_reason":                     "budget",
+			})
+		})
+		It("should respect budgets for the region of the candidates", func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyRegion:     "test-region-1",
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{
+				{Nodes: "100%"},
+				{Regions: []string{"test-region-1"}, Nodes: "0"},
+			}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectMetricCounterValue(disruption.CandidatesSkippedTotal, float64(numNodes), map[string]string{
+				metrics.ReasonLabel:               string(v1.DisruptionReasonDrifted),
+				disruption.ConsolidationTypeLabel: "",
+				"skip_reason":                     "budget",
+			})
+		})
+		It("should respect budgets for the capacity of the candidates", func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			// Each candidate has 32 vCPU, which exceeds the capacity that the budget allows to be disrupted
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DriftBatchSize: lo.ToPtr(numNodes)}))
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{
+				Nodes:     "100%",
+				Resources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("20")},
+			}}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Since none of the NodePool's nodes are being disrupted, a single candidate is let through
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(1))
+			ExpectMetricCounterValue(disruption.CandidatesSkippedTotal, float64(numNodes-1), map[string]string{
+				metrics.ReasonLabel:               string(v1.DisruptionReasonDrifted),
+				disruption.ConsolidationTypeLabel: "",
+				"skip_reason":                     "budget",
+			})
+		})
+		It("should not let a candidate exceed the capacity budget while another node is being disrupted", func() {
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			// Each candidate has 32 vCPU, which exceeds the capacity that the budget allows to be disrupted
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{
+				Nodes:     "100%",
+				Resources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("20")},
+			}}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectMetricCounterValue(disruption.CandidatesSkippedTotal, float64(numNodes-1), map[string]string{
+				metrics.ReasonLabel:               string(v1.DisruptionReasonDrifted),
+				disruption.ConsolidationTypeLabel: "",
+				"skip_reason":                     "budget",
+			})
+		})
+		It("should respect budgets for the zone of the candidates", func() {
+			zone := mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any()
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       zone,
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{
+				{Nodes: "100%"},
+				{Zones: []string{zone}, Nodes: "0"},
+			}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectMetricCounterValue(disruption.CandidatesSkippedTotal, float64(numNodes), map[string]string{
+				metrics.ReasonLabel:               string(v1.DisruptionReasonDrifted),
+				disruption.ConsolidationTypeLabel: "",
+				"skip_reason":                     "budget",
+			})
+		})
 		It("should disrupt 3 nodes, taking into account commands in progress", func() {
 			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
 				ObjectMeta: metav1.ObjectMeta{
@@ -387,17 +719,99 @@ var _ = Describe("Drift", func() {
 			ExpectExists(ctx, env.Client, nodeClaim)
 			ExpectNotFound(ctx, env.Client, nodeClaim2)
 		})
-		It("should ignore nodes without the drifted status condition", func() {
-			_ = nodeClaim.StatusConditions().Clear(v1.ConditionTypeDrifted)
-			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
-
-			// inform cluster state about nodes and nodeclaims
-			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
-			ExpectSingletonReconciled(ctx, disruptionController)
-
-			// Expect to not create or delete more nodeclaims
-			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
-			ExpectExists(ctx, env.Client, nodeClaim)
+		It("should publish an event to the pods that block the drift of a node", func() {
+			pod := test.Pod(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("150"),
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			podEvents := lo.Filter(recorder.Events(), func(e events.Event, _ int) bool {
+				p, ok := e.InvolvedObject.(*corev1.Pod)
+				return ok && p.UID == pod.UID && e.Reason == events.DisruptionBlocked
+			})
+			Expect(podEvents).To(HaveLen(1))
+			Expect(podEvents[0].Message).To(ContainSubstring(node.Name))
+		})
+		It("should wait for canary replacements to soak before continuing a staged drift rollout", func() {
+			nodePool.Spec.Disruption.DriftRollout = &v1.DriftRollout{
+				CanaryPercent: 50,
+				SoakDuration:  metav1.Duration{Duration: time.Hour},
+			}
+			// The second NodeClaim was launched after the rollout started, so it's a canary replacement
+			nodeClaim.Status.Conditions = lo.Reject(nodeClaim.Status.Conditions, func(c status.Condition, _ int) bool { return c.Type == v1.ConditionTypeDrifted })
+			nodeClaim.Status.Conditions = append(nodeClaim.Status.Conditions, status.Condition{
+				Type:               v1.ConditionTypeDrifted,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1.ConditionTypeDrifted,
+				Message:            v1.ConditionTypeDrifted,
+				LastTransitionTime: metav1.Time{Time: time.Now().Add(-time.Hour)},
+			})
+			nodeClaim2, node2 := test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("32")},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// The canary replacement just became ready, so the drifted node shouldn't be disrupted
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			Expect(recorder.DetectedEvent("Waiting for 1 canary nodes to be ready for 1h0m0s before continuing drift")).To(BeTrue())
+			ExpectExists(ctx, env.Client, nodeClaim)
+
+			// Once the canary replacement has soaked, the rollout continues
+			fakeClock.Step(2 * time.Hour)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(1))
+			Expect(cmds[0].Candidates[0].NodeClaim.Name).To(Equal(nodeClaim.Name))
+		})
+		It("should ignore nodes without the drifted status condition", func() {
+			_ = nodeClaim.StatusConditions().Clear(v1.ConditionTypeDrifted)
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should ignore nodes of NodePools whose drift is paused", func() {
+			nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeDriftPaused, "FailureThresholdReached", "2 drift replacements failed to launch or initialize, reaching the threshold of 2")
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			Expect(recorder.DetectedEvent("Drift is paused, 2 drift replacements failed to launch or initialize, reaching the threshold of 2")).To(BeTrue())
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
 		})
 		It("should ignore nodes with the drifted status condition set to false", func() {
 			nodeClaim.StatusConditions().SetFalse(v1.ConditionTypeDrifted, "NotDrifted", "NotDrifted")
@@ -411,6 +825,208 @@ var _ = Describe("Drift", func() {
 			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
 			ExpectExists(ctx, env.Client, nodeClaim)
 		})
+		It("should ignore nodes that drifted for a reason the NodePool doesn't automate", func() {
+			nodePool.Spec.Disruption.AutomatedDriftReasons = []string{string(cloudprovider.ImageDrifted)}
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(cloudprovider.UserDataDrifted), string(cloudprovider.UserDataDrifted))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should delete nodes that drifted for a reason the NodePool automates", func() {
+			nodePool.Spec.Disruption.AutomatedDriftReasons = []string{string(cloudprovider.ImageDrifted)}
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(cloudprovider.ImageDrifted), string(cloudprovider.ImageDrifted))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
+		It("should delete nodes that drifted for a reason the NodePool doesn't automate once approved", func() {
+			nodePool.Spec.Disruption.AutomatedDriftReasons = []string{string(cloudprovider.ImageDrifted)}
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.DriftApprovedAnnotationKey: "true"})
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(cloudprovider.UserDataDrifted), string(cloudprovider.UserDataDrifted))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
+		It("should ignore drifted nodes that are selected by a maintenance window that isn't open", func() {
+			fakeClock.SetTime(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, &v1alpha1.MaintenanceWindow{
+				ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+				Spec: v1alpha1.MaintenanceWindowSpec{
+					Schedule:     "0 0 * * *",
+					Duration:     metav1.Duration{Duration: time.Hour},
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}},
+				},
+			})
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should delete drifted nodes that are selected by an open maintenance window", func() {
+			fakeClock.SetTime(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, &v1alpha1.MaintenanceWindow{
+				ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+				Spec: v1alpha1.MaintenanceWindowSpec{
+					Schedule:     "0 0 * * *",
+					Duration:     metav1.Duration{Duration: time.Hour},
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}},
+				},
+			}, &v1alpha1.MaintenanceWindow{
+				ObjectMeta: metav1.ObjectMeta{Name: "midday"},
+				Spec: v1alpha1.MaintenanceWindowSpec{
+					Schedule: "30 11 * * *",
+					Duration: metav1.Duration{Duration: time.Hour},
+				},
+			})
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
+		It("should delete drifted nodes that aren't selected by any maintenance window", func() {
+			fakeClock.SetTime(time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, &v1alpha1.MaintenanceWindow{
+				ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+				Spec: v1alpha1.MaintenanceWindowSpec{
+					Schedule:     "0 0 * * *",
+					Duration:     metav1.Duration{Duration: time.Hour},
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{v1.NodePoolLabelKey: "other"}},
+				},
+			})
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
+		It("should ignore drifted nodes when a decision policy vetoes the command", func() {
+			nodePool.Spec.Disruption.DecisionPolicies = []v1.DecisionPolicy{{Name: "no-drift", Expression: "command.reason != 'Drifted'"}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(recorder.DetectedEvent(`Decision policy "no-drift" of NodePool "` + nodePool.Name + `" vetoed Drifted`)).To(BeTrue())
+		})
+		It("should record why a decision policy skipped the command", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDecisionRetention: lo.ToPtr(time.Hour)}))
+			nodePool.Spec.Disruption.DecisionPolicies = []v1.DecisionPolicy{{Name: "no-drift", Expression: "command.reason != 'Drifted'"}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			decisions := &v1alpha1.DisruptionDecisionList{}
+			Expect(env.Client.List(ctx, decisions)).To(Succeed())
+			Expect(decisions.Items).To(HaveLen(1))
+			Expect(decisions.Items[0].Status.Phase).To(Equal(v1alpha1.DisruptionDecisionPhaseSkipped))
+			Expect(decisions.Items[0].Status.Message).To(Equal("a decision policy of the candidates' NodePools vetoed the command"))
+			Expect(decisions.Items[0].Status.EvictionPrecheck).To(BeNil())
+		})
+		It("should ignore drifted nodes when a decision policy fails to compile", func() {
+			nodePool.Spec.Disruption.DecisionPolicies = []v1.DecisionPolicy{{Name: "invalid", Expression: "command.podCount +"}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should delete drifted nodes when every decision policy approves the command", func() {
+			nodePool.Spec.Disruption.DecisionPolicies = []v1.DecisionPolicy{
+				{Name: "empty", Expression: "command.podCount == 0"},
+				{Name: "single", Expression: "command.candidateCount == 1 && command.candidates.all(c, c.nodePool == '" + nodePool.Name + "')"},
+			}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
+		It("should ignore nodes with the karpenter.sh/drift-protected annotation", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DriftProtectedAnnotationKey: "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should ignore nodeclaims with the karpenter.sh/drift-protected annotation", func() {
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.DriftProtectedAnnotationKey: "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should delete drifted nodes with the karpenter.sh/drift-protected annotation set to false", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DriftProtectedAnnotationKey: "false"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+		})
 		It("should ignore nodes with the karpenter.sh/do-not-disrupt annotation", func() {
 			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptAnnotationKey: "true"})
 			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
@@ -425,6 +1041,150 @@ var _ = Describe("Drift", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectExists(ctx, env.Client, nodeClaim)
 		})
+		It("should ignore nodes with a karpenter.sh/do-not-disrupt annotation that hasn't expired", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptAnnotationKey: fakeClock.Now().Add(time.Hour).Format(time.RFC3339)})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should disrupt nodes with an expired karpenter.sh/do-not-disrupt annotation", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptAnnotationKey: fakeClock.Now().Add(-time.Hour).Format(time.RFC3339)})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		It("should disrupt nodes once their karpenter.sh/do-not-disrupt annotation expires", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptAnnotationKey: fakeClock.Now().Add(time.Hour).Format(time.RFC3339)})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+			Expect(queue.GetCommands()).To(HaveLen(0))
+
+			fakeClock.Step(2 * time.Hour)
+			ExpectSingletonReconciled(ctx, disruptionController)
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		It("should not create replacements for drifted nodes that have pods with a karpenter.sh/do-not-disrupt annotation that hasn't expired", func() {
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.DoNotDisruptAnnotationKey: fakeClock.Now().Add(time.Hour).Format(time.RFC3339),
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(queue.GetCommands()).To(HaveLen(0))
+		})
+		It("should ignore nodes that block drift with the karpenter.sh/do-not-disrupt-reasons annotation", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptReasonsAnnotationKey: "Underutilized, Drifted"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should ignore nodes with pods that block drift with the karpenter.sh/do-not-disrupt-reasons annotation", func() {
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.DoNotDisruptReasonsAnnotationKey: "Drifted",
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(queue.GetCommands()).To(HaveLen(0))
+		})
+		It("should disrupt drifted nodes that only block other reasons with the karpenter.sh/do-not-disrupt-reasons annotation", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptReasonsAnnotationKey: "Underutilized,Empty"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		Context("Cluster Autoscaler Compatibility", func() {
+			It("should ignore nodes with the cluster-autoscaler scale-down-disabled annotation", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ClusterAutoscalerCompatibility: lo.ToPtr(true)}))
+				node.Annotations = lo.Assign(node.Annotations, map[string]string{state.ClusterAutoscalerScaleDownDisabledAnnotationKey: "true"})
+				ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+				// inform cluster state about nodes and nodeclaims
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				Expect(queue.GetCommands()).To(HaveLen(0))
+				ExpectExists(ctx, env.Client, nodeClaim)
+			})
+			It("should ignore nodes with pods that have the cluster-autoscaler safe-to-evict annotation set to false", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ClusterAutoscalerCompatibility: lo.ToPtr(true)}))
+				pod := test.Pod(test.PodOptions{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							state.ClusterAutoscalerSafeToEvictAnnotationKey: "false",
+						},
+					},
+				})
+				ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+				ExpectManualBinding(ctx, env.Client, pod, node)
+
+				// inform cluster state about nodes and nodeclaims
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+				Expect(queue.GetCommands()).To(HaveLen(0))
+			})
+			It("should disrupt nodes with the cluster-autoscaler annotations when compatibility is disabled", func() {
+				node.Annotations = lo.Assign(node.Annotations, map[string]string{state.ClusterAutoscalerScaleDownDisabledAnnotationKey: "true"})
+				ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+				// inform cluster state about nodes and nodeclaims
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				Expect(queue.GetCommands()).To(HaveLen(1))
+			})
+		})
 		It("should delete drifted nodes with the karpenter.sh/do-not-disrupt annotation set to false", func() {
 			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptAnnotationKey: "false"})
 			labels := map[string]string{
@@ -504,6 +1264,50 @@ var _ = Describe("Drift", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectExists(ctx, env.Client, nodeClaim)
 		})
+		It("should not create replacements for drifted nodes that have pods in a namespace with the karpenter.sh/do-not-disrupt annotation", func() {
+			namespace := test.Namespace(test.NamespaceOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.DoNotDisruptAnnotationKey: "true",
+					},
+				},
+			})
+			pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name}})
+			ExpectApplied(ctx, env.Client, namespace, nodeClaim, node, nodePool, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should not create replacements for drifted nodes that have pods in a namespace with the karpenter.sh/do-not-disrupt label", func() {
+			namespace := test.Namespace(test.NamespaceOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.DoNotDisruptAnnotationKey: "true",
+					},
+				},
+			})
+			pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name}})
+			ExpectApplied(ctx, env.Client, namespace, nodeClaim, node, nodePool, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
 		It("should not create replacements for drifted nodes that have pods with the karpenter.sh/do-not-disrupt annotation when the NodePool's TerminationGracePeriod is not nil", func() {
 			pod := test.Pod(test.PodOptions{
 				ObjectMeta: metav1.ObjectMeta{
@@ -520,37 +1324,246 @@ var _ = Describe("Drift", func() {
 
 			ExpectSingletonReconciled(ctx, disruptionController)
 
-			// Pods with `karpenter.sh/do-not-disrupt` can't be evicted and hence can't be rescheduled on a new node.
-			// Expect no new nodeclaims to be created.
-			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
-			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
-			ExpectExists(ctx, env.Client, nodeClaim)
+			// Pods with `karpenter.sh/do-not-disrupt` can't be evicted and hence can't be rescheduled on a new node.
+			// Expect no new nodeclaims to be created.
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should not create replacements for drifted nodes that have pods with the blocking PDBs when the NodePool's TerminationGracePeriod is not nil", func() {
+			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
+			podLabels := map[string]string{"test": "value"}
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+			})
+			budget := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: fromInt(0),
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod, budget)
+			ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Pods with blocking PDBs can't be evicted and hence can't be rescheduled on a new node.
+			// Expect no new nodeclaims to be created.
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should disrupt drifted nodes that have pods with blocking PDBs that the NodePool ignores", func() {
+			podLabels := map[string]string{"test": "value"}
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+			})
+			budget := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: fromInt(0),
+			})
+			nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets = []v1.PodDisruptionBudgetSelector{{Namespace: budget.Namespace, Name: budget.Name}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod, budget)
+			ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// The pod is deleted rather than evicted, so it's rescheduled on a replacement
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Replacements).To(HaveLen(1))
+		})
+		It("should not disrupt drifted nodes that have pods with blocking PDBs that the NodePool doesn't ignore", func() {
+			podLabels := map[string]string{"test": "value"}
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+			})
+			budget := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: fromInt(0),
+			})
+			nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets = []v1.PodDisruptionBudgetSelector{{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"ignored": "true"}}}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod, budget)
+			ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+		})
+		It("should skip drifted nodes with pods that would be denied eviction when the eviction pre-check is enabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{EvictionPrecheck: lo.ToPtr(true), DisruptionDecisionRetention: lo.ToPtr(time.Hour)}))
+			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
+			podLabels := map[string]string{"test": "value"}
+			pod := test.Pod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+			})
+			budget := test.PodDisruptionBudget(test.PDBOptions{
+				Labels:         podLabels,
+				MaxUnavailable: fromInt(0),
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod, budget)
+			ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// The blocking PDB denies the dry-run eviction, so the command is skipped
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+
+			decisions := &v1alpha1.DisruptionDecisionList{}
+			Expect(env.Client.List(ctx, decisions)).To(Succeed())
+			Expect(decisions.Items).To(HaveLen(1))
+			Expect(decisions.Items[0].Status.Phase).To(Equal(v1alpha1.DisruptionDecisionPhaseSkipped))
+			Expect(decisions.Items[0].Status.Message).To(Equal("pods on the candidates would be denied eviction"))
+			Expect(decisions.Items[0].Status.EvictionPrecheck).ToNot(BeNil())
+			Expect(decisions.Items[0].Status.EvictionPrecheck.Outcome).To(Equal(v1alpha1.EvictionPrecheckOutcomeSkipped))
+			Expect(decisions.Items[0].Status.EvictionPrecheck.BlockedPods).To(ConsistOf(client.ObjectKeyFromObject(pod).String()))
+		})
+		It("should record but not execute drift commands when dry-run mode is enabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDryRun: lo.ToPtr(true), DisruptionDecisionRetention: lo.ToPtr(time.Hour)}))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+			ExpectMetricCounterValue(disruption.DryRunDecisionsTotal, 1, map[string]string{
+				"decision":           "delete",
+				metrics.ReasonLabel:  "drifted",
+				"consolidation_type": "",
+			})
+
+			decisions := &v1alpha1.DisruptionDecisionList{}
+			Expect(env.Client.List(ctx, decisions)).To(Succeed())
+			Expect(decisions.Items).To(HaveLen(1))
+			Expect(decisions.Items[0].Status.Phase).To(Equal(v1alpha1.DisruptionDecisionPhaseDryRun))
+			Expect(decisions.Items[0].Status.CompletionTime).ToNot(BeNil())
+			Expect(decisions.Items[0].Status.Message).To(Equal("disruption dry-run mode is enabled, the command was not executed"))
+		})
+		It("should update the record of a dry-run command rather than creating a new record on every loop", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDryRun: lo.ToPtr(true), DisruptionDecisionRetention: lo.ToPtr(time.Hour)}))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+			decisions := &v1alpha1.DisruptionDecisionList{}
+			Expect(env.Client.List(ctx, decisions)).To(Succeed())
+			Expect(decisions.Items).To(HaveLen(1))
+			commandID := decisions.Items[0].Spec.CommandID
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+			Expect(env.Client.List(ctx, decisions)).To(Succeed())
+			Expect(decisions.Items).To(HaveLen(1))
+			Expect(decisions.Items[0].Spec.CommandID).ToNot(Equal(commandID))
+			Expect(decisions.Items[0].Status.Phase).To(Equal(v1alpha1.DisruptionDecisionPhaseDryRun))
+		})
+		It("should record but not execute drift commands when the NodePool enables dry-run mode", func() {
+			nodePool.Spec.Disruption.DryRun = lo.ToPtr(true)
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(recorder.DetectedEvent("Would disrupt (Drifted) with decision delete, dry-run mode is enabled")).To(BeTrue())
+		})
+		It("should delete drifted nodes when the NodePool disables dry-run mode that is enabled for the controller", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDryRun: lo.ToPtr(true)}))
+			nodePool.Spec.Disruption.DryRun = lo.ToPtr(false)
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
 		})
-		It("should not create replacements for drifted nodes that have pods with the blocking PDBs when the NodePool's TerminationGracePeriod is not nil", func() {
-			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
-			podLabels := map[string]string{"test": "value"}
+		It("should surface the termination deadline of drifted nodes with a terminationGracePeriod", func() {
+			nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Hour}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, cluster, cloudProvider, cmds[0])
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+			Expect(nodeClaim.Status.TerminationDeadline).ToNot(BeNil())
+			Expect(nodeClaim.Status.TerminationDeadline.Time).To(BeTemporally("==", nodeClaim.DeletionTimestamp.Add(time.Hour)))
+		})
+		It("should annotate the replacements and the candidates with each other", func() {
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+			Expect(env.Client.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+
 			pod := test.Pod(test.PodOptions{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: podLabels,
-				},
-			})
-			budget := test.PodDisruptionBudget(test.PDBOptions{
-				Labels:         podLabels,
-				MaxUnavailable: fromInt(0),
-			})
-			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod, budget)
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					}}})
+			ExpectApplied(ctx, env.Client, rs, pod, nodeClaim, node, nodePool)
 			ExpectManualBinding(ctx, env.Client, pod, node)
 
 			// inform cluster state about nodes and nodeclaims
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
-
 			ExpectSingletonReconciled(ctx, disruptionController)
 
-			// Pods with blocking PDBs can't be evicted and hence can't be rescheduled on a new node.
-			// Expect no new nodeclaims to be created.
-			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
-			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
-			ExpectExists(ctx, env.Client, nodeClaim)
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Replacements).To(HaveLen(1))
+			replacement := &v1.NodeClaim{}
+			Expect(env.Client.Get(ctx, client.ObjectKey{Name: cmds[0].Replacements[0].Name}, replacement)).To(Succeed())
+			Expect(replacement.Annotations).To(HaveKeyWithValue(v1.NodeClaimReplacesAnnotationKey, nodeClaim.Name))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimReplacedByAnnotationKey, replacement.Name))
 		})
 		It("should replace drifted nodes", func() {
 			labels := map[string]string{
@@ -981,6 +1994,298 @@ var _ = Describe("Drift", func() {
 			ExpectExists(ctx, env.Client, nodeClaim)
 			ExpectExists(ctx, env.Client, node)
 		})
+		It("should drift the non-empty node with the fewest pods first when ordering by pod count", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DriftOrdering: lo.ToPtr(options.DriftOrderingFewestPodsFirst)}))
+			labels := map[string]string{
+				"app": "test",
+			}
+
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+
+			pods := test.Pods(3, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					},
+				},
+				// Make two pods fit on a single node
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("15")},
+				},
+			})
+
+			nodeClaim2, node2 := test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("32")},
+				},
+			})
+			nodeClaim2.Status.Conditions = append(nodeClaim2.Status.Conditions, status.Condition{
+				Type:               v1.ConditionTypeDrifted,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1.ConditionTypeDrifted,
+				Message:            v1.ConditionTypeDrifted,
+				LastTransitionTime: metav1.Time{Time: time.Now().Add(-time.Hour)},
+			})
+
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], pods[2], nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// bind pods to node so that they're not empty and don't disrupt in parallel. The node that drifted
+			// earliest has more pods, so it's disrupted last.
+			ExpectManualBinding(ctx, env.Client, pods[0], node)
+			ExpectManualBinding(ctx, env.Client, pods[1], node2)
+			ExpectManualBinding(ctx, env.Client, pods[2], node2)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Process the item so that the nodes can be deleted.
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(1))
+			Expect(cmds[0].Candidates[0].NodeClaim.Name).To(Equal(nodeClaim.Name))
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, cluster, cloudProvider, cmds[0])
+			ExpectObjectReconciled(ctx, env.Client, queue, cmds[0].Candidates[0].NodeClaim)
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim, nodeClaim2)
+
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node)
+			ExpectExists(ctx, env.Client, nodeClaim2)
+			ExpectExists(ctx, env.Client, node2)
+		})
+		It("should drift multiple non-empty nodes in a single command when batching is enabled", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DriftBatchSize: lo.ToPtr(2)}))
+			labels := map[string]string{
+				"app": "test",
+			}
+
+			// create our RS so we can link a pod to it
+			rs := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, rs)
+
+			pods := test.Pods(2, test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         "apps/v1",
+							Kind:               "ReplicaSet",
+							Name:               rs.Name,
+							UID:                rs.UID,
+							Controller:         lo.ToPtr(true),
+							BlockOwnerDeletion: lo.ToPtr(true),
+						},
+					},
+				},
+				// Make each pod request only fit on a single node
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("30")},
+				},
+			})
+
+			nodeClaim2, node2 := test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					ProviderID:  test.RandomProviderID(),
+					Allocatable: map[corev1.ResourceName]resource.Quantity{corev1.ResourceCPU: resource.MustParse("32")},
+				},
+			})
+			nodeClaim2.Status.Conditions = append(nodeClaim2.Status.Conditions, status.Condition{
+				Type:               v1.ConditionTypeDrifted,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1.ConditionTypeDrifted,
+				Message:            v1.ConditionTypeDrifted,
+				LastTransitionTime: metav1.Time{Time: time.Now().Add(-time.Hour)},
+			})
+
+			ExpectApplied(ctx, env.Client, rs, pods[0], pods[1], nodeClaim, node, nodeClaim2, node2, nodePool)
+
+			// bind pods to node so that they're not empty and don't disrupt in parallel.
+			ExpectManualBinding(ctx, env.Client, pods[0], node)
+			ExpectManualBinding(ctx, env.Client, pods[1], node2)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Both candidates should be disrupted together with a replacement for each pod
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(2))
+			Expect(cmds[0].Replacements).To(HaveLen(2))
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, cluster, cloudProvider, cmds[0])
+			ExpectObjectReconciled(ctx, env.Client, queue, cmds[0].Candidates[0].NodeClaim)
+			// Cascade any deletion of the nodeClaim to the node
+			ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim, nodeClaim2)
+
+			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(2))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+			ExpectNotFound(ctx, env.Client, nodeClaim, node, nodeClaim2, node2)
+		})
+	})
+	Context("Reboot", func() {
+		var rebootingController *disruption.Controller
+		var evictionQueue *terminator.Queue
+		BeforeEach(func() {
+			evictionQueue = terminator.NewQueue(env.Client, recorder)
+			*queue = lo.FromPtr(disruption.NewQueue(env.Client, recorder, cluster, fakeClock, prov, disruption.WithQueueRebooter(cloudProvider, evictionQueue)))
+			rebootingController = disruption.NewController(fakeClock, env.Client, prov, cloudProvider, recorder, cluster, queue, disruption.WithRebooter(cloudProvider))
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1.NodePoolHashAnnotationKey:        "new-hash",
+				v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+			})
+			nodePool.Spec.Disruption.RebootDriftReasons = []string{"NodePoolDrifted"}
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{
+				v1.NodePoolHashAnnotationKey:        "old-hash",
+				v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+			})
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, "NodePoolDrifted", "NodePoolDrifted")
+			Expect(nodeClaim.StatusConditions().Clear(v1.ConditionTypeConsolidatable)).To(BeNil())
+		})
+		It("should reboot nodes that drifted for a reason the NodePool resolves by rebooting", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, rebootingController)
+
+			// Expect the node to be tainted and count against the disruption budgets until it's rebooted
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Decision()).To(Equal(disruption.RebootDecision))
+			Expect(cloudProvider.RebootCalls).To(HaveLen(0))
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).To(ContainElement(v1.DisruptedNoScheduleTaint))
+			Expect(ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim).MarkedForDeletion()).To(BeTrue())
+
+			// Expect the instance to be rebooted in place rather than replaced, and the command to wait for the node
+			fakeClock.Step(time.Hour)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+			Expect(cloudProvider.RebootCalls).To(HaveLen(1))
+			Expect(cloudProvider.RebootCalls[0].Name).To(Equal(nodeClaim.Name))
+			Expect(queue.GetCommands()).To(HaveLen(1))
+			Expect(recorder.DetectedEvent("Rebooted instance in place (NodePoolDrifted)")).To(BeTrue())
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimRebootedNodePoolHashAnnotationKey, "new-hash"))
+			Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, "old-hash"))
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+
+			// Once the node is Ready again, expect the command to complete and the node to be schedulable
+			node = ExpectExists(ctx, env.Client, node)
+			node.Status.Conditions = []corev1.NodeCondition{{
+				Type:               corev1.NodeReady,
+				Status:             corev1.ConditionTrue,
+				LastHeartbeatTime:  metav1.NewTime(fakeClock.Now().Add(time.Minute)),
+				LastTransitionTime: metav1.NewTime(fakeClock.Now().Add(time.Minute)),
+				Reason:             "KubeletReady",
+			}}
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+			Expect(cloudProvider.RebootCalls).To(HaveLen(1))
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().Get(v1.ConditionTypeDisruptionReason)).To(BeNil())
+			Expect(ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim).MarkedForDeletion()).To(BeFalse())
+		})
+		It("should drain nodes before rebooting them", func() {
+			pod := test.Pod(test.PodOptions{
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+			})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, rebootingController)
+
+			// Expect capacity to be launched for the pod, since it's evicted during the reboot
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Decision()).To(Equal(disruption.RebootDecision))
+			Expect(cmds[0].Replacements).To(HaveLen(1))
+			ExpectMakeNewNodeClaimsReady(ctx, env.Client, cluster, cloudProvider, cmds[0])
+
+			// Expect the pod to be evicted through the eviction queue before the instance is rebooted
+			fakeClock.Step(time.Hour)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+			Expect(evictionQueue.Has(pod)).To(BeTrue())
+			Expect(cloudProvider.RebootCalls).To(HaveLen(0))
+
+			ExpectDeleted(ctx, env.Client, pod)
+			ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+			Expect(cloudProvider.RebootCalls).To(HaveLen(1))
+			Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(2))
+		})
+		It("should replace nodes that are still drifted after they were rebooted for the NodePool hash", func() {
+			nodeClaim.Annotations[v1.NodeClaimRebootedNodePoolHashAnnotationKey] = "new-hash"
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, rebootingController)
+
+			Expect(cloudProvider.RebootCalls).To(HaveLen(0))
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		It("should replace nodes that drifted for a reason the NodePool doesn't resolve by rebooting", func() {
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(cloudprovider.ImageDrifted), string(cloudprovider.ImageDrifted))
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, rebootingController)
+
+			Expect(cloudProvider.RebootCalls).To(HaveLen(0))
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		It("should replace nodes when the cloud provider can't reboot them", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(cloudProvider.RebootCalls).To(HaveLen(0))
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		It("should not reboot nodes in dry-run mode", func() {
+			nodePool.Spec.Disruption.DryRun = lo.ToPtr(true)
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, rebootingController)
+
+			Expect(cloudProvider.RebootCalls).To(HaveLen(0))
+			Expect(ExpectExists(ctx, env.Client, nodeClaim).StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+		})
 	})
 
 	Context("Static NodePool", func() {
@@ -1010,7 +2315,7 @@ var _ = Describe("Drift", func() {
 			for _, nc := range nodeClaims {
 				nc.StatusConditions().SetTrue(v1.ConditionTypeDrifted)
 			}
-			drift := disruption.NewDrift(env.Client, cluster, prov, recorder)
+			drift := disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder)
 
 			ExpectApplied(ctx, env.Client, staticNp, nodeClaims[0], nodeClaims[1], nodes[0], nodes[1])
 
@@ -0,0 +1,101 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"math"
+
+	"github.com/awslabs/operatorpkg/option"
+	"github.com/samber/lo"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// DriftCandidateComparator orders drifted candidates for disruption. Less reports whether candidate a should be
+// disrupted before candidate b. Empty candidates are always disrupted before non-empty candidates, regardless of
+// the comparator.
+type DriftCandidateComparator interface {
+	Less(a, b *Candidate) bool
+}
+
+// DriftCandidateComparatorFunc adapts a function to a DriftCandidateComparator
+type DriftCandidateComparatorFunc func(a, b *Candidate) bool
+
+func (f DriftCandidateComparatorFunc) Less(a, b *Candidate) bool {
+	return f(a, b)
+}
+
+type DriftOptions struct {
+	comparator DriftCandidateComparator
+	rebooter   cloudprovider.Rebooter
+}
+
+// WithDriftComparator overrides the drift ordering that's configured through the drift-ordering option
+func WithDriftComparator(comparator DriftCandidateComparator) option.Function[DriftOptions] {
+	return func(o *DriftOptions) {
+		o.comparator = comparator
+	}
+}
+
+// WithDriftRebooter lets Drift reboot the candidates whose drift reason their NodePool resolves by rebooting, instead
+// of replacing them
+func WithDriftRebooter(rebooter cloudprovider.Rebooter) option.Function[DriftOptions] {
+	return func(o *DriftOptions) {
+		o.rebooter = rebooter
+	}
+}
+
+// driftComparator returns the comparator that orders drifted candidates. Each built-in ordering falls back to the
+// drift transition time so that candidates that compare equal are disrupted oldest first.
+func (d *Drift) driftComparator(ctx context.Context) DriftCandidateComparator {
+	if d.comparator != nil {
+		return d.comparator
+	}
+	switch options.FromContext(ctx).DriftOrdering {
+	case options.DriftOrderingFewestPodsFirst:
+		return DriftCandidateComparatorFunc(func(a, b *Candidate) bool {
+			if len(a.reschedulablePods) != len(b.reschedulablePods) {
+				return len(a.reschedulablePods) < len(b.reschedulablePods)
+			}
+			return driftedBefore(a, b)
+		})
+	case options.DriftOrderingCheapestFirst:
+		return DriftCandidateComparatorFunc(func(a, b *Candidate) bool {
+			if pa, pb := candidatePrice(a), candidatePrice(b); pa != pb {
+				return pa < pb
+			}
+			return driftedBefore(a, b)
+		})
+	default:
+		return DriftCandidateComparatorFunc(driftedBefore)
+	}
+}
+
+// driftedBefore returns true if candidate a drifted before candidate b
+func driftedBefore(a, b *Candidate) bool {
+	return a.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).LastTransitionTime.Time.Before(
+		b.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).LastTransitionTime.Time)
+}
+
+// candidatePrice returns the price of the candidate, ordering candidates with an unknown price last
+func candidatePrice(c *Candidate) float64 {
+	price, err := getCandidatePrices([]*Candidate{c})
+	return lo.Ternary(err != nil, math.MaxFloat64, price)
+}
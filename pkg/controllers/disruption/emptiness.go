@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/awslabs/operatorpkg/option"
+	"github.com/samber/lo"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -55,11 +56,11 @@ func (e *Emptiness) ShouldDisrupt(_ context.Context, c *Candidate) bool {
 // ComputeCommand generates a disruption command given candidates
 //
 //nolint:gocyclo
-func (e *Emptiness) ComputeCommands(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) ([]Command, error) {
+func (e *Emptiness) ComputeCommands(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) ([]Command, error) {
 	if e.IsConsolidated() {
 		return []Command{}, nil
 	}
-	candidates = e.sortCandidates(candidates)
+	candidates = e.sortCandidates(ctx, candidates)
 
 	empty := make([]*Candidate, 0, len(candidates))
 	constrainedByBudgets := false
@@ -67,15 +68,20 @@ func (e *Emptiness) ComputeCommands(ctx context.Context, disruptionBudgetMapping
 		if len(candidate.reschedulablePods) > 0 {
 			continue
 		}
-		if disruptionBudgetMapping[candidate.NodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(candidate) {
 			// set constrainedByBudgets to true if any node was a candidate but was constrained by a budget
 			constrainedByBudgets = true
+			recordBudgetSkipped(ctx, candidate.NodePool.Name, 1)
+			continue
+		}
+		// Empty nodes can't be removed if that would reduce the zonal diversity of the NodePool below its floor
+		if reducesZoneSpread(e.cluster, append(lo.Filter(empty, func(c *Candidate, _ int) bool { return c.NodePool.Name == candidate.NodePool.Name }), candidate), nil) {
 			continue
 		}
 		// If there's disruptions allowed for the candidate's nodepool,
 		// add it to the list of candidates, and decrement the budget.
 		empty = append(empty, candidate)
-		disruptionBudgetMapping[candidate.NodePool.Name]--
+		disruptionBudgetMapping.Consume(candidate)
 	}
 	// none empty, so do nothing
 	if len(empty) == 0 {
@@ -94,11 +100,13 @@ func (e *Emptiness) ComputeCommands(ctx context.Context, disruptionBudgetMapping
 	validCmd, err := e.validator.Validate(ctx, cmd, consolidationTTL)
 	if err != nil {
 		if IsValidationError(err) {
+			recordSkipped(ctx, skipReasonValidation, len(cmd.Candidates))
 			log.FromContext(ctx).V(1).WithValues(cmd.LogValues()...).Info("abandoning empty node consolidation attempt due to pod churn, command is no longer valid")
 			return []Command{}, nil
 		}
 		return []Command{}, err
 	}
+	recordSkipped(ctx, skipReasonValidation, len(cmd.Candidates)-len(validCmd.Candidates))
 	return []Command{validCmd}, nil
 }
 
@@ -18,10 +18,12 @@ limitations under the License.
 package disruption_test
 
 import (
+	"context"
 	"sort"
 	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
@@ -34,8 +36,10 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 )
 
 var _ = Describe("Emptiness", func() {
@@ -231,6 +235,107 @@ var _ = Describe("Emptiness", func() {
 
 			Expect(len(ExpectNodeClaims(ctx, env.Client))).To(Equal(7))
 		})
+		It("should only allow as many empty nodes to be disrupted as the cluster-wide rate limit", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionRateLimit: lo.ToPtr(2)}))
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: leastExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        leastExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       leastExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%"}}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(2))
+			ExpectMetricCounterValue(disruption.CandidatesSkippedTotal, float64(numNodes-2), map[string]string{
+				metrics.ReasonLabel:               string(v1.DisruptionReasonEmpty),
+				disruption.ConsolidationTypeLabel: "empty",
+				"skip_reason":                     "rate_limit",
+			})
+
+			// The rate limit is exhausted, so no further nodes are disrupted until the bucket refills
+			ExpectSingletonReconciled(ctx, disruptionController)
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		It("should only build as many candidates per loop as the candidate limit and continue with the next nodes", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionCandidateLimit: lo.ToPtr(4)}))
+			nodeClaims, nodes = test.NodeClaimsAndNodes(numNodes, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: leastExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        leastExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       leastExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%"}}
+
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := 0; i < numNodes; i++ {
+				nodeClaims[i].StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(4))
+
+			// The next loop builds candidates for the next nodes rather than the ones that were already evaluated
+			ExpectSingletonReconciled(ctx, disruptionController)
+			cmds = queue.GetCommands()
+			Expect(cmds).To(HaveLen(2))
+			candidates := lo.FlatMap(cmds, func(cmd *disruption.Command, _ int) []string {
+				return lo.Map(cmd.Candidates, func(c *disruption.Candidate, _ int) string { return c.Name() })
+			})
+			Expect(candidates).To(HaveLen(8))
+			Expect(lo.Uniq(candidates)).To(HaveLen(8))
+		})
+		It("should not disrupt empty nodes of NodePools in another shard", func() {
+			nodePoolShard := lo.Ternary(nodepoolutils.InShard(options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2)})), nodePool.Name), 0, 1)
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2), ShardIndex: lo.ToPtr(1 - nodePoolShard)}))
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+			ExpectSingletonReconciled(ctx, disruptionController)
+			Expect(queue.GetCommands()).To(HaveLen(0))
+
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2), ShardIndex: lo.ToPtr(nodePoolShard)}))
+			ExpectSingletonReconciled(ctx, disruptionController)
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
 		It("should allow 2 nodes from each nodePool to be deleted", func() {
 			// Create 10 nodepools
 			nps := test.NodePools(10, v1.NodePool{
@@ -364,6 +469,53 @@ var _ = Describe("Emptiness", func() {
 		})
 	})
 	Context("Emptiness", func() {
+		It("should keep the candidates of other nodePools when the candidates of a nodePool are no longer valid", func() {
+			nodePool2 := test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Disruption: v1.Disruption{
+						ConsolidateAfter:    v1.MustParseNillableDuration("0s"),
+						ConsolidationPolicy: v1.ConsolidationPolicyWhenEmpty,
+						Budgets:             []v1.Budget{{Nodes: "100%"}},
+					},
+				},
+			})
+			nodeClaim2.Labels[v1.NodePoolLabelKey] = nodePool2.Name
+			node2.Labels[v1.NodePoolLabelKey] = nodePool2.Name
+			ExpectApplied(ctx, env.Client, nodePool, nodePool2, nodeClaim, node, nodeClaim2, node2)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+
+			// The budget of the second nodePool is blocked after the command is computed
+			c := disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue)
+			emptyConsolidation := disruption.NewEmptiness(c, disruption.WithValidator(NewTestEmptinessValidator([]*corev1.Node{node2}, []*v1.NodeClaim{nodeClaim2}, nodePool2, WithEmptinessBlockingBudget())))
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, emptyConsolidation.Reason())
+			Expect(err).To(Succeed())
+			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, emptyConsolidation.ShouldDisrupt, emptyConsolidation.Class(), queue)
+			Expect(err).To(Succeed())
+
+			cmds, err := emptyConsolidation.ComputeCommands(ctx, budgets, candidates...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmds).To(HaveLen(1))
+			Expect(lo.Map(cmds[0].Candidates, func(c *disruption.Candidate, _ int) string { return c.NodePool.Name })).To(ConsistOf(nodePool.Name))
+			ExpectMetricCounterValue(disruption.FailedValidationsTotal, 1, map[string]string{disruption.ConsolidationTypeLabel: emptyConsolidation.ConsolidationType()})
+		})
+		It("should share the budget of a nodePool between its candidates during validation", func() {
+			nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%"}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, nodeClaim2, node2)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+
+			// The budget of the nodePool is lowered to a single node after the command is computed
+			c := disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue)
+			emptyConsolidation := disruption.NewEmptiness(c, disruption.WithValidator(NewTestEmptinessValidator([]*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2}, nodePool, WithEmptinessBudget("1"))))
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, emptyConsolidation.Reason())
+			Expect(err).To(Succeed())
+			candidates, err := disruption.GetCandidates(ctx, cluster, env.Client, recorder, fakeClock, cloudProvider, emptyConsolidation.ShouldDisrupt, emptyConsolidation.Class(), queue)
+			Expect(err).To(Succeed())
+
+			cmds, err := emptyConsolidation.ComputeCommands(ctx, budgets, candidates...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(1))
+		})
 		It("can delete empty nodes", func() {
 			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
 
@@ -400,6 +552,40 @@ var _ = Describe("Emptiness", func() {
 				metrics.ReasonLabel: "empty",
 			})
 		})
+		Context("Reason Priority", func() {
+			BeforeEach(func() {
+				nodePool.Spec.Disruption.Budgets = []v1.Budget{
+					{Nodes: "100%"},
+					{Nodes: "1", Reasons: []v1.DisruptionReason{v1.DisruptionReasonDrifted}},
+				}
+				ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, nodeClaim2, node2)
+
+				// inform cluster state about nodes and nodeclaims
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node, node2}, []*v1.NodeClaim{nodeClaim, nodeClaim2})
+
+				// Saturate the drift budget with an in-flight drift command
+				Expect(queue.StartCommand(ctx, &disruption.Command{
+					Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
+					CreationTimestamp: fakeClock.Now(),
+					ID:                uuid.New(),
+					Candidates:        []*disruption.Candidate{{StateNode: ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim), NodePool: nodePool}},
+				})).To(Succeed())
+			})
+			It("should not delete empty nodes while drift commands saturate the drift budget when drift has priority", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionReasonPriority: []string{string(v1.DisruptionReasonDrifted), string(v1.DisruptionReasonEmpty)}}))
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				Expect(queue.GetCommands()).To(HaveLen(1))
+				Expect(queue.HasAny(nodeClaim2.Status.ProviderID)).To(BeFalse())
+			})
+			It("should delete empty nodes while drift commands saturate the drift budget when emptiness has priority", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionReasonPriority: []string{string(v1.DisruptionReasonEmpty), string(v1.DisruptionReasonDrifted)}}))
+				ExpectSingletonReconciled(ctx, disruptionController)
+
+				Expect(queue.GetCommands()).To(HaveLen(2))
+				Expect(queue.HasAny(nodeClaim2.Status.ProviderID)).To(BeTrue())
+			})
+		})
 		It("should ignore nodes without the consolidatable status condition", func() {
 			_ = nodeClaim.StatusConditions().Clear(v1.ConditionTypeConsolidatable)
 			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
@@ -428,6 +614,73 @@ var _ = Describe("Emptiness", func() {
 			Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(1))
 			ExpectExists(ctx, env.Client, nodeClaim)
 		})
+		It("should ignore nodes of NodePools that paused disruption", func() {
+			nodePool.Spec.Disruption.Paused = true
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should ignore nodes that are excluded by the NodePool", func() {
+			nodePool.Spec.Disruption.ExcludeNodes = &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/stateful-canary": "true"}}
+			node.Labels = lo.Assign(node.Labels, map[string]string{"example.com/stateful-canary": "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should disrupt nodes that aren't excluded by the NodePool", func() {
+			nodePool.Spec.Disruption.ExcludeNodes = &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/stateful-canary": "true"}}
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(1))
+		})
+		It("should ignore nodes that are excluded by a registered candidate filter", func() {
+			disruptionController = disruption.NewController(fakeClock, env.Client, prov, cloudProvider, recorder, cluster, queue,
+				disruption.WithMethods(NewMethodsWithNopValidator()...),
+				disruption.WithCandidateFilters(func(_ context.Context, c *disruption.Candidate) bool {
+					return c.Labels()["example.com/compliance-capture"] != "true"
+				}),
+			)
+			node.Labels = lo.Assign(node.Labels, map[string]string{"example.com/compliance-capture": "true"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Expect to not create or delete more nodeclaims
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should ignore nodes that block emptiness with the karpenter.sh/do-not-disrupt-reasons annotation", func() {
+			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptReasonsAnnotationKey: "Empty"})
+			ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			Expect(queue.GetCommands()).To(HaveLen(0))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
 		It("should delete nodes with the karpenter.sh/do-not-disrupt annotation set to false", func() {
 			node.Annotations = lo.Assign(node.Annotations, map[string]string{v1.DoNotDisruptAnnotationKey: "false"})
 			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
@@ -752,6 +1005,63 @@ var _ = Describe("Emptiness", func() {
 		Expect(ExpectNodes(ctx, env.Client)).To(HaveLen(0))
 		ExpectNotFound(ctx, env.Client, nodeClaims[0], nodes[0])
 	})
+	Context("Zone Spread", func() {
+		BeforeEach(func() {
+			nodePool.Spec.ZoneSpread = &v1.ZoneSpread{MinZones: 2, MinNodes: 1}
+			nodeClaims, nodes = test.NodeClaimsAndNodes(3, v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey:            nodePool.Name,
+						corev1.LabelInstanceTypeStable: leastExpensiveInstance.Name,
+						v1.CapacityTypeLabelKey:        leastExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+						corev1.LabelTopologyZone:       "test-zone-1",
+					},
+				},
+				Status: v1.NodeClaimStatus{
+					Allocatable: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:  resource.MustParse("32"),
+						corev1.ResourcePods: resource.MustParse("100"),
+					},
+				},
+			})
+			nodeClaims[2].Labels[corev1.LabelTopologyZone] = "test-zone-2"
+			nodes[2].Labels[corev1.LabelTopologyZone] = "test-zone-2"
+			for _, nc := range nodeClaims {
+				nc.StatusConditions().SetTrue(v1.ConditionTypeConsolidatable)
+			}
+		})
+		It("should not remove empty nodes that would reduce the NodePool below its zone spread floor", func() {
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := range nodeClaims {
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			// Only one of the nodes in test-zone-1 can be removed, the last node in each zone is kept
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(1))
+			Expect(cmds[0].Candidates[0].Labels()).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-1"))
+		})
+		It("should remove empty nodes when the NodePool is below its minimum node count", func() {
+			nodePool.Spec.ZoneSpread.MinNodes = 4
+			ExpectApplied(ctx, env.Client, nodePool)
+			for i := range nodeClaims {
+				ExpectApplied(ctx, env.Client, nodeClaims[i], nodes[i])
+			}
+
+			// inform cluster state about nodes and nodeclaims
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+			ExpectSingletonReconciled(ctx, disruptionController)
+
+			cmds := queue.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Candidates).To(HaveLen(3))
+		})
+	})
 	Context("Static NodePool", func() {
 		It("should not consolidate static NodePool nodes", func() {
 			staticNp := test.StaticNodePool(v1.NodePool{
@@ -48,25 +48,103 @@ func WaitingOnReadiness(nodeClaim *v1.NodeClaim) events.Event {
 	}
 }
 
-func Terminating(node *corev1.Node, nodeClaim *v1.NodeClaim, reason string) []events.Event {
+func Terminating(node *corev1.Node, nodeClaim *v1.NodeClaim, reason, details string) []events.Event {
+	msg := cases.Title(language.Und, cases.NoLower).String(reason)
+	if details != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, details)
+	}
 	return []events.Event{
 		{
 			InvolvedObject: node,
 			Type:           corev1.EventTypeNormal,
 			Reason:         events.DisruptionTerminating,
-			Message:        fmt.Sprintf("Disrupting Node: %s", cases.Title(language.Und, cases.NoLower).String(reason)),
+			Message:        fmt.Sprintf("Disrupting Node: %s", msg),
 			DedupeValues:   []string{string(node.UID), reason},
 		},
 		{
 			InvolvedObject: nodeClaim,
 			Type:           corev1.EventTypeNormal,
 			Reason:         events.DisruptionTerminating,
-			Message:        fmt.Sprintf("Disrupting NodeClaim: %s", cases.Title(language.Und, cases.NoLower).String(reason)),
+			Message:        fmt.Sprintf("Disrupting NodeClaim: %s", msg),
 			DedupeValues:   []string{string(nodeClaim.UID), reason},
 		},
 	}
 }
 
+// Rebooted is an event that informs the user that a drifted NodeClaim/Node combination was rebooted in place instead
+// of being replaced
+func Rebooted(node *corev1.Node, nodeClaim *v1.NodeClaim, details string) (evs []events.Event) {
+	msg := "Rebooted instance in place"
+	if details != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, details)
+	}
+	if node != nil {
+		evs = append(evs, events.Event{
+			InvolvedObject: node,
+			Type:           corev1.EventTypeNormal,
+			Reason:         events.DisruptionRebooted,
+			Message:        msg,
+			DedupeValues:   []string{string(node.UID)},
+		})
+	}
+	evs = append(evs, events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DisruptionRebooted,
+		Message:        msg,
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	})
+	return evs
+}
+
+// RolledBack is an event that informs the user that a NodeClaim/Node combination is no longer being disrupted because
+// the replacements launched for it failed
+func RolledBack(node *corev1.Node, nodeClaim *v1.NodeClaim, reason string) (evs []events.Event) {
+	msg := fmt.Sprintf("Rolled back disruption (%s), replacements failed to launch or initialize", cases.Title(language.Und, cases.NoLower).String(reason))
+	if node != nil {
+		evs = append(evs, events.Event{
+			InvolvedObject: node,
+			Type:           corev1.EventTypeWarning,
+			Reason:         events.DisruptionRolledBack,
+			Message:        msg,
+			DedupeValues:   []string{string(node.UID), reason},
+		})
+	}
+	evs = append(evs, events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.DisruptionRolledBack,
+		Message:        msg,
+		DedupeValues:   []string{string(nodeClaim.UID), reason},
+	})
+	return evs
+}
+
+// DryRun is an event that informs the user that a NodeClaim/Node combination would have been disrupted if disruption
+// dry-run mode wasn't enabled
+func DryRun(node *corev1.Node, nodeClaim *v1.NodeClaim, reason, decision string) []events.Event {
+	msg := fmt.Sprintf("Would disrupt (%s) with decision %s, dry-run mode is enabled", cases.Title(language.Und, cases.NoLower).String(reason), decision)
+	evs := []events.Event{{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DisruptionDryRun,
+		Message:        msg,
+		DedupeValues:   []string{string(nodeClaim.UID), reason, decision},
+		DedupeTimeout:  time.Minute * 15,
+	}}
+	if node != nil {
+		evs = append(evs, events.Event{
+			InvolvedObject: node,
+			Type:           corev1.EventTypeNormal,
+			Reason:         events.DisruptionDryRun,
+			Message:        msg,
+			DedupeValues:   []string{string(node.UID), reason, decision},
+			DedupeTimeout:  time.Minute * 15,
+		})
+	}
+	return evs
+}
+
 // Unconsolidatable is an event that informs the user that a NodeClaim/Node combination cannot be consolidated
 // due to the state of the NodeClaim/Node or due to some state of the pods that are scheduled to the NodeClaim/Node
 func Unconsolidatable(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) []events.Event {
@@ -114,6 +192,19 @@ func Blocked(node *corev1.Node, nodeClaim *v1.NodeClaim, msg string) (evs []even
 	return evs
 }
 
+// PodBlocked is an event that informs the owner of a pod that the pod couldn't be rescheduled if its node was
+// disrupted, and that it's blocking the disruption of the node until its scheduling constraints can be satisfied
+func PodBlocked(pod *corev1.Pod, nodeName string, reason v1.DisruptionReason, err error) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DisruptionBlocked,
+		Message:        fmt.Sprintf("Pod is blocking %s disruption of node %s, it would not reschedule: %s", reason, nodeName, err),
+		DedupeValues:   []string{string(pod.UID), string(reason)},
+		DedupeTimeout:  time.Minute * 15,
+	}
+}
+
 func NodePoolBlockedForDisruptionReason(nodePool *v1.NodePool, reason v1.DisruptionReason) events.Event {
 	return events.Event{
 		InvolvedObject: nodePool,
@@ -136,3 +227,25 @@ func NodePoolBlocked(nodePool *v1.NodePool) events.Event {
 		DedupeTimeout: 1 * time.Minute,
 	}
 }
+
+func NodePoolDriftPaused(nodePool *v1.NodePool) events.Event {
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.DisruptionBlocked,
+		Message:        fmt.Sprintf("Drift is paused, %s", nodePool.StatusConditions().Get(v1.ConditionTypeDriftPaused).Message),
+		DedupeValues:   []string{string(nodePool.UID)},
+		DedupeTimeout:  1 * time.Minute,
+	}
+}
+
+func NodePoolDriftRolloutSoaking(nodePool *v1.NodePool, canaries int) events.Event {
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DisruptionBlocked,
+		Message:        fmt.Sprintf("Waiting for %d canary nodes to be ready for %s before continuing drift", canaries, nodePool.Spec.Disruption.DriftRollout.SoakDuration.Duration),
+		DedupeValues:   []string{string(nodePool.UID)},
+		DedupeTimeout:  1 * time.Minute,
+	}
+}
@@ -44,28 +44,29 @@ import (
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/pdb"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 var errCandidateDeleting = fmt.Errorf("candidate is deleting")
 
 //nolint:gocyclo
 func SimulateScheduling(ctx context.Context, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner,
-	candidates ...*Candidate,
+	clk clock.Clock, candidates ...*Candidate,
 ) (scheduling.Results, error) {
 	candidateNames := sets.NewString(lo.Map(candidates, func(t *Candidate, i int) string { return t.Name() })...)
-	nodes := cluster.DeepCopyNodes()
+	nodes := snapshotNodes(ctx, cluster)
 	deletingNodes := nodes.Deleting()
 	stateNodes := lo.Filter(nodes.Active(), func(n *state.StateNode, _ int) bool {
 		return !candidateNames.Has(n.Name())
 	})
 
-	// We do one final check to ensure that the node that we are attempting to consolidate isn't
-	// already handled for deletion by some other controller. This could happen if the node was markedForDeletion
-	// between returning the candidates and getting the stateNodes above
-	if _, ok := lo.Find(deletingNodes, func(n *state.StateNode) bool {
-		return candidateNames.Has(n.Name())
-	}); ok {
-		return scheduling.Results{}, errCandidateDeleting
+	// We do one final check against the current cluster state to ensure that the node that we are attempting to
+	// consolidate isn't already handled for deletion by some other controller. This could happen if the node was
+	// markedForDeletion after the snapshot that the candidates were built from was taken
+	for n := range cluster.Nodes() {
+		if candidateNames.Has(n.Name()) && n.MarkedForDeletion() {
+			return scheduling.Results{}, errCandidateDeleting
+		}
 	}
 
 	// start by getting all pending pods
@@ -77,19 +78,17 @@ func SimulateScheduling(ctx context.Context, kubeClient client.Client, cluster *
 	// Don't provision capacity for pods which will not get evicted due to fully blocking PDBs.
 	// Since Karpenter doesn't know when these pods will be successfully evicted, spinning up capacity until
 	// these pods are evicted is wasteful.
-	pdbs, err := pdb.NewLimits(ctx, kubeClient)
-	if err != nil {
-		return scheduling.Results{}, fmt.Errorf("tracking PodDisruptionBudgets, %w", err)
-	}
+	pdbs := snapshotPDBs(ctx, cluster)
 	for _, n := range candidates {
+		limits := candidatePDBs(pdbs, n.NodePool, n.disruptionClass)
 		currentlyReschedulablePods := lo.Filter(n.reschedulablePods, func(p *corev1.Pod, _ int) bool {
-			return pdbs.IsCurrentlyReschedulable(p)
+			return limits.IsCurrentlyReschedulable(p, clk)
 		})
 		pods = append(pods, currentlyReschedulablePods...)
 	}
 
 	// We get the pods that are on nodes that are deleting
-	deletingNodePods, err := deletingNodes.CurrentlyReschedulablePods(ctx, kubeClient)
+	deletingNodePods, err := deletingNodes.CurrentlyReschedulablePods(ctx, kubeClient, clk)
 	if err != nil {
 		return scheduling.Results{}, fmt.Errorf("failed to get pods from deleting nodes, %w", err)
 	}
@@ -163,6 +162,18 @@ func (u *UninitializedNodeError) Error() string {
 	return fmt.Sprintf("would schedule against uninitialized %s", strings.Join(info, ", "))
 }
 
+// publishPodsBlocked publishes an event to each pod of the candidate that wouldn't reschedule, so that the owners of
+// the pods learn which of their constraints is blocking the disruption. Pods that would only schedule against
+// uninitialized nodes are skipped since they aren't blocked by their constraints.
+func publishPodsBlocked(recorder events.Recorder, candidate *Candidate, reason v1.DisruptionReason, results scheduling.Results) {
+	for p, err := range results.NonPendingPodErrors() {
+		if _, ok := lo.ErrorsAs[*UninitializedNodeError](err); ok {
+			continue
+		}
+		recorder.Publish(disruptionevents.PodBlocked(p, candidate.Name(), reason, err))
+	}
+}
+
 // instanceTypesAreSubset returns true if the lhs slice of instance types are a subset of the rhs.
 func instanceTypesAreSubset(lhs []*cloudprovider.InstanceType, rhs []*cloudprovider.InstanceType) bool {
 	rhsNames := sets.NewString(lo.Map(rhs, func(t *cloudprovider.InstanceType, i int) string { return t.Name })...)
@@ -174,20 +185,43 @@ func instanceTypesAreSubset(lhs []*cloudprovider.InstanceType, rhs []*cloudprovi
 func GetCandidates(ctx context.Context, cluster *state.Cluster, kubeClient client.Client, recorder events.Recorder, clk clock.Clock,
 	cloudProvider cloudprovider.CloudProvider, shouldDisrupt CandidateFilter, disruptionClass string, queue *Queue,
 ) ([]*Candidate, error) {
+	candidates, _, err := getCandidates(ctx, snapshotNodes(ctx, cluster), snapshotPDBs(ctx, cluster), kubeClient, recorder, clk, cloudProvider, shouldDisrupt, disruptionClass, queue)
+	return candidates, err
+}
+
+// blockedCandidate is a node in a NodePool that wasn't a candidate because of a state.BlockedError
+type blockedCandidate struct {
+	nodePool string
+	err      error
+}
+
+// getCandidates returns the candidates along with the nodes that weren't candidates because their disruption was blocked
+func getCandidates(ctx context.Context, nodes state.StateNodes, pdbs pdb.Limits, kubeClient client.Client, recorder events.Recorder, clk clock.Clock,
+	cloudProvider cloudprovider.CloudProvider, shouldDisrupt CandidateFilter, disruptionClass string, queue *Queue,
+) ([]*Candidate, []blockedCandidate, error) {
 	nodePoolMap, nodePoolToInstanceTypesMap, err := BuildNodePoolMap(ctx, kubeClient, cloudProvider)
 	if err != nil {
-		return nil, err
-	}
-	pdbs, err := pdb.NewLimits(ctx, kubeClient)
-	if err != nil {
-		return nil, fmt.Errorf("tracking PodDisruptionBudgets, %w", err)
+		return nil, nil, err
 	}
-	candidates := lo.FilterMap(cluster.DeepCopyNodes(), func(n *state.StateNode, _ int) (*Candidate, bool) {
+	var blocked []blockedCandidate
+	candidates := lo.FilterMap(nodes, func(n *state.StateNode, _ int) (*Candidate, bool) {
 		cn, e := NewCandidate(ctx, kubeClient, recorder, clk, n, pdbs, nodePoolMap, nodePoolToInstanceTypesMap, queue, disruptionClass)
+		if nodePoolName := n.Labels()[v1.NodePoolLabelKey]; state.BlockedReason(e) != "" && nodepoolutils.InShard(ctx, nodePoolName) {
+			blocked = append(blocked, blockedCandidate{nodePool: nodePoolName, err: e})
+		}
 		return cn, e == nil
 	})
-	// Filter only the valid candidates that we should disrupt
-	return lo.Filter(candidates, func(c *Candidate, _ int) bool { return shouldDisrupt(ctx, c) }), nil
+	// Filter only the valid candidates that we should disrupt, leaving the NodePools of other shards to their replicas
+	candidates = lo.Filter(candidates, func(c *Candidate, _ int) bool {
+		return nodepoolutils.InShard(ctx, c.NodePool.Name) && shouldDisrupt(ctx, c)
+	})
+	inWindow, err := inMaintenanceWindow(ctx, kubeClient, clk)
+	if err != nil {
+		return nil, nil, err
+	}
+	candidates, outsideWindow := lo.FilterReject(candidates, func(c *Candidate, _ int) bool { return inWindow(ctx, c) })
+	recordSkipped(ctx, skipReasonMaintenanceWindow, len(outsideWindow))
+	return candidates, blocked, nil
 }
 
 // BuildNodePoolMap builds a provName -> nodePool map and a provName -> instanceName -> instance type map
@@ -226,13 +260,21 @@ func BuildNodePoolMap(ctx context.Context, kubeClient client.Client, cloudProvid
 
 // BuildDisruptionBudgets prepares our disruption budget mapping. The disruption budget maps each disruption reason to the number of allowed disruptions.
 // We calculate allowed disruptions by taking the max disruptions allowed by disruption reason and subtracting the number of nodes that are NotReady and already being deleted by that disruption reason.
+// Allowed pod evictions are calculated the same way, subtracting the reschedulable pods on those nodes.
 //
 //nolint:gocyclo
-func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, reason v1.DisruptionReason) (map[string]int, error) {
-	disruptionBudgetMapping := map[string]int{}
-	numNodes := map[string]int{}   // map[nodepool] -> node count in nodepool
-	disrupting := map[string]int{} // map[nodepool] -> nodes undergoing disruption
-	for _, node := range cluster.DeepCopyNodes() {
+func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, reason v1.DisruptionReason) (DisruptionBudgetMapping, error) {
+	disruptionBudgetMapping := DisruptionBudgetMapping{}
+	numNodes := map[string]int{}                            // map[nodepool] -> node count in nodepool
+	disrupting := map[string]int{}                          // map[nodepool] -> nodes undergoing disruption
+	disruptingPods := map[string]int{}                      // map[nodepool] -> reschedulable pods on nodes undergoing disruption
+	disruptingResources := map[string]corev1.ResourceList{} // map[nodepool] -> capacity of nodes undergoing disruption
+	disruptingByDriftReason := map[string]map[string]int{}  // map[nodepool][driftReason] -> drifted nodes undergoing disruption for the drift reason
+	numNodesByRegion := map[string]map[string]int{}         // map[nodepool][region] -> node count in the nodepool's region
+	disruptingByRegion := map[string]map[string]int{}       // map[nodepool][region] -> nodes undergoing disruption in the nodepool's region
+	numNodesByZone := map[string]map[string]int{}           // map[nodepool][zone] -> node count in the nodepool's zone
+	disruptingByZone := map[string]map[string]int{}         // map[nodepool][zone] -> nodes undergoing disruption in the nodepool's zone
+	for _, node := range snapshotNodes(ctx, cluster) {
 		// We only consider nodes that we own and are initialized towards the total.
 		// If a node is launched/registered, but not initialized, pods aren't scheduled
 		// to the node, and these are treated as unhealthy until they're cleaned up.
@@ -253,12 +295,36 @@ func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, c
 
 		nodePool := node.Labels()[v1.NodePoolLabelKey]
 		numNodes[nodePool]++
+		region := node.Labels()[corev1.LabelTopologyRegion]
+		if numNodesByRegion[nodePool] == nil {
+			numNodesByRegion[nodePool], disruptingByRegion[nodePool] = map[string]int{}, map[string]int{}
+		}
+		numNodesByRegion[nodePool][region]++
+		zone := node.Labels()[corev1.LabelTopologyZone]
+		if numNodesByZone[nodePool] == nil {
+			numNodesByZone[nodePool], disruptingByZone[nodePool] = map[string]int{}, map[string]int{}
+		}
+		numNodesByZone[nodePool][zone]++
 
 		// If the node satisfies one of the following, we subtract it from the allowed disruptions.
 		// 1. Has a NotReady conditiion
 		// 2. Is marked as disrupting
 		if cond := nodeutils.GetCondition(node.Node, corev1.NodeReady); cond.Status != corev1.ConditionTrue || node.MarkedForDeletion() {
 			disrupting[nodePool]++
+			disruptingByRegion[nodePool][region]++
+			disruptingByZone[nodePool][zone]++
+			pods, err := node.CurrentlyReschedulablePods(ctx, kubeClient, clk)
+			if err != nil {
+				return disruptionBudgetMapping, fmt.Errorf("listing pods on disrupting node, %w", err)
+			}
+			disruptingPods[nodePool] += len(pods)
+			disruptingResources[nodePool] = resources.Merge(disruptingResources[nodePool], node.Capacity())
+			if cond := node.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted); cond.IsTrue() {
+				if disruptingByDriftReason[nodePool] == nil {
+					disruptingByDriftReason[nodePool] = map[string]int{}
+				}
+				disruptingByDriftReason[nodePool][cond.Reason]++
+			}
 		}
 	}
 	nodePools, err := nodepoolutils.ListManaged(ctx, kubeClient, cloudProvider)
@@ -267,7 +333,31 @@ func BuildDisruptionBudgetMapping(ctx context.Context, cluster *state.Cluster, c
 	}
 	for _, nodePool := range nodePools {
 		allowedDisruptions := nodePool.MustGetAllowedDisruptions(clk, numNodes[nodePool.Name], reason)
-		disruptionBudgetMapping[nodePool.Name] = lo.Max([]int{allowedDisruptions - disrupting[nodePool.Name], 0})
+		allowedPodDisruptions := nodePool.MustGetAllowedPodDisruptions(clk, reason)
+		budget := DisruptionBudget{
+			Nodes: lo.Max([]int{allowedDisruptions - disrupting[nodePool.Name], 0}),
+			Pods:  lo.Max([]int{allowedPodDisruptions - disruptingPods[nodePool.Name], 0}),
+			Idle:  disrupting[nodePool.Name] == 0,
+		}
+		if allowedResources := nodePool.MustGetAllowedResourceDisruptions(clk, reason); allowedResources != nil {
+			budget.Resources = resources.Subtract(allowedResources, disruptingResources[nodePool.Name])
+		}
+		if reason == v1.DisruptionReasonDrifted && len(nodePool.DriftReasons()) > 0 {
+			budget.DriftReasons = lo.SliceToMap(nodePool.DriftReasons(), func(driftReason string) (string, int) {
+				return driftReason, lo.Max([]int{nodePool.MustGetAllowedDisruptionsByDriftReason(clk, numNodes[nodePool.Name], driftReason) - disruptingByDriftReason[nodePool.Name][driftReason], 0})
+			})
+		}
+		if len(nodePool.Regions()) > 0 {
+			budget.Regions = lo.SliceToMap(nodePool.Regions(), func(region string) (string, int) {
+				return region, lo.Max([]int{nodePool.MustGetAllowedDisruptionsByRegion(clk, numNodesByRegion[nodePool.Name][region], reason, region) - disruptingByRegion[nodePool.Name][region], 0})
+			})
+		}
+		if len(nodePool.Zones()) > 0 {
+			budget.Zones = lo.SliceToMap(nodePool.Zones(), func(zone string) (string, int) {
+				return zone, lo.Max([]int{nodePool.MustGetAllowedDisruptionsByZone(clk, numNodesByZone[nodePool.Name][zone], reason, zone) - disruptingByZone[nodePool.Name][zone], 0})
+			})
+		}
+		disruptionBudgetMapping[nodePool.Name] = budget
 		NodePoolAllowedDisruptions.Set(float64(allowedDisruptions), map[string]string{
 			metrics.NodePoolLabel: nodePool.Name, metrics.ReasonLabel: string(reason),
 		})
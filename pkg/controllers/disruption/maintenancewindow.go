@@ -0,0 +1,66 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+)
+
+type maintenanceWindow struct {
+	selector labels.Selector
+	open     bool
+}
+
+// inMaintenanceWindow returns a CandidateFilter that rejects candidates that are selected by MaintenanceWindows when
+// none of those windows are open. Candidates that aren't selected by any MaintenanceWindow can always be disrupted.
+func inMaintenanceWindow(ctx context.Context, kubeClient client.Client, clk clock.Clock) (CandidateFilter, error) {
+	windowList := &v1alpha1.MaintenanceWindowList{}
+	if err := kubeClient.List(ctx, windowList); err != nil {
+		return nil, fmt.Errorf("listing maintenance windows, %w", err)
+	}
+	windows := lo.FilterMap(windowList.Items, func(mw v1alpha1.MaintenanceWindow, _ int) (maintenanceWindow, bool) {
+		selector := labels.Everything()
+		if mw.Spec.NodeSelector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(mw.Spec.NodeSelector); err != nil {
+				log.FromContext(ctx).WithValues("MaintenanceWindow", klog.KObj(&mw)).Error(err, "ignoring maintenance window, invalid node selector")
+				return maintenanceWindow{}, false
+			}
+		}
+		// If the maintenance window is misconfigured, fail closed.
+		open, err := mw.IsOpen(clk)
+		if err != nil {
+			log.FromContext(ctx).WithValues("MaintenanceWindow", klog.KObj(&mw)).Error(err, "invalid maintenance window schedule")
+		}
+		return maintenanceWindow{selector: selector, open: open}, true
+	})
+	return func(_ context.Context, c *Candidate) bool {
+		selecting := lo.Filter(windows, func(w maintenanceWindow, _ int) bool { return w.selector.Matches(labels.Set(c.Labels())) })
+		return len(selecting) == 0 || lo.ContainsBy(selecting, func(w maintenanceWindow) bool { return w.open })
+	}, nil
+}
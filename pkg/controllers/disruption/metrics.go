@@ -29,6 +29,25 @@ const (
 	decisionLabel                = "decision"
 	ConsolidationTypeLabel       = "consolidation_type"
 	CandidatesIneligible         = "candidates_ineligible"
+	skipReasonLabel              = "skip_reason"
+	failureReasonLabel           = "failure_reason"
+	blockedReasonLabel           = "blocked_reason"
+)
+
+// Reasons that a node was blocked from disruption, in addition to the reasons of a state.BlockedError
+const (
+	blockedReasonDeadLettered     = "dead_lettered"
+	blockedReasonNodePoolNotFound = "nodepool_not_found"
+	blockedReasonPaused           = "paused"
+	blockedReasonExcluded         = "excluded"
+	blockedReasonBudgetExhausted  = "budget_exhausted"
+)
+
+// Reasons that an enqueued disruption command failed
+const (
+	failureReasonReplacementFailed = "replacement_failed"
+	failureReasonTimedOut          = "timed_out"
+	failureReasonOther             = "other"
 )
 
 func init() {
@@ -58,6 +77,16 @@ var (
 		},
 		[]string{decisionLabel, metrics.ReasonLabel, ConsolidationTypeLabel},
 	)
+	DryRunDecisionsTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "dry_run_decisions_total",
+			Help:      "Number of disruption decisions that were recorded instead of executed because dry-run mode is enabled. Labeled by disruption decision, reason, and consolidation type.",
+		},
+		[]string{decisionLabel, metrics.ReasonLabel, ConsolidationTypeLabel},
+	)
 	EligibleNodes = opmetrics.NewPrometheusGauge(
 		crmetrics.Registry,
 		prometheus.GaugeOpts{
@@ -88,6 +117,16 @@ var (
 		},
 		[]string{ConsolidationTypeLabel},
 	)
+	CandidatesSkippedTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "candidates_skipped_total",
+			Help:      "Number of candidates that were skipped while evaluating a disruption method. Labeled by reason, consolidation type, and skip reason.",
+		},
+		[]string{metrics.ReasonLabel, ConsolidationTypeLabel, skipReasonLabel},
+	)
 	NodePoolAllowedDisruptions = opmetrics.NewPrometheusGauge(
 		crmetrics.Registry,
 		prometheus.GaugeOpts{
@@ -98,6 +137,26 @@ var (
 		},
 		[]string{metrics.NodePoolLabel, metrics.ReasonLabel},
 	)
+	DeadLetteredCandidates = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "dead_lettered_candidates",
+			Help:      "The number of candidates that are excluded from disruption because their disruption commands failed repeatedly. Labeled by the reason of the last failed command.",
+		},
+		[]string{metrics.ReasonLabel},
+	)
+	BlockedCandidates = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "blocked_candidates",
+			Help:      "The number of nodes that were blocked from disruption in the latest evaluation of a disruption method. Labeled by NodePool, reason, and blocked reason, either pdb, do_not_disrupt, nominated, budget_exhausted, nodepool_not_found, paused, excluded, or dead_lettered.",
+		},
+		[]string{metrics.NodePoolLabel, metrics.ReasonLabel, blockedReasonLabel},
+	)
 	DisruptionQueueFailuresTotal = opmetrics.NewPrometheusCounter(
 		crmetrics.Registry,
 		prometheus.CounterOpts{
@@ -108,4 +167,45 @@ var (
 		},
 		[]string{decisionLabel, metrics.ReasonLabel, ConsolidationTypeLabel},
 	)
+	QueueCommandFailuresTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "queue_command_failures_total",
+			Help:      "The number of enqueued disruption commands that failed. Labeled by reason and by why the command failed, either replacement_failed, timed_out, or other.",
+		},
+		[]string{metrics.ReasonLabel, failureReasonLabel},
+	)
+	QueueDepth = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "queue_depth",
+			Help:      "The number of disruption commands in the orchestration queue. Labeled by reason.",
+		},
+		[]string{metrics.ReasonLabel},
+	)
+	QueueOldestCommandAgeSeconds = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "queue_oldest_command_age_seconds",
+			Help:      "The age of the oldest disruption command in the orchestration queue. Labeled by reason.",
+		},
+		[]string{metrics.ReasonLabel},
+	)
+	ReplacementWaitDurationSeconds = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: voluntaryDisruptionSubsystem,
+			Name:      "replacement_wait_duration_seconds",
+			Help:      "The time from when a disruption command was computed until all of its replacements initialized. Labeled by reason.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{metrics.ReasonLabel},
+	)
 )
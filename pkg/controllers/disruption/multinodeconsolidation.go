@@ -48,11 +48,11 @@ func NewMultiNodeConsolidation(c consolidation, opts ...option.Function[MethodOp
 	}
 }
 
-func (m *MultiNodeConsolidation) ComputeCommands(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) ([]Command, error) {
+func (m *MultiNodeConsolidation) ComputeCommands(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) ([]Command, error) {
 	if m.IsConsolidated() {
 		return []Command{}, nil
 	}
-	candidates = m.sortCandidates(candidates)
+	candidates = m.sortCandidates(ctx, candidates)
 
 	// In order, filter out all candidates that would violate the budget.
 	// Since multi-node consolidation relies on the ordering of
@@ -66,8 +66,9 @@ func (m *MultiNodeConsolidation) ComputeCommands(ctx context.Context, disruption
 	for _, candidate := range candidates {
 		// If there's disruptions allowed for the candidate's nodepool,
 		// add it to the list of candidates, and decrement the budget.
-		if disruptionBudgetMapping[candidate.NodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(candidate) {
 			constrainedByBudgets = true
+			recordBudgetSkipped(ctx, candidate.NodePool.Name, 1)
 			continue
 		}
 		// Filter out empty candidates. If there was an empty node that wasn't consolidated before this, we should
@@ -78,7 +79,7 @@ func (m *MultiNodeConsolidation) ComputeCommands(ctx context.Context, disruption
 		}
 		// set constrainedByBudgets to true if any node was a candidate but was constrained by a budget
 		disruptableCandidates = append(disruptableCandidates, candidate)
-		disruptionBudgetMapping[candidate.NodePool.Name]--
+		disruptionBudgetMapping.Consume(candidate)
 	}
 
 	// Only consider a maximum batch of 100 NodeClaims to save on computation.
@@ -102,7 +103,7 @@ func (m *MultiNodeConsolidation) ComputeCommands(ctx context.Context, disruption
 
 	if cmd, err = m.validator.Validate(ctx, cmd, consolidationTTL); err != nil {
 		if IsValidationError(err) {
-			log.FromContext(ctx).V(1).WithValues(cmd.LogValues()...).Info("abandoning multi-node consolidation attempt due to pod churn, command is no longer valid")
+			recordSkipped(ctx, skipReasonValidation, len(cmd.Candidates))
 			return []Command{}, nil
 		}
 		return []Command{}, fmt.Errorf("validating consolidation, %w", err)
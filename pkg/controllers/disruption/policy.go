@@ -0,0 +1,145 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+)
+
+// decisionPolicyEnv declares the variables that are available to NodePool decision policies
+var decisionPolicyEnv = lo.Must(cel.NewEnv(cel.Variable("command", cel.MapType(cel.StringType, cel.DynType))))
+
+// decisionPrograms caches the compiled decision policies by expression
+var decisionPrograms sync.Map
+
+// evaluateDecisionPolicies evaluates the decision policies of every NodePool that owns a candidate of the command.
+// It returns false if any policy vetoes the command. Policies that fail to compile or evaluate veto the command, so
+// that a broken policy doesn't silently allow disruptions that it was meant to prevent.
+func (c *Controller) evaluateDecisionPolicies(ctx context.Context, cmd *Command) bool {
+	nodePools := lo.UniqBy(lo.Map(cmd.Candidates, func(candidate *Candidate, _ int) *v1.NodePool { return candidate.NodePool }),
+		func(nodePool *v1.NodePool) string { return nodePool.Name })
+	if lo.NoneBy(nodePools, func(nodePool *v1.NodePool) bool { return len(nodePool.Spec.Disruption.DecisionPolicies) > 0 }) {
+		return true
+	}
+	summary, err := commandSummary(cmd)
+	if err != nil {
+		c.vetoCommand(ctx, cmd, fmt.Sprintf("Unable to summarize disruption command for decision policies, %s", err))
+		return false
+	}
+	for _, nodePool := range nodePools {
+		for _, policy := range nodePool.Spec.Disruption.DecisionPolicies {
+			approved, err := evaluateDecisionPolicy(policy, summary)
+			if err != nil {
+				c.vetoCommand(ctx, cmd, fmt.Sprintf("Decision policy %q of NodePool %q failed, %s", policy.Name, nodePool.Name, err))
+				return false
+			}
+			if !approved {
+				c.vetoCommand(ctx, cmd, fmt.Sprintf("Decision policy %q of NodePool %q vetoed %s", policy.Name, nodePool.Name, cmd.Reason()))
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *Controller) vetoCommand(ctx context.Context, cmd *Command, msg string) {
+	log.FromContext(ctx).WithValues(cmd.LogValues()...).V(1).Info(msg)
+	for _, candidate := range cmd.Candidates {
+		c.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, msg)...)
+	}
+}
+
+// evaluateDecisionPolicy returns true if the policy approves the command described by the summary
+func evaluateDecisionPolicy(policy v1.DecisionPolicy, summary map[string]any) (bool, error) {
+	prg, err := compileDecisionPolicy(policy.Expression)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := prg.Eval(map[string]any{"command": summary})
+	if err != nil {
+		return false, fmt.Errorf("evaluating expression, %w", err)
+	}
+	approved, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %s, expected bool", out.Type().TypeName())
+	}
+	return approved, nil
+}
+
+func compileDecisionPolicy(expression string) (cel.Program, error) {
+	if prg, ok := decisionPrograms.Load(expression); ok {
+		return prg.(cel.Program), nil
+	}
+	ast, iss := decisionPolicyEnv.Compile(expression)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("compiling expression, %w", iss.Err())
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression must evaluate to bool, got %s", ast.OutputType())
+	}
+	prg, err := decisionPolicyEnv.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("building program, %w", err)
+	}
+	decisionPrograms.Store(expression, prg)
+	return prg, nil
+}
+
+// commandSummary describes the command to decision policies. The price delta is the difference between the
+// cheapest launch price of the replacements and the price of the candidates, so it's negative for commands that
+// save money.
+func commandSummary(cmd *Command) (map[string]any, error) {
+	candidatePrice, err := getCandidatePrices(cmd.Candidates)
+	if err != nil {
+		return nil, err
+	}
+	replacementPrice := lo.SumBy(cmd.Replacements, func(r *Replacement) float64 {
+		return lo.Min(lo.Map(r.InstanceTypeOptions, func(it *cloudprovider.InstanceType, _ int) float64 {
+			return it.Offerings.Available().WorstLaunchPrice(r.Requirements)
+		}))
+	})
+	return map[string]any{
+		"reason":           string(cmd.Reason()),
+		"decision":         string(cmd.Decision()),
+		"candidateCount":   int64(len(cmd.Candidates)),
+		"podCount":         int64(lo.SumBy(cmd.Candidates, func(c *Candidate) int { return len(c.reschedulablePods) })),
+		"replacementCount": int64(len(cmd.Replacements)),
+		"priceDelta":       replacementPrice - candidatePrice,
+		"candidates": lo.Map(cmd.Candidates, func(c *Candidate, _ int) any {
+			return map[string]any{
+				"name":         c.NodeClaim.Name,
+				"nodePool":     c.NodePool.Name,
+				"instanceType": c.Labels()[corev1.LabelInstanceTypeStable],
+				"capacityType": c.capacityType,
+				"region":       c.region,
+				"zone":         c.zone,
+				"podCount":     int64(len(c.reschedulablePods)),
+			}
+		}),
+	}, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+// maxPrecheckBlockedPods is the number of blocked pods recorded on a DisruptionDecision
+const maxPrecheckBlockedPods = 20
+
+// precheckEvictions issues dry-run evictions for the reschedulable pods of the command's candidates to detect pods
+// that would stall the drain of a candidate, e.g. due to a blocking PDB or an admission webhook. Candidates with pods
+// that would be denied eviction are removed from commands that don't launch replacements, since the rest of the
+// candidates can still be disrupted without them. Commands that launch replacements were simulated with every
+// candidate, so they're skipped instead. It returns false if the command should be skipped.
+func (c *Controller) precheckEvictions(ctx context.Context, cmd *Command) bool {
+	var blockedPods []string
+	blocked := sets.New[string]()
+	for _, candidate := range cmd.Candidates {
+		if denied := deniedEvictions(ctx, c.kubeClient, c.clock, candidate); len(denied) > 0 {
+			blockedPods = append(blockedPods, lo.Map(denied, func(p *corev1.Pod, _ int) string { return client.ObjectKeyFromObject(p).String() })...)
+			blocked.Insert(candidate.NodeClaim.Name)
+		}
+	}
+	if blocked.Len() == 0 {
+		cmd.evictionPrecheck = &v1alpha1.EvictionPrecheck{Outcome: v1alpha1.EvictionPrecheckOutcomePassed}
+		return true
+	}
+	for _, candidate := range cmd.Candidates {
+		if blocked.Has(candidate.NodeClaim.Name) {
+			c.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, "Pods on node would be denied eviction")...)
+		}
+	}
+	cmd.evictionPrecheck = &v1alpha1.EvictionPrecheck{BlockedPods: lo.Slice(blockedPods, 0, maxPrecheckBlockedPods)}
+	remaining := lo.Reject(cmd.Candidates, func(candidate *Candidate, _ int) bool { return blocked.Has(candidate.NodeClaim.Name) })
+	if len(cmd.Replacements) > 0 || len(remaining) == 0 {
+		cmd.evictionPrecheck.Outcome = v1alpha1.EvictionPrecheckOutcomeSkipped
+		return false
+	}
+	cmd.evictionPrecheck.Outcome = v1alpha1.EvictionPrecheckOutcomeDowngraded
+	cmd.Candidates = remaining
+	return true
+}
+
+// deniedEvictions returns the reschedulable pods of the candidate whose eviction the apiserver would deny. Unlike the
+// PDB checks of NewCandidate, this reflects admission webhooks and ValidatingAdmissionPolicies on evictions.
+func deniedEvictions(ctx context.Context, kubeClient client.Client, clk clock.Clock, candidate *Candidate) []*corev1.Pod {
+	var denied []*corev1.Pod
+	for _, p := range candidate.reschedulablePods {
+		if !podutils.IsEvictable(p, clk) {
+			continue
+		}
+		if err := dryRunEviction(ctx, kubeClient, p); err != nil {
+			log.FromContext(ctx).WithValues("Pod", client.ObjectKeyFromObject(p), "NodeClaim", candidate.NodeClaim.Name).V(1).Info(fmt.Sprintf("dry-run eviction denied, %s", err))
+			denied = append(denied, p)
+		}
+	}
+	return denied
+}
+
+// dryRunEviction returns an error if the eviction of the pod would be denied
+func dryRunEviction(ctx context.Context, kubeClient client.Client, pod *corev1.Pod) error {
+	err := kubeClient.SubResource("eviction").Create(ctx,
+		pod,
+		&policyv1.Eviction{
+			DeleteOptions: &metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{
+					UID: lo.ToPtr(pod.UID),
+				},
+			},
+		},
+		&client.SubResourceCreateOptions{CreateOptions: client.CreateOptions{DryRun: []string{metav1.DryRunAll}}},
+	)
+	// 404 and 409 mean that the pod no longer exists, so it won't block the drain
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+const pollingPeriod = 30 * time.Second
+
+// Controller watches the prices of the offerings available to each NodePool and marks the cluster as unconsolidated
+// when a price changes by more than the price change threshold, so that consolidation is evaluated for the affected
+// NodePools without waiting for the cluster's consolidation state to be refreshed periodically. Evaluations are
+// triggered at most once per minimum interval, so that volatile pricing doesn't cause consolidation to thrash.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+
+	prices        map[string]map[string]float64 // map[nodepool][offering] -> price
+	pending       sets.Set[string]              // nodepools whose prices changed since the last triggered evaluation
+	lastTriggered time.Time
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster) *Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		cluster:       cluster,
+		prices:        map[string]map[string]float64{},
+		pending:       sets.New[string](),
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, "disruption.pricing")
+
+	threshold := options.FromContext(ctx).PriceChangeThreshold
+	if threshold == 0 {
+		return reconciler.Result{}, nil
+	}
+	nodePools, err := nodepoolutils.ListManaged(ctx, c.kubeClient, c.cloudProvider)
+	if err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing nodepools, %w", err)
+	}
+	prices := map[string]map[string]float64{}
+	for _, nodePool := range nodePools {
+		instanceTypes, err := c.cloudProvider.GetInstanceTypes(ctx, nodePool)
+		if err != nil {
+			log.FromContext(ctx).WithValues("NodePool", klog.KObj(nodePool)).Error(err, "failed listing instance types")
+			prices[nodePool.Name] = c.prices[nodePool.Name]
+			continue
+		}
+		prices[nodePool.Name] = offeringPrices(instanceTypes)
+		// Consolidation is disabled for the NodePool, so its price changes can't be acted on
+		if nodePool.Spec.Disruption.ConsolidateAfter.Duration == nil {
+			continue
+		}
+		if previous, ok := c.prices[nodePool.Name]; ok && priceChanged(previous, prices[nodePool.Name], threshold) {
+			c.pending.Insert(nodePool.Name)
+		}
+	}
+	c.prices = prices
+
+	if c.pending.Len() == 0 || c.clock.Since(c.lastTriggered) < options.FromContext(ctx).PriceChangeMinInterval {
+		return reconciler.Result{RequeueAfter: pollingPeriod}, nil
+	}
+	log.FromContext(ctx).WithValues("NodePools", sets.List(c.pending)).V(1).Info("evaluating consolidation, offering prices changed")
+	for _, nodePool := range sets.List(c.pending) {
+		PriceChangeEvaluationsTotal.Inc(map[string]string{metrics.NodePoolLabel: nodePool})
+	}
+	c.cluster.MarkUnconsolidated()
+	c.lastTriggered = c.clock.Now()
+	c.pending = sets.New[string]()
+	return reconciler.Result{RequeueAfter: pollingPeriod}, nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("disruption.pricing").
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
+
+// offeringPrices maps each available offering of the instance types to its price
+func offeringPrices(instanceTypes []*cloudprovider.InstanceType) map[string]float64 {
+	prices := map[string]float64{}
+	for _, it := range instanceTypes {
+		for _, o := range it.Offerings.Available() {
+			prices[fmt.Sprintf("%s/%s", it.Name, o.Requirements.String())] = o.Price
+		}
+	}
+	return prices
+}
+
+// priceChanged returns true if the price of an offering that was and still is available changed by at least the
+// threshold percentage
+func priceChanged(previous, current map[string]float64, threshold int) bool {
+	return lo.SomeBy(lo.Entries(current), func(e lo.Entry[string, float64]) bool {
+		price, ok := previous[e.Key]
+		if !ok || price == 0 {
+			return false
+		}
+		return math.Abs(e.Value-price)/price*100 >= float64(threshold)
+	})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/disruption/pricing"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	ctx           context.Context
+	env           *test.Environment
+	fakeClock     *clock.FakeClock
+	cloudProvider *fake.CloudProvider
+	cluster       *state.Cluster
+	controller    *pricing.Controller
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pricing")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	fakeClock = clock.NewFakeClock(time.Now())
+	cloudProvider = fake.NewCloudProvider()
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PriceChangeThreshold: lo.ToPtr(20)}))
+	fakeClock.SetTime(time.Now())
+	cloudProvider.Reset()
+	cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{fake.NewInstanceType(fake.InstanceTypeOptions{Name: "default-instance-type"})}
+	controller = pricing.NewController(fakeClock, env.Client, cloudProvider, cluster)
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("Pricing", func() {
+	var nodePool *v1.NodePool
+
+	// setPrice sets the price of every offering of the instance type
+	setPrice := func(price float64) {
+		for _, o := range cloudProvider.InstanceTypes[0].Offerings {
+			o.Price = price
+		}
+	}
+	// expectEvaluationTriggered reconciles the controller and checks whether it marked the cluster as unconsolidated
+	expectEvaluationTriggered := func(triggered bool) {
+		GinkgoHelper()
+		fakeClock.Step(10 * time.Second)
+		consolidationState := cluster.ConsolidationState()
+		ExpectSingletonReconciled(ctx, controller)
+		Expect(cluster.ConsolidationState().Equal(consolidationState)).To(Equal(!triggered))
+	}
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		setPrice(1.0)
+		ExpectApplied(ctx, env.Client, nodePool)
+		// Record the initial prices
+		ExpectSingletonReconciled(ctx, controller)
+		cluster.MarkUnconsolidated()
+	})
+	It("should trigger an evaluation when a price changes beyond the threshold", func() {
+		setPrice(1.5)
+		expectEvaluationTriggered(true)
+		ExpectMetricCounterValue(pricing.PriceChangeEvaluationsTotal, 1, map[string]string{metrics.NodePoolLabel: nodePool.Name})
+	})
+	It("should not trigger an evaluation when a price changes within the threshold", func() {
+		setPrice(1.1)
+		expectEvaluationTriggered(false)
+	})
+	It("should not trigger an evaluation for NodePools that don't consolidate", func() {
+		nodePool.Spec.Disruption.ConsolidateAfter = v1.MustParseNillableDuration("Never")
+		ExpectApplied(ctx, env.Client, nodePool)
+		setPrice(0.5)
+		expectEvaluationTriggered(false)
+	})
+	It("should rate limit the evaluations triggered by price changes", func() {
+		setPrice(1.5)
+		expectEvaluationTriggered(true)
+		setPrice(1.0)
+		expectEvaluationTriggered(false)
+		// The price change is still pending, and is evaluated once the minimum interval has passed
+		fakeClock.Step(time.Minute)
+		expectEvaluationTriggered(true)
+	})
+	It("should not trigger evaluations when disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PriceChangeThreshold: lo.ToPtr(0)}))
+		setPrice(1.5)
+		expectEvaluationTriggered(false)
+	})
+})
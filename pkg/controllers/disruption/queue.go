@@ -24,12 +24,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/awslabs/operatorpkg/option"
 	"github.com/awslabs/operatorpkg/serrors"
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
 	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
@@ -48,13 +51,19 @@ import (
 	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/stream"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 )
 
@@ -67,6 +76,12 @@ const (
 	retryDurationScale      = 80 * time.Millisecond
 )
 
+var (
+	errCommandTimedOut     = stderrors.New("command reached timeout")
+	errReplacementDeleted  = stderrors.New("replacement was deleted")
+	errReplacementTimedOut = stderrors.New("replacement didn't initialize before the replacement timeout")
+)
+
 type UnrecoverableError struct {
 	error
 }
@@ -100,12 +115,31 @@ type Queue struct {
 	cluster             *state.Cluster
 	clock               clock.Clock
 	provisioner         *provisioning.Provisioner
+	DeadLetters         *DeadLetters
+	rebooter            cloudprovider.Rebooter
+	evictionQueue       *terminator.Queue
+	recovered           bool // whether the commands persisted before a restart have been recovered
+}
+
+type QueueOptions struct {
+	rebooter      cloudprovider.Rebooter
+	evictionQueue *terminator.Queue
+}
+
+// WithQueueRebooter lets the queue execute reboot commands. The candidates of reboot commands are drained through the
+// eviction queue before their instances are rebooted.
+func WithQueueRebooter(rebooter cloudprovider.Rebooter, evictionQueue *terminator.Queue) option.Function[QueueOptions] {
+	return func(o *QueueOptions) {
+		o.rebooter = rebooter
+		o.evictionQueue = evictionQueue
+	}
 }
 
 // NewQueue creates a queue that will asynchronously orchestrate disruption commands
 func NewQueue(kubeClient client.Client, recorder events.Recorder, cluster *state.Cluster, clock clock.Clock,
-	provisioner *provisioning.Provisioner,
+	provisioner *provisioning.Provisioner, opts ...option.Function[QueueOptions],
 ) *Queue {
+	o := option.Resolve(opts...)
 	queue := &Queue{
 		// nolint:staticcheck
 		// We need to implement a deprecated interface since Command currently doesn't implement "comparable"
@@ -116,11 +150,19 @@ func NewQueue(kubeClient client.Client, recorder events.Recorder, cluster *state
 		cluster:             cluster,
 		clock:               clock,
 		provisioner:         provisioner,
+		DeadLetters:         NewDeadLetters(clock),
+		rebooter:            o.rebooter,
+		evictionQueue:       o.evictionQueue,
 	}
 	return queue
 }
 
 func (q *Queue) Register(ctx context.Context, m manager.Manager) error {
+	if options.FromContext(ctx).DisruptionCommandMaxFailures > 0 {
+		if err := m.AddMetricsServerExtraHandler("/debug/disruption/dead-letters", q.DeadLetters); err != nil {
+			return err
+		}
+	}
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("disruption.queue").
 		WatchesRawSource(source.Channel(q.source, &handler.TypedEnqueueRequestForObject[*v1.NodeClaim]{})).
@@ -144,6 +186,7 @@ func (q *Queue) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconci
 		return reconcile.Result{}, nil
 	}
 	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues(cmd.LogValues()...))
+	q.updateMetrics()
 
 	if err := q.waitOrTerminate(ctx, cmd); err != nil {
 		// If recoverable, re-queue and try again.
@@ -162,19 +205,74 @@ func (q *Queue) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconci
 			metrics.ReasonLabel:    pretty.ToSnakeCase(string(cmd.Reason())),
 			ConsolidationTypeLabel: cmd.ConsolidationType(),
 		})
+		QueueCommandFailuresTotal.Inc(map[string]string{
+			metrics.ReasonLabel: pretty.ToSnakeCase(string(cmd.Reason())),
+			failureReasonLabel:  commandFailureReason(err),
+		})
 		stateNodes := lo.Map(cmd.Candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode })
 		multiErr := multierr.Combine(err, state.RequireNoScheduleTaint(ctx, q.kubeClient, false, stateNodes...))
 		multiErr = multierr.Combine(multiErr, state.ClearNodeClaimsCondition(ctx, q.kubeClient, v1.ConditionTypeDisruptionReason, stateNodes...))
 		// Log the error
 		log.FromContext(ctx).Error(multiErr, "failed terminating nodes while executing a disruption command")
+		if cmd.Reason() == v1.DisruptionReasonDrifted && len(failedLaunches) > 0 {
+			q.recordDriftFailures(ctx, failedLaunches)
+		}
+		if len(failedLaunches) > 0 {
+			q.rollback(ctx, cmd, failedLaunches)
+		}
+		q.cluster.Publish(commandEvent(cmd, stream.CommandFailed))
+		q.completeDecision(ctx, cmd, multiErr)
+		q.DeadLetters.RecordFailure(ctx, cmd, multiErr)
 	} else {
 		log.FromContext(ctx).V(1).Info("command succeeded")
 		cmd.Succeeded = true
+		q.cluster.Publish(commandEvent(cmd, stream.CommandSucceeded))
+		q.completeDecision(ctx, cmd, nil)
+		q.DeadLetters.RecordSuccess(cmd)
+		if cmd.Reason() == v1.DisruptionReasonDrifted && cmd.Decision() != RebootDecision {
+			q.recordDriftReplacements(ctx, cmd)
+		}
 	}
+	q.forgetCommand(ctx, cmd)
 	q.CompleteCommand(cmd)
 	return reconcile.Result{}, nil
 }
 
+// rollback cleans up after a replace command whose replacements failed. The replacements that never initialized are
+// deleted so that they don't linger as unused capacity, and cluster state is marked as changed so that the disruption
+// decision is computed again for the untainted candidates.
+func (q *Queue) rollback(ctx context.Context, cmd *Command, failedLaunches []*Replacement) {
+	for _, r := range failedLaunches {
+		if err := q.kubeClient.Delete(ctx, &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: r.Name}}); client.IgnoreNotFound(err) != nil {
+			log.FromContext(ctx).WithValues("NodeClaim", klog.KRef("", r.Name)).Error(err, "failed deleting replacement while rolling back disruption command")
+		}
+	}
+	for _, c := range cmd.Candidates {
+		q.recorder.Publish(disruptionevents.RolledBack(c.Node, c.NodeClaim, string(cmd.Reason()))...)
+	}
+	q.cluster.MarkUnconsolidated()
+	log.FromContext(ctx).WithValues("replacements", len(failedLaunches)).Info("rolled back disruption command")
+}
+
+// recordDriftReplacements adds the candidates of a successful drift command to the drift rollout progress of their NodePools
+func (q *Queue) recordDriftReplacements(ctx context.Context, cmd *Command) {
+	for nodePoolName, candidates := range lo.GroupBy(cmd.Candidates, func(c *Candidate) string { return c.NodePool.Name }) {
+		if err := nodepoolutils.RecordDriftReplacements(ctx, q.kubeClient, nodePoolName, len(candidates), q.clock.Now()); err != nil {
+			log.FromContext(ctx).Error(err, "failed recording drift rollout progress")
+		}
+	}
+}
+
+// recordDriftFailures adds the replacements of a failed drift command that never initialized to the drift rollout progress
+// of their NodePools, pausing drift for NodePools that reach their failure threshold
+func (q *Queue) recordDriftFailures(ctx context.Context, failedLaunches []*Replacement) {
+	for nodePoolName, replacements := range lo.GroupBy(failedLaunches, func(r *Replacement) string { return r.NodePoolName }) {
+		if err := nodepoolutils.RecordDriftFailures(ctx, q.kubeClient, nodePoolName, len(replacements)); err != nil {
+			log.FromContext(ctx).Error(err, "failed recording drift rollout failures")
+		}
+	}
+}
+
 // waitOrTerminate will wait until launched nodeclaims are ready.
 // Once the replacements are ready, it will terminate the candidates.
 // nolint:gocyclo
@@ -185,9 +283,10 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) (err error) {
 	// Wrap an error in an unrecoverable error if it timed out
 	defer func() {
 		if q.clock.Since(cmd.CreationTimestamp) > retryDuration {
-			err = NewUnrecoverableError(serrors.Wrap(fmt.Errorf("command reached timeout, %w", err), "duration", q.clock.Since(cmd.CreationTimestamp)))
+			err = NewUnrecoverableError(serrors.Wrap(fmt.Errorf("%w, %w", errCommandTimedOut, err), "duration", q.clock.Since(cmd.CreationTimestamp)))
 		}
 	}()
+	waiting := lo.ContainsBy(cmd.Replacements, func(r *Replacement) bool { return !r.Initialized })
 	waitErrs := make([]error, len(cmd.Replacements))
 	for i := range cmd.Replacements {
 		// If we know the node claim is Initialized, no need to check again.
@@ -201,7 +300,7 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) (err error) {
 			// This means that there was an ICE error or the Node initializationTTL expired
 			// In this case, the error is unrecoverable, so don't requeue.
 			if errors.IsNotFound(err) && !q.cluster.NodeClaimExists(cmd.Replacements[i].Name) {
-				return NewUnrecoverableError(fmt.Errorf("replacement was deleted, %w", err))
+				return NewUnrecoverableError(fmt.Errorf("%w, %w", errReplacementDeleted, err))
 			}
 			waitErrs[i] = fmt.Errorf("getting node claim, %w", err)
 			continue
@@ -219,8 +318,22 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) (err error) {
 	}
 	// If we have any errors, don't continue
 	if err := multierr.Combine(waitErrs...); err != nil {
+		if timeout := options.FromContext(ctx).DisruptionReplacementTimeout; timeout > 0 && q.clock.Since(cmd.CreationTimestamp) > timeout {
+			return NewUnrecoverableError(fmt.Errorf("%w, %w", errReplacementTimedOut, err))
+		}
 		return fmt.Errorf("waiting for replacement initialization, %w", err)
 	}
+	if waiting {
+		ReplacementWaitDurationSeconds.Observe(q.clock.Since(cmd.CreationTimestamp).Seconds(), map[string]string{
+			metrics.ReasonLabel: pretty.ToSnakeCase(string(cmd.Reason())),
+		})
+	}
+	q.updateCommandPhase(ctx, cmd, v1alpha1.DisruptionCommandPhaseTerminating)
+
+	// Reboot commands keep their candidates, so they're drained and rebooted instead of deleted
+	if cmd.Decision() == RebootDecision {
+		return q.rebootCandidates(ctx, cmd)
+	}
 
 	// All replacements have been provisioned.
 	// All we need to do now is get a successful delete call for each node claim,
@@ -233,7 +346,12 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) (err error) {
 			errs[i] = client.IgnoreNotFound(err)
 			return
 		}
-		q.recorder.Publish(disruptionevents.Terminating(cmd.Candidates[i].Node, cmd.Candidates[i].NodeClaim, string(cmd.Reason()))...)
+		q.recorder.Publish(disruptionevents.Terminating(cmd.Candidates[i].Node, cmd.Candidates[i].NodeClaim, string(cmd.Reason()), lo.Ternary(cmd.Reason() == v1.DisruptionReasonDrifted, driftDetails(cmd.Candidates[i].NodeClaim), ""))...)
+		if cmd.Class() == EventualDisruptionClass {
+			if err := q.recordTerminationDeadline(ctx, cmd.Candidates[i].NodeClaim); err != nil {
+				log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(cmd.Candidates[i].NodeClaim)).Error(err, "failed recording termination deadline")
+			}
+		}
 		metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
 			metrics.ReasonLabel:       pretty.ToSnakeCase(string(cmd.Reason())),
 			metrics.NodePoolLabel:     cmd.Candidates[i].NodeClaim.Labels[v1.NodePoolLabelKey],
@@ -245,6 +363,37 @@ func (q *Queue) waitOrTerminate(ctx context.Context, cmd *Command) (err error) {
 	return multierr.Combine(errs...)
 }
 
+// recordTerminationDeadline surfaces when the pods that block the eventual disruption of a deleted candidate are forcibly
+// evicted on the NodeClaim's status. The deadline is computed from the DeletionTimestamp the same way as the
+// karpenter.sh/nodeclaim-termination-timestamp annotation.
+func (q *Queue) recordTerminationDeadline(ctx context.Context, candidate *v1.NodeClaim) error {
+	nodeClaim := &v1.NodeClaim{}
+	if err := q.kubeClient.Get(ctx, client.ObjectKeyFromObject(candidate), nodeClaim); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	var reason string
+	if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason); cond.IsTrue() {
+		reason = cond.Reason
+	}
+	if nodeClaim.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	var deadline time.Time
+	if terminationGracePeriod := nodeClaim.TerminationGracePeriodFor(reason); terminationGracePeriod != nil {
+		deadline = nodeClaim.DeletionTimestamp.Add(terminationGracePeriod.Duration)
+	}
+	// Node repair records the termination timestamp of unhealthy NodeClaims before they're deleted
+	if terminationTime, err := time.Parse(time.RFC3339, nodeClaim.Annotations[v1.NodeClaimTerminationTimestampAnnotationKey]); err == nil && (deadline.IsZero() || terminationTime.Before(deadline)) {
+		deadline = terminationTime
+	}
+	if deadline.IsZero() {
+		return nil
+	}
+	stored := nodeClaim.DeepCopy()
+	nodeClaim.Status.TerminationDeadline = lo.ToPtr(metav1.NewTime(deadline))
+	return client.IgnoreNotFound(q.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)))
+}
+
 // markDisrupted taints the node and adds the Disrupted condition to the NodeClaim for a candidate that is about to be disrupted
 // For static NodeClaims, we mark NodeClaims as pendingdisruption in statenodepool
 func (q *Queue) markDisrupted(ctx context.Context, cmd *Command) ([]*Candidate, error) {
@@ -283,9 +432,18 @@ func (q *Queue) markDisrupted(ctx context.Context, cmd *Command) ([]*Candidate,
 	return markedCandidates, multierr.Combine(errs...)
 }
 
-// createReplacementNodeClaims creates replacement NodeClaims
+// createReplacementNodeClaims creates replacement NodeClaims. The replacements are annotated with the names of the
+// candidates they replace, and the candidates with the names of their replacements, so that the linkage can be traced
+// from the API.
 func (q *Queue) createReplacementNodeClaims(ctx context.Context, cmd *Command) error {
-	nodeClaimNames, err := q.provisioner.CreateNodeClaims(ctx, lo.Map(cmd.Replacements, func(r *Replacement, _ int) *pscheduling.NodeClaim { return r.NodeClaim }), provisioning.WithReason(strings.ToLower(string(cmd.Reason()))))
+	if len(cmd.Replacements) == 0 {
+		return nil
+	}
+	candidateNames := lo.Map(cmd.Candidates, func(c *Candidate, _ int) string { return c.NodeClaim.Name })
+	nodeClaimNames, err := q.provisioner.CreateNodeClaims(ctx, lo.Map(cmd.Replacements, func(r *Replacement, _ int) *pscheduling.NodeClaim { return r.NodeClaim }),
+		provisioning.WithReason(strings.ToLower(string(cmd.Reason()))),
+		provisioning.WithAnnotations(map[string]string{v1.NodeClaimReplacesAnnotationKey: strings.Join(candidateNames, ",")}),
+	)
 	if err != nil {
 		return err
 	}
@@ -296,6 +454,15 @@ func (q *Queue) createReplacementNodeClaims(ctx context.Context, cmd *Command) e
 	for i, name := range nodeClaimNames {
 		cmd.Replacements[i].Name = name
 	}
+	// The linkage is only informational, so failing to annotate the candidates doesn't fail the command
+	workqueue.ParallelizeUntil(ctx, len(cmd.Candidates), len(cmd.Candidates), func(i int) {
+		nodeClaim := cmd.Candidates[i].NodeClaim.DeepCopy()
+		stored := nodeClaim.DeepCopy()
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimReplacedByAnnotationKey: strings.Join(nodeClaimNames, ",")})
+		if err := q.kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored)); client.IgnoreNotFound(err) != nil {
+			log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(nodeClaim)).Error(err, "failed annotating candidate with its replacements")
+		}
+	})
 	return nil
 }
 
@@ -330,6 +497,9 @@ func (q *Queue) StartCommand(ctx context.Context, cmd *Command) error {
 		// we don't want to disrupt workloads with no way to provision new nodes for them.
 		return serrors.Wrap(fmt.Errorf("launching replacement nodeclaim, %w", err), "command-id", cmd.ID)
 	}
+	// Persist the command once its replacements exist, so that a restart doesn't lose track of the tainted candidates
+	// and the replacements that were launched for them
+	q.persistCommand(ctx, cmd)
 	// IMPORTANT
 	// We must MarkForDeletion AFTER we launch the replacements and not before
 	// The reason for this is to avoid producing double-launches
@@ -358,6 +528,7 @@ func (q *Queue) StartCommand(ctx context.Context, cmd *Command) error {
 	// This invariant SHOULD NOT be relied on anywhere else besides within this file.
 	q.source <- event.TypedGenericEvent[*v1.NodeClaim]{Object: cmd.Candidates[0].NodeClaim}
 	q.Unlock()
+	q.updateMetrics()
 
 	// An action is only performed and pods/nodes are only disrupted after a successful add to the queue
 	DecisionsPerformedTotal.Inc(map[string]string{
@@ -365,9 +536,25 @@ func (q *Queue) StartCommand(ctx context.Context, cmd *Command) error {
 		metrics.ReasonLabel:    strings.ToLower(string(cmd.Reason())),
 		ConsolidationTypeLabel: cmd.ConsolidationType(),
 	})
+	q.cluster.Publish(commandEvent(cmd, stream.CommandStarted))
+	q.recordDecision(ctx, cmd, v1alpha1.DisruptionDecisionPhaseExecuting, "")
 	return nil
 }
 
+// commandEvent converts a command into a cluster state stream notification
+func commandEvent(cmd *Command, eventType stream.EventType) stream.Event {
+	return stream.Event{
+		Type: eventType,
+		Details: map[string]string{
+			"command-id":   cmd.ID.String(),
+			"reason":       strings.ToLower(string(cmd.Reason())),
+			"decision":     string(cmd.Decision()),
+			"candidates":   strings.Join(lo.Map(cmd.Candidates, func(c *Candidate, _ int) string { return c.NodeClaim.Name }), ","),
+			"replacements": strings.Join(lo.Map(cmd.Replacements, func(r *Replacement, _ int) string { return r.Name }), ","),
+		},
+	}
+}
+
 // HasAny checks to see if the candidate is part of an currently executing command.
 func (q *Queue) HasAny(ids ...string) bool {
 	q.RLock()
@@ -382,6 +569,23 @@ func (q *Queue) HasAny(ids ...string) bool {
 	return false
 }
 
+// NodePoolsWithCommands returns the names of the NodePools whose nodes are candidates of in-flight commands for the reason
+func (q *Queue) NodePoolsWithCommands(reason v1.DisruptionReason) sets.Set[string] {
+	q.RLock()
+	defer q.RUnlock()
+
+	nodePools := sets.New[string]()
+	for _, cmd := range q.ProviderIDToCommand {
+		if cmd.Reason() != reason {
+			continue
+		}
+		for _, c := range cmd.Candidates {
+			nodePools.Insert(c.NodePool.Name)
+		}
+	}
+	return nodePools
+}
+
 // For TESTING ONLY
 // This function is not thread safe as it returns pointers to commands.
 // If you edit these commands returned, you can create race conditions.
@@ -394,7 +598,8 @@ func (q *Queue) GetCommands() []*Command {
 
 // CompleteCommand fully clears the queue of all references of a hash/command
 func (q *Queue) CompleteCommand(cmd *Command) {
-	if !cmd.Succeeded {
+	// The candidates of reboot commands remain in the cluster once they succeed
+	if !cmd.Succeeded || cmd.Decision() == RebootDecision {
 		q.cluster.UnmarkForDeletion(lo.Map(cmd.Candidates, func(c *Candidate, _ int) string { return c.ProviderID() })...)
 	}
 	// Remove all candidates linked to the command
@@ -403,6 +608,37 @@ func (q *Queue) CompleteCommand(cmd *Command) {
 	for _, c := range cmd.Candidates {
 		delete(q.ProviderIDToCommand, c.ProviderID())
 	}
+	q.updateMetricsLocked()
+}
+
+// updateMetrics records the depth of the queue and the age of its oldest command for each disruption reason
+func (q *Queue) updateMetrics() {
+	q.RLock()
+	defer q.RUnlock()
+	q.updateMetricsLocked()
+}
+
+func (q *Queue) updateMetricsLocked() {
+	QueueDepth.Reset()
+	QueueOldestCommandAgeSeconds.Reset()
+	for reason, cmds := range lo.GroupBy(lo.UniqValues(q.ProviderIDToCommand), func(cmd *Command) v1.DisruptionReason { return cmd.Reason() }) {
+		labels := map[string]string{metrics.ReasonLabel: pretty.ToSnakeCase(string(reason))}
+		QueueDepth.Set(float64(len(cmds)), labels)
+		oldest := lo.MinBy(cmds, func(a, b *Command) bool { return a.CreationTimestamp.Before(b.CreationTimestamp) })
+		QueueOldestCommandAgeSeconds.Set(q.clock.Since(oldest.CreationTimestamp).Seconds(), labels)
+	}
+}
+
+// commandFailureReason classifies the error of a failed command for the queue failure metrics
+func commandFailureReason(err error) string {
+	switch {
+	case stderrors.Is(err, errCommandTimedOut):
+		return failureReasonTimedOut
+	case stderrors.Is(err, errReplacementDeleted), stderrors.Is(err, errReplacementTimedOut):
+		return failureReasonReplacementFailed
+	default:
+		return failureReasonOther
+	}
 }
 
 func (q *Queue) IsEmpty() bool {
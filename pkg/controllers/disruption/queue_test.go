@@ -17,10 +17,12 @@ limitations under the License.
 package disruption_test
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
@@ -35,8 +37,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 )
@@ -98,7 +103,7 @@ var _ = Describe("Queue", func() {
 
 			stateNode := ExpectStateNodeExists(cluster, node1)
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -132,7 +137,7 @@ var _ = Describe("Queue", func() {
 			}
 
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -157,7 +162,7 @@ var _ = Describe("Queue", func() {
 			}
 
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -188,7 +193,7 @@ var _ = Describe("Queue", func() {
 			}
 
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -203,6 +208,125 @@ var _ = Describe("Queue", func() {
 			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
 			node1 = ExpectNodeExists(ctx, env.Client, node1.Name)
 			Expect(node1.Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+			ExpectMetricCounterValue(disruption.QueueCommandFailuresTotal, 1, map[string]string{"reason": "drifted", "failure_reason": "timed_out"})
+		})
+		It("should roll back commands whose replacements don't initialize before the replacement timeout", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionReplacementTimeout: lo.ToPtr(5 * time.Minute)}))
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			nct := scheduling.NewNodeClaimTemplate(nodePool)
+			nct.InstanceTypeOptions = append([]*cloudprovider.InstanceType{}, cloudProvider.InstanceTypes...)
+			cmd := &disruption.Command{
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
+				CreationTimestamp: fakeClock.Now(),
+				ID:                uuid.New(),
+				Results:           scheduling.Results{},
+				Candidates:        []*disruption.Candidate{{StateNode: stateNode, NodePool: nodePool}},
+				Replacements:      []*disruption.Replacement{{NodeClaim: &scheduling.NodeClaim{NodeClaimTemplate: *nct}}},
+			}
+			Expect(queue.StartCommand(ctx, cmd)).To(BeNil())
+			replacement := &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: cmd.Replacements[0].Name}}
+			ExpectExists(ctx, env.Client, replacement)
+
+			// The command keeps waiting before the replacement timeout
+			fakeClock.Step(4 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
+			Expect(queue.HasAny(nodeClaim1.Status.ProviderID)).To(BeTrue())
+
+			fakeClock.Step(2 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
+			Expect(queue.IsEmpty()).To(BeTrue())
+			Expect(ExpectNodeExists(ctx, env.Client, node1.Name).Spec.Taints).ToNot(ContainElement(v1.DisruptedNoScheduleTaint))
+			ExpectNotFound(ctx, env.Client, replacement)
+			Expect(recorder.DetectedEvent("Rolled back disruption (Drifted), replacements failed to launch or initialize")).To(BeTrue())
+			ExpectMetricCounterValue(disruption.QueueCommandFailuresTotal, 1, map[string]string{"reason": "drifted", "failure_reason": "replacement_failed"})
+		})
+		It("should report the depth of the queue and the age of its oldest command", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			nct := scheduling.NewNodeClaimTemplate(nodePool)
+			nct.InstanceTypeOptions = append([]*cloudprovider.InstanceType{}, cloudProvider.InstanceTypes...)
+			cmd := &disruption.Command{
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
+				CreationTimestamp: fakeClock.Now(),
+				ID:                uuid.New(),
+				Results:           scheduling.Results{},
+				Candidates:        []*disruption.Candidate{{StateNode: stateNode, NodePool: nodePool}},
+				Replacements:      []*disruption.Replacement{{NodeClaim: &scheduling.NodeClaim{NodeClaimTemplate: *nct}}},
+			}
+			Expect(queue.StartCommand(ctx, cmd)).To(BeNil())
+			ExpectMetricGaugeValue(disruption.QueueDepth, 1, map[string]string{"reason": "drifted"})
+
+			fakeClock.Step(time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
+			ExpectMetricGaugeValue(disruption.QueueOldestCommandAgeSeconds, 60, map[string]string{"reason": "drifted"})
+
+			// Time out the command so that it's removed from the queue
+			fakeClock.Step(10 * time.Minute)
+			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
+			Expect(queue.IsEmpty()).To(BeTrue())
+			_, found := FindMetricWithLabelValues("karpenter_voluntary_disruption_queue_depth", map[string]string{"reason": "drifted"})
+			Expect(found).To(BeFalse())
+		})
+		Context("Dead Letters", func() {
+			var cmd *disruption.Command
+			var stateNode *state.StateNode
+			BeforeEach(func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionCommandMaxFailures: lo.ToPtr(1), DisruptionDeadLetterTTL: lo.ToPtr(time.Hour)}))
+				ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+				ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+				stateNode = ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+				nct := scheduling.NewNodeClaimTemplate(nodePool)
+				nct.InstanceTypeOptions = append([]*cloudprovider.InstanceType{}, cloudProvider.InstanceTypes...)
+				cmd = &disruption.Command{
+					Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
+					CreationTimestamp: fakeClock.Now(),
+					ID:                uuid.New(),
+					Results:           scheduling.Results{},
+					Candidates:        []*disruption.Candidate{{StateNode: stateNode, NodePool: nodePool}},
+					Replacements:      []*disruption.Replacement{{NodeClaim: &scheduling.NodeClaim{NodeClaimTemplate: *nct}}},
+				}
+				Expect(queue.StartCommand(ctx, cmd)).To(BeNil())
+
+				// Step the clock to time out the command
+				fakeClock.Step(11 * time.Minute)
+				ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
+			})
+			It("should dead-letter candidates whose commands failed", func() {
+				Expect(queue.DeadLetters.IsDeadLettered(ctx, nodeClaim1)).To(BeTrue())
+				deadLetters := queue.DeadLetters.List()
+				Expect(deadLetters).To(HaveLen(1))
+				Expect(deadLetters[0].NodeClaim).To(Equal(nodeClaim1.Name))
+				Expect(deadLetters[0].Failures).To(HaveLen(1))
+				Expect(deadLetters[0].Failures[0].CommandID).To(Equal(cmd.ID))
+				Expect(deadLetters[0].Failures[0].Reason).To(Equal(v1.DisruptionReasonDrifted))
+				ExpectMetricGaugeValue(disruption.DeadLetteredCandidates, 1, map[string]string{"reason": "drifted"})
+			})
+			It("should release candidates once the dead-letter TTL elapses", func() {
+				fakeClock.Step(time.Hour)
+				Expect(queue.DeadLetters.IsDeadLettered(ctx, nodeClaim1)).To(BeFalse())
+				Expect(queue.DeadLetters.List()).To(HaveLen(1))
+				queue.DeadLetters.Release(ctx, nodeClaim1)
+				Expect(queue.DeadLetters.List()).To(HaveLen(0))
+			})
+			It("should release candidates when a retry is requested", func() {
+				nodeClaim1.Annotations = lo.Assign(nodeClaim1.Annotations, map[string]string{v1.DisruptionRetryRequestedAnnotationKey: "1"})
+				Expect(queue.DeadLetters.IsDeadLettered(ctx, nodeClaim1)).To(BeFalse())
+				Expect(queue.DeadLetters.List()).To(HaveLen(1))
+				queue.DeadLetters.Release(ctx, nodeClaim1)
+				Expect(queue.DeadLetters.List()).To(HaveLen(0))
+			})
+			It("should not dead-letter candidates when disabled", func() {
+				queue.DeadLetters = disruption.NewDeadLetters(fakeClock)
+				ctx = options.ToContext(ctx, test.Options())
+				queue.DeadLetters.RecordFailure(ctx, cmd, fmt.Errorf("failed"))
+				Expect(queue.DeadLetters.IsDeadLettered(ctx, nodeClaim1)).To(BeFalse())
+			})
 		})
 		It("should fully handle a command when replacements are initialized", func() {
 			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
@@ -218,7 +342,7 @@ var _ = Describe("Queue", func() {
 			}
 
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -244,7 +368,7 @@ var _ = Describe("Queue", func() {
 			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
 			Expect(cmd.Replacements[0].Initialized).To(BeTrue())
 
-			terminatingEvents := disruptionevents.Terminating(node1, nodeClaim1, string(cmd.Reason()))
+			terminatingEvents := disruptionevents.Terminating(node1, nodeClaim1, string(cmd.Reason()), "")
 			Expect(recorder.DetectedEvent(terminatingEvents[0].Message)).To(BeTrue())
 			Expect(recorder.DetectedEvent(terminatingEvents[1].Message)).To(BeTrue())
 
@@ -271,7 +395,7 @@ var _ = Describe("Queue", func() {
 			}
 
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -315,7 +439,7 @@ var _ = Describe("Queue", func() {
 			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
 
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -326,7 +450,7 @@ var _ = Describe("Queue", func() {
 
 			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
 
-			terminatingEvents := disruptionevents.Terminating(node1, nodeClaim1, string(cmd.Reason()))
+			terminatingEvents := disruptionevents.Terminating(node1, nodeClaim1, string(cmd.Reason()), "")
 			Expect(recorder.DetectedEvent(terminatingEvents[0].Message)).To(BeTrue())
 			Expect(recorder.DetectedEvent(terminatingEvents[1].Message)).To(BeTrue())
 
@@ -334,6 +458,156 @@ var _ = Describe("Queue", func() {
 			// And expect the nodeClaim and node to be deleted
 			ExpectNotFound(ctx, env.Client, nodeClaim1, node1)
 		})
+		It("should record a DisruptionDecision for an executed command", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionDecisionRetention: lo.ToPtr(time.Hour)}))
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			cmd := &disruption.Command{
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
+				CreationTimestamp: fakeClock.Now(),
+				ID:                uuid.New(),
+				Results:           scheduling.Results{},
+				Candidates:        []*disruption.Candidate{{StateNode: stateNode, NodePool: nodePool}},
+				Replacements:      nil,
+			}
+			Expect(queue.StartCommand(ctx, cmd)).To(BeNil())
+
+			decision := ExpectExists(ctx, env.Client, &v1alpha1.DisruptionDecision{ObjectMeta: metav1.ObjectMeta{Name: cmd.ID.String()}})
+			Expect(decision.Spec.CommandID).To(Equal(cmd.ID.String()))
+			Expect(decision.Spec.Reason).To(Equal(string(v1.DisruptionReasonDrifted)))
+			Expect(decision.Spec.Decision).To(Equal(string(disruption.DeleteDecision)))
+			Expect(decision.Spec.Candidates).To(ConsistOf(v1alpha1.DisruptionDecisionCandidate{
+				NodeClaim:    nodeClaim1.Name,
+				Node:         node1.Name,
+				NodePool:     nodePool.Name,
+				InstanceType: nodeClaim1.Labels[corev1.LabelInstanceTypeStable],
+				CapacityType: nodeClaim1.Labels[v1.CapacityTypeLabelKey],
+				Zone:         nodeClaim1.Labels[corev1.LabelTopologyZone],
+			}))
+			Expect(decision.Status.Phase).To(Equal(v1alpha1.DisruptionDecisionPhaseExecuting))
+
+			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
+			decision = ExpectExists(ctx, env.Client, decision)
+			Expect(decision.Status.Phase).To(Equal(v1alpha1.DisruptionDecisionPhaseSucceeded))
+			Expect(decision.Status.CompletionTime).ToNot(BeNil())
+		})
+		It("should not record a DisruptionDecision when recording is disabled", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			cmd := &disruption.Command{
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
+				CreationTimestamp: fakeClock.Now(),
+				ID:                uuid.New(),
+				Results:           scheduling.Results{},
+				Candidates:        []*disruption.Candidate{{StateNode: stateNode, NodePool: nodePool}},
+				Replacements:      nil,
+			}
+			Expect(queue.StartCommand(ctx, cmd)).To(BeNil())
+			ExpectNotFound(ctx, env.Client, &v1alpha1.DisruptionDecision{ObjectMeta: metav1.ObjectMeta{Name: cmd.ID.String()}})
+		})
+		It("should persist a DisruptionCommand until the command completes", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			stateNode := ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1)
+
+			cmd := &disruption.Command{
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
+				CreationTimestamp: fakeClock.Now(),
+				ID:                uuid.New(),
+				Results:           scheduling.Results{},
+				Candidates:        []*disruption.Candidate{{StateNode: stateNode, NodePool: nodePool}},
+				Replacements:      nil,
+			}
+			Expect(queue.StartCommand(ctx, cmd)).To(BeNil())
+
+			disruptionCommand := ExpectExists(ctx, env.Client, &v1alpha1.DisruptionCommand{ObjectMeta: metav1.ObjectMeta{Name: cmd.ID.String()}})
+			Expect(disruptionCommand.Spec.Reason).To(Equal(string(v1.DisruptionReasonDrifted)))
+			Expect(disruptionCommand.Spec.Class).To(Equal(cmd.Class()))
+			Expect(disruptionCommand.Spec.Candidates).To(ConsistOf(v1alpha1.DisruptionCommandCandidate{
+				NodeClaim:  nodeClaim1.Name,
+				ProviderID: nodeClaim1.Status.ProviderID,
+			}))
+			Expect(disruptionCommand.Status.Phase).To(Equal(v1alpha1.DisruptionCommandPhaseTerminating))
+
+			ExpectObjectReconciled(ctx, env.Client, queue, stateNode.NodeClaim)
+			ExpectNotFound(ctx, env.Client, disruptionCommand)
+		})
+		It("should recover commands that were persisted before a restart", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+			disruptionCommand := &v1alpha1.DisruptionCommand{
+				ObjectMeta: metav1.ObjectMeta{Name: uuid.New().String()},
+				Spec: v1alpha1.DisruptionCommandSpec{
+					Reason:       string(v1.DisruptionReasonDrifted),
+					Class:        disruption.GracefulDisruptionClass,
+					Candidates:   []v1alpha1.DisruptionCommandCandidate{{NodeClaim: nodeClaim1.Name, ProviderID: nodeClaim1.Status.ProviderID}},
+					Replacements: []v1alpha1.DisruptionCommandReplacement{{NodeClaim: "replacement", NodePool: nodePool.Name}},
+					StartTime:    metav1.NewTime(fakeClock.Now()),
+				},
+			}
+			ExpectApplied(ctx, env.Client, disruptionCommand)
+
+			q := disruption.NewQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(q.Recover(ctx)).To(Succeed())
+			Expect(q.HasAny(nodeClaim1.Status.ProviderID)).To(BeTrue())
+			cmds := q.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].ID.String()).To(Equal(disruptionCommand.Name))
+			Expect(cmds[0].Reason()).To(Equal(v1.DisruptionReasonDrifted))
+			Expect(cmds[0].Replacements).To(HaveLen(1))
+			Expect(cmds[0].Replacements[0].Name).To(Equal("replacement"))
+			Expect(ExpectStateNodeExistsForNodeClaim(cluster, nodeClaim1).MarkedForDeletion()).To(BeTrue())
+		})
+		It("should remove persisted commands whose candidates no longer exist", func() {
+			disruptionCommand := &v1alpha1.DisruptionCommand{
+				ObjectMeta: metav1.ObjectMeta{Name: uuid.New().String()},
+				Spec: v1alpha1.DisruptionCommandSpec{
+					Reason:     string(v1.DisruptionReasonDrifted),
+					Class:      disruption.GracefulDisruptionClass,
+					Candidates: []v1alpha1.DisruptionCommandCandidate{{NodeClaim: "missing", ProviderID: test.RandomProviderID()}},
+					StartTime:  metav1.NewTime(fakeClock.Now()),
+				},
+			}
+			ExpectApplied(ctx, env.Client, disruptionCommand)
+
+			q := disruption.NewQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(q.Recover(ctx)).To(Succeed())
+			Expect(q.IsEmpty()).To(BeTrue())
+			ExpectNotFound(ctx, env.Client, disruptionCommand)
+		})
+		It("should recover commands that weren't persisted from the replacements that they launched", func() {
+			nodeClaim1.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, string(v1.DisruptionReasonDrifted), string(v1.DisruptionReasonDrifted))
+			nodeClaim2.Annotations = lo.Assign(nodeClaim2.Annotations, map[string]string{v1.NodeClaimReplacesAnnotationKey: nodeClaim1.Name})
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodeClaim2, node2, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1, node2}, []*v1.NodeClaim{nodeClaim1, nodeClaim2})
+
+			q := disruption.NewQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(q.Recover(ctx)).To(Succeed())
+			Expect(q.HasAny(nodeClaim1.Status.ProviderID)).To(BeTrue())
+			Expect(q.HasAny(nodeClaim2.Status.ProviderID)).To(BeFalse())
+			cmds := q.GetCommands()
+			Expect(cmds).To(HaveLen(1))
+			Expect(cmds[0].Reason()).To(Equal(v1.DisruptionReasonDrifted))
+			Expect(cmds[0].Class()).To(Equal(disruption.EventualDisruptionClass))
+			Expect(cmds[0].Replacements).To(HaveLen(1))
+			Expect(cmds[0].Replacements[0].Name).To(Equal(nodeClaim2.Name))
+
+			// The recovered command is persisted so that it survives another restart
+			ExpectExists(ctx, env.Client, &v1alpha1.DisruptionCommand{ObjectMeta: metav1.ObjectMeta{Name: cmds[0].ID.String()}})
+		})
+		It("should not recover marked candidates without any replacements", func() {
+			nodeClaim1.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, string(v1.DisruptionReasonDrifted), string(v1.DisruptionReasonDrifted))
+			ExpectApplied(ctx, env.Client, nodeClaim1, node1, nodePool)
+			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1}, []*v1.NodeClaim{nodeClaim1})
+
+			q := disruption.NewQueue(env.Client, recorder, cluster, fakeClock, prov)
+			Expect(q.Recover(ctx)).To(Succeed())
+			Expect(q.IsEmpty()).To(BeTrue())
+		})
 		It("should finish two commands in order as replacements are intialized", func() {
 			ExpectApplied(ctx, env.Client, nodePool, nodeClaim1, node1, nodeClaim2, node2)
 			ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node1, node2}, []*v1.NodeClaim{nodeClaim1, nodeClaim2})
@@ -352,7 +626,7 @@ var _ = Describe("Queue", func() {
 			}}
 
 			cmd := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
@@ -361,7 +635,7 @@ var _ = Describe("Queue", func() {
 			}
 			Expect(queue.StartCommand(ctx, cmd)).To(BeNil())
 			cmd2 := &disruption.Command{
-				Method:            disruption.NewDrift(env.Client, cluster, prov, recorder),
+				Method:            disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 				CreationTimestamp: fakeClock.Now(),
 				ID:                uuid.New(),
 				Results:           scheduling.Results{},
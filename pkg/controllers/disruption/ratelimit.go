@@ -0,0 +1,168 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/utils/env"
+)
+
+const (
+	// RateLimitLeaseName is the name of the Lease that the token bucket of the disruption rate limit is stored on
+	RateLimitLeaseName = "karpenter-disruption-rate-limit"
+	// rateLimitTokensAnnotationKey stores the tokens that were in the bucket when the Lease was last renewed
+	rateLimitTokensAnnotationKey = apis.Group + "/disruption-rate-limit-tokens"
+)
+
+// RateLimiter is a token bucket that limits how many nodes are disrupted per period across all NodePools and disruption
+// reasons. It's layered on top of the NodePool disruption budgets to protect infrastructure that's shared by the whole
+// cluster, e.g. DNS and image registries, while large fleets are rolled. The bucket is stored on a Lease in the leader
+// election namespace rather than in memory, so that it's shared by every shard and survives a change of leader.
+type RateLimiter struct {
+	// mu serializes taking tokens within this process so that concurrent commands don't conflict with each other
+	mu         sync.Mutex
+	clock      clock.Clock
+	kubeClient client.Client
+	// reader reads the Lease without the informer cache, which only holds node Leases
+	reader client.Reader
+}
+
+func NewRateLimiter(clk clock.Clock, kubeClient client.Client, reader client.Reader) *RateLimiter {
+	return &RateLimiter{clock: clk, kubeClient: kubeClient, reader: reader}
+}
+
+// Available returns the number of nodes that can be disrupted without waiting for the bucket to refill
+func (r *RateLimiter) Available(ctx context.Context) (int, error) {
+	limit, period := options.FromContext(ctx).DisruptionRateLimit, options.FromContext(ctx).DisruptionRateLimitPeriod
+	if limit == 0 || period == 0 {
+		return math.MaxInt, nil
+	}
+	lease, err := r.lease(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int(r.tokens(lease, limit, period)), nil
+}
+
+// Take removes a token from the bucket for each of the n nodes and returns the number of tokens taken, along with a
+// function that returns them if the nodes end up not being disrupted. If partial is set, as many of the n tokens as
+// are in the bucket are taken, and otherwise either all n tokens or none are. The bucket is read once per attempt, and
+// the attempt is retried if another shard changed the bucket since it was read.
+func (r *RateLimiter) Take(ctx context.Context, n int, partial bool) (int, func(), error) {
+	limit, period := options.FromContext(ctx).DisruptionRateLimit, options.FromContext(ctx).DisruptionRateLimitPeriod
+	if limit == 0 || period == 0 {
+		return n, func() {}, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	taken := 0
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		lease, err := r.lease(ctx)
+		if err != nil {
+			return err
+		}
+		tokens := r.tokens(lease, limit, period)
+		taken = lo.Ternary(partial, lo.Min([]int{n, int(tokens)}), lo.Ternary(tokens >= float64(n), n, 0))
+		if taken == 0 {
+			return nil
+		}
+		return r.store(ctx, lease, tokens-float64(taken))
+	}); err != nil {
+		return 0, nil, err
+	}
+	return taken, func() { r.release(ctx, taken) }, nil
+}
+
+// release returns n tokens to the bucket, retrying if another shard changes the bucket at the same time
+func (r *RateLimiter) release(ctx context.Context, n int) {
+	limit, period := options.FromContext(ctx).DisruptionRateLimit, options.FromContext(ctx).DisruptionRateLimitPeriod
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		lease, err := r.lease(ctx)
+		if err != nil {
+			return err
+		}
+		return r.store(ctx, lease, math.Min(r.tokens(lease, limit, period)+float64(n), float64(limit)))
+	}); err != nil {
+		log.FromContext(ctx).Error(err, "failed returning tokens to the disruption rate limit")
+	}
+}
+
+// tokens returns the tokens in the bucket, refilled at limit tokens per period since the Lease was last renewed
+func (r *RateLimiter) tokens(lease *coordinationv1.Lease, limit int, period time.Duration) float64 {
+	tokens, err := strconv.ParseFloat(lease.Annotations[rateLimitTokensAnnotationKey], 64)
+	if err != nil || lease.Spec.RenewTime == nil {
+		return float64(limit)
+	}
+	refilled := r.clock.Since(lease.Spec.RenewTime.Time).Seconds() * float64(limit) / period.Seconds()
+	return math.Max(math.Min(tokens+refilled, float64(limit)), 0)
+}
+
+// store records the tokens in the bucket as of now. We use client.MergeFromWithOptimisticLock so that tokens taken
+// concurrently by another shard aren't overwritten.
+func (r *RateLimiter) store(ctx context.Context, lease *coordinationv1.Lease, tokens float64) error {
+	stored := lease.DeepCopy()
+	lease.Annotations = lo.Assign(lease.Annotations, map[string]string{rateLimitTokensAnnotationKey: strconv.FormatFloat(tokens, 'f', -1, 64)})
+	lease.Spec.RenewTime = lo.ToPtr(metav1.NewMicroTime(r.clock.Now()))
+	if err := r.kubeClient.Patch(ctx, lease, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("patching disruption rate limit lease, %w", err)
+	}
+	return nil
+}
+
+// lease returns the Lease that the bucket is stored on, creating it with a full bucket if it doesn't exist
+func (r *RateLimiter) lease(ctx context.Context) (*coordinationv1.Lease, error) {
+	lease := &coordinationv1.Lease{}
+	nn := types.NamespacedName{Namespace: leaseNamespace(ctx), Name: RateLimitLeaseName}
+	if err := r.reader.Get(ctx, nn, lease); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("getting disruption rate limit lease, %w", err)
+		}
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name}}
+		if err = r.kubeClient.Create(ctx, lease); err != nil {
+			return nil, fmt.Errorf("creating disruption rate limit lease, %w", err)
+		}
+	}
+	return lease, nil
+}
+
+// leaseNamespace returns the namespace of the leader election Lease, defaulting to the namespace that Karpenter runs in
+func leaseNamespace(ctx context.Context) string {
+	if namespace := options.FromContext(ctx).LeaderElectionNamespace; namespace != "" {
+		return namespace
+	}
+	return env.Namespace()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awslabs/operatorpkg/serrors"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+)
+
+// rebootCandidates orchestrates a reboot command in place of deleting its candidates. Each candidate is drained through
+// the eviction queue, so that its pods are only evicted when their PDBs allow it, and its instance is rebooted once the
+// drain completes. The command completes once every rebooted node is Ready again. Until then, the candidates stay
+// tainted and marked for deletion in cluster state, so they count against the disruption budgets of their NodePools.
+func (q *Queue) rebootCandidates(ctx context.Context, cmd *Command) error {
+	if q.rebooter == nil || q.evictionQueue == nil {
+		return NewUnrecoverableError(fmt.Errorf("cloud provider doesn't support rebooting instances"))
+	}
+	var errs []error
+	for _, candidate := range cmd.Candidates {
+		if err := q.rebootCandidate(ctx, cmd, candidate); err != nil {
+			errs = append(errs, serrors.Wrap(err, "NodeClaim", klog.KObj(candidate.NodeClaim)))
+		}
+	}
+	if err := multierr.Combine(errs...); err != nil {
+		return err
+	}
+	// Every candidate is back, so they can schedule pods again
+	stateNodes := lo.Map(cmd.Candidates, func(c *Candidate, _ int) *state.StateNode { return c.StateNode })
+	return multierr.Combine(
+		state.RequireNoScheduleTaint(ctx, q.kubeClient, false, stateNodes...),
+		state.ClearNodeClaimsCondition(ctx, q.kubeClient, v1.ConditionTypeDisruptionReason, stateNodes...),
+	)
+}
+
+// rebootCandidate drains and reboots a candidate, and returns an error until its node is Ready again. A candidate whose
+// NodeClaim already records the NodePool hash that it's rebooted for isn't rebooted again, so that the command can be
+// retried and recovered after a restart.
+func (q *Queue) rebootCandidate(ctx context.Context, cmd *Command, candidate *Candidate) error {
+	node := &corev1.Node{}
+	if err := q.kubeClient.Get(ctx, client.ObjectKeyFromObject(candidate.Node), node); err != nil {
+		return fmt.Errorf("getting node, %w", err)
+	}
+	nodeClaim := &v1.NodeClaim{}
+	if err := q.kubeClient.Get(ctx, client.ObjectKeyFromObject(candidate.NodeClaim), nodeClaim); err != nil {
+		return fmt.Errorf("getting nodeclaim, %w", err)
+	}
+	hash := candidate.NodePool.Annotations[v1.NodePoolHashAnnotationKey]
+	if nodeClaim.Annotations[v1.NodeClaimRebootedNodePoolHashAnnotationKey] != hash {
+		if err := q.drain(ctx, node); err != nil {
+			return err
+		}
+		details := driftDetails(nodeClaim)
+		rebootTime := q.clock.Now()
+		if err := q.rebooter.Reboot(ctx, nodeClaim); err != nil {
+			return fmt.Errorf("rebooting instance, %w", err)
+		}
+		cmd.rebootTimes = lo.Assign(cmd.rebootTimes, map[string]time.Time{nodeClaim.Name: rebootTime})
+		if err := q.markRebooted(ctx, nodeClaim, hash); err != nil {
+			return fmt.Errorf("marking rebooted, %w", err)
+		}
+		q.recorder.Publish(disruptionevents.Rebooted(node, nodeClaim, details)...)
+		metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
+			metrics.ReasonLabel:       pretty.ToSnakeCase(string(cmd.Reason())),
+			metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
+			metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
+		})
+	}
+	// The node only counts as back once its Ready condition transitioned after the reboot. The reboot time isn't known
+	// for commands that were recovered after a restart, so they only wait for the node to be Ready.
+	ready := nodeutils.GetCondition(node, corev1.NodeReady)
+	if rebootTime, ok := cmd.rebootTimes[nodeClaim.Name]; ready.Status != corev1.ConditionTrue || (ok && !ready.LastTransitionTime.After(rebootTime)) {
+		return fmt.Errorf("waiting for node to be ready after reboot")
+	}
+	return nil
+}
+
+// drain evicts the pods of a candidate that's rebooted through the eviction queue, and returns an error until they've
+// all terminated. DaemonSet pods aren't evicted since they restart along with the node.
+func (q *Queue) drain(ctx context.Context, node *corev1.Node) error {
+	pods, err := nodeutils.GetPods(ctx, q.kubeClient, node)
+	if err != nil {
+		return fmt.Errorf("listing pods on node, %w", err)
+	}
+	waiting := lo.Filter(pods, func(p *corev1.Pod, _ int) bool {
+		return podutil.IsWaitingEviction(p, q.clock) && !podutil.IsOwnedByDaemonSet(p)
+	})
+	if len(waiting) == 0 {
+		return nil
+	}
+	q.evictionQueue.Add(lo.Filter(waiting, func(p *corev1.Pod, _ int) bool { return podutil.IsEvictable(p, q.clock) })...)
+	return fmt.Errorf("%d pods are waiting to be evicted", len(waiting))
+}
+
+// markRebooted records the NodePool hash that the NodeClaim was rebooted for and clears its Drifted status condition,
+// so that drift is detected again if the reboot didn't resolve it and the NodeClaim is then replaced
+func (q *Queue) markRebooted(ctx context.Context, nodeClaim *v1.NodeClaim, hash string) error {
+	stored := nodeClaim.DeepCopy()
+	nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{
+		v1.NodeClaimRebootedNodePoolHashAnnotationKey: hash,
+	})
+	if err := q.kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+		return err
+	}
+	stored = nodeClaim.DeepCopy()
+	_ = nodeClaim.StatusConditions().Clear(v1.ConditionTypeDrifted)
+	return q.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored))
+}
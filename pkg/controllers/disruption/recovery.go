@@ -0,0 +1,286 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
+	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+// persistCommand writes a DisruptionCommand for a command that has launched its replacements, so that the command can
+// be recovered if Karpenter restarts before it completes. Failing to write the object is logged, but never blocks the command.
+func (q *Queue) persistCommand(ctx context.Context, cmd *Command) {
+	disruptionCommand := NewDisruptionCommand(cmd)
+	if err := q.kubeClient.Create(ctx, disruptionCommand); err != nil {
+		log.FromContext(ctx).Error(err, "failed persisting disruption command")
+		return
+	}
+	q.updateCommandPhase(ctx, cmd, lo.Ternary(len(cmd.Replacements) > 0, v1alpha1.DisruptionCommandPhaseLaunching, v1alpha1.DisruptionCommandPhaseTerminating))
+}
+
+// updateCommandPhase records the progress of a command on its DisruptionCommand
+func (q *Queue) updateCommandPhase(ctx context.Context, cmd *Command, phase v1alpha1.DisruptionCommandPhase) {
+	if cmd.phase == phase {
+		return
+	}
+	disruptionCommand := &v1alpha1.DisruptionCommand{}
+	if err := q.kubeClient.Get(ctx, types.NamespacedName{Name: cmd.ID.String()}, disruptionCommand); err != nil {
+		log.FromContext(ctx).Error(client.IgnoreNotFound(err), "failed updating disruption command")
+		return
+	}
+	stored := disruptionCommand.DeepCopy()
+	disruptionCommand.Status.Phase = phase
+	if err := q.kubeClient.Status().Patch(ctx, disruptionCommand, client.MergeFrom(stored)); err != nil {
+		log.FromContext(ctx).Error(client.IgnoreNotFound(err), "failed updating disruption command")
+		return
+	}
+	cmd.phase = phase
+}
+
+// forgetCommand removes the DisruptionCommand of a command that has completed
+func (q *Queue) forgetCommand(ctx context.Context, cmd *Command) {
+	if err := q.kubeClient.Delete(ctx, &v1alpha1.DisruptionCommand{ObjectMeta: metav1.ObjectMeta{Name: cmd.ID.String()}}); client.IgnoreNotFound(err) != nil {
+		log.FromContext(ctx).Error(err, "failed removing disruption command")
+	}
+}
+
+// Recover rebuilds the commands that were in flight when Karpenter last stopped, so that their candidates stay tainted
+// and their replacements are awaited instead of being forgotten. Commands are rebuilt from their DisruptionCommands, or
+// from the candidates and replacements that they marked if they weren't persisted. Recovery only runs once, and must be
+// called after cluster state has synced so that the candidates can be found.
+func (q *Queue) Recover(ctx context.Context) error {
+	q.RLock()
+	recovered := q.recovered
+	q.RUnlock()
+	if recovered {
+		return nil
+	}
+	disruptionCommands := &v1alpha1.DisruptionCommandList{}
+	if err := q.kubeClient.List(ctx, disruptionCommands); err != nil {
+		return fmt.Errorf("listing disruption commands, %w", err)
+	}
+	nodePools := &v1.NodePoolList{}
+	if err := q.kubeClient.List(ctx, nodePools); err != nil {
+		return fmt.Errorf("listing nodepools, %w", err)
+	}
+	nodePoolsByName := lo.SliceToMap(nodePools.Items, func(np v1.NodePool) (string, *v1.NodePool) { return np.Name, &np })
+	nodes := q.cluster.SnapshotNodes()
+	stateNodes := lo.SliceToMap(nodes, func(n *state.StateNode) (string, *state.StateNode) { return n.ProviderID(), n })
+	for i := range disruptionCommands.Items {
+		cmd, err := commandFromDisruptionCommand(&disruptionCommands.Items[i], stateNodes, nodePoolsByName)
+		// Commands of other shards are recovered by their replicas
+		if err == nil && len(cmd.Candidates) > 0 && !inShard(ctx, cmd) {
+			continue
+		}
+		// Commands whose candidates are all gone have nothing left to orchestrate
+		if err != nil || len(cmd.Candidates) == 0 {
+			if err != nil {
+				log.FromContext(ctx).Error(err, "failed recovering disruption command")
+			}
+			if err := q.kubeClient.Delete(ctx, &disruptionCommands.Items[i]); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("removing disruption command, %w", err)
+			}
+			continue
+		}
+		q.enqueueRecovered(ctx, cmd)
+	}
+	for _, cmd := range commandsFromReplacements(nodes, nodePoolsByName, q.HasAny) {
+		if !inShard(ctx, cmd) {
+			continue
+		}
+		q.persistCommand(ctx, cmd)
+		q.enqueueRecovered(ctx, cmd)
+	}
+	q.Lock()
+	q.recovered = true
+	q.Unlock()
+	return nil
+}
+
+// enqueueRecovered marks the candidates of a recovered command for deletion and adds the command to the queue, resuming
+// its wait for replacements
+func (q *Queue) enqueueRecovered(ctx context.Context, cmd *Command) {
+	q.cluster.MarkForDeletion(lo.Map(cmd.Candidates, func(c *Candidate, _ int) string { return c.ProviderID() })...)
+	q.Lock()
+	for _, c := range cmd.Candidates {
+		q.ProviderIDToCommand[c.ProviderID()] = cmd
+	}
+	q.source <- event.TypedGenericEvent[*v1.NodeClaim]{Object: cmd.Candidates[0].NodeClaim}
+	q.Unlock()
+	q.updateMetrics()
+	log.FromContext(ctx).WithValues(append([]any{"command-id", cmd.ID}, cmd.LogValues()...)...).Info("recovered disruption command")
+}
+
+// inShard returns true if the command belongs to this replica's shard. Candidates are only computed from the NodePools of
+// a single shard, so the first candidate decides the shard of the whole command.
+func inShard(ctx context.Context, cmd *Command) bool {
+	return nodepoolutils.InShard(ctx, cmd.Candidates[0].Labels()[v1.NodePoolLabelKey])
+}
+
+// NewDisruptionCommand converts a command into a DisruptionCommand, named after the command's ID
+func NewDisruptionCommand(cmd *Command) *v1alpha1.DisruptionCommand {
+	return &v1alpha1.DisruptionCommand{
+		ObjectMeta: metav1.ObjectMeta{Name: cmd.ID.String()},
+		Spec: v1alpha1.DisruptionCommandSpec{
+			Reason:            string(cmd.Reason()),
+			Class:             cmd.Class(),
+			ConsolidationType: cmd.ConsolidationType(),
+			Candidates: lo.Map(cmd.Candidates, func(c *Candidate, _ int) v1alpha1.DisruptionCommandCandidate {
+				return v1alpha1.DisruptionCommandCandidate{NodeClaim: c.NodeClaim.Name, ProviderID: c.ProviderID()}
+			}),
+			Replacements: lo.Map(cmd.Replacements, func(r *Replacement, _ int) v1alpha1.DisruptionCommandReplacement {
+				return v1alpha1.DisruptionCommandReplacement{NodeClaim: r.Name, NodePool: r.NodePoolName}
+			}),
+			Reboot:    cmd.Reboot,
+			StartTime: metav1.NewTime(cmd.CreationTimestamp),
+		},
+	}
+}
+
+// commandFromDisruptionCommand rebuilds a command from its DisruptionCommand. Candidates that no longer exist in cluster
+// state are dropped from the command.
+func commandFromDisruptionCommand(disruptionCommand *v1alpha1.DisruptionCommand, stateNodes map[string]*state.StateNode,
+	nodePools map[string]*v1.NodePool) (*Command, error) {
+	id, err := uuid.Parse(disruptionCommand.Name)
+	if err != nil {
+		return nil, fmt.Errorf("parsing command id, %w", err)
+	}
+	cmd := &Command{
+		Method: recoveredMethod{
+			reason:            v1.DisruptionReason(disruptionCommand.Spec.Reason),
+			class:             disruptionCommand.Spec.Class,
+			consolidationType: disruptionCommand.Spec.ConsolidationType,
+		},
+		CreationTimestamp: disruptionCommand.Spec.StartTime.Time,
+		ID:                id,
+		Reboot:            disruptionCommand.Spec.Reboot,
+		phase:             disruptionCommand.Status.Phase,
+	}
+	for _, c := range disruptionCommand.Spec.Candidates {
+		if stateNode, ok := stateNodes[c.ProviderID]; ok && stateNode.NodeClaim != nil {
+			cmd.Candidates = append(cmd.Candidates, recoveredCandidate(stateNode, nodePools))
+		}
+	}
+	cmd.Replacements = lo.Map(disruptionCommand.Spec.Replacements, func(r v1alpha1.DisruptionCommandReplacement, _ int) *Replacement {
+		return recoveredReplacement(r.NodeClaim, r.NodePool)
+	})
+	return cmd, nil
+}
+
+// commandsFromReplacements rebuilds the commands that weren't persisted as DisruptionCommands. Candidates keep their
+// DisruptionReason condition while their command is in flight, and the replacements launched by a command share a
+// karpenter.sh/replaces annotation that names its candidates. Candidates that are marked without any replacements are
+// left for the disruption controller to unmark, since their command may have failed before launching its replacements.
+func commandsFromReplacements(nodes state.StateNodes, nodePools map[string]*v1.NodePool, inFlight func(...string) bool) []*Command {
+	candidates := map[string]*state.StateNode{}
+	replacements := map[string][]*state.StateNode{}
+	for _, n := range nodes {
+		if n.NodeClaim == nil || n.MarkedForDeletion() {
+			continue
+		}
+		if n.NodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason).IsTrue() && !inFlight(n.ProviderID()) {
+			candidates[n.NodeClaim.Name] = n
+		}
+		if replaces, ok := n.NodeClaim.Annotations[v1.NodeClaimReplacesAnnotationKey]; ok {
+			replacements[replaces] = append(replacements[replaces], n)
+		}
+	}
+	var cmds []*Command
+	for replaces, replacementNodes := range replacements {
+		candidateNodes := lo.FilterMap(strings.Split(replaces, ","), func(name string, _ int) (*state.StateNode, bool) {
+			n, ok := candidates[name]
+			return n, ok
+		})
+		if len(candidateNodes) == 0 {
+			continue
+		}
+		reason := v1.DisruptionReason(candidateNodes[0].NodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason).Reason)
+		cmds = append(cmds, &Command{
+			Method: recoveredMethod{
+				reason: reason,
+				class:  lo.Ternary(reason == v1.DisruptionReasonDrifted || reason == v1.DisruptionReasonUnhealthy, EventualDisruptionClass, GracefulDisruptionClass),
+			},
+			// The command was computed just before its replacements were launched
+			CreationTimestamp: lo.MinBy(replacementNodes, func(a, b *state.StateNode) bool {
+				return a.NodeClaim.CreationTimestamp.Before(&b.NodeClaim.CreationTimestamp)
+			}).NodeClaim.CreationTimestamp.Time,
+			ID: uuid.New(),
+			Candidates: lo.Map(candidateNodes, func(n *state.StateNode, _ int) *Candidate {
+				return recoveredCandidate(n, nodePools)
+			}),
+			Replacements: lo.Map(replacementNodes, func(n *state.StateNode, _ int) *Replacement {
+				return recoveredReplacement(n.NodeClaim.Name, n.Labels()[v1.NodePoolLabelKey])
+			}),
+		})
+		// A candidate can only belong to a single command
+		for _, n := range candidateNodes {
+			delete(candidates, n.NodeClaim.Name)
+		}
+	}
+	return cmds
+}
+
+func recoveredCandidate(stateNode *state.StateNode, nodePools map[string]*v1.NodePool) *Candidate {
+	nodePoolName := stateNode.Labels()[v1.NodePoolLabelKey]
+	nodePool, ok := nodePools[nodePoolName]
+	if !ok {
+		nodePool = &v1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: nodePoolName}}
+	}
+	return &Candidate{StateNode: stateNode, NodePool: nodePool}
+}
+
+func recoveredReplacement(nodeClaimName, nodePoolName string) *Replacement {
+	return &Replacement{
+		NodeClaim: &pscheduling.NodeClaim{NodeClaimTemplate: pscheduling.NodeClaimTemplate{NodePoolName: nodePoolName}},
+		Name:      nodeClaimName,
+	}
+}
+
+// recoveredMethod stands in for the method that computed a command recovered from its DisruptionCommand. It never
+// produces commands of its own.
+type recoveredMethod struct {
+	reason            v1.DisruptionReason
+	class             string
+	consolidationType string
+}
+
+func (m recoveredMethod) ShouldDisrupt(context.Context, *Candidate) bool { return false }
+
+func (m recoveredMethod) ComputeCommands(context.Context, DisruptionBudgetMapping, ...*Candidate) ([]Command, error) {
+	return nil, nil
+}
+
+func (m recoveredMethod) Reason() v1.DisruptionReason { return m.reason }
+
+func (m recoveredMethod) Class() string { return m.class }
+
+func (m recoveredMethod) ConsolidationType() string { return m.consolidationType }
@@ -0,0 +1,106 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+)
+
+// Repair is a subreconciler that replaces the candidates that node repair has marked as Unhealthy. Replacements are
+// launched for the candidate's pods before it's deleted, after which it's forcefully drained.
+type Repair struct {
+	clock       clock.Clock
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
+	recorder    events.Recorder
+}
+
+func NewRepair(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Repair {
+	return &Repair{
+		clock:       clk,
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
+		recorder:    recorder,
+	}
+}
+
+// ShouldDisrupt is a predicate used to filter candidates
+func (r *Repair) ShouldDisrupt(_ context.Context, c *Candidate) bool {
+	if c.OwnedByStaticNodePool() {
+		return false
+	}
+	return c.NodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy).IsTrue()
+}
+
+// ComputeCommands generates a disruption command for the first unhealthy candidate whose pods can be rescheduled
+func (r *Repair) ComputeCommands(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) ([]Command, error) {
+	for _, candidate := range candidates {
+		// If the disruption budget doesn't allow this candidate to be disrupted,
+		// continue to the next candidate.
+		if !disruptionBudgetMapping.Allows(candidate) {
+			recordBudgetSkipped(ctx, candidate.NodePool.Name, 1)
+			continue
+		}
+		// Check if we need to create any NodeClaims.
+		results, err := SimulateScheduling(ctx, r.kubeClient, r.cluster, r.provisioner, r.clock, candidate)
+		if err != nil {
+			// if a candidate is now deleting, just retry
+			if errors.Is(err, errCandidateDeleting) {
+				continue
+			}
+			return []Command{}, err
+		}
+		if !results.AllNonPendingPodsScheduled() {
+			recordSkipped(ctx, skipReasonSimulation, 1)
+			r.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, fmt.Sprintf("Repair is blocked, %s", pretty.Sentence(results.NonPendingPodSchedulingErrors())))...)
+			publishPodsBlocked(r.recorder, candidate, r.Reason(), results)
+			continue
+		}
+		return []Command{{
+			Candidates:   []*Candidate{candidate},
+			Replacements: replacementsFromNodeClaims(results.NewNodeClaims...),
+			Results:      results,
+		}}, nil
+	}
+	return []Command{}, nil
+}
+
+func (r *Repair) Reason() v1.DisruptionReason {
+	return v1.DisruptionReasonUnhealthy
+}
+
+func (r *Repair) Class() string {
+	return EventualDisruptionClass
+}
+
+func (r *Repair) ConsolidationType() string {
+	return ""
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var _ = Describe("Repair", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Disruption: v1.Disruption{
+					ConsolidateAfter: v1.MustParseNillableDuration("Never"),
+					Budgets: []v1.Budget{{
+						Nodes: "100%",
+					}},
+				},
+			},
+		})
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				ProviderID: test.RandomProviderID(),
+				Allocatable: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU:  resource.MustParse("32"),
+					corev1.ResourcePods: resource.MustParse("100"),
+				},
+			},
+		})
+		Expect(nodeClaim.StatusConditions().Clear(v1.ConditionTypeConsolidatable)).To(BeNil())
+	})
+	It("should replace an unhealthy node", func() {
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, string(corev1.NodeReady), "")
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		cmds := queue.GetCommands()
+		Expect(cmds).To(HaveLen(1))
+		Expect(cmds[0].Reason()).To(Equal(v1.DisruptionReasonUnhealthy))
+		Expect(cmds[0].Replacements).To(HaveLen(1))
+		ExpectMakeNewNodeClaimsReady(ctx, env.Client, cluster, cloudProvider, cmds[0])
+		ExpectObjectReconciled(ctx, env.Client, queue, nodeClaim)
+
+		ExpectNodeClaimsCascadeDeletion(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should replace an unhealthy node with pods that block eviction", func() {
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, string(corev1.NodeReady), "")
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.DoNotDisruptAnnotationKey: "true"},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		cmds := queue.GetCommands()
+		Expect(cmds).To(HaveLen(1))
+		Expect(cmds[0].Reason()).To(Equal(v1.DisruptionReasonUnhealthy))
+	})
+	It("should replace an uninitialized node whose startup taints timed out", func() {
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, v1.UnhealthyReasonStartupTaintsTimedOut, "")
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaim))
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		cmds := queue.GetCommands()
+		Expect(cmds).To(HaveLen(1))
+		Expect(cmds[0].Reason()).To(Equal(v1.DisruptionReasonUnhealthy))
+	})
+	It("should not disrupt an uninitialized node that is unhealthy for another reason", func() {
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, string(corev1.NodeReady), "")
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+		ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaim))
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		Expect(queue.GetCommands()).To(HaveLen(0))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should not disrupt a node that isn't unhealthy", func() {
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		Expect(queue.GetCommands()).To(HaveLen(0))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should respect a budget that blocks unhealthy disruption", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{
+			Nodes:   "0",
+			Reasons: []v1.DisruptionReason{v1.DisruptionReasonUnhealthy},
+		}}
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, string(corev1.NodeReady), "")
+		ExpectApplied(ctx, env.Client, nodeClaim, node, nodePool)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, disruptionController)
+		Expect(queue.GetCommands()).To(HaveLen(0))
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+})
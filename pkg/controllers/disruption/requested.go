@@ -0,0 +1,110 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+)
+
+// DisruptNowAnnotationValue is the value of the karpenter.sh/disrupt annotation that requests a node be disrupted
+const DisruptNowAnnotationValue = "now"
+
+// Requested is a subreconciler that gracefully disrupts candidates that operators have requested to be disrupted
+// with the karpenter.sh/disrupt annotation. Unlike deleting the NodeClaim, replacements are launched for the
+// candidate's pods before it's drained.
+type Requested struct {
+	clock       clock.Clock
+	kubeClient  client.Client
+	cluster     *state.Cluster
+	provisioner *provisioning.Provisioner
+	recorder    events.Recorder
+}
+
+func NewRequested(clk clock.Clock, kubeClient client.Client, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Requested {
+	return &Requested{
+		clock:       clk,
+		kubeClient:  kubeClient,
+		cluster:     cluster,
+		provisioner: provisioner,
+		recorder:    recorder,
+	}
+}
+
+// ShouldDisrupt is a predicate used to filter candidates
+func (r *Requested) ShouldDisrupt(_ context.Context, c *Candidate) bool {
+	if c.OwnedByStaticNodePool() {
+		return false
+	}
+	return c.Annotations()[v1.DisruptAnnotationKey] == DisruptNowAnnotationValue || c.NodeClaim.Annotations[v1.DisruptAnnotationKey] == DisruptNowAnnotationValue
+}
+
+// ComputeCommands generates a disruption command for the first requested candidate whose pods can be rescheduled
+func (r *Requested) ComputeCommands(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) ([]Command, error) {
+	for _, candidate := range candidates {
+		// If the disruption budget doesn't allow this candidate to be disrupted,
+		// continue to the next candidate.
+		if !disruptionBudgetMapping.Allows(candidate) {
+			recordBudgetSkipped(ctx, candidate.NodePool.Name, 1)
+			continue
+		}
+		// Check if we need to create any NodeClaims.
+		results, err := SimulateScheduling(ctx, r.kubeClient, r.cluster, r.provisioner, r.clock, candidate)
+		if err != nil {
+			// if a candidate is now deleting, just retry
+			if errors.Is(err, errCandidateDeleting) {
+				continue
+			}
+			return []Command{}, err
+		}
+		if !results.AllNonPendingPodsScheduled() {
+			recordSkipped(ctx, skipReasonSimulation, 1)
+			r.recorder.Publish(disruptionevents.Blocked(candidate.Node, candidate.NodeClaim, fmt.Sprintf("Requested disruption is blocked, %s", pretty.Sentence(results.NonPendingPodSchedulingErrors())))...)
+			publishPodsBlocked(r.recorder, candidate, r.Reason(), results)
+			continue
+		}
+		return []Command{{
+			Candidates:   []*Candidate{candidate},
+			Replacements: replacementsFromNodeClaims(results.NewNodeClaims...),
+			Results:      results,
+		}}, nil
+	}
+	return []Command{}, nil
+}
+
+func (r *Requested) Reason() v1.DisruptionReason {
+	return v1.DisruptionReasonRequested
+}
+
+func (r *Requested) Class() string {
+	return GracefulDisruptionClass
+}
+
+func (r *Requested) ConsolidationType() string {
+	return ""
+}
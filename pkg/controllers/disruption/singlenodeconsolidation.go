@@ -53,7 +53,7 @@ func NewSingleNodeConsolidation(c consolidation, opts ...option.Function[MethodO
 
 // ComputeCommand generates a disruption command given candidates
 // nolint:gocyclo
-func (s *SingleNodeConsolidation) ComputeCommands(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) ([]Command, error) {
+func (s *SingleNodeConsolidation) ComputeCommands(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) ([]Command, error) {
 	if s.IsConsolidated() {
 		return []Command{}, nil
 	}
@@ -80,8 +80,9 @@ func (s *SingleNodeConsolidation) ComputeCommands(ctx context.Context, disruptio
 		// If the disruption budget doesn't allow this candidate to be disrupted,
 		// continue to the next candidate. We don't need to decrement any budget
 		// counter since single node consolidation commands can only have one candidate.
-		if disruptionBudgetMapping[candidate.NodePool.Name] == 0 {
+		if !disruptionBudgetMapping.Allows(candidate) {
 			constrainedByBudgets = true
+			recordBudgetSkipped(ctx, candidate.NodePool.Name, 1)
 			continue
 		}
 		// Filter out empty candidates. If there was an empty node that wasn't consolidated before this, we should
@@ -102,7 +103,7 @@ func (s *SingleNodeConsolidation) ComputeCommands(ctx context.Context, disruptio
 		}
 		if _, err = s.validator.Validate(ctx, cmd, consolidationTTL); err != nil {
 			if IsValidationError(err) {
-				log.FromContext(ctx).V(1).WithValues(cmd.LogValues()...).Info("abandoning single-node consolidation attempt due to pod churn, command is no longer valid")
+				recordSkipped(ctx, skipReasonValidation, len(cmd.Candidates))
 				return []Command{}, nil
 			}
 			return []Command{}, fmt.Errorf("validating consolidation, %w", err)
@@ -140,7 +141,7 @@ func (s *SingleNodeConsolidation) SortCandidates(ctx context.Context, candidates
 
 	// First sort by disruption cost as the base ordering
 	sort.Slice(candidates, func(i int, j int) bool {
-		return candidates[i].DisruptionCost < candidates[j].DisruptionCost
+		return lessDisruptive(ctx, candidates[i], candidates[j])
 	})
 
 	return s.shuffleCandidates(ctx, lo.GroupBy(candidates, func(c *Candidate) string { return c.NodePool.Name }))
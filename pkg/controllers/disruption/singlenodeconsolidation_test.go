@@ -17,6 +17,7 @@ limitations under the License.
 package disruption_test
 
 import (
+	"math"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -191,10 +192,10 @@ var _ = Describe("SingleNodeConsolidation", func() {
 			// Mark nodePool2 as timed out
 			consolidation.PreviouslyUnseenNodePools.Insert(nodePool2.Name)
 			// Create a budget mapping that allows all disruptions
-			budgetMapping := map[string]int{
-				nodePool1.Name: 1,
-				nodePool2.Name: 1,
-				nodePool3.Name: 1,
+			budgetMapping := disruption.DisruptionBudgetMapping{
+				nodePool1.Name: {Nodes: 1, Pods: math.MaxInt32},
+				nodePool2.Name: {Nodes: 1, Pods: math.MaxInt32},
+				nodePool3.Name: {Nodes: 1, Pods: math.MaxInt32},
 			}
 
 			// Call ComputeCommand which should process all nodepools
@@ -211,10 +212,10 @@ var _ = Describe("SingleNodeConsolidation", func() {
 			Expect(err).To(BeNil())
 
 			// Create a budget mapping that allows all disruptions
-			budgetMapping := map[string]int{
-				nodePool1.Name: 30,
-				nodePool2.Name: 30,
-				nodePool3.Name: 30,
+			budgetMapping := disruption.DisruptionBudgetMapping{
+				nodePool1.Name: {Nodes: 30, Pods: math.MaxInt32},
+				nodePool2.Name: {Nodes: 30, Pods: math.MaxInt32},
+				nodePool3.Name: {Nodes: 30, Pods: math.MaxInt32},
 			}
 
 			_, _ = consolidation.ComputeCommands(ctx, budgetMapping, candidates...)
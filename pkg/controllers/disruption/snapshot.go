@@ -0,0 +1,69 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/utils/pdb"
+)
+
+type clusterSnapshotKey struct{}
+
+// clusterSnapshot is the cluster state that the methods of a single disruption loop are evaluated against. The state
+// nodes and PodDisruptionBudget limits are taken once per loop and shared by every method and scheduling simulation, so
+// that they're evaluated against a consistent view of the cluster. The disruption budgets that gate lower priority
+// reasons are built at most once per reason and loop.
+type clusterSnapshot struct {
+	nodes   state.StateNodes
+	pdbs    pdb.Limits
+	budgets map[v1.DisruptionReason]DisruptionBudgetMapping
+}
+
+func newClusterSnapshot(cluster *state.Cluster) *clusterSnapshot {
+	return &clusterSnapshot{nodes: cluster.SnapshotNodes(), pdbs: cluster.PodDisruptionBudgetLimits(), budgets: map[v1.DisruptionReason]DisruptionBudgetMapping{}}
+}
+
+func withClusterSnapshot(ctx context.Context, snapshot *clusterSnapshot) context.Context {
+	return context.WithValue(ctx, clusterSnapshotKey{}, snapshot)
+}
+
+// withoutClusterSnapshot drops the snapshot of the disruption loop, e.g. so that validation observes the cluster as it
+// is after the validation period rather than as it was when the commands were computed.
+func withoutClusterSnapshot(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clusterSnapshotKey{}, (*clusterSnapshot)(nil))
+}
+
+// snapshotNodes returns the state nodes of the disruption loop's snapshot, or a snapshot of the current state nodes when
+// evaluated outside of the loop.
+func snapshotNodes(ctx context.Context, cluster *state.Cluster) state.StateNodes {
+	if snapshot, ok := ctx.Value(clusterSnapshotKey{}).(*clusterSnapshot); ok && snapshot != nil {
+		return snapshot.nodes
+	}
+	return cluster.SnapshotNodes()
+}
+
+// snapshotPDBs returns the PodDisruptionBudget limits of the disruption loop's snapshot, or the current limits when
+// evaluated outside of the loop.
+func snapshotPDBs(ctx context.Context, cluster *state.Cluster) pdb.Limits {
+	if snapshot, ok := ctx.Value(clusterSnapshotKey{}).(*clusterSnapshot); ok && snapshot != nil {
+		return snapshot.pdbs
+	}
+	return cluster.PodDisruptionBudgetLimits()
+}
@@ -18,6 +18,7 @@ package disruption
 
 import (
 	"context"
+	"maps"
 	"math"
 
 	"github.com/samber/lo"
@@ -51,7 +52,7 @@ func (d *StaticDrift) ShouldDisrupt(_ context.Context, c *Candidate) bool {
 	return c.OwnedByStaticNodePool() && c.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()
 }
 
-func (d *StaticDrift) ComputeCommands(ctx context.Context, disruptionBudgetMapping map[string]int, candidates ...*Candidate) ([]Command, error) {
+func (d *StaticDrift) ComputeCommands(ctx context.Context, disruptionBudgetMapping DisruptionBudgetMapping, candidates ...*Candidate) ([]Command, error) {
 	// Group candidates by nodepool name
 	candidatesByNodePool := lo.GroupBy(candidates, func(candidate *Candidate) string {
 		return candidate.NodePool.Name
@@ -61,7 +62,7 @@ func (d *StaticDrift) ComputeCommands(ctx context.Context, disruptionBudgetMappi
 	for npName, npCandidates := range candidatesByNodePool {
 		np := npCandidates[0].NodePool
 
-		if disruptionBudgetMapping[npName] == 0 {
+		if disruptionBudgetMapping[npName].Nodes == 0 {
 			continue
 		}
 
@@ -75,10 +76,17 @@ func (d *StaticDrift) ComputeCommands(ctx context.Context, disruptionBudgetMappi
 			continue
 		}
 
-		maxDrifts := lo.Min([]int64{
-			int64(disruptionBudgetMapping[np.Name]),
-			int64(len(npCandidates)),
-		})
+		// Only drift as many candidates, in order, as fit within both the node and pod budgets
+		budgets := maps.Clone(disruptionBudgetMapping)
+		maxDrifts := int64(0)
+		for _, c := range npCandidates {
+			if !budgets.Allows(c) {
+				break
+			}
+			budgets.Consume(c)
+			maxDrifts++
+		}
+		recordBudgetSkipped(ctx, npName, len(npCandidates)-int(maxDrifts))
 
 		// Acquire limits from cluster state without bursting over
 		maxAllowedDrifts := d.cluster.NodePoolState.ReserveNodeCount(npName, nodeLimit, maxDrifts)
@@ -19,6 +19,7 @@ package disruption_test
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -69,6 +70,7 @@ var prov *provisioning.Provisioner
 var cloudProvider *fake.CloudProvider
 var nodeStateController *informer.NodeController
 var nodeClaimStateController *informer.NodeClaimController
+var pdbStateController *informer.PodDisruptionBudgetController
 var fakeClock *clock.FakeClock
 var recorder *test.EventRecorder
 var queue *disruption.Queue
@@ -95,6 +97,7 @@ var _ = BeforeSuite(func() {
 	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
 	nodeStateController = informer.NewNodeController(env.Client, cluster)
 	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster)
+	pdbStateController = informer.NewPodDisruptionBudgetController(env.Client, cluster)
 	recorder = test.NewEventRecorder()
 	prov = provisioning.NewProvisioner(env.Client, recorder, cloudProvider, cluster, fakeClock)
 	queue = disruption.NewQueue(env.Client, recorder, cluster, fakeClock, prov)
@@ -160,6 +163,9 @@ var _ = AfterEach(func() {
 
 	// Reset the metrics collectors
 	disruption.DecisionsPerformedTotal.Reset()
+	disruption.CandidatesSkippedTotal.Reset()
+	disruption.DryRunDecisionsTotal.Reset()
+	disruption.QueueCommandFailuresTotal.Reset()
 })
 
 var _ = Describe("Simulate Scheduling", func() {
@@ -235,7 +241,7 @@ var _ = Describe("Simulate Scheduling", func() {
 		candidate, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, stateNode, pdbs, nodePoolMap, nodePoolToInstanceTypesMap, queue, disruption.GracefulDisruptionClass)
 		Expect(err).To(Succeed())
 
-		results, err := disruption.SimulateScheduling(ctx, env.Client, cluster, prov, candidate)
+		results, err := disruption.SimulateScheduling(ctx, env.Client, cluster, prov, fakeClock, candidate)
 		Expect(err).To(Succeed())
 		Expect(results.PodErrors[pod]).To(BeNil())
 	})
@@ -706,7 +712,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
 			// This should not bring in the unmanaged node.
-			Expect(budgets[nodePool.Name]).To(Equal(10))
+			Expect(budgets[nodePool.Name].Nodes).To(Equal(10))
 		}
 	})
 	It("should not consider nodes that are not initialized as part of disruption count", func() {
@@ -737,7 +743,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
 			// This should not bring in the uninitialized node.
-			Expect(budgets[nodePool.Name]).To(Equal(10))
+			Expect(budgets[nodePool.Name].Nodes).To(Equal(10))
 		}
 	})
 	It("should not consider nodes that have the terminating status condition as part of disruption count", func() {
@@ -769,7 +775,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
 			// This should not bring in the terminating node.
-			Expect(budgets[nodePool.Name]).To(Equal(10))
+			Expect(budgets[nodePool.Name].Nodes).To(Equal(10))
 		}
 	})
 	It("should not return a negative disruption value", func() {
@@ -790,7 +796,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		for _, reason := range allKnownDisruptionReasons {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
-			Expect(budgets[nodePool.Name]).To(Equal(0))
+			Expect(budgets[nodePool.Name].Nodes).To(Equal(0))
 		}
 	})
 	It("should consider nodes with a deletion timestamp set and MarkedForDeletion to the disruption count", func() {
@@ -814,7 +820,7 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		for _, reason := range allKnownDisruptionReasons {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
-			Expect(budgets[nodePool.Name]).To(Equal(8))
+			Expect(budgets[nodePool.Name].Nodes).To(Equal(8))
 		}
 	})
 	It("should consider not ready nodes to the disruption count", func() {
@@ -835,9 +841,68 @@ var _ = Describe("BuildDisruptionBudgetMapping", func() {
 		for _, reason := range allKnownDisruptionReasons {
 			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
 			Expect(err).To(Succeed())
-			Expect(budgets[nodePool.Name]).To(Equal(8))
+			Expect(budgets[nodePool.Name].Nodes).To(Equal(8))
 		}
 	})
+	It("should not limit pod evictions when no budget sets pods", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%"}}
+		ExpectApplied(ctx, env.Client, nodePool)
+
+		for _, reason := range allKnownDisruptionReasons {
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
+			Expect(err).To(Succeed())
+			Expect(budgets[nodePool.Name].Pods).To(Equal(math.MaxInt32))
+		}
+	})
+	It("should subtract reschedulable pods on disrupting nodes from the pod disruption count", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%", Pods: lo.ToPtr[int32](10)}}
+		ExpectApplied(ctx, env.Client, nodePool)
+
+		pods := test.Pods(3, test.PodOptions{})
+		for _, p := range pods {
+			ExpectApplied(ctx, env.Client, p)
+			ExpectManualBinding(ctx, env.Client, p, nodes[0])
+		}
+		cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID)
+
+		for _, reason := range allKnownDisruptionReasons {
+			budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, reason)
+			Expect(err).To(Succeed())
+			Expect(budgets[nodePool.Name].Nodes).To(Equal(9))
+			Expect(budgets[nodePool.Name].Pods).To(Equal(7))
+		}
+	})
+	It("should only subtract the disrupting nodes that drifted for a drift reason from its disruption count", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{
+			{Nodes: "100%"},
+			{DriftReasons: []string{string(cloudprovider.ImageDrifted)}, Nodes: "3"},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		nodeClaims[0].StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(cloudprovider.ImageDrifted), string(cloudprovider.ImageDrifted))
+		nodeClaims[1].StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(cloudprovider.UserDataDrifted), string(cloudprovider.UserDataDrifted))
+		ExpectApplied(ctx, env.Client, nodeClaims[0], nodeClaims[1])
+		ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaims[0]))
+		ExpectReconcileSucceeded(ctx, nodeClaimStateController, client.ObjectKeyFromObject(nodeClaims[1]))
+		cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID, nodeClaims[1].Status.ProviderID)
+
+		budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name].Nodes).To(Equal(8))
+		Expect(budgets[nodePool.Name].DriftReasons).To(Equal(map[string]int{string(cloudprovider.ImageDrifted): 2}))
+	})
+	It("should only mark NodePools without disrupting nodes as idle", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: "100%", Pods: lo.ToPtr[int32](10)}}
+		ExpectApplied(ctx, env.Client, nodePool)
+
+		budgets, err := disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name].Idle).To(BeTrue())
+
+		cluster.MarkForDeletion(nodeClaims[0].Status.ProviderID)
+		budgets, err = disruption.BuildDisruptionBudgetMapping(ctx, cluster, fakeClock, env.Client, cloudProvider, recorder, v1.DisruptionReasonDrifted)
+		Expect(err).To(Succeed())
+		Expect(budgets[nodePool.Name].Idle).To(BeFalse())
+	})
 })
 
 var _ = Describe("Pod Eviction Cost", func() {
@@ -1071,6 +1136,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
 
@@ -1080,6 +1146,66 @@ var _ = Describe("Candidate Filtering", func() {
 		Expect(c.NodeClaim).ToNot(BeNil())
 		Expect(c.Node).ToNot(BeNil())
 	})
+	It("should consider candidates that have do-not-disrupt pods scheduled with a drifted terminationGracePeriodOverride set for eventual disruption", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+		})
+		nodeClaim.Spec.TerminationGracePeriodOverrides = []v1.TerminationGracePeriodOverride{
+			{Reason: string(v1.DisruptionReasonDrifted), TerminationGracePeriod: v1.MustParseNillableDuration("24h")},
+		}
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1.DoNotDisruptAnnotationKey: "true",
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		Expect(cluster.DeepCopyNodes()).To(HaveLen(1))
+		c, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.DeepCopyNodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.EventualDisruptionClass)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.NodeClaim).ToNot(BeNil())
+	})
+	It("should not consider candidates that have do-not-disrupt pods scheduled with a drifted terminationGracePeriodOverride of Never for eventual disruption", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+		})
+		nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
+		nodeClaim.Spec.TerminationGracePeriodOverrides = []v1.TerminationGracePeriodOverride{
+			{Reason: string(v1.DisruptionReasonDrifted), TerminationGracePeriod: v1.MustParseNillableDuration("Never")},
+		}
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1.DoNotDisruptAnnotationKey: "true",
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		Expect(cluster.DeepCopyNodes()).To(HaveLen(1))
+		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.DeepCopyNodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.EventualDisruptionClass)
+		Expect(err).To(HaveOccurred())
+	})
 	It("should not consider candidates that have do-not-disrupt pods scheduled with a terminationGracePeriod set for graceful disruption", func() {
 		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -1132,6 +1258,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
 
@@ -1195,6 +1322,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
 
@@ -1327,6 +1455,8 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget1, budget2)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget1))
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget2))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
@@ -1371,6 +1501,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
@@ -1418,6 +1549,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
@@ -1464,6 +1596,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
@@ -1478,6 +1611,37 @@ var _ = Describe("Candidate Filtering", func() {
 		Expect(c.NodeClaim).ToNot(BeNil())
 		Expect(c.Node).ToNot(BeNil())
 	})
+	It("should not count mirror pods towards the disruption cost of a candidate", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{corev1.MirrorPodAnnotationKey: "mirror"},
+			},
+		})
+		ExpectApplied(ctx, env.Client, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		var err error
+		pdbLimits, err = pdb.NewLimits(ctx, env.Client)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cluster.DeepCopyNodes()).To(HaveLen(1))
+		c, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.DeepCopyNodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.DisruptionCost).To(BeNumerically("==", 0))
+	})
 	It("should not consider candidates that have do-not-disrupt pods without a terminationGracePeriod set for graceful disruption", func() {
 		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -1531,6 +1695,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
 		var err error
@@ -1572,6 +1737,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, succeededPod, failedPod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, succeededPod, node)
 		ExpectManualBinding(ctx, env.Client, failedPod, node)
 
@@ -1609,6 +1775,7 @@ var _ = Describe("Candidate Filtering", func() {
 			MaxUnavailable: fromInt(0),
 		})
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod, budget)
+		ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(budget))
 		ExpectManualBinding(ctx, env.Client, pod, node)
 
 		ExpectDeletionTimestampSet(ctx, env.Client, pod)
@@ -1878,7 +2045,7 @@ var _ = Describe("Candidate Filtering", func() {
 		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
 
 		Expect(cluster.DeepCopyNodes()).To(HaveLen(1))
-		cmd := &disruption.Command{Method: disruption.NewDrift(env.Client, cluster, prov, recorder), Results: pscheduling.Results{}, Candidates: []*disruption.Candidate{{StateNode: cluster.DeepCopyNodes()[0], NodePool: nodePool}}, Replacements: nil}
+		cmd := &disruption.Command{Method: disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder), Results: pscheduling.Results{}, Candidates: []*disruption.Candidate{{StateNode: cluster.DeepCopyNodes()[0], NodePool: nodePool}}, Replacements: nil}
 		Expect(queue.StartCommand(ctx, cmd))
 
 		_, err := disruption.NewCandidate(ctx, env.Client, recorder, fakeClock, cluster.DeepCopyNodes()[0], pdbLimits, nodePoolMap, nodePoolInstanceTypeMap, queue, disruption.GracefulDisruptionClass)
@@ -1887,6 +2054,156 @@ var _ = Describe("Candidate Filtering", func() {
 	})
 })
 
+var _ = Describe("RateLimiter", func() {
+	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{DisruptionRateLimit: lo.ToPtr(4)}))
+	})
+	It("should share the token bucket between rate limiters", func() {
+		taken, release, err := disruption.NewRateLimiter(fakeClock, env.Client, env.Client).Take(ctx, 3, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taken).To(Equal(3))
+
+		// A rate limiter of another shard, or of the next leader, sees the tokens that were taken
+		rateLimiter := disruption.NewRateLimiter(fakeClock, env.Client, env.Client)
+		Expect(rateLimiter.Available(ctx)).To(Equal(1))
+		taken, _, err = rateLimiter.Take(ctx, 2, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taken).To(Equal(0))
+
+		release()
+		Expect(rateLimiter.Available(ctx)).To(Equal(4))
+	})
+	It("should take the tokens that are available when taking part of the tokens", func() {
+		rateLimiter := disruption.NewRateLimiter(fakeClock, env.Client, env.Client)
+		taken, _, err := rateLimiter.Take(ctx, 3, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taken).To(Equal(3))
+
+		taken, release, err := rateLimiter.Take(ctx, 2, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taken).To(Equal(1))
+		Expect(rateLimiter.Available(ctx)).To(Equal(0))
+
+		release()
+		Expect(rateLimiter.Available(ctx)).To(Equal(1))
+	})
+	It("should retry taking the tokens when another rate limiter takes them concurrently", func() {
+		taken, _, err := disruption.NewRateLimiter(fakeClock, env.Client, env.Client).Take(ctx, 1, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taken).To(Equal(1))
+
+		// Another rate limiter takes a token after the Lease is read, so the first attempt conflicts
+		rateLimiter := disruption.NewRateLimiter(fakeClock, env.Client, &racingReader{Reader: env.Client, race: func() {
+			taken, _, err := disruption.NewRateLimiter(fakeClock, env.Client, env.Client).Take(ctx, 1, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(taken).To(Equal(1))
+		}})
+		taken, _, err = rateLimiter.Take(ctx, 2, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taken).To(Equal(2))
+		Expect(rateLimiter.Available(ctx)).To(Equal(0))
+	})
+})
+
+// racingReader runs race after the first read, as if another client changed the object after it was read
+type racingReader struct {
+	client.Reader
+	race func()
+}
+
+func (r *racingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	err := r.Reader.Get(ctx, key, obj, opts...)
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return err
+}
+
+var _ = Describe("Report", func() {
+	var nodePool *v1.NodePool
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+	})
+	It("should report the candidates of each NodePool and why they can't be disrupted", func() {
+		nodeClaims, nodes := test.NodeClaimsAndNodes(2, v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+		})
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1.DoNotDisruptAnnotationKey: "true",
+				},
+			},
+		})
+		nodeClaims[1].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		nodes[1].Annotations = lo.Assign(nodes[1].Annotations, map[string]string{v1.DoNotDisruptReasonsAnnotationKey: "Underutilized"})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodes[0], nodeClaims[1], nodes[1], pod)
+		ExpectManualBinding(ctx, env.Client, pod, nodes[0])
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+
+		reports, err := disruptionController.Report(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(HaveLen(1))
+		Expect(reports[0].NodePool).To(Equal(nodePool.Name))
+		Expect(reports[0].AllowedDisruptions).To(HaveKey(v1.DisruptionReasonDrifted))
+		Expect(reports[0].Candidates).To(HaveLen(2))
+
+		blocked, ok := lo.Find(reports[0].Candidates, func(c disruption.CandidateReport) bool { return c.NodeClaim == nodeClaims[0].Name })
+		Expect(ok).To(BeTrue())
+		Expect(blocked.Blocked).To(ContainSubstring("karpenter.sh/do-not-disrupt"))
+		Expect(blocked.Reasons).To(BeEmpty())
+
+		drifted, ok := lo.Find(reports[0].Candidates, func(c disruption.CandidateReport) bool { return c.NodeClaim == nodeClaims[1].Name })
+		Expect(ok).To(BeTrue())
+		Expect(drifted.Blocked).To(BeEmpty())
+		Expect(drifted.Reasons).To(ContainElement(v1.DisruptionReasonDrifted))
+		Expect(drifted.Reasons).ToNot(ContainElement(v1.DisruptionReasonUnderutilized))
+		Expect(drifted.BlockedReasons).To(ConsistOf("Underutilized"))
+	})
+	It("should report candidates with the disruption class of each method", func() {
+		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: mostExpensiveInstance.Name,
+					v1.CapacityTypeLabelKey:        mostExpensiveOffering.Requirements.Get(v1.CapacityTypeLabelKey).Any(),
+					corev1.LabelTopologyZone:       mostExpensiveOffering.Requirements.Get(corev1.LabelTopologyZone).Any(),
+				},
+			},
+			Spec: v1.NodeClaimSpec{
+				TerminationGracePeriod: &metav1.Duration{Duration: time.Hour},
+			},
+		})
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					v1.DoNotDisruptAnnotationKey: "true",
+				},
+			},
+		})
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		reports, err := disruptionController.Report(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(HaveLen(1))
+		Expect(reports[0].Candidates).To(HaveLen(1))
+		// Drift is eventual, so the TerminationGracePeriod lets it disrupt the node despite the do-not-disrupt pod
+		Expect(reports[0].Candidates[0].Blocked).To(BeEmpty())
+		Expect(reports[0].Candidates[0].Reasons).To(ConsistOf(v1.DisruptionReasonDrifted))
+	})
+})
+
 var _ = Describe("Metrics", func() {
 	var nodePool *v1.NodePool
 	var labels = map[string]string{
@@ -0,0 +1,137 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/karpenter/pkg/metrics"
+)
+
+// Reasons that a candidate was skipped while a Method computed its commands
+const (
+	// skipReasonBudget is used for candidates that would have violated the disruption budgets of their NodePool
+	skipReasonBudget = "budget"
+	// skipReasonPDB is used for nodes with pods whose eviction is blocked, e.g. by a PDB or the do-not-disrupt
+	// annotation. These nodes are never candidates, so they're counted before the Method filters candidates.
+	skipReasonPDB = "pdb"
+	// skipReasonSimulation is used for candidates whose pods couldn't be rescheduled in the scheduling simulation
+	skipReasonSimulation = "simulation"
+	// skipReasonValidation is used for candidates of commands that were no longer valid after the validation period
+	skipReasonValidation = "validation"
+	// skipReasonMaintenanceWindow is used for candidates that are selected by MaintenanceWindows, none of which are open
+	skipReasonMaintenanceWindow = "maintenance_window"
+	// skipReasonRateLimit is used for candidates that would have exceeded the cluster-wide disruption rate limit
+	skipReasonRateLimit = "rate_limit"
+)
+
+type evaluationSummaryKey struct{}
+
+// blockedKey identifies the nodes of a NodePool that were blocked from disruption for the same reason
+type blockedKey struct {
+	nodePool string
+	reason   string
+}
+
+// evaluationSummary aggregates the outcome of evaluating a Method in a single disruption loop, so that it can be
+// reported once at the end of the loop rather than through a log line for each candidate
+type evaluationSummary struct {
+	mu              sync.Mutex
+	evaluated       int
+	skipped         map[string]int
+	blocked         map[blockedKey]int
+	commandsSkipped int
+	enqueued        int
+}
+
+func newEvaluationSummary() *evaluationSummary {
+	return &evaluationSummary{skipped: map[string]int{}, blocked: map[blockedKey]int{}}
+}
+
+func withEvaluationSummary(ctx context.Context, summary *evaluationSummary) context.Context {
+	return context.WithValue(ctx, evaluationSummaryKey{}, summary)
+}
+
+// recordSkipped records candidates that were skipped by the Method being evaluated. It's a no-op if commands are
+// computed outside of the disruption loop.
+func recordSkipped(ctx context.Context, reason string, count int) {
+	summary, ok := ctx.Value(evaluationSummaryKey{}).(*evaluationSummary)
+	if !ok || count <= 0 {
+		return
+	}
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	summary.skipped[reason] += count
+}
+
+// recordBlocked records nodes of a NodePool that were blocked from disruption by the Method being evaluated, labeled by a
+// machine-readable reason. It's a no-op if commands are computed outside of the disruption loop.
+func recordBlocked(ctx context.Context, nodePool string, reason string, count int) {
+	summary, ok := ctx.Value(evaluationSummaryKey{}).(*evaluationSummary)
+	if !ok || count <= 0 {
+		return
+	}
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	summary.blocked[blockedKey{nodePool: nodePool, reason: reason}] += count
+}
+
+// recordBudgetSkipped records candidates of a NodePool that were skipped because they would have violated its
+// disruption budgets
+func recordBudgetSkipped(ctx context.Context, nodePool string, count int) {
+	recordSkipped(ctx, skipReasonBudget, count)
+	recordBlocked(ctx, nodePool, blockedReasonBudgetExhausted, count)
+}
+
+// observe records the skipped candidates of the Method in the CandidatesSkippedTotal metric and replaces the nodes that
+// the Method reports in the BlockedCandidates metric
+func (s *evaluationSummary) observe(m Method) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for reason, count := range s.skipped {
+		CandidatesSkippedTotal.Add(float64(count), map[string]string{
+			metrics.ReasonLabel:    strings.ToLower(string(m.Reason())),
+			ConsolidationTypeLabel: m.ConsolidationType(),
+			skipReasonLabel:        reason,
+		})
+	}
+	// Nodes that are no longer blocked are removed, since the gauge only reports the latest evaluation of the Method
+	BlockedCandidates.DeletePartialMatch(map[string]string{metrics.ReasonLabel: strings.ToLower(string(m.Reason()))})
+	for key, count := range s.blocked {
+		BlockedCandidates.Set(float64(count), map[string]string{
+			metrics.NodePoolLabel: key.nodePool,
+			metrics.ReasonLabel:   strings.ToLower(string(m.Reason())),
+			blockedReasonLabel:    key.reason,
+		})
+	}
+}
+
+func (s *evaluationSummary) LogValues() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]int{
+		"candidates":        s.evaluated,
+		"skippedBudget":     s.skipped[skipReasonBudget],
+		"skippedPDB":        s.skipped[skipReasonPDB],
+		"skippedSimulation": s.skipped[skipReasonSimulation],
+		"skippedValidation": s.skipped[skipReasonValidation],
+		"commandsSkipped":   s.commandsSkipped,
+		"commandsEnqueued":  s.enqueued,
+	}
+}
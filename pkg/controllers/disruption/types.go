@@ -19,6 +19,8 @@ package disruption
 import (
 	"context"
 	"fmt"
+	"maps"
+	"strings"
 	"time"
 
 	"github.com/awslabs/operatorpkg/option"
@@ -26,6 +28,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +37,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/apis/v1alpha1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	disruptionevents "sigs.k8s.io/karpenter/pkg/controllers/disruption/events"
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
@@ -41,6 +46,7 @@ import (
 	disruptionutils "sigs.k8s.io/karpenter/pkg/utils/disruption"
 	"sigs.k8s.io/karpenter/pkg/utils/pdb"
 	"sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 const (
@@ -60,24 +66,120 @@ func WithValidator(v Validator) option.Function[MethodOptions] {
 
 type Method interface {
 	ShouldDisrupt(context.Context, *Candidate) bool
-	ComputeCommands(context.Context, map[string]int, ...*Candidate) ([]Command, error)
+	ComputeCommands(context.Context, DisruptionBudgetMapping, ...*Candidate) ([]Command, error)
 	Reason() v1.DisruptionReason
 	Class() string
 	ConsolidationType() string
 }
 
+// DisruptionBudget is the remaining disruption budget of a NodePool
+type DisruptionBudget struct {
+	// Nodes is the number of nodes that can still be disrupted
+	Nodes int
+	// Pods is the number of reschedulable pods that can still be evicted
+	Pods int
+	// Resources is the capacity that can still be disrupted for each budgeted resource
+	Resources corev1.ResourceList
+	// DriftReasons is the number of nodes that drifted for each budgeted drift reason that can still be disrupted
+	DriftReasons map[string]int
+	// Regions is the number of nodes in each budgeted region that can still be disrupted
+	Regions map[string]int
+	// Zones is the number of nodes in each budgeted zone that can still be disrupted
+	Zones map[string]int
+	// Idle is true if none of the NodePool's nodes are being disrupted
+	Idle bool
+}
+
+// DisruptionBudgetMapping maps NodePool names to their remaining disruption budgets
+type DisruptionBudgetMapping map[string]DisruptionBudget
+
+// Allows returns true if the remaining budget of the candidate's NodePool allows the candidate to be disrupted
+func (m DisruptionBudgetMapping) Allows(c *Candidate) bool {
+	budget := m[c.NodePool.Name]
+	if remaining, ok := budget.DriftReasons[c.driftReason()]; ok && remaining <= 0 {
+		return false
+	}
+	if remaining, ok := budget.Regions[c.region]; ok && remaining <= 0 {
+		return false
+	}
+	if remaining, ok := budget.Zones[c.zone]; ok && remaining <= 0 {
+		return false
+	}
+	// An idle NodePool lets a single candidate exceed its pod and resource budgets, unless they're exhausted, so that
+	// nodes with more pods or capacity than the budgets allow aren't blocked from ever being disrupted
+	if budget.Resources != nil && !resources.Fits(lo.PickByKeys(c.Capacity(), lo.Keys(budget.Resources)), budget.Resources) &&
+		!(budget.Idle && lo.EveryBy(lo.Values(budget.Resources), func(q resource.Quantity) bool { return q.Sign() > 0 })) {
+		return false
+	}
+	if budget.Pods < len(c.reschedulablePods) && !(budget.Idle && budget.Pods > 0) {
+		return false
+	}
+	return budget.Nodes > 0
+}
+
+// Consume subtracts the candidate from the remaining budget of its NodePool
+func (m DisruptionBudgetMapping) Consume(c *Candidate) {
+	budget := m[c.NodePool.Name]
+	budget.Nodes--
+	budget.Pods -= len(c.reschedulablePods)
+	budget.Idle = false
+	if _, ok := budget.DriftReasons[c.driftReason()]; ok {
+		// The mapping may be a shallow copy, so don't mutate the drift reasons it shares
+		budget.DriftReasons = maps.Clone(budget.DriftReasons)
+		budget.DriftReasons[c.driftReason()]--
+	}
+	if _, ok := budget.Regions[c.region]; ok {
+		budget.Regions = maps.Clone(budget.Regions)
+		budget.Regions[c.region]--
+	}
+	if _, ok := budget.Zones[c.zone]; ok {
+		budget.Zones = maps.Clone(budget.Zones)
+		budget.Zones[c.zone]--
+	}
+	if budget.Resources != nil {
+		budget.Resources = resources.Subtract(budget.Resources, c.Capacity())
+	}
+	m[c.NodePool.Name] = budget
+}
+
 type CandidateFilter func(context.Context, *Candidate) bool
 
+// allowsReason extends the filter to exclude candidates whose node or pods block the disruption reason with the
+// karpenter.sh/do-not-disrupt-reasons annotation
+func allowsReason(reason v1.DisruptionReason, filter CandidateFilter) CandidateFilter {
+	return func(ctx context.Context, c *Candidate) bool {
+		return !c.doNotDisruptReasons.Has(string(reason)) && filter(ctx, c)
+	}
+}
+
 // Candidate is a state.StateNode that we are considering for disruption along with extra information to be used in
 // making that determination
 type Candidate struct {
 	*state.StateNode
 	instanceType      *cloudprovider.InstanceType
 	NodePool          *v1.NodePool
+	region            string
 	zone              string
 	capacityType      string
 	DisruptionCost    float64
+	LocalStorage      resource.Quantity
 	reschedulablePods []*corev1.Pod
+	staticPods        []*corev1.Pod
+	// doNotDisruptReasons are the disruption reasons that the node or its pods block
+	doNotDisruptReasons sets.Set[string]
+	// disruptionClass is the class of the disruption that the candidate was built for
+	disruptionClass string
+}
+
+// driftReason returns the reason of the candidate's Drifted status condition, or "" if the candidate hasn't drifted
+func (c *Candidate) driftReason() string {
+	if c.NodeClaim == nil {
+		return ""
+	}
+	if cond := c.NodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted); cond.IsTrue() {
+		return cond.Reason
+	}
+	return ""
 }
 
 func (c *Candidate) OwnedByStaticNodePool() bool {
@@ -93,7 +195,11 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 	if queue.HasAny(node.ProviderID()) {
 		return nil, fmt.Errorf("candidate is already being disrupted")
 	}
-	if err = node.ValidateNodeDisruptable(); err != nil {
+	// Candidates whose disruption commands failed repeatedly aren't disrupted until they're released from the dead-letter list
+	if queue.DeadLetters.IsDeadLettered(ctx, node.NodeClaim) {
+		return nil, state.NewBlockedError(blockedReasonDeadLettered, fmt.Errorf("candidate is dead-lettered after repeated disruption command failures"))
+	}
+	if err = node.ValidateNodeDisruptable(ctx, clk); err != nil {
 		// Only emit an event if the NodeClaim is not nil, ensuring that we only emit events for Karpenter-managed nodes
 		if node.NodeClaim != nil {
 			recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, pretty.Sentence(err.Error()))...)
@@ -107,32 +213,83 @@ func NewCandidate(ctx context.Context, kubeClient client.Client, recorder events
 	// skip any candidates where we can't determine the nodePool
 	if nodePool == nil || instanceTypeMap == nil {
 		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf("NodePool not found (NodePool=%s)", nodePoolName))...)
-		return nil, serrors.Wrap(fmt.Errorf("nodepool not found"), "NodePool", klog.KRef("", nodePoolName))
+		return nil, state.NewBlockedError(blockedReasonNodePoolNotFound, serrors.Wrap(fmt.Errorf("nodepool not found"), "NodePool", klog.KRef("", nodePoolName)))
+	}
+	// skip any candidates whose NodePool has paused disruption
+	if nodePool.Spec.Disruption.Paused {
+		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf("Disruption is paused (NodePool=%s)", nodePoolName))...)
+		return nil, state.NewBlockedError(blockedReasonPaused, serrors.Wrap(fmt.Errorf("disruption is paused"), "NodePool", klog.KRef("", nodePoolName)))
+	}
+	// skip any candidates that their NodePool excludes from disruption
+	if nodePool.Spec.Disruption.ExcludesNode(node.Labels()) {
+		recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, fmt.Sprintf("Node is excluded from disruption (NodePool=%s)", nodePoolName))...)
+		return nil, state.NewBlockedError(blockedReasonExcluded, serrors.Wrap(fmt.Errorf("node is excluded from disruption"), "NodePool", klog.KRef("", nodePoolName)))
 	}
 	// We only care if instanceType in non-empty consolidation to do price-comparison.
 	instanceType := instanceTypeMap[node.Labels()[corev1.LabelInstanceTypeStable]]
-	if pods, err = node.ValidatePodsDisruptable(ctx, kubeClient, pdbs); err != nil {
+	if pods, err = node.ValidatePodsDisruptable(ctx, kubeClient, clk, candidatePDBs(pdbs, nodePool, disruptionClass)); err != nil {
 		// If the NodeClaim has a TerminationGracePeriod set and the disruption class is eventual, the node should be
 		// considered a candidate even if there's a pod that will block eviction. Other error types should still cause
-		// failure creating the candidate.
-		eventualDisruptionCandidate := node.NodeClaim.Spec.TerminationGracePeriod != nil && disruptionClass == EventualDisruptionClass
+		// failure creating the candidate. The TerminationGracePeriod is resolved for the Drifted reason, and unhealthy
+		// NodeClaims are forcefully drained by node repair regardless of their TerminationGracePeriod.
+		eventualDisruptionCandidate := disruptionClass == EventualDisruptionClass &&
+			(node.NodeClaim.TerminationGracePeriodFor(string(v1.DisruptionReasonDrifted)) != nil || node.NodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy).IsTrue())
 		if lo.Ternary(eventualDisruptionCandidate, state.IgnorePodBlockEvictionError(err), err) != nil {
 			recorder.Publish(disruptionevents.Blocked(node.Node, node.NodeClaim, pretty.Sentence(err.Error()))...)
 			return nil, err
 		}
 	}
+	reschedulablePods := lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return pod.IsReschedulable(p) })
+	// Static pods are never evicted or rescheduled, they terminate along with the node
+	staticPods, otherPods := lo.FilterReject(pods, func(p *corev1.Pod, _ int) bool { return pod.IsStatic(p) })
 	return &Candidate{
 		StateNode:         node,
 		instanceType:      instanceType,
 		NodePool:          nodePool,
 		capacityType:      node.Labels()[v1.CapacityTypeLabelKey],
+		region:            node.Labels()[corev1.LabelTopologyRegion],
 		zone:              node.Labels()[corev1.LabelTopologyZone],
-		reschedulablePods: lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return pod.IsReschedulable(p) }),
-		// We get the disruption cost from all pods in the candidate, not just the reschedulable pods
-		DisruptionCost: disruptionutils.ReschedulingCost(ctx, pods) * disruptionutils.LifetimeRemaining(clk, nodePool, node.NodeClaim),
+		reschedulablePods: reschedulablePods,
+		staticPods:        staticPods,
+		// We get the disruption cost from all pods in the candidate that can be moved, not just the reschedulable pods
+		DisruptionCost:      disruptionutils.ReschedulingCost(ctx, otherPods) * disruptionutils.LifetimeRemaining(clk, nodePool, node.NodeClaim),
+		LocalStorage:        disruptionutils.LocalStorage(reschedulablePods),
+		doNotDisruptReasons: doNotDisruptReasons(node, pods),
+		disruptionClass:     disruptionClass,
 	}, nil
 }
 
+// candidatePDBs returns the PDBs that limit the disruption of a candidate. Eventual disruption doesn't wait for the PDBs
+// that the NodePool of the candidate ignores, since their pods are deleted rather than evicted.
+func candidatePDBs(pdbs pdb.Limits, nodePool *v1.NodePool, disruptionClass string) pdb.Limits {
+	if disruptionClass != EventualDisruptionClass || len(nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets) == 0 {
+		return pdbs
+	}
+	return pdbs.Ignoring(nodePool.Spec.Disruption.IgnoresPodDisruptionBudget)
+}
+
+// doNotDisruptReasons returns the disruption reasons that are blocked by the karpenter.sh/do-not-disrupt-reasons
+// annotation of the node or of its active pods. The annotation is a comma-separated list of disruption reasons,
+// e.g. "Drifted,Underutilized".
+func doNotDisruptReasons(node *state.StateNode, pods []*corev1.Pod) sets.Set[string] {
+	values := []string{node.Annotations()[v1.DoNotDisruptReasonsAnnotationKey]}
+	for _, p := range pods {
+		// Like karpenter.sh/do-not-disrupt, only pods that are actively running block disruption
+		if pod.IsActive(p) {
+			values = append(values, p.Annotations[v1.DoNotDisruptReasonsAnnotationKey])
+		}
+	}
+	reasons := sets.New[string]()
+	for _, value := range values {
+		for _, reason := range strings.Split(value, ",") {
+			if reason = strings.TrimSpace(reason); reason != "" {
+				reasons.Insert(reason)
+			}
+		}
+	}
+	return reasons
+}
+
 type Replacement struct {
 	*scheduling.NodeClaim
 
@@ -158,6 +315,14 @@ type Command struct {
 	Results      scheduling.Results
 	Candidates   []*Candidate
 	Replacements []*Replacement
+	// Reboot reboots the candidates' instances in place instead of deleting them
+	Reboot bool
+
+	evictionPrecheck *v1alpha1.EvictionPrecheck
+	// rebootTimes are when the instances of a reboot command's candidates were rebooted, by NodeClaim name
+	rebootTimes map[string]time.Time
+	// phase is the last phase recorded on the command's DisruptionCommand
+	phase v1alpha1.DisruptionCommandPhase
 }
 
 type Decision string
@@ -166,10 +331,13 @@ var (
 	NoOpDecision    Decision = "no-op"
 	ReplaceDecision Decision = "replace"
 	DeleteDecision  Decision = "delete"
+	RebootDecision  Decision = "reboot"
 )
 
 func (c Command) Decision() Decision {
 	switch {
+	case len(c.Candidates) > 0 && c.Reboot:
+		return RebootDecision
 	case len(c.Candidates) > 0 && len(c.Replacements) > 0:
 		return ReplaceDecision
 	case len(c.Candidates) > 0 && len(c.Replacements) == 0:
@@ -181,6 +349,7 @@ func (c Command) Decision() Decision {
 
 func (c Command) LogValues() []any {
 	podCount := lo.Reduce(c.Candidates, func(_ int, cd *Candidate, _ int) int { return len(cd.reschedulablePods) }, 0)
+	staticPodCount := lo.SumBy(c.Candidates, func(cd *Candidate) int { return len(cd.staticPods) })
 
 	candidateNodes := lo.Map(c.Candidates, func(candidate *Candidate, _ int) interface{} {
 		return map[string]interface{}{
@@ -210,6 +379,7 @@ func (c Command) LogValues() []any {
 		"disrupted-node-count", len(candidateNodes),
 		"replacement-node-count", len(replacementNodes),
 		"pod-count", podCount,
+		"static-pod-count", staticPodCount,
 		"disrupted-nodes", candidateNodes,
 		"replacement-nodes", replacementNodes,
 	}
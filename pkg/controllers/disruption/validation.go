@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/samber/lo"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -31,6 +32,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
 
 type ValidationError struct {
@@ -53,6 +55,9 @@ type Validator interface {
 	Validate(context.Context, Command, time.Duration) (Command, error)
 }
 
+// maxParallelValidations bounds the number of candidates that are checked concurrently during validation
+const maxParallelValidations = 10
+
 // Validation is used to perform validation on a consolidation command.  It makes an assumption that when re-used, all
 // of the commands passed to IsValid were constructed based off of the same consolidation state.  This allows it to
 // skip the validation TTL for all but the first command.
@@ -86,12 +91,13 @@ func NewEmptinessValidator(c consolidation) *EmptinessValidator {
 			queue:         c.queue,
 			reason:        v1.DisruptionReasonEmpty,
 		},
-		filter:         e.ShouldDisrupt,
+		filter:         allowsReason(v1.DisruptionReasonEmpty, e.ShouldDisrupt),
 		validationType: e.ConsolidationType(),
 	}
 }
 
 func (e *EmptinessValidator) Validate(ctx context.Context, cmd Command, validationPeriod time.Duration) (Command, error) {
+	ctx = withoutClusterSnapshot(ctx)
 	if validationPeriod > 0 {
 		select {
 		case <-ctx.Done():
@@ -126,7 +132,7 @@ func NewSingleConsolidationValidator(c consolidation) *ConsolidationValidator {
 			queue:         c.queue,
 			reason:        v1.DisruptionReasonUnderutilized,
 		},
-		filter:         s.ShouldDisrupt,
+		filter:         allowsReason(v1.DisruptionReasonUnderutilized, s.ShouldDisrupt),
 		validationType: s.ConsolidationType(),
 	}
 }
@@ -144,12 +150,13 @@ func NewMultiConsolidationValidator(c consolidation) *ConsolidationValidator {
 			queue:         c.queue,
 			reason:        v1.DisruptionReasonUnderutilized,
 		},
-		filter:         m.ShouldDisrupt,
+		filter:         allowsReason(v1.DisruptionReasonUnderutilized, m.ShouldDisrupt),
 		validationType: m.ConsolidationType(),
 	}
 }
 
 func (c *ConsolidationValidator) Validate(ctx context.Context, cmd Command, validationPeriod time.Duration) (Command, error) {
+	ctx = withoutClusterSnapshot(ctx)
 	if err := c.isValid(ctx, cmd, validationPeriod); err != nil {
 		return Command{}, err
 	}
@@ -180,36 +187,24 @@ func (c *ConsolidationValidator) isValid(ctx context.Context, cmd Command, valid
 }
 
 func (e *EmptinessValidator) validateCandidates(ctx context.Context, candidates ...*Candidate) ([]*Candidate, error) {
-	// This GetCandidates call filters out nodes that were nominated
-	validatedCandidates, err := GetCandidates(ctx, e.cluster, e.kubeClient, e.recorder, e.clock, e.cloudProvider, e.filter, GracefulDisruptionClass, e.queue)
+	validatedCandidates, reasons, err := e.checkCandidates(ctx, e.filter, candidates)
 	if err != nil {
-		return nil, fmt.Errorf("constructing validation candidates, %w", err)
+		return nil, err
 	}
-	validatedCandidates = mapCandidates(candidates, validatedCandidates)
 	if len(validatedCandidates) == 0 {
 		FailedValidationsTotal.Add(float64(len(candidates)), map[string]string{ConsolidationTypeLabel: e.validationType})
 		return nil, NewValidationError(fmt.Errorf("%d candidates are no longer valid", len(candidates)))
 	}
-	disruptionBudgetMapping, err := BuildDisruptionBudgetMapping(ctx, e.cluster, e.clock, e.kubeClient, e.cloudProvider, e.recorder, e.reason)
-	if err != nil {
-		return nil, fmt.Errorf("building disruption budgets, %w", err)
+	// Unlike consolidation, the candidates of an emptiness command don't depend on each other, so the ones that are still
+	// valid are kept
+	valid := lo.Filter(validatedCandidates, func(_ *Candidate, i int) bool { return reasons[i] == nil })
+	if failed := len(validatedCandidates) - len(valid); failed > 0 {
+		FailedValidationsTotal.Add(float64(failed), map[string]string{ConsolidationTypeLabel: e.validationType})
 	}
-
-	if valid := lo.Filter(validatedCandidates, func(cn *Candidate, _ int) bool {
-		if e.cluster.IsNodeNominated(cn.ProviderID()) {
-			FailedValidationsTotal.Inc(map[string]string{ConsolidationTypeLabel: e.validationType})
-			return false
-		}
-		if disruptionBudgetMapping[cn.NodePool.Name] == 0 {
-			FailedValidationsTotal.Inc(map[string]string{ConsolidationTypeLabel: e.validationType})
-			return false
-		}
-		disruptionBudgetMapping[cn.NodePool.Name]--
-		return true
-	}); len(valid) > 0 {
+	if len(valid) > 0 {
 		return valid, nil
 	}
-	return nil, NewValidationError(fmt.Errorf("%d candidates failed validation because it they were nominated for a pod or would violate disruption budgets", len(candidates)))
+	return nil, NewValidationError(fmt.Errorf("%d candidates failed validation because it they were nominated for a pod, would violate disruption budgets or have pods that would be denied eviction", len(candidates)))
 }
 
 // ValidateCandidates gets the current representation of the provided candidates and ensures that they are all still valid.
@@ -218,39 +213,67 @@ func (e *EmptinessValidator) validateCandidates(ctx context.Context, candidates
 //	a. It must pass the global candidate filtering logic (no blocking PDBs, no do-not-disrupt annotation, etc)
 //	b. It must not have any pods nominated for it
 //	c. It must still be disruptable without violating node disruption budgets
+//	d. The apiserver must admit the eviction of its pods, if the eviction precheck is enabled
 //
 // If these conditions are met for all candidates, ValidateCandidates returns a slice with the updated representations.
 func (c *ConsolidationValidator) validateCandidates(ctx context.Context, candidates ...*Candidate) ([]*Candidate, error) {
-	// GracefulDisruptionClass is hardcoded here because ValidateCandidates is only used for consolidation disruption. All consolidation disruption is graceful disruption.
-	validatedCandidates, err := GetCandidates(ctx, c.cluster, c.kubeClient, c.recorder, c.clock, c.cloudProvider, c.filter, GracefulDisruptionClass, c.queue)
+	validatedCandidates, reasons, err := c.checkCandidates(ctx, c.filter, candidates)
 	if err != nil {
-		return nil, fmt.Errorf("constructing validation candidates, %w", err)
+		return nil, err
 	}
-	validatedCandidates = mapCandidates(candidates, validatedCandidates)
 	// If we filtered out any candidates, return nil as some NodeClaims in the consolidation decision have changed.
 	if len(validatedCandidates) != len(candidates) {
 		FailedValidationsTotal.Add(float64(len(candidates)), map[string]string{ConsolidationTypeLabel: c.validationType})
 		return nil, NewValidationError(fmt.Errorf("%d candidates are no longer valid", len(candidates)-len(validatedCandidates)))
 	}
-	disruptionBudgetMapping, err := BuildDisruptionBudgetMapping(ctx, c.cluster, c.clock, c.kubeClient, c.cloudProvider, c.recorder, c.reason)
+	// Return nil if any candidate can no longer be disrupted
+	if reason, ok := lo.Find(reasons, func(err error) bool { return err != nil }); ok {
+		FailedValidationsTotal.Add(float64(len(candidates)), map[string]string{ConsolidationTypeLabel: c.validationType})
+		return nil, NewValidationError(reason)
+	}
+	return validatedCandidates, nil
+}
+
+// checkCandidates gets the current representation of the candidates and checks whether each of them can still be
+// disrupted, returning the current candidates along with the reason each of them can't be, or nil if it can. The
+// candidates and disruption budgets are built once for the whole command, and the candidates are checked concurrently
+// since the eviction precheck makes a request for each of their pods. The budgets are consumed in the candidates' order
+// once they're checked, so that the candidates of a NodePool share its budget.
+func (v *validation) checkCandidates(ctx context.Context, filter CandidateFilter, candidates []*Candidate) ([]*Candidate, []error, error) {
+	// GracefulDisruptionClass is hardcoded here because validation is only used for consolidation disruption. All consolidation disruption is graceful disruption.
+	validatedCandidates, err := GetCandidates(ctx, v.cluster, v.kubeClient, v.recorder, v.clock, v.cloudProvider, filter, GracefulDisruptionClass, v.queue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("constructing validation candidates, %w", err)
+	}
+	validatedCandidates = mapCandidates(candidates, validatedCandidates)
+	if len(validatedCandidates) == 0 {
+		return nil, nil, nil
+	}
+	disruptionBudgetMapping, err := BuildDisruptionBudgetMapping(ctx, v.cluster, v.clock, v.kubeClient, v.cloudProvider, v.recorder, v.reason)
 	if err != nil {
-		return nil, fmt.Errorf("building disruption budgets, %w", err)
+		return nil, nil, fmt.Errorf("building disruption budgets, %w", err)
 	}
-	// Return nil if any candidate meets either of the following conditions:
-	//  a. A pod was nominated to the candidate
-	//  b. Disrupting the candidate would violate node disruption budgets
-	for _, vc := range validatedCandidates {
-		if c.cluster.IsNodeNominated(vc.ProviderID()) {
-			FailedValidationsTotal.Add(float64(len(candidates)), map[string]string{ConsolidationTypeLabel: c.validationType})
-			return nil, NewValidationError(fmt.Errorf("a candidate was nominated during validation"))
+	reasons := make([]error, len(validatedCandidates))
+	workqueue.ParallelizeUntil(ctx, maxParallelValidations, len(validatedCandidates), func(i int) {
+		if v.cluster.IsNodeNominated(validatedCandidates[i].ProviderID()) {
+			reasons[i] = fmt.Errorf("a candidate was nominated during validation")
+			return
+		}
+		if options.FromContext(ctx).EvictionPrecheck && len(deniedEvictions(ctx, v.kubeClient, v.clock, validatedCandidates[i])) > 0 {
+			reasons[i] = fmt.Errorf("a candidate has pods that would be denied eviction")
 		}
-		if disruptionBudgetMapping[vc.NodePool.Name] == 0 {
-			FailedValidationsTotal.Add(float64(len(candidates)), map[string]string{ConsolidationTypeLabel: c.validationType})
-			return nil, NewValidationError(fmt.Errorf("a candidate can no longer be disrupted without violating budgets"))
+	})
+	for i, vc := range validatedCandidates {
+		if reasons[i] != nil {
+			continue
 		}
-		disruptionBudgetMapping[vc.NodePool.Name]--
+		if !disruptionBudgetMapping.Allows(vc) {
+			reasons[i] = fmt.Errorf("a candidate can no longer be disrupted without violating budgets")
+			continue
+		}
+		disruptionBudgetMapping.Consume(vc)
 	}
-	return validatedCandidates, nil
+	return validatedCandidates, reasons, nil
 }
 
 // ValidateCommand validates a command for a Method
@@ -259,7 +282,7 @@ func (v *validation) validateCommand(ctx context.Context, cmd Command, candidate
 	if len(candidates) == 0 {
 		return NewValidationError(fmt.Errorf("no candidates"))
 	}
-	results, err := SimulateScheduling(ctx, v.kubeClient, v.cluster, v.provisioner, candidates...)
+	results, err := SimulateScheduling(ctx, v.kubeClient, v.cluster, v.provisioner, v.clock, candidates...)
 	if err != nil {
 		return fmt.Errorf("simluating scheduling, %w", err)
 	}
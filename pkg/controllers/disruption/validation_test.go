@@ -23,12 +23,15 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/controllers/disruption"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 )
@@ -51,7 +54,7 @@ func NewMethodsWithNopValidator() []disruption.Method {
 	return []disruption.Method{
 		emptiness,
 		disruption.NewStaticDrift(cluster, prov, cloudProvider),
-		disruption.NewDrift(env.Client, cluster, prov, recorder),
+		disruption.NewDrift(fakeClock, env.Client, cluster, prov, recorder),
 		multiNodeConsolidation,
 		singleNodeConsolidation,
 	}
@@ -59,6 +62,7 @@ func NewMethodsWithNopValidator() []disruption.Method {
 
 type TestEmptinessValidator struct {
 	blocked    bool
+	budget     string
 	churn      bool
 	nominated  bool
 	nodes      []*corev1.Node
@@ -81,6 +85,12 @@ func WithEmptinessBlockingBudget() TestEmptinessValidatorOption {
 	}
 }
 
+func WithEmptinessBudget(nodes string) TestEmptinessValidatorOption {
+	return func(v *TestEmptinessValidator) {
+		v.budget = nodes
+	}
+}
+
 func WithEmptinessNodeNomination() TestEmptinessValidatorOption {
 	return func(v *TestEmptinessValidator) {
 		v.nominated = true
@@ -104,6 +114,10 @@ func (t *TestEmptinessValidator) Validate(ctx context.Context, cmd disruption.Co
 	if t.blocked {
 		blockingBudget(t.nodes, t.nodeClaims, t.nodePool)
 	}
+	if t.budget != "" {
+		t.nodePool.Spec.Disruption.Budgets = []v1.Budget{{Nodes: t.budget}}
+		ExpectApplied(ctx, env.Client, t.nodePool)
+	}
 	if t.churn {
 		churn(t.nodes, t.nodeClaims)
 	}
@@ -117,6 +131,7 @@ type TestConsolidationValidator struct {
 	blocked       bool
 	churn         bool
 	nominated     bool
+	denied        bool
 	cluster       *state.Cluster
 	nodePool      *v1.NodePool
 	consolidation *disruption.ConsolidationValidator
@@ -142,6 +157,12 @@ func WithUnderutilizedNodeNomination() TestConsolidationValidatorOption {
 	}
 }
 
+func WithUnderutilizedDeniedEviction() TestConsolidationValidatorOption {
+	return func(v *TestConsolidationValidator) {
+		v.denied = true
+	}
+}
+
 func NewTestSingleConsolidationValidator(nodePool *v1.NodePool, opts ...TestConsolidationValidatorOption) disruption.Validator {
 	return newTestConsolidationValidator(nodePool, disruption.NewSingleConsolidationValidator(disruption.MakeConsolidation(fakeClock, cluster, env.Client, prov, cloudProvider, recorder, queue)), opts...)
 }
@@ -179,6 +200,10 @@ func (t *TestConsolidationValidator) Validate(ctx context.Context, cmd disruptio
 	if t.nominated {
 		nominated(nodes, nodeClaims)
 	}
+	if t.denied {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{EvictionPrecheck: lo.ToPtr(true)}))
+		deniedEviction(nodes, nodeClaims)
+	}
 	return t.consolidation.Validate(ctx, cmd, 0)
 }
 
@@ -220,6 +245,19 @@ func blockingBudget(nodes []*corev1.Node, nodeClaims []*v1.NodeClaim, nodePool *
 	ExpectApplied(ctx, env.Client, nodePool)
 }
 
+// deniedEviction applies a PDB that the static PDB checks allow, but whose evictions the apiserver denies since the
+// PDB's status hasn't been observed by the disruption controller
+func deniedEviction(nodes []*corev1.Node, nodeClaims []*v1.NodeClaim) {
+	ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
+	pdb := test.PodDisruptionBudget(test.PDBOptions{
+		Labels:         map[string]string{"app": "test"},
+		MaxUnavailable: fromInt(1),
+		Status:         &policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+	})
+	ExpectApplied(ctx, env.Client, pdb)
+	ExpectReconcileSucceeded(ctx, pdbStateController, client.ObjectKeyFromObject(pdb))
+}
+
 func nominated(nodes []*corev1.Node, nodeClaims []*v1.NodeClaim) {
 	ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, nodes, nodeClaims)
 	for i := range nodes {
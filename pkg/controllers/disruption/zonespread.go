@@ -0,0 +1,65 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disruption
+
+import (
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	pscheduling "sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+)
+
+// reducesZoneSpread returns true if removing the candidates and launching the replacements would reduce the number of
+// zones that a NodePool's nodes span below the NodePool's zone spread floor. Replacements that aren't constrained to a
+// single zone aren't counted towards the zones that the NodePool will span.
+func reducesZoneSpread(cluster *state.Cluster, candidates []*Candidate, replacements []*pscheduling.NodeClaim) bool {
+	for nodePoolName, nodePoolCandidates := range lo.GroupBy(candidates, func(c *Candidate) string { return c.NodePool.Name }) {
+		floor := nodePoolCandidates[0].NodePool.Spec.ZoneSpread
+		if floor == nil {
+			continue
+		}
+		removed := sets.New(lo.Map(nodePoolCandidates, func(c *Candidate, _ int) string { return c.ProviderID() })...)
+		nodes := 0
+		before, after := sets.New[string](), sets.New[string]()
+		for n := range cluster.Nodes() {
+			if n.Labels()[v1.NodePoolLabelKey] != nodePoolName || n.MarkedForDeletion() {
+				continue
+			}
+			nodes++
+			zone, ok := n.Labels()[corev1.LabelTopologyZone]
+			if !ok {
+				continue
+			}
+			before.Insert(zone)
+			if !removed.Has(n.ProviderID()) {
+				after.Insert(zone)
+			}
+		}
+		for _, r := range replacements {
+			if zones := r.Requirements.Get(corev1.LabelTopologyZone); r.NodePoolName == nodePoolName && zones.Operator() == corev1.NodeSelectorOpIn && zones.Len() == 1 {
+				after.Insert(zones.Any())
+			}
+		}
+		if nodes >= int(floor.MinNodes) && after.Len() < int(floor.MinZones) && after.Len() < before.Len() {
+			return true
+		}
+	}
+	return false
+}
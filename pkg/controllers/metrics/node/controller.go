@@ -38,13 +38,15 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 const (
-	resourceType = "resource_type"
-	nodeName     = "node_name"
-	nodePhase    = "phase"
+	resourceType  = "resource_type"
+	nodeName      = "node_name"
+	nodePhase     = "phase"
+	nodePoolLabel = "nodepool"
 )
 
 var (
@@ -56,6 +58,14 @@ var (
 	SystemOverhead      opmetrics.GaugeMetric
 	Lifetime            opmetrics.GaugeMetric
 	ClusterUtilization  opmetrics.GaugeMetric
+
+	// Aggregated alternatives to the per-node resource metrics, which are always emitted
+	NodePoolAllocatable         opmetrics.GaugeMetric
+	NodePoolTotalPodRequests    opmetrics.GaugeMetric
+	NodePoolTotalPodLimits      opmetrics.GaugeMetric
+	NodePoolTotalDaemonRequests opmetrics.GaugeMetric
+	NodePoolTotalDaemonLimits   opmetrics.GaugeMetric
+	NodePoolSystemOverhead      opmetrics.GaugeMetric
 )
 
 // Initialize metrics at runtime to ensure cloud provider's well-known labels are properly
@@ -141,6 +151,25 @@ func initializeMetrics() {
 		},
 		[]string{resourceType},
 	)
+	NodePoolAllocatable = newNodePoolGauge("node_allocatable", "Node allocatable summed across the nodes of a nodepool. Labeled by nodepool and resource type.")
+	NodePoolTotalPodRequests = newNodePoolGauge("node_total_pod_requests", "Node total pod requests summed across the nodes of a nodepool, including the DaemonSet pods. Labeled by nodepool and resource type.")
+	NodePoolTotalPodLimits = newNodePoolGauge("node_total_pod_limits", "Node total pod limits summed across the nodes of a nodepool, including the DaemonSet pods. Labeled by nodepool and resource type.")
+	NodePoolTotalDaemonRequests = newNodePoolGauge("node_total_daemon_requests", "Node total daemon requests summed across the nodes of a nodepool. Labeled by nodepool and resource type.")
+	NodePoolTotalDaemonLimits = newNodePoolGauge("node_total_daemon_limits", "Node total daemon limits summed across the nodes of a nodepool. Labeled by nodepool and resource type.")
+	NodePoolSystemOverhead = newNodePoolGauge("node_system_overhead", "Node system daemon overhead summed across the nodes of a nodepool. Labeled by nodepool and resource type.")
+}
+
+func newNodePoolGauge(name, help string) opmetrics.GaugeMetric {
+	return opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.NodePoolSubsystem,
+			Name:      name,
+			Help:      help,
+		},
+		[]string{nodePoolLabel, resourceType},
+	)
 }
 
 func nodeLabelNamesWithResourceType() []string {
@@ -180,12 +209,16 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 		return n.Node == nil
 	})
 
-	// Build per-node metrics
-	metricsMap := lo.SliceToMap(nodes, func(n *state.StateNode) (string, []*metrics.StoreMetric) {
-		return client.ObjectKeyFromObject(n.Node).String(), buildMetrics(n)
-	})
+	// Build per-node metrics. These have a series per node, so they're skipped when high-cardinality metrics are disabled.
+	metricsMap := map[string][]*metrics.StoreMetric{}
+	if options.FromContext(ctx).HighCardinalityMetrics {
+		metricsMap = lo.SliceToMap(nodes, func(n *state.StateNode) (string, []*metrics.StoreMetric) {
+			return client.ObjectKeyFromObject(n.Node).String(), buildMetrics(n)
+		})
+	}
 
-	// Build cluster level metric
+	// Build nodepool and cluster level metrics
+	metricsMap["nodePoolAggregates"] = buildNodePoolMetrics(nodes)
 	metricsMap["clusterUtilization"] = buildClusterUtilizationMetric(nodes)
 
 	c.metricStore.ReplaceAll(metricsMap)
@@ -237,15 +270,58 @@ func buildClusterUtilizationMetric(nodes state.StateNodes) []*metrics.StoreMetri
 	return res
 }
 
-func buildMetrics(n *state.StateNode) (res []*metrics.StoreMetric) {
-	for gaugeMetric, resourceList := range map[opmetrics.GaugeMetric]corev1.ResourceList{
+// buildNodePoolMetrics sums the per-node resource metrics across the nodes of each nodepool
+func buildNodePoolMetrics(nodes state.StateNodes) (res []*metrics.StoreMetric) {
+	aggregates := map[opmetrics.GaugeMetric]opmetrics.GaugeMetric{
+		SystemOverhead:      NodePoolSystemOverhead,
+		TotalPodRequests:    NodePoolTotalPodRequests,
+		TotalPodLimits:      NodePoolTotalPodLimits,
+		TotalDaemonRequests: NodePoolTotalDaemonRequests,
+		TotalDaemonLimits:   NodePoolTotalDaemonLimits,
+		Allocatable:         NodePoolAllocatable,
+	}
+	totals := map[opmetrics.GaugeMetric]map[string]corev1.ResourceList{} // map[metric] -> map[nodepool] -> summed resources
+	for _, n := range nodes {
+		nodePool, ok := n.Labels()[v1.NodePoolLabelKey]
+		if !ok {
+			continue
+		}
+		for gaugeMetric, resourceList := range nodeResources(n) {
+			aggregate := aggregates[gaugeMetric]
+			if _, ok := totals[aggregate]; !ok {
+				totals[aggregate] = map[string]corev1.ResourceList{}
+			}
+			totals[aggregate][nodePool] = resources.Merge(totals[aggregate][nodePool], resourceList)
+		}
+	}
+	for gaugeMetric, nodePools := range totals {
+		for nodePool, resourceList := range nodePools {
+			for resourceName, quantity := range resourceList {
+				res = append(res, &metrics.StoreMetric{
+					GaugeMetric: gaugeMetric,
+					Value:       lo.Ternary(resourceName == corev1.ResourceCPU, float64(quantity.MilliValue())/float64(1000), float64(quantity.Value())),
+					Labels:      map[string]string{nodePoolLabel: nodePool, resourceType: resourceNameToString(resourceName)},
+				})
+			}
+		}
+	}
+	return res
+}
+
+// nodeResources returns the resources reported by each of the per-node resource metrics
+func nodeResources(n *state.StateNode) map[opmetrics.GaugeMetric]corev1.ResourceList {
+	return map[opmetrics.GaugeMetric]corev1.ResourceList{
 		SystemOverhead:      resources.Subtract(n.Node.Status.Capacity, n.Node.Status.Allocatable),
 		TotalPodRequests:    n.PodRequests(),
 		TotalPodLimits:      n.PodLimits(),
 		TotalDaemonRequests: n.DaemonSetRequests(),
 		TotalDaemonLimits:   n.DaemonSetLimits(),
 		Allocatable:         n.Node.Status.Allocatable,
-	} {
+	}
+}
+
+func buildMetrics(n *state.StateNode) (res []*metrics.StoreMetric) {
+	for gaugeMetric, resourceList := range nodeResources(n) {
 		for resourceName, quantity := range resourceList {
 			res = append(res, &metrics.StoreMetric{
 				GaugeMetric: gaugeMetric,
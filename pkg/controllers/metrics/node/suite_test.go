@@ -23,12 +23,14 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	clock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/metrics/node"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
@@ -76,6 +78,7 @@ var _ = Describe("Node Metrics", func() {
 	var resources corev1.ResourceList
 
 	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options())
 		resources = corev1.ResourceList{
 			corev1.ResourcePods:   resource.MustParse("100"),
 			corev1.ResourceCPU:    resource.MustParse("5000"),
@@ -136,4 +139,25 @@ var _ = Describe("Node Metrics", func() {
 		})
 		Expect(found).To(BeFalse())
 	})
+	It("should only emit the nodepool metrics when high-cardinality metrics are disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{HighCardinalityMetrics: lo.ToPtr(false)}))
+		nodePool := test.NodePool()
+		node.Labels = lo.Assign(node.Labels, map[string]string{v1.NodePoolLabelKey: nodePool.Name})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectSingletonReconciled(ctx, metricsStateController)
+
+		_, found := FindMetricWithLabelValues("karpenter_nodes_allocatable", map[string]string{
+			"node_name": node.GetName(),
+		})
+		Expect(found).To(BeFalse())
+		for k, v := range resources {
+			metric, found := FindMetricWithLabelValues("karpenter_nodepools_node_allocatable", map[string]string{
+				"nodepool":      nodePool.Name,
+				"resource_type": k.String(),
+			})
+			Expect(found).To(BeTrue())
+			Expect(metric.GetGauge().GetValue()).To(BeNumerically("~", v.AsApproximateFloat64()))
+		}
+	})
 })
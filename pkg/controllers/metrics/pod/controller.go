@@ -54,6 +54,8 @@ const (
 	podHostInstanceType = "instance_type"
 	podPhase            = "phase"
 	podScheduled        = "scheduled"
+	podPriorityClass    = "priority_class"
+	startupStage        = "stage"
 )
 
 var (
@@ -156,6 +158,30 @@ var (
 		[]string{podName, podNamespace},
 	)
 	// Stage: alpha
+	PodEndToEndStartupDurationSeconds = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.PodSubsystem,
+			Name:      "end_to_end_startup_duration_seconds",
+			Help:      "The time from pod creation until the pod is running, for pods that were pending and run on a node that Karpenter manages. Labeled by the node's nodepool and the pod's priority class.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{podNodePool, podPriorityClass},
+	)
+	// Stage: alpha
+	PodStartupStageDurationSeconds = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.PodSubsystem,
+			Name:      "startup_stage_duration_seconds",
+			Help:      "The time spent in each stage between pod creation and the pod running, for pods that run on a nodeclaim that Karpenter launched for them. The stages are scheduling (until Karpenter first thought the pod could schedule), launch (until the nodeclaim launched), registration (until the node registered) and startup (until the pod is running). Labeled by the node's nodepool, the pod's priority class and the stage.",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{podNodePool, podPriorityClass, startupStage},
+	)
+	// Stage: alpha
 	PodSchedulingUndecidedTimeSeconds = opmetrics.NewPrometheusGauge(
 		crmetrics.Registry,
 		prometheus.GaugeOpts{
@@ -254,7 +280,9 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	c.recordPodSchedulingUndecidedMetric(pod)
 	// Get the time for when we Karpenter first thought the pod was schedulable. This should be zero if we didn't simulate for this pod.
 	schedulableTime := c.cluster.PodSchedulingSuccessTime(types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace})
-	c.recordPodStartupMetric(pod, schedulableTime)
+	if err = c.recordPodStartupMetric(ctx, pod, schedulableTime); err != nil {
+		return reconcile.Result{}, err
+	}
 	c.recordPodBoundMetric(pod, schedulableTime)
 	// Requeue every 30s for pods that are stuck without a state change
 	return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
@@ -283,7 +311,7 @@ func (c *Controller) recordPodSchedulingUndecidedMetric(pod *corev1.Pod) {
 	}
 }
 
-func (c *Controller) recordPodStartupMetric(pod *corev1.Pod, schedulableTime time.Time) {
+func (c *Controller) recordPodStartupMetric(ctx context.Context, pod *corev1.Pod, schedulableTime time.Time) error {
 	key := client.ObjectKeyFromObject(pod).String()
 	if pod.Status.Phase == corev1.PodPending {
 		PodUnstartedTimeSeconds.Set(time.Since(pod.CreationTimestamp.Time).Seconds(), map[string]string{
@@ -303,13 +331,16 @@ func (c *Controller) recordPodStartupMetric(pod *corev1.Pod, schedulableTime tim
 			})
 		}
 		c.pendingPods.Insert(key)
-		return
+		return nil
 	}
 	cond, ready := lo.Find(pod.Status.Conditions, func(c corev1.PodCondition) bool {
 		return c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue
 	})
 	if c.pendingPods.Has(key) {
 		if ready {
+			if err := c.recordEndToEndStartupMetrics(ctx, pod, cond.LastTransitionTime.Time, schedulableTime); err != nil {
+				return err
+			}
 			// Delete the unstarted metric since the pod is now started
 			PodUnstartedTimeSeconds.Delete(map[string]string{
 				podName:      pod.Name,
@@ -361,6 +392,7 @@ func (c *Controller) recordPodStartupMetric(pod *corev1.Pod, schedulableTime tim
 			}
 		}
 	}
+	return nil
 }
 func (c *Controller) recordPodBoundMetric(pod *corev1.Pod, schedulableTime time.Time) {
 	key := client.ObjectKeyFromObject(pod).String()
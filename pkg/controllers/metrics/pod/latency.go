@@ -0,0 +1,88 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+)
+
+// Stages of a pod's startup that are reported by PodStartupStageDurationSeconds
+const (
+	startupStageScheduling   = "scheduling"
+	startupStageLaunch       = "launch"
+	startupStageRegistration = "registration"
+	startupStageStartup      = "startup"
+)
+
+// recordEndToEndStartupMetrics correlates the milestones between a pod's creation and the pod running: when Karpenter
+// first thought the pod could schedule, when the NodeClaim the pod runs on launched and registered, and when the pod
+// became ready. This must be called before the pod's scheduling mappings are cleared from cluster state.
+func (c *Controller) recordEndToEndStartupMetrics(ctx context.Context, pod *corev1.Pod, readyTime, schedulableTime time.Time) error {
+	if pod.Spec.NodeName == "" {
+		return nil
+	}
+	node := &corev1.Node{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	// Only track pods that run on nodes that Karpenter manages
+	nodePool, ok := node.Labels[v1.NodePoolLabelKey]
+	if !ok {
+		return nil
+	}
+	labels := map[string]string{
+		podNodePool:      nodePool,
+		podPriorityClass: pod.Spec.PriorityClassName,
+	}
+	PodEndToEndStartupDurationSeconds.Observe(readyTime.Sub(pod.CreationTimestamp.Time).Seconds(), labels)
+
+	// Only break the startup down into stages if Karpenter scheduled the pod and launched its NodeClaim afterwards
+	if schedulableTime.IsZero() || node.Spec.ProviderID == "" {
+		return nil
+	}
+	nodeClaims := &v1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaims, nodeclaimutils.ForProviderID(node.Spec.ProviderID)); err != nil {
+		return fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	if len(nodeClaims.Items) != 1 {
+		return nil
+	}
+	launched := nodeClaims.Items[0].StatusConditions().Get(v1.ConditionTypeLaunched)
+	registered := nodeClaims.Items[0].StatusConditions().Get(v1.ConditionTypeRegistered)
+	if !launched.IsTrue() || !registered.IsTrue() || launched.LastTransitionTime.Time.Before(schedulableTime) {
+		return nil
+	}
+	for stage, duration := range map[string]time.Duration{
+		startupStageScheduling:   schedulableTime.Sub(pod.CreationTimestamp.Time),
+		startupStageLaunch:       launched.LastTransitionTime.Sub(schedulableTime),
+		startupStageRegistration: registered.LastTransitionTime.Sub(launched.LastTransitionTime.Time),
+		startupStageStartup:      readyTime.Sub(registered.LastTransitionTime.Time),
+	} {
+		PodStartupStageDurationSeconds.Observe(duration.Seconds(), lo.Assign(labels, map[string]string{startupStage: stage}))
+	}
+	return nil
+}
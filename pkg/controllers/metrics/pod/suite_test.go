@@ -30,11 +30,14 @@ import (
 	clock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/metrics/pod"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -52,7 +55,7 @@ func TestAPIs(t *testing.T) {
 }
 
 var _ = BeforeSuite(func() {
-	env = test.NewEnvironment()
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
 	fakeClock = clock.NewFakeClock(time.Now())
 	cloudProvider = fake.NewCloudProvider()
 	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
@@ -63,6 +66,8 @@ var _ = AfterEach(func() {
 	cluster.Reset()
 	pod.PodStartupDurationSeconds.Reset()
 	pod.PodProvisioningStartupDurationSeconds.Reset()
+	pod.PodEndToEndStartupDurationSeconds.Reset()
+	pod.PodStartupStageDurationSeconds.Reset()
 })
 
 var _ = AfterSuite(func() {
@@ -250,6 +255,59 @@ var _ = Describe("Pod Metrics", func() {
 		_, found = FindMetricWithLabelValues("karpenter_pods_provisioning_startup_duration_seconds", nil)
 		Expect(found).To(BeTrue())
 	})
+	It("should update the end-to-end startup metrics for a pod that runs on a nodeclaim launched for it", func() {
+		nodePool := test.NodePool()
+		nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+			},
+		})
+		p := test.Pod(test.PodOptions{NodeName: node.Name})
+		p.Status.Phase = corev1.PodPending
+		ExpectApplied(ctx, env.Client, p)
+		// Karpenter decided the pod could schedule before the nodeclaim launched
+		fakeClock.SetTime(time.Now().Add(-time.Minute))
+		cluster.MarkPodSchedulingDecisions(ctx, map[*corev1.Pod]error{}, map[string][]*corev1.Pod{nodePool.Name: {p}}, map[string][]*corev1.Pod{nodeClaim.Name: {p}})
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p)) //This will add pod to pending pods and unscheduled pods set
+
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+		ExpectApplied(ctx, env.Client, nodeClaim, node)
+		p.Status.Phase = corev1.PodRunning
+		p.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()}}
+		ExpectApplied(ctx, env.Client, p)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+		_, found := FindMetricWithLabelValues("karpenter_pods_end_to_end_startup_duration_seconds", map[string]string{
+			"nodepool":       nodePool.Name,
+			"priority_class": "",
+		})
+		Expect(found).To(BeTrue())
+		for _, stage := range []string{"scheduling", "launch", "registration", "startup"} {
+			_, found = FindMetricWithLabelValues("karpenter_pods_startup_stage_duration_seconds", map[string]string{
+				"nodepool": nodePool.Name,
+				"stage":    stage,
+			})
+			Expect(found).To(BeTrue())
+		}
+	})
+	It("should not break down the startup of a pod that runs on a node Karpenter doesn't manage", func() {
+		node := test.Node()
+		p := test.Pod(test.PodOptions{NodeName: node.Name})
+		p.Status.Phase = corev1.PodPending
+		ExpectApplied(ctx, env.Client, node, p)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p)) //This will add pod to pending pods and unscheduled pods set
+
+		p.Status.Phase = corev1.PodRunning
+		p.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()}}
+		ExpectApplied(ctx, env.Client, p)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(p))
+
+		_, found := FindMetricWithLabelValues("karpenter_pods_end_to_end_startup_duration_seconds", nil)
+		Expect(found).To(BeFalse())
+		_, found = FindMetricWithLabelValues("karpenter_pods_startup_stage_duration_seconds", nil)
+		Expect(found).To(BeFalse())
+	})
 	It("should update the pod unstarted time metrics when the pod has succeeded", func() {
 		p := test.Pod()
 		p.Status.Phase = corev1.PodPending
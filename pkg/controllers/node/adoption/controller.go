@@ -0,0 +1,186 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adoption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/awslabs/operatorpkg/object"
+	"github.com/awslabs/operatorpkg/reasonable"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// Controller adopts pre-existing Nodes, e.g. Nodes that were launched by cluster-autoscaler before migrating to
+// Karpenter, into the NodePool named by their karpenter.sh/adopt annotation. It creates a NodeClaim for the Node that
+// adopts its instance rather than launching a new one, so that the Node can be drifted, expired and consolidated like
+// any other Node that Karpenter manages.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	recorder      events.Recorder
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		recorder:      recorder,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, c.Name())
+
+	nodePoolName, ok := node.Annotations[v1.NodeAdoptAnnotationKey]
+	if !ok || node.Spec.ProviderID == "" || !node.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	// Nodes that already have a NodeClaim are either managed by Karpenter already or were adopted
+	if _, err := nodeutils.NodeClaimForNode(ctx, c.kubeClient, node); !nodeutils.IsNodeClaimNotFoundError(err) {
+		if nodeutils.IsDuplicateNodeClaimError(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	nodePool := &v1.NodePool{}
+	if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: nodePoolName}, nodePool); err != nil {
+		if errors.IsNotFound(err) {
+			c.recorder.Publish(AdoptionFailedEvent(node, fmt.Sprintf("NodePool %s doesn't exist", nodePoolName)))
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("getting nodepool, %w", err)
+	}
+	if !nodepoolutils.IsManaged(nodePool, c.cloudProvider) {
+		c.recorder.Publish(AdoptionFailedEvent(node, fmt.Sprintf("NodePool %s isn't managed by this Karpenter instance", nodePoolName)))
+		return reconcile.Result{}, nil
+	}
+	reason, err := c.incompatibility(ctx, nodePool, node)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if reason != "" {
+		c.recorder.Publish(AdoptionFailedEvent(node, reason))
+		return reconcile.Result{}, nil
+	}
+	nodeClaim := c.nodeClaimForNode(nodePool, node)
+	// The NodeClaim is named after the Node so that adopting the Node twice fails to create a second NodeClaim, even
+	// before the first NodeClaim is resolved to the Node by its provider id
+	if err := c.kubeClient.Create(ctx, nodeClaim); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("creating nodeclaim, %w", err)
+	}
+	log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(nodeClaim), "NodePool", klog.KObj(nodePool), "provider-id", node.Spec.ProviderID).Info("adopting node")
+	c.recorder.Publish(AdoptedEvent(node, nodeClaim))
+	return reconcile.Result{}, nil
+}
+
+// incompatibility returns why the Node can't be adopted into the NodePool, or an empty string if it can. The Node and its
+// instance must satisfy the NodePool's requirements like a NodeClaim that's launched from the NodePool, and the Node's
+// capacity counts against the NodePool's limits once it's adopted.
+func (c *Controller) incompatibility(ctx context.Context, nodePool *v1.NodePool, node *corev1.Node) (string, error) {
+	instance, err := c.cloudProvider.Get(ctx, node.Spec.ProviderID)
+	if err != nil {
+		if cloudprovider.IsNodeClaimNotFoundError(err) {
+			return fmt.Sprintf("instance %s doesn't exist", node.Spec.ProviderID), nil
+		}
+		return "", fmt.Errorf("getting instance, %w", err)
+	}
+	requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Requirements()...)
+	if err := scheduling.NewLabelRequirements(lo.Assign(instance.Labels, node.Labels)).Intersects(requirements); err != nil {
+		return fmt.Sprintf("node doesn't satisfy the requirements of NodePool %s, %s", nodePool.Name, err), nil
+	}
+	if err := nodePool.Spec.Limits.ExceededBy(resources.Merge(nodePool.Status.Resources, node.Status.Capacity)); err != nil {
+		return fmt.Sprintf("node would exceed the limits of NodePool %s, %s", nodePool.Name, err), nil
+	}
+	return "", nil
+}
+
+// nodeClaimForNode returns a NodeClaim from the NodePool's template that adopts the Node's instance. The NodeClaim isn't
+// stamped with the NodePool's hash since the instance wasn't launched from the template, which leaves the cloudprovider
+// to determine whether the instance has drifted from the NodePool.
+func (c *Controller) nodeClaimForNode(nodePool *v1.NodePool, node *corev1.Node) *v1.NodeClaim {
+	nodeClaim := nodePool.Spec.Template.ToNodeClaim()
+	nodeClaim.ObjectMeta = metav1.ObjectMeta{
+		Name: node.Name,
+		Annotations: lo.Assign(nodeClaim.Annotations, map[string]string{
+			v1.NodeClaimAdoptedInstanceAnnotationKey: node.Spec.ProviderID,
+		}),
+		Labels: lo.Assign(nodeClaim.Labels, map[string]string{
+			v1.NodePoolLabelKey: nodePool.Name,
+			v1.NodeClassLabelKey(nodePool.Spec.Template.Spec.NodeClassRef.GroupKind()): nodePool.Spec.Template.Spec.NodeClassRef.Name,
+		}),
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion:         object.GVK(nodePool).GroupVersion().String(),
+				Kind:               object.GVK(nodePool).Kind,
+				Name:               nodePool.Name,
+				UID:                nodePool.UID,
+				BlockOwnerDeletion: lo.ToPtr(true),
+			},
+		},
+	}
+	// The Node is already running, so nothing would remove the NodePool's startup taints from it, and the NodePool's
+	// taints could evict its pods. Instead, the Node keeps the taints that it has.
+	nodeClaim.Spec.StartupTaints = nil
+	nodeClaim.Spec.Taints = lo.Reject(node.Spec.Taints, func(t corev1.Taint, _ int) bool {
+		return lo.ContainsBy(scheduling.KnownEphemeralTaints, func(ephemeral corev1.Taint) bool { return ephemeral.MatchTaint(&t) })
+	})
+	return nodeClaim
+}
+
+func (c *Controller) Name() string {
+	return "node.adoption"
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named(c.Name()).
+		For(&corev1.Node{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			_, ok := o.GetAnnotations()[v1.NodeAdoptAnnotationKey]
+			return ok
+		}))).
+		WithOptions(controller.Options{
+			RateLimiter:             reasonable.RateLimiter(),
+			MaxConcurrentReconciles: utilscontroller.LinearScaleReconciles(utilscontroller.CPUCount(ctx), 10, 1000),
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
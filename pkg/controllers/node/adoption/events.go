@@ -0,0 +1,46 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adoption
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+func AdoptedEvent(node *corev1.Node, nodeClaim *v1.NodeClaim) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.Adopted,
+		Message:        fmt.Sprintf("Adopted into NodePool %s by NodeClaim %s", nodeClaim.Labels[v1.NodePoolLabelKey], nodeClaim.Name),
+		DedupeValues:   []string{string(node.UID)},
+	}
+}
+
+func AdoptionFailedEvent(node *corev1.Node, reason string) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.AdoptionFailed,
+		Message:        fmt.Sprintf("Failed to adopt node, %s", reason),
+		DedupeValues:   []string{string(node.UID), reason},
+	}
+}
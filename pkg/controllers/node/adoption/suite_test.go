@@ -0,0 +1,211 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adoption_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/adoption"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var adoptionController *adoption.Controller
+var env *test.Environment
+var cloudProvider *fake.CloudProvider
+var recorder *test.EventRecorder
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Adoption")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx), test.NodeClaimProviderIDFieldIndexer(ctx)))
+	ctx = options.ToContext(ctx, test.Options())
+
+	cloudProvider = fake.NewCloudProvider()
+	recorder = test.NewEventRecorder()
+	adoptionController = adoption.NewController(env.Client, cloudProvider, recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+	cloudProvider.Reset()
+	recorder.Reset()
+})
+
+var _ = Describe("Adoption", func() {
+	var nodePool *v1.NodePool
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Template: v1.NodeClaimTemplate{
+					Spec: v1.NodeClaimTemplateSpec{
+						StartupTaints: []corev1.Taint{{Key: "example.com/startup", Effect: corev1.TaintEffectNoSchedule}},
+						Taints:        []corev1.Taint{{Key: "example.com/dedicated", Effect: corev1.TaintEffectNoExecute}},
+					},
+				},
+			},
+		})
+		node = test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1.NodeAdoptAnnotationKey: nodePool.Name},
+			},
+			ProviderID: test.RandomProviderID(),
+			Taints:     []corev1.Taint{{Key: "example.com/existing", Effect: corev1.TaintEffectNoSchedule}},
+			Capacity:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+		})
+		cloudProvider.CreatedNodeClaims[node.Spec.ProviderID] = test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1.CapacityTypeLabelKey: v1.CapacityTypeOnDemand},
+			},
+			Status: v1.NodeClaimStatus{ProviderID: node.Spec.ProviderID},
+		})
+	})
+	It("should create a nodeclaim that adopts the node's instance", func() {
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		nodeClaim := ExpectExists(ctx, env.Client, &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: node.Name}})
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimAdoptedInstanceAnnotationKey, node.Spec.ProviderID))
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.NodePoolHashAnnotationKey))
+		Expect(nodeClaim.Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePool.Name))
+		Expect(nodeClaim.OwnerReferences).To(HaveLen(1))
+		Expect(nodeClaim.OwnerReferences[0].Name).To(Equal(nodePool.Name))
+		Expect(recorder.Calls(events.Adopted)).To(Equal(1))
+	})
+	It("should keep the node's taints rather than the nodepool's", func() {
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		nodeClaim := ExpectExists(ctx, env.Client, &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: node.Name}})
+		Expect(nodeClaim.Spec.StartupTaints).To(BeEmpty())
+		Expect(nodeClaim.Spec.Taints).To(ConsistOf(corev1.Taint{Key: "example.com/existing", Effect: corev1.TaintEffectNoSchedule}))
+	})
+	It("should not adopt nodes that already have a nodeclaim", func() {
+		nodeClaim, managedNode := test.NodeClaimAndNode()
+		managedNode.Annotations = map[string]string{v1.NodeAdoptAnnotationKey: nodePool.Name}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, managedNode)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, managedNode)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+		Expect(recorder.Calls(events.Adopted)).To(Equal(0))
+	})
+	It("should not adopt nodes without the adopt annotation", func() {
+		delete(node.Annotations, v1.NodeAdoptAnnotationKey)
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+	})
+	It("should not adopt nodes into a nodepool that doesn't exist", func() {
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		Expect(recorder.Calls(events.AdoptionFailed)).To(Equal(1))
+	})
+	It("should not adopt nodes into a nodepool that isn't managed by this Karpenter instance", func() {
+		nodePool.Spec.Template.Spec.NodeClassRef = &v1.NodeClassReference{
+			Group: "karpenter.test.sh",
+			Kind:  "UnmanagedNodeClass",
+			Name:  "default",
+		}
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		Expect(recorder.Calls(events.AdoptionFailed)).To(Equal(1))
+	})
+	It("should not adopt nodes whose instance doesn't exist", func() {
+		delete(cloudProvider.CreatedNodeClaims, node.Spec.ProviderID)
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		Expect(recorder.Calls(events.AdoptionFailed)).To(Equal(1))
+	})
+	It("should not adopt nodes whose labels don't satisfy the nodepool's requirements", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+		}
+		node.Labels[corev1.LabelTopologyZone] = "test-zone-2"
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		Expect(recorder.Calls(events.AdoptionFailed)).To(Equal(1))
+	})
+	It("should not adopt nodes whose instance doesn't satisfy the nodepool's requirements", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: v1.CapacityTypeLabelKey, Operator: corev1.NodeSelectorOpIn, Values: []string{v1.CapacityTypeSpot}}},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		Expect(recorder.Calls(events.AdoptionFailed)).To(Equal(1))
+	})
+	It("should not adopt nodes whose capacity would exceed the nodepool's limits", func() {
+		nodePool.Spec.Limits = v1.Limits{corev1.ResourceCPU: resource.MustParse("10")}
+		nodePool.Status.Resources = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")}
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(0))
+		Expect(recorder.Calls(events.AdoptionFailed)).To(Equal(1))
+	})
+	It("should adopt nodes whose capacity fits within the nodepool's limits", func() {
+		nodePool.Spec.Limits = v1.Limits{corev1.ResourceCPU: resource.MustParse("10")}
+		nodePool.Status.Resources = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("6")}
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+		Expect(recorder.Calls(events.Adopted)).To(Equal(1))
+	})
+	It("should only create one nodeclaim when the node is reconciled again", func() {
+		ExpectApplied(ctx, env.Client, nodePool, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+		ExpectObjectReconciled(ctx, env.Client, adoptionController, node)
+
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(HaveLen(1))
+	})
+})
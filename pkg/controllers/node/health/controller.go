@@ -45,6 +45,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
@@ -113,9 +114,9 @@ func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcil
 	}
 	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(nodeClaim)))
 
-	unhealthyNodeCondition, policyTerminationDuration := c.findUnhealthyConditions(node)
+	unhealthyNodeCondition, policyTerminationDuration := c.findUnhealthyConditions(ctx, node)
 	if unhealthyNodeCondition == nil {
-		return reconcile.Result{}, nil
+		return reconcile.Result{}, client.IgnoreNotFound(c.clearUnhealthy(ctx, nodeClaim))
 	}
 
 	// If the Node is unhealthy, but has not reached its full toleration disruption
@@ -150,14 +151,17 @@ func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcil
 			return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
 		}
 	}
-	// For unhealthy past the tolerationDisruption window we can forcefully terminate the node
+	// For unhealthy past the tolerationDisruption window we can forcefully terminate the node. With graceful node repair,
+	// the node is instead forcefully drained once the disruption controller has replaced it.
 	if err := c.annotateTerminationGracePeriod(ctx, nodeClaim); err != nil {
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
+	if options.FromContext(ctx).GracefulNodeRepair {
+		return reconcile.Result{}, client.IgnoreNotFound(c.markUnhealthy(ctx, nodeClaim, node, unhealthyNodeCondition))
+	}
 	return c.deleteNodeClaim(ctx, nodeClaim, node, unhealthyNodeCondition)
 }
 
-// deleteNodeClaim removes the NodeClaim from the api-server
 func (c *Controller) deleteNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim, node *corev1.Node, unhealthyNodeCondition *corev1.NodeCondition) (reconcile.Result, error) {
 	if !nodeClaim.DeletionTimestamp.IsZero() {
 		return reconcile.Result{}, nil
@@ -181,11 +185,73 @@ func (c *Controller) deleteNodeClaim(ctx context.Context, nodeClaim *v1.NodeClai
 	return reconcile.Result{}, nil
 }
 
+// markUnhealthy adds the Unhealthy condition to the NodeClaim when graceful node repair is enabled. The disruption
+// controller replaces Unhealthy NodeClaims before deleting them, within the NodePool's disruption budgets for the
+// Unhealthy reason.
+func (c *Controller) markUnhealthy(ctx context.Context, nodeClaim *v1.NodeClaim, node *corev1.Node, unhealthyNodeCondition *corev1.NodeCondition) error {
+	if !nodeClaim.DeletionTimestamp.IsZero() || nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy).IsTrue() {
+		return nil
+	}
+	stored := nodeClaim.DeepCopy()
+	nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, string(unhealthyNodeCondition.Type),
+		fmt.Sprintf("Node condition %s has been %s since %s", unhealthyNodeCondition.Type, unhealthyNodeCondition.Status, unhealthyNodeCondition.LastTransitionTime.Format(time.RFC3339)))
+	if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+		return err
+	}
+	log.FromContext(ctx).WithValues("condition", unhealthyNodeCondition.Type).Info("marked unhealthy node for repair")
+	NodeClaimsUnhealthyDisruptedTotal.Inc(map[string]string{
+		Condition:                 pretty.ToSnakeCase(string(unhealthyNodeCondition.Type)),
+		metrics.NodePoolLabel:     node.Labels[v1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel: node.Labels[v1.CapacityTypeLabelKey],
+		ImageID:                   nodeClaim.Status.ImageID,
+	})
+	return nil
+}
+
+// clearUnhealthy removes the Unhealthy condition and the termination timestamp from a NodeClaim whose node recovered
+// before it was replaced. Only Unhealthy conditions that were set for a node condition are cleared, since the other
+// reasons, e.g. StartupTaintsTimedOut, are owned by the controllers that set them.
+func (c *Controller) clearUnhealthy(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	unhealthy := nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)
+	if !nodeClaim.DeletionTimestamp.IsZero() || unhealthy == nil {
+		return nil
+	}
+	if !lo.ContainsBy(c.repairPolicies(ctx), func(policy cloudprovider.RepairPolicy) bool { return string(policy.ConditionType) == unhealthy.Reason }) {
+		return nil
+	}
+	if _, ok := nodeClaim.Annotations[v1.NodeClaimTerminationTimestampAnnotationKey]; ok {
+		stored := nodeClaim.DeepCopy()
+		delete(nodeClaim.Annotations, v1.NodeClaimTerminationTimestampAnnotationKey)
+		if err := c.kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+			return err
+		}
+	}
+	stored := nodeClaim.DeepCopy()
+	_ = nodeClaim.StatusConditions().Clear(v1.ConditionTypeUnhealthy)
+	if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("node recovered before it was repaired")
+	return nil
+}
+
+// repairPolicies returns the RepairPolicies of the cloud provider, along with the Node conditions that are configured
+// to be repaired
+func (c *Controller) repairPolicies(ctx context.Context) []cloudprovider.RepairPolicy {
+	return append(c.cloudProvider.RepairPolicies(), lo.Map(options.FromContext(ctx).NodeRepairConditions, func(condition options.NodeRepairCondition, _ int) cloudprovider.RepairPolicy {
+		return cloudprovider.RepairPolicy{
+			ConditionType:      condition.Type,
+			ConditionStatus:    condition.Status,
+			TolerationDuration: condition.TolerationDuration,
+		}
+	})...)
+}
+
 // Find a node with a condition that matches one of the unhealthy conditions defined by the cloud provider
 // If there are multiple unhealthy status condition we will requeue based on the condition closest to its terminationDuration
-func (c *Controller) findUnhealthyConditions(node *corev1.Node) (nc *corev1.NodeCondition, cpTerminationDuration time.Duration) {
+func (c *Controller) findUnhealthyConditions(ctx context.Context, node *corev1.Node) (nc *corev1.NodeCondition, cpTerminationDuration time.Duration) {
 	requeueTime := time.Time{}
-	for _, policy := range c.cloudProvider.RepairPolicies() {
+	for _, policy := range c.repairPolicies(ctx) {
 		// check the status and the type on the condition
 		nodeCondition := nodeutils.GetCondition(node, policy.ConditionType)
 		if nodeCondition.Status == policy.ConditionStatus {
@@ -239,8 +305,9 @@ func (c *Controller) areNodesHealthy(ctx context.Context, opts ...client.ListOpt
 	if err := c.kubeClient.List(ctx, nodeList, append(opts, client.UnsafeDisableDeepCopy)...); err != nil {
 		return false, err
 	}
+	policies := c.repairPolicies(ctx)
 	unhealthyNodeCount := lo.CountBy(nodeList.Items, func(node corev1.Node) bool {
-		_, found := lo.Find(c.cloudProvider.RepairPolicies(), func(policy cloudprovider.RepairPolicy) bool {
+		_, found := lo.Find(policies, func(policy cloudprovider.RepairPolicy) bool {
 			nodeCondition := nodeutils.GetCondition(lo.ToPtr(node), policy.ConditionType)
 			return nodeCondition.Status == policy.ConditionStatus
 		})
@@ -35,6 +35,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
@@ -80,6 +81,7 @@ var _ = Describe("Node Health", func() {
 	var nodePool *v1.NodePool
 
 	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options())
 		fakeClock.SetTime(time.Now())
 		cloudProvider.Reset()
 
@@ -95,6 +97,7 @@ var _ = Describe("Node Health", func() {
 
 		// Reset the metrics collectors
 		metrics.NodeClaimsDisruptedTotal.Reset()
+		health.NodeClaimsUnhealthyDisruptedTotal.Reset()
 	})
 
 	Context("Reconciliation", func() {
@@ -112,6 +115,22 @@ var _ = Describe("Node Health", func() {
 			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 			Expect(nodeClaim.DeletionTimestamp).ToNot(BeNil())
 		})
+		It("should delete nodes with a configured unhealthy condition", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeRepairConditions: []options.NodeRepairCondition{
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, TolerationDuration: 10 * time.Minute},
+			}}))
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type:               corev1.NodeDiskPressure,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+			})
+			fakeClock.Step(15 * time.Minute)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectObjectReconciled(ctx, env.Client, healthController, node)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.DeletionTimestamp).ToNot(BeNil())
+		})
 		It("should not delete node when unhealthy type does not match cloud provider passed in value", func() {
 			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
 				Type:               "FakeHealthyNode",
@@ -385,6 +404,95 @@ var _ = Describe("Node Health", func() {
 			Expect(nodeClaim.DeletionTimestamp).ToNot(BeNil())
 		})
 	})
+	Context("Graceful repair", func() {
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{GracefulNodeRepair: lo.ToPtr(true)}))
+		})
+		It("should mark nodes that are unhealthy by the cloud provider for repair", func() {
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type:               "BadNode",
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+			})
+			fakeClock.Step(60 * time.Minute)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectObjectReconciled(ctx, env.Client, healthController, node)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.DeletionTimestamp).To(BeNil())
+			condition := nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)
+			Expect(condition.IsTrue()).To(BeTrue())
+			Expect(condition.Reason).To(Equal("BadNode"))
+		})
+		It("should mark nodes with a configured unhealthy condition for repair", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeRepairConditions: []options.NodeRepairCondition{
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, TolerationDuration: 10 * time.Minute},
+			}}))
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type:               corev1.NodeDiskPressure,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+			})
+			fakeClock.Step(15 * time.Minute)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectObjectReconciled(ctx, env.Client, healthController, node)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			condition := nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)
+			Expect(condition.IsTrue()).To(BeTrue())
+			Expect(condition.Reason).To(Equal(string(corev1.NodeDiskPressure)))
+		})
+		It("should clear the unhealthy condition when the node recovers", func() {
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimTerminationTimestampAnnotationKey: fakeClock.Now().Format(time.RFC3339)})
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, "BadNode", "")
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type:               "BadNode",
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectObjectReconciled(ctx, env.Client, healthController, node)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)).To(BeNil())
+			Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.NodeClaimTerminationTimestampAnnotationKey))
+		})
+		It("should not clear the unhealthy condition when it wasn't set for a node condition", func() {
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, v1.UnhealthyReasonStartupTaintsTimedOut, "")
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectObjectReconciled(ctx, env.Client, healthController, node)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			condition := nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)
+			Expect(condition.IsTrue()).To(BeTrue())
+			Expect(condition.Reason).To(Equal(v1.UnhealthyReasonStartupTaintsTimedOut))
+		})
+		It("should respect node disruption budgets by leaving unhealthy nodes to the disruption controller", func() {
+			nodePool.Spec.Disruption = v1.Disruption{
+				Budgets: []v1.Budget{
+					{
+						Nodes: "0",
+					},
+				},
+			}
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type:               "BadNode",
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: metav1.Time{Time: fakeClock.Now()},
+			})
+			fakeClock.Step(60 * time.Minute)
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			ExpectObjectReconciled(ctx, env.Client, healthController, node)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.DeletionTimestamp).To(BeNil())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy).IsTrue()).To(BeTrue())
+			ExpectMetricCounterValue(health.NodeClaimsUnhealthyDisruptedTotal, 1, map[string]string{
+				health.Condition:      pretty.ToSnakeCase(string(cloudProvider.RepairPolicies()[0].ConditionType)),
+				metrics.NodePoolLabel: nodePool.Name,
+			})
+		})
+	})
 	Context("Metrics", func() {
 		It("should fire a karpenter_nodeclaims_disrupted_total metric when unhealthy", func() {
 			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
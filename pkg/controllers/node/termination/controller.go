@@ -19,8 +19,11 @@ package termination
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/awslabs/operatorpkg/option"
 	"github.com/awslabs/operatorpkg/serrors"
 	"github.com/samber/lo"
 	"golang.org/x/time/rate"
@@ -49,9 +52,11 @@ import (
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	"sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/readonly"
 	volumeutil "sigs.k8s.io/karpenter/pkg/utils/volume"
 )
 
@@ -67,16 +72,30 @@ type Controller struct {
 	cloudProvider cloudprovider.CloudProvider
 	terminator    *terminator.Terminator
 	recorder      events.Recorder
+	preTerminator cloudprovider.PreTerminator
+}
+
+type ControllerOptions struct {
+	preTerminator cloudprovider.PreTerminator
+}
+
+// WithPreTerminator sets the cloudprovider hook that prepares instances for termination once their nodes have drained
+func WithPreTerminator(preTerminator cloudprovider.PreTerminator) option.Function[ControllerOptions] {
+	return func(o *ControllerOptions) {
+		o.preTerminator = preTerminator
+	}
 }
 
 // NewController constructs a controller instance
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, terminator *terminator.Terminator, recorder events.Recorder, opts ...option.Function[ControllerOptions]) *Controller {
+	o := option.Resolve(opts...)
 	return &Controller{
 		clock:         clk,
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
 		terminator:    terminator,
 		recorder:      recorder,
+		preTerminator: o.preTerminator,
 	}
 }
 
@@ -143,8 +162,12 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 	var terminationErr error
 	var result reconcile.Result
 	for _, f := range []terminationFunc{
+		c.awaitPreDrainNotification,
+		c.awaitPreDrainHooks,
 		c.awaitDrain,
 		c.awaitVolumeDetachment,
+		c.awaitTerminationHolds,
+		c.awaitPreTermination,
 		c.awaitInstanceTermination,
 	} {
 		result, terminationErr = f(ctx, nodeClaim, node, nodeTerminationTime)
@@ -170,6 +193,9 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 			NodesDrainedTotal.Inc(map[string]string{
 				metrics.NodePoolLabel: node.Labels[v1.NodePoolLabelKey],
 			})
+			NodesDrainDurationSeconds.Observe(time.Since(drainStartTime(ctx, node, nodeClaim)).Seconds(), map[string]string{
+				metrics.NodePoolLabel: node.Labels[v1.NodePoolLabelKey],
+			})
 		}
 		// We sleep here after a patch operation since we want to ensure that we are able to read our own writes
 		// so that we avoid duplicating metrics and log lines due to quick re-queues from our node watcher
@@ -190,6 +216,91 @@ func (c *Controller) finalize(ctx context.Context, node *corev1.Node) (reconcile
 
 type terminationFunc func(context.Context, *v1.NodeClaim, *corev1.Node, *time.Time) (reconcile.Result, error)
 
+// awaitPreDrainNotification taints the node as decommissioning and will continue to requeue until the pre-drain
+// notification window has elapsed, giving pods with graceful shutdown hooks and external systems the chance to react
+// before the drain starts. The window never extends past the nodeClaim's terminationGracePeriod.
+func (c *Controller) awaitPreDrainNotification(
+	ctx context.Context,
+	nodeClaim *v1.NodeClaim,
+	node *corev1.Node,
+	nodeTerminationTime *time.Time,
+) (reconcile.Result, error) {
+	window := options.FromContext(ctx).PreDrainNotificationWindow
+	// The window doesn't apply to nodes that already started draining
+	if window == 0 || (nodeClaim != nil && nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained) != nil) {
+		return reconcile.Result{}, nil
+	}
+	if err := c.terminator.Taint(ctx, node, v1.DecommissioningNoScheduleTaint); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, serrors.Wrap(fmt.Errorf("tainting node, %w", err), "taint", pretty.Taint(v1.DecommissioningNoScheduleTaint))
+	}
+	drainTime := node.DeletionTimestamp.Add(window)
+	if nodeTerminationTime != nil && nodeTerminationTime.Before(drainTime) {
+		drainTime = *nodeTerminationTime
+	}
+	if remaining := drainTime.Sub(c.clock.Now()); remaining > 0 {
+		c.recorder.Publish(terminatorevents.NodeDecommissioning(node, drainTime))
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// awaitPreDrainHooks will continue to requeue until the pre-drain hooks registered on the nodeClaim have been removed,
+// giving external controllers the chance to e.g. deregister the node from service discovery before its pods are evicted.
+// Hooks are no longer waited on once the pre-drain hook timeout or the nodeClaim's terminationGracePeriod has elapsed.
+func (c *Controller) awaitPreDrainHooks(
+	ctx context.Context,
+	nodeClaim *v1.NodeClaim,
+	node *corev1.Node,
+	nodeTerminationTime *time.Time,
+) (reconcile.Result, error) {
+	// Hooks that are registered after the drain has started don't block it
+	if nodeClaim == nil || nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained) != nil {
+		return reconcile.Result{}, nil
+	}
+	hooks := preDrainHooks(nodeClaim)
+	if len(hooks) == 0 {
+		// We only surface the status condition on NodeClaims that had to wait on hooks
+		if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypePreDrainHooksCompleted); cond != nil && cond.IsUnknown() {
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypePreDrainHooksCompleted)
+		}
+		return reconcile.Result{}, nil
+	}
+	timeout := options.FromContext(ctx).PreDrainHookTimeout
+	if (timeout == 0 || c.clock.Since(node.DeletionTimestamp.Time) < timeout) && !c.hasTerminationGracePeriodElapsed(nodeTerminationTime) {
+		c.recorder.Publish(terminatorevents.NodeAwaitingPreDrainHooks(node, hooks))
+		nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypePreDrainHooksCompleted, "AwaitingPreDrainHooks", "AwaitingPreDrainHooks")
+		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+	if nodeClaim.StatusConditions().SetFalse(v1.ConditionTypePreDrainHooksCompleted, "PreDrainHooksTimedOut", "PreDrainHooksTimedOut") {
+		log.FromContext(ctx).Info("pre-drain hooks timed out, draining node", "hooks", hooks)
+	}
+	return reconcile.Result{}, nil
+}
+
+// preDrainHooks returns the sorted names of the pre-drain hooks that are registered on the nodeClaim
+func preDrainHooks(nodeClaim *v1.NodeClaim) []string {
+	var hooks []string
+	for k := range nodeClaim.Annotations {
+		if name, ok := strings.CutPrefix(k, v1.PreDrainHookAnnotationPrefix); ok && name != "" {
+			hooks = append(hooks, name)
+		}
+	}
+	sort.Strings(hooks)
+	return hooks
+}
+
+// drainStartTime returns when the node started draining, which is when its pre-drain hooks completed or, without
+// hooks, when the pre-drain notification window of the node ended
+func drainStartTime(ctx context.Context, node *corev1.Node, nodeClaim *v1.NodeClaim) time.Time {
+	if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypePreDrainHooksCompleted); cond != nil && !cond.IsUnknown() {
+		return cond.LastTransitionTime.Time
+	}
+	return node.DeletionTimestamp.Add(options.FromContext(ctx).PreDrainNotificationWindow)
+}
+
 // awaitDrain initiates the drain of the node and will continue to requeue until the node has been drained. If the
 // nodeClaim has a terminationGracePeriod set, pods will be deleted to ensure this function does not requeue past the
 // nodeTerminationTime.
@@ -217,7 +328,8 @@ func (c *Controller) awaitDrain(
 
 // awaitVolumeDetachment will continue to requeue until all volume attachments associated with the node have been
 // deleted. The deletion is performed by the upstream attach-detach controller, Karpenter just needs to await deletion.
-// This will be skipped once the nodeClaim's terminationGracePeriod has elapsed at nodeTerminationTime.
+// This will be skipped once the nodeClaim's terminationGracePeriod has elapsed at nodeTerminationTime, once the volume
+// detachment timeout has elapsed, or when waiting for volume detachment is disabled.
 //
 //nolint:gocyclo
 func (c *Controller) awaitVolumeDetachment(
@@ -242,6 +354,21 @@ func (c *Controller) awaitVolumeDetachment(
 		return reconcile.Result{}, nil
 	}
 
+	if !options.FromContext(ctx).VolumeDetachmentWait {
+		if nodeClaim != nil {
+			nodeClaim.StatusConditions().SetFalse(v1.ConditionTypeVolumesDetached, "VolumeDetachmentWaitDisabled", "VolumeDetachmentWaitDisabled")
+		}
+		return reconcile.Result{}, nil
+	}
+	if timeout := options.FromContext(ctx).VolumeDetachmentTimeout; timeout != 0 && c.clock.Since(volumeDetachmentStartTime(node, nodeClaim)) >= timeout {
+		// The volume attachments didn't get deleted within the timeout, e.g. because a CSI driver failed to detach them.
+		// Like an elapsed TGP, we stop waiting and fall through to instance termination.
+		c.recorder.Publish(terminatorevents.NodeVolumeDetachmentTimedOut(node, timeout, pendingVolumeAttachments...))
+		if nodeClaim != nil {
+			nodeClaim.StatusConditions().SetFalse(v1.ConditionTypeVolumesDetached, "VolumeDetachmentTimedOut", "VolumeDetachmentTimedOut")
+		}
+		return reconcile.Result{}, nil
+	}
 	if !c.hasTerminationGracePeriodElapsed(nodeTerminationTime) {
 		// There are volume attachments blocking instance termination remaining. We should set the status condition to
 		// unknown (if not already) and requeue. This case should never fall through, to continue to instance termination
@@ -263,6 +390,97 @@ func (c *Controller) awaitVolumeDetachment(
 	return reconcile.Result{}, nil
 }
 
+// volumeDetachmentStartTime returns when the termination started waiting for volume attachments, which is when the
+// node finished draining
+func volumeDetachmentStartTime(node *corev1.Node, nodeClaim *v1.NodeClaim) time.Time {
+	if nodeClaim != nil {
+		if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained); cond.IsTrue() {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return node.DeletionTimestamp.Time
+}
+
+// awaitTerminationHolds will continue to requeue until the termination holds that external controllers placed on the
+// nodeClaim with termination-hold.karpenter.sh/<hold> finalizers have been removed, e.g. once a backup of the node's
+// local storage completes. Holds are no longer waited on once the termination hold timeout or the nodeClaim's
+// terminationGracePeriod has elapsed.
+func (c *Controller) awaitTerminationHolds(
+	ctx context.Context,
+	nodeClaim *v1.NodeClaim,
+	node *corev1.Node,
+	nodeTerminationTime *time.Time,
+) (reconcile.Result, error) {
+	if nodeClaim == nil {
+		return reconcile.Result{}, nil
+	}
+	// Holds that are placed after they were released or timed out don't block termination
+	cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeTerminationHoldsReleased)
+	if cond != nil && !cond.IsUnknown() {
+		return reconcile.Result{}, nil
+	}
+	holds := nodeClaim.TerminationHolds()
+	if len(holds) == 0 {
+		// We only surface the status condition on NodeClaims that had to wait on holds
+		if cond != nil {
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeTerminationHoldsReleased)
+		}
+		return reconcile.Result{}, nil
+	}
+	start := c.clock.Now()
+	if cond != nil {
+		start = cond.LastTransitionTime.Time
+	}
+	if timeout := options.FromContext(ctx).TerminationHoldTimeout; (timeout != 0 && c.clock.Since(start) >= timeout) || c.hasTerminationGracePeriodElapsed(nodeTerminationTime) {
+		c.recorder.Publish(terminatorevents.NodeTerminationHoldsTimedOut(node, holds))
+		nodeClaim.StatusConditions().SetFalse(v1.ConditionTypeTerminationHoldsReleased, "TerminationHoldsTimedOut", "TerminationHoldsTimedOut")
+		return reconcile.Result{}, nil
+	}
+	c.recorder.Publish(terminatorevents.NodeAwaitingTerminationHolds(node, holds))
+	nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeTerminationHoldsReleased, "AwaitingTerminationHolds", "AwaitingTerminationHolds")
+	return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+}
+
+// awaitPreTermination calls the cloudprovider's pre-terminate hook until the instance is ready to be terminated, e.g.
+// once it has been deregistered from load balancers and its connections have drained. Failed calls are retried with
+// backoff. The hook is no longer waited on once the pre-terminate timeout or the nodeClaim's terminationGracePeriod
+// has elapsed.
+func (c *Controller) awaitPreTermination(
+	ctx context.Context,
+	nodeClaim *v1.NodeClaim,
+	node *corev1.Node,
+	nodeTerminationTime *time.Time,
+) (reconcile.Result, error) {
+	// Preparing the instance for termination mutates the cloudprovider, the same as terminating it
+	if c.preTerminator == nil || nodeClaim == nil || readonly.Enabled(ctx) {
+		return reconcile.Result{}, nil
+	}
+	cond := nodeClaim.StatusConditions().Get(v1.ConditionTypePreTerminated)
+	if cond != nil && !cond.IsUnknown() {
+		return reconcile.Result{}, nil
+	}
+	start := c.clock.Now()
+	if cond != nil {
+		start = cond.LastTransitionTime.Time
+	}
+	if timeout := options.FromContext(ctx).PreTerminateTimeout; (timeout != 0 && c.clock.Since(start) >= timeout) || c.hasTerminationGracePeriodElapsed(nodeTerminationTime) {
+		c.recorder.Publish(terminatorevents.NodePreTerminationTimedOut(node))
+		nodeClaim.StatusConditions().SetFalse(v1.ConditionTypePreTerminated, "PreTerminationTimedOut", "PreTerminationTimedOut")
+		return reconcile.Result{}, nil
+	}
+	nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypePreTerminated, "AwaitingPreTermination", "AwaitingPreTermination")
+	done, err := c.preTerminator.PreTerminate(ctx, nodeClaim)
+	if err != nil {
+		c.recorder.Publish(terminatorevents.NodeFailedToPreTerminate(node, err))
+		return reconcile.Result{}, fmt.Errorf("pre-terminating instance, %w", err)
+	}
+	if !done {
+		return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypePreTerminated)
+	return reconcile.Result{}, nil
+}
+
 // awaitInstanceTermination will initiate instance termination and continue to requeue until the cloudprovider indicates
 // the instance is no longer found. Once gone, the node's finalizer will be removed, unblocking the NodeClaim lifecycle
 // controller.
@@ -50,6 +50,17 @@ var (
 		},
 		[]string{metrics.NodePoolLabel},
 	)
+	NodesDrainDurationSeconds = opmetrics.NewPrometheusHistogram(
+		crmetrics.Registry,
+		prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.NodeSubsystem,
+			Name:      "drain_duration_seconds",
+			Help:      "The time taken to drain a node, from when its pods started being evicted until all of them were evicted",
+			Buckets:   metrics.DurationBuckets(),
+		},
+		[]string{metrics.NodePoolLabel},
+	)
 	NodeLifetimeDurationSeconds = opmetrics.NewPrometheusHistogram(
 		crmetrics.Registry,
 		prometheus.HistogramOpts{
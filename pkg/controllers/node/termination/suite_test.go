@@ -27,8 +27,10 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
 	clock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -41,7 +43,9 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
@@ -91,6 +95,7 @@ var _ = Describe("Termination", func() {
 	var nodePool *v1.NodePool
 
 	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options())
 		fakeClock.SetTime(time.Now())
 		cloudProvider.Reset()
 		*queue = lo.FromPtr(terminator.NewQueue(env.Client, recorder))
@@ -109,6 +114,7 @@ var _ = Describe("Termination", func() {
 		termination.DurationSeconds.Reset()
 		termination.NodeLifetimeDurationSeconds.Reset()
 		termination.NodesDrainedTotal.Reset()
+		termination.NodesDrainDurationSeconds.Reset()
 	})
 
 	Context("Reconciliation", func() {
@@ -506,6 +512,47 @@ var _ = Describe("Termination", func() {
 			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should evict lower priority pods first", func() {
+			low := &schedulingv1.PriorityClass{ObjectMeta: test.ObjectMeta(), Value: 100}
+			high := &schedulingv1.PriorityClass{ObjectMeta: test.ObjectMeta(), Value: 1000}
+			ExpectApplied(ctx, env.Client, low, high)
+			DeferCleanup(func() { ExpectDeleted(ctx, env.Client, low, high) })
+
+			podDefault := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podLow := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: low.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podLow.Spec.Priority = lo.ToPtr(low.Value)
+			podHigh := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: high.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podHigh.Spec.Priority = lo.ToPtr(high.Value)
+			podCritical := test.Pod(test.PodOptions{NodeName: node.Name, PriorityClassName: "system-cluster-critical", ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podDefault, podLow, podHigh, podCritical)
+
+			// Trigger Termination Controller
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+			podGroups := [][]*corev1.Pod{{podDefault}, {podLow}, {podHigh}, {podCritical}}
+			for i, podGroup := range podGroups {
+				node = ExpectNodeExists(ctx, env.Client, node.Name)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))
+				for _, pod := range podGroup {
+					ExpectObjectReconciled(ctx, env.Client, queue, pod)
+				}
+				EventuallyExpectTerminating(ctx, env.Client, lo.Map(podGroup, func(p *corev1.Pod, _ int) client.Object { return p })...)
+				// Ensure that none of the pods of the next wave have started terminating
+				if i != len(podGroups)-1 {
+					for _, pod := range podGroups[i+1] {
+						Expect(queue.Has(pod)).To(BeFalse())
+					}
+				}
+				ExpectDeleted(ctx, env.Client, lo.Map(podGroup, func(p *corev1.Pod, _ int) client.Object { return p })...)
+			}
+
+			// Reconcile to delete node
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // DrainValidation, VolumeDetachment, InstanceTerminationInitiation
+			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+			ExpectNotFound(ctx, env.Client, node)
+		})
 		It("should not evict static pods", func() {
 			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
 			ExpectApplied(ctx, env.Client, node, nodeClaim, podEvict)
@@ -546,6 +593,55 @@ var _ = Describe("Termination", func() {
 			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
 			ExpectNotFound(ctx, env.Client, node)
 		})
+		It("should not evict mirror pods and report them as terminating with the node", func() {
+			recorder.Reset()
+			podEvict := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+			podNoEvict := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{corev1.MirrorPodAnnotationKey: "mirror"},
+				},
+			})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, podEvict, podNoEvict)
+
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // DrainInitiation
+			ExpectObjectReconciled(ctx, env.Client, queue, podEvict)
+
+			// Expect mirror pod to not be queued for eviction
+			Expect(queue.Has(podNoEvict)).To(BeFalse())
+			Expect(recorder.DetectedEvent("Failed to drain node, 1 pods are waiting to be evicted, 1 static pods will terminate with the node")).To(BeTrue())
+
+			// Expect podEvict to be enqueued for eviction then be successful
+			EventuallyExpectTerminating(ctx, env.Client, podEvict)
+			ExpectDeleted(ctx, env.Client, podEvict)
+
+			// Reconcile to delete node, the mirror pod shouldn't block the drain
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // DrainValidation, VolumeDetachment, InstanceTerminationInitiation
+			Expect(recorder.Calls(events.DrainedWithStaticPods)).To(Equal(1))
+			ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+			ExpectNotFound(ctx, env.Client, node)
+		})
+		It("should report mirror pods when they're the only pods left on the node", func() {
+			recorder.Reset()
+			pod := test.Pod(test.PodOptions{
+				NodeName: node.Name,
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{corev1.MirrorPodAnnotationKey: "mirror"},
+				},
+			})
+			ExpectApplied(ctx, env.Client, node, nodeClaim, pod)
+
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectObjectReconciled(ctx, env.Client, terminationController, node)
+
+			Expect(queue.Has(pod)).To(BeFalse())
+			Expect(recorder.Calls(events.DrainedWithStaticPods)).To(Equal(1))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Drained node, 1 static pods will terminate with the node (%s)", klog.KObj(pod)))).To(BeTrue())
+		})
 		It("should not delete nodes until all pods are deleted", func() {
 			pods := test.Pods(2, test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
 			ExpectApplied(ctx, env.Client, node, nodeClaim, pods[0], pods[1])
@@ -817,6 +913,169 @@ var _ = Describe("Termination", func() {
 			pod = ExpectExists(ctx, env.Client, pod)
 			Expect(pod.DeletionTimestamp.Time).To((BeTemporally("~", nodeTerminationTimestamp, 10*time.Second)))
 		})
+		Context("PreDrainHooks", func() {
+			BeforeEach(func() {
+				recorder.Reset()
+				nodeClaim.Annotations = map[string]string{
+					v1.PreDrainHookAnnotationPrefix + "service-discovery": "true",
+				}
+			})
+			It("should wait for pre-drain hooks before draining", func() {
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainHooks
+				ExpectExists(ctx, env.Client, node)
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreDrainHooksCompleted).IsUnknown()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreDrainHooksCompleted).Reason).To(Equal("AwaitingPreDrainHooks"))
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained)).To(BeNil())
+				Expect(recorder.Calls(events.AwaitingPreDrainHooks)).To(Equal(1))
+
+				delete(nodeClaim.Annotations, v1.PreDrainHookAnnotationPrefix+"service-discovery")
+				ExpectApplied(ctx, env.Client, nodeClaim)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // PreDrainHooks, Drain, VolumeDetachment, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+				ExpectNotFound(ctx, env.Client, node)
+
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreDrainHooksCompleted).IsTrue()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained).IsTrue()).To(BeTrue())
+			})
+			It("should drain once the pre-drain hook timeout elapses", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PreDrainHookTimeout: lo.ToPtr(time.Minute)}))
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainHooks
+				ExpectExists(ctx, env.Client, node)
+
+				fakeClock.Step(2 * time.Minute)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // PreDrainHooks, Drain, VolumeDetachment, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+				ExpectNotFound(ctx, env.Client, node)
+
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreDrainHooksCompleted).IsFalse()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreDrainHooksCompleted).Reason).To(Equal("PreDrainHooksTimedOut"))
+			})
+			It("should drain once the nodeclaim's termination grace period elapses", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PreDrainHookTimeout: lo.ToPtr(time.Duration(0))}))
+				nodeClaim.Annotations[v1.NodeClaimTerminationTimestampAnnotationKey] = fakeClock.Now().Add(2 * time.Hour).Format(time.RFC3339)
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainHooks
+				fakeClock.Step(time.Hour)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainHooks
+				ExpectExists(ctx, env.Client, node)
+
+				fakeClock.Step(2 * time.Hour)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // PreDrainHooks, Drain, VolumeDetachment, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+				ExpectNotFound(ctx, env.Client, node)
+			})
+		})
+		Context("PreDrainNotification", func() {
+			BeforeEach(func() {
+				recorder.Reset()
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PreDrainNotificationWindow: lo.ToPtr(time.Minute)}))
+			})
+			It("should taint the node as decommissioning and wait for the window before draining", func() {
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainNotification
+				node = ExpectNodeExists(ctx, env.Client, node.Name)
+				Expect(node.Spec.Taints).To(ContainElement(v1.DecommissioningNoScheduleTaint))
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained)).To(BeNil())
+				Expect(recorder.Calls(events.Decommissioning)).To(Equal(1))
+
+				fakeClock.Step(2 * time.Minute)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // PreDrainNotification, Drain, VolumeDetachment, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+				ExpectNotFound(ctx, env.Client, node)
+
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained).IsTrue()).To(BeTrue())
+			})
+			It("should not evict pods during the window", func() {
+				pod := test.Pod(test.PodOptions{NodeName: node.Name, ObjectMeta: metav1.ObjectMeta{OwnerReferences: defaultOwnerRefs}})
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool, pod)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainNotification
+				Expect(queue.Has(pod)).To(BeFalse())
+				ExpectExists(ctx, env.Client, pod)
+
+				fakeClock.Step(2 * time.Minute)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainNotification, DrainInitiation
+				Expect(queue.Has(pod)).To(BeTrue())
+			})
+			It("should drain once the nodeclaim's termination grace period elapses", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PreDrainNotificationWindow: lo.ToPtr(time.Hour)}))
+				nodeClaim.Annotations = map[string]string{
+					v1.NodeClaimTerminationTimestampAnnotationKey: fakeClock.Now().Add(time.Minute).Format(time.RFC3339),
+				}
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // PreDrainNotification
+				ExpectExists(ctx, env.Client, node)
+
+				fakeClock.Step(2 * time.Minute)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // PreDrainNotification, Drain, VolumeDetachment, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+				ExpectNotFound(ctx, env.Client, node)
+			})
+		})
+		Context("TerminationHolds", func() {
+			BeforeEach(func() {
+				recorder.Reset()
+				nodeClaim.Finalizers = append(nodeClaim.Finalizers, v1.TerminationHoldFinalizerPrefix+"backup")
+			})
+			It("should wait for termination holds before terminating the instance", func() {
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // Drain, VolumeDetachment, TerminationHolds
+				ExpectExists(ctx, env.Client, node)
+				Expect(cloudProvider.DeleteCalls).To(HaveLen(0))
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrained).IsTrue()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeTerminationHoldsReleased).IsUnknown()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeTerminationHoldsReleased).Reason).To(Equal("AwaitingTerminationHolds"))
+				Expect(recorder.Calls(events.AwaitingTerminationHolds)).To(Equal(1))
+
+				nodeClaim.Finalizers = lo.Without(nodeClaim.Finalizers, v1.TerminationHoldFinalizerPrefix+"backup")
+				ExpectApplied(ctx, env.Client, nodeClaim)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // TerminationHolds, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+				ExpectNotFound(ctx, env.Client, node)
+
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeTerminationHoldsReleased).IsTrue()).To(BeTrue())
+			})
+			It("should terminate the instance once the termination hold timeout elapses", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{TerminationHoldTimeout: lo.ToPtr(time.Minute)}))
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // Drain, VolumeDetachment, TerminationHolds
+				ExpectExists(ctx, env.Client, node)
+
+				fakeClock.Step(2 * time.Minute)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // TerminationHolds, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationValidation
+				ExpectNotFound(ctx, env.Client, node)
+
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeTerminationHoldsReleased).IsFalse()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeTerminationHoldsReleased).Reason).To(Equal("TerminationHoldsTimedOut"))
+				Expect(recorder.Calls(events.TerminationHoldsTimedOut)).To(Equal(1))
+			})
+		})
 		Context("VolumeAttachments", func() {
 			It("should wait for volume attachments", func() {
 				va := test.VolumeAttachment(test.VolumeAttachmentOptions{
@@ -883,6 +1142,49 @@ var _ = Describe("Termination", func() {
 				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationFinalization
 				ExpectNotFound(ctx, env.Client, node)
 			})
+			It("should wait for volume attachments until the volume detachment timeout elapses", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{VolumeDetachmentTimeout: lo.ToPtr(time.Minute)}))
+				recorder.Reset()
+				va := test.VolumeAttachment(test.VolumeAttachmentOptions{
+					NodeName:   node.Name,
+					VolumeName: "foo",
+				})
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool, va)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // Drain, VolumeDetachment
+				ExpectExists(ctx, env.Client, node)
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeVolumesDetached).Reason).To(Equal("AwaitingVolumeDetachment"))
+
+				fakeClock.Step(2 * time.Minute)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // VolumeDetachment, InstanceTerminationInitation
+				ExpectExists(ctx, env.Client, node)
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeVolumesDetached).IsFalse()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeVolumesDetached).Reason).To(Equal("VolumeDetachmentTimedOut"))
+				Expect(recorder.Calls(events.VolumeDetachmentTimedOut)).To(Equal(1))
+
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationFinalization
+				ExpectNotFound(ctx, env.Client, node)
+			})
+			It("should not wait for volume attachments when waiting is disabled", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{VolumeDetachmentWait: lo.ToPtr(false)}))
+				va := test.VolumeAttachment(test.VolumeAttachmentOptions{
+					NodeName:   node.Name,
+					VolumeName: "foo",
+				})
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool, va)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // Drain, VolumeDetachment, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationFinalization
+				ExpectNotFound(ctx, env.Client, node)
+
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeVolumesDetached).IsFalse()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeVolumesDetached).Reason).To(Equal("VolumeDetachmentWaitDisabled"))
+			})
 			It("should wait for volume attachments until the nodeclaim's termination grace period expires", func() {
 				va := test.VolumeAttachment(test.VolumeAttachmentOptions{
 					NodeName:   node.Name,
@@ -911,6 +1213,81 @@ var _ = Describe("Termination", func() {
 				ExpectNotFound(ctx, env.Client, node)
 			})
 		})
+		Context("PreTermination", func() {
+			var preTerminationController *termination.Controller
+
+			BeforeEach(func() {
+				preTerminationController = termination.NewController(fakeClock, env.Client, cloudProvider, terminator.NewTerminator(fakeClock, env.Client, queue, recorder), recorder, termination.WithPreTerminator(cloudProvider))
+				recorder.Reset()
+			})
+			It("should wait for the cloudprovider to prepare the instance before terminating it", func() {
+				cloudProvider.PreTerminatePending = true
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node)) // Drain, VolumeDetachment, PreTermination
+				ExpectExists(ctx, env.Client, node)
+				Expect(cloudProvider.PreTerminateCalls).To(HaveLen(1))
+				Expect(cloudProvider.DeleteCalls).To(HaveLen(0))
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreTerminated).IsUnknown()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreTerminated).Reason).To(Equal("AwaitingPreTermination"))
+
+				cloudProvider.PreTerminatePending = false
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node)) // PreTermination, InstanceTerminationInitiation
+				Expect(cloudProvider.PreTerminateCalls).To(HaveLen(2))
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreTerminated).IsTrue()).To(BeTrue())
+
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node)) // InstanceTerminationFinalization
+				ExpectNotFound(ctx, env.Client, node)
+				Expect(cloudProvider.PreTerminateCalls).To(HaveLen(2))
+			})
+			It("should terminate the instance once the pre-terminate timeout elapses", func() {
+				ctx = options.ToContext(ctx, test.Options(test.OptionsFields{PreTerminateTimeout: lo.ToPtr(time.Minute)}))
+				cloudProvider.PreTerminatePending = true
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node)) // Drain, VolumeDetachment, PreTermination
+				ExpectExists(ctx, env.Client, node)
+
+				fakeClock.Step(2 * time.Minute)
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node)) // PreTermination, InstanceTerminationInitiation
+				Expect(cloudProvider.PreTerminateCalls).To(HaveLen(1))
+				nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreTerminated).IsFalse()).To(BeTrue())
+				Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypePreTerminated).Reason).To(Equal("PreTerminationTimedOut"))
+				Expect(recorder.Calls(events.PreTerminationTimedOut)).To(Equal(1))
+
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node)) // InstanceTerminationFinalization
+				ExpectNotFound(ctx, env.Client, node)
+			})
+			It("should retry the pre-terminate hook when it fails", func() {
+				cloudProvider.NextPreTerminateErr = fmt.Errorf("deregistering instance")
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				Expect(ExpectObjectReconcileFailed(ctx, env.Client, preTerminationController, node)).To(HaveOccurred()) // Drain, VolumeDetachment, PreTermination
+				ExpectExists(ctx, env.Client, node)
+				Expect(cloudProvider.DeleteCalls).To(HaveLen(0))
+				Expect(recorder.Calls(events.FailedPreTermination)).To(Equal(1))
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node))    // PreTermination, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, preTerminationController, node)) // InstanceTerminationFinalization
+				ExpectNotFound(ctx, env.Client, node)
+				Expect(cloudProvider.PreTerminateCalls).To(HaveLen(2))
+			})
+			It("should not call the pre-terminate hook without a pre-terminator", func() {
+				ExpectApplied(ctx, env.Client, node, nodeClaim, nodePool)
+				Expect(env.Client.Delete(ctx, node)).To(Succeed())
+
+				ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node))    // Drain, VolumeDetachment, InstanceTerminationInitiation
+				ExpectNotRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // InstanceTerminationFinalization
+				ExpectNotFound(ctx, env.Client, node)
+				Expect(cloudProvider.PreTerminateCalls).To(HaveLen(0))
+			})
+		})
 	})
 	Context("Metrics", func() {
 		It("should fire the terminationSummary metric when deleting nodes", func() {
@@ -937,6 +1314,13 @@ var _ = Describe("Termination", func() {
 			Expect(ok).To(BeTrue())
 			Expect(lo.FromPtr(m.GetCounter().Value)).To(BeNumerically("==", 1))
 		})
+		It("should fire the drain duration histogram metric when deleting nodes", func() {
+			ExpectApplied(ctx, env.Client, node, nodeClaim)
+			Expect(env.Client.Delete(ctx, node)).To(Succeed())
+			node = ExpectNodeExists(ctx, env.Client, node.Name)
+			ExpectRequeued(ExpectObjectReconciled(ctx, env.Client, terminationController, node)) // Drain, VolumeDetachment, InstanceTerminationInitiation
+			ExpectMetricHistogramSampleCountValue("karpenter_nodes_drain_duration_seconds", 1, map[string]string{"nodepool": node.Labels[v1.NodePoolLabelKey]})
+		})
 		It("should fire the lifetime duration histogram metric when deleting nodes", func() {
 			ExpectApplied(ctx, env.Client, node, nodeClaim)
 			Expect(env.Client.Delete(ctx, node)).To(Succeed())
@@ -22,6 +22,7 @@ import (
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/events"
@@ -40,6 +41,16 @@ func EvictPod(pod *corev1.Pod, reason string) events.Event {
 	}
 }
 
+func DeletePodIgnoringPDB(pod *corev1.Pod, reason string) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.Disrupted,
+		Message:        "Deleted pod, its PDB is ignored by the NodePool: " + reason,
+		DedupeValues:   []string{pod.Name},
+	}
+}
+
 func DisruptPodDelete(pod *corev1.Pod, gracePeriodSeconds *int64, nodeGracePeriodTerminationTime *time.Time) events.Event {
 	return events.Event{
 		InvolvedObject: pod,
@@ -50,6 +61,56 @@ func DisruptPodDelete(pod *corev1.Pod, gracePeriodSeconds *int64, nodeGracePerio
 	}
 }
 
+func DeletePodAfterTerminationGracePeriod(pod *corev1.Pod, gracePeriodSeconds int64) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.Disrupted,
+		Message:        fmt.Sprintf("Deleting the pod with its %d seconds grace-period, the terminationGracePeriod of the node has elapsed. This bypasses the PDB of the pod and the do-not-disrupt annotation.", gracePeriodSeconds),
+		DedupeValues:   []string{pod.Name},
+	}
+}
+
+func ForceDeletePod(pod *corev1.Pod) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.ForceDeleted,
+		Message:        "Force deleting the pod, it didn't terminate within its grace-period after the terminationGracePeriod of the node elapsed",
+		DedupeValues:   []string{pod.Name},
+	}
+}
+
+func EvictionDeferred(pod *corev1.Pod, until time.Time) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.EvictionDeferred,
+		Message:        fmt.Sprintf("Deferring eviction until %s, pod has an active interactive session", until.Format(time.RFC3339)),
+		DedupeValues:   []string{pod.Name},
+	}
+}
+
+func EvictionStarted(pod *corev1.Pod) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.EvictionStarted,
+		Message:        "Evicting pod",
+		DedupeValues:   []string{pod.Name},
+	}
+}
+
+func EvictionBlocked(pod *corev1.Pod, pdbs []string) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.EvictionBlocked,
+		Message:        fmt.Sprintf("Eviction blocked by PodDisruptionBudgets (%s)", pretty.Slice(pdbs, 5)),
+		DedupeValues:   []string{pod.Name},
+	}
+}
+
 func NodeFailedToDrain(node *corev1.Node, err error) events.Event {
 	return events.Event{
 		InvolvedObject: node,
@@ -60,6 +121,17 @@ func NodeFailedToDrain(node *corev1.Node, err error) events.Event {
 	}
 }
 
+func NodeDrainedWithStaticPods(node *corev1.Node, staticPods []*corev1.Pod) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.DrainedWithStaticPods,
+		Message: fmt.Sprintf("Drained node, %d static pods will terminate with the node (%s)", len(staticPods),
+			pretty.Slice(lo.Map(staticPods, func(p *corev1.Pod, _ int) string { return klog.KObj(p).String() }), 5)),
+		DedupeValues: []string{node.Name},
+	}
+}
+
 func NodeAwaitingVolumeDetachmentEvent(node *corev1.Node, volumeAttachments ...*storagev1.VolumeAttachment) events.Event {
 	return events.Event{
 		InvolvedObject: node,
@@ -75,6 +147,82 @@ func NodeAwaitingVolumeDetachmentEvent(node *corev1.Node, volumeAttachments ...*
 	}
 }
 
+func NodeAwaitingPreDrainHooks(node *corev1.Node, hooks []string) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.AwaitingPreDrainHooks,
+		Message:        fmt.Sprintf("Awaiting pre-drain hooks (%s)", pretty.Slice(hooks, 5)),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
+func NodeDecommissioning(node *corev1.Node, drainTime time.Time) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.Decommissioning,
+		Message:        fmt.Sprintf("Node is decommissioning, pods will be evicted from %s", drainTime.Format(time.RFC3339)),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
+func NodeAwaitingTerminationHolds(node *corev1.Node, holds []string) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.AwaitingTerminationHolds,
+		Message:        fmt.Sprintf("Awaiting termination holds (%s)", pretty.Slice(holds, 5)),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
+func NodeTerminationHoldsTimedOut(node *corev1.Node, holds []string) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.TerminationHoldsTimedOut,
+		Message:        fmt.Sprintf("Terminating the instance, termination holds weren't released in time (%s)", pretty.Slice(holds, 5)),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
+func NodeVolumeDetachmentTimedOut(node *corev1.Node, timeout time.Duration, volumeAttachments ...*storagev1.VolumeAttachment) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.VolumeDetachmentTimedOut,
+		Message: fmt.Sprintf(
+			"Terminating the instance, bound volumeattachments (%s) weren't deleted within %s",
+			pretty.Slice(lo.Map(volumeAttachments, func(va *storagev1.VolumeAttachment, _ int) string {
+				return va.Name
+			}), 5),
+			timeout,
+		),
+		DedupeValues: []string{node.Name},
+	}
+}
+
+func NodeFailedToPreTerminate(node *corev1.Node, err error) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.FailedPreTermination,
+		Message:        fmt.Sprintf("Failed to prepare the instance for termination, %s", err),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
+func NodePreTerminationTimedOut(node *corev1.Node) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.PreTerminationTimedOut,
+		Message:        "Terminating the instance, the cloud provider didn't finish preparing it for termination in time",
+		DedupeValues:   []string{node.Name},
+	}
+}
+
 func NodeTerminationGracePeriodExpiring(node *corev1.Node, terminationTime string) events.Event {
 	return events.Event{
 		InvolvedObject: node,
@@ -85,6 +233,26 @@ func NodeTerminationGracePeriodExpiring(node *corev1.Node, terminationTime strin
 	}
 }
 
+func NodeTerminationGracePeriodElapsed(node *corev1.Node, terminationTime time.Time) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.TerminationGracePeriodElapsed,
+		Message:        fmt.Sprintf("Forcibly deleting pods, the terminationGracePeriod elapsed at %s", terminationTime.Format(time.RFC3339)),
+		DedupeValues:   []string{node.Name},
+	}
+}
+
+func NodeForcedDrainCompleted(node *corev1.Node) events.Event {
+	return events.Event{
+		InvolvedObject: node,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.ForcedDrainCompleted,
+		Message:        "Deleted all pods after the terminationGracePeriod elapsed, terminating the instance",
+		DedupeValues:   []string{node.Name},
+	}
+}
+
 func NodeClaimTerminationGracePeriodExpiring(nodeClaim *v1.NodeClaim, terminationTime string) events.Event {
 	return events.Event{
 		InvolvedObject: nodeClaim,
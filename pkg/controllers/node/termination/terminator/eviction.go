@@ -30,6 +30,7 @@ import (
 	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/workqueue"
@@ -47,6 +48,7 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
@@ -198,10 +200,23 @@ func (q *Queue) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Resul
 		// Regardless of whether the PDBs allow disruptions, Kubernetes doesn't support multiple PDBs on a single pod:
 		// https://github.com/kubernetes/kubernetes/blob/84cacae7046df93c1f6f8ea97c912d948e1ad06a/pkg/registry/core/pod/storage/eviction.go#L226
 		if apierrors.IsTooManyRequests(err) || message == multiplePodDisruptionBudgetsError {
+			ignored, err2 := ignoresPodDisruptionBudgets(ctx, q.kubeClient, pod)
+			if err2 != nil {
+				return reconcile.Result{}, err2
+			}
+			if ignored {
+				return q.deletePod(ctx, pod)
+			}
 			node, err2 := podutils.NodeForPod(ctx, q.kubeClient, pod)
 			if err2 != nil {
 				return reconcile.Result{}, err2
 			}
+			pdbs, err2 := podDisruptionBudgetsForPod(ctx, q.kubeClient, pod)
+			if err2 != nil {
+				return reconcile.Result{}, err2
+			}
+			q.recorder.Publish(terminatorevents.EvictionBlocked(pod, lo.Map(pdbs, func(pdb policyv1.PodDisruptionBudget, _ int) string { return pdb.Name })))
+			PodsEvictionBlockedTotal.Inc(map[string]string{metrics.NodePoolLabel: node.Labels[v1.NodePoolLabelKey]})
 			errorMessage := lo.Ternary(message == multiplePodDisruptionBudgetsError, "eviction does not support multiple PDBs", "evicting pod violates a PDB")
 			q.recorder.Publish(terminatorevents.NodeFailedToDrain(node, serrors.Wrap(errors.New(errorMessage), "Pod", klog.KRef(pod.Namespace, pod.Name))))
 			return reconcile.Result{Requeue: true}, nil
@@ -220,6 +235,66 @@ func (q *Queue) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Resul
 	return reconcile.Result{}, nil
 }
 
+// deletePod deletes a pod whose PDBs are ignored by the NodePool of its node, rather than waiting for the PDBs to allow
+// its eviction
+func (q *Queue) deletePod(ctx context.Context, pod *corev1.Pod) (reconcile.Result, error) {
+	if err := q.kubeClient.Delete(ctx, pod, &client.DeleteOptions{Preconditions: &metav1.Preconditions{UID: lo.ToPtr(pod.UID)}}); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+		return reconcile.Result{}, fmt.Errorf("deleting pod, %w", err)
+	}
+	reason := evictionReason(ctx, pod, q.kubeClient)
+	q.recorder.Publish(terminatorevents.DeletePodIgnoringPDB(pod, reason))
+	PodsDrainedTotal.Inc(map[string]string{ReasonLabel: reason})
+
+	q.Lock()
+	defer q.Unlock()
+	q.set.Delete(NewQueueKey(pod))
+	return reconcile.Result{}, nil
+}
+
+// ignoresPodDisruptionBudgets returns true if the pod's node is drifted and every PDB of the pod is ignored by the
+// NodePool of the node
+func ignoresPodDisruptionBudgets(ctx context.Context, kubeClient client.Client, pod *corev1.Pod) (bool, error) {
+	node, err := podutils.NodeForPod(ctx, kubeClient, pod)
+	if err != nil {
+		return false, err
+	}
+	nodeClaim, err := nodeutils.NodeClaimForNode(ctx, kubeClient, node)
+	if err != nil {
+		// Nodes without a single NodeClaim aren't disrupted by Karpenter, so there's no NodePool to ignore PDBs
+		return false, nodeutils.IgnoreDuplicateNodeClaimError(nodeutils.IgnoreNodeClaimNotFoundError(err))
+	}
+	// Drift is the only eventual disruption reason
+	if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason); !cond.IsTrue() || cond.Reason != string(v1.DisruptionReasonDrifted) {
+		return false, nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: nodeClaim.Labels[v1.NodePoolLabelKey]}, nodePool); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if len(nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets) == 0 {
+		return false, nil
+	}
+	matching, err := podDisruptionBudgetsForPod(ctx, kubeClient, pod)
+	if err != nil {
+		return false, err
+	}
+	return len(matching) > 0 && lo.EveryBy(matching, func(pdb policyv1.PodDisruptionBudget) bool {
+		return nodePool.Spec.Disruption.IgnoresPodDisruptionBudget(&pdb)
+	}), nil
+}
+
+// podDisruptionBudgetsForPod returns the PDBs that select the pod
+func podDisruptionBudgetsForPod(ctx context.Context, kubeClient client.Client, pod *corev1.Pod) ([]policyv1.PodDisruptionBudget, error) {
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	if err := kubeClient.List(ctx, pdbs, client.InNamespace(pod.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing pod disruption budgets, %w", err)
+	}
+	return lo.Filter(pdbs.Items, func(pdb policyv1.PodDisruptionBudget, _ int) bool {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		return err == nil && selector.Matches(labels.Set(pod.Labels))
+	}), nil
+}
+
 func evictionReason(ctx context.Context, pod *corev1.Pod, kubeClient client.Client) string {
 	node, err := podutils.NodeForPod(ctx, kubeClient, pod)
 	if err != nil {
@@ -52,3 +52,14 @@ var PodsDrainedTotal = opmetrics.NewPrometheusCounter(
 	},
 	[]string{ReasonLabel},
 )
+
+var PodsEvictionBlockedTotal = opmetrics.NewPrometheusCounter(
+	crmetrics.Registry,
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.PodSubsystem,
+		Name:      "eviction_blocked_total",
+		Help:      "The total number of pod evictions that were blocked by PodDisruptionBudgets during node termination, labeled by the NodePool of the node",
+	},
+	[]string{metrics.NodePoolLabel},
+)
@@ -0,0 +1,88 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminator
+
+import (
+	"context"
+	"time"
+
+	"github.com/awslabs/operatorpkg/option"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/clock"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// SessionProbe reports whether a pod has an active interactive session, e.g. an exec or attach session or a running
+// debug container, so that draining can defer its eviction for the interactive-session-grace-period.
+type SessionProbe interface {
+	HasActiveSession(ctx context.Context, pod *corev1.Pod) bool
+}
+
+// AnnotationSessionProbe is the default SessionProbe. A pod has an active session while it's running an ephemeral
+// (debug) container, or while the time in its karpenter.sh/interactive-session-until annotation is in the future.
+// Exec proxies and admission webhooks can implement the annotation protocol by extending the annotation for as
+// long as a session is open.
+type AnnotationSessionProbe struct {
+	clock clock.Clock
+}
+
+func NewAnnotationSessionProbe(clk clock.Clock) *AnnotationSessionProbe {
+	return &AnnotationSessionProbe{clock: clk}
+}
+
+func (p *AnnotationSessionProbe) HasActiveSession(_ context.Context, pod *corev1.Pod) bool {
+	if lo.ContainsBy(pod.Status.EphemeralContainerStatuses, func(s corev1.ContainerStatus) bool { return s.State.Running != nil }) {
+		return true
+	}
+	until, err := time.Parse(time.RFC3339, pod.Annotations[v1.InteractiveSessionUntilAnnotationKey])
+	if err != nil {
+		return false
+	}
+	return p.clock.Now().Before(until)
+}
+
+type TerminatorOptions struct {
+	sessionProbe SessionProbe
+}
+
+// WithSessionProbe overrides the probe used to detect pods with active interactive sessions
+func WithSessionProbe(probe SessionProbe) option.Function[TerminatorOptions] {
+	return func(o *TerminatorOptions) {
+		o.sessionProbe = probe
+	}
+}
+
+// sessionDeferralEnd returns the time until which the eviction of pods with active interactive sessions is deferred
+// on the node, or nil if sessions aren't protected. The window starts when the node begins terminating and never
+// extends past the node's termination grace period, after which its pods are deleted regardless.
+func (t *Terminator) sessionDeferralEnd(ctx context.Context, node *corev1.Node, nodeGracePeriodExpirationTime *time.Time) *time.Time {
+	gracePeriod := options.FromContext(ctx).InteractiveSessionGracePeriod
+	if gracePeriod == 0 || node.DeletionTimestamp == nil {
+		return nil
+	}
+	end := node.DeletionTimestamp.Add(gracePeriod)
+	if nodeGracePeriodExpirationTime != nil && nodeGracePeriodExpirationTime.Before(end) {
+		end = *nodeGracePeriodExpirationTime
+	}
+	if !t.clock.Now().Before(end) {
+		return nil
+	}
+	return &end
+}
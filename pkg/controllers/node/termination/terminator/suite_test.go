@@ -18,6 +18,7 @@ package terminator_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -36,6 +37,7 @@ import (
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -78,6 +80,7 @@ var _ = AfterSuite(func() {
 
 var _ = BeforeEach(func() {
 	recorder.Reset() // Reset the events that we captured during the run
+	fakeClock.SetTime(time.Now())
 	// Shut down the queue and restart it to ensure no races
 	*queue = lo.FromPtr(terminator.NewQueue(env.Client, recorder))
 })
@@ -103,6 +106,7 @@ var _ = Describe("Eviction/Queue", func() {
 
 		terminator.NodesEvictionRequestsTotal.Reset()
 		terminator.PodsDrainedTotal.Reset()
+		terminator.PodsEvictionBlockedTotal.Reset()
 	})
 
 	Context("Eviction API", func() {
@@ -141,10 +145,12 @@ var _ = Describe("Eviction/Queue", func() {
 			//nolint:staticcheck
 			Expect(result.Requeue).To(BeTrue())
 			ExpectMetricCounterValue(terminator.NodesEvictionRequestsTotal, 1, map[string]string{terminator.CodeLabel: "429"})
-			e := recorder.Events()
-			Expect(e).To(HaveLen(1))
-			Expect(e[0].Reason).To(Equal(events.FailedDraining))
-			Expect(e[0].Message).To(ContainSubstring("evicting pod violates a PDB"))
+			Expect(recorder.Calls(events.FailedDraining)).To(Equal(1))
+			failed, _ := lo.Find(recorder.Events(), func(e events.Event) bool { return e.Reason == events.FailedDraining })
+			Expect(failed.Message).To(ContainSubstring("evicting pod violates a PDB"))
+			Expect(recorder.Calls(events.EvictionBlocked)).To(Equal(1))
+			Expect(recorder.DetectedEvent(fmt.Sprintf("Eviction blocked by PodDisruptionBudgets (%s)", pdb.Name))).To(BeTrue())
+			ExpectMetricCounterValue(terminator.PodsEvictionBlockedTotal, 1, map[string]string{metrics.NodePoolLabel: ""})
 		})
 		It("should return a NodeDrainError event when two PDBs refer to the same pod", func() {
 			pdb2 := test.PodDisruptionBudget(test.PDBOptions{
@@ -158,10 +164,55 @@ var _ = Describe("Eviction/Queue", func() {
 			//nolint:staticcheck
 			Expect(result.Requeue).To(BeTrue())
 			ExpectMetricCounterValue(terminator.NodesEvictionRequestsTotal, 1, map[string]string{terminator.CodeLabel: "500"})
-			e := recorder.Events()
-			Expect(e).To(HaveLen(1))
-			Expect(e[0].Reason).To(Equal(events.FailedDraining))
-			Expect(e[0].Message).To(ContainSubstring("eviction does not support multiple PDBs"))
+			Expect(recorder.Calls(events.FailedDraining)).To(Equal(1))
+			failed, _ := lo.Find(recorder.Events(), func(e events.Event) bool { return e.Reason == events.FailedDraining })
+			Expect(failed.Message).To(ContainSubstring("eviction does not support multiple PDBs"))
+			Expect(recorder.Calls(events.EvictionBlocked)).To(Equal(1))
+		})
+		Context("Ignored PDBs", func() {
+			var nodePool *v1.NodePool
+			var nodeClaim *v1.NodeClaim
+			BeforeEach(func() {
+				nodePool = test.NodePool()
+				nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets = []v1.PodDisruptionBudgetSelector{{Namespace: pdb.Namespace, Name: pdb.Name}}
+				nodeClaim = test.NodeClaim(v1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}},
+					Status:     v1.NodeClaimStatus{ProviderID: node.Spec.ProviderID},
+				})
+				nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, string(v1.DisruptionReasonDrifted), string(v1.DisruptionReasonDrifted))
+			})
+			It("should delete the pod when the blocking PDB is ignored by the NodePool of a drifted node", func() {
+				ExpectApplied(ctx, env.Client, nodePool, nodeClaim, pdb, pod, node)
+				ExpectManualBinding(ctx, env.Client, pod, node)
+				queue.Add(pod)
+				ExpectObjectReconciled(ctx, env.Client, queue, pod)
+
+				Expect(queue.Has(pod)).To(BeFalse())
+				Expect(recorder.Calls(events.Disrupted)).To(Equal(1))
+				Expect(recorder.Calls(events.FailedDraining)).To(Equal(0))
+			})
+			It("should not delete the pod when the node isn't drifted", func() {
+				nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, string(v1.DisruptionReasonUnderutilized), string(v1.DisruptionReasonUnderutilized))
+				ExpectApplied(ctx, env.Client, nodePool, nodeClaim, pdb, pod, node)
+				ExpectManualBinding(ctx, env.Client, pod, node)
+				queue.Add(pod)
+				result := ExpectObjectReconciled(ctx, env.Client, queue, pod)
+				//nolint:staticcheck
+				Expect(result.Requeue).To(BeTrue())
+				Expect(queue.Has(pod)).To(BeTrue())
+				Expect(recorder.Calls(events.FailedDraining)).To(Equal(1))
+			})
+			It("should not delete the pod when the blocking PDB isn't ignored", func() {
+				nodePool.Spec.Disruption.IgnoredPodDisruptionBudgets = []v1.PodDisruptionBudgetSelector{{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"ignored": "true"}}}}
+				ExpectApplied(ctx, env.Client, nodePool, nodeClaim, pdb, pod, node)
+				ExpectManualBinding(ctx, env.Client, pod, node)
+				queue.Add(pod)
+				result := ExpectObjectReconciled(ctx, env.Client, queue, pod)
+				//nolint:staticcheck
+				Expect(result.Requeue).To(BeTrue())
+				Expect(queue.Has(pod)).To(BeTrue())
+				Expect(recorder.Calls(events.FailedDraining)).To(Equal(1))
+			})
 		})
 		It("should ensure that calling Evict() is valid while making Add() calls", func() {
 			// Ensure that we add enough pods to the queue while we are pulling items off of the queue (enough to trigger a DATA RACE)
@@ -247,5 +298,177 @@ var _ = Describe("Eviction/Queue", func() {
 			ExpectNotFound(ctx, env.Client, pod)
 			Expect(recorder.Calls(events.Disrupted)).To(Equal(1))
 		})
+		It("should not delete a pod with its forced termination grace period still remaining before nodeTerminationTime", func() {
+			pod.Spec.TerminationGracePeriodSeconds = lo.ToPtr[int64](120)
+			pod.Annotations = map[string]string{v1.ForcedTerminationGracePeriodAnnotationKey: "30s"}
+			ExpectApplied(ctx, env.Client, pod)
+
+			nodeTerminationTime := time.Now().Add(time.Minute * 1)
+			Expect(terminatorInstance.DeleteExpiringPods(ctx, []*corev1.Pod{pod}, &nodeTerminationTime)).To(Succeed())
+			ExpectExists(ctx, env.Client, pod)
+			Expect(recorder.Calls(events.Disrupted)).To(Equal(0))
+		})
+		It("should delete a pod with less than its forced termination grace period remaining before nodeTerminationTime", func() {
+			pod.Spec.TerminationGracePeriodSeconds = lo.ToPtr[int64](3600)
+			pod.Annotations = map[string]string{v1.ForcedTerminationGracePeriodAnnotationKey: "30s"}
+			ExpectApplied(ctx, env.Client, pod)
+
+			nodeTerminationTime := time.Now().Add(time.Second * 20)
+			Expect(terminatorInstance.DeleteExpiringPods(ctx, []*corev1.Pod{pod}, &nodeTerminationTime)).To(Succeed())
+			ExpectNotFound(ctx, env.Client, pod)
+			Expect(recorder.Calls(events.Disrupted)).To(Equal(1))
+		})
+		It("should ignore an invalid forced termination grace period", func() {
+			pod.Spec.TerminationGracePeriodSeconds = lo.ToPtr[int64](120)
+			pod.Annotations = map[string]string{v1.ForcedTerminationGracePeriodAnnotationKey: "soon"}
+			ExpectApplied(ctx, env.Client, pod)
+
+			nodeTerminationTime := time.Now().Add(time.Minute * 1)
+			Expect(terminatorInstance.DeleteExpiringPods(ctx, []*corev1.Pod{pod}, &nodeTerminationTime)).To(Succeed())
+			ExpectNotFound(ctx, env.Client, pod)
+			Expect(recorder.Calls(events.Disrupted)).To(Equal(1))
+		})
+	})
+
+	Context("Forced Drain", func() {
+		It("should escalate the drain once the node's terminationGracePeriod has elapsed", func() {
+			pod.Spec.TerminationGracePeriodSeconds = lo.ToPtr[int64](60)
+			ExpectApplied(ctx, env.Client, pdb, pod, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			nodeTerminationTime := fakeClock.Now().Add(-time.Second)
+
+			// The pod is deleted with its own grace period, even though its PDB blocks its eviction
+			Expect(terminator.IsNodeDrainError(terminatorInstance.Drain(ctx, node, &nodeTerminationTime))).To(BeTrue())
+			pod = ExpectExists(ctx, env.Client, pod)
+			Expect(pod.DeletionTimestamp.IsZero()).To(BeFalse())
+			Expect(lo.FromPtr(pod.DeletionGracePeriodSeconds)).To(BeNumerically("==", 60))
+			Expect(recorder.Calls(events.TerminationGracePeriodElapsed)).To(Equal(1))
+			Expect(recorder.Calls(events.Disrupted)).To(Equal(1))
+
+			// The pod isn't force deleted while it's within its grace period
+			Expect(terminator.IsNodeDrainError(terminatorInstance.Drain(ctx, node, &nodeTerminationTime))).To(BeTrue())
+			ExpectExists(ctx, env.Client, pod)
+			Expect(recorder.Calls(events.ForceDeleted)).To(Equal(0))
+
+			// The pod is force deleted once its grace period has passed
+			fakeClock.Step(70 * time.Second)
+			Expect(terminator.IsNodeDrainError(terminatorInstance.Drain(ctx, node, &nodeTerminationTime))).To(BeTrue())
+			ExpectNotFound(ctx, env.Client, pod)
+			Expect(recorder.Calls(events.ForceDeleted)).To(Equal(1))
+
+			// The drain completes once no pods remain
+			Expect(terminatorInstance.Drain(ctx, node, &nodeTerminationTime)).To(Succeed())
+			Expect(recorder.Calls(events.ForcedDrainCompleted)).To(Equal(1))
+		})
+		It("should cap the grace period of pods with a forced termination grace period", func() {
+			pod.Spec.TerminationGracePeriodSeconds = lo.ToPtr[int64](600)
+			pod.Annotations = map[string]string{v1.ForcedTerminationGracePeriodAnnotationKey: "10s"}
+			ExpectApplied(ctx, env.Client, pod, node)
+			ExpectManualBinding(ctx, env.Client, pod, node)
+			nodeTerminationTime := fakeClock.Now().Add(-time.Second)
+
+			Expect(terminator.IsNodeDrainError(terminatorInstance.Drain(ctx, node, &nodeTerminationTime))).To(BeTrue())
+			pod = ExpectExists(ctx, env.Client, pod)
+			Expect(lo.FromPtr(pod.DeletionGracePeriodSeconds)).To(BeNumerically("==", 10))
+		})
+	})
+
+	Context("Eviction Pacing", func() {
+		var pods []*corev1.Pod
+		BeforeEach(func() {
+			pods = test.Pods(3, test.PodOptions{NodeName: node.Name})
+			ExpectApplied(ctx, env.Client, node)
+			for _, p := range pods {
+				ExpectApplied(ctx, env.Client, p)
+			}
+			node.DeletionTimestamp = &metav1.Time{Time: fakeClock.Now()}
+		})
+		It("should only queue up to the eviction concurrency of the node", func() {
+			pacedCtx := options.ToContext(ctx, test.Options(test.OptionsFields{EvictionConcurrencyPerNode: lo.ToPtr(2)}))
+			Expect(terminatorInstance.Drain(pacedCtx, node, nil)).ToNot(Succeed())
+			Expect(lo.CountBy(pods, queue.Has)).To(Equal(2))
+
+			// Pods that are already queued keep counting against the concurrency
+			Expect(terminatorInstance.Drain(pacedCtx, node, nil)).ToNot(Succeed())
+			Expect(lo.CountBy(pods, queue.Has)).To(Equal(2))
+		})
+		It("should queue all pods when the eviction concurrency is disabled", func() {
+			Expect(terminatorInstance.Drain(ctx, node, nil)).ToNot(Succeed())
+			Expect(lo.CountBy(pods, queue.Has)).To(Equal(3))
+			Expect(recorder.Calls(events.EvictionStarted)).To(Equal(3))
+		})
+		It("should wait for the eviction interval of the node between evictions", func() {
+			pacedCtx := options.ToContext(ctx, test.Options(test.OptionsFields{EvictionIntervalPerNode: lo.ToPtr(time.Minute)}))
+			Expect(terminatorInstance.Drain(pacedCtx, node, nil)).ToNot(Succeed())
+			Expect(lo.CountBy(pods, queue.Has)).To(Equal(1))
+
+			fakeClock.Step(30 * time.Second)
+			Expect(terminatorInstance.Drain(pacedCtx, node, nil)).ToNot(Succeed())
+			Expect(lo.CountBy(pods, queue.Has)).To(Equal(1))
+
+			fakeClock.Step(time.Minute)
+			Expect(terminatorInstance.Drain(pacedCtx, node, nil)).ToNot(Succeed())
+			Expect(lo.CountBy(pods, queue.Has)).To(Equal(2))
+		})
+	})
+
+	Context("Interactive Sessions", func() {
+		var sessionCtx context.Context
+		BeforeEach(func() {
+			sessionCtx = options.ToContext(ctx, test.Options(test.OptionsFields{InteractiveSessionGracePeriod: lo.ToPtr(time.Hour)}))
+		})
+		It("should defer the eviction of a pod with an active interactive session", func() {
+			pod.Annotations = map[string]string{v1.InteractiveSessionUntilAnnotationKey: fakeClock.Now().Add(10 * time.Minute).Format(time.RFC3339)}
+			ExpectApplied(ctx, env.Client, pod, node)
+			node.DeletionTimestamp = &metav1.Time{Time: fakeClock.Now()}
+
+			err := terminatorInstance.Drain(sessionCtx, node, nil)
+			Expect(terminator.IsNodeDrainError(err)).To(BeTrue())
+			Expect(queue.Has(pod)).To(BeFalse())
+			Expect(recorder.Calls(events.EvictionDeferred)).To(Equal(1))
+		})
+		It("should evict a pod once its interactive session has ended", func() {
+			pod.Annotations = map[string]string{v1.InteractiveSessionUntilAnnotationKey: fakeClock.Now().Add(-time.Minute).Format(time.RFC3339)}
+			ExpectApplied(ctx, env.Client, pod, node)
+			node.DeletionTimestamp = &metav1.Time{Time: fakeClock.Now()}
+
+			err := terminatorInstance.Drain(sessionCtx, node, nil)
+			Expect(terminator.IsNodeDrainError(err)).To(BeTrue())
+			Expect(queue.Has(pod)).To(BeTrue())
+			Expect(recorder.Calls(events.EvictionDeferred)).To(Equal(0))
+		})
+		It("should evict a pod with an active interactive session once the grace period has elapsed", func() {
+			pod.Annotations = map[string]string{v1.InteractiveSessionUntilAnnotationKey: fakeClock.Now().Add(10 * time.Minute).Format(time.RFC3339)}
+			ExpectApplied(ctx, env.Client, pod, node)
+			node.DeletionTimestamp = &metav1.Time{Time: fakeClock.Now().Add(-2 * time.Hour)}
+
+			Expect(terminatorInstance.Drain(sessionCtx, node, nil)).ToNot(Succeed())
+			Expect(queue.Has(pod)).To(BeTrue())
+		})
+		It("should not extend the deferral past the node's termination grace period", func() {
+			pod.Annotations = map[string]string{v1.InteractiveSessionUntilAnnotationKey: fakeClock.Now().Add(10 * time.Minute).Format(time.RFC3339)}
+			ExpectApplied(ctx, env.Client, pod, node)
+			node.DeletionTimestamp = &metav1.Time{Time: fakeClock.Now().Add(-time.Minute)}
+
+			Expect(terminatorInstance.Drain(sessionCtx, node, lo.ToPtr(fakeClock.Now().Add(-time.Second)))).ToNot(Succeed())
+			Expect(queue.Has(pod)).To(BeTrue())
+		})
+		It("should not defer evictions when the grace period is disabled", func() {
+			pod.Annotations = map[string]string{v1.InteractiveSessionUntilAnnotationKey: fakeClock.Now().Add(10 * time.Minute).Format(time.RFC3339)}
+			ExpectApplied(ctx, env.Client, pod, node)
+			node.DeletionTimestamp = &metav1.Time{Time: fakeClock.Now()}
+
+			Expect(terminatorInstance.Drain(ctx, node, nil)).ToNot(Succeed())
+			Expect(queue.Has(pod)).To(BeTrue())
+		})
+		It("should consider a pod running a debug container to have an active session", func() {
+			probe := terminator.NewAnnotationSessionProbe(fakeClock)
+			Expect(probe.HasActiveSession(ctx, pod)).To(BeFalse())
+			pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "debugger",
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}}
+			Expect(probe.HasActiveSession(ctx, pod)).To(BeTrue())
+		})
 	})
 })
@@ -18,19 +18,27 @@ package terminator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/awslabs/operatorpkg/option"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	terminatorevents "sigs.k8s.io/karpenter/pkg/controllers/node/termination/terminator/events"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
 )
@@ -40,14 +48,23 @@ type Terminator struct {
 	kubeClient    client.Client
 	evictionQueue *Queue
 	recorder      events.Recorder
+	sessionProbe  SessionProbe
+
+	mu sync.Mutex
+	// lastEvictionTimes tracks when the last pod of each draining node was queued for eviction, to pace evictions
+	lastEvictionTimes map[string]time.Time
 }
 
-func NewTerminator(clk clock.Clock, kubeClient client.Client, eq *Queue, recorder events.Recorder) *Terminator {
+func NewTerminator(clk clock.Clock, kubeClient client.Client, eq *Queue, recorder events.Recorder, opts ...option.Function[TerminatorOptions]) *Terminator {
+	o := option.Resolve(opts...)
 	return &Terminator{
 		clock:         clk,
 		kubeClient:    kubeClient,
 		evictionQueue: eq,
 		recorder:      recorder,
+		sessionProbe:  lo.Ternary[SessionProbe](o.sessionProbe != nil, o.sessionProbe, NewAnnotationSessionProbe(clk)),
+
+		lastEvictionTimes: map[string]time.Time{},
 	}
 }
 
@@ -98,6 +115,9 @@ func (t *Terminator) Drain(ctx context.Context, node *corev1.Node, nodeGracePeri
 	if err != nil {
 		return fmt.Errorf("listing pods on node, %w", err)
 	}
+	if nodeGracePeriodExpirationTime != nil && t.clock.Now().After(*nodeGracePeriodExpirationTime) {
+		return t.forceDrain(ctx, node, pods, *nodeGracePeriodExpirationTime)
+	}
 	podsToDelete := lo.Filter(pods, func(p *corev1.Pod, _ int) bool {
 		return podutil.IsWaitingEviction(p, t.clock) && (!podutil.IsTerminating(p) || podutil.IsPodEligibleForForcedEviction(p, nodeGracePeriodExpirationTime))
 	})
@@ -106,21 +126,137 @@ func (t *Terminator) Drain(ctx context.Context, node *corev1.Node, nodeGracePeri
 	}
 	// Monitor pods in pod groups that either haven't been evicted or are actively evicting
 	podGroups := t.groupPodsByPriority(lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsWaitingEviction(p, t.clock) }))
+	// Static pods are never evicted, they will terminate along with the node
+	staticPods := lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsStatic(p) && !podutil.IsTerminal(p) })
+	deferralEnd := t.sessionDeferralEnd(ctx, node, nodeGracePeriodExpirationTime)
 	for _, group := range podGroups {
 		if len(group) > 0 {
 			// Only add pods to the eviction queue that haven't been evicted yet
-			t.evictionQueue.Add(lo.Filter(group, func(p *corev1.Pod, _ int) bool { return podutil.IsEvictable(p) })...)
-			return NewNodeDrainError(fmt.Errorf("%d pods are waiting to be evicted", lo.SumBy(podGroups, func(pods []*corev1.Pod) int { return len(pods) })))
+			evictable := lo.Filter(group, func(p *corev1.Pod, _ int) bool { return podutil.IsEvictable(p, t.clock) })
+			// Pods with active interactive sessions keep the group, and therefore the drain, waiting until the session
+			// ends or the deferral window closes
+			var deferred []*corev1.Pod
+			if deferralEnd != nil {
+				evictable, deferred = lo.FilterReject(evictable, func(p *corev1.Pod, _ int) bool { return !t.sessionProbe.HasActiveSession(ctx, p) })
+			}
+			evictable = t.throttleEvictions(ctx, node, pods, evictable)
+			for _, p := range evictable {
+				if !t.evictionQueue.Has(p) {
+					t.recorder.Publish(terminatorevents.EvictionStarted(p))
+				}
+			}
+			t.evictionQueue.Add(evictable...)
+			for _, p := range deferred {
+				t.recorder.Publish(terminatorevents.EvictionDeferred(p, *deferralEnd))
+			}
+			msg := fmt.Sprintf("%d pods are waiting to be evicted", lo.SumBy(podGroups, func(pods []*corev1.Pod) int { return len(pods) }))
+			if len(deferred) > 0 {
+				msg = fmt.Sprintf("%s, %d pods with active interactive sessions are deferred until %s", msg, len(deferred), deferralEnd.Format(time.RFC3339))
+			}
+			if len(staticPods) > 0 {
+				msg = fmt.Sprintf("%s, %d static pods will terminate with the node", msg, len(staticPods))
+			}
+			return NewNodeDrainError(errors.New(msg))
 		}
 	}
+	// The drain is complete once only static pods remain, but they're still reported since they'll terminate with the node
+	if len(staticPods) > 0 {
+		t.recorder.Publish(terminatorevents.NodeDrainedWithStaticPods(node, staticPods))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.lastEvictionTimes, node.Name)
 	return nil
 }
 
+// forceDrain escalates the drain of a node whose terminationGracePeriod has elapsed:
+//  1. Pods that haven't been deleted yet, e.g. because their PDBs blocked their eviction, are deleted with their own
+//     grace period, regardless of their PDBs and the do-not-disrupt annotation.
+//  2. Pods that haven't terminated once their grace period has passed are force deleted.
+//  3. Once no pods remain, the drain completes so that the instance is terminated.
+func (t *Terminator) forceDrain(ctx context.Context, node *corev1.Node, pods []*corev1.Pod, nodeGracePeriodExpirationTime time.Time) error {
+	remaining := lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutil.IsWaitingEviction(p, t.clock) })
+	if len(remaining) == 0 {
+		t.recorder.Publish(terminatorevents.NodeForcedDrainCompleted(node))
+		return nil
+	}
+	t.recorder.Publish(terminatorevents.NodeTerminationGracePeriodElapsed(node, nodeGracePeriodExpirationTime))
+	for _, pod := range remaining {
+		var gracePeriodSeconds int64
+		switch {
+		case !podutil.IsTerminating(pod):
+			gracePeriodSeconds = int64(podTerminationGracePeriod(pod).Seconds())
+			t.recorder.Publish(terminatorevents.DeletePodAfterTerminationGracePeriod(pod, gracePeriodSeconds))
+		case !t.clock.Now().Before(pod.DeletionTimestamp.Time):
+			t.recorder.Publish(terminatorevents.ForceDeletePod(pod))
+		default:
+			// The pod is still within its grace period
+			continue
+		}
+		if err := t.kubeClient.Delete(ctx, pod, &client.DeleteOptions{
+			GracePeriodSeconds: lo.ToPtr(gracePeriodSeconds),
+			Preconditions:      &metav1.Preconditions{UID: lo.ToPtr(pod.UID)},
+		}); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+			return fmt.Errorf("deleting pod, %w", err)
+		}
+		log.FromContext(ctx).WithValues(
+			"Pod", klog.KObj(pod),
+			"delete.gracePeriodSeconds", gracePeriodSeconds,
+		).V(1).Info("deleting pod after the terminationGracePeriod elapsed")
+	}
+	return NewNodeDrainError(fmt.Errorf("terminationGracePeriod elapsed, %d pods are being forcibly deleted", len(remaining)))
+}
+
+// podTerminationGracePeriod returns the grace period that the pod is granted when it's forcibly deleted, its
+// terminationGracePeriodSeconds capped by its forced termination grace period
+func podTerminationGracePeriod(pod *corev1.Pod) time.Duration {
+	gracePeriod := time.Duration(lo.FromPtrOr(pod.Spec.TerminationGracePeriodSeconds, corev1.DefaultTerminationGracePeriodSeconds)) * time.Second
+	if forcedGracePeriod, ok := forcedTerminationGracePeriod(pod); ok {
+		gracePeriod = min(gracePeriod, forcedGracePeriod)
+	}
+	return gracePeriod
+}
+
+// throttleEvictions returns the evictable pods that can be queued for eviction without exceeding the node's eviction
+// concurrency, or queueing pods faster than the node's eviction interval. Pods count against the concurrency from when
+// they're queued until they've terminated.
+func (t *Terminator) throttleEvictions(ctx context.Context, node *corev1.Node, pods []*corev1.Pod, evictable []*corev1.Pod) []*corev1.Pod {
+	opts := options.FromContext(ctx)
+	if opts.EvictionConcurrencyPerNode == 0 && opts.EvictionIntervalPerNode == 0 {
+		return evictable
+	}
+	evictable = lo.Reject(evictable, func(p *corev1.Pod, _ int) bool { return t.evictionQueue.Has(p) })
+	if opts.EvictionConcurrencyPerNode > 0 {
+		inFlight := lo.CountBy(pods, func(p *corev1.Pod) bool {
+			return podutil.IsWaitingEviction(p, t.clock) && (t.evictionQueue.Has(p) || podutil.IsTerminating(p))
+		})
+		evictable = lo.Slice(evictable, 0, max(opts.EvictionConcurrencyPerNode-inFlight, 0))
+	}
+	if opts.EvictionIntervalPerNode > 0 && len(evictable) > 0 {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if last, ok := t.lastEvictionTimes[node.Name]; ok && t.clock.Since(last) < opts.EvictionIntervalPerNode {
+			return nil
+		}
+		t.lastEvictionTimes[node.Name] = t.clock.Now()
+		evictable = evictable[:1]
+	}
+	return evictable
+}
+
+// systemCriticalPriority is the lowest priority of the system critical priority classes, pods at or above it are
+// treated as critical even if they use a different priority class
+// https://kubernetes.io/docs/tasks/administer-cluster/guaranteed-scheduling-critical-addon-pods/
+const systemCriticalPriority = int32(2000000000)
+
+// groupPodsByPriority groups pods into the waves that they're evicted in. Noncritical pods are evicted before critical
+// pods, and non-daemon pods before daemon pods. Noncritical pods are further split into waves by their priority, lowest
+// first, so that e.g. ingress pods keep serving until the workloads behind them have moved.
 func (t *Terminator) groupPodsByPriority(pods []*corev1.Pod) [][]*corev1.Pod {
 	// 1. Prioritize noncritical pods, non-daemon pods https://kubernetes.io/docs/concepts/architecture/nodes/#graceful-node-shutdown
 	var nonCriticalNonDaemon, nonCriticalDaemon, criticalNonDaemon, criticalDaemon []*corev1.Pod
 	for _, pod := range pods {
-		if pod.Spec.PriorityClassName == "system-cluster-critical" || pod.Spec.PriorityClassName == "system-node-critical" {
+		if isCritical(pod) {
 			if podutil.IsOwnedByDaemonSet(pod) {
 				criticalDaemon = append(criticalDaemon, pod)
 			} else {
@@ -134,7 +270,21 @@ func (t *Terminator) groupPodsByPriority(pods []*corev1.Pod) [][]*corev1.Pod {
 			}
 		}
 	}
-	return [][]*corev1.Pod{nonCriticalNonDaemon, nonCriticalDaemon, criticalNonDaemon, criticalDaemon}
+	// 2. Evict noncritical pods in ascending order of priority
+	return append(append(groupPodsByPriorityValue(nonCriticalNonDaemon), groupPodsByPriorityValue(nonCriticalDaemon)...), criticalNonDaemon, criticalDaemon)
+}
+
+func isCritical(pod *corev1.Pod) bool {
+	return pod.Spec.PriorityClassName == "system-cluster-critical" || pod.Spec.PriorityClassName == "system-node-critical" ||
+		lo.FromPtr(pod.Spec.Priority) >= systemCriticalPriority
+}
+
+// groupPodsByPriorityValue groups pods by their priority, with the lowest priority group first
+func groupPodsByPriorityValue(pods []*corev1.Pod) [][]*corev1.Pod {
+	groups := lo.GroupBy(pods, func(p *corev1.Pod) int32 { return lo.FromPtr(p.Spec.Priority) })
+	priorities := lo.Keys(groups)
+	slices.Sort(priorities)
+	return lo.Map(priorities, func(priority int32, _ int) []*corev1.Pod { return groups[priority] })
 }
 
 func (t *Terminator) DeleteExpiringPods(ctx context.Context, pods []*corev1.Pod, nodeGracePeriodTerminationTime *time.Time) error {
@@ -145,6 +295,9 @@ func (t *Terminator) DeleteExpiringPods(ctx context.Context, pods []*corev1.Pod,
 			// delete pod proactively to give as much of its terminationGracePeriodSeconds as possible for deletion
 			// ensure that we clamp the maximum pod terminationGracePeriodSeconds to the node's remaining expiration time in the delete command
 			gracePeriodSeconds := lo.ToPtr(int64(time.Until(*nodeGracePeriodTerminationTime).Seconds()))
+			if gracePeriod, ok := forcedTerminationGracePeriod(pod); ok {
+				gracePeriodSeconds = lo.ToPtr(min(*gracePeriodSeconds, int64(gracePeriod.Seconds())))
+			}
 			t.recorder.Publish(terminatorevents.DisruptPodDelete(pod, gracePeriodSeconds, nodeGracePeriodTerminationTime))
 			opts := &client.DeleteOptions{
 				GracePeriodSeconds: gracePeriodSeconds,
@@ -172,6 +325,22 @@ func (t *Terminator) podDeleteTimeWithGracePeriod(nodeGracePeriodExpirationTime
 
 	// calculate the time the pod should be deleted to allow it's full grace period for termination, equal to its terminationGracePeriodSeconds before the node's expiration time
 	// eg: if a node will be force terminated in 30m, but the current pod has a grace period of 45m, we return a time of 15m ago
-	deleteTime := nodeGracePeriodExpirationTime.Add(time.Duration(*pod.Spec.TerminationGracePeriodSeconds) * time.Second * -1)
+	deleteTime := nodeGracePeriodExpirationTime.Add(podTerminationGracePeriod(pod) * -1)
 	return &deleteTime
 }
+
+// forcedTerminationGracePeriod returns the cap on the grace period that the pod is granted when it's deleted to
+// accommodate the node's terminationGracePeriod, set through the karpenter.sh/forced-termination-grace-period annotation.
+// This lets e.g. batch pods be killed quickly while databases on the same node are deleted early enough to get their
+// full terminationGracePeriodSeconds.
+func forcedTerminationGracePeriod(pod *corev1.Pod) (time.Duration, bool) {
+	value, ok := pod.Annotations[v1.ForcedTerminationGracePeriodAnnotationKey]
+	if !ok {
+		return 0, false
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil || gracePeriod < 0 {
+		return 0, false
+	}
+	return gracePeriod, true
+}
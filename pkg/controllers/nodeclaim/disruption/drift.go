@@ -19,10 +19,13 @@ package disruption
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/patrickmn/go-cache"
 	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -38,6 +41,7 @@ const (
 	NodePoolDrifted      cloudprovider.DriftReason = "NodePoolDrifted"
 	RequirementsDrifted  cloudprovider.DriftReason = "RequirementsDrifted"
 	InstanceTypeNotFound cloudprovider.DriftReason = "InstanceTypeNotFound"
+	RolloutTriggered     cloudprovider.DriftReason = "RolloutTriggered"
 )
 
 // Drift is a nodeclaim sub-controller that adds or removes status conditions on drifted nodeclaims
@@ -72,7 +76,7 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1.NodePool, nodeClaim
 		return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
 	}
 	// 3. Finally, if the NodeClaim is drifted, but doesn't have status condition, add it.
-	nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(driftedReason), string(driftedReason))
+	nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDrifted, string(driftedReason), driftMessage(nodePool, nodeClaim, driftedReason))
 	if !hasDriftedCondition {
 		log.FromContext(ctx).V(1).WithValues("reason", string(driftedReason)).Info("marking drifted")
 	}
@@ -83,14 +87,18 @@ func (d *Drift) Reconcile(ctx context.Context, nodePool *v1.NodePool, nodeClaim
 // isDrifted will check if a NodeClaim is drifted from the fields in the NodePool Spec and the CloudProvider
 func (d *Drift) isDrifted(ctx context.Context, nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) (cloudprovider.DriftReason, error) {
 	// First check for static drift or node requirements have drifted to save on API calls.
-	if reason := lo.FindOrElse([]cloudprovider.DriftReason{areStaticFieldsDrifted(nodePool, nodeClaim), areRequirementsDrifted(nodePool, nodeClaim)}, "", func(i cloudprovider.DriftReason) bool {
+	if reason := lo.FindOrElse([]cloudprovider.DriftReason{isRolloutTriggered(nodePool, nodeClaim), areStaticFieldsDrifted(nodePool, nodeClaim), areRequirementsDrifted(nodePool, nodeClaim)}, "", func(i cloudprovider.DriftReason) bool {
 		return i != ""
 	}); reason != "" {
 		return reason, nil
 	}
 	// To reduce the amount of GetInstanceTypes() calls that we make per-NodeClaim, only check this for a NodeClaim once every 30m and don't start checking it until 1h after creation
-	// It's alright to be more delayed with instance type drift since this is a cloudprovider-generated set of options rather than a user-defined field
-	if _, ok := d.instanceTypeNotFoundCheckCache.Get(string(nodeClaim.UID)); !ok && d.clock.Since(nodeClaim.CreationTimestamp.Time) > time.Hour {
+	// It's alright to be more delayed with instance type drift since this is a cloudprovider-generated set of options rather than a user-defined field.
+	// Operators can bypass this by setting the karpenter.sh/drift-check-requested annotation on the NodePool to a new value, which
+	// forces a check the next time each of its NodeClaims is reconciled.
+	requested := nodePool.Annotations[v1.DriftCheckRequestedAnnotationKey]
+	checked, ok := d.instanceTypeNotFoundCheckCache.Get(string(nodeClaim.UID))
+	if forced := requested != "" && checked != requested; forced || (!ok && d.clock.Since(nodeClaim.CreationTimestamp.Time) > time.Hour) {
 		// Include instance type checking separate from the other two to reduce the amount of times we grab the instance types.
 		its, err := d.cloudProvider.GetInstanceTypes(ctx, nodePool)
 		if err != nil {
@@ -101,13 +109,26 @@ func (d *Drift) isDrifted(ctx context.Context, nodePool *v1.NodePool, nodeClaim
 		}
 		// Only add a cache entry once we've validated that an instance type exists. We only cache a successful check rather
 		// that the result to ensure we respond quickly to transient abnormalities in the GetInstanceTypes response.
-		d.instanceTypeNotFoundCheckCache.SetDefault(string(nodeClaim.UID), nil)
+		// The requested drift check is stored alongside so that we only force a check once per request.
+		d.instanceTypeNotFoundCheckCache.SetDefault(string(nodeClaim.UID), requested)
 	}
 	// Then check if it's drifted from the cloud provider side.
 	driftedReason, err := d.cloudProvider.IsDrifted(ctx, nodeClaim)
 	if err != nil {
 		return "", err
 	}
+	if driftedReason == "" {
+		return "", nil
+	}
+	// Finally, classify the NodeClass drift using the NodePool's drift policies
+	shouldDrift, err := nodePool.Spec.Disruption.ShouldDrift(d.clock, string(driftedReason))
+	if err != nil {
+		return "", fmt.Errorf("evaluating nodeclass drift policies, %w", err)
+	}
+	if !shouldDrift {
+		log.FromContext(ctx).V(1).WithValues("reason", string(driftedReason)).Info("ignoring drift, disallowed by nodeclass drift policy")
+		return "", nil
+	}
 	return driftedReason, nil
 }
 
@@ -165,8 +186,19 @@ func areStaticFieldsDrifted(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) clou
 	return lo.Ternary(nodePoolHash != nodeClaimHash, NodePoolDrifted, "")
 }
 
+// isRolloutTriggered returns RolloutTriggered if the NodeClaim wasn't launched with the NodePool's current rollout trigger
+func isRolloutTriggered(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) cloudprovider.DriftReason {
+	if nodePool.Spec.RolloutTrigger == "" {
+		return ""
+	}
+	return lo.Ternary(nodeClaim.Annotations[v1.NodePoolRolloutTriggerAnnotationKey] != nodePool.Spec.RolloutTrigger, RolloutTriggered, "")
+}
+
 func areRequirementsDrifted(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) cloudprovider.DriftReason {
-	nodepoolReq := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Spec.Template.Spec.Requirements...)
+	if !nodePool.Spec.Disruption.IsDriftHashField(v1.DriftHashFieldRequirements) {
+		return ""
+	}
+	nodepoolReq := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Requirements()...)
 	nodeClaimReq := scheduling.NewLabelRequirements(nodeClaim.Labels)
 
 	// Every nodepool requirement is compatible with the NodeClaim label set
@@ -176,3 +208,72 @@ func areRequirementsDrifted(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) clou
 
 	return ""
 }
+
+// driftMessage describes why the NodeClaim drifted so that operators can tell which fields differ from the NodePool
+func driftMessage(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim, reason cloudprovider.DriftReason) string {
+	switch reason {
+	case NodePoolDrifted:
+		// Fields that were removed from the template can't always be detected from the NodeClaim
+		if fields := staticFieldsDiff(nodePool, nodeClaim); len(fields) > 0 {
+			return fmt.Sprintf("NodePool template fields changed: %s", strings.Join(fields, ", "))
+		}
+		return "NodePool template hash changed"
+	case RequirementsDrifted:
+		return fmt.Sprintf("NodePool requirements incompatible with NodeClaim labels: %s", strings.Join(requirementsDiff(nodePool, nodeClaim), ", "))
+	case RolloutTriggered:
+		return fmt.Sprintf("NodePool rollout trigger changed from %q to %q", nodeClaim.Annotations[v1.NodePoolRolloutTriggerAnnotationKey], nodePool.Spec.RolloutTrigger)
+	case InstanceTypeNotFound:
+		return fmt.Sprintf("Instance type %q not found or has no compatible offerings", nodeClaim.Labels[corev1.LabelInstanceTypeStable])
+	default:
+		return string(reason)
+	}
+}
+
+// staticFieldsDiff returns the NodePool template fields that differ from the NodeClaim
+func staticFieldsDiff(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) []string {
+	template := nodePool.Spec.Template
+	var fields []string
+	disruption := nodePool.Spec.Disruption
+	if disruption.IsDriftHashField(v1.DriftHashFieldLabels) && lo.SomeBy(lo.Entries(template.Labels), func(e lo.Entry[string, string]) bool { return nodeClaim.Labels[e.Key] != e.Value }) {
+		fields = append(fields, "metadata.labels")
+	}
+	if disruption.IsDriftHashField(v1.DriftHashFieldAnnotations) && lo.SomeBy(lo.Entries(template.Annotations), func(e lo.Entry[string, string]) bool { return nodeClaim.Annotations[e.Key] != e.Value }) {
+		fields = append(fields, "metadata.annotations")
+	}
+	if disruption.IsDriftHashField(v1.DriftHashFieldTaints) && !lo.ElementsMatch(template.Spec.Taints, nodeClaim.Spec.Taints) {
+		fields = append(fields, "spec.taints")
+	}
+	if disruption.IsDriftHashField(v1.DriftHashFieldTaints) && !lo.ElementsMatch(template.Spec.StartupTaints, nodeClaim.Spec.StartupTaints) {
+		fields = append(fields, "spec.startupTaints")
+	}
+	if lo.FromPtr(template.Spec.NodeClassRef) != lo.FromPtr(nodeClaim.Spec.NodeClassRef) {
+		fields = append(fields, "spec.nodeClassRef")
+	}
+	if !equality.Semantic.DeepEqual(template.Spec.TerminationGracePeriod, nodeClaim.Spec.TerminationGracePeriod) {
+		fields = append(fields, "spec.terminationGracePeriod")
+	}
+	if !equality.Semantic.DeepEqual(template.Spec.TerminationGracePeriodOverrides, nodeClaim.Spec.TerminationGracePeriodOverrides) {
+		fields = append(fields, "spec.terminationGracePeriodOverrides")
+	}
+	if !equality.Semantic.DeepEqual(template.Spec.ExpireAfter.Duration, nodeClaim.Spec.ExpireAfter.Duration) {
+		fields = append(fields, "spec.expireAfter")
+	}
+	if !equality.Semantic.DeepEqual(template.Spec.ExpireAfterCost, nodeClaim.Spec.ExpireAfterCost) {
+		fields = append(fields, "spec.expireAfterCost")
+	}
+	if !equality.Semantic.DeepEqual(template.Spec.Kubelet, nodeClaim.Spec.Kubelet) {
+		fields = append(fields, "spec.kubelet")
+	}
+	return fields
+}
+
+// requirementsDiff returns the keys of the NodePool requirements that aren't compatible with the NodeClaim's labels
+func requirementsDiff(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) []string {
+	nodepoolReq := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Requirements()...)
+	nodeClaimReq := scheduling.NewLabelRequirements(nodeClaim.Labels)
+	keys := lo.Filter(nodepoolReq.Keys().UnsortedList(), func(key string, _ int) bool {
+		return nodeClaimReq.Compatible(scheduling.NewRequirements(nodepoolReq.Get(key))) != nil
+	})
+	sort.Strings(keys)
+	return keys
+}
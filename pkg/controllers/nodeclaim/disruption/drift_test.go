@@ -17,6 +17,7 @@ limitations under the License.
 package disruption_test
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/imdario/mergo"
@@ -130,6 +131,48 @@ var _ = Describe("Drift", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
 	})
+	Context("Drift Check Requested", func() {
+		BeforeEach(func() {
+			cp.InstanceTypes = nil
+		})
+		It("should not check for stale instance type drift within an hour of creation", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+		It("should check for stale instance type drift within an hour of creation when requested", func() {
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{v1.DriftCheckRequestedAnnotationKey: "1"})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.InstanceTypeNotFound)))
+		})
+		It("should bypass the instance type check cache when a new check is requested", func() {
+			cp.InstanceTypes = []*cloudprovider.InstanceType{it}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			fakeClock.Step(time.Hour * 2) // To move 2h past the creationTimestamp
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+
+			// The successful check is cached, so removing the instance type isn't observed yet
+			cp.InstanceTypes = nil
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{v1.DriftCheckRequestedAnnotationKey: "1"})
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.InstanceTypeNotFound)))
+		})
+	})
 	It("should detect static drift before cloud provider drift", func() {
 		cp.Drifted = "drifted"
 		nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
@@ -164,6 +207,54 @@ var _ = Describe("Drift", func() {
 		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
 		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.RequirementsDrifted)))
 	})
+	It("should not detect node requirement drift when requirements aren't selected by driftHashFields", func() {
+		nodePool.Spec.Disruption.DriftHashFields = []v1.DriftHashField{v1.DriftHashFieldLabels}
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{
+				NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+					Key:      corev1.LabelInstanceTypeStable,
+					Operator: corev1.NodeSelectorOpDoesNotExist,
+				},
+			},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+	})
+	It("should record the requirement keys that drifted in the status condition message", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{
+				NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+					Key:      corev1.LabelInstanceTypeStable,
+					Operator: corev1.NodeSelectorOpDoesNotExist,
+				},
+			},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Message).To(Equal(fmt.Sprintf("NodePool requirements incompatible with NodeClaim labels: %s", corev1.LabelInstanceTypeStable)))
+	})
+	It("should record the template fields that drifted in the status condition message", func() {
+		nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "example.com/dedicated", Effect: corev1.TaintEffectNoSchedule}}
+		nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+			v1.NodePoolHashAnnotationKey:        "test-123456789",
+			v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+		})
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{
+			v1.NodePoolHashAnnotationKey:        "test-123",
+			v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.NodePoolDrifted)))
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Message).To(ContainSubstring("spec.taints"))
+	})
 	It("should remove the status condition from the nodeClaim when the nodeClaim launch condition is unknown", func() {
 		cp.Drifted = "drifted"
 		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeDrifted)
@@ -205,6 +296,95 @@ var _ = Describe("Drift", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
 	})
+	Context("NodeClass Drift Policies", func() {
+		BeforeEach(func() {
+			cp.Drifted = "SecurityGroupDrift"
+		})
+		It("should detect drift when no policy matches the drift reason", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []v1.NodeClassDriftPolicy{{
+				Reasons: []string{"TagsDrift"},
+				Action:  v1.NodeClassDriftActionNever,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+		})
+		It("should detect drift when the matching policy always drifts", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []v1.NodeClassDriftPolicy{{
+				Reasons: []string{"SecurityGroupDrift"},
+				Action:  v1.NodeClassDriftActionAlways,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+		})
+		It("should not detect drift when the matching policy never drifts", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []v1.NodeClassDriftPolicy{{
+				Reasons: []string{"SecurityGroupDrift"},
+				Action:  v1.NodeClassDriftActionNever,
+			}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+		It("should use the first policy that matches the drift reason", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []v1.NodeClassDriftPolicy{
+				{Reasons: []string{"SecurityGroupDrift"}, Action: v1.NodeClassDriftActionNever},
+				{Reasons: []string{"SecurityGroupDrift"}, Action: v1.NodeClassDriftActionAlways},
+			}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+		It("should only detect drift while the maintenance window is active", func() {
+			// Maintenance window is active from 00:00 to 02:00 UTC every day
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []v1.NodeClassDriftPolicy{{
+				Reasons:  []string{"SecurityGroupDrift"},
+				Action:   v1.NodeClassDriftActionInMaintenanceWindow,
+				Schedule: lo.ToPtr("0 0 * * *"),
+				Duration: lo.ToPtr(metav1.Duration{Duration: 2 * time.Hour}),
+			}}
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+			fakeClock.SetTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+
+			fakeClock.SetTime(time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC))
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+		})
+		It("should not apply policies to static drift", func() {
+			nodePool.Spec.Disruption.NodeClassDriftPolicies = []v1.NodeClassDriftPolicy{{
+				Reasons: []string{string(disruption.NodePoolDrifted)},
+				Action:  v1.NodeClassDriftActionNever,
+			}}
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{
+				v1.NodePoolHashAnnotationKey:        "123456789",
+				v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+			})
+			nodePool.Annotations = lo.Assign(nodePool.Annotations, map[string]string{
+				v1.NodePoolHashAnnotationKey:        "abcdefghi",
+				v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.NodePoolDrifted)))
+		})
+	})
 	Context("NodeRequirement Drift", func() {
 		DescribeTable("",
 			func(oldNodePoolReq []v1.NodeSelectorRequirementWithMinValues, newNodePoolReq []v1.NodeSelectorRequirementWithMinValues, labels map[string]string, drifted bool) {
@@ -408,6 +588,36 @@ var _ = Describe("Drift", func() {
 		})
 
 	})
+	Context("Rollout Trigger", func() {
+		It("should detect drift when the NodeClaim wasn't launched with the rollout trigger", func() {
+			nodePool.Spec.RolloutTrigger = "2024-01-01"
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.RolloutTriggered)))
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Message).To(Equal(`NodePool rollout trigger changed from "" to "2024-01-01"`))
+		})
+		It("should detect drift when the rollout trigger changes", func() {
+			nodePool.Spec.RolloutTrigger = "2024-01-02"
+			nodeClaim.Annotations[v1.NodePoolRolloutTriggerAnnotationKey] = "2024-01-01"
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Reason).To(Equal(string(disruption.RolloutTriggered)))
+		})
+		It("should not detect drift when the NodeClaim was launched with the rollout trigger", func() {
+			nodePool.Spec.RolloutTrigger = "2024-01-01"
+			nodeClaim.Annotations[v1.NodePoolRolloutTriggerAnnotationKey] = "2024-01-01"
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+	})
 	Context("NodePool Static Drift", func() {
 		var nodePoolController *hash.Controller
 		BeforeEach(func() {
@@ -479,7 +689,40 @@ var _ = Describe("Drift", func() {
 			Entry("NodeClassRef Name", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{NodeClassRef: &v1.NodeClassReference{Name: "testName"}}}}}),
 			Entry("ExpireAfter", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{ExpireAfter: v1.MustParseNillableDuration("100m")}}}}),
 			Entry("TerminationGracePeriod", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{TerminationGracePeriod: &metav1.Duration{Duration: 100 * time.Minute}}}}}),
+			Entry("TerminationGracePeriodOverrides", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{TerminationGracePeriodOverrides: []v1.TerminationGracePeriodOverride{{Reason: string(v1.DisruptionReasonDrifted), TerminationGracePeriod: v1.MustParseNillableDuration("24h")}}}}}}),
+			Entry("ExpireAfterCost", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{ExpireAfterCost: lo.ToPtr("50")}}}}),
+			Entry("Kubelet", v1.NodePool{Spec: v1.NodePoolSpec{Template: v1.NodeClaimTemplate{Spec: v1.NodeClaimTemplateSpec{Kubelet: &v1.KubeletConfiguration{MaxPods: lo.ToPtr[int32](50)}}}}}),
 		)
+		It("should not detect drift on changes to fields that aren't selected by driftHashFields", func() {
+			nodePool.Spec.Disruption.DriftHashFields = []v1.DriftHashField{v1.DriftHashFieldTaints}
+			nodeClaim.Annotations[v1.NodePoolHashAnnotationKey] = nodePool.Hash()
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			nodePool.Spec.Template.Labels = map[string]string{"keyLabelTest": "valueLabelTest"}
+			nodePool.Spec.Template.Annotations = map[string]string{"keyAnnotationTest": "valueAnnotationTest"}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted)).To(BeNil())
+		})
+		It("should detect drift on changes to fields that are selected by driftHashFields", func() {
+			nodePool.Spec.Disruption.DriftHashFields = []v1.DriftHashField{v1.DriftHashFieldTaints}
+			nodeClaim.Annotations[v1.NodePoolHashAnnotationKey] = nodePool.Hash()
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			nodePool.Spec.Template.Spec.Taints = []corev1.Taint{{Key: "keytest2taint", Effect: corev1.TaintEffectNoExecute}}
+			ExpectApplied(ctx, env.Client, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimDisruptionController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDrifted).Message).To(ContainSubstring("spec.taints"))
+		})
 		It("should not return drifted if karpenter.sh/nodepool-hash annotation is not present on the NodePool", func() {
 			nodePool.Annotations = map[string]string{}
 			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
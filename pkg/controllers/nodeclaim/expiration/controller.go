@@ -18,9 +18,14 @@ package expiration
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/samber/lo"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -34,8 +39,15 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+	"sigs.k8s.io/karpenter/pkg/utils/readonly"
 )
 
 // Expiration is a nodeclaim controller that deletes expired nodeclaims based on expireAfter
@@ -43,14 +55,20 @@ type Controller struct {
 	clock         clock.Clock
 	kubeClient    client.Client
 	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+	provisioner   *provisioning.Provisioner
+	recorder      events.Recorder
 }
 
 // NewController constructs a nodeclaim disruption controller
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster, provisioner *provisioning.Provisioner, recorder events.Recorder) *Controller {
 	return &Controller{
 		clock:         clk,
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
+		cluster:       cluster,
+		provisioner:   provisioner,
+		recorder:      recorder,
 	}
 }
 
@@ -67,17 +85,56 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 		return reconcile.Result{}, nil
 	}
 	// From here there are three scenarios to handle:
-	// 1. If ExpireAfter is not configured, exit expiration loop
-	if nodeClaim.Spec.ExpireAfter.Duration == nil {
+	// 1. If neither ExpireAfter nor ExpireAfterCost is configured, exit expiration loop
+	var expirationTimes []time.Time
+	if nodeClaim.Spec.ExpireAfter.Duration != nil {
+		expirationTimes = append(expirationTimes, ExpirationTime(ctx, nodeClaim))
+	}
+	if costExpirationTime, ok := CostExpirationTime(nodeClaim); ok {
+		expirationTimes = append(expirationTimes, costExpirationTime)
+	}
+	if len(expirationTimes) == 0 {
 		return reconcile.Result{}, nil
 	}
-	expirationTime := nodeClaim.CreationTimestamp.Add(*nodeClaim.Spec.ExpireAfter.Duration)
-	// 2. If the NodeClaim isn't expired leave the reconcile loop.
+	expirationTime := lo.MinBy(expirationTimes, func(a, b time.Time) bool { return a.Before(b) })
+	// 2. If the NodeClaim isn't expired, warn about the upcoming expiration if it's close enough and leave the reconcile loop.
 	if c.clock.Now().Before(expirationTime) {
-		// Use t.Sub(clock.Now()) instead of time.Until() to ensure we're using the injected clock.
+		warningDuration := options.FromContext(ctx).ExpirationWarningDuration
+		warningTime := expirationTime.Add(-warningDuration)
+		if warningDuration == 0 || c.clock.Now().Before(warningTime) {
+			// Use t.Sub(clock.Now()) instead of time.Until() to ensure we're using the injected clock.
+			return reconcile.Result{RequeueAfter: lo.Ternary(warningDuration == 0, expirationTime, warningTime).Sub(c.clock.Now())}, nil
+		}
+		if err := c.warn(ctx, nodeClaim, expirationTime, warningDuration); err != nil {
+			return reconcile.Result{}, err
+		}
 		return reconcile.Result{RequeueAfter: expirationTime.Sub(c.clock.Now())}, nil
 	}
-	// 3. Otherwise, if the NodeClaim is expired we can forcefully expire the nodeclaim (by deleting it)
+	// In read-only mode expired NodeClaims are left in place until the mode is switched off
+	if readonly.Enabled(ctx) {
+		log.FromContext(ctx).V(1).Info("skipping deletion of expired nodeclaim, read-only mode is enabled")
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}
+	// 3. Otherwise, if the NodeClaim is expired we can forcefully expire the nodeclaim (by deleting it). When replacements
+	// are enabled, we first wait for a replacement to register so that the NodeClaim's pods have somewhere to go.
+	if options.FromContext(ctx).ExpirationReplacement {
+		ready, err := c.replacementReady(ctx, nodeClaim)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !ready {
+			return reconcile.Result{RequeueAfter: replacementPollingPeriod}, nil
+		}
+	}
+	// Mark the NodeClaim as expired before deleting it so that the expired terminationGracePeriod override is applied
+	// when the NodeClaim is finalized.
+	if !nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason).IsTrue() {
+		stored := nodeClaim.DeepCopy()
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, v1.DisruptionReasonExpired, v1.DisruptionReasonExpired)
+		if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+	}
 	if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
@@ -95,6 +152,69 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 	return reconcile.Result{}, nil
 }
 
+// warn publishes Expiring events to the NodeClaim, its Node, and the pods running on the Node so that workload owners
+// have a chance to checkpoint before the NodeClaim is disrupted
+func (c *Controller) warn(ctx context.Context, nodeClaim *v1.NodeClaim, expirationTime time.Time, warningDuration time.Duration) error {
+	c.recorder.Publish(ExpiringEvent(nodeClaim, expirationTime, warningDuration))
+	node, err := nodeclaimutils.NodeForNodeClaim(ctx, c.kubeClient, nodeClaim)
+	if err != nil {
+		// The NodeClaim may not have registered a Node yet, in which case there's no one else to warn
+		if nodeclaimutils.IsNodeNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("getting node for nodeclaim, %w", err)
+	}
+	c.recorder.Publish(ExpiringEvent(node, expirationTime, warningDuration))
+	pods, err := nodeutils.GetPods(ctx, c.kubeClient, node)
+	if err != nil {
+		return fmt.Errorf("listing pods for node, %w", err)
+	}
+	for _, pod := range pods {
+		if podutils.IsTerminal(pod) || podutils.IsOwnedByNode(pod) {
+			continue
+		}
+		c.recorder.Publish(ExpiringEvent(pod, expirationTime, warningDuration))
+	}
+	return nil
+}
+
+// ExpirationTime returns when the NodeClaim expires. When expiration jitter is configured, the expiration is brought
+// forward by up to that percentage of expireAfter so that NodeClaims created together don't all expire at once. The
+// jitter is derived from the NodeClaim's UID so it's stable across reconciles and controller restarts.
+func ExpirationTime(ctx context.Context, nodeClaim *v1.NodeClaim) time.Time {
+	expireAfter := *nodeClaim.Spec.ExpireAfter.Duration
+	if jitterPercent := options.FromContext(ctx).ExpirationJitterPercent; jitterPercent > 0 {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(nodeClaim.UID))
+		fraction := float64(h.Sum64()%10000) / 10000
+		expireAfter -= time.Duration(float64(expireAfter) * float64(jitterPercent) / 100 * fraction)
+	}
+	return nodeClaim.CreationTimestamp.Add(expireAfter)
+}
+
+// CostExpirationTime returns when the NodeClaim's cumulative cost reaches its expireAfterCost, based on the price of
+// the offering that it was launched with. It returns false if the NodeClaim doesn't expire based on cost, including
+// when its launch price is unknown or free.
+func CostExpirationTime(nodeClaim *v1.NodeClaim) (time.Time, bool) {
+	if nodeClaim.Spec.ExpireAfterCost == nil {
+		return time.Time{}, false
+	}
+	cost, err := strconv.ParseFloat(*nodeClaim.Spec.ExpireAfterCost, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	price, err := strconv.ParseFloat(nodeClaim.Annotations[v1.NodeClaimLaunchPriceAnnotationKey], 64)
+	if err != nil || price <= 0 {
+		return time.Time{}, false
+	}
+	// Offering prices are hourly, so guard against expirations that are too far out to represent as a duration
+	hours := cost / price
+	if hours >= float64(math.MaxInt64)/float64(time.Hour) {
+		return time.Time{}, false
+	}
+	return nodeClaim.CreationTimestamp.Add(time.Duration(hours * float64(time.Hour))), true
+}
+
 func (c *Controller) Name() string {
 	return "nodeclaim.expiration"
 }
@@ -0,0 +1,40 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expiration
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+// ExpiringEvent warns the owners of the NodeClaim, its Node, or a pod running on the Node that the NodeClaim is going
+// to expire. The event is only published once per warning window so that it isn't repeated on every reconcile.
+func ExpiringEvent(obj client.Object, expirationTime time.Time, warningDuration time.Duration) events.Event {
+	return events.Event{
+		InvolvedObject: obj,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.Expiring,
+		Message:        fmt.Sprintf("NodeClaim expires at %s and will be disrupted, checkpoint any long-running work", expirationTime.Format(time.RFC3339)),
+		DedupeValues:   []string{string(obj.GetUID())},
+		DedupeTimeout:  warningDuration,
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expiration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning/scheduling"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	operatorlogging "sigs.k8s.io/karpenter/pkg/operator/logging"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
+)
+
+const replacementPollingPeriod = 10 * time.Second
+
+// replacementReady launches replacements for the expired NodeClaim's pods, mirroring what Replace consolidation does,
+// and returns true once every replacement has registered. NodeClaims whose pods fit on the rest of the cluster don't
+// need a replacement.
+func (c *Controller) replacementReady(ctx context.Context, nodeClaim *v1.NodeClaim) (bool, error) {
+	if names, ok := nodeClaim.Annotations[v1.ExpirationReplacementAnnotationKey]; ok {
+		registered, found, err := c.replacementsRegistered(ctx, strings.Split(names, ","))
+		if err != nil {
+			return false, err
+		}
+		if found {
+			return registered, nil
+		}
+		// A replacement failed to launch or was deleted before it registered, so we launch new ones
+	}
+	pods, err := c.reschedulablePods(ctx, nodeClaim)
+	if err != nil {
+		return false, err
+	}
+	if len(pods) == 0 {
+		return true, nil
+	}
+	results, err := c.simulateScheduling(ctx, nodeClaim, pods)
+	if err != nil {
+		// Without a NodePool there's nothing to launch a replacement from, so we don't hold up expiration
+		if errors.Is(err, provisioning.ErrNodePoolsNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	if len(results.NewNodeClaims) == 0 {
+		return true, nil
+	}
+	names, err := c.provisioner.CreateNodeClaims(ctx, results.NewNodeClaims,
+		provisioning.WithReason(strings.ToLower(metrics.ExpiredReason)),
+		provisioning.WithAnnotations(map[string]string{v1.NodeClaimReplacesAnnotationKey: nodeClaim.Name}),
+	)
+	if err != nil {
+		return false, fmt.Errorf("creating replacement nodeclaims, %w", err)
+	}
+	stored := nodeClaim.DeepCopy()
+	nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.ExpirationReplacementAnnotationKey: strings.Join(names, ",")})
+	// We use an optimistic lock so that we don't overwrite the replacements recorded by a concurrent reconcile
+	if err := c.kubeClient.Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		return false, client.IgnoreNotFound(fmt.Errorf("recording replacement nodeclaims, %w", err))
+	}
+	log.FromContext(ctx).WithValues("replacements", names).Info("launched replacements for expired nodeclaim")
+	return false, nil
+}
+
+// replacementsRegistered returns whether all of the replacements have registered. found is false if one of the
+// replacements no longer exists or is deleting.
+func (c *Controller) replacementsRegistered(ctx context.Context, names []string) (registered bool, found bool, err error) {
+	registered = true
+	for _, name := range names {
+		replacement := &v1.NodeClaim{}
+		if err := c.kubeClient.Get(ctx, client.ObjectKey{Name: name}, replacement); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, false, nil
+			}
+			return false, false, fmt.Errorf("getting replacement nodeclaim, %w", err)
+		}
+		if !replacement.DeletionTimestamp.IsZero() {
+			return false, false, nil
+		}
+		registered = registered && replacement.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue()
+	}
+	return registered, true, nil
+}
+
+// simulateScheduling schedules the expired NodeClaim's pods against the rest of the cluster with the provisioner's
+// scheduler, so that the replacements are launched from any compatible NodePool and respect the NodePools' limits,
+// minValues and the pods' scheduling constraints
+func (c *Controller) simulateScheduling(ctx context.Context, nodeClaim *v1.NodeClaim, pods []*corev1.Pod) (scheduling.Results, error) {
+	stateNodes := lo.Filter(c.cluster.DeepCopyNodes().Active(), func(n *state.StateNode, _ int) bool {
+		return n.NodeClaim == nil || n.NodeClaim.Name != nodeClaim.Name
+	})
+	var opts []scheduling.Options
+	if options.FromContext(ctx).PreferencePolicy == options.PreferencePolicyIgnore {
+		opts = append(opts, scheduling.IgnorePreferences)
+	}
+	opts = append(opts, scheduling.MinValuesPolicy(options.FromContext(ctx).MinValuesPolicy))
+	scheduler, err := c.provisioner.NewScheduler(log.IntoContext(ctx, operatorlogging.NopLogger), pods, stateNodes, opts...)
+	if err != nil {
+		return scheduling.Results{}, fmt.Errorf("creating scheduler, %w", err)
+	}
+	results, err := scheduler.Solve(log.IntoContext(ctx, operatorlogging.NopLogger), pods)
+	if err != nil {
+		return scheduling.Results{}, fmt.Errorf("scheduling pods, %w", err)
+	}
+	return results.TruncateInstanceTypes(ctx, scheduling.MaxInstanceTypes), nil
+}
+
+// reschedulablePods returns the pods on the NodeClaim's Node that will need to be rescheduled once it's drained
+func (c *Controller) reschedulablePods(ctx context.Context, nodeClaim *v1.NodeClaim) ([]*corev1.Pod, error) {
+	node, err := nodeclaimutils.NodeForNodeClaim(ctx, c.kubeClient, nodeClaim)
+	if err != nil {
+		if nodeclaimutils.IsNodeNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting node for nodeclaim, %w", err)
+	}
+	pods, err := nodeutils.GetPods(ctx, c.kubeClient, node)
+	if err != nil {
+		return nil, fmt.Errorf("listing pods for node, %w", err)
+	}
+	return lo.Filter(pods, func(p *corev1.Pod, _ int) bool { return podutils.IsReschedulable(p) }), nil
+}
@@ -23,14 +23,20 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/expiration"
+	"sigs.k8s.io/karpenter/pkg/controllers/provisioning"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -44,6 +50,9 @@ var expirationController *expiration.Controller
 var env *test.Environment
 var cp *fake.CloudProvider
 var fakeClock *clock.FakeClock
+var recorder *test.EventRecorder
+var cluster *state.Cluster
+var prov *provisioning.Provisioner
 
 func TestAPIs(t *testing.T) {
 	ctx = TestContextWithLogger(t)
@@ -56,7 +65,10 @@ var _ = BeforeSuite(func() {
 	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeProviderIDFieldIndexer(ctx)))
 	ctx = options.ToContext(ctx, test.Options())
 	cp = fake.NewCloudProvider()
-	expirationController = expiration.NewController(fakeClock, env.Client, cp)
+	recorder = test.NewEventRecorder()
+	cluster = state.NewCluster(fakeClock, env.Client, cp)
+	prov = provisioning.NewProvisioner(env.Client, recorder, cp, cluster, fakeClock)
+	expirationController = expiration.NewController(fakeClock, env.Client, cp, cluster, prov, recorder)
 })
 
 var _ = AfterSuite(func() {
@@ -66,6 +78,8 @@ var _ = AfterSuite(func() {
 var _ = BeforeEach(func() {
 	ctx = options.ToContext(ctx, test.Options())
 	fakeClock.SetTime(time.Now())
+	recorder.Reset()
+	cluster.Reset()
 })
 
 var _ = AfterEach(func() {
@@ -178,6 +192,195 @@ var _ = Describe("Expiration", func() {
 		result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
 		Expect(result.RequeueAfter).To(BeNumerically("~", time.Second*100, time.Second))
 	})
+	Context("Jitter", func() {
+		var nodeClaims []*v1.NodeClaim
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ExpirationJitterPercent: lo.ToPtr(50)}))
+			nodeClaims = lo.Times(10, func(_ int) *v1.NodeClaim {
+				return test.NodeClaim(v1.NodeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+					},
+					Spec: v1.NodeClaimSpec{
+						ExpireAfter: v1.MustParseNillableDuration("200s"),
+					},
+				})
+			})
+			for _, nc := range nodeClaims {
+				ExpectApplied(ctx, env.Client, nc)
+			}
+		})
+		It("should spread expiration of NodeClaims created together within the jitter percentage", func() {
+			requeues := lo.Map(nodeClaims, func(nc *v1.NodeClaim, _ int) time.Duration {
+				fakeClock.SetTime(nc.CreationTimestamp.Time)
+				return ExpectObjectReconciled(ctx, env.Client, expirationController, nc).RequeueAfter
+			})
+			for _, requeue := range requeues {
+				Expect(requeue).To(BeNumerically(">", 100*time.Second))
+				Expect(requeue).To(BeNumerically("<=", 200*time.Second))
+			}
+			Expect(lo.Uniq(requeues)).ToNot(HaveLen(1))
+		})
+		It("should compute the same expiration time for a NodeClaim across reconciles", func() {
+			Expect(expiration.ExpirationTime(ctx, nodeClaims[0])).To(Equal(expiration.ExpirationTime(ctx, nodeClaims[0])))
+			Expect(expiration.ExpirationTime(ctx, nodeClaims[0]).After(nodeClaims[0].CreationTimestamp.Add(200 * time.Second))).To(BeFalse())
+		})
+		It("should not remove NodeClaims before the jittered expiration", func() {
+			fakeClock.Step(99 * time.Second)
+			for _, nc := range nodeClaims {
+				ExpectObjectReconciled(ctx, env.Client, expirationController, nc)
+				ExpectExists(ctx, env.Client, nc)
+			}
+		})
+		It("should remove all NodeClaims once expireAfter has elapsed", func() {
+			fakeClock.Step(201 * time.Second)
+			for _, nc := range nodeClaims {
+				ExpectObjectReconciled(ctx, env.Client, expirationController, nc)
+				ExpectNotFound(ctx, env.Client, nc)
+			}
+		})
+	})
+	Context("Warning", func() {
+		var pod *corev1.Pod
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ExpirationWarningDuration: lo.ToPtr(time.Minute)}))
+			nodeClaim.Spec.ExpireAfter = v1.MustParseNillableDuration("200s")
+			pod = test.Pod(test.PodOptions{NodeName: node.Name})
+		})
+		It("should not warn before the warning window", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim, node, pod)
+			result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(result.RequeueAfter).To(BeNumerically("~", 140*time.Second, time.Second))
+			Expect(recorder.Calls(events.Expiring)).To(Equal(0))
+		})
+		It("should warn the nodeclaim, node, and pods within the warning window", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim, node, pod)
+			fakeClock.Step(150 * time.Second)
+			result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(result.RequeueAfter).To(BeNumerically("~", 50*time.Second, time.Second))
+			ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(recorder.Calls(events.Expiring)).To(Equal(3))
+			Expect(lo.Map(recorder.Events(), func(e events.Event, _ int) string {
+				return e.InvolvedObject.(client.Object).GetName()
+			})).To(ConsistOf(nodeClaim.Name, node.Name, pod.Name))
+		})
+		It("should warn the nodeclaim if it doesn't have a node", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim)
+			fakeClock.Step(150 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(recorder.Calls(events.Expiring)).To(Equal(1))
+		})
+		It("should not warn when the warning duration is disabled", func() {
+			ctx = options.ToContext(ctx, test.Options())
+			ExpectApplied(ctx, env.Client, nodeClaim, node, pod)
+			fakeClock.Step(150 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(recorder.Calls(events.Expiring)).To(Equal(0))
+		})
+	})
+	Context("Cost", func() {
+		BeforeEach(func() {
+			nodeClaim.Spec.ExpireAfter = v1.MustParseNillableDuration("Never")
+			nodeClaim.Spec.ExpireAfterCost = lo.ToPtr("3")
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimLaunchPriceAnnotationKey: "1.5"})
+		})
+		It("should requeue until the nodeclaim's cost reaches expireAfterCost", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim)
+			result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(result.RequeueAfter).To(BeNumerically("~", 2*time.Hour, time.Second))
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should expire the nodeclaim once its cost reaches expireAfterCost", func() {
+			ExpectApplied(ctx, env.Client, nodeClaim)
+			fakeClock.Step(2*time.Hour + time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+		})
+		It("should expire the nodeclaim at whichever of expireAfter and expireAfterCost comes first", func() {
+			nodeClaim.Spec.ExpireAfter = v1.MustParseNillableDuration("720h")
+			ExpectApplied(ctx, env.Client, nodeClaim)
+			fakeClock.Step(2*time.Hour + time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+		})
+		It("should not expire the nodeclaim based on cost if its launch price is unknown", func() {
+			delete(nodeClaim.Annotations, v1.NodeClaimLaunchPriceAnnotationKey)
+			ExpectApplied(ctx, env.Client, nodeClaim)
+			fakeClock.Step(24 * time.Hour)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+	})
+	Context("Replacement", func() {
+		var pod *corev1.Pod
+		BeforeEach(func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ExpirationReplacement: lo.ToPtr(true)}))
+			nodeClaim.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+				{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"default-instance-type"}}},
+			}
+			pod = test.Pod(test.PodOptions{NodeName: node.Name})
+		})
+		It("should launch a replacement and wait for it to register before deleting the nodeclaim", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			fakeClock.Step(60 * time.Second)
+			result := ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Annotations).To(HaveKey(v1.ExpirationReplacementAnnotationKey))
+			replacement := ExpectExists(ctx, env.Client, &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: nodeClaim.Annotations[v1.ExpirationReplacementAnnotationKey]}})
+			Expect(replacement.Labels).To(HaveKeyWithValue(v1.NodePoolLabelKey, nodePool.Name))
+			Expect(replacement.Annotations).To(HaveKeyWithValue(v1.NodeClaimReplacesAnnotationKey, nodeClaim.Name))
+
+			// The nodeclaim isn't deleted while the replacement is still launching
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			ExpectExists(ctx, env.Client, nodeClaim)
+
+			replacement.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+			ExpectApplied(ctx, env.Client, replacement)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+		})
+		It("should launch another replacement if the replacement is deleted before it registers", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			fakeClock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			replacement := &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: nodeClaim.Annotations[v1.ExpirationReplacementAnnotationKey]}}
+			ExpectDeleted(ctx, env.Client, replacement)
+
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.Annotations[v1.ExpirationReplacementAnnotationKey]).ToNot(Equal(replacement.Name))
+			ExpectExists(ctx, env.Client, &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: nodeClaim.Annotations[v1.ExpirationReplacementAnnotationKey]}})
+		})
+		It("should size the replacement for the pods of the nodeclaim", func() {
+			pod = test.Pod(test.PodOptions{
+				NodeName:             node.Name,
+				ResourceRequirements: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")}},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			fakeClock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			replacement := ExpectExists(ctx, env.Client, &v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Name: nodeClaim.Annotations[v1.ExpirationReplacementAnnotationKey]}})
+			Expect(replacement.Spec.Resources.Requests.Cpu().Cmp(resource.MustParse("3"))).To(BeNumerically(">=", 0))
+		})
+		It("should delete the nodeclaim without a replacement if there are no ready nodepools to launch one from", func() {
+			nodePool.StatusConditions().SetFalse(v1.ConditionTypeValidationSucceeded, "Invalid", "")
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+			fakeClock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+		})
+		It("should delete the nodeclaim without a replacement if it has no reschedulable pods", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+			fakeClock.Step(60 * time.Second)
+			ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+		})
+	})
 	It("shouldn't expire the same NodeClaim multiple times", func() {
 		nodeClaim.Finalizers = append(nodeClaim.Finalizers, "test-finalizer")
 		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
@@ -196,4 +399,16 @@ var _ = Describe("Expiration", func() {
 			"nodepool":          nodePool.Name,
 		})
 	})
+	It("should mark the NodeClaim as expired when deleting it", func() {
+		nodeClaim.Finalizers = append(nodeClaim.Finalizers, "test-finalizer")
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+		// step forward to make the node expired
+		fakeClock.Step(60 * time.Second)
+		ExpectObjectReconciled(ctx, env.Client, expirationController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason).IsTrue()).To(BeTrue())
+		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason).Reason).To(Equal(v1.DisruptionReasonExpired))
+	})
 })
@@ -18,6 +18,7 @@ package garbagecollection
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/awslabs/operatorpkg/reconciler"
@@ -39,6 +40,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 )
@@ -47,6 +49,8 @@ type Controller struct {
 	clock         clock.Clock
 	kubeClient    client.Client
 	cloudProvider cloudprovider.CloudProvider
+	// misses counts the consecutive passes that didn't find the instance of a NodeClaim, keyed by provider id
+	misses map[string]int
 }
 
 func NewController(c clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
@@ -54,6 +58,7 @@ func NewController(c clock.Clock, kubeClient client.Client, cloudProvider cloudp
 		clock:         c,
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
+		misses:        map[string]int{},
 	}
 }
 
@@ -79,8 +84,10 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 	nodeClaims = lo.Filter(nodeClaims, func(n *v1.NodeClaim, _ int) bool {
 		return n.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue() &&
 			n.DeletionTimestamp.IsZero() &&
-			!cloudProviderProviderIDs.Has(n.Status.ProviderID)
+			!cloudProviderProviderIDs.Has(n.Status.ProviderID) &&
+			c.clock.Since(n.CreationTimestamp.Time) >= options.FromContext(ctx).NodeClaimGCMinAge
 	})
+	nodeClaims = c.confirmMissing(ctx, nodeClaims)
 
 	errs := make([]error, len(nodeClaims))
 	workqueue.ParallelizeUntil(ctx, 20, len(nodeClaims), func(i int) {
@@ -96,6 +103,17 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 		if node != nil && nodeutils.GetCondition(node, corev1.NodeReady).Status == corev1.ConditionTrue {
 			return
 		}
+		// Record the root cause before deleting the NodeClaim so that it's visible while the NodeClaim terminates
+		stored := nodeClaims[i].DeepCopy()
+		nodeClaims[i].StatusConditions().SetTrueWithReason(v1.ConditionTypeGarbageCollected, v1.GarbageCollectedReasonInstanceTerminated,
+			fmt.Sprintf("Instance %s was terminated outside of Karpenter", nodeClaims[i].Status.ProviderID))
+		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+		// can cause races due to the fact that it fully replaces the list on a change
+		// Here, we are updating the status condition list
+		if err := c.kubeClient.Status().Patch(ctx, nodeClaims[i], client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+			errs[i] = client.IgnoreNotFound(err)
+			return
+		}
 		if err := c.kubeClient.Delete(ctx, nodeClaims[i]); err != nil {
 			errs[i] = client.IgnoreNotFound(err)
 			return
@@ -110,6 +128,11 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 			metrics.NodePoolLabel:     nodeClaims[i].Labels[v1.NodePoolLabelKey],
 			metrics.CapacityTypeLabel: nodeClaims[i].Labels[v1.CapacityTypeLabelKey],
 		})
+		metrics.NodeClaimsGarbageCollectedTotal.Inc(map[string]string{
+			metrics.ReasonLabel:       v1.GarbageCollectedReasonInstanceTerminated,
+			metrics.NodePoolLabel:     nodeClaims[i].Labels[v1.NodePoolLabelKey],
+			metrics.CapacityTypeLabel: nodeClaims[i].Labels[v1.CapacityTypeLabelKey],
+		})
 	})
 	if err = multierr.Combine(errs...); err != nil {
 		return reconciler.Result{}, err
@@ -117,6 +140,27 @@ func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
 	return reconciler.Result{RequeueAfter: time.Minute * 2}, nil
 }
 
+// confirmMissing records a miss for each NodeClaim whose instance wasn't found and returns the NodeClaims that have
+// been missing for enough consecutive passes. Requiring repeated misses tolerates eventually consistent List responses
+// from the cloud provider that briefly omit healthy instances.
+func (c *Controller) confirmMissing(ctx context.Context, nodeClaims []*v1.NodeClaim) []*v1.NodeClaim {
+	missing := sets.New(lo.Map(nodeClaims, func(n *v1.NodeClaim, _ int) string { return n.Status.ProviderID })...)
+	for providerID := range c.misses {
+		if !missing.Has(providerID) {
+			delete(c.misses, providerID)
+		}
+	}
+	return lo.Filter(nodeClaims, func(n *v1.NodeClaim, _ int) bool {
+		c.misses[n.Status.ProviderID]++
+		if c.misses[n.Status.ProviderID] < options.FromContext(ctx).NodeClaimGCConfirmations {
+			log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(n), "provider-id", n.Status.ProviderID, "misses", c.misses[n.Status.ProviderID]).
+				V(1).Info("waiting to confirm nodeclaim has no cloudprovider representation")
+			return false
+		}
+		return true
+	})
+}
+
 func (c *Controller) Register(_ context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("nodeclaim.garbagecollection").
@@ -37,6 +37,7 @@ import (
 	nodeclaimgarbagecollection "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/garbagecollection"
 	nodeclaimlifcycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
 	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -80,6 +81,7 @@ var _ = Describe("GarbageCollection", func() {
 	var nodePool *v1.NodePool
 
 	BeforeEach(func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeClaimGCMinAge: lo.ToPtr(time.Duration(0)), NodeClaimGCConfirmations: lo.ToPtr(1)}))
 		nodePool = test.NodePool()
 	})
 	It("should delete the NodeClaim when the Node is there in a NotReady state and the instance is gone", func() {
@@ -109,6 +111,33 @@ var _ = Describe("GarbageCollection", func() {
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should record that the instance was terminated as the root cause of the NodeClaim's deletion", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+
+		nodeClaim, node, err := ExpectNodeClaimDeployed(ctx, env.Client, cloudProvider, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		ExpectMakeNodesNotReady(ctx, env.Client, node)
+		fakeClock.SetTime(time.Now().Add(time.Second * 20))
+		Expect(cloudProvider.Delete(ctx, nodeClaim)).To(Succeed())
+
+		ExpectSingletonReconciled(ctx, garbageCollectionController)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		garbageCollected := ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeGarbageCollected)
+		Expect(garbageCollected.Status).To(Equal(metav1.ConditionTrue))
+		Expect(garbageCollected.Reason).To(Equal(v1.GarbageCollectedReasonInstanceTerminated))
+		ExpectMetricCounterValue(metrics.NodeClaimsGarbageCollectedTotal, 1, map[string]string{
+			metrics.ReasonLabel:   v1.GarbageCollectedReasonInstanceTerminated,
+			metrics.NodePoolLabel: nodePool.Name,
+		})
+	})
 	It("shouldn't delete the NodeClaim when the Node is there in a Ready state and the instance is gone", func() {
 		nodeClaim := test.NodeClaim(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -220,4 +249,67 @@ var _ = Describe("GarbageCollection", func() {
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectExists(ctx, env.Client, nodeClaim)
 	})
+	It("shouldn't delete the NodeClaim when it's younger than the minimum age and the instance is gone", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeClaimGCMinAge: lo.ToPtr(time.Minute), NodeClaimGCConfirmations: lo.ToPtr(1)}))
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		nodeClaim, node, err := ExpectNodeClaimDeployed(ctx, env.Client, cloudProvider, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		ExpectMakeNodesNotReady(ctx, env.Client, node)
+
+		// Step forward, but not past the minimum age
+		fakeClock.SetTime(time.Now().Add(time.Second * 20))
+
+		// Delete the nodeClaim from the cloudprovider
+		Expect(cloudProvider.Delete(ctx, nodeClaim)).To(Succeed())
+
+		ExpectSingletonReconciled(ctx, garbageCollectionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		// Step forward past the minimum age
+		fakeClock.SetTime(time.Now().Add(time.Minute * 2))
+		ExpectSingletonReconciled(ctx, garbageCollectionController)
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should only delete the NodeClaim once the instance is missing for consecutive passes", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{NodeClaimGCMinAge: lo.ToPtr(time.Duration(0)), NodeClaimGCConfirmations: lo.ToPtr(2)}))
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		nodeClaim, node, err := ExpectNodeClaimDeployed(ctx, env.Client, cloudProvider, nodeClaim)
+		Expect(err).ToNot(HaveOccurred())
+		ExpectMakeNodesNotReady(ctx, env.Client, node)
+
+		// Step forward to move past the cache eventual consistency timeout
+		fakeClock.SetTime(time.Now().Add(time.Second * 20))
+
+		// The instance is briefly missing from the cloudprovider and comes back on the next pass
+		instance := cloudProvider.CreatedNodeClaims[nodeClaim.Status.ProviderID]
+		Expect(cloudProvider.Delete(ctx, nodeClaim)).To(Succeed())
+		ExpectSingletonReconciled(ctx, garbageCollectionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+		cloudProvider.CreatedNodeClaims[nodeClaim.Status.ProviderID] = instance
+		ExpectSingletonReconciled(ctx, garbageCollectionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		// Delete the nodeClaim from the cloudprovider, and expect it to be garbage collected after two passes
+		Expect(cloudProvider.Delete(ctx, nodeClaim)).To(Succeed())
+		ExpectSingletonReconciled(ctx, garbageCollectionController)
+		ExpectExists(ctx, env.Client, nodeClaim)
+		ExpectSingletonReconciled(ctx, garbageCollectionController)
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
 })
@@ -0,0 +1,158 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"go.uber.org/multierr"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/readonly"
+)
+
+const (
+	// maxBatchSize is the maximum number of interruptions handled in a single reconcile
+	maxBatchSize  = 100
+	pollingPeriod = time.Second
+)
+
+// Controller is a singleton controller that disrupts the NodeClaims of the instances that the CloudProvider signals are
+// going to be interrupted. Deleting the NodeClaim taints and drains its Node, and the provisioner launches replacement
+// capacity for the pods of the deleting Node in the meantime.
+type Controller struct {
+	kubeClient           client.Client
+	cloudProvider        cloudprovider.CloudProvider
+	interruptionProvider cloudprovider.InterruptionProvider
+	recorder             events.Recorder
+
+	// pending are the interruptions that failed to be handled and are retried on the next reconcile
+	pending []cloudprovider.Interruption
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, interruptionProvider cloudprovider.InterruptionProvider, recorder events.Recorder) *Controller {
+	return &Controller{
+		kubeClient:           kubeClient,
+		cloudProvider:        cloudProvider,
+		interruptionProvider: interruptionProvider,
+		recorder:             recorder,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodeclaim.interruption")
+
+	interruptions := append(c.pending, c.drain()...)
+	c.pending = nil
+	var errs []error
+	for _, interruption := range interruptions {
+		if err := c.handle(ctx, interruption); err != nil {
+			c.pending = append(c.pending, interruption)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return reconciler.Result{}, multierr.Combine(errs...)
+	}
+	return reconciler.Result{RequeueAfter: pollingPeriod}, nil
+}
+
+func (c *Controller) drain() []cloudprovider.Interruption {
+	var batch []cloudprovider.Interruption
+	for len(batch) < maxBatchSize {
+		select {
+		case interruption := <-c.interruptionProvider.Interruptions():
+			InterruptionsReceivedTotal.Inc(map[string]string{kindLabel: string(interruption.Kind)})
+			batch = append(batch, interruption)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (c *Controller) handle(ctx context.Context, interruption cloudprovider.Interruption) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("provider-id", interruption.ProviderID, "kind", interruption.Kind))
+	nodeClaims, err := nodeclaimutils.ListManaged(ctx, c.kubeClient, c.cloudProvider, nodeclaimutils.ForProviderID(interruption.ProviderID))
+	if err != nil {
+		return fmt.Errorf("listing nodeclaims for interruption, %w", err)
+	}
+	if len(nodeClaims) == 0 {
+		log.FromContext(ctx).V(1).Info("ignoring interruption, no nodeclaim has the provider id")
+		return nil
+	}
+	for _, nodeClaim := range nodeClaims {
+		if err := c.interrupt(ctx, nodeClaim, interruption); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Controller) interrupt(ctx context.Context, nodeClaim *v1.NodeClaim, interruption cloudprovider.Interruption) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("NodeClaim", klog.KObj(nodeClaim)))
+	if !nodeClaim.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	// In read-only mode the instance is left to be interrupted, after which garbage collection cleans up the NodeClaim
+	if readonly.Enabled(ctx) {
+		log.FromContext(ctx).V(1).Info("skipping deletion of interrupted nodeclaim, read-only mode is enabled")
+		return nil
+	}
+	c.recorder.Publish(InterruptedEvent(nodeClaim, interruption))
+	// Mark the NodeClaim as interrupted before deleting it so that the interrupted terminationGracePeriod override is
+	// applied when the NodeClaim is finalized
+	if !nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason).IsTrue() {
+		stored := nodeClaim.DeepCopy()
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, v1.DisruptionReasonInterrupted, string(interruption.Kind))
+		if err := c.kubeClient.Status().Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	if err := c.kubeClient.Delete(ctx, nodeClaim); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.FromContext(ctx).V(1).Info("deleting interrupted nodeclaim")
+	metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
+		metrics.ReasonLabel:       strings.ToLower(metrics.InterruptedReason),
+		metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
+	})
+	return nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodeclaim.interruption").
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
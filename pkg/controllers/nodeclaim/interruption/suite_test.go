@@ -0,0 +1,162 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interruption_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/interruption"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var interruptionController *interruption.Controller
+var env *test.Environment
+var cp *fake.CloudProvider
+var recorder *test.EventRecorder
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interruption")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeClaimProviderIDFieldIndexer(ctx)))
+	ctx = options.ToContext(ctx, test.Options())
+	cp = fake.NewCloudProvider()
+	recorder = test.NewEventRecorder()
+	interruptionController = interruption.NewController(env.Client, cp, cp, recorder)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options())
+	cp.Reset()
+	recorder.Reset()
+	metrics.NodeClaimsDisruptedTotal.Reset()
+	interruption.InterruptionsReceivedTotal.Reset()
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("Interruption", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodeClaim, _ = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:     nodePool.Name,
+					v1.CapacityTypeLabelKey: v1.CapacityTypeSpot,
+				},
+			},
+		})
+	})
+	It("should delete the nodeclaim of an interrupted instance", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		cp.InterruptionsChan <- cloudprovider.Interruption{ProviderID: nodeClaim.Status.ProviderID, Kind: cloudprovider.SpotInterruption, Deadline: time.Now().Add(2 * time.Minute)}
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+		Expect(recorder.Calls(events.Interrupted)).To(Equal(1))
+		ExpectMetricCounterValue(interruption.InterruptionsReceivedTotal, 1, map[string]string{"kind": string(cloudprovider.SpotInterruption)})
+		ExpectMetricCounterValue(metrics.NodeClaimsDisruptedTotal, 1, map[string]string{
+			metrics.ReasonLabel:       metrics.InterruptedReason,
+			metrics.NodePoolLabel:     nodePool.Name,
+			metrics.CapacityTypeLabel: v1.CapacityTypeSpot,
+		})
+	})
+	It("should mark the nodeclaim as interrupted before deleting it", func() {
+		nodeClaim.Finalizers = []string{v1.TerminationFinalizer}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		cp.InterruptionsChan <- cloudprovider.Interruption{ProviderID: nodeClaim.Status.ProviderID, Kind: cloudprovider.ScheduledMaintenance}
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		condition := nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason)
+		Expect(condition.IsTrue()).To(BeTrue())
+		Expect(condition.Reason).To(Equal(v1.DisruptionReasonInterrupted))
+		Expect(condition.Message).To(Equal(string(cloudprovider.ScheduledMaintenance)))
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+	})
+	It("should ignore interruptions for instances without a nodeclaim", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		cp.InterruptionsChan <- cloudprovider.Interruption{ProviderID: test.RandomProviderID(), Kind: cloudprovider.SpotInterruption}
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		ExpectExists(ctx, env.Client, nodeClaim)
+		ExpectMetricCounterValue(interruption.InterruptionsReceivedTotal, 1, map[string]string{"kind": string(cloudprovider.SpotInterruption)})
+	})
+	It("should not delete interrupted nodeclaims when read-only mode is enabled", func() {
+		modeFile := filepath.Join(GinkgoT().TempDir(), "read-only")
+		Expect(os.WriteFile(modeFile, []byte("true"), 0600)).To(Succeed())
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ReadOnlyModeFile: lo.ToPtr(modeFile)}))
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		cp.InterruptionsChan <- cloudprovider.Interruption{ProviderID: nodeClaim.Status.ProviderID, Kind: cloudprovider.InstanceStopping}
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		ExpectExists(ctx, env.Client, nodeClaim)
+	})
+	It("should handle every interruption that has been signaled", func() {
+		nodeClaims := []*v1.NodeClaim{nodeClaim}
+		for range 2 {
+			nc, _ := test.NodeClaimAndNode(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+			nodeClaims = append(nodeClaims, nc)
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		for _, nc := range nodeClaims {
+			ExpectApplied(ctx, env.Client, nc)
+			cp.InterruptionsChan <- cloudprovider.Interruption{ProviderID: nc.Status.ProviderID, Kind: cloudprovider.InstanceTerminating}
+		}
+		ExpectSingletonReconciled(ctx, interruptionController)
+
+		for _, nc := range nodeClaims {
+			ExpectNotFound(ctx, env.Client, nc)
+		}
+	})
+})
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/awslabs/operatorpkg/option"
 	"github.com/patrickmn/go-cache"
 	"github.com/samber/lo"
 	"go.uber.org/multierr"
@@ -49,7 +50,6 @@ import (
 	"sigs.k8s.io/karpenter/pkg/operator/injection"
 	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
-	"sigs.k8s.io/karpenter/pkg/utils/result"
 )
 
 const (
@@ -70,19 +70,45 @@ type Controller struct {
 	registration   *Registration
 	initialization *Initialization
 	liveness       *Liveness
+	stateMachine   *StateMachine
 }
 
-func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) *Controller {
-	return &Controller{
+type ControllerOptions struct {
+	nodeAttestor cloudprovider.NodeAttestor
+}
+
+// WithNodeAttestor sets the cloudprovider hook that attests the identity of Nodes before they're registered
+func WithNodeAttestor(nodeAttestor cloudprovider.NodeAttestor) option.Function[ControllerOptions] {
+	return func(o *ControllerOptions) {
+		o.nodeAttestor = nodeAttestor
+	}
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder, opts ...option.Function[ControllerOptions]) *Controller {
+	o := option.Resolve(opts...)
+	c := &Controller{
 		kubeClient:    kubeClient,
 		cloudProvider: cloudProvider,
 		recorder:      recorder,
 
 		launch:         &Launch{kubeClient: kubeClient, cloudProvider: cloudProvider, cache: cache.New(time.Hour, time.Minute), recorder: recorder},
-		registration:   &Registration{kubeClient: kubeClient, recorder: recorder},
-		initialization: &Initialization{kubeClient: kubeClient},
+		registration:   &Registration{kubeClient: kubeClient, recorder: recorder, nodeAttestor: o.nodeAttestor},
+		initialization: &Initialization{clock: clk, kubeClient: kubeClient, recorder: recorder},
 		liveness:       &Liveness{clock: clk, kubeClient: kubeClient},
+		stateMachine:   NewStateMachine(clk),
 	}
+	c.stateMachine.OnState(StatePending, c.launch)
+	c.stateMachine.OnState(StateLaunched, c.registration)
+	c.stateMachine.OnState(StateRegistered, c.initialization)
+	// Liveness times out NodeClaims that fail to launch, register or initialize, so it runs from the start
+	c.stateMachine.OnState(StatePending, c.liveness)
+	return c
+}
+
+// StateMachine returns the StateMachine that tracks the lifecycle State of NodeClaims so that callers can register
+// hooks on its transitions
+func (c *Controller) StateMachine() *StateMachine {
+	return c.stateMachine
 }
 
 func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
@@ -145,18 +171,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 	}
 
 	stored = nodeClaim.DeepCopy()
-	var results []reconcile.Result
-	var errs error
-	for _, reconciler := range []reconcile.TypedReconciler[*v1.NodeClaim]{
-		c.launch,
-		c.registration,
-		c.initialization,
-		c.liveness,
-	} {
-		res, err := reconciler.Reconcile(ctx, nodeClaim)
-		errs = multierr.Append(errs, err)
-		results = append(results, res)
-	}
+	res, errs := c.stateMachine.Reconcile(ctx, nodeClaim)
 	if !equality.Semantic.DeepEqual(stored, nodeClaim) {
 		statusCopy := nodeClaim.DeepCopy()
 		if err := c.kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
@@ -174,7 +189,7 @@ func (c *Controller) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (re
 	if errs != nil {
 		return reconcile.Result{}, errs
 	}
-	return result.Min(results...), nil
+	return res, nil
 }
 
 //nolint:gocyclo
@@ -188,6 +203,12 @@ func (c *Controller) finalize(ctx context.Context, nodeClaim *v1.NodeClaim) (rec
 		}
 		return reconcile.Result{}, fmt.Errorf("adding nodeclaim terminationGracePeriod annotation, %w", err)
 	}
+	if err := c.transition(ctx, nodeClaim); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
 
 	// Only delete Nodes if the NodeClaim has been registered. Deleting Nodes without the termination finalizer
 	// may result in leaked leases due to a kubelet bug until k8s 1.29. The Node should be garbage collected after the
@@ -243,7 +264,16 @@ func (c *Controller) finalize(ctx context.Context, nodeClaim *v1.NodeClaim) (rec
 		})
 	}
 	stored := nodeClaim.DeepCopy() // The NodeClaim may have been modified in the EnsureTerminated function
+	if err := c.stateMachine.Transition(ctx, nodeClaim); err != nil {
+		return reconcile.Result{}, err
+	}
 	controllerutil.RemoveFinalizer(nodeClaim, v1.TerminationFinalizer)
+	// Termination holds that timed out no longer block the deletion of the NodeClaim
+	if nodeClaim.StatusConditions().Get(v1.ConditionTypeTerminationHoldsReleased).IsFalse() {
+		for _, hold := range nodeClaim.TerminationHolds() {
+			controllerutil.RemoveFinalizer(nodeClaim, v1.TerminationHoldFinalizerPrefix+hold)
+		}
+	}
 	if !equality.Semantic.DeepEqual(stored, nodeClaim) {
 		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
 		// can cause races due to the fact that it fully replaces the list on a change
@@ -268,6 +298,19 @@ func (c *Controller) finalize(ctx context.Context, nodeClaim *v1.NodeClaim) (rec
 
 }
 
+// transition moves the NodeClaim to its current lifecycle State and persists it if it changed
+func (c *Controller) transition(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	stored := nodeClaim.DeepCopy()
+	if err := c.stateMachine.Transition(ctx, nodeClaim); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(stored, nodeClaim) {
+		return nil
+	}
+	// We use client.MergeFromWithOptimisticLock so that we don't record a State from a stale view of the NodeClaim
+	return c.kubeClient.Patch(ctx, nodeClaim, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{}))
+}
+
 func (c *Controller) ensureTerminationGracePeriodTerminationTimeAnnotation(ctx context.Context, nodeClaim *v1.NodeClaim) error {
 	// if the expiration annotation is already set, we don't need to do anything
 	if _, exists := nodeClaim.Annotations[v1.NodeClaimTerminationTimestampAnnotationKey]; exists {
@@ -277,8 +320,13 @@ func (c *Controller) ensureTerminationGracePeriodTerminationTimeAnnotation(ctx c
 	// In Kubernetes, every object has a terminationGracePeriodSeconds, defaulted to and un-changeable from 0. There is an additional TerminationGracePeriodSeconds in the PodSpec which can be configured.
 	// We use the kubernetes object TerminationGracePeriod to infer that the DeletionTimestamp is always equal to the time the NodeClaim is deleted.
 	// This should not be confused with the NodeClaim.spec.terminationGracePeriod field introduced in Karpenter Custom Resources.
-	if nodeClaim.Spec.TerminationGracePeriod != nil && !nodeClaim.DeletionTimestamp.IsZero() {
-		terminationTimeString := nodeClaim.DeletionTimestamp.Time.Add(nodeClaim.Spec.TerminationGracePeriod.Duration).Format(time.RFC3339)
+	// The NodeClaim's TerminationGracePeriodOverrides are applied based on the reason that the NodeClaim was disrupted.
+	var reason string
+	if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason); cond.IsTrue() {
+		reason = cond.Reason
+	}
+	if terminationGracePeriod := nodeClaim.TerminationGracePeriodFor(reason); terminationGracePeriod != nil && !nodeClaim.DeletionTimestamp.IsZero() {
+		terminationTimeString := nodeClaim.DeletionTimestamp.Time.Add(terminationGracePeriod.Duration).Format(time.RFC3339)
 		return c.annotateTerminationGracePeriodTerminationTime(ctx, nodeClaim, terminationTimeString)
 	}
 
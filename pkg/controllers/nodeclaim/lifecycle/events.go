@@ -18,7 +18,10 @@ package lifecycle
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -35,6 +38,28 @@ func InsufficientCapacityErrorEvent(nodeClaim *v1.NodeClaim, err error) events.E
 	}
 }
 
+func LaunchFallbackEvent(nodeClaim *v1.NodeClaim, err error) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.LaunchFallback,
+		Message:        fmt.Sprintf("Retrying launch without the offerings that lacked capacity: %s", truncateMessage(err.Error())),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
+
+// NominatedNodeClaimLaunchFailedEvent is published to the pods that a NodeClaim was created for when the NodeClaim fails
+// to launch, so that workload owners can see why their pods are still pending
+func NominatedNodeClaimLaunchFailedEvent(pod *corev1.Pod, nodeClaim *v1.NodeClaim, err error) events.Event {
+	return events.Event{
+		InvolvedObject: pod,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.NominatedNodeClaimLaunchFailed,
+		Message:        fmt.Sprintf("Failed to launch NodeClaim %s that was nominated for the pod: %s", nodeClaim.Name, truncateMessage(err.Error())),
+		DedupeValues:   []string{string(pod.UID), string(nodeClaim.UID)},
+	}
+}
+
 func NodeClassNotReadyEvent(nodeClaim *v1.NodeClaim, err error) events.Event {
 	return events.Event{
 		InvolvedObject: nodeClaim,
@@ -45,6 +70,53 @@ func NodeClassNotReadyEvent(nodeClaim *v1.NodeClaim, err error) events.Event {
 	}
 }
 
+// StartupTaintsTimedOutEvent is published when a NodeClaim is marked Unhealthy for replacement because its startup
+// taints weren't removed within the startup taint timeout
+func StartupTaintsTimedOutEvent(nodeClaim *v1.NodeClaim, taint *corev1.Taint, timeout time.Duration) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.StartupTaintsTimedOut,
+		Message:        fmt.Sprintf("StartupTaint %q wasn't removed within %s, replacing the NodeClaim", formatTaint(taint), timeout),
+		DedupeValues:   []string{string(nodeClaim.UID)},
+	}
+}
+
+// StartupTaintsForceRemovedEvent is published when the startup taints of a NodeClaim's node are removed by Karpenter
+// because they weren't removed within the startup taint timeout
+func StartupTaintsForceRemovedEvent(nodeClaim *v1.NodeClaim, taints []corev1.Taint, timeout time.Duration) events.Event {
+	return events.Event{
+		InvolvedObject: nodeClaim,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.StartupTaintsForceRemoved,
+		Message: fmt.Sprintf("Removed StartupTaints %s that weren't removed within %s", strings.Join(lo.Map(taints, func(t corev1.Taint, _ int) string {
+			return fmt.Sprintf("%q", formatTaint(&t))
+		}), ", "), timeout),
+		DedupeValues: []string{string(nodeClaim.UID)},
+	}
+}
+
+// NodeVerificationFailedEvent is published when the registration of a Node that claims a NodeClaim's instance is
+// rejected because the Node couldn't be verified to run on it
+func NodeVerificationFailedEvent(nodeClaim *v1.NodeClaim, node *corev1.Node, err error) []events.Event {
+	return []events.Event{
+		{
+			InvolvedObject: nodeClaim,
+			Type:           corev1.EventTypeWarning,
+			Reason:         events.NodeVerificationFailed,
+			Message:        fmt.Sprintf("Rejected the registration of Node %s: %s", node.Name, truncateMessage(err.Error())),
+			DedupeValues:   []string{string(nodeClaim.UID), string(node.UID)},
+		},
+		{
+			InvolvedObject: node,
+			Type:           corev1.EventTypeWarning,
+			Reason:         events.NodeVerificationFailed,
+			Message:        fmt.Sprintf("Rejected the registration for NodeClaim %s: %s", nodeClaim.Name, truncateMessage(err.Error())),
+			DedupeValues:   []string{string(node.UID), string(nodeClaim.UID)},
+		},
+	}
+}
+
 func UnregisteredTaintMissingEvent(nodeClaim *v1.NodeClaim) events.Event {
 	return events.Event{
 		InvolvedObject: nodeClaim,
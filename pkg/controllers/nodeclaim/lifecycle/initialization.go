@@ -23,11 +23,14 @@ import (
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
@@ -35,7 +38,9 @@ import (
 )
 
 type Initialization struct {
+	clock      clock.Clock
 	kubeClient client.Client
+	recorder   events.Recorder
 }
 
 // Reconcile checks for initialization based on if:
@@ -47,6 +52,10 @@ func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim)
 	if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized); !cond.IsUnknown() {
 		// Ensure that we always set the status condition to the latest generation
 		nodeClaim.StatusConditions().Set(*cond)
+		// Backfill the initialization time of NodeClaims that initialized before it was recorded
+		if cond.IsTrue() && nodeClaim.Status.InitializedAt == nil {
+			nodeClaim.Status.InitializedAt = lo.ToPtr(cond.LastTransitionTime)
+		}
 		return reconcile.Result{}, nil
 	}
 	if !nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue() {
@@ -63,7 +72,12 @@ func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim)
 	}
 	if taint, ok := StartupTaintsRemoved(node, nodeClaim); !ok {
 		nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeInitialized, "StartupTaintsExist", fmt.Sprintf("StartupTaint %q still exists", formatTaint(taint)))
-		return reconcile.Result{}, nil
+		if result, err := i.reconcileStartupTaintTimeout(ctx, nodeClaim, node, taint); err != nil || !lo.IsEmpty(result) {
+			return result, err
+		}
+		if _, ok = StartupTaintsRemoved(node, nodeClaim); !ok {
+			return reconcile.Result{}, nil
+		}
 	}
 	if taint, ok := KnownEphemeralTaintsRemoved(node); !ok {
 		nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeInitialized, "KnownEphemeralTaintsExist", fmt.Sprintf("KnownEphemeralTaint %q still exists", formatTaint(taint)))
@@ -82,6 +96,43 @@ func (i *Initialization) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim)
 	}
 	log.FromContext(ctx).WithValues("allocatable", node.Status.Allocatable).Info("initialized nodeclaim")
 	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+	nodeClaim.Status.InitializedAt = lo.ToPtr(nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).LastTransitionTime)
+	if nodeClaim.Status.RegisteredAt != nil {
+		NodeClaimInitializationDurationSeconds.Observe(nodeClaim.Status.InitializedAt.Sub(nodeClaim.Status.RegisteredAt.Time).Seconds(), startupDurationLabels(nodeClaim))
+	}
+	return reconcile.Result{}, nil
+}
+
+// reconcileStartupTaintTimeout handles nodes whose startup taints haven't been removed within the startup taint timeout
+// since they registered. Depending on the startup taint timeout policy, the startup taints are either removed from the
+// node so that it can initialize, or the NodeClaim is marked Unhealthy so that it's replaced through node repair.
+func (i *Initialization) reconcileStartupTaintTimeout(ctx context.Context, nodeClaim *v1.NodeClaim, node *corev1.Node, taint *corev1.Taint) (reconcile.Result, error) {
+	timeout := options.FromContext(ctx).StartupTaintTimeout
+	if timeout == 0 || nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy).IsTrue() {
+		return reconcile.Result{}, nil
+	}
+	if timeUntilTimeout := timeout - i.clock.Since(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).LastTransitionTime.Time); timeUntilTimeout > 0 {
+		return reconcile.Result{RequeueAfter: timeUntilTimeout}, nil
+	}
+	if options.FromContext(ctx).StartupTaintTimeoutPolicy == options.StartupTaintTimeoutPolicyReplace {
+		log.FromContext(ctx).WithValues("taint", formatTaint(taint), "timeout", timeout).Info("marking nodeclaim unhealthy, startup taint wasn't removed")
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeUnhealthy, v1.UnhealthyReasonStartupTaintsTimedOut,
+			fmt.Sprintf("StartupTaint %q wasn't removed within %s", formatTaint(taint), timeout))
+		i.recorder.Publish(StartupTaintsTimedOutEvent(nodeClaim, taint, timeout))
+		return reconcile.Result{}, nil
+	}
+	stored := node.DeepCopy()
+	removed, kept := lo.FilterReject(node.Spec.Taints, func(t corev1.Taint, _ int) bool {
+		return lo.ContainsBy(nodeClaim.Spec.StartupTaints, func(startupTaint corev1.Taint) bool { return startupTaint.MatchTaint(&t) })
+	})
+	node.Spec.Taints = kept
+	// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+	// can cause races due to the fact that it fully replaces the list on a change
+	if err := i.kubeClient.Patch(ctx, node, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	log.FromContext(ctx).WithValues("taints", lo.Map(removed, func(t corev1.Taint, _ int) string { return formatTaint(&t) }), "timeout", timeout).Info("removed startup taints that weren't removed")
+	i.recorder.Publish(StartupTaintsForceRemovedEvent(nodeClaim, removed, timeout))
 	return reconcile.Result{}, nil
 }
 
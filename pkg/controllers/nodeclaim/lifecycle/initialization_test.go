@@ -17,8 +17,11 @@ limitations under the License.
 package lifecycle_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +29,9 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/node/health"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 )
@@ -206,6 +212,87 @@ var _ = Describe("Initialization", func() {
 		node = ExpectExists(ctx, env.Client, node)
 		Expect(node.Labels).To(HaveKeyWithValue(v1.NodeInitializedLabelKey, "true"))
 	})
+	It("should record when the nodeClaim launched, registered and initialized", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.LaunchedAt).ToNot(BeNil())
+		Expect(nodeClaim.Status.LaunchedAt.Time).To(Equal(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).LastTransitionTime.Time))
+		Expect(nodeClaim.Status.RegisteredAt).To(BeNil())
+		Expect(nodeClaim.Status.InitializedAt).To(BeNil())
+
+		node := test.Node(test.NodeOptions{
+			ProviderID: nodeClaim.Status.ProviderID,
+			Taints:     []corev1.Taint{v1.UnregisteredNoExecuteTaint},
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.RegisteredAt).ToNot(BeNil())
+		Expect(nodeClaim.Status.RegisteredAt.Time).To(Equal(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeRegistered).LastTransitionTime.Time))
+
+		ExpectMakeNodesReady(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.InitializedAt).ToNot(BeNil())
+		Expect(nodeClaim.Status.InitializedAt.Time).To(Equal(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeInitialized).LastTransitionTime.Time))
+	})
+	It("should record the registration and initialization durations of the nodeClaim", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		labels := map[string]string{
+			"nodepool":      nodePool.Name,
+			"instance_type": nodeClaim.Labels[corev1.LabelInstanceTypeStable],
+			"zone":          nodeClaim.Labels[corev1.LabelTopologyZone],
+			"capacity_type": nodeClaim.Labels[v1.CapacityTypeLabelKey],
+		}
+
+		node := test.Node(test.NodeOptions{
+			ProviderID: nodeClaim.Status.ProviderID,
+			Taints:     []corev1.Taint{v1.UnregisteredNoExecuteTaint},
+		})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectMetricHistogramSampleCountValue("karpenter_nodeclaims_registration_duration_seconds", 1, labels)
+
+		ExpectMakeNodesReady(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectMetricHistogramSampleCountValue("karpenter_nodeclaims_initialization_duration_seconds", 1, labels)
+	})
+	It("should backfill the lifecycle timestamps of nodeClaims that transitioned before they were recorded", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Status.LaunchedAt).ToNot(BeNil())
+		Expect(nodeClaim.Status.RegisteredAt).ToNot(BeNil())
+		Expect(nodeClaim.Status.InitializedAt).ToNot(BeNil())
+	})
 	It("should not consider the Node to be initialized when the status of the Node is NotReady", func() {
 		nodeClaim := test.NodeClaim(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -655,4 +742,92 @@ var _ = Describe("Initialization", func() {
 		Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeRegistered).Status).To(Equal(metav1.ConditionTrue))
 		Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeInitialized).Status).To(Equal(metav1.ConditionTrue))
 	})
+	Context("Startup Taint Timeout", func() {
+		var nodeClaim *v1.NodeClaim
+		var node *corev1.Node
+		startupTaint := corev1.Taint{
+			Key:    "custom-startup-taint",
+			Effect: corev1.TaintEffectNoSchedule,
+			Value:  "custom-startup-value",
+		}
+
+		BeforeEach(func() {
+			recorder.Reset()
+			nodeClaim = test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey: nodePool.Name,
+					},
+				},
+				Spec: v1.NodeClaimSpec{
+					StartupTaints: []corev1.Taint{startupTaint},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+			node = test.Node(test.NodeOptions{
+				ProviderID: nodeClaim.Status.ProviderID,
+				Taints:     []corev1.Taint{v1.UnregisteredNoExecuteTaint},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectMakeNodesReady(ctx, env.Client, node)
+		})
+		It("should replace the nodeClaim once the startup taint timeout elapses", func() {
+			timeoutCtx := options.ToContext(ctx, test.Options(test.OptionsFields{StartupTaintTimeout: lo.ToPtr(5 * time.Minute)}))
+			ExpectObjectReconciled(timeoutCtx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeInitialized).Status).To(Equal(metav1.ConditionUnknown))
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)).To(BeNil())
+
+			fakeClock.Step(6 * time.Minute)
+			ExpectObjectReconciled(timeoutCtx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeInitialized).Status).To(Equal(metav1.ConditionUnknown))
+			unhealthy := ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeUnhealthy)
+			Expect(unhealthy.Status).To(Equal(metav1.ConditionTrue))
+			Expect(unhealthy.Reason).To(Equal(v1.UnhealthyReasonStartupTaintsTimedOut))
+			Expect(recorder.Calls(events.StartupTaintsTimedOut)).To(Equal(1))
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).To(ContainElement(startupTaint))
+		})
+		It("should keep the nodeClaim unhealthy when node health reconciles its healthy node", func() {
+			timeoutCtx := options.ToContext(ctx, test.Options(test.OptionsFields{StartupTaintTimeout: lo.ToPtr(5 * time.Minute)}))
+			healthController := health.NewController(env.Client, cloudProvider, fakeClock, recorder)
+			fakeClock.Step(6 * time.Minute)
+			ExpectObjectReconciled(timeoutCtx, env.Client, nodeClaimController, nodeClaim)
+			Expect(ExpectStatusConditionExists(ExpectExists(ctx, env.Client, nodeClaim), v1.ConditionTypeUnhealthy).Status).To(Equal(metav1.ConditionTrue))
+
+			// Node health only clears the Unhealthy conditions that it set for node conditions
+			ExpectObjectReconciled(timeoutCtx, env.Client, healthController, node)
+			ExpectObjectReconciled(timeoutCtx, env.Client, nodeClaimController, nodeClaim)
+			unhealthy := ExpectStatusConditionExists(ExpectExists(ctx, env.Client, nodeClaim), v1.ConditionTypeUnhealthy)
+			Expect(unhealthy.Status).To(Equal(metav1.ConditionTrue))
+			Expect(unhealthy.Reason).To(Equal(v1.UnhealthyReasonStartupTaintsTimedOut))
+			Expect(recorder.Calls(events.StartupTaintsTimedOut)).To(Equal(1))
+		})
+		It("should remove the startup taints once the startup taint timeout elapses", func() {
+			timeoutCtx := options.ToContext(ctx, test.Options(test.OptionsFields{
+				StartupTaintTimeout:       lo.ToPtr(5 * time.Minute),
+				StartupTaintTimeoutPolicy: lo.ToPtr(options.StartupTaintTimeoutPolicyRemove),
+			}))
+			fakeClock.Step(6 * time.Minute)
+			ExpectObjectReconciled(timeoutCtx, env.Client, nodeClaimController, nodeClaim)
+			node = ExpectExists(ctx, env.Client, node)
+			Expect(node.Spec.Taints).ToNot(ContainElement(startupTaint))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeInitialized).Status).To(Equal(metav1.ConditionTrue))
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)).To(BeNil())
+			Expect(recorder.Calls(events.StartupTaintsForceRemoved)).To(Equal(1))
+		})
+		It("should wait for the startup taints when the startup taint timeout is disabled", func() {
+			fakeClock.Step(time.Hour)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeInitialized).Status).To(Equal(metav1.ConditionUnknown))
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy)).To(BeNil())
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).To(ContainElement(startupTaint))
+		})
+	})
 })
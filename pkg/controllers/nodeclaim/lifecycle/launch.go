@@ -20,10 +20,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
+	"github.com/awslabs/operatorpkg/serrors"
 	"github.com/patrickmn/go-cache"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -32,7 +35,11 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 type Launch struct {
@@ -49,6 +56,10 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconc
 		if cond.IsTrue() {
 			// Once the NodeClaim has successfully marked as launched, we no longer need to store it
 			l.cache.Delete(string(nodeClaim.UID))
+			// Backfill the launch time of NodeClaims that launched before it was recorded
+			if nodeClaim.Status.LaunchedAt == nil {
+				nodeClaim.Status.LaunchedAt = lo.ToPtr(cond.LastTransitionTime)
+			}
 		}
 		return reconcile.Result{}, nil
 	}
@@ -59,10 +70,14 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconc
 	// One of the following scenarios can happen with a NodeClaim that isn't marked as launched:
 	//  1. It was already launched by the CloudProvider but the client-go cache wasn't updated quickly enough or
 	//     patching failed on the status. In this case, we use the in-memory cached value for the created NodeClaim.
-	//  2. It is a standard NodeClaim launch where we should call CloudProvider Create() and fill in details of the launched
+	//  2. It adopts an existing instance, in which case we call CloudProvider Get() and fill in details of the
+	//     instance into the NodeClaim CR.
+	//  3. It is a standard NodeClaim launch where we should call CloudProvider Create() and fill in details of the launched
 	//     NodeClaim into the NodeClaim CR.
 	if ret, ok := l.cache.Get(string(nodeClaim.UID)); ok {
 		created = ret.(*v1.NodeClaim)
+	} else if providerID, ok := nodeClaim.Annotations[v1.NodeClaimAdoptedInstanceAnnotationKey]; ok {
+		created, err = l.adoptInstance(ctx, nodeClaim, providerID)
 	} else {
 		created, err = l.launchNodeClaim(ctx, nodeClaim)
 	}
@@ -72,32 +87,51 @@ func (l *Launch) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconc
 	}
 	l.cache.SetDefault(string(nodeClaim.UID), created)
 	nodeClaim = PopulateNodeClaimDetails(nodeClaim, created)
+	// Record the price that the NodeClaim was launched at so that its cost can be tracked for cost-based expiration
+	if nodeClaim.Spec.ExpireAfterCost != nil {
+		if price, err := l.launchPrice(ctx, nodeClaim); err != nil {
+			log.FromContext(ctx).Error(err, "failed determining launch price, nodeclaim won't expire based on cost")
+		} else {
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimLaunchPriceAnnotationKey: strconv.FormatFloat(price, 'f', -1, 64)})
+		}
+	}
 	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+	nodeClaim.Status.LaunchedAt = lo.ToPtr(nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).LastTransitionTime)
 	return reconcile.Result{}, nil
 }
 
 func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
-	created, err := l.cloudProvider.Create(ctx, nodeClaim)
+	created, err := l.create(ctx, nodeClaim)
 	if err != nil {
 		switch {
 		case cloudprovider.IsInsufficientCapacityError(err):
 			l.recorder.Publish(InsufficientCapacityErrorEvent(nodeClaim, err))
+			if options.FromContext(ctx).FeatureGates.NominatedPods {
+				l.publishNominatedPodEvents(ctx, nodeClaim, err)
+			}
 			log.FromContext(ctx).Error(err, "failed launching nodeclaim")
 
+			launchErr := err
 			if err = l.kubeClient.Delete(ctx, nodeClaim); err != nil {
 				return nil, client.IgnoreNotFound(err)
 			}
+			markGarbageCollected(nodeClaim, v1.GarbageCollectedReasonLaunchFailed, launchErr.Error())
 			metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
 				metrics.ReasonLabel:       "insufficient_capacity",
 				metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
 				metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
 			})
+			if err = nodepoolutils.RecordProvisioningFailure(ctx, l.kubeClient, nodeClaim.Labels[v1.NodePoolLabelKey]); err != nil {
+				log.FromContext(ctx).Error(err, "failed recording provisioning failure on nodepool")
+			}
 			return nil, nil
 		case cloudprovider.IsNodeClassNotReadyError(err):
 			log.FromContext(ctx).Error(err, "failed launching nodeclaim")
+			launchErr := err
 			if err = l.kubeClient.Delete(ctx, nodeClaim); err != nil {
 				return nil, client.IgnoreNotFound(err)
 			}
+			markGarbageCollected(nodeClaim, v1.GarbageCollectedReasonLaunchFailed, launchErr.Error())
 			metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
 				metrics.ReasonLabel:       "nodeclass_not_ready",
 				metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
@@ -123,6 +157,177 @@ func (l *Launch) launchNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim) (
 	return created, nil
 }
 
+// adoptInstance resolves the existing instance that the NodeClaim adopts. NodeClaims whose instance no longer exists
+// are deleted.
+func (l *Launch) adoptInstance(ctx context.Context, nodeClaim *v1.NodeClaim, providerID string) (*v1.NodeClaim, error) {
+	instance, err := l.cloudProvider.Get(ctx, providerID)
+	if err != nil {
+		if cloudprovider.IsNodeClaimNotFoundError(err) {
+			log.FromContext(ctx).WithValues("provider-id", providerID).Error(err, "failed adopting instance")
+			return nil, client.IgnoreNotFound(l.kubeClient.Delete(ctx, nodeClaim))
+		}
+		nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeLaunched, "AdoptionFailed", truncateMessage(err.Error()))
+		return nil, fmt.Errorf("getting adopted instance, %w", err)
+	}
+	log.FromContext(ctx).WithValues(
+		"provider-id", instance.Status.ProviderID,
+		"instance-type", instance.Labels[corev1.LabelInstanceTypeStable],
+		"zone", instance.Labels[corev1.LabelTopologyZone],
+		"capacity-type", instance.Labels[v1.CapacityTypeLabelKey]).Info("adopted instance")
+	return instance, nil
+}
+
+// create launches the NodeClaim. When the CloudProvider reports which offerings lacked capacity, the launch is retried
+// with those offerings removed from the NodeClaim's requirements, falling back to the next-best instance types and
+// zones, until no compatible offerings remain or the fallback attempts run out.
+func (l *Launch) create(ctx context.Context, nodeClaim *v1.NodeClaim) (*v1.NodeClaim, error) {
+	created, err := l.cloudProvider.Create(ctx, nodeClaim)
+	var unavailable []cloudprovider.UnavailableOffering
+	for attempt := 0; attempt < options.FromContext(ctx).LaunchFallbackAttempts && cloudprovider.IsInsufficientCapacityError(err); attempt++ {
+		offerings := cloudprovider.UnavailableOfferingsFromError(err)
+		if len(offerings) == 0 {
+			break
+		}
+		unavailable = append(unavailable, offerings...)
+		fallback, e := l.fallbackNodeClaim(ctx, nodeClaim, unavailable)
+		if e != nil {
+			log.FromContext(ctx).Error(e, "failed determining fallback offerings")
+			break
+		}
+		if fallback == nil {
+			break
+		}
+		l.recorder.Publish(LaunchFallbackEvent(nodeClaim, err))
+		log.FromContext(ctx).WithValues("attempt", attempt+1).Info("retrying launch without the offerings that lacked capacity")
+		created, err = l.cloudProvider.Create(ctx, fallback)
+	}
+	return created, err
+}
+
+// offeringKey identifies an offering by the labels that a NodeClaim's requirements can narrow its offerings by
+type offeringKey struct {
+	instanceType string
+	zone         string
+	capacityType string
+}
+
+// fallbackNodeClaim returns a copy of the NodeClaim whose requirements are narrowed to the instance types, zones and
+// capacity types of its remaining compatible offerings. Since requirements can only narrow each of these independently,
+// they are narrowed further until the combinations they admit exclude every offering that lacked capacity. It returns
+// nil if no offerings remain, or if too few remain to satisfy the NodeClaim's minValues.
+func (l *Launch) fallbackNodeClaim(ctx context.Context, nodeClaim *v1.NodeClaim, unavailable []cloudprovider.UnavailableOffering) (*v1.NodeClaim, error) {
+	instanceTypes, err := l.instanceTypes(ctx, nodeClaim)
+	if err != nil {
+		return nil, err
+	}
+	reqs := scheduling.NewNodeSelectorRequirementsWithMinValues(nodeClaim.Spec.Requirements...)
+	var remaining, excluded []offeringKey
+	for _, it := range instanceTypes {
+		if !reqs.IsCompatible(it.Requirements, scheduling.AllowUndefinedWellKnownLabels) || !resources.Fits(nodeClaim.Spec.Resources.Requests, it.Allocatable()) {
+			continue
+		}
+		for _, o := range it.Offerings.Compatible(reqs) {
+			key := offeringKey{instanceType: it.Name, zone: o.Zone(), capacityType: o.CapacityType()}
+			if !o.Available || lo.ContainsBy(unavailable, func(u cloudprovider.UnavailableOffering) bool { return u.Matches(it.Name, o) }) {
+				excluded = append(excluded, key)
+				continue
+			}
+			remaining = append(remaining, key)
+		}
+	}
+	names, zones, capacityTypes := offeringSets(remaining)
+	for {
+		e, ok := lo.Find(excluded, func(e offeringKey) bool {
+			return names.Has(e.instanceType) && zones.Has(e.zone) && capacityTypes.Has(e.capacityType)
+		})
+		if !ok {
+			break
+		}
+		// Drop whichever of the excluded offering's instance type, zone or capacity type keeps the most remaining
+		// offerings, preferring to drop the instance type
+		remaining = lo.MaxBy([][]offeringKey{
+			lo.Reject(remaining, func(k offeringKey, _ int) bool { return k.instanceType == e.instanceType }),
+			lo.Reject(remaining, func(k offeringKey, _ int) bool { return k.zone == e.zone }),
+			lo.Reject(remaining, func(k offeringKey, _ int) bool { return k.capacityType == e.capacityType }),
+		}, func(a, b []offeringKey) bool { return len(a) > len(b) })
+		names, zones, capacityTypes = offeringSets(remaining)
+	}
+	if len(remaining) == 0 {
+		return nil, nil
+	}
+	reqs.Add(
+		scheduling.NewRequirement(corev1.LabelInstanceTypeStable, corev1.NodeSelectorOpIn, sets.List(names)...),
+		scheduling.NewRequirement(corev1.LabelTopologyZone, corev1.NodeSelectorOpIn, sets.List(zones)...),
+		scheduling.NewRequirement(v1.CapacityTypeLabelKey, corev1.NodeSelectorOpIn, sets.List(capacityTypes)...),
+	)
+	for _, r := range reqs {
+		if r.MinValues != nil && r.Len() < *r.MinValues {
+			return nil, nil
+		}
+	}
+	fallback := nodeClaim.DeepCopy()
+	fallback.Spec.Requirements = reqs.NodeSelectorRequirements()
+	return fallback, nil
+}
+
+// offeringSets returns the instance types, zones and capacity types of the offerings
+func offeringSets(offerings []offeringKey) (sets.Set[string], sets.Set[string], sets.Set[string]) {
+	names, zones, capacityTypes := sets.New[string](), sets.New[string](), sets.New[string]()
+	for _, o := range offerings {
+		names.Insert(o.instanceType)
+		zones.Insert(o.zone)
+		capacityTypes.Insert(o.capacityType)
+	}
+	return names, zones, capacityTypes
+}
+
+// publishNominatedPodEvents fans the launch failure out to the pods that the NodeClaim was created for
+func (l *Launch) publishNominatedPodEvents(ctx context.Context, nodeClaim *v1.NodeClaim, err error) {
+	pods, e := nodeclaimutils.GetNominatedPods(nodeClaim)
+	if e != nil {
+		log.FromContext(ctx).Error(e, "failed getting nominated pods")
+		return
+	}
+	for _, pod := range pods {
+		l.recorder.Publish(NominatedNodeClaimLaunchFailedEvent(pod, nodeClaim, err))
+	}
+	NominatedPodEventsTotal.Add(float64(len(pods)), map[string]string{
+		metrics.NodePoolLabel: nodeClaim.Labels[v1.NodePoolLabelKey],
+	})
+}
+
+// launchPrice returns the price of the cheapest offering that's compatible with the launched NodeClaim's labels
+func (l *Launch) launchPrice(ctx context.Context, nodeClaim *v1.NodeClaim) (float64, error) {
+	instanceTypes, err := l.instanceTypes(ctx, nodeClaim)
+	if err != nil {
+		return 0, err
+	}
+	instanceType, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool {
+		return it.Name == nodeClaim.Labels[corev1.LabelInstanceTypeStable]
+	})
+	if !ok {
+		return 0, serrors.Wrap(fmt.Errorf("instance type not found"), "instance-type", nodeClaim.Labels[corev1.LabelInstanceTypeStable])
+	}
+	offerings := instanceType.Offerings.Compatible(scheduling.NewLabelRequirements(nodeClaim.Labels))
+	if len(offerings) == 0 {
+		return 0, serrors.Wrap(fmt.Errorf("unable to determine offering"), "instance-type", instanceType.Name, "capacity-type", nodeClaim.Labels[v1.CapacityTypeLabelKey], "zone", nodeClaim.Labels[corev1.LabelTopologyZone])
+	}
+	return offerings.Cheapest().Price, nil
+}
+
+// instanceTypes returns the instance types of the NodeClaim's NodePool
+func (l *Launch) instanceTypes(ctx context.Context, nodeClaim *v1.NodeClaim) ([]*cloudprovider.InstanceType, error) {
+	nodePool := &v1.NodePool{}
+	if err := l.kubeClient.Get(ctx, client.ObjectKey{Name: nodeClaim.Labels[v1.NodePoolLabelKey]}, nodePool); err != nil {
+		return nil, fmt.Errorf("getting nodepool, %w", err)
+	}
+	instanceTypes, err := l.cloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return nil, fmt.Errorf("getting instance types, %w", err)
+	}
+	return instanceTypes, nil
+}
+
 func PopulateNodeClaimDetails(nodeClaim, retrieved *v1.NodeClaim) *v1.NodeClaim {
 	// These are ordered in priority order so that user-defined nodeClaim labels and requirements trump retrieved labels
 	// or the static nodeClaim labels
@@ -22,12 +22,20 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 )
 
 var _ = Describe("Launch", func() {
@@ -86,6 +94,50 @@ var _ = Describe("Launch", func() {
 		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
 		Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).Status).To(Equal(metav1.ConditionTrue))
 	})
+	It("should record the launch price when the nodeclaim expires based on cost", func() {
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+			fake.NewInstanceType(fake.InstanceTypeOptions{
+				Name: "cost-instance-type",
+				Offerings: []*cloudprovider.Offering{{
+					Available: true,
+					Requirements: scheduling.NewLabelRequirements(map[string]string{
+						v1.CapacityTypeLabelKey:  v1.CapacityTypeOnDemand,
+						corev1.LabelTopologyZone: "test-zone-1",
+					}),
+					Price: 1.5,
+				}},
+			}),
+		}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+			Spec: v1.NodeClaimSpec{
+				ExpireAfterCost: lo.ToPtr("50"),
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimLaunchPriceAnnotationKey, "1.5"))
+	})
+	It("should not record the launch price when the nodeclaim doesn't expire based on cost", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.NodeClaimLaunchPriceAnnotationKey))
+	})
 	It("should delete the nodeclaim if InsufficientCapacity is returned from the cloudprovider", func() {
 		cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
 		nodeClaim := test.NodeClaim()
@@ -94,6 +146,191 @@ var _ = Describe("Launch", func() {
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should record the failed launch as the root cause of the nodeclaim's deletion", func() {
+		cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
+		nodeClaim := test.NodeClaim(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.DeletionTimestamp.IsZero()).To(BeFalse())
+		garbageCollected := ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeGarbageCollected)
+		Expect(garbageCollected.Status).To(Equal(metav1.ConditionTrue))
+		Expect(garbageCollected.Reason).To(Equal(v1.GarbageCollectedReasonLaunchFailed))
+		Expect(garbageCollected.Message).To(ContainSubstring("all instance types were unavailable"))
+		ExpectMetricCounterValue(metrics.NodeClaimsGarbageCollectedTotal, 1, map[string]string{
+			metrics.ReasonLabel:   v1.GarbageCollectedReasonLaunchFailed,
+			metrics.NodePoolLabel: nodePool.Name,
+		})
+	})
+	Context("Nominated Pods", func() {
+		var nodeClaim *v1.NodeClaim
+		var pods []*corev1.Pod
+		BeforeEach(func() {
+			recorder.Reset()
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
+			nodeClaim = test.NodeClaim(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+			pods = test.Pods(2, test.PodOptions{})
+			Expect(nodeclaimutils.SetNominatedPods(nodeClaim, pods)).To(Succeed())
+		})
+		It("should publish launch failures to the nominated pods", func() {
+			nominatedCtx := options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NominatedPods: lo.ToPtr(true)}}))
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(nominatedCtx, env.Client, nodeClaimController, nodeClaim)
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+
+			Expect(recorder.Calls(events.NominatedNodeClaimLaunchFailed)).To(Equal(2))
+			recorder.ForEachEvent(func(evt events.Event) {
+				if evt.Reason == events.NominatedNodeClaimLaunchFailed {
+					Expect(lo.Map(pods, func(p *corev1.Pod, _ int) string { return p.Name })).To(ContainElement(evt.InvolvedObject.(*corev1.Pod).Name))
+				}
+			})
+			ExpectMetricCounterValue(nodeclaimlifecycle.NominatedPodEventsTotal, 2, map[string]string{
+				metrics.NodePoolLabel: nodePool.Name,
+			})
+		})
+		It("should not publish launch failures to the nominated pods when the feature gate is disabled", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+
+			Expect(recorder.Calls(events.NominatedNodeClaimLaunchFailed)).To(Equal(0))
+		})
+	})
+	Context("Launch Fallback", func() {
+		var nodeClaim *v1.NodeClaim
+		BeforeEach(func() {
+			recorder.Reset()
+			offering := func(zone string, price float64) *cloudprovider.Offering {
+				return &cloudprovider.Offering{
+					Available: true,
+					Requirements: scheduling.NewLabelRequirements(map[string]string{
+						v1.CapacityTypeLabelKey:  v1.CapacityTypeOnDemand,
+						corev1.LabelTopologyZone: zone,
+					}),
+					Price: price,
+				}
+			}
+			cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:      "small-instance-type",
+					Offerings: []*cloudprovider.Offering{offering("test-zone-1", 1), offering("test-zone-2", 1.5)},
+				}),
+				fake.NewInstanceType(fake.InstanceTypeOptions{
+					Name:      "large-instance-type",
+					Offerings: []*cloudprovider.Offering{offering("test-zone-1", 2)},
+				}),
+			}
+			nodeClaim = test.NodeClaim(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name}}})
+		})
+		It("should retry the launch without the instance types that lacked capacity", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityErrorForOfferings(fmt.Errorf("small-instance-type was unavailable"), cloudprovider.UnavailableOffering{InstanceType: "small-instance-type"})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).Status).To(Equal(metav1.ConditionTrue))
+			Expect(nodeClaim.Labels).To(HaveKeyWithValue(corev1.LabelInstanceTypeStable, "large-instance-type"))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(recorder.Calls(events.LaunchFallback)).To(Equal(1))
+		})
+		It("should retry the launch without the zones that lacked capacity", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityErrorForOfferings(fmt.Errorf("test-zone-1 was unavailable"), cloudprovider.UnavailableOffering{Zone: "test-zone-1"})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).Status).To(Equal(metav1.ConditionTrue))
+			Expect(nodeClaim.Labels).To(HaveKeyWithValue(corev1.LabelInstanceTypeStable, "small-instance-type"))
+			Expect(nodeClaim.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-2"))
+		})
+		It("should not retry the launch with the offering that lacked capacity", func() {
+			// small-instance-type in test-zone-2 and large-instance-type in test-zone-1 remain, but narrowing each label
+			// independently would re-admit small-instance-type in test-zone-1
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityErrorForOfferings(fmt.Errorf("small-instance-type was unavailable in test-zone-1"), cloudprovider.UnavailableOffering{InstanceType: "small-instance-type", Zone: "test-zone-1"})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).Status).To(Equal(metav1.ConditionTrue))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(nodeClaim.Labels).ToNot(And(
+				HaveKeyWithValue(corev1.LabelInstanceTypeStable, "small-instance-type"),
+				HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-1"),
+			))
+			reqs := scheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...)
+			Expect(reqs.Get(corev1.LabelInstanceTypeStable).Has("small-instance-type") && reqs.Get(corev1.LabelTopologyZone).Has("test-zone-1")).To(BeFalse())
+		})
+		It("should delete the nodeclaim when no compatible offerings remain", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityErrorForOfferings(fmt.Errorf("on-demand capacity was unavailable"), cloudprovider.UnavailableOffering{CapacityType: v1.CapacityTypeOnDemand})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+			Expect(recorder.Calls(events.LaunchFallback)).To(Equal(0))
+		})
+		It("should delete the nodeclaim when the cloudprovider doesn't report the offerings that lacked capacity", func() {
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityError(fmt.Errorf("all instance types were unavailable"))
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+		})
+		It("should not retry the launch when launch fallback is disabled", func() {
+			disabledCtx := options.ToContext(ctx, test.Options(test.OptionsFields{LaunchFallbackAttempts: lo.ToPtr(0)}))
+			cloudProvider.NextCreateErr = cloudprovider.NewInsufficientCapacityErrorForOfferings(fmt.Errorf("small-instance-type was unavailable"), cloudprovider.UnavailableOffering{InstanceType: "small-instance-type"})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(disabledCtx, env.Client, nodeClaimController, nodeClaim)
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+		})
+	})
+	Context("Adoption", func() {
+		It("should adopt the existing instance instead of launching one", func() {
+			providerID := test.RandomProviderID()
+			cloudProvider.CreatedNodeClaims[providerID] = test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{corev1.LabelInstanceTypeStable: "adopted-instance-type"},
+				},
+				Status: v1.NodeClaimStatus{ProviderID: providerID},
+			})
+			nodeClaim := test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+					Annotations: map[string]string{v1.NodeClaimAdoptedInstanceAnnotationKey: providerID},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeLaunched).Status).To(Equal(metav1.ConditionTrue))
+			Expect(nodeClaim.Status.ProviderID).To(Equal(providerID))
+			Expect(nodeClaim.Labels).To(HaveKeyWithValue(corev1.LabelInstanceTypeStable, "adopted-instance-type"))
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+		})
+		It("should delete the nodeclaim if the adopted instance doesn't exist", func() {
+			nodeClaim := test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+					Annotations: map[string]string{v1.NodeClaimAdoptedInstanceAnnotationKey: test.RandomProviderID()},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(0))
+		})
+	})
 	It("should delete the nodeclaim if NodeClassNotReady is returned from the cloudprovider", func() {
 		cloudProvider.NextCreateErr = cloudprovider.NewNodeClassNotReadyError(fmt.Errorf("nodeClass isn't ready"))
 		nodeClaim := test.NodeClaim()
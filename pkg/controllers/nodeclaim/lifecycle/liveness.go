@@ -18,8 +18,10 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/awslabs/operatorpkg/status"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -32,6 +34,7 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/metrics"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 )
 
 type Liveness struct {
@@ -39,15 +42,16 @@ type Liveness struct {
 	kubeClient client.Client
 }
 
-// registrationTimeout is a heuristic time that we expect the node to register within
+// registrationTimeout is a heuristic time that we expect the node to register within, unless the NodeClaim overrides it
 // launchTimeout is a heuristic time that we expect to be able to launch within
 // If we don't see the node within this time, then we should delete the NodeClaim and try again
 
 const (
-	registrationTimeout       = time.Minute * 15
-	registrationTimeoutReason = "registration_timeout"
-	launchTimeout             = time.Minute * 5
-	launchTimeoutReason       = "launch_timeout"
+	registrationTimeout         = time.Minute * 15
+	registrationTimeoutReason   = "registration_timeout"
+	launchTimeout               = time.Minute * 5
+	launchTimeoutReason         = "launch_timeout"
+	initializationTimeoutReason = "initialization_timeout"
 )
 
 type NodeClaimTimeout struct {
@@ -70,7 +74,7 @@ var (
 func (l *Liveness) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
 	registered := nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered)
 	if registered.IsTrue() {
-		return reconcile.Result{}, nil
+		return l.reconcileInitialization(ctx, nodeClaim, registered)
 	}
 	launched := nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched)
 	if launched == nil {
@@ -93,7 +97,8 @@ func (l *Liveness) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reco
 	}
 	// If the Registered statusCondition hasn't gone True during the timeout since we first updated it, we should terminate the NodeClaim
 	// NOTE: Timeout has to be stored and checked in the same place since l.clock can advance after the check causing a race
-	if timeUntilTimeout := registrationTimeout - l.clock.Since(registered.LastTransitionTime.Time); timeUntilTimeout > 0 {
+	timeout := RegistrationTimeoutFor(nodeClaim)
+	if timeUntilTimeout := timeout.duration - l.clock.Since(registered.LastTransitionTime.Time); timeUntilTimeout > 0 {
 		return reconcile.Result{RequeueAfter: timeUntilTimeout}, nil
 	}
 	if err := l.updateNodePoolRegistrationHealth(ctx, nodeClaim); client.IgnoreNotFound(err) != nil {
@@ -103,7 +108,7 @@ func (l *Liveness) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reco
 		return reconcile.Result{}, err
 	}
 	// Delete the NodeClaim if we believe the NodeClaim won't register since we haven't seen the node
-	if err := l.deleteNodeClaimForTimeout(ctx, RegistrationTimeout, nodeClaim); err != nil {
+	if err := l.deleteNodeClaimForTimeout(ctx, timeout, nodeClaim); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return reconcile.Result{}, err
 		}
@@ -112,6 +117,36 @@ func (l *Liveness) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reco
 	return reconcile.Result{}, nil
 }
 
+// reconcileInitialization deletes a registered NodeClaim whose node hasn't initialized within the NodeClaim's
+// initialization timeout
+func (l *Liveness) reconcileInitialization(ctx context.Context, nodeClaim *v1.NodeClaim, registered *status.Condition) (reconcile.Result, error) {
+	if nodeClaim.Spec.InitializationTimeout == nil || nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).IsTrue() {
+		return reconcile.Result{}, nil
+	}
+	timeout := NodeClaimTimeout{
+		duration: nodeClaim.Spec.InitializationTimeout.Duration,
+		reason:   initializationTimeoutReason,
+	}
+	if timeUntilTimeout := timeout.duration - l.clock.Since(registered.LastTransitionTime.Time); timeUntilTimeout > 0 {
+		return reconcile.Result{RequeueAfter: timeUntilTimeout}, nil
+	}
+	if err := l.deleteNodeClaimForTimeout(ctx, timeout, nodeClaim); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	return reconcile.Result{}, nil
+}
+
+// RegistrationTimeoutFor returns the registration timeout of the NodeClaim, which defaults to the RegistrationTimeout
+func RegistrationTimeoutFor(nodeClaim *v1.NodeClaim) NodeClaimTimeout {
+	if nodeClaim.Spec.RegistrationTimeout == nil {
+		return RegistrationTimeout
+	}
+	return NodeClaimTimeout{
+		duration: nodeClaim.Spec.RegistrationTimeout.Duration,
+		reason:   registrationTimeoutReason,
+	}
+}
+
 // updateNodePoolRegistrationHealth sets the NodeRegistrationHealthy=False
 // on the NodePool if the nodeClaim fails to launch/register
 func (l *Liveness) updateNodePoolRegistrationHealth(ctx context.Context, nodeClaim *v1.NodeClaim) error {
@@ -145,11 +180,39 @@ func (l *Liveness) deleteNodeClaimForTimeout(ctx context.Context, timeout NodeCl
 	if err := l.kubeClient.Delete(ctx, nodeClaim); err != nil {
 		return err
 	}
+	reason, message := timeout.garbageCollectedReasonAndMessage(nodeClaim)
+	markGarbageCollected(nodeClaim, reason, message)
 	log.FromContext(ctx).V(1).WithValues("timeout", timeout.duration, "reason", timeout.reason).Info("terminating due to timeout")
 	metrics.NodeClaimsDisruptedTotal.Inc(map[string]string{
 		metrics.ReasonLabel:       timeout.reason,
 		metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
 		metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
 	})
+	if err := nodepoolutils.RecordProvisioningFailure(ctx, l.kubeClient, nodeClaim.Labels[v1.NodePoolLabelKey]); err != nil {
+		log.FromContext(ctx).Error(err, "failed recording provisioning failure on nodepool")
+	}
 	return nil
 }
+
+// garbageCollectedReasonAndMessage classifies why a NodeClaim that's deleted for the timeout never became usable
+func (t NodeClaimTimeout) garbageCollectedReasonAndMessage(nodeClaim *v1.NodeClaim) (string, string) {
+	switch t.reason {
+	case launchTimeoutReason:
+		return v1.GarbageCollectedReasonLaunchFailed, fmt.Sprintf("Instance didn't launch within %s: %s", t.duration, nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).Message)
+	case initializationTimeoutReason:
+		return v1.GarbageCollectedReasonInitializationTimeout, fmt.Sprintf("Node didn't initialize within %s: %s", t.duration, nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).Message)
+	default:
+		return v1.GarbageCollectedReasonRegistrationTimeout, fmt.Sprintf("Node didn't register within %s", t.duration)
+	}
+}
+
+// markGarbageCollected records the root cause of the deletion of a NodeClaim on its GarbageCollected status condition,
+// which stays visible while the NodeClaim terminates
+func markGarbageCollected(nodeClaim *v1.NodeClaim, reason, message string) {
+	nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeGarbageCollected, reason, truncateMessage(message))
+	metrics.NodeClaimsGarbageCollectedTotal.Inc(map[string]string{
+		metrics.ReasonLabel:       reason,
+		metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
+		metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
+	})
+}
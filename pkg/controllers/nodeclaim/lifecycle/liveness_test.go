@@ -30,6 +30,7 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 )
@@ -97,6 +98,29 @@ var _ = Describe("Liveness", func() {
 		Entry("should delete the nodeClaim when the Node hasn't registered past the registration timeout", true),
 		Entry("should ignore NodeClaims not managed by this Karpenter instance", false),
 	)
+	It("should record the registration timeout as the root cause of the nodeClaim's deletion", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		fakeClock.Step(time.Minute * 20)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		garbageCollected := ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeGarbageCollected)
+		Expect(garbageCollected.Status).To(Equal(metav1.ConditionTrue))
+		Expect(garbageCollected.Reason).To(Equal(v1.GarbageCollectedReasonRegistrationTimeout))
+		ExpectMetricCounterValue(metrics.NodeClaimsGarbageCollectedTotal, 1, map[string]string{
+			metrics.ReasonLabel:   v1.GarbageCollectedReasonRegistrationTimeout,
+			metrics.NodePoolLabel: nodePool.Name,
+		})
+	})
 	It("shouldn't delete the nodeClaim when the node has registered past the registration timeout", func() {
 		nodeClaim := test.NodeClaim(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -127,6 +151,85 @@ var _ = Describe("Liveness", func() {
 		ExpectExists(ctx, env.Client, nodeClaim)
 		ExpectExists(ctx, env.Client, node)
 	})
+	It("should use the nodeClaim's registration timeout", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+			Spec: v1.NodeClaimSpec{
+				RegistrationTimeout: &metav1.Duration{Duration: time.Hour},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		// The default registration timeout has passed, but the nodeClaim's hasn't
+		fakeClock.Step(time.Minute * 20)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectExists(ctx, env.Client, nodeClaim)
+
+		fakeClock.Step(time.Minute * 45)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	Context("Initialization Timeout", func() {
+		var nodeClaim *v1.NodeClaim
+		var node *corev1.Node
+		BeforeEach(func() {
+			nodeClaim = test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey: nodePool.Name,
+					},
+				},
+				Spec: v1.NodeClaimSpec{
+					InitializationTimeout: &metav1.Duration{Duration: time.Minute * 10},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			node = test.Node(test.NodeOptions{
+				ProviderID:  nodeClaim.Status.ProviderID,
+				ReadyStatus: corev1.ConditionFalse,
+				Taints:      []corev1.Taint{v1.UnregisteredNoExecuteTaint},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeRegistered).Status).To(Equal(metav1.ConditionTrue))
+		})
+		It("should delete the nodeClaim when the node hasn't initialized past the initialization timeout", func() {
+			fakeClock.Step(time.Minute * 5)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectExists(ctx, env.Client, nodeClaim)
+
+			fakeClock.Step(time.Minute * 10)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			garbageCollected := ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeGarbageCollected)
+			Expect(garbageCollected.Status).To(Equal(metav1.ConditionTrue))
+			Expect(garbageCollected.Reason).To(Equal(v1.GarbageCollectedReasonInitializationTimeout))
+			ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
+			ExpectNotFound(ctx, env.Client, nodeClaim)
+		})
+		It("shouldn't delete the nodeClaim when the node has initialized", func() {
+			node = ExpectExists(ctx, env.Client, node)
+			node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeInitialized).Status).To(Equal(metav1.ConditionTrue))
+
+			fakeClock.Step(time.Minute * 15)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			ExpectExists(ctx, env.Client, nodeClaim)
+		})
+	})
 	It("should delete the NodeClaim when the NodeClaim hasn't launched past the launch timeout", func() {
 		nodeClaim := test.NodeClaim(v1.NodeClaim{
 			ObjectMeta: metav1.ObjectMeta{
@@ -153,8 +256,14 @@ var _ = Describe("Liveness", func() {
 		// If the node hasn't launched in the launch timeout timeframe, then we deprovision the nodeClaim
 		fakeClock.Step(time.Minute * 6)
 		_ = ExpectObjectReconcileFailed(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(ExpectStatusConditionExists(nodeClaim, v1.ConditionTypeGarbageCollected).Reason).To(Equal(v1.GarbageCollectedReasonLaunchFailed))
 		ExpectFinalizersRemoved(ctx, env.Client, nodeClaim)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
+
+		// The failed launch counts towards the NodePool's consecutive provisioning failures
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 1))
 	})
 	It("should not delete the NodeClaim when the NodeClaim hasn't launched before the launch timeout", func() {
 		nodeClaim := test.NodeClaim(v1.NodeClaim{
@@ -19,11 +19,21 @@ package lifecycle
 import (
 	opmetrics "github.com/awslabs/operatorpkg/metrics"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 )
 
+const (
+	stateLabel        = "state"
+	fromStateLabel    = "from_state"
+	toStateLabel      = "to_state"
+	instanceTypeLabel = "instance_type"
+	zoneLabel         = "zone"
+)
+
 var InstanceTerminationDurationSeconds = opmetrics.NewPrometheusHistogram(
 	crmetrics.Registry,
 	prometheus.HistogramOpts{
@@ -46,3 +56,71 @@ var NodeClaimTerminationDurationSeconds = opmetrics.NewPrometheusHistogram(
 		Buckets:   prometheus.ExponentialBuckets(1, 2, 12)}, //The threshold values generated here are 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024. 2048
 	[]string{metrics.NodePoolLabel},
 )
+
+var NodeClaimLifecycleStateDurationSeconds = opmetrics.NewPrometheusHistogram(
+	crmetrics.Registry,
+	prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeClaimSubsystem,
+		Name:      "lifecycle_state_duration_seconds",
+		Help:      "Duration that NodeClaims spend in each lifecycle state before transitioning out of it in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 12), //The threshold values generated here are 1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304
+	},
+	[]string{metrics.NodePoolLabel, stateLabel},
+)
+
+var NodeClaimsInvalidLifecycleTransitionsTotal = opmetrics.NewPrometheusCounter(
+	crmetrics.Registry,
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeClaimSubsystem,
+		Name:      "lifecycle_invalid_transitions_total",
+		Help:      "The number of times a NodeClaim was observed moving between two lifecycle states that it can't transition between.",
+	},
+	[]string{metrics.NodePoolLabel, fromStateLabel, toStateLabel},
+)
+
+var NominatedPodEventsTotal = opmetrics.NewPrometheusCounter(
+	crmetrics.Registry,
+	prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeClaimSubsystem,
+		Name:      "nominated_pod_events_total",
+		Help:      "The number of events published to pods that a NodeClaim was nominated for when the NodeClaim failed to launch.",
+	},
+	[]string{metrics.NodePoolLabel},
+)
+
+var NodeClaimRegistrationDurationSeconds = opmetrics.NewPrometheusHistogram(
+	crmetrics.Registry,
+	prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeClaimSubsystem,
+		Name:      "registration_duration_seconds",
+		Help:      "Duration between a NodeClaim launching and its node registering with the cluster in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12), //The threshold values generated here are 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048
+	},
+	[]string{metrics.NodePoolLabel, instanceTypeLabel, zoneLabel, metrics.CapacityTypeLabel},
+)
+
+var NodeClaimInitializationDurationSeconds = opmetrics.NewPrometheusHistogram(
+	crmetrics.Registry,
+	prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metrics.NodeClaimSubsystem,
+		Name:      "initialization_duration_seconds",
+		Help:      "Duration between a NodeClaim's node registering with the cluster and initializing in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12), //The threshold values generated here are 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048
+	},
+	[]string{metrics.NodePoolLabel, instanceTypeLabel, zoneLabel, metrics.CapacityTypeLabel},
+)
+
+// startupDurationLabels returns the labels of the NodeClaim's registration and initialization durations
+func startupDurationLabels(nodeClaim *v1.NodeClaim) map[string]string {
+	return map[string]string{
+		metrics.NodePoolLabel:     nodeClaim.Labels[v1.NodePoolLabelKey],
+		instanceTypeLabel:         nodeClaim.Labels[corev1.LabelInstanceTypeStable],
+		zoneLabel:                 nodeClaim.Labels[corev1.LabelTopologyZone],
+		metrics.CapacityTypeLabel: nodeClaim.Labels[v1.CapacityTypeLabelKey],
+	}
+}
@@ -20,10 +20,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/awslabs/operatorpkg/object"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/metrics"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
@@ -39,14 +42,19 @@ import (
 )
 
 type Registration struct {
-	kubeClient client.Client
-	recorder   events.Recorder
+	kubeClient   client.Client
+	recorder     events.Recorder
+	nodeAttestor cloudprovider.NodeAttestor
 }
 
 func (r *Registration) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
 	if cond := nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered); !cond.IsUnknown() {
 		// Ensure that we always set the status condition to the latest generation
 		nodeClaim.StatusConditions().Set(*cond)
+		// Backfill the registration time of NodeClaims that registered before it was recorded
+		if cond.IsTrue() && nodeClaim.Status.RegisteredAt == nil {
+			nodeClaim.Status.RegisteredAt = lo.ToPtr(cond.LastTransitionTime)
+		}
 		return reconcile.Result{}, nil
 	}
 	node, err := nodeclaimutils.NodeForNodeClaim(ctx, r.kubeClient, nodeClaim)
@@ -61,12 +69,25 @@ func (r *Registration) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (
 		}
 		return reconcile.Result{}, fmt.Errorf("getting node for nodeclaim, %w", err)
 	}
+	// The unregistered taint is only removed from Nodes that are verified to run on the NodeClaim's instance. Rejected
+	// NodeClaims are deleted once their registration timeout elapses.
+	if err = r.verifyNode(ctx, nodeClaim, node); err != nil {
+		if !cloudprovider.IsNodeAttestationError(err) {
+			return reconcile.Result{}, fmt.Errorf("verifying node, %w", err)
+		}
+		log.FromContext(ctx).WithValues("Node", klog.KObj(node)).Error(err, "rejecting node registration")
+		nodeClaim.StatusConditions().SetUnknownWithReason(v1.ConditionTypeRegistered, "NodeVerificationFailed", truncateMessage(err.Error()))
+		r.recorder.Publish(NodeVerificationFailedEvent(nodeClaim, node, err)...)
+		return reconcile.Result{}, nil
+	}
 	_, hasStartupTaint := lo.Find(node.Spec.Taints, func(t corev1.Taint) bool {
 		return t.MatchTaint(&v1.UnregisteredNoExecuteTaint)
 	})
 	// if the sync hasn't happened yet and the race protecting startup taint isn't present then log it as missing and proceed
 	// if the sync has happened then the startup taint has been removed if it was present
-	if _, ok := node.Labels[v1.NodeRegisteredLabelKey]; !ok && !hasStartupTaint {
+	// adopted nodes joined the cluster before Karpenter managed them, so they're never expected to have the taint
+	_, adopted := nodeClaim.Annotations[v1.NodeClaimAdoptedInstanceAnnotationKey]
+	if _, ok := node.Labels[v1.NodeRegisteredLabelKey]; !ok && !hasStartupTaint && !adopted {
 		log.FromContext(ctx).WithValues("taint", v1.UnregisteredTaintKey).Error(fmt.Errorf("missing taint prevents registration-related race conditions on Karpenter-managed nodes"), "node claim registration error")
 		r.recorder.Publish(UnregisteredTaintMissingEvent(nodeClaim))
 	}
@@ -79,6 +100,10 @@ func (r *Registration) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (
 	}
 	log.FromContext(ctx).Info("registered nodeclaim")
 	nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+	nodeClaim.Status.RegisteredAt = lo.ToPtr(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).LastTransitionTime)
+	if nodeClaim.Status.LaunchedAt != nil {
+		NodeClaimRegistrationDurationSeconds.Observe(nodeClaim.Status.RegisteredAt.Sub(nodeClaim.Status.LaunchedAt.Time).Seconds(), startupDurationLabels(nodeClaim))
+	}
 	nodeClaim.Status.NodeName = node.Name
 
 	metrics.NodesCreatedTotal.Inc(map[string]string{
@@ -93,8 +118,29 @@ func (r *Registration) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (
 	return reconcile.Result{}, nil
 }
 
-// updateNodePoolRegistrationHealth sets the NodeRegistrationHealthy=True
-// on the NodePool if the nodeClaim that registered is owned by a NodePool
+// verifyNode verifies that the Node that claims the NodeClaim runs on its instance, so that a spoofed Node can't receive
+// the NodeClaim's labels and the pods that are scheduled to it. Nodes that don't are rejected with a
+// NodeAttestationError.
+func (r *Registration) verifyNode(ctx context.Context, nodeClaim *v1.NodeClaim, node *corev1.Node) error {
+	if node.Spec.ProviderID != nodeClaim.Status.ProviderID {
+		return cloudprovider.NewNodeAttestationError(fmt.Errorf("node has provider id %q, expected %q", node.Spec.ProviderID, nodeClaim.Status.ProviderID))
+	}
+	gvk := object.GVK(nodeClaim)
+	if owner, ok := lo.Find(node.OwnerReferences, func(o metav1.OwnerReference) bool {
+		return o.APIVersion == gvk.GroupVersion().String() && o.Kind == gvk.Kind && o.UID != nodeClaim.UID
+	}); ok {
+		return cloudprovider.NewNodeAttestationError(fmt.Errorf("node is already owned by nodeclaim %q", owner.Name))
+	}
+	if r.nodeAttestor == nil {
+		return nil
+	}
+	return r.nodeAttestor.AttestNode(ctx, nodeClaim, node)
+}
+
+// updateNodePoolRegistrationHealth sets the NodeRegistrationHealthy=True and resets the consecutive provisioning failures
+// if the nodeClaim that registered is owned by a NodePool. The NodePool has recovered from its provisioning failures, so
+// its widened requirements are reverted if the nodeClaim satisfies the original requirements, and the weight of the
+// fallbacks it escalated to is reverted.
 func (r *Registration) updateNodePoolRegistrationHealth(ctx context.Context, nodeClaim *v1.NodeClaim) error {
 	nodePoolName := nodeClaim.Labels[v1.NodePoolLabelKey]
 	if nodePoolName != "" {
@@ -103,14 +149,51 @@ func (r *Registration) updateNodePoolRegistrationHealth(ctx context.Context, nod
 			return err
 		}
 		stored := nodePool.DeepCopy()
-		if nodePool.StatusConditions().SetTrue(v1.ConditionTypeNodeRegistrationHealthy) {
+		nodePool.Status.ConsecutiveProvisioningFailures = 0
+		if satisfiesWidenedRequirements(nodePool, nodeClaim) {
+			nodePool.Status.WidenedRequirements = nil
+		}
+		if nodePool.StatusConditions().SetTrue(v1.ConditionTypeNodeRegistrationHealthy) || !equality.Semantic.DeepEqual(stored.Status, nodePool.Status) {
 			// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
 			// can cause races due to the fact that it fully replaces the list on a change
-			// Here, we are updating the status condition list
+			// Here, we are updating the status condition and widened requirements lists
 			if err := r.kubeClient.Status().Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); client.IgnoreNotFound(err) != nil {
 				return err
 			}
 		}
+		return r.revertEscalations(ctx, nodePoolName)
+	}
+	return nil
+}
+
+// satisfiesWidenedRequirements returns true if the nodeClaim satisfies the NodePool's widened requirements as they are
+// in its spec. NodeClaims that were only launched because the requirements were widened don't show that the original
+// requirements have recovered.
+func satisfiesWidenedRequirements(nodePool *v1.NodePool, nodeClaim *v1.NodeClaim) bool {
+	reqs := scheduling.NewNodeSelectorRequirementsWithMinValues(lo.Filter(nodePool.Spec.Template.Spec.Requirements, func(r v1.NodeSelectorRequirementWithMinValues, _ int) bool {
+		return lo.Contains(nodePool.Status.WidenedRequirements, r.Key)
+	})...)
+	return reqs.Intersects(scheduling.NewLabelRequirements(nodeClaim.Labels)) == nil
+}
+
+// revertEscalations clears the escalated weight of the fallback NodePools that the recovered NodePool escalated to.
+// Registrations of the fallbacks' own NodeClaims leave the escalation in place, since the NodePool that failed may still
+// be failing.
+func (r *Registration) revertEscalations(ctx context.Context, nodePoolName string) error {
+	nodePools := &v1.NodePoolList{}
+	if err := r.kubeClient.List(ctx, nodePools); err != nil {
+		return err
+	}
+	for _, fallback := range nodePools.Items {
+		if fallback.Status.EscalatedBy != nodePoolName {
+			continue
+		}
+		stored := fallback.DeepCopy()
+		fallback.Status.EscalatedWeight = nil
+		fallback.Status.EscalatedBy = ""
+		if err := r.kubeClient.Status().Patch(ctx, &fallback, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); client.IgnoreNotFound(err) != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package lifecycle_test
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/awslabs/operatorpkg/object"
@@ -27,8 +28,11 @@ import (
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
 	"sigs.k8s.io/karpenter/pkg/events"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
@@ -491,6 +495,127 @@ var _ = Describe("Registration", func() {
 			Status: metav1.ConditionTrue,
 		})
 	})
+	It("should reset the consecutive provisioning failures on the nodePool if registration succeeds", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		nodePool.StatusConditions().SetTrue(v1.ConditionTypeNodeRegistrationHealthy)
+		nodePool.Status.ConsecutiveProvisioningFailures = 4
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 0))
+	})
+	It("should revert widened requirements on the nodePool if a nodeClaim that satisfies them registers", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"default-instance-type"}}},
+		}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: "default-instance-type",
+				},
+			},
+		})
+		nodePool.StatusConditions().SetTrue(v1.ConditionTypeNodeRegistrationHealthy)
+		nodePool.Status.WidenedRequirements = []string{corev1.LabelInstanceTypeStable}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.WidenedRequirements).To(BeEmpty())
+	})
+	It("should keep widened requirements on the nodePool if a nodeClaim that only satisfies the widened requirements registers", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"default-instance-type"}}},
+		}
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: "other-instance-type",
+				},
+			},
+		})
+		nodePool.StatusConditions().SetTrue(v1.ConditionTypeNodeRegistrationHealthy)
+		nodePool.Status.ConsecutiveProvisioningFailures = 2
+		nodePool.Status.WidenedRequirements = []string{corev1.LabelInstanceTypeStable}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 0))
+		Expect(nodePool.Status.WidenedRequirements).To(ConsistOf(corev1.LabelInstanceTypeStable))
+	})
+	It("should revert the escalated weight of the fallback if a nodeClaim of the nodePool that escalated registers", func() {
+		fallback := test.NodePool()
+		fallback.Status.EscalatedWeight = lo.ToPtr[int32](51)
+		fallback.Status.EscalatedBy = nodePool.Name
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		nodePool.StatusConditions().SetTrue(v1.ConditionTypeNodeRegistrationHealthy)
+		ExpectApplied(ctx, env.Client, nodePool, fallback, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		fallback = ExpectExists(ctx, env.Client, fallback)
+		Expect(fallback.Status.EscalatedWeight).To(BeNil())
+		Expect(fallback.Status.EscalatedBy).To(BeEmpty())
+	})
+	It("should keep the escalated weight of the fallback if a nodeClaim of the fallback registers", func() {
+		fallback := test.NodePool()
+		fallback.StatusConditions().SetTrue(v1.ConditionTypeNodeRegistrationHealthy)
+		fallback.Status.EscalatedWeight = lo.ToPtr[int32](51)
+		fallback.Status.EscalatedBy = nodePool.Name
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: fallback.Name,
+				},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodePool, fallback, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		fallback = ExpectExists(ctx, env.Client, fallback)
+		Expect(lo.FromPtr(fallback.Status.EscalatedWeight)).To(BeNumerically("==", 51))
+		Expect(fallback.Status.EscalatedBy).To(Equal(nodePool.Name))
+	})
 	It("should not block on updating NodeRegistrationHealthy status condition if nodeClaim is not owned by a nodePool", func() {
 		nodeClaim := test.NodeClaim()
 		ExpectApplied(ctx, env.Client, nodeClaim)
@@ -505,4 +630,85 @@ var _ = Describe("Registration", func() {
 		Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue()).To(BeTrue())
 		Expect(nodeClaim.Status.NodeName).To(Equal(node.Name))
 	})
+	Context("Node Verification", func() {
+		var nodeClaim *v1.NodeClaim
+
+		BeforeEach(func() {
+			recorder.Reset()
+			nodeClaim = test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						v1.NodePoolLabelKey: nodePool.Name,
+					},
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		})
+		It("should reject a Node that is already owned by another nodeClaim", func() {
+			node := test.Node(test.NodeOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: object.GVK(nodeClaim).GroupVersion().String(),
+							Kind:       object.GVK(nodeClaim).Kind,
+							Name:       "other-nodeclaim",
+							UID:        uuid.NewUUID(),
+						},
+					},
+				},
+				ProviderID: nodeClaim.Status.ProviderID,
+				Taints:     []corev1.Taint{v1.UnregisteredNoExecuteTaint},
+			})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsUnknown()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).Reason).To(Equal("NodeVerificationFailed"))
+			Expect(nodeClaim.Status.NodeName).To(Equal(""))
+			node = ExpectExists(ctx, env.Client, node)
+			Expect(node.Spec.Taints).To(ContainElement(v1.UnregisteredNoExecuteTaint))
+			Expect(node.Labels).ToNot(HaveKey(v1.NodeRegisteredLabelKey))
+			Expect(recorder.Calls(events.NodeVerificationFailed)).To(Equal(2))
+		})
+		It("should register a Node that the cloud provider attests", func() {
+			attestingController := nodeclaimlifecycle.NewController(fakeClock, env.Client, cloudProvider, recorder, nodeclaimlifecycle.WithNodeAttestor(cloudProvider))
+			node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, attestingController, nodeClaim)
+
+			Expect(cloudProvider.AttestNodeCalls).To(HaveLen(1))
+			Expect(cloudProvider.AttestNodeCalls[0].Name).To(Equal(node.Name))
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue()).To(BeTrue())
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).ToNot(ContainElement(v1.UnregisteredNoExecuteTaint))
+		})
+		It("should reject a Node that the cloud provider fails to attest", func() {
+			attestingController := nodeclaimlifecycle.NewController(fakeClock, env.Client, cloudProvider, recorder, nodeclaimlifecycle.WithNodeAttestor(cloudProvider))
+			cloudProvider.NextAttestNodeErr = cloudprovider.NewNodeAttestationError(fmt.Errorf("instance identity document doesn't match"))
+			node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+			ExpectApplied(ctx, env.Client, node)
+			ExpectObjectReconciled(ctx, env.Client, attestingController, nodeClaim)
+
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsUnknown()).To(BeTrue())
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).Reason).To(Equal("NodeVerificationFailed"))
+			Expect(ExpectExists(ctx, env.Client, node).Spec.Taints).To(ContainElement(v1.UnregisteredNoExecuteTaint))
+			Expect(recorder.Calls(events.NodeVerificationFailed)).To(Equal(2))
+		})
+		It("should retry the registration when the cloud provider can't attest a Node", func() {
+			attestingController := nodeclaimlifecycle.NewController(fakeClock, env.Client, cloudProvider, recorder, nodeclaimlifecycle.WithNodeAttestor(cloudProvider))
+			cloudProvider.NextAttestNodeErr = fmt.Errorf("rate limited")
+			node := test.Node(test.NodeOptions{ProviderID: nodeClaim.Status.ProviderID, Taints: []corev1.Taint{v1.UnregisteredNoExecuteTaint}})
+			ExpectApplied(ctx, env.Client, node)
+			_ = ExpectObjectReconcileFailed(ctx, env.Client, attestingController, nodeClaim)
+			Expect(recorder.Calls(events.NodeVerificationFailed)).To(Equal(0))
+
+			ExpectObjectReconciled(ctx, env.Client, attestingController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue()).To(BeTrue())
+		})
+	})
 })
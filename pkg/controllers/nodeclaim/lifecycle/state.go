@@ -0,0 +1,284 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/utils/result"
+)
+
+// State is a stage in the lifecycle of a NodeClaim. The current State is derived from the NodeClaim's status conditions
+// and deletion timestamp, while the last observed State is persisted on the NodeClaim so that moving between them can be
+// validated and hooked into.
+type State string
+
+const (
+	StatePending    State = "Pending"
+	StateLaunched   State = "Launched"
+	StateRegistered State = "Registered"
+	// StateInitialized is transient, a NodeClaim moves on to StateActive as soon as its initialization is observed
+	StateInitialized State = "Initialized"
+	StateActive      State = "Active"
+	StateDisrupting  State = "Disrupting"
+	StateDraining    State = "Draining"
+	StateTerminating State = "Terminating"
+)
+
+// transitions are the valid transitions out of each State. A NodeClaim can be deleted at any point before it's
+// terminating, so every earlier State may transition to StateDraining.
+var transitions = map[State][]State{
+	StatePending:     {StateLaunched, StateDraining},
+	StateLaunched:    {StateRegistered, StateDraining},
+	StateRegistered:  {StateInitialized, StateDraining},
+	StateInitialized: {StateActive, StateDraining},
+	StateActive:      {StateDisrupting, StateDraining},
+	StateDisrupting:  {StateActive, StateDraining},
+	StateDraining:    {StateTerminating},
+	StateTerminating: {},
+}
+
+// StateFor returns the State that the NodeClaim is currently in based on its status conditions and deletion timestamp
+func StateFor(nodeClaim *v1.NodeClaim) State {
+	switch {
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeInstanceTerminating).IsTrue():
+		return StateTerminating
+	case !nodeClaim.DeletionTimestamp.IsZero():
+		return StateDraining
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeDisruptionReason).IsTrue():
+		return StateDisrupting
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).IsTrue():
+		return StateActive
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue():
+		return StateRegistered
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue():
+		return StateLaunched
+	default:
+		return StatePending
+	}
+}
+
+// StoredStateFor returns the last State that was recorded on the NodeClaim by the StateMachine
+func StoredStateFor(nodeClaim *v1.NodeClaim) State {
+	return State(lo.CoalesceOrEmpty(nodeClaim.Annotations[v1.NodeClaimLifecycleStateAnnotationKey], string(StatePending)))
+}
+
+// Path returns the States that a NodeClaim passes through when moving from one State to another, excluding the State
+// that it starts in. If the second State can't be reached from the first, Path returns false.
+func Path(from, to State) ([]State, bool) {
+	parents := map[State]State{from: from}
+	queue := []State{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			var path []State
+			for s := to; s != from; s = parents[s] {
+				path = append([]State{s}, path...)
+			}
+			return path, true
+		}
+		for _, next := range transitions[current] {
+			if _, ok := parents[next]; !ok {
+				parents[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil, false
+}
+
+// InvalidTransitionError is returned when a NodeClaim can't move between two States
+type InvalidTransitionError struct {
+	From State
+	To   State
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("invalid nodeclaim lifecycle transition from %s to %s", e.From, e.To)
+}
+
+func IsInvalidTransitionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	itErr := &InvalidTransitionError{}
+	return errors.As(err, &itErr)
+}
+
+// TransitionHook is called when a NodeClaim moves between two States. Hooks may mutate the NodeClaim, and any changes
+// are persisted alongside the new State. Returning an error leaves the NodeClaim in the State it's transitioning from.
+type TransitionHook func(ctx context.Context, nodeClaim *v1.NodeClaim, from, to State) error
+
+type transition struct {
+	from State
+	to   State
+}
+
+// step is a sub-reconciler that moves NodeClaims out of a State
+type step struct {
+	state      State
+	reconciler reconcile.TypedReconciler[*v1.NodeClaim]
+}
+
+// StateMachine drives NodeClaims through their lifecycle States by running the sub-reconciler of each State, running
+// the hooks registered for each transition and recording how long NodeClaims spend in each State
+type StateMachine struct {
+	clock clock.Clock
+	steps []step
+	hooks map[transition][]TransitionHook
+}
+
+func NewStateMachine(clk clock.Clock) *StateMachine {
+	return &StateMachine{
+		clock: clk,
+		hooks: map[transition][]TransitionHook{},
+	}
+}
+
+// OnState registers a sub-reconciler that moves NodeClaims out of the State. Sub-reconcilers are run in the order that
+// they're registered.
+func (m *StateMachine) OnState(state State, reconciler reconcile.TypedReconciler[*v1.NodeClaim]) {
+	m.steps = append(m.steps, step{state: state, reconciler: reconciler})
+}
+
+// Reconcile runs the sub-reconciler of every State that the NodeClaim has reached, transitioning the NodeClaim after
+// each of them so that the hooks of a transition run as soon as a sub-reconciler moves the NodeClaim into its next
+// State. Sub-reconcilers of States that the NodeClaim has already left are still run so that they keep their status
+// conditions up to date. The caller is responsible for persisting the NodeClaim afterwards.
+func (m *StateMachine) Reconcile(ctx context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
+	var results []reconcile.Result
+	var errs error
+	for _, s := range m.steps {
+		if current := StateFor(nodeClaim); current != s.state {
+			if _, ok := Path(s.state, current); !ok {
+				continue
+			}
+		}
+		res, err := s.reconciler.Reconcile(ctx, nodeClaim)
+		errs = multierr.Append(errs, err)
+		results = append(results, res)
+		errs = multierr.Append(errs, m.Transition(ctx, nodeClaim))
+	}
+	if errs != nil {
+		return reconcile.Result{}, errs
+	}
+	return result.Min(results...), nil
+}
+
+// OnTransition registers a hook that is called whenever a NodeClaim moves from one State to another. Hooks are called
+// in the order that they're registered.
+func (m *StateMachine) OnTransition(from, to State, hook TransitionHook) error {
+	if !lo.Contains(transitions[from], to) {
+		return &InvalidTransitionError{From: from, To: to}
+	}
+	m.hooks[transition{from: from, to: to}] = append(m.hooks[transition{from: from, to: to}], hook)
+	return nil
+}
+
+// Transition moves the NodeClaim from its stored State to its current State, passing through every intermediate State
+// so that their hooks run in order. The caller is responsible for persisting the NodeClaim afterwards.
+func (m *StateMachine) Transition(ctx context.Context, nodeClaim *v1.NodeClaim) error {
+	from, to := StoredStateFor(nodeClaim), StateFor(nodeClaim)
+	if from == to {
+		return nil
+	}
+	path, ok := Path(from, to)
+	if !ok {
+		// We still record the current State so that we only report the invalid transition once
+		log.FromContext(ctx).Error(&InvalidTransitionError{From: from, To: to}, "skipping nodeclaim lifecycle transition hooks")
+		NodeClaimsInvalidLifecycleTransitionsTotal.Inc(map[string]string{
+			metrics.NodePoolLabel: nodeClaim.Labels[v1.NodePoolLabelKey],
+			fromStateLabel:        string(from),
+			toStateLabel:          string(to),
+		})
+		setState(nodeClaim, to)
+		return nil
+	}
+	for _, next := range path {
+		for _, hook := range m.hooks[transition{from: from, to: next}] {
+			if err := hook(ctx, nodeClaim, from, next); err != nil {
+				return fmt.Errorf("running nodeclaim lifecycle transition hook from %s to %s, %w", from, next, err)
+			}
+		}
+		m.observeDuration(nodeClaim, from, next)
+		log.FromContext(ctx).V(1).WithValues("from", from, "to", next).Info("transitioned nodeclaim lifecycle state")
+		setState(nodeClaim, next)
+		from = next
+	}
+	return nil
+}
+
+func (m *StateMachine) observeDuration(nodeClaim *v1.NodeClaim, from, to State) {
+	start := enteredAt(nodeClaim, from)
+	if start.IsZero() {
+		return
+	}
+	// A NodeClaim may re-enter a State (e.g. Active after an aborted disruption) whose start is recorded before the
+	// State we're leaving, so fall back to now in that case
+	leftAt := enteredAt(nodeClaim, to)
+	if leftAt.IsZero() || leftAt.Before(start) {
+		leftAt = m.clock.Now()
+	}
+	NodeClaimLifecycleStateDurationSeconds.Observe(leftAt.Sub(start).Seconds(), map[string]string{
+		metrics.NodePoolLabel: nodeClaim.Labels[v1.NodePoolLabelKey],
+		stateLabel:            string(from),
+	})
+}
+
+// enteredAt returns when the NodeClaim entered the State, or the zero time if it can't be determined
+func enteredAt(nodeClaim *v1.NodeClaim, state State) time.Time {
+	var conditionType string
+	switch state {
+	case StatePending:
+		return nodeClaim.CreationTimestamp.Time
+	case StateLaunched:
+		conditionType = v1.ConditionTypeLaunched
+	case StateRegistered:
+		conditionType = v1.ConditionTypeRegistered
+	case StateInitialized, StateActive:
+		conditionType = v1.ConditionTypeInitialized
+	case StateDisrupting:
+		conditionType = v1.ConditionTypeDisruptionReason
+	case StateDraining:
+		if nodeClaim.DeletionTimestamp == nil {
+			return time.Time{}
+		}
+		return nodeClaim.DeletionTimestamp.Time
+	case StateTerminating:
+		conditionType = v1.ConditionTypeInstanceTerminating
+	}
+	if cond := nodeClaim.StatusConditions().Get(conditionType); cond != nil {
+		return cond.LastTransitionTime.Time
+	}
+	return time.Time{}
+}
+
+func setState(nodeClaim *v1.NodeClaim, state State) {
+	nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimLifecycleStateAnnotationKey: string(state)})
+}
@@ -0,0 +1,218 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	nodeclaimlifecycle "sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/lifecycle"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+)
+
+var _ = Describe("State", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var stateMachine *nodeclaimlifecycle.StateMachine
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodeClaim = test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+			},
+		})
+		stateMachine = nodeclaimlifecycle.NewStateMachine(fakeClock)
+		nodeclaimlifecycle.NodeClaimLifecycleStateDurationSeconds.Reset()
+		nodeclaimlifecycle.NodeClaimsInvalidLifecycleTransitionsTotal.Reset()
+	})
+	Context("StateFor", func() {
+		It("should derive the state from the nodeclaim status conditions", func() {
+			Expect(nodeclaimlifecycle.StateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StatePending))
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+			Expect(nodeclaimlifecycle.StateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateLaunched))
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+			Expect(nodeclaimlifecycle.StateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateRegistered))
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+			Expect(nodeclaimlifecycle.StateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateActive))
+			nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, string(v1.DisruptionReasonDrifted), string(v1.DisruptionReasonDrifted))
+			Expect(nodeclaimlifecycle.StateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateDisrupting))
+			nodeClaim.DeletionTimestamp = lo.ToPtr(metav1.Now())
+			Expect(nodeclaimlifecycle.StateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateDraining))
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInstanceTerminating)
+			Expect(nodeclaimlifecycle.StateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateTerminating))
+		})
+	})
+	Context("Path", func() {
+		It("should return the intermediate states between two states", func() {
+			path, ok := nodeclaimlifecycle.Path(nodeclaimlifecycle.StateLaunched, nodeclaimlifecycle.StateActive)
+			Expect(ok).To(BeTrue())
+			Expect(path).To(Equal([]nodeclaimlifecycle.State{nodeclaimlifecycle.StateRegistered, nodeclaimlifecycle.StateInitialized, nodeclaimlifecycle.StateActive}))
+		})
+		It("should not return a path backwards through the lifecycle", func() {
+			_, ok := nodeclaimlifecycle.Path(nodeclaimlifecycle.StateActive, nodeclaimlifecycle.StateLaunched)
+			Expect(ok).To(BeFalse())
+			_, ok = nodeclaimlifecycle.Path(nodeclaimlifecycle.StateTerminating, nodeclaimlifecycle.StateDraining)
+			Expect(ok).To(BeFalse())
+		})
+	})
+	Context("StateMachine", func() {
+		It("should reject hooks for invalid transitions", func() {
+			err := stateMachine.OnTransition(nodeclaimlifecycle.StateActive, nodeclaimlifecycle.StateLaunched, func(context.Context, *v1.NodeClaim, nodeclaimlifecycle.State, nodeclaimlifecycle.State) error {
+				return nil
+			})
+			Expect(nodeclaimlifecycle.IsInvalidTransitionError(err)).To(BeTrue())
+		})
+		It("should run the hooks for every intermediate transition in order", func() {
+			var transitions []string
+			for _, t := range [][2]nodeclaimlifecycle.State{
+				{nodeclaimlifecycle.StatePending, nodeclaimlifecycle.StateLaunched},
+				{nodeclaimlifecycle.StateLaunched, nodeclaimlifecycle.StateRegistered},
+				{nodeclaimlifecycle.StateRegistered, nodeclaimlifecycle.StateInitialized},
+				{nodeclaimlifecycle.StateInitialized, nodeclaimlifecycle.StateActive},
+			} {
+				Expect(stateMachine.OnTransition(t[0], t[1], func(_ context.Context, _ *v1.NodeClaim, from, to nodeclaimlifecycle.State) error {
+					transitions = append(transitions, fmt.Sprintf("%s->%s", from, to))
+					return nil
+				})).To(Succeed())
+			}
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeInitialized)
+
+			Expect(stateMachine.Transition(ctx, nodeClaim)).To(Succeed())
+			Expect(transitions).To(Equal([]string{"Pending->Launched", "Launched->Registered", "Registered->Initialized", "Initialized->Active"}))
+			Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimLifecycleStateAnnotationKey, string(nodeclaimlifecycle.StateActive)))
+		})
+		It("should stop at the state before a failing hook", func() {
+			Expect(stateMachine.OnTransition(nodeclaimlifecycle.StateLaunched, nodeclaimlifecycle.StateRegistered, func(context.Context, *v1.NodeClaim, nodeclaimlifecycle.State, nodeclaimlifecycle.State) error {
+				return fmt.Errorf("failed")
+			})).To(Succeed())
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeRegistered)
+
+			Expect(stateMachine.Transition(ctx, nodeClaim)).ToNot(Succeed())
+			Expect(nodeclaimlifecycle.StoredStateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateLaunched))
+		})
+		It("should record invalid transitions without running hooks", func() {
+			called := false
+			Expect(stateMachine.OnTransition(nodeclaimlifecycle.StatePending, nodeclaimlifecycle.StateLaunched, func(context.Context, *v1.NodeClaim, nodeclaimlifecycle.State, nodeclaimlifecycle.State) error {
+				called = true
+				return nil
+			})).To(Succeed())
+			nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimLifecycleStateAnnotationKey: string(nodeclaimlifecycle.StateActive)})
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+
+			Expect(stateMachine.Transition(ctx, nodeClaim)).To(Succeed())
+			Expect(called).To(BeFalse())
+			Expect(nodeclaimlifecycle.StoredStateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateLaunched))
+			ExpectMetricCounterValue(nodeclaimlifecycle.NodeClaimsInvalidLifecycleTransitionsTotal, 1, map[string]string{
+				"nodepool":   nodePool.Name,
+				"from_state": string(nodeclaimlifecycle.StateActive),
+				"to_state":   string(nodeclaimlifecycle.StateLaunched),
+			})
+		})
+		It("should record the duration spent in each state", func() {
+			nodeClaim.CreationTimestamp = metav1.NewTime(fakeClock.Now().Add(-time.Minute))
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+
+			Expect(stateMachine.Transition(ctx, nodeClaim)).To(Succeed())
+			ExpectMetricHistogramSampleCountValue("karpenter_nodeclaims_lifecycle_state_duration_seconds", 1, map[string]string{
+				"nodepool": nodePool.Name,
+				"state":    string(nodeclaimlifecycle.StatePending),
+			})
+		})
+	})
+	Context("Reconcile", func() {
+		It("should only run the sub-reconcilers of the states the nodeclaim has reached", func() {
+			var ran []nodeclaimlifecycle.State
+			for _, state := range []nodeclaimlifecycle.State{nodeclaimlifecycle.StatePending, nodeclaimlifecycle.StateLaunched, nodeclaimlifecycle.StateRegistered} {
+				stateMachine.OnState(state, reconcile.TypedFunc[*v1.NodeClaim](func(context.Context, *v1.NodeClaim) (reconcile.Result, error) {
+					ran = append(ran, state)
+					return reconcile.Result{}, nil
+				}))
+			}
+			nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+
+			_, err := stateMachine.Reconcile(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ran).To(Equal([]nodeclaimlifecycle.State{nodeclaimlifecycle.StatePending, nodeclaimlifecycle.StateLaunched}))
+		})
+		It("should run the transition hooks as soon as a sub-reconciler moves the nodeclaim into its next state", func() {
+			var events []string
+			stateMachine.OnState(nodeclaimlifecycle.StatePending, reconcile.TypedFunc[*v1.NodeClaim](func(_ context.Context, nodeClaim *v1.NodeClaim) (reconcile.Result, error) {
+				events = append(events, "launch")
+				nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+				return reconcile.Result{}, nil
+			}))
+			stateMachine.OnState(nodeclaimlifecycle.StateLaunched, reconcile.TypedFunc[*v1.NodeClaim](func(context.Context, *v1.NodeClaim) (reconcile.Result, error) {
+				events = append(events, "register")
+				return reconcile.Result{RequeueAfter: time.Minute}, nil
+			}))
+			Expect(stateMachine.OnTransition(nodeclaimlifecycle.StatePending, nodeclaimlifecycle.StateLaunched, func(context.Context, *v1.NodeClaim, nodeclaimlifecycle.State, nodeclaimlifecycle.State) error {
+				events = append(events, "launched")
+				return nil
+			})).To(Succeed())
+
+			res, err := stateMachine.Reconcile(ctx, nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(time.Minute))
+			Expect(events).To(Equal([]string{"launch", "launched", "register"}))
+			Expect(nodeclaimlifecycle.StoredStateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateLaunched))
+		})
+	})
+	Context("Controller", func() {
+		It("should record the lifecycle state on the nodeclaim as it progresses", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeclaimlifecycle.StoredStateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateLaunched))
+
+			ExpectMakeNodeClaimsInitialized(ctx, env.Client, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeclaimlifecycle.StoredStateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateActive))
+		})
+		It("should record the draining and terminating states once the nodeclaim is deleted", func() {
+			ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			nodeClaim.Finalizers = append(nodeClaim.Finalizers, "test-finalizer")
+			ExpectApplied(ctx, env.Client, nodeClaim)
+
+			ExpectDeletionTimestampSet(ctx, env.Client, nodeClaim)
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeclaimlifecycle.StoredStateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateDraining))
+
+			// The instance is deleted on the first pass, so the second pass finds it gone and removes the finalizer
+			ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+			nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+			Expect(nodeclaimlifecycle.StoredStateFor(nodeClaim)).To(Equal(nodeclaimlifecycle.StateTerminating))
+		})
+	})
+})
@@ -369,6 +369,48 @@ var _ = Describe("Termination", func() {
 		_, annotationExists := nodeClaim.Annotations[v1.NodeClaimTerminationTimestampAnnotationKey]
 		Expect(annotationExists).To(BeTrue())
 	})
+	It("should annotate the node using the terminationGracePeriodOverride for the disruption reason", func() {
+		nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
+		nodeClaim.Spec.TerminationGracePeriodOverrides = []v1.TerminationGracePeriodOverride{
+			{Reason: string(v1.DisruptionReasonDrifted), TerminationGracePeriod: v1.MustParseNillableDuration("24h")},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		node := test.NodeClaimLinkedNode(nodeClaim)
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, string(v1.DisruptionReasonDrifted), string(v1.DisruptionReasonDrifted))
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim) // triggers the node deletion
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodeClaimTerminationTimestampAnnotationKey, nodeClaim.DeletionTimestamp.Add(24*time.Hour).Format(time.RFC3339)))
+	})
+	It("should not annotate the node if the terminationGracePeriodOverride for the disruption reason is Never", func() {
+		nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
+		nodeClaim.Spec.TerminationGracePeriodOverrides = []v1.TerminationGracePeriodOverride{
+			{Reason: v1.DisruptionReasonExpired, TerminationGracePeriod: v1.MustParseNillableDuration("Never")},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		node := test.NodeClaimLinkedNode(nodeClaim)
+		ExpectApplied(ctx, env.Client, node)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		nodeClaim.StatusConditions().SetTrueWithReason(v1.ConditionTypeDisruptionReason, v1.DisruptionReasonExpired, v1.DisruptionReasonExpired)
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim) // triggers the node deletion
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.NodeClaimTerminationTimestampAnnotationKey))
+	})
 	It("should not change the annotation if the NodeClaim has a terminationGracePeriod and the annotation already exists", func() {
 		nodeClaim.Spec.TerminationGracePeriod = &metav1.Duration{Duration: time.Second * 300}
 		nodeClaim.Annotations = map[string]string{
@@ -411,4 +453,28 @@ var _ = Describe("Termination", func() {
 		ExpectExists(ctx, env.Client, node)
 		ExpectNotFound(ctx, env.Client, nodeClaim)
 	})
+	It("should remove termination holds that timed out", func() {
+		nodeClaim.Finalizers = append(nodeClaim.Finalizers, v1.TerminationHoldFinalizerPrefix+"backup")
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		nodeClaim.StatusConditions().SetFalse(v1.ConditionTypeTerminationHoldsReleased, "TerminationHoldsTimedOut", "TerminationHoldsTimedOut")
+		ExpectApplied(ctx, env.Client, nodeClaim)
+
+		Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim) // triggers the instance termination
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim) // the instance is gone, so the finalizers are removed
+		ExpectNotFound(ctx, env.Client, nodeClaim)
+	})
+	It("should not remove termination holds that haven't timed out", func() {
+		nodeClaim.Finalizers = append(nodeClaim.Finalizers, v1.TerminationHoldFinalizerPrefix+"backup")
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim)
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim)
+
+		Expect(env.Client.Delete(ctx, nodeClaim)).To(Succeed())
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim) // triggers the instance termination
+		ExpectObjectReconciled(ctx, env.Client, nodeClaimController, nodeClaim) // the instance is gone, so the termination finalizer is removed
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Finalizers).To(ConsistOf(v1.TerminationHoldFinalizerPrefix + "backup"))
+	})
 })
@@ -0,0 +1,116 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// Controller finds the instances attributed to the cluster that have no corresponding NodeClaim or Node, e.g. because
+// the controller crashed after launching an instance but before recording it on its NodeClaim. Orphaned instances are
+// reported through metrics and deleted when orphaned instance deletion is enabled.
+type Controller struct {
+	clock         clock.Clock
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	lister        cloudprovider.ClusterInstanceLister
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, lister cloudprovider.ClusterInstanceLister) *Controller {
+	return &Controller{
+		clock:         clk,
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		lister:        lister,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodeclaim.orphan")
+
+	// Instances are listed before the NodeClaims so that an instance that's launched in between is always matched
+	instances, err := c.lister.ListClusterInstances(ctx)
+	if err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing cluster instances, %w", err)
+	}
+	nodeClaimList := &v1.NodeClaimList{}
+	if err = c.kubeClient.List(ctx, nodeClaimList); err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing nodeclaims, %w", err)
+	}
+	nodeList := &corev1.NodeList{}
+	if err = c.kubeClient.List(ctx, nodeList); err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing nodes, %w", err)
+	}
+	providerIDs := sets.New(lo.Map(nodeClaimList.Items, func(nc v1.NodeClaim, _ int) string { return nc.Status.ProviderID })...)
+	providerIDs.Insert(lo.Map(nodeList.Items, func(n corev1.Node, _ int) string { return n.Spec.ProviderID })...)
+	orphans := lo.Filter(instances, func(instance *v1.NodeClaim, _ int) bool {
+		return instance.DeletionTimestamp.IsZero() &&
+			!providerIDs.Has(instance.Status.ProviderID) &&
+			c.clock.Since(instance.CreationTimestamp.Time) >= options.FromContext(ctx).OrphanedInstanceMinAge
+	})
+	OrphanedInstances.Set(float64(len(orphans)), nil)
+
+	if !options.FromContext(ctx).OrphanedInstanceDeletion {
+		for _, orphan := range orphans {
+			log.FromContext(ctx).WithValues("provider-id", orphan.Status.ProviderID, "NodePool", orphan.Labels[v1.NodePoolLabelKey]).Info("found orphaned instance with no nodeclaim or node")
+		}
+		return reconciler.Result{RequeueAfter: time.Minute * 5}, nil
+	}
+	errs := make([]error, len(orphans))
+	workqueue.ParallelizeUntil(ctx, 20, len(orphans), func(i int) {
+		if err := c.cloudProvider.Delete(ctx, orphans[i]); cloudprovider.IgnoreNodeClaimNotFoundError(err) != nil {
+			errs[i] = fmt.Errorf("deleting orphaned instance, %w", err)
+			return
+		}
+		log.FromContext(ctx).WithValues("provider-id", orphans[i].Status.ProviderID, "NodePool", orphans[i].Labels[v1.NodePoolLabelKey]).Info("deleted orphaned instance with no nodeclaim or node")
+		OrphanedInstancesDeletedTotal.Inc(map[string]string{
+			metrics.NodePoolLabel: orphans[i].Labels[v1.NodePoolLabelKey],
+		})
+	})
+	if err = multierr.Combine(errs...); err != nil {
+		return reconciler.Result{}, err
+	}
+	return reconciler.Result{RequeueAfter: time.Minute * 5}, nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodeclaim.orphan").
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/orphan"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var orphanController *orphan.Controller
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cloudProvider *fake.CloudProvider
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Orphan")
+}
+
+var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	orphanController = orphan.NewController(fakeClock, env.Client, cloudProvider, cloudProvider)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = AfterEach(func() {
+	fakeClock.SetTime(time.Now())
+	ExpectCleanedUp(ctx, env.Client)
+	cloudProvider.Reset()
+})
+
+var _ = Describe("Orphan", func() {
+	var nodePool *v1.NodePool
+	var instance *v1.NodeClaim
+
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		instance = test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey: nodePool.Name,
+				},
+				CreationTimestamp: metav1.NewTime(fakeClock.Now().Add(-time.Hour)),
+			},
+			Status: v1.NodeClaimStatus{
+				ProviderID: test.RandomProviderID(),
+			},
+		})
+		cloudProvider.CreatedNodeClaims[instance.Status.ProviderID] = instance
+	})
+	It("should report an orphaned instance without deleting it by default", func() {
+		ExpectSingletonReconciled(ctx, orphanController)
+		ExpectMetricGaugeValue(orphan.OrphanedInstances, 1, nil)
+		Expect(cloudProvider.CreatedNodeClaims).To(HaveKey(instance.Status.ProviderID))
+		Expect(cloudProvider.DeleteCalls).To(BeEmpty())
+	})
+	It("should delete an orphaned instance when orphaned instance deletion is enabled", func() {
+		deletionCtx := options.ToContext(ctx, test.Options(test.OptionsFields{OrphanedInstanceDeletion: lo.ToPtr(true)}))
+		ExpectSingletonReconciled(deletionCtx, orphanController)
+		Expect(cloudProvider.CreatedNodeClaims).ToNot(HaveKey(instance.Status.ProviderID))
+		ExpectMetricCounterValue(orphan.OrphanedInstancesDeletedTotal, 1, map[string]string{
+			metrics.NodePoolLabel: nodePool.Name,
+		})
+	})
+	It("shouldn't delete an instance that has a NodeClaim", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{Status: v1.NodeClaimStatus{ProviderID: instance.Status.ProviderID}})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		deletionCtx := options.ToContext(ctx, test.Options(test.OptionsFields{OrphanedInstanceDeletion: lo.ToPtr(true)}))
+		ExpectSingletonReconciled(deletionCtx, orphanController)
+		ExpectMetricGaugeValue(orphan.OrphanedInstances, 0, nil)
+		Expect(cloudProvider.CreatedNodeClaims).To(HaveKey(instance.Status.ProviderID))
+	})
+	It("shouldn't delete an instance that has a Node without a NodeClaim", func() {
+		node := test.Node(test.NodeOptions{ProviderID: instance.Status.ProviderID})
+		ExpectApplied(ctx, env.Client, node)
+		deletionCtx := options.ToContext(ctx, test.Options(test.OptionsFields{OrphanedInstanceDeletion: lo.ToPtr(true)}))
+		ExpectSingletonReconciled(deletionCtx, orphanController)
+		ExpectMetricGaugeValue(orphan.OrphanedInstances, 0, nil)
+		Expect(cloudProvider.CreatedNodeClaims).To(HaveKey(instance.Status.ProviderID))
+	})
+	It("shouldn't delete an instance that's younger than the minimum age", func() {
+		instance.CreationTimestamp = metav1.NewTime(fakeClock.Now())
+		deletionCtx := options.ToContext(ctx, test.Options(test.OptionsFields{OrphanedInstanceDeletion: lo.ToPtr(true)}))
+		ExpectSingletonReconciled(deletionCtx, orphanController)
+		Expect(cloudProvider.CreatedNodeClaims).To(HaveKey(instance.Status.ProviderID))
+
+		fakeClock.Step(time.Minute * 11)
+		ExpectSingletonReconciled(deletionCtx, orphanController)
+		Expect(cloudProvider.CreatedNodeClaims).ToNot(HaveKey(instance.Status.ProviderID))
+	})
+})
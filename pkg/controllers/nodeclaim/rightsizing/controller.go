@@ -0,0 +1,214 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightsizing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/singleton"
+	"github.com/samber/lo"
+	"go.uber.org/multierr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+// pollingPeriod is how often recommendations are recomputed for every node in the cluster
+const pollingPeriod = 5 * time.Minute
+
+// Recommendation is the cheapest instance type that would still fit the pods currently bound to a node
+type Recommendation struct {
+	InstanceType     string
+	CurrentPrice     float64
+	RecommendedPrice float64
+}
+
+// EstimatedSavings is the difference in price between the node's current offering and the recommended offering
+func (r Recommendation) EstimatedSavings() float64 {
+	return r.CurrentPrice - r.RecommendedPrice
+}
+
+// Controller is an advisory controller that periodically computes, for each Karpenter-managed node, the cheapest
+// instance type that would still fit its current pods plus a configurable amount of headroom. Recommendations are
+// surfaced through metrics and NodeClaim annotations so that teams can act on them manually when automatic
+// consolidation is disabled. This controller never disrupts or replaces nodes.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	cluster       *state.Cluster
+	metricStore   *metrics.Store
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, cluster *state.Cluster) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		cluster:       cluster,
+		metricStore:   metrics.NewStore(),
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodeclaim.rightsizing")
+
+	if !c.cluster.Synced(ctx) {
+		return reconciler.Result{RequeueAfter: time.Second}, nil
+	}
+	nodePools, err := nodepoolutils.ListManaged(ctx, c.kubeClient, c.cloudProvider)
+	if err != nil {
+		return reconciler.Result{}, fmt.Errorf("listing nodepools, %w", err)
+	}
+	nodePoolMap := lo.SliceToMap(nodePools, func(np *v1.NodePool) (string, *v1.NodePool) { return np.Name, np })
+	instanceTypes := map[string][]*cloudprovider.InstanceType{}
+
+	var errs error
+	metricsMap := map[string][]*metrics.StoreMetric{}
+	savings := map[string]float64{}
+	for _, n := range c.cluster.DeepCopyNodes() {
+		if !n.Managed() || !n.Initialized() || n.MarkedForDeletion() {
+			continue
+		}
+		nodePool, ok := nodePoolMap[n.Labels()[v1.NodePoolLabelKey]]
+		if !ok {
+			continue
+		}
+		if _, ok = instanceTypes[nodePool.Name]; !ok {
+			its, err := c.cloudProvider.GetInstanceTypes(ctx, nodePool)
+			if err != nil {
+				log.FromContext(ctx).WithValues("NodePool", klog.KObj(nodePool)).Error(err, "failed listing instance types")
+				continue
+			}
+			instanceTypes[nodePool.Name] = its
+		}
+		recommendation, found := Recommend(n, nodePool, instanceTypes[nodePool.Name], options.FromContext(ctx).RightsizingHeadroomPercent)
+		if err := c.annotate(ctx, n.NodeClaim, recommendation, found); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+		if !found {
+			continue
+		}
+		savings[nodePool.Name] += recommendation.EstimatedSavings()
+		// The per-nodeclaim metric has a series per nodeclaim, so it's skipped when high-cardinality metrics are disabled
+		if options.FromContext(ctx).HighCardinalityMetrics {
+			metricsMap[n.NodeClaim.Name] = buildMetrics(n, recommendation)
+		}
+	}
+	metricsMap["nodePoolAggregates"] = buildNodePoolMetrics(savings)
+	c.metricStore.ReplaceAll(metricsMap)
+	if errs != nil {
+		return reconciler.Result{}, errs
+	}
+	return reconciler.Result{RequeueAfter: pollingPeriod}, nil
+}
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodeclaim.rightsizing").
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(c))
+}
+
+// annotate sets or removes the rightsizing annotations on the NodeClaim, only patching when something changed
+func (c *Controller) annotate(ctx context.Context, nodeClaim *v1.NodeClaim, recommendation Recommendation, found bool) error {
+	stored := nodeClaim.DeepCopy()
+	if found {
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{
+			v1.RightsizingRecommendationAnnotationKey:   recommendation.InstanceType,
+			v1.RightsizingEstimatedSavingsAnnotationKey: strconv.FormatFloat(recommendation.EstimatedSavings(), 'f', 4, 64),
+		})
+	} else {
+		delete(nodeClaim.Annotations, v1.RightsizingRecommendationAnnotationKey)
+		delete(nodeClaim.Annotations, v1.RightsizingEstimatedSavingsAnnotationKey)
+	}
+	if equality.Semantic.DeepEqual(stored.Annotations, nodeClaim.Annotations) {
+		return nil
+	}
+	if err := c.kubeClient.Patch(ctx, nodeClaim, client.MergeFrom(stored)); err != nil {
+		return client.IgnoreNotFound(fmt.Errorf("patching nodeclaim, %w", err))
+	}
+	return nil
+}
+
+// Recommend returns the cheapest instance type, compatible with the NodePool requirements and the node's current zone
+// and capacity type, whose allocatable resources fit the node's current pod requests plus headroom. A recommendation
+// is only returned if it is strictly cheaper than the node's current offering.
+func Recommend(n *state.StateNode, nodePool *v1.NodePool, instanceTypes []*cloudprovider.InstanceType, headroomPercent int) (Recommendation, bool) {
+	current, ok := lo.Find(instanceTypes, func(it *cloudprovider.InstanceType) bool {
+		return it.Name == n.Labels()[corev1.LabelInstanceTypeStable]
+	})
+	if !ok {
+		return Recommendation{}, false
+	}
+	// Only compare offerings in the same region, zone and capacity type so that the recommendation can be acted on
+	// without changing the node's placement or purchase model
+	offeringReqs := scheduling.NewLabelRequirements(lo.PickByKeys(n.Labels(), []string{corev1.LabelTopologyRegion, corev1.LabelTopologyZone, v1.CapacityTypeLabelKey}))
+	currentOffering := current.Offerings.Compatible(offeringReqs).Cheapest()
+	if currentOffering == nil {
+		return Recommendation{}, false
+	}
+	requests := withHeadroom(n.PodRequests(), headroomPercent)
+	nodePoolReqs := scheduling.NewNodeSelectorRequirementsWithMinValues(nodePool.Requirements()...)
+
+	recommendation := Recommendation{CurrentPrice: currentOffering.Price, RecommendedPrice: math.MaxFloat64}
+	for _, it := range instanceTypes {
+		if it.Name == current.Name || !nodePoolReqs.IsCompatible(it.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
+			continue
+		}
+		if !resources.Fits(requests, it.Allocatable()) {
+			continue
+		}
+		offering := it.Offerings.Available().Compatible(offeringReqs).Cheapest()
+		if offering == nil || offering.Price >= recommendation.RecommendedPrice {
+			continue
+		}
+		recommendation.InstanceType = it.Name
+		recommendation.RecommendedPrice = offering.Price
+	}
+	if recommendation.InstanceType == "" || recommendation.RecommendedPrice >= recommendation.CurrentPrice {
+		return Recommendation{}, false
+	}
+	return recommendation, true
+}
+
+// withHeadroom scales every requested resource up by the given percentage
+func withHeadroom(requests corev1.ResourceList, headroomPercent int) corev1.ResourceList {
+	ret := corev1.ResourceList{}
+	for name, quantity := range requests {
+		ret[name] = *resource.NewMilliQuantity(quantity.MilliValue()*int64(100+headroomPercent)/100, quantity.Format)
+	}
+	return ret
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightsizing
+
+import (
+	opmetrics "github.com/awslabs/operatorpkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/metrics"
+)
+
+const (
+	nodeClaimLabel               = "nodeclaim"
+	instanceTypeLabel            = "instance_type"
+	recommendedInstanceTypeLabel = "recommended_instance_type"
+)
+
+var (
+	EstimatedSavings = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.NodeClaimSubsystem,
+			Name:      "rightsizing_estimated_savings",
+			Help:      "Estimated price difference between a nodeclaim's current offering and the cheapest instance type that would still fit its pods. Labeled by nodeclaim, nodepool, current instance type, and recommended instance type.",
+		},
+		[]string{nodeClaimLabel, metrics.NodePoolLabel, instanceTypeLabel, recommendedInstanceTypeLabel},
+	)
+	NodePoolEstimatedSavings = opmetrics.NewPrometheusGauge(
+		crmetrics.Registry,
+		prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: metrics.NodePoolSubsystem,
+			Name:      "rightsizing_estimated_savings",
+			Help:      "Estimated price difference summed across the nodeclaims of a nodepool that have a rightsizing recommendation. Labeled by nodepool.",
+		},
+		[]string{metrics.NodePoolLabel},
+	)
+)
+
+func buildNodePoolMetrics(savings map[string]float64) []*metrics.StoreMetric {
+	return lo.MapToSlice(savings, func(nodePool string, value float64) *metrics.StoreMetric {
+		return &metrics.StoreMetric{
+			GaugeMetric: NodePoolEstimatedSavings,
+			Value:       value,
+			Labels:      map[string]string{metrics.NodePoolLabel: nodePool},
+		}
+	})
+}
+
+func buildMetrics(n *state.StateNode, recommendation Recommendation) []*metrics.StoreMetric {
+	return []*metrics.StoreMetric{
+		{
+			GaugeMetric: EstimatedSavings,
+			Value:       recommendation.EstimatedSavings(),
+			Labels: map[string]string{
+				nodeClaimLabel:               n.NodeClaim.Name,
+				metrics.NodePoolLabel:        n.Labels()[v1.NodePoolLabelKey],
+				instanceTypeLabel:            n.Labels()[corev1.LabelInstanceTypeStable],
+				recommendedInstanceTypeLabel: recommendation.InstanceType,
+			},
+		},
+	}
+}
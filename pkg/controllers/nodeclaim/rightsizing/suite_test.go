@@ -0,0 +1,218 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightsizing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodeclaim/rightsizing"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+var env *test.Environment
+var fakeClock *clock.FakeClock
+var cluster *state.Cluster
+var cloudProvider *fake.CloudProvider
+var nodeStateController *informer.NodeController
+var nodeClaimStateController *informer.NodeClaimController
+var rightsizingController *rightsizing.Controller
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rightsizing")
+}
+
+var _ = BeforeSuite(func() {
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider = fake.NewCloudProvider()
+	fakeClock = clock.NewFakeClock(time.Now())
+	cluster = state.NewCluster(fakeClock, env.Client, cloudProvider)
+	nodeStateController = informer.NewNodeController(env.Client, cluster)
+	nodeClaimStateController = informer.NewNodeClaimController(env.Client, cloudProvider, cluster)
+	rightsizingController = rightsizing.NewController(env.Client, cloudProvider, cluster)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options())
+	cloudProvider.Reset()
+	cluster.Reset()
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = Describe("Rightsizing", func() {
+	var nodePool *v1.NodePool
+	var nodeClaim *v1.NodeClaim
+	var node *corev1.Node
+	var largeInstanceType, smallInstanceType *cloudprovider.InstanceType
+
+	BeforeEach(func() {
+		largeInstanceType = fake.NewInstanceType(fake.InstanceTypeOptions{
+			Name: "large-instance-type",
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		})
+		smallInstanceType = fake.NewInstanceType(fake.InstanceTypeOptions{
+			Name: "small-instance-type",
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		})
+		cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{largeInstanceType, smallInstanceType}
+
+		nodePool = test.NodePool()
+		nodeClaim, node = test.NodeClaimAndNode(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					v1.NodePoolLabelKey:            nodePool.Name,
+					corev1.LabelInstanceTypeStable: largeInstanceType.Name,
+					v1.CapacityTypeLabelKey:        v1.CapacityTypeOnDemand,
+					corev1.LabelTopologyZone:       "test-zone-1",
+				},
+			},
+			Status: v1.NodeClaimStatus{
+				Allocatable: largeInstanceType.Allocatable(),
+			},
+		})
+	})
+	It("should recommend a smaller instance type when the node is under-utilized", func() {
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, rightsizingController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.RightsizingRecommendationAnnotationKey, smallInstanceType.Name))
+		Expect(nodeClaim.Annotations).To(HaveKey(v1.RightsizingEstimatedSavingsAnnotationKey))
+		savings := largeInstanceType.Offerings.Cheapest().Price - smallInstanceType.Offerings.Cheapest().Price
+		ExpectMetricGaugeValue(rightsizing.EstimatedSavings, savings, map[string]string{
+			"nodeclaim":                 nodeClaim.Name,
+			"nodepool":                  nodePool.Name,
+			"instance_type":             largeInstanceType.Name,
+			"recommended_instance_type": smallInstanceType.Name,
+		})
+		ExpectMetricGaugeValue(rightsizing.NodePoolEstimatedSavings, savings, map[string]string{
+			"nodepool": nodePool.Name,
+		})
+	})
+	It("should only emit the nodepool savings metric when high-cardinality metrics are disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{HighCardinalityMetrics: lo.ToPtr(false)}))
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, rightsizingController)
+
+		_, found := FindMetricWithLabelValues("karpenter_nodeclaims_rightsizing_estimated_savings", map[string]string{
+			"nodeclaim": nodeClaim.Name,
+		})
+		Expect(found).To(BeFalse())
+		savings := largeInstanceType.Offerings.Cheapest().Price - smallInstanceType.Offerings.Cheapest().Price
+		ExpectMetricGaugeValue(rightsizing.NodePoolEstimatedSavings, savings, map[string]string{
+			"nodepool": nodePool.Name,
+		})
+	})
+	It("should not recommend an instance type when the pods would not fit with headroom", func() {
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.9")},
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, rightsizingController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.RightsizingRecommendationAnnotationKey))
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.RightsizingEstimatedSavingsAnnotationKey))
+	})
+	It("should not recommend an instance type that is incompatible with the nodepool requirements", func() {
+		nodePool.Spec.Template.Spec.Requirements = []v1.NodeSelectorRequirementWithMinValues{
+			{
+				NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+					Key:      corev1.LabelInstanceTypeStable,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{largeInstanceType.Name},
+				},
+			},
+		}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, rightsizingController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.RightsizingRecommendationAnnotationKey))
+	})
+	It("should remove a stale recommendation when it no longer applies", func() {
+		nodeClaim.Annotations = map[string]string{
+			v1.RightsizingRecommendationAnnotationKey:   smallInstanceType.Name,
+			v1.RightsizingEstimatedSavingsAnnotationKey: "0.6000",
+		}
+		pod := test.Pod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+		}})
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaim, node, pod)
+		ExpectManualBinding(ctx, env.Client, pod, node)
+		ExpectMakeNodesAndNodeClaimsInitializedAndStateUpdated(ctx, env.Client, nodeStateController, nodeClaimStateController, []*corev1.Node{node}, []*v1.NodeClaim{nodeClaim})
+
+		ExpectSingletonReconciled(ctx, rightsizingController)
+
+		nodeClaim = ExpectExists(ctx, env.Client, nodeClaim)
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.RightsizingRecommendationAnnotationKey))
+		Expect(nodeClaim.Annotations).ToNot(HaveKey(v1.RightsizingEstimatedSavingsAnnotationKey))
+	})
+})
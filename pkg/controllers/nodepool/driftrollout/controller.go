@@ -0,0 +1,95 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftrollout
+
+import (
+	"context"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/equality"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+// Controller surfaces the progress of replacing a NodePool's drifted nodes on the NodePool's status. The number of
+// remaining drifted nodes is computed from the NodePool's NodeClaims, while the number of replaced nodes is recorded
+// by the disruption queue when a drift command succeeds.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodepool.driftrollout")
+
+	nodeClaims := &v1.NodeClaimList{}
+	if err := c.kubeClient.List(ctx, nodeClaims, client.MatchingLabels{v1.NodePoolLabelKey: nodePool.Name}); err != nil {
+		return reconcile.Result{}, err
+	}
+	remaining := int64(lo.CountBy(nodeClaims.Items, func(nc v1.NodeClaim) bool {
+		return nc.StatusConditions().Get(v1.ConditionTypeDrifted).IsTrue()
+	}))
+	// Don't surface a rollout on NodePools that have never drifted
+	if remaining == 0 && nodePool.Status.DriftRollout == nil {
+		return reconcile.Result{}, nil
+	}
+	stored := nodePool.DeepCopy()
+	rollout := lo.FromPtr(nodePool.Status.DriftRollout)
+	// A new rollout starts when nodes drift after every drifted node from the previous rollout was replaced
+	if remaining > 0 && rollout.Remaining == 0 {
+		rollout = v1.DriftRolloutStatus{}
+	}
+	rollout.Remaining = remaining
+	rollout.Drifted = rollout.Replaced + rollout.Remaining
+	nodePool.Status.DriftRollout = &rollout
+	// Drift resumes once a new rollout starts or the failure threshold is raised above (or removed from) the failures
+	if threshold := nodePool.Spec.Disruption.DriftFailureThreshold; threshold == nil || rollout.Failed < int64(*threshold) {
+		_ = nodePool.StatusConditions().Clear(v1.ConditionTypeDriftPaused)
+	}
+	if !equality.Semantic.DeepEqual(stored, nodePool) {
+		if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFrom(stored)); err != nil {
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodepool.driftrollout").
+		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		Watches(&v1.NodeClaim{}, nodepoolutils.NodeClaimEventHandler()).
+		WithOptions(controller.Options{MaxConcurrentReconciles: utilscontroller.LinearScaleReconciles(utilscontroller.CPUCount(ctx), 10, 1000)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftrollout_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/driftrollout"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller    *driftrollout.Controller
+	ctx           context.Context
+	env           *test.Environment
+	cloudProvider *fake.CloudProvider
+	nodePool      *v1.NodePool
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DriftRollout")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	controller = driftrollout.NewController(env.Client, cloudProvider)
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("DriftRollout", func() {
+	var nodeClaims []*v1.NodeClaim
+	BeforeEach(func() {
+		nodePool = test.NodePool()
+		nodeClaims = lo.Times(3, func(_ int) *v1.NodeClaim {
+			return test.NodeClaim(v1.NodeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+				},
+			})
+		})
+	})
+	It("should not surface a rollout when no nodes have drifted", func() {
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.DriftRollout).To(BeNil())
+	})
+	It("should count drifted NodeClaims as remaining", func() {
+		nodeClaims[0].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		nodeClaims[1].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.DriftRollout).ToNot(BeNil())
+		Expect(nodePool.Status.DriftRollout.Remaining).To(BeNumerically("==", 2))
+		Expect(nodePool.Status.DriftRollout.Replaced).To(BeNumerically("==", 0))
+		Expect(nodePool.Status.DriftRollout.Drifted).To(BeNumerically("==", 2))
+	})
+	It("should include replaced nodes in the drifted total", func() {
+		nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Replaced: 2, Remaining: 1}
+		nodeClaims[0].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.DriftRollout.Remaining).To(BeNumerically("==", 1))
+		Expect(nodePool.Status.DriftRollout.Replaced).To(BeNumerically("==", 2))
+		Expect(nodePool.Status.DriftRollout.Drifted).To(BeNumerically("==", 3))
+	})
+	It("should start a new rollout once the previous rollout completed", func() {
+		nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Replaced: 3, Remaining: 0, LastReplacementTime: lo.ToPtr(metav1.Now())}
+		nodeClaims[0].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.DriftRollout.Remaining).To(BeNumerically("==", 1))
+		Expect(nodePool.Status.DriftRollout.Replaced).To(BeNumerically("==", 0))
+		Expect(nodePool.Status.DriftRollout.Drifted).To(BeNumerically("==", 1))
+		Expect(nodePool.Status.DriftRollout.LastReplacementTime).To(BeNil())
+	})
+	It("should keep a completed rollout on the status", func() {
+		nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Replaced: 2, Remaining: 1}
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.DriftRollout.Remaining).To(BeNumerically("==", 0))
+		Expect(nodePool.Status.DriftRollout.Replaced).To(BeNumerically("==", 2))
+		Expect(nodePool.Status.DriftRollout.Drifted).To(BeNumerically("==", 2))
+	})
+	It("should resume drift when a new rollout starts", func() {
+		nodePool.Spec.Disruption.DriftFailureThreshold = lo.ToPtr[int32](2)
+		nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Replaced: 3, Remaining: 0, Failed: 2}
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeDriftPaused, "FailureThresholdReached", "FailureThresholdReached")
+		nodeClaims[0].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.DriftRollout.Failed).To(BeNumerically("==", 0))
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDriftPaused)).To(BeNil())
+	})
+	It("should keep drift paused while the failures reach the threshold", func() {
+		nodePool.Spec.Disruption.DriftFailureThreshold = lo.ToPtr[int32](2)
+		nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Replaced: 1, Remaining: 2, Failed: 2}
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeDriftPaused, "FailureThresholdReached", "FailureThresholdReached")
+		nodeClaims[0].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		nodeClaims[1].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDriftPaused).IsTrue()).To(BeTrue())
+	})
+	It("should resume drift when the threshold is raised above the failures", func() {
+		nodePool.Spec.Disruption.DriftFailureThreshold = lo.ToPtr[int32](5)
+		nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Replaced: 1, Remaining: 2, Failed: 2}
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeDriftPaused, "FailureThresholdReached", "FailureThresholdReached")
+		nodeClaims[0].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		nodeClaims[1].StatusConditions().SetTrue(v1.ConditionTypeDrifted)
+		ExpectApplied(ctx, env.Client, nodePool, nodeClaims[0], nodeClaims[1], nodeClaims[2])
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDriftPaused)).To(BeNil())
+	})
+})
@@ -147,6 +147,23 @@ var _ = Describe("Static Drift Hash", func() {
 
 		Expect(nodePool.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, expectedHash))
 	})
+	It("should not update the drift hash when a NodePool static field that isn't selected by driftHashFields is updated", func() {
+		nodePool.Spec.Disruption.DriftHashFields = []v1.DriftHashField{v1.DriftHashFieldTaints}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+
+		expectedHash := nodePool.Hash()
+		Expect(nodePool.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, expectedHash))
+
+		nodePool.Spec.Template.Labels = map[string]string{"keyLabeltest": "valueLabeltest"}
+		nodePool.Spec.Template.Annotations = map[string]string{"keyAnnotation": "valueAnnotation"}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+
+		Expect(nodePool.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, expectedHash))
+	})
 	It("should update nodepool hash version when the nodepool hash version is out of sync with the controller hash version", func() {
 		nodePool.Annotations = map[string]string{
 			v1.NodePoolHashAnnotationKey:        "abceduefed",
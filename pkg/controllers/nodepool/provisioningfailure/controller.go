@@ -0,0 +1,178 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioningfailure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+)
+
+// maxWeight is the largest weight that can be set on a NodePool
+const maxWeight = int32(100)
+
+// Controller reacts to NodePools that have exceeded the consecutive provisioning failure threshold. It first tries to
+// widen the NodePool's instance-type requirements and, when nothing can be widened, escalates to the NodePool's
+// fallback by raising the fallback's weight above the failing NodePool. Both are recorded in the status of the NodePool
+// they apply to rather than in its spec, so that they're reverted once the failing NodePool recovers: widened
+// requirements once a NodeClaim that satisfies the original requirements registers, and the escalated weight once a
+// NodeClaim for the failing NodePool registers. Every decision is evented on the NodePool.
+type Controller struct {
+	kubeClient    client.Client
+	cloudProvider cloudprovider.CloudProvider
+	recorder      events.Recorder
+}
+
+func NewController(kubeClient client.Client, cloudProvider cloudprovider.CloudProvider, recorder events.Recorder) *Controller {
+	return &Controller{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		recorder:      recorder,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "nodepool.provisioningfailure")
+
+	threshold := options.FromContext(ctx).ProvisioningFailureThreshold
+	if threshold == 0 || nodePool.Status.ConsecutiveProvisioningFailures < int64(threshold) {
+		return reconcile.Result{}, nil
+	}
+	stored := nodePool.DeepCopy()
+	failures := nodePool.Status.ConsecutiveProvisioningFailures
+	instanceTypes, err := c.cloudProvider.GetInstanceTypes(ctx, nodePool)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("getting instance types, %w", err)
+	}
+	widened := Widen(nodePool, instanceTypes)
+	acted := len(widened) > 0
+	if !acted {
+		if acted, err = c.escalate(ctx, nodePool); err != nil {
+			if errors.IsConflict(err) {
+				return reconcile.Result{Requeue: true}, nil
+			}
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+	// Leave the failure count in place when nothing could be done so that a later change to the NodePool or its
+	// fallback is still acted on. Otherwise, reset it so that the next decision requires another run of failures.
+	if !acted {
+		return reconcile.Result{}, nil
+	}
+	nodePool.Status.ConsecutiveProvisioningFailures = 0
+	// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
+	// can cause races due to the fact that it fully replaces the list on a change
+	// Here, we are updating the widened requirements list
+	if err := c.kubeClient.Status().Patch(ctx, nodePool, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if len(widened) > 0 {
+		log.FromContext(ctx).WithValues("requirements", widened, "failures", failures).Info("widened nodepool requirements after consecutive provisioning failures")
+		c.recorder.Publish(WidenedEvent(nodePool, widened, failures))
+	}
+	return reconcile.Result{}, nil
+}
+
+// escalate raises the weight of the NodePool's fallback above the failing NodePool, returning true if the fallback was
+// updated
+func (c *Controller) escalate(ctx context.Context, nodePool *v1.NodePool) (bool, error) {
+	name, ok := nodePool.Annotations[v1.ProvisioningFallbackNodePoolAnnotationKey]
+	if !ok || name == nodePool.Name {
+		return false, nil
+	}
+	fallback := &v1.NodePool{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: name}, fallback); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	// Weight isn't supported on static NodePools, so there is nothing to escalate to
+	if nodepoolutils.IsStatic(fallback) {
+		return false, nil
+	}
+	weight := nodePool.Weight() + 1
+	if weight > maxWeight || fallback.Weight() >= weight {
+		return false, nil
+	}
+	stored := fallback.DeepCopy()
+	fallback.Status.EscalatedWeight = lo.ToPtr(weight)
+	fallback.Status.EscalatedBy = nodePool.Name
+	if err := c.kubeClient.Status().Patch(ctx, fallback, client.MergeFromWithOptions(stored, client.MergeFromWithOptimisticLock{})); err != nil {
+		return false, err
+	}
+	log.FromContext(ctx).WithValues("fallback", klog.KObj(fallback), "weight", weight, "failures", nodePool.Status.ConsecutiveProvisioningFailures).Info("escalated to fallback nodepool after consecutive provisioning failures")
+	c.recorder.Publish(EscalatedEvent(nodePool, fallback, nodePool.Status.ConsecutiveProvisioningFailures))
+	return true, nil
+}
+
+// Widen records every instance-type requirement on the NodePool that is restricted to a set of values as widened to
+// Exists in the NodePool's status, returning the keys that were widened. A requirement is only widened if its minValues
+// permit it: the available instance types that are compatible with the widened requirements must satisfy the
+// requirement's minValues, and must include instance types that the requirement currently excludes.
+func Widen(nodePool *v1.NodePool, instanceTypes []*cloudprovider.InstanceType) []string {
+	var widened []string
+	for _, req := range nodePool.Spec.Template.Spec.Requirements {
+		if req.Key != corev1.LabelInstanceTypeStable || req.Operator != corev1.NodeSelectorOpIn || lo.Contains(nodePool.Status.WidenedRequirements, req.Key) {
+			continue
+		}
+		candidate := nodePool.DeepCopy()
+		candidate.Status.WidenedRequirements = append(candidate.Status.WidenedRequirements, req.Key)
+		reqs := scheduling.NewNodeSelectorRequirementsWithMinValues(candidate.Requirements()...)
+		compatible := lo.Filter(instanceTypes, func(it *cloudprovider.InstanceType, _ int) bool {
+			return len(it.Offerings.Available()) > 0 && reqs.IsCompatible(it.Requirements, scheduling.AllowUndefinedWellKnownLabels)
+		})
+		if len(compatible) < lo.FromPtrOr(req.MinValues, 1) || lo.EveryBy(compatible, func(it *cloudprovider.InstanceType) bool { return lo.Contains(req.Values, it.Name) }) {
+			continue
+		}
+		nodePool.Status.WidenedRequirements = append(nodePool.Status.WidenedRequirements, req.Key)
+		widened = append(widened, req.Key)
+	}
+	return widened
+}
+
+func (c *Controller) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodepool.provisioningfailure").
+		For(&v1.NodePool{}, builder.WithPredicates(nodepoolutils.IsManagedPredicateFuncs(c.cloudProvider))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: utilscontroller.LinearScaleReconciles(utilscontroller.CPUCount(ctx), 10, 1000)}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,46 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioningfailure
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/events"
+)
+
+func WidenedEvent(nodePool *v1.NodePool, keys []string, failures int64) events.Event {
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           corev1.EventTypeNormal,
+		Reason:         events.ProvisioningRequirementsWidened,
+		Message:        fmt.Sprintf("Widened requirements %v after %d consecutive provisioning failures", keys, failures),
+		DedupeValues:   append([]string{string(nodePool.UID)}, keys...),
+	}
+}
+
+func EscalatedEvent(nodePool, fallback *v1.NodePool, failures int64) events.Event {
+	return events.Event{
+		InvolvedObject: nodePool,
+		Type:           corev1.EventTypeWarning,
+		Reason:         events.ProvisioningEscalated,
+		Message:        fmt.Sprintf("Raised weight of fallback NodePool %s to %d after %d consecutive provisioning failures", fallback.Name, fallback.Weight(), failures),
+		DedupeValues:   []string{string(nodePool.UID), fallback.Name, fmt.Sprint(fallback.Weight())},
+	}
+}
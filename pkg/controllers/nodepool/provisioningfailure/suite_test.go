@@ -0,0 +1,241 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioningfailure_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/karpenter/pkg/apis"
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
+	"sigs.k8s.io/karpenter/pkg/controllers/nodepool/provisioningfailure"
+	"sigs.k8s.io/karpenter/pkg/events"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var (
+	controller    *provisioningfailure.Controller
+	ctx           context.Context
+	env           *test.Environment
+	cloudProvider *fake.CloudProvider
+	recorder      *test.EventRecorder
+	nodePool      *v1.NodePool
+)
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ProvisioningFailure")
+}
+
+var _ = BeforeSuite(func() {
+	cloudProvider = fake.NewCloudProvider()
+	recorder = test.NewEventRecorder()
+	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...))
+	controller = provisioningfailure.NewController(env.Client, cloudProvider, recorder)
+})
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ProvisioningFailureThreshold: lo.ToPtr(3)}))
+	recorder.Reset()
+})
+
+var _ = AfterEach(func() {
+	ExpectCleanedUp(ctx, env.Client)
+})
+
+var _ = AfterSuite(func() {
+	Expect(env.Stop()).To(Succeed(), "Failed to stop environment")
+})
+
+var _ = Describe("ProvisioningFailure", func() {
+	BeforeEach(func() {
+		nodePool = test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Template: v1.NodeClaimTemplate{
+					Spec: v1.NodeClaimTemplateSpec{
+						Requirements: []v1.NodeSelectorRequirementWithMinValues{
+							{
+								NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+									Key:      corev1.LabelInstanceTypeStable,
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{"small-instance-type", "default-instance-type"},
+								},
+								MinValues: lo.ToPtr(2),
+							},
+							{
+								NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+									Key:      v1.CapacityTypeLabelKey,
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{v1.CapacityTypeOnDemand},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	})
+	It("should not act on a NodePool below the failure threshold", func() {
+		nodePool.Status.ConsecutiveProvisioningFailures = 2
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Spec.Template.Spec.Requirements[0].Operator).To(Equal(corev1.NodeSelectorOpIn))
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 2))
+		Expect(recorder.Calls(events.ProvisioningRequirementsWidened)).To(Equal(0))
+	})
+	It("should not act when the failure threshold is disabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ProvisioningFailureThreshold: lo.ToPtr(0)}))
+		nodePool.Status.ConsecutiveProvisioningFailures = 10
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Spec.Template.Spec.Requirements[0].Operator).To(Equal(corev1.NodeSelectorOpIn))
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 10))
+	})
+	It("should widen instance-type requirements in the status while preserving minValues", func() {
+		nodePool.Status.ConsecutiveProvisioningFailures = 3
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Spec.Template.Spec.Requirements[0].Operator).To(Equal(corev1.NodeSelectorOpIn))
+		Expect(nodePool.Status.WidenedRequirements).To(ConsistOf(corev1.LabelInstanceTypeStable))
+		Expect(nodePool.Requirements()).To(ContainElements(
+			v1.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+					Key:      corev1.LabelInstanceTypeStable,
+					Operator: corev1.NodeSelectorOpExists,
+				},
+				MinValues: lo.ToPtr(2),
+			},
+			v1.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+					Key:      v1.CapacityTypeLabelKey,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{v1.CapacityTypeOnDemand},
+				},
+			},
+		))
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 0))
+		Expect(recorder.Calls(events.ProvisioningRequirementsWidened)).To(Equal(1))
+	})
+	It("should not widen instance-type requirements when minValues can't be satisfied", func() {
+		nodePool.Spec.Template.Spec.Requirements[0].MinValues = lo.ToPtr(50)
+		nodePool.Status.ConsecutiveProvisioningFailures = 3
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.WidenedRequirements).To(BeEmpty())
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 3))
+		Expect(recorder.Calls(events.ProvisioningRequirementsWidened)).To(Equal(0))
+	})
+	It("should escalate to the fallback NodePool when requirements can't be widened", func() {
+		nodePool.Spec.Template.Spec.Requirements = nodePool.Spec.Template.Spec.Requirements[1:]
+		nodePool.Spec.Weight = lo.ToPtr[int32](50)
+		fallback := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Weight: lo.ToPtr[int32](10)}})
+		nodePool.Annotations = map[string]string{v1.ProvisioningFallbackNodePoolAnnotationKey: fallback.Name}
+		nodePool.Status.ConsecutiveProvisioningFailures = 5
+		ExpectApplied(ctx, env.Client, nodePool, fallback)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		fallback = ExpectExists(ctx, env.Client, fallback)
+		Expect(lo.FromPtr(fallback.Spec.Weight)).To(BeNumerically("==", 10))
+		Expect(lo.FromPtr(fallback.Status.EscalatedWeight)).To(BeNumerically("==", 51))
+		Expect(fallback.Weight()).To(BeNumerically("==", 51))
+		Expect(fallback.Status.EscalatedBy).To(Equal(nodePool.Name))
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 0))
+		Expect(recorder.Calls(events.ProvisioningEscalated)).To(Equal(1))
+	})
+	It("should not escalate to a fallback NodePool that is already preferred", func() {
+		nodePool.Spec.Template.Spec.Requirements = nodePool.Spec.Template.Spec.Requirements[1:]
+		nodePool.Spec.Weight = lo.ToPtr[int32](50)
+		fallback := test.NodePool(v1.NodePool{Spec: v1.NodePoolSpec{Weight: lo.ToPtr[int32](80)}})
+		nodePool.Annotations = map[string]string{v1.ProvisioningFallbackNodePoolAnnotationKey: fallback.Name}
+		nodePool.Status.ConsecutiveProvisioningFailures = 5
+		ExpectApplied(ctx, env.Client, nodePool, fallback)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		fallback = ExpectExists(ctx, env.Client, fallback)
+		Expect(lo.FromPtr(fallback.Spec.Weight)).To(BeNumerically("==", 80))
+		Expect(fallback.Status.EscalatedWeight).To(BeNil())
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 5))
+		Expect(recorder.Calls(events.ProvisioningEscalated)).To(Equal(0))
+	})
+	It("should not escalate when the fallback NodePool doesn't exist", func() {
+		nodePool.Spec.Template.Spec.Requirements = nodePool.Spec.Template.Spec.Requirements[1:]
+		nodePool.Annotations = map[string]string{v1.ProvisioningFallbackNodePoolAnnotationKey: "missing"}
+		nodePool.Status.ConsecutiveProvisioningFailures = 5
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, controller, nodePool)
+
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.Status.ConsecutiveProvisioningFailures).To(BeNumerically("==", 5))
+	})
+})
+
+var _ = Describe("Widen", func() {
+	It("should only widen instance-type requirements restricted to a set of values", func() {
+		nodePool := test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Template: v1.NodeClaimTemplate{
+					Spec: v1.NodeClaimTemplateSpec{
+						Requirements: []v1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"large-instance-type"}}},
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-zone-1"}}},
+						},
+					},
+				},
+			},
+		})
+		Expect(provisioningfailure.Widen(nodePool, fake.InstanceTypes(5))).To(BeEmpty())
+		Expect(nodePool.Status.WidenedRequirements).To(BeEmpty())
+	})
+	It("should not widen requirements that already allow every available instance type", func() {
+		instanceTypes := fake.InstanceTypes(2)
+		nodePool := test.NodePool(v1.NodePool{
+			Spec: v1.NodePoolSpec{
+				Template: v1.NodeClaimTemplate{
+					Spec: v1.NodeClaimTemplateSpec{
+						Requirements: []v1.NodeSelectorRequirementWithMinValues{
+							{NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelInstanceTypeStable, Operator: corev1.NodeSelectorOpIn, Values: []string{instanceTypes[0].Name, instanceTypes[1].Name}}},
+						},
+					},
+				},
+			},
+		})
+		Expect(provisioningfailure.Widen(nodePool, instanceTypes)).To(BeEmpty())
+		Expect(provisioningfailure.Widen(nodePool, fake.InstanceTypes(3))).To(ConsistOf(corev1.LabelInstanceTypeStable))
+		Expect(nodePool.Status.WidenedRequirements).To(ConsistOf(corev1.LabelInstanceTypeStable))
+	})
+})
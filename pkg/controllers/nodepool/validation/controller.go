@@ -59,6 +59,12 @@ func (c *Controller) Reconcile(ctx context.Context, nodePool *v1.NodePool) (reco
 	} else {
 		nodePool.StatusConditions().SetTrue(v1.ConditionTypeValidationSucceeded)
 	}
+	// Invalid budgets only block disruption, so they don't affect the NodePool's readiness
+	if err := nodePool.Spec.Disruption.ValidateBudgetTimeZones(); err != nil {
+		nodePool.StatusConditions().SetFalse(v1.ConditionTypeDisruptionBudgetsValid, "InvalidTimeZone", err.Error())
+	} else {
+		nodePool.StatusConditions().SetTrue(v1.ConditionTypeDisruptionBudgetsValid)
+	}
 	if !equality.Semantic.DeepEqual(stored, nodePool) {
 		// We use client.MergeFromWithOptimisticLock because patching a list with a JSON merge patch
 		// can cause races due to the fact that it fully replaces the list on a change
@@ -21,12 +21,15 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Pallinder/go-randomdata"
 	"github.com/awslabs/operatorpkg/status"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -112,6 +115,34 @@ var _ = Describe("Counter", func() {
 		Expect(nodePool.StatusConditions().IsTrue(status.ConditionReady)).To(BeTrue())
 		Expect(nodePool.StatusConditions().IsTrue(v1.ConditionTypeValidationSucceeded)).To(BeTrue())
 	})
+	It("should set the DisruptionBudgetsValid status condition to false if a budget's time zone can't be loaded", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{
+			Nodes:    "10",
+			Schedule: lo.ToPtr("0 0 * * 6"),
+			Duration: &metav1.Duration{Duration: 48 * time.Hour},
+			TimeZone: lo.ToPtr("Not/AZone"),
+		}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolValidationController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		nodePool.StatusConditions().SetTrue(v1.ConditionTypeNodeClassReady)
+		Expect(nodePool.StatusConditions().IsTrue(status.ConditionReady)).To(BeTrue())
+		condition := nodePool.StatusConditions().Get(v1.ConditionTypeDisruptionBudgetsValid)
+		Expect(condition.IsFalse()).To(BeTrue())
+		Expect(condition.Reason).To(Equal("InvalidTimeZone"))
+	})
+	It("should set the DisruptionBudgetsValid status condition to true if every budget's time zone can be loaded", func() {
+		nodePool.Spec.Disruption.Budgets = []v1.Budget{{
+			Nodes:    "10",
+			Schedule: lo.ToPtr("0 0 * * 6"),
+			Duration: &metav1.Duration{Duration: 48 * time.Hour},
+			TimeZone: lo.ToPtr("America/New_York"),
+		}}
+		ExpectApplied(ctx, env.Client, nodePool)
+		ExpectObjectReconciled(ctx, env.Client, nodePoolValidationController, nodePool)
+		nodePool = ExpectExists(ctx, env.Client, nodePool)
+		Expect(nodePool.StatusConditions().IsTrue(v1.ConditionTypeDisruptionBudgetsValid)).To(BeTrue())
+	})
 	It("should ignore NodePools which aren't managed by this instance of Karpenter", func() {
 		nodePool.Spec.Template.Spec.NodeClassRef = &v1.NodeClassReference{
 			Group: "karpenter.test.sh",
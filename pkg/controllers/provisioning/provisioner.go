@@ -34,6 +34,7 @@ import (
 	"go.uber.org/multierr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
@@ -56,8 +57,11 @@ import (
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/utils/daemonset"
 	nodeutils "sigs.k8s.io/karpenter/pkg/utils/node"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
 	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
 	"sigs.k8s.io/karpenter/pkg/utils/pretty"
+	"sigs.k8s.io/karpenter/pkg/utils/readonly"
+	"sigs.k8s.io/karpenter/pkg/utils/shard"
 )
 
 // LaunchOptions are the set of options that can be used to trigger certain
@@ -65,6 +69,7 @@ import (
 type LaunchOptions struct {
 	RecordPodNomination bool
 	Reason              string
+	Annotations         map[string]string
 }
 
 // RecordPodNomination causes nominate pod events to be recorded against the node.
@@ -76,6 +81,11 @@ func WithReason(reason string) func(*LaunchOptions) {
 	return func(o *LaunchOptions) { o.Reason = reason }
 }
 
+// WithAnnotations adds the annotations to the created NodeClaims
+func WithAnnotations(annotations map[string]string) func(*LaunchOptions) {
+	return func(o *LaunchOptions) { o.Annotations = lo.Assign(o.Annotations, annotations) }
+}
+
 // Provisioner waits for enqueued pods, batches them, creates capacity and binds the pods to the capacity.
 type Provisioner struct {
 	cloudProvider  cloudprovider.CloudProvider
@@ -138,6 +148,11 @@ func (p *Provisioner) Reconcile(ctx context.Context) (result reconciler.Result,
 	if len(results.NewNodeClaims) == 0 {
 		return reconciler.Result{RequeueAfter: singleton.RequeueImmediately}, nil
 	}
+	// In read-only mode the scheduling results are still computed and reported, but no NodeClaims are created
+	if readonly.Enabled(ctx) {
+		log.FromContext(ctx).WithValues("nodeclaims", len(results.NewNodeClaims)).Info("skipping nodeclaim creation, read-only mode is enabled")
+		return reconciler.Result{RequeueAfter: singleton.RequeueImmediately}, nil
+	}
 	if _, err = p.CreateNodeClaims(ctx, results.NewNodeClaims, WithReason(metrics.ProvisionedReason), RecordPodNomination); err != nil {
 		return reconciler.Result{}, err
 	}
@@ -229,6 +244,22 @@ func (p *Provisioner) consolidationWarnings(ctx context.Context, pods []*corev1.
 	}
 }
 
+// inShard returns true if the pod is provisioned by this replica's shard. Pods that require a single NodePool are
+// provisioned by the shard of that NodePool. Every other pod may schedule to the NodePools of any shard, so it's
+// partitioned by its owner instead, which keeps the pods of a workload in the same scheduling simulation.
+func inShard(ctx context.Context, pod *corev1.Pod) bool {
+	if options.FromContext(ctx).ShardCount <= 1 {
+		return true
+	}
+	if nodePool := scheduling.NewStrictPodRequirements(pod).Get(v1.NodePoolLabelKey); nodePool.Operator() == corev1.NodeSelectorOpIn && nodePool.Len() == 1 {
+		return nodepoolutils.InShard(ctx, nodePool.Any())
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return shard.Owns(ctx, string(owner.UID))
+	}
+	return shard.Owns(ctx, string(pod.UID))
+}
+
 var ErrNodePoolsNotFound = errors.New("no nodepools found")
 
 //nolint:gocyclo
@@ -287,6 +318,9 @@ func (p *Provisioner) NewScheduler(
 	if err != nil {
 		return nil, fmt.Errorf("injecting volume topology requirements, %w", err)
 	}
+	if err = p.injectDefaultRequests(ctx, pods); err != nil {
+		return nil, fmt.Errorf("injecting default requests, %w", err)
+	}
 
 	// Calculate cluster topology, if a context error occurs, it is wrapped and returned
 	topology, err := scheduler.NewTopology(ctx, p.kubeClient, p.cluster, stateNodes, nodePools, instanceTypes, pods, opts...)
@@ -325,12 +359,14 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 	// We do this after getting the pending pods so that we undershoot if pods are
 	// actively migrating from a node that is being deleted
 	// NOTE: The assumption is that these nodes are cordoned and no additional pods will schedule to them
-	deletingNodePods, err := nodes.Deleting().CurrentlyReschedulablePods(ctx, p.kubeClient)
+	deletingNodePods, err := nodes.Deleting().CurrentlyReschedulablePods(ctx, p.kubeClient, p.clock)
 	if err != nil {
 		return scheduler.Results{}, err
 	}
 
 	pods := append(pendingPods, deletingNodePods...)
+	// Leave the pods of other shards to their replicas
+	pods = lo.Filter(pods, func(pod *corev1.Pod, _ int) bool { return inShard(ctx, pod) })
 	// nothing to schedule, so just return success
 	if len(pods) == 0 {
 		return scheduler.Results{}, nil
@@ -341,6 +377,7 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 		scheduler.DisableReservedCapacityFallback,
 		scheduler.NumConcurrentReconciles(int(math.Ceil(float64(options.FromContext(ctx).CPURequests) / 1000.0))),
 		scheduler.MinValuesPolicy(options.FromContext(ctx).MinValuesPolicy),
+		scheduler.WorkloadAffinityWeight(options.FromContext(ctx).WorkloadAffinityWeight),
 	}
 	if options.FromContext(ctx).PreferencePolicy == options.PreferencePolicyIgnore {
 		opts = append(opts, scheduler.IgnorePreferences)
@@ -406,7 +443,7 @@ func (p *Provisioner) Schedule(ctx context.Context) (scheduler.Results, error) {
 
 func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts ...option.Function[LaunchOptions]) (string, error) {
 	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("NodePool", klog.KRef("", n.NodePoolName)))
-	options := option.Resolve(opts...)
+	launchOptions := option.Resolve(opts...)
 	latest := &v1.NodePool{}
 	if err := p.kubeClient.Get(ctx, types.NamespacedName{Name: n.NodePoolName}, latest); err != nil {
 		return "", fmt.Errorf("getting current resource usage, %w", err)
@@ -415,6 +452,15 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 		return "", err
 	}
 	nodeClaim := n.ToNodeClaim()
+	if len(launchOptions.Annotations) > 0 {
+		nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, launchOptions.Annotations)
+	}
+	// Record the pods that the NodeClaim is being created for so that launch failures can be surfaced on the pods
+	if options.FromContext(ctx).FeatureGates.NominatedPods {
+		if err := nodeclaimutils.SetNominatedPods(nodeClaim, n.Pods); err != nil {
+			log.FromContext(ctx).Error(err, "failed recording nominated pods")
+		}
+	}
 
 	if err := p.kubeClient.Create(ctx, nodeClaim); err != nil {
 		return "", err
@@ -433,14 +479,14 @@ func (p *Provisioner) Create(ctx context.Context, n *scheduler.NodeClaim, opts .
 
 	if val, ok := nodeClaim.Annotations[v1.NodeClaimMinValuesRelaxedAnnotationKey]; ok {
 		metrics.NodeClaimsCreatedTotal.Inc(map[string]string{
-			metrics.ReasonLabel:           options.Reason,
+			metrics.ReasonLabel:           launchOptions.Reason,
 			metrics.NodePoolLabel:         nodeClaim.Labels[v1.NodePoolLabelKey],
 			metrics.MinValuesRelaxedLabel: val,
 		})
 	} else {
 		// If annotation is missing for any reason, assume that min values wasn't relaxed.
 		metrics.NodeClaimsCreatedTotal.Inc(map[string]string{
-			metrics.ReasonLabel:           options.Reason,
+			metrics.ReasonLabel:           launchOptions.Reason,
 			metrics.NodePoolLabel:         nodeClaim.Labels[v1.NodePoolLabelKey],
 			metrics.MinValuesRelaxedLabel: "false",
 		})
@@ -507,6 +553,7 @@ func (p *Provisioner) Validate(ctx context.Context, pod *corev1.Pod) error {
 		validateNodeSelector(ctx, pod),
 		validateAffinity(ctx, pod),
 		p.volumeTopology.ValidatePersistentVolumeClaims(ctx, pod),
+		validateRequests(ctx, pod),
 	)
 }
 
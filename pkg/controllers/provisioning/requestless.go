@@ -0,0 +1,114 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
+)
+
+var RequestlessPodError = fmt.Errorf("pod doesn't request cpu or memory")
+
+// isRequestless returns true if none of the pod's containers request cpu or memory
+func isRequestless(pod *corev1.Pod) bool {
+	return lo.NoneBy(append(slices.Clone(pod.Spec.InitContainers), pod.Spec.Containers...), func(c corev1.Container) bool {
+		_, cpu := c.Resources.Requests[corev1.ResourceCPU]
+		_, memory := c.Resources.Requests[corev1.ResourceMemory]
+		return cpu || memory
+	})
+}
+
+// validateRequests rejects pods without requests when the requestless pod policy is Reject
+func validateRequests(ctx context.Context, pod *corev1.Pod) error {
+	if options.FromContext(ctx).RequestlessPodPolicy != options.RequestlessPodPolicyReject || !isRequestless(pod) {
+		return nil
+	}
+	return RequestlessPodError
+}
+
+// injectDefaultRequests assumes default requests for the containers of pods without requests according to the
+// requestless pod policy, so that the pods are packed onto appropriately sized nodes.
+func (p *Provisioner) injectDefaultRequests(ctx context.Context, pods []*corev1.Pod) error {
+	policy := options.FromContext(ctx).RequestlessPodPolicy
+	if policy != options.RequestlessPodPolicyDefault && policy != options.RequestlessPodPolicyLimitRange {
+		return nil
+	}
+	defaults := map[string]corev1.ResourceList{}
+	for _, pod := range pods {
+		if !isRequestless(pod) {
+			continue
+		}
+		requests, ok := defaults[pod.Namespace]
+		if !ok {
+			var err error
+			if requests, err = p.defaultRequests(ctx, policy, pod.Namespace); err != nil {
+				return err
+			}
+			defaults[pod.Namespace] = requests
+		}
+		for i := range pod.Spec.Containers {
+			pod.Spec.Containers[i].Resources.Requests = lo.Assign(corev1.ResourceList{}, requests, pod.Spec.Containers[i].Resources.Requests)
+		}
+	}
+	return nil
+}
+
+// defaultRequests returns the requests assumed for each container of a pod without requests in the namespace
+func (p *Provisioner) defaultRequests(ctx context.Context, policy options.RequestlessPodPolicy, namespace string) (corev1.ResourceList, error) {
+	switch policy {
+	case options.RequestlessPodPolicyDefault:
+		ns := &corev1.Namespace{}
+		if err := p.kubeClient.Get(ctx, types.NamespacedName{Name: namespace}, ns); client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("getting namespace, %w", err)
+		}
+		if raw, ok := ns.Annotations[v1.DefaultPodRequestsAnnotationKey]; ok {
+			requests, err := resources.Parse(raw)
+			if err == nil {
+				return requests, nil
+			}
+			log.FromContext(ctx).WithValues("Namespace", namespace).Error(err, fmt.Sprintf("ignoring invalid %s annotation", v1.DefaultPodRequestsAnnotationKey))
+		}
+	case options.RequestlessPodPolicyLimitRange:
+		limitRanges := &corev1.LimitRangeList{}
+		if err := p.kubeClient.List(ctx, limitRanges, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("listing limit ranges, %w", err)
+		}
+		for _, lr := range limitRanges.Items {
+			for _, item := range lr.Spec.Limits {
+				if item.Type != corev1.LimitTypeContainer {
+					continue
+				}
+				// Like the LimitRanger admission plugin, the default limits are used as requests if no default requests are set
+				if requests := lo.Ternary(len(item.DefaultRequest) > 0, item.DefaultRequest, item.Default); len(requests) > 0 {
+					return requests, nil
+				}
+			}
+		}
+	}
+	return options.FromContext(ctx).RequestlessPodDefaultRequests, nil
+}
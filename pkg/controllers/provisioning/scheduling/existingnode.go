@@ -20,6 +20,8 @@ import (
 	"fmt"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
@@ -35,10 +37,14 @@ type ExistingNode struct {
 	topology           *Topology
 	remainingResources v1.ResourceList
 	requirements       scheduling.Requirements
+	hostPortUsage      *scheduling.HostPortUsage
+	volumeUsage        *scheduling.VolumeUsage
+	workloadPods       map[types.UID]int // (workload UID) -> number of the workload's pods on the node, only tracked with workload affinity
 }
 
 func NewExistingNode(n *state.StateNode, topology *Topology, taints []v1.Taint, daemonResources v1.ResourceList) *ExistingNode {
-	// The state node passed in here must be a deep copy from cluster state as we modify it
+	// The state node passed in here isn't modified, so it can be a read-only snapshot of cluster state. The host port
+	// and volume usage that scheduling adds to are copied from it instead.
 	// the remaining daemonResources to schedule are the total daemonResources minus what has already scheduled
 	resources.SubtractFrom(daemonResources, n.DaemonSetRequests())
 	// If unexpected daemonset pods schedule to the node due to labels appearing on the node which cause the
@@ -58,6 +64,8 @@ func NewExistingNode(n *state.StateNode, topology *Topology, taints []v1.Taint,
 		topology:           topology,
 		remainingResources: resources.Subtract(available, daemonResources),
 		requirements:       scheduling.NewLabelRequirements(n.Labels()),
+		hostPortUsage:      n.HostPortUsage().DeepCopy(),
+		volumeUsage:        n.VolumeUsage().DeepCopy(),
 	}
 	node.requirements.Add(scheduling.NewRequirement(v1.LabelHostname, v1.NodeSelectorOpIn, n.HostName()))
 	topology.Register(v1.LabelHostname, n.HostName())
@@ -74,10 +82,10 @@ func (n *ExistingNode) CanAdd(pod *v1.Pod, podData *PodData, volumes scheduling.
 	}
 	// determine the host ports that will be used if the pod schedules
 	hostPorts := scheduling.GetHostPorts(pod)
-	if err = n.VolumeUsage().ExceedsLimits(volumes); err != nil {
+	if err = n.volumeUsage.ExceedsLimits(volumes); err != nil {
 		return nil, fmt.Errorf("checking volume usage, %w", err)
 	}
-	if err = n.HostPortUsage().Conflicts(pod, hostPorts); err != nil {
+	if err = n.hostPortUsage.Conflicts(pod, hostPorts); err != nil {
 		return nil, fmt.Errorf("checking host port usage, %w", err)
 	}
 	// check resource requests first since that's a pretty likely reason the pod won't schedule on an in-flight
@@ -114,6 +122,17 @@ func (n *ExistingNode) Add(pod *v1.Pod, podData *PodData, nodeRequirements sched
 	resources.SubtractFrom(n.remainingResources, podData.Requests)
 	n.requirements = nodeRequirements
 	n.topology.Record(pod, n.cachedTaints, nodeRequirements)
-	n.HostPortUsage().Add(pod, scheduling.GetHostPorts(pod))
-	n.VolumeUsage().Add(pod, volumes)
+	n.hostPortUsage.Add(pod, scheduling.GetHostPorts(pod))
+	n.volumeUsage.Add(pod, volumes)
+	if key := workloadKey(pod); key != "" && n.workloadPods != nil {
+		n.workloadPods[key]++
+	}
+}
+
+// workloadKey identifies the workload of a pod by the UID of its controller, e.g. the ReplicaSet of a Deployment's pods
+func workloadKey(pod *v1.Pod) types.UID {
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return owner.UID
+	}
+	return ""
 }
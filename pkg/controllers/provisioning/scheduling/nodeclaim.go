@@ -216,7 +216,7 @@ func (n *NodeClaim) offeringsToReserve(
 			}
 			// Track every incompatible reserved offering for release. Since releasing a reservation is a no-op when there is no
 			// reservation for the given host, there's no need to check that a reservation actually exists for the offering.
-			if !nodeClaimRequirements.IsCompatible(o.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
+			if !o.IsCompatible(nodeClaimRequirements) {
 				continue
 			}
 			hasCompatibleOffering = true
@@ -399,7 +399,7 @@ func filterInstanceTypesByRequirements(instanceTypes []*cloudprovider.InstanceTy
 		// which have to be garbage collected and slow down Karpenter's scheduling algorithm
 		itHasOffering := false
 		for _, of := range it.Offerings {
-			if of.Available && requirements.IsCompatible(of.Requirements, scheduling.AllowUndefinedWellKnownLabels) {
+			if of.Available && of.IsCompatible(requirements) {
 				itHasOffering = true
 				break
 			}
@@ -59,14 +59,18 @@ func NewNodeClaimTemplate(nodePool *v1.NodePool) *NodeClaimTemplate {
 		NodeClaim:         *nodePool.Spec.Template.ToNodeClaim(),
 		NodePoolName:      nodePool.Name,
 		NodePoolUUID:      nodePool.UID,
-		NodePoolWeight:    lo.FromPtr(nodePool.Spec.Weight),
+		NodePoolWeight:    nodePool.Weight(),
 		Requirements:      scheduling.NewRequirements(),
 		IsStaticNodeClaim: nodePool.Spec.Replicas != nil,
 	}
+	nct.Spec.Requirements = nodePool.Requirements()
 	nct.Annotations = lo.Assign(nct.Annotations, map[string]string{
 		v1.NodePoolHashAnnotationKey:        nodePool.Hash(),
 		v1.NodePoolHashVersionAnnotationKey: v1.NodePoolHashVersion,
 	})
+	if nodePool.Spec.RolloutTrigger != "" {
+		nct.Annotations[v1.NodePoolRolloutTriggerAnnotationKey] = nodePool.Spec.RolloutTrigger
+	}
 	nct.Labels = lo.Assign(nct.Labels, map[string]string{
 		v1.NodePoolLabelKey: nodePool.Name,
 		v1.NodeClassLabelKey(nodePool.Spec.Template.Spec.NodeClassRef.GroupKind()): nodePool.Spec.Template.Spec.NodeClassRef.Name,
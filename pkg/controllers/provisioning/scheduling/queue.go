@@ -33,7 +33,12 @@ type Queue struct {
 	lastLen map[types.UID]int
 }
 
-// NewQueue constructs a new queue given the input pods, sorting them to optimize for bin-packing into nodes.
+// agingThreshold is the number of consecutive scheduling rounds that a pod must lose before it's boosted ahead of the
+// bin-packing order, preventing old pending pods from starving behind a constant stream of new pods
+const agingThreshold = 3
+
+// NewQueue constructs a new queue given the input pods, sorting them to optimize for bin-packing into nodes. Pods that
+// have repeatedly lost out to other pods in previous scheduling rounds are sorted first.
 func NewQueue(pods []*v1.Pod, podData map[types.UID]*PodData) *Queue {
 	sort.Slice(pods, byCPUAndMemoryDescending(pods, podData))
 	return &Queue{
@@ -74,6 +79,11 @@ func byCPUAndMemoryDescending(pods []*v1.Pod, podData map[types.UID]*PodData) fu
 		lhsPod := pods[i]
 		rhsPod := pods[j]
 
+		// Pods that have lost more rounds are scheduled first so that they can claim any remaining NodePool limits
+		if lhsBoost, rhsBoost := agingBoost(podData[lhsPod.UID]), agingBoost(podData[rhsPod.UID]); lhsBoost != rhsBoost {
+			return lhsBoost > rhsBoost
+		}
+
 		lhs := podData[lhsPod.UID].Requests
 		rhs := podData[rhsPod.UID].Requests
 
@@ -106,3 +116,11 @@ func byCPUAndMemoryDescending(pods []*v1.Pod, podData map[types.UID]*PodData) fu
 		return lhsPod.UID < rhsPod.UID
 	}
 }
+
+// agingBoost returns the priority boost of a pod, which is zero until the pod has lost agingThreshold rounds
+func agingBoost(pd *PodData) int {
+	if pd.SchedulingLosses < agingThreshold {
+		return 0
+	}
+	return pd.SchedulingLosses
+}
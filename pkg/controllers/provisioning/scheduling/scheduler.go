@@ -89,6 +89,7 @@ type options struct {
 	preferencePolicy        PreferencePolicy
 	minValuesPolicy         karpopts.MinValuesPolicy
 	numConcurrentReconciles int
+	workloadAffinityWeight  int
 }
 
 type Options = option.Function[options]
@@ -113,6 +114,14 @@ var MinValuesPolicy = func(policy karpopts.MinValuesPolicy) func(*options) {
 	}
 }
 
+// WorkloadAffinityWeight biases the scheduler towards existing nodes that already run pods of the same workload. Each
+// pod of the workload on a node moves the node weight places forward in the order that existing nodes are tried in.
+var WorkloadAffinityWeight = func(weight int) func(*options) {
+	return func(opts *options) {
+		opts.workloadAffinityWeight = weight
+	}
+}
+
 func NewScheduler(
 	ctx context.Context,
 	kubeClient client.Client,
@@ -171,11 +180,13 @@ func NewScheduler(
 			return np.Name, corev1.ResourceList(np.Spec.Limits)
 		}),
 		clock:                   clock,
+		zoneSpread:              newZoneSpread(nodePools, stateNodes),
 		reservationManager:      NewReservationManager(instanceTypes),
 		reservedOfferingMode:    option.Resolve(opts...).reservedOfferingMode,
 		preferencePolicy:        option.Resolve(opts...).preferencePolicy,
 		minValuesPolicy:         minValuesPolicy,
 		numConcurrentReconciles: lo.Ternary(option.Resolve(opts...).numConcurrentReconciles > 0, option.Resolve(opts...).numConcurrentReconciles, 1),
+		workloadAffinityWeight:  option.Resolve(opts...).workloadAffinityWeight,
 	}
 	s.calculateExistingNodeClaims(ctx, stateNodes, daemonSetPods)
 	return s
@@ -186,6 +197,9 @@ type PodData struct {
 	Requirements             scheduling.Requirements
 	StrictRequirements       scheduling.Requirements
 	HasResourceClaimRequests bool
+	// SchedulingLosses is the number of consecutive scheduling rounds where the pod failed to schedule while other pods
+	// were able to schedule
+	SchedulingLosses int
 }
 
 type Scheduler struct {
@@ -203,11 +217,13 @@ type Scheduler struct {
 	recorder                events.Recorder
 	kubeClient              client.Client
 	clock                   clock.Clock
+	zoneSpread              *zoneSpread
 	reservationManager      *ReservationManager
 	reservedOfferingMode    ReservedOfferingMode
 	preferencePolicy        PreferencePolicy
 	minValuesPolicy         karpopts.MinValuesPolicy
 	numConcurrentReconciles int
+	workloadAffinityWeight  int
 }
 
 // DRAError indicates a pod will not be attempted to be scheduled because it has Dynamic Resource Allocation requirements
@@ -324,16 +340,19 @@ func (r Results) ExistingNodeToPodMapping() map[string][]*corev1.Pod {
 // We don't care if a pod was pending before consolidation and will still be pending after. It may be a pod that we can't
 // schedule at all and don't want it to block consolidation.
 func (r Results) AllNonPendingPodsScheduled() bool {
-	return len(lo.OmitBy(r.PodErrors, func(p *corev1.Pod, err error) bool {
+	return len(r.NonPendingPodErrors()) == 0
+}
+
+// NonPendingPodErrors returns the scheduling errors of the pods that weren't pending before the simulation
+func (r Results) NonPendingPodErrors() map[*corev1.Pod]error {
+	return lo.OmitBy(r.PodErrors, func(p *corev1.Pod, err error) bool {
 		return pod.IsProvisionable(p)
-	})) == 0
+	})
 }
 
 // NonPendingPodSchedulingErrors creates a string that describes why pods wouldn't schedule that is suitable for presentation
 func (r Results) NonPendingPodSchedulingErrors() string {
-	errs := lo.OmitBy(r.PodErrors, func(p *corev1.Pod, err error) bool {
-		return pod.IsProvisionable(p)
-	})
+	errs := r.NonPendingPodErrors()
 	if len(errs) == 0 {
 		return "No Pod Scheduling Errors"
 	}
@@ -420,6 +439,7 @@ func (s *Scheduler) Solve(ctx context.Context, pods []*corev1.Pod) (Results, err
 		}
 	}
 	UnfinishedWorkSeconds.Delete(map[string]string{ControllerLabel: injection.GetControllerName(ctx), schedulingIDLabel: string(s.uuid)})
+	s.zoneSpread.apply(s.newNodeClaims)
 	for _, m := range s.newNodeClaims {
 		m.FinalizeScheduling()
 	}
@@ -482,6 +502,7 @@ func (s *Scheduler) updateCachedPodData(p *corev1.Pod) {
 		Requirements:             requirements,
 		StrictRequirements:       strictRequirements,
 		HasResourceClaimRequests: pod.HasDRARequirements(p),
+		SchedulingLosses:         s.cluster.PodSchedulingLosses(client.ObjectKeyFromObject(p)),
 	}
 }
 
@@ -524,20 +545,33 @@ func (s *Scheduler) addToExistingNode(ctx context.Context, pod *corev1.Pod) erro
 	if err != nil {
 		return err
 	}
+	// With workload affinity, nodes that run pods of the pod's workload are moved forward in the order, so we have
+	// to check every node rather than stopping at the first one that the pod fits on
+	workload := workloadKey(pod)
+	affinity := s.workloadAffinityWeight > 0 && workload != ""
+	rank := func(i int) int {
+		if !affinity {
+			return i
+		}
+		return i - s.workloadAffinityWeight*s.existingNodes[i].workloadPods[workload]
+	}
+	bestRank := math.MaxInt
 	parallelizeUntil(s.numConcurrentReconciles, len(s.existingNodes), func(i int) bool {
 		r, err := s.existingNodes[i].CanAdd(pod, s.cachedPodData[pod.UID], volumes)
 		if err == nil {
 			mu.Lock()
 			defer mu.Unlock()
 
-			// Ensure that we always take an earlier successful schedule to keep consistent ordering
-			if i >= idx {
-				return false
+			// Ensure that we always take the best ranked successful schedule, breaking ties with the earlier
+			// schedule, to keep consistent ordering
+			if rank(i) > bestRank || (rank(i) == bestRank && i >= idx) {
+				return affinity
 			}
 			existingNode = s.existingNodes[i]
 			requirements = r
 			idx = i
-			return false
+			bestRank = rank(i)
+			return affinity
 		}
 		return true
 	})
@@ -679,12 +713,32 @@ func (s *Scheduler) calculateExistingNodeClaims(ctx context.Context, stateNodes
 	for _, node := range stateNodes {
 		taints := node.Taints()
 		daemons := s.getCompatibleDaemonPods(ctx, node, taints, daemonSetPods)
-		s.existingNodes = append(s.existingNodes, NewExistingNode(node, s.topology, taints, resources.RequestsForPods(daemons...)))
+		existingNode := NewExistingNode(node, s.topology, taints, resources.RequestsForPods(daemons...))
+		if s.workloadAffinityWeight > 0 {
+			existingNode.workloadPods = s.getWorkloadPods(ctx, node)
+		}
+		s.existingNodes = append(s.existingNodes, existingNode)
 		s.updateRemainingResources(node)
 	}
 	s.sortExistingNodes()
 }
 
+// getWorkloadPods counts the pods of each workload that are bound to the node
+func (s *Scheduler) getWorkloadPods(ctx context.Context, node *state.StateNode) map[types.UID]int {
+	workloadPods := map[types.UID]int{}
+	pods, err := node.Pods(ctx, s.kubeClient)
+	if err != nil {
+		log.FromContext(ctx).WithValues("Node", klog.KRef("", node.Name())).Error(err, "failed listing pods for workload affinity")
+		return workloadPods
+	}
+	for _, p := range pods {
+		if key := workloadKey(p); key != "" {
+			workloadPods[key]++
+		}
+	}
+	return workloadPods
+}
+
 // getCompatibleDaemonPods filters daemon pods that can schedule to the given node
 func (s *Scheduler) getCompatibleDaemonPods(ctx context.Context, node *state.StateNode, taints []corev1.Taint, daemonSetPods []*corev1.Pod) []*corev1.Pod {
 	var daemons []*corev1.Pod
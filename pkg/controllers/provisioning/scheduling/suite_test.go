@@ -1826,6 +1826,59 @@ var _ = Context("Scheduling", func() {
 			possibleInstanceType := sets.NewString(pscheduling.NewNodeSelectorRequirementsWithMinValues(cloudProvider.CreateCalls[0].Spec.Requirements...).Get(corev1.LabelInstanceTypeStable).Values()...)
 			Expect(possibleInstanceType).To(Equal(sets.NewString("small", "medium", "large")))
 		})
+		Context("Regions", func() {
+			BeforeEach(func() {
+				cloudProvider.InstanceTypes = []*cloudprovider.InstanceType{
+					fake.NewInstanceType(fake.InstanceTypeOptions{
+						Name: "default-instance-type",
+						Offerings: []*cloudprovider.Offering{
+							{
+								Available: true,
+								Requirements: pscheduling.NewLabelRequirements(map[string]string{
+									v1.CapacityTypeLabelKey:    v1.CapacityTypeOnDemand,
+									corev1.LabelTopologyRegion: "test-region-1",
+									corev1.LabelTopologyZone:   "test-region-1a",
+								}),
+								Price: 1.00,
+							},
+							{
+								Available: true,
+								Requirements: pscheduling.NewLabelRequirements(map[string]string{
+									v1.CapacityTypeLabelKey:    v1.CapacityTypeOnDemand,
+									corev1.LabelTopologyRegion: "test-region-2",
+									corev1.LabelTopologyZone:   "test-region-2a",
+								}),
+								Price: 2.00,
+							},
+						},
+					}),
+				}
+			})
+			It("should launch in the region selected by the pod", func() {
+				ExpectApplied(ctx, env.Client, nodePool)
+				pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyRegion: "test-region-2"}})
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyRegion, "test-region-2"))
+				Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-region-2a"))
+			})
+			It("should launch in the region allowed by the NodePool", func() {
+				nodePool.Spec.Template.Spec.Requirements = append(nodePool.Spec.Template.Spec.Requirements, v1.NodeSelectorRequirementWithMinValues{
+					NodeSelectorRequirement: corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyRegion, Operator: corev1.NodeSelectorOpIn, Values: []string{"test-region-2"}},
+				})
+				ExpectApplied(ctx, env.Client, nodePool)
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				node := ExpectScheduled(ctx, env.Client, pod)
+				Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyRegion, "test-region-2"))
+			})
+			It("should not schedule pods that select a region without offerings", func() {
+				ExpectApplied(ctx, env.Client, nodePool)
+				pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{corev1.LabelTopologyRegion: "test-region-3"}})
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				ExpectNotScheduled(ctx, env.Client, pod)
+			})
+		})
 	})
 
 	Describe("In-Flight Nodes", func() {
@@ -2559,6 +2612,61 @@ var _ = Context("Scheduling", func() {
 			// Expect that the scheduled node is equal to the ready node since it's initialized
 			Expect(scheduledNode.Name).To(Equal(nodes[elem].Name))
 		})
+		Context("Workload Affinity", func() {
+			var nodes []*corev1.Node
+			var workloadPodOptions test.PodOptions
+			BeforeEach(func() {
+				nodes = lo.Map([]string{"node-a", "node-b"}, func(name string, _ int) *corev1.Node {
+					return test.Node(test.NodeOptions{
+						ObjectMeta: metav1.ObjectMeta{Name: name},
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10"),
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+							corev1.ResourcePods:   resource.MustParse("110"),
+						},
+					})
+				})
+				workloadPodOptions = test.PodOptions{
+					ObjectMeta: metav1.ObjectMeta{
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion: "apps/v1",
+								Kind:       "ReplicaSet",
+								Name:       "workload",
+								UID:        "workload-uid",
+								Controller: lo.ToPtr(true),
+							},
+						},
+					},
+				}
+				ExpectApplied(ctx, env.Client, nodePool, nodes[0], nodes[1])
+				ExpectMakeNodesInitialized(ctx, env.Client, nodes[0], nodes[1])
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(nodes[0]))
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(nodes[1]))
+
+				// A pod of the workload is already running on the second node
+				running := test.Pod(workloadPodOptions)
+				ExpectApplied(ctx, env.Client, running)
+				ExpectManualBinding(ctx, env.Client, running, nodes[1])
+			})
+			It("should schedule a pod to the existing node running its workload", func() {
+				affinityCtx := options.ToContext(ctx, test.Options(test.OptionsFields{WorkloadAffinityWeight: lo.ToPtr(1)}))
+				pod := test.UnschedulablePod(workloadPodOptions)
+				ExpectProvisioned(affinityCtx, env.Client, cluster, cloudProvider, prov, pod)
+				Expect(ExpectScheduled(ctx, env.Client, pod).Name).To(Equal(nodes[1].Name))
+			})
+			It("should schedule a pod to the first existing node when workload affinity is disabled", func() {
+				pod := test.UnschedulablePod(workloadPodOptions)
+				ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+				Expect(ExpectScheduled(ctx, env.Client, pod).Name).To(Equal(nodes[0].Name))
+			})
+			It("should schedule a pod without a controller to the first existing node", func() {
+				affinityCtx := options.ToContext(ctx, test.Options(test.OptionsFields{WorkloadAffinityWeight: lo.ToPtr(1)}))
+				pod := test.UnschedulablePod()
+				ExpectProvisioned(affinityCtx, env.Client, cluster, cloudProvider, prov, pod)
+				Expect(ExpectScheduled(ctx, env.Client, pod).Name).To(Equal(nodes[0].Name))
+			})
+		})
 		It("should consider a pod incompatible with an existing node but compatible with NodePool", func() {
 			nodeClaim, node := test.NodeClaimAndNode(v1.NodeClaim{
 				Status: v1.NodeClaimStatus{
@@ -3694,6 +3802,73 @@ var _ = Context("Scheduling", func() {
 		})
 	})
 
+	Describe("Zone Spread", func() {
+		var nodes []*corev1.Node
+		BeforeEach(func() {
+			nodes = lo.Map([]string{"test-zone-1", "test-zone-2"}, func(zone string, _ int) *corev1.Node {
+				return test.Node(test.NodeOptions{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							v1.NodePoolLabelKey:      nodePool.Name,
+							corev1.LabelTopologyZone: zone,
+							v1.CapacityTypeLabelKey:  v1.CapacityTypeOnDemand,
+						},
+					},
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:  resource.MustParse("100m"),
+						corev1.ResourcePods: resource.MustParse("110"),
+					},
+				})
+			})
+		})
+		It("should launch into a zone the NodePool doesn't span when below its zone spread floor", func() {
+			nodePool.Spec.ZoneSpread = &v1.ZoneSpread{MinZones: 3, MinNodes: 1}
+			ExpectApplied(ctx, env.Client, nodePool, nodes[0], nodes[1])
+			for _, node := range nodes {
+				ExpectMakeNodesInitialized(ctx, env.Client, node)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			}
+			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-3"))
+		})
+		It("should not narrow the zone when the NodePool is below its minimum node count", func() {
+			nodePool.Spec.ZoneSpread = &v1.ZoneSpread{MinZones: 3, MinNodes: 5}
+			ExpectApplied(ctx, env.Client, nodePool, nodes[0], nodes[1])
+			for _, node := range nodes {
+				ExpectMakeNodesInitialized(ctx, env.Client, node)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			}
+			pod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+			Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+			Expect(cloudProvider.CreateCalls[0].Spec.Requirements).ToNot(ContainElement(HaveField("Key", corev1.LabelTopologyZone)))
+		})
+		It("should treat the zone spread floor as a soft constraint", func() {
+			nodePool.Spec.ZoneSpread = &v1.ZoneSpread{MinZones: 3, MinNodes: 1}
+			ExpectApplied(ctx, env.Client, nodePool, nodes[0], nodes[1])
+			for _, node := range nodes {
+				ExpectMakeNodesInitialized(ctx, env.Client, node)
+				ExpectReconcileSucceeded(ctx, nodeStateController, client.ObjectKeyFromObject(node))
+			}
+			pod := test.UnschedulablePod(test.PodOptions{
+				NodeSelector: map[string]string{corev1.LabelTopologyZone: "test-zone-1"},
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "test-zone-1"))
+		})
+	})
+
 	Describe("Deleting Nodes", func() {
 		It("should re-schedule pods from a deleting node when pods are active", func() {
 			ExpectApplied(ctx, env.Client, nodePool)
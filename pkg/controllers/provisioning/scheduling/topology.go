@@ -112,7 +112,7 @@ func buildDomainGroups(nodePools []*v1.NodePool, instanceTypes map[string][]*clo
 		for _, it := range its {
 			// We need to intersect the instance type requirements with the current nodePool requirements.  This
 			// ensures that something like zones from an instance type don't expand the universe of valid domains.
-			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Spec.Template.Spec.Requirements...)
+			requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Requirements()...)
 			requirements.Add(scheduling.NewLabelRequirements(np.Spec.Template.Labels).Values()...)
 			requirements.Add(it.Requirements.Values()...)
 
@@ -126,7 +126,7 @@ func buildDomainGroups(nodePools []*v1.NodePool, instanceTypes map[string][]*clo
 			}
 		}
 
-		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Spec.Template.Spec.Requirements...)
+		requirements := scheduling.NewNodeSelectorRequirementsWithMinValues(np.Requirements()...)
 		requirements.Add(scheduling.NewLabelRequirements(np.Spec.Template.Labels).Values()...)
 		for key, requirement := range requirements {
 			if requirement.Operator() == corev1.NodeSelectorOpIn {
@@ -0,0 +1,113 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduling
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/scheduling"
+)
+
+// zoneSpread steers new NodeClaims towards zones that increase the zonal diversity of NodePools which declare a zone
+// spread floor. The floor is a soft constraint: a NodeClaim is only narrowed to a new zone if its requirements and
+// instance types allow it.
+type zoneSpread struct {
+	floors map[string]*v1.ZoneSpread   // (NodePool name) -> zone spread floor
+	zones  map[string]sets.Set[string] // (NodePool name) -> zones spanned by the NodePool's nodes
+	nodes  map[string]int              // (NodePool name) -> number of nodes in the NodePool
+}
+
+func newZoneSpread(nodePools []*v1.NodePool, stateNodes []*state.StateNode) *zoneSpread {
+	z := &zoneSpread{
+		floors: map[string]*v1.ZoneSpread{},
+		zones:  map[string]sets.Set[string]{},
+		nodes:  map[string]int{},
+	}
+	for _, np := range nodePools {
+		if np.Spec.ZoneSpread != nil {
+			z.floors[np.Name] = np.Spec.ZoneSpread
+			z.zones[np.Name] = sets.New[string]()
+		}
+	}
+	for _, n := range stateNodes {
+		nodePoolName := n.Labels()[v1.NodePoolLabelKey]
+		if _, ok := z.floors[nodePoolName]; !ok {
+			continue
+		}
+		z.nodes[nodePoolName]++
+		if zone, ok := n.Labels()[corev1.LabelTopologyZone]; ok {
+			z.zones[nodePoolName].Insert(zone)
+		}
+	}
+	return z
+}
+
+// apply narrows the zone requirements of the NodeClaims so that NodePools below their zone spread floor launch
+// capacity into zones they don't span yet
+func (z *zoneSpread) apply(nodeClaims []*NodeClaim) {
+	for _, n := range nodeClaims {
+		if _, ok := z.floors[n.NodePoolName]; ok {
+			z.nodes[n.NodePoolName]++
+		}
+	}
+	for _, n := range nodeClaims {
+		floor, ok := z.floors[n.NodePoolName]
+		if !ok {
+			continue
+		}
+		covered := z.zones[n.NodePoolName]
+		if zones := n.Requirements.Get(corev1.LabelTopologyZone); zones.Operator() == corev1.NodeSelectorOpIn && zones.Len() == 1 {
+			covered.Insert(zones.Any())
+			continue
+		}
+		if z.nodes[n.NodePoolName] < int(floor.MinNodes) || covered.Len() >= int(floor.MinZones) {
+			continue
+		}
+		for _, zone := range uncoveredZones(n, covered) {
+			requirements := scheduling.NewRequirements(n.Requirements.Values()...)
+			requirements.Add(scheduling.NewRequirement(corev1.LabelTopologyZone, corev1.NodeSelectorOpIn, zone))
+			instanceTypes := n.InstanceTypeOptions.Compatible(requirements)
+			if len(instanceTypes) == 0 {
+				continue
+			}
+			if _, _, err := instanceTypes.SatisfiesMinValues(requirements); err != nil {
+				continue
+			}
+			n.Requirements = requirements
+			n.InstanceTypeOptions = instanceTypes
+			covered.Insert(zone)
+			break
+		}
+	}
+}
+
+// uncoveredZones returns the zones, in sorted order, that the NodeClaim could launch into and that its NodePool
+// doesn't span yet
+func uncoveredZones(n *NodeClaim, covered sets.Set[string]) []string {
+	zones := sets.New[string]()
+	for _, it := range n.InstanceTypeOptions {
+		for _, o := range it.Offerings.Available().Compatible(n.Requirements) {
+			if zone := o.Zone(); zone != "" && !covered.Has(zone) {
+				zones.Insert(zone)
+			}
+		}
+	}
+	return sets.List(zones)
+}
@@ -19,6 +19,8 @@ package provisioning_test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -52,6 +54,9 @@ import (
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
+	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	nodepoolutils "sigs.k8s.io/karpenter/pkg/utils/nodepool"
+	"sigs.k8s.io/karpenter/pkg/utils/shard"
 	. "sigs.k8s.io/karpenter/pkg/utils/testing"
 )
 
@@ -277,6 +282,19 @@ var _ = Describe("Provisioning", func() {
 		Expect(len(nodeClaims.Items)).To(Equal(1))
 		Expect(nodeClaims.Items[0].Spec.TerminationGracePeriod.Duration).To(BeNumerically("==", 223*time.Hour))
 	})
+	It("should pass the nodePool kubelet configuration to the cloud provider", func() {
+		nodePool := test.NodePool()
+		nodePool.Spec.Template.Spec.Kubelet = &v1.KubeletConfiguration{
+			MaxPods:      lo.ToPtr[int32](42),
+			KubeReserved: map[string]string{string(corev1.ResourceCPU): "200m"},
+		}
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		ExpectScheduled(ctx, env.Client, pod)
+		Expect(cloudProvider.CreateCalls).To(HaveLen(1))
+		Expect(cloudProvider.CreateCalls[0].Spec.Kubelet).To(Equal(nodePool.Spec.Template.Spec.Kubelet))
+	})
 	It("should ignore NodePools that are deleting", func() {
 		nodePool := test.NodePool()
 		ExpectApplied(ctx, env.Client, nodePool)
@@ -330,6 +348,52 @@ var _ = Describe("Provisioning", func() {
 		ExpectScheduled(ctx, env.Client, pod)
 		Expect(cluster.PodSchedulingSuccessTimeRegistrationHealthyCheck(client.ObjectKeyFromObject(pod)).IsZero()).To(BeTrue())
 	})
+	Context("Sharding", func() {
+		var nodePool *v1.NodePool
+		var nodePoolShard int
+		BeforeEach(func() {
+			nodePool = test.NodePool()
+			nodePoolShard = lo.Ternary(nodepoolutils.InShard(options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2)})), nodePool.Name), 0, 1)
+			ExpectApplied(ctx, env.Client, nodePool)
+		})
+		It("should provision pods that require a NodePool of its shard", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2), ShardIndex: lo.ToPtr(nodePoolShard)}))
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1.NodePoolLabelKey: nodePool.Name}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+		})
+		It("should not provision pods that require a NodePool of another shard", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2), ShardIndex: lo.ToPtr(1 - nodePoolShard)}))
+			pod := test.UnschedulablePod(test.PodOptions{NodeSelector: map[string]string{v1.NodePoolLabelKey: nodePool.Name}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should provision pods without a NodePool requirement from the shard of their owner", func() {
+			replicaSet := test.ReplicaSet()
+			ExpectApplied(ctx, env.Client, replicaSet)
+			ownerShard := lo.Ternary(shard.Owns(options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2)})), string(replicaSet.UID)), 0, 1)
+			pod := test.UnschedulablePod(test.PodOptions{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "apps/v1",
+							Kind:       "ReplicaSet",
+							Name:       replicaSet.Name,
+							UID:        replicaSet.UID,
+							Controller: lo.ToPtr(true),
+						},
+					},
+				},
+			})
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2), ShardIndex: lo.ToPtr(1 - ownerShard)}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(2), ShardIndex: lo.ToPtr(ownerShard)}))
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectScheduled(ctx, env.Client, pod)
+		})
+	})
 	It("should provision nodes for pods with supported node selectors", func() {
 		nodePool := test.NodePool()
 		schedulable := []*corev1.Pod{
@@ -488,6 +552,95 @@ var _ = Describe("Provisioning", func() {
 
 		Expect(nodeClaim.Annotations).To(HaveKeyWithValue(v1.NodePoolHashAnnotationKey, hash))
 	})
+	It("should record the nominated pods on the NodeClaim when the NominatedPods feature gate is enabled", func() {
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{FeatureGates: test.FeatureGates{NominatedPods: lo.ToPtr(true)}}))
+		ExpectApplied(ctx, env.Client, test.NodePool())
+		pods := test.UnschedulablePods(test.PodOptions{}, 2)
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pods...)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		nominated, err := nodeclaimutils.GetNominatedPods(nodeClaims[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lo.Map(nominated, func(p *corev1.Pod, _ int) types.UID { return p.UID })).To(ConsistOf(pods[0].UID, pods[1].UID))
+	})
+	It("should not record the nominated pods on the NodeClaim when the NominatedPods feature gate is disabled", func() {
+		ExpectApplied(ctx, env.Client, test.NodePool())
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].Annotations).ToNot(HaveKey(v1.NodeClaimNominatedPodsAnnotationKey))
+	})
+	It("should not create NodeClaims in read-only mode", func() {
+		modeFile := filepath.Join(GinkgoT().TempDir(), "read-only")
+		Expect(os.WriteFile(modeFile, []byte("true"), 0600)).To(Succeed())
+		ctx = options.ToContext(ctx, test.Options(test.OptionsFields{ReadOnlyModeFile: lo.ToPtr(modeFile)}))
+		pod := test.UnschedulablePod()
+		ExpectApplied(ctx, env.Client, test.NodePool(), pod)
+		prov.Trigger(pod.UID)
+
+		ExpectParallelized(
+			func() {
+				Eventually(func() bool { return fakeClock.HasWaiters() }, time.Second*10).Should(BeTrue())
+				fakeClock.Step(time.Second * 11)
+			},
+			func() {
+				ExpectSingletonReconciled(ctx, prov)
+			},
+		)
+		Expect(ExpectNodeClaims(ctx, env.Client)).To(BeEmpty())
+		ExpectNotScheduled(ctx, env.Client, pod)
+	})
+	Context("Requestless Pods", func() {
+		BeforeEach(func() {
+			ExpectApplied(ctx, env.Client, test.NodePool())
+		})
+		It("should ignore pods without requests when the policy is Reject", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{RequestlessPodPolicy: lo.ToPtr(options.RequestlessPodPolicyReject)}))
+			pod := test.UnschedulablePod()
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			ExpectNotScheduled(ctx, env.Client, pod)
+		})
+		It("should assume the namespace default requests for pods without requests when the policy is Default", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{RequestlessPodPolicy: lo.ToPtr(options.RequestlessPodPolicyDefault)}))
+			ns := test.Namespace()
+			ns.Annotations = map[string]string{v1.DefaultPodRequestsAnnotationKey: "cpu=10"}
+			ExpectApplied(ctx, env.Client, ns)
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Status.Allocatable.Cpu().Cmp(resource.MustParse("10"))).To(BeNumerically(">=", 0))
+		})
+		It("should assume the LimitRange default requests for pods without requests when the policy is LimitRange", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{RequestlessPodPolicy: lo.ToPtr(options.RequestlessPodPolicyLimitRange)}))
+			ns := test.Namespace()
+			ExpectApplied(ctx, env.Client, ns, &corev1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: ns.Name},
+				Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+					Type:           corev1.LimitTypeContainer,
+					DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")},
+				}}},
+			})
+			pod := test.UnschedulablePod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name}})
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+			node := ExpectScheduled(ctx, env.Client, pod)
+			Expect(node.Status.Allocatable.Cpu().Cmp(resource.MustParse("10"))).To(BeNumerically(">=", 0))
+		})
+	})
+	It("should annotate nodeclaims with the nodepool rollout trigger", func() {
+		nodePool := test.NodePool()
+		nodePool.Spec.RolloutTrigger = "2024-01-01"
+		ExpectApplied(ctx, env.Client, nodePool)
+		pod := test.UnschedulablePod()
+		ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, pod)
+		ExpectScheduled(ctx, env.Client, pod)
+
+		nodeClaims := ExpectNodeClaims(ctx, env.Client)
+		Expect(nodeClaims).To(HaveLen(1))
+		Expect(nodeClaims[0].Annotations).To(HaveKeyWithValue(v1.NodePoolRolloutTriggerAnnotationKey, "2024-01-01"))
+	})
 	It("should schedule all pods on one inflight node when node is in deleting state", func() {
 		nodePool := test.NodePool()
 		its, err := cloudProvider.GetInstanceTypes(ctx, nodePool)
@@ -859,6 +1012,28 @@ var _ = Describe("Provisioning", func() {
 			// only available instance type has 2 GPUs which would exceed the limit
 			ExpectNotScheduled(ctx, env.Client, pod)
 		})
+		It("should prioritize pods that repeatedly lost out on limits in previous scheduling rounds", func() {
+			nodePool := test.NodePool(v1.NodePool{
+				Spec: v1.NodePoolSpec{
+					Limits: v1.Limits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}),
+				},
+			})
+			ExpectApplied(ctx, env.Client, nodePool)
+			// Both pods require a 2 CPU node, so only one of them fits within the limit
+			newPod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.75")},
+			}})
+			oldPod := test.UnschedulablePod(test.PodOptions{ResourceRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")},
+			}})
+			// The smaller pod lost out to other pods in previous rounds
+			for range 3 {
+				cluster.MarkPodSchedulingDecisions(ctx, map[*corev1.Pod]error{oldPod: fmt.Errorf("exceeds limits")}, map[string][]*corev1.Pod{nodePool.Name: {test.Pod()}}, nil)
+			}
+			ExpectProvisioned(ctx, env.Client, cluster, cloudProvider, prov, newPod, oldPod)
+			ExpectScheduled(ctx, env.Client, oldPod)
+			ExpectNotScheduled(ctx, env.Client, newPod)
+		})
 		It("should not schedule to a nodepool after a scheduling round if limits would be exceeded", func() {
 			ExpectApplied(ctx, env.Client, test.NodePool(v1.NodePool{
 				Spec: v1.NodePoolSpec{
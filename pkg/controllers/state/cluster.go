@@ -30,6 +30,7 @@ import (
 	"go.uber.org/multierr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -44,8 +45,10 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/stream"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	nodeclaimutils "sigs.k8s.io/karpenter/pkg/utils/nodeclaim"
+	"sigs.k8s.io/karpenter/pkg/utils/pdb"
 	podutils "sigs.k8s.io/karpenter/pkg/utils/pod"
 	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
@@ -63,8 +66,14 @@ type Cluster struct {
 	nodeNameToProviderID      map[string]string               // node name -> provider id
 	nodeClaimNameToProviderID map[string]string               // node claim name -> provider id
 	nodePoolResources         map[string]corev1.ResourceList  // node pool name -> resource list
+	nodeClaimPhases           map[string]string               // node claim name -> last published phase
 	daemonSetPods             sync.Map                        // daemonSet -> existing pod
 
+	podDisruptionBudgets       *pdb.Cache
+	podDisruptionBudgetsSynced atomic.Bool
+
+	publisher stream.Publisher
+
 	NodePoolState *NodePoolState
 
 	podAcks                         sync.Map // pod namespaced name -> time when Karpenter first saw the pod as pending
@@ -72,6 +81,7 @@ type Cluster struct {
 	podsSchedulableTimes            sync.Map // pod namespaced name -> time when it was first marked as able to fit to a node
 	podHealthyNodePoolScheduledTime sync.Map // pod namespaced name -> time when pod scheduled to a nodePool that has NodeRegistrationHealthy=true, is marked as able to fit to a node
 	podToNodeClaim                  sync.Map // pod namespaced name -> nodeClaim name
+	podSchedulingLosses             sync.Map // pod namespaced name -> consecutive scheduling rounds where the pod failed to schedule while other pods scheduled
 
 	clusterStateMu sync.RWMutex // Separate mutex as this is called in some places that mu is held
 	// A monotonically increasing timestamp representing the time state of the
@@ -98,7 +108,10 @@ func NewCluster(clk clock.Clock, client client.Client, cloudProvider cloudprovid
 		nodeNameToProviderID:      map[string]string{},
 		nodeClaimNameToProviderID: map[string]string{},
 		nodePoolResources:         map[string]corev1.ResourceList{},
+		nodeClaimPhases:           map[string]string{},
+		podDisruptionBudgets:      pdb.NewCache(),
 
+		publisher:     stream.NopPublisher{},
 		NodePoolState: NewNodePoolState(),
 
 		podAcks:                         sync.Map{},
@@ -106,6 +119,7 @@ func NewCluster(clk clock.Clock, client client.Client, cloudProvider cloudprovid
 		podsSchedulingAttempted:         sync.Map{},
 		podHealthyNodePoolScheduledTime: sync.Map{},
 		podToNodeClaim:                  sync.Map{},
+		podSchedulingLosses:             sync.Map{},
 	}
 }
 
@@ -255,6 +269,19 @@ func (c *Cluster) DeepCopyNodes() StateNodes {
 	})
 }
 
+// SnapshotNodes creates a read-only copy of all state nodes. Unlike DeepCopyNodes, the copies share the pod tracking of
+// the state nodes, which is only copied when the cluster state next modifies it, so snapshotting many nodes is cheap.
+// NOTE: The returned state nodes must not be modified
+func (c *Cluster) SnapshotNodes() StateNodes {
+	// The write lock is held since snapshotting marks the state nodes as shared
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return lo.Map(lo.Values(c.nodes), func(n *StateNode, _ int) *StateNode {
+		return n.snapshot()
+	})
+}
+
 // IsNodeNominated returns true if the given node was expected to have a pod bound to it during a recent scheduling
 // batch
 func (c *Cluster) IsNodeNominated(providerID string) bool {
@@ -334,6 +361,17 @@ func (c *Cluster) UpdateNodeClaim(nodeClaim *v1.NodeClaim) {
 	// that we're not racing with the internal cache for the cluster, assuming the node doesn't exist.
 	c.nodeClaimNameToProviderID[nodeClaim.Name] = nodeClaim.Status.ProviderID
 	ClusterStateNodesCount.Set(float64(len(c.nodes)), nil)
+
+	if phase := stream.NodeClaimPhase(nodeClaim); c.nodeClaimPhases[nodeClaim.Name] != phase {
+		c.nodeClaimPhases[nodeClaim.Name] = phase
+		c.publish(stream.Event{
+			Type:       stream.NodeClaimPhaseChanged,
+			NodeClaim:  nodeClaim.Name,
+			NodePool:   nodeClaim.Labels[v1.NodePoolLabelKey],
+			ProviderID: nodeClaim.Status.ProviderID,
+			Phase:      phase,
+		})
+	}
 }
 
 func (c *Cluster) DeleteNodeClaim(name string) {
@@ -342,6 +380,11 @@ func (c *Cluster) DeleteNodeClaim(name string) {
 
 	c.cleanupNodeClaim(name)
 	ClusterStateNodesCount.Set(float64(len(c.nodes)), nil)
+
+	if _, ok := c.nodeClaimPhases[name]; ok {
+		delete(c.nodeClaimPhases, name)
+		c.publish(stream.Event{Type: stream.NodeClaimRemoved, NodeClaim: name})
+	}
 }
 
 func (c *Cluster) UpdateNode(ctx context.Context, node *corev1.Node) error {
@@ -362,6 +405,7 @@ func (c *Cluster) UpdateNode(ctx context.Context, node *corev1.Node) error {
 	if managed && node.Labels[corev1.LabelInstanceTypeStable] == "" && !initialized {
 		return nil
 	}
+	_, exists := c.nodeNameToProviderID[node.Name]
 	n, err := c.newStateFromNode(ctx, node, c.nodes[node.Spec.ProviderID])
 	if err != nil {
 		return err
@@ -369,14 +413,29 @@ func (c *Cluster) UpdateNode(ctx context.Context, node *corev1.Node) error {
 	c.nodes[node.Spec.ProviderID] = n
 	c.nodeNameToProviderID[node.Name] = node.Spec.ProviderID
 	ClusterStateNodesCount.Set(float64(len(c.nodes)), nil)
+
+	if !exists {
+		c.publish(stream.Event{
+			Type:       stream.NodeAdded,
+			Node:       node.Name,
+			NodePool:   node.Labels[v1.NodePoolLabelKey],
+			ProviderID: node.Spec.ProviderID,
+		})
+	}
 	return nil
 }
 
 func (c *Cluster) DeleteNode(name string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+
+	providerID, exists := c.nodeNameToProviderID[name]
 	c.cleanupNode(name)
 	ClusterStateNodesCount.Set(float64(len(c.nodes)), nil)
+
+	if exists {
+		c.publish(stream.Event{Type: stream.NodeRemoved, Node: name, ProviderID: providerID})
+	}
 }
 
 func (c *Cluster) UpdatePod(ctx context.Context, pod *corev1.Pod) error {
@@ -424,8 +483,15 @@ func (c *Cluster) PodAckTime(podKey types.NamespacedName) time.Time {
 // We'll only emit a metric for a pod if we haven't done it before.
 func (c *Cluster) MarkPodSchedulingDecisions(ctx context.Context, podErrors map[*corev1.Pod]error, npPods map[string][]*corev1.Pod, ncPods map[string][]*corev1.Pod) {
 	now := c.clock.Now()
+	// A pod only loses a round when other pods were able to schedule, e.g. because a NodePool limit was consumed by
+	// the pods that were ordered ahead of it
+	contended := lo.SomeBy(lo.Values(npPods), func(pods []*corev1.Pod) bool { return len(pods) > 0 })
 	for pod := range podErrors {
 		nn := client.ObjectKeyFromObject(pod)
+		if contended {
+			losses, _ := c.podSchedulingLosses.LoadOrStore(nn, 0)
+			c.podSchedulingLosses.Store(nn, losses.(int)+1)
+		}
 		// delete podsSchedulableTimes and podHealthyNodePoolScheduledTime for pods that have pod errors
 		c.podsSchedulableTimes.Delete(nn)
 		_, alreadyExists := c.podsSchedulingAttempted.LoadOrStore(nn, now)
@@ -447,6 +513,7 @@ func (c *Cluster) MarkPodSchedulingDecisions(ctx context.Context, podErrors map[
 		}
 		for _, p := range pods {
 			nn := client.ObjectKeyFromObject(p)
+			c.podSchedulingLosses.Delete(nn)
 			c.podsSchedulableTimes.LoadOrStore(nn, now)
 			_, alreadyExists := c.podsSchedulingAttempted.LoadOrStore(nn, now)
 			// If we already attempted this, we don't need to emit another metric.
@@ -496,6 +563,15 @@ func (c *Cluster) PodSchedulingSuccessTime(podKey types.NamespacedName) time.Tim
 	return time.Time{}
 }
 
+// PodSchedulingLosses returns the number of consecutive scheduling rounds where the pod failed to schedule while other
+// pods were able to schedule.
+func (c *Cluster) PodSchedulingLosses(podKey types.NamespacedName) int {
+	if val, found := c.podSchedulingLosses.Load(podKey); found {
+		return val.(int)
+	}
+	return 0
+}
+
 // PodNodeClaimMapping returns the nodeClaim against which the pod is simulated to get scheduled
 func (c *Cluster) PodNodeClaimMapping(podKey types.NamespacedName) string {
 	if val, found := c.podToNodeClaim.Load(podKey); found {
@@ -529,6 +605,7 @@ func (c *Cluster) ClearPodSchedulingMappings(podKey types.NamespacedName) {
 	c.podsSchedulingAttempted.Delete(podKey)
 	c.podHealthyNodePoolScheduledTime.Delete(podKey)
 	c.podToNodeClaim.Delete(podKey)
+	c.podSchedulingLosses.Delete(podKey)
 }
 
 // MarkUnconsolidated marks the cluster state as being unconsolidated.  This should be called in any situation where
@@ -569,6 +646,21 @@ func (c *Cluster) NodePoolResourcesFor(nodePoolName string) corev1.ResourceList
 	return maps.Clone(c.nodePoolResources[nodePoolName])
 }
 
+// SetPublisher configures where cluster state change notifications are sent. This must be called before the
+// cluster state starts receiving updates.
+func (c *Cluster) SetPublisher(publisher stream.Publisher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.publisher = publisher
+}
+
+// Publish sends a state change notification to the configured publisher, stamping it with the current time
+func (c *Cluster) Publish(evt stream.Event) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.publish(evt)
+}
+
 // Reset the cluster state for unit testing
 func (c *Cluster) Reset() {
 	c.mu.Lock()
@@ -582,12 +674,16 @@ func (c *Cluster) Reset() {
 	c.nodeClaimNameToProviderID = map[string]string{}
 	c.NodePoolState = NewNodePoolState()
 	c.nodePoolResources = map[string]corev1.ResourceList{}
+	c.nodeClaimPhases = map[string]string{}
 	c.bindings = map[types.NamespacedName]string{}
 	c.antiAffinityPods = sync.Map{}
 	c.daemonSetPods = sync.Map{}
+	c.podDisruptionBudgets = pdb.NewCache()
+	c.podDisruptionBudgetsSynced.Store(false)
 	c.podAcks = sync.Map{}
 	c.podsSchedulingAttempted = sync.Map{}
 	c.podsSchedulableTimes = sync.Map{}
+	c.podSchedulingLosses = sync.Map{}
 }
 
 func (c *Cluster) GetDaemonSetPod(daemonset *appsv1.DaemonSet) *corev1.Pod {
@@ -625,6 +721,43 @@ func (c *Cluster) DeleteDaemonSet(key types.NamespacedName) {
 	c.daemonSetPods.Delete(key)
 }
 
+// UpdatePodDisruptionBudget tracks the PodDisruptionBudget so that disruption can evaluate it without listing every
+// PodDisruptionBudget in the cluster
+func (c *Cluster) UpdatePodDisruptionBudget(budget *policyv1.PodDisruptionBudget) error {
+	return c.podDisruptionBudgets.Update(budget)
+}
+
+func (c *Cluster) DeletePodDisruptionBudget(key types.NamespacedName) {
+	c.podDisruptionBudgets.Delete(key)
+}
+
+// PodDisruptionBudgetLimits returns the limits of the PodDisruptionBudgets that are tracked in the cluster state
+func (c *Cluster) PodDisruptionBudgetLimits() pdb.Limits {
+	return c.podDisruptionBudgets.Limits()
+}
+
+// PodDisruptionBudgetsSynced validates that every PodDisruptionBudget stored in the apiserver is tracked in the cluster
+// state. Like Synced, once the PodDisruptionBudgets have synced they're assumed to be kept consistent by the informer.
+func (c *Cluster) PodDisruptionBudgetsSynced(ctx context.Context) bool {
+	if c.podDisruptionBudgetsSynced.Load() {
+		return true
+	}
+	// Because we may get many PodDisruptionBudgets from this response, we are not DeepCopying the cached data here
+	// DO NOT MUTATE PodDisruptionBudgets in this function as this will affect the underlying cached PodDisruptionBudget
+	budgets := &policyv1.PodDisruptionBudgetList{}
+	if err := c.kubeClient.List(ctx, budgets, client.UnsafeDisableDeepCopy); err != nil {
+		log.FromContext(ctx).Error(err, "failed checking PodDisruptionBudget sync")
+		return false
+	}
+	synced := lo.EveryBy(budgets.Items, func(budget policyv1.PodDisruptionBudget) bool {
+		return c.podDisruptionBudgets.Has(client.ObjectKeyFromObject(&budget))
+	})
+	if synced {
+		c.podDisruptionBudgetsSynced.Store(true)
+	}
+	return synced
+}
+
 // WARNING
 // Everything under this section of code assumes that you have already held a lock when you are calling into these functions
 // and explicitly modifying the cluster state. If you do not hold the cluster state lock before calling any of these helpers
@@ -645,6 +778,8 @@ func (c *Cluster) newStateFromNodeClaim(nodeClaim *v1.NodeClaim, oldNode *StateN
 		volumeUsage:       oldNode.volumeUsage,
 		markedForDeletion: oldNode.markedForDeletion,
 		nominatedUntil:    oldNode.nominatedUntil,
+		// The pod tracking is carried over from the old node, so it's shared with any snapshot of the old node
+		shared: oldNode.shared,
 	}
 	// Cleanup the old nodeClaim with its old providerID if its providerID changes
 	// This can happen since nodes don't get created with providerIDs. Rather, CCM picks up the
@@ -711,6 +846,13 @@ func (c *Cluster) newStateFromNode(ctx context.Context, node *corev1.Node, oldNo
 	return n, nil
 }
 
+func (c *Cluster) publish(evt stream.Event) {
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = c.clock.Now()
+	}
+	c.publisher.Publish(evt)
+}
+
 func (c *Cluster) cleanupNode(name string) {
 	if id := c.nodeNameToProviderID[name]; id != "" {
 		if c.nodes[id].NodeClaim == nil {
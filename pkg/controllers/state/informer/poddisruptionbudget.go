@@ -0,0 +1,72 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/karpenter/pkg/controllers/state"
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+	utilscontroller "sigs.k8s.io/karpenter/pkg/utils/controller"
+)
+
+// PodDisruptionBudgetController reconciles PodDisruptionBudgets so that cluster state can maintain their limits
+// incrementally rather than disruption listing every PodDisruptionBudget on each loop.
+type PodDisruptionBudgetController struct {
+	kubeClient client.Client
+	cluster    *state.Cluster
+}
+
+func NewPodDisruptionBudgetController(kubeClient client.Client, cluster *state.Cluster) *PodDisruptionBudgetController {
+	return &PodDisruptionBudgetController{
+		kubeClient: kubeClient,
+		cluster:    cluster,
+	}
+}
+
+func (c *PodDisruptionBudgetController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx = injection.WithControllerName(ctx, "state.poddisruptionbudget")
+
+	budget := &policyv1.PodDisruptionBudget{}
+	if err := c.kubeClient.Get(ctx, req.NamespacedName, budget); err != nil {
+		if errors.IsNotFound(err) {
+			// notify cluster state of the PodDisruptionBudget deletion
+			c.cluster.DeletePodDisruptionBudget(req.NamespacedName)
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if err := c.cluster.UpdatePodDisruptionBudget(budget); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *PodDisruptionBudgetController) Register(ctx context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("state.poddisruptionbudget").
+		For(&policyv1.PodDisruptionBudget{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: utilscontroller.LinearScaleReconciles(utilscontroller.CPUCount(ctx), minReconciles, maxReconciles)}).
+		Complete(c)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/awslabs/operatorpkg/serrors"
@@ -32,6 +33,7 @@ import (
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
@@ -66,6 +68,41 @@ func IgnorePodBlockEvictionError(err error) error {
 	return err
 }
 
+func (e *PodBlockEvictionError) Unwrap() error {
+	return e.error
+}
+
+// Machine-readable reasons that a node can't be disrupted
+const (
+	BlockedReasonNominated    = "nominated"
+	BlockedReasonDoNotDisrupt = "do_not_disrupt"
+	BlockedReasonPDB          = "pdb"
+)
+
+// BlockedError is an error that blocks the disruption of a node for a machine-readable reason, so that blocked nodes
+// can be aggregated in metrics
+type BlockedError struct {
+	error
+	Reason string
+}
+
+func NewBlockedError(reason string, err error) *BlockedError {
+	return &BlockedError{error: err, Reason: reason}
+}
+
+func (e *BlockedError) Unwrap() error {
+	return e.error
+}
+
+// BlockedReason returns the reason of the BlockedError wrapped by the error, or an empty string if there isn't one
+func BlockedReason(err error) string {
+	var blockedError *BlockedError
+	if stderrors.As(err, &blockedError) {
+		return blockedError.Reason
+	}
+	return ""
+}
+
 //go:generate controller-gen object:headerFile="../../../hack/boilerplate.go.txt" paths="."
 
 // StateNodes is a typed version of a list of *Node
@@ -99,10 +136,10 @@ func (n StateNodes) Pods(ctx context.Context, kubeClient client.Client) ([]*core
 	return pods, nil
 }
 
-func (n StateNodes) CurrentlyReschedulablePods(ctx context.Context, kubeClient client.Client) ([]*corev1.Pod, error) {
+func (n StateNodes) CurrentlyReschedulablePods(ctx context.Context, kubeClient client.Client, clk clock.Clock) ([]*corev1.Pod, error) {
 	var pods []*corev1.Pod
 	for _, node := range n {
-		p, err := node.CurrentlyReschedulablePods(ctx, kubeClient)
+		p, err := node.CurrentlyReschedulablePods(ctx, kubeClient, clk)
 		if err != nil {
 			return nil, err
 		}
@@ -135,6 +172,10 @@ type StateNode struct {
 	// of the karpenter.sh/disruption taint to know when a node is marked for deletion.
 	markedForDeletion bool
 	nominatedUntil    metav1.Time
+
+	// shared is set when the pod tracking of the StateNode is referenced by a snapshot of the cluster state. A shared
+	// StateNode copies its pod tracking before it's next modified, so that snapshots never observe the modification.
+	shared bool
 }
 
 func NewNode() *StateNode {
@@ -163,6 +204,29 @@ func (in *StateNode) ShallowCopy() *StateNode {
 	}
 }
 
+// snapshot returns a read-only copy of the StateNode that shares its pod tracking rather than deep copying it. Both the
+// StateNode and the copy are marked as shared so that neither modifies the pod tracking in place afterward.
+func (in *StateNode) snapshot() *StateNode {
+	in.shared = true
+	out := in.ShallowCopy()
+	out.shared = true
+	return out
+}
+
+// copyOnWrite copies the pod tracking of a shared StateNode so that it can be modified without affecting snapshots
+func (in *StateNode) copyOnWrite() {
+	if !in.shared {
+		return
+	}
+	in.daemonSetRequests = maps.Clone(in.daemonSetRequests)
+	in.daemonSetLimits = maps.Clone(in.daemonSetLimits)
+	in.podRequests = maps.Clone(in.podRequests)
+	in.podLimits = maps.Clone(in.podLimits)
+	in.hostPortUsage = in.hostPortUsage.DeepCopy()
+	in.volumeUsage = in.volumeUsage.DeepCopy()
+	in.shared = false
+}
+
 func (in *StateNode) Name() string {
 	if in.Node == nil {
 		return in.NodeClaim.Name
@@ -194,19 +258,28 @@ func (in *StateNode) Pods(ctx context.Context, kubeClient client.Client) ([]*cor
 	return nodeutils.GetPods(ctx, kubeClient, in.Node)
 }
 
+// The cluster-autoscaler annotations that block disruption like karpenter.sh/do-not-disrupt when the
+// cluster-autoscaler-compatibility setting is enabled
+const (
+	ClusterAutoscalerSafeToEvictAnnotationKey       = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	ClusterAutoscalerScaleDownDisabledAnnotationKey = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+)
+
 // ValidateNodeDisruptable returns an error if the StateNode cannot be disrupted
 // This checks all associated StateNode internals, node labels, and do-not-disrupt annotations on the node.
 // ValidateNodeDisruptable takes in a recorder to emit events on the nodeclaims when the state node is not a candidate
 //
 //nolint:gocyclo
-func (in *StateNode) ValidateNodeDisruptable() error {
+func (in *StateNode) ValidateNodeDisruptable(ctx context.Context, clk clock.Clock) error {
 	if in.NodeClaim == nil {
 		return fmt.Errorf("node isn't managed by karpenter")
 	}
 	if in.Node == nil {
 		return fmt.Errorf("nodeclaim does not have an associated node")
 	}
-	if !in.Initialized() {
+	// Nodes whose startup taints timed out never initialize, so they're only disrupted to be replaced by node repair
+	if unhealthy := in.NodeClaim.StatusConditions().Get(v1.ConditionTypeUnhealthy); !in.Initialized() &&
+		!(unhealthy.IsTrue() && unhealthy.Reason == v1.UnhealthyReasonStartupTaintsTimedOut) {
 		return fmt.Errorf("node isn't initialized")
 	}
 	if in.MarkedForDeletion() {
@@ -214,10 +287,13 @@ func (in *StateNode) ValidateNodeDisruptable() error {
 	}
 	// skip the node if it is nominated by a recent provisioning pass to be the target of a pending pod.
 	if in.Nominated() {
-		return fmt.Errorf("node is nominated for a pending pod")
+		return NewBlockedError(BlockedReasonNominated, fmt.Errorf("node is nominated for a pending pod"))
+	}
+	if podutils.IsDoNotDisrupt(in.Annotations()[v1.DoNotDisruptAnnotationKey], clk) {
+		return NewBlockedError(BlockedReasonDoNotDisrupt, fmt.Errorf("disruption is blocked through the %q annotation", v1.DoNotDisruptAnnotationKey))
 	}
-	if in.Annotations()[v1.DoNotDisruptAnnotationKey] == "true" {
-		return fmt.Errorf("disruption is blocked through the %q annotation", v1.DoNotDisruptAnnotationKey)
+	if options.FromContext(ctx).ClusterAutoscalerCompatibility && in.Annotations()[ClusterAutoscalerScaleDownDisabledAnnotationKey] == "true" {
+		return NewBlockedError(BlockedReasonDoNotDisrupt, fmt.Errorf("disruption is blocked through the %q annotation", ClusterAutoscalerScaleDownDisabledAnnotationKey))
 	}
 	// check whether the node has the NodePool label
 	if _, ok := in.Labels()[v1.NodePoolLabelKey]; !ok {
@@ -231,34 +307,54 @@ func (in *StateNode) ValidateNodeDisruptable() error {
 // ValidatePodDisruptable takes in a recorder to emit events on the nodeclaims when the state node is not a candidate
 //
 //nolint:gocyclo
-func (in *StateNode) ValidatePodsDisruptable(ctx context.Context, kubeClient client.Client, pdbs pdb.Limits) ([]*corev1.Pod, error) {
+func (in *StateNode) ValidatePodsDisruptable(ctx context.Context, kubeClient client.Client, clk clock.Clock, pdbs pdb.Limits) ([]*corev1.Pod, error) {
 	pods, err := in.Pods(ctx, kubeClient)
 	if err != nil {
 		return nil, fmt.Errorf("getting pods from node, %w", err)
 	}
+	namespaces := map[string]bool{}
 	for _, po := range pods {
 		// We only consider pods that are actively running for "karpenter.sh/do-not-disrupt"
 		// This means that we will allow Mirror Pods and DaemonSets to block disruption using this annotation
-		if !podutils.IsDisruptable(po) {
-			return pods, NewPodBlockEvictionError(serrors.Wrap(fmt.Errorf(`pod has "karpenter.sh/do-not-disrupt" annotation`), "Pod", klog.KObj(po)))
+		if !podutils.IsDisruptable(po, clk) {
+			return pods, NewPodBlockEvictionError(NewBlockedError(BlockedReasonDoNotDisrupt, serrors.Wrap(fmt.Errorf(`pod has "karpenter.sh/do-not-disrupt" annotation`), "Pod", klog.KObj(po))))
+		}
+		if !podutils.IsActive(po) {
+			continue
+		}
+		if options.FromContext(ctx).ClusterAutoscalerCompatibility && po.Annotations[ClusterAutoscalerSafeToEvictAnnotationKey] == "false" {
+			return pods, NewPodBlockEvictionError(NewBlockedError(BlockedReasonDoNotDisrupt, serrors.Wrap(fmt.Errorf(`pod has "%s=false" annotation`, ClusterAutoscalerSafeToEvictAnnotationKey), "Pod", klog.KObj(po))))
+		}
+		// Namespaces protect all of their pods the same way, so that platform teams don't have to annotate every pod
+		doNotDisrupt, ok := namespaces[po.Namespace]
+		if !ok {
+			namespace := &corev1.Namespace{}
+			if err := kubeClient.Get(ctx, types.NamespacedName{Name: po.Namespace}, namespace); client.IgnoreNotFound(err) != nil {
+				return nil, fmt.Errorf("getting namespace, %w", err)
+			}
+			doNotDisrupt = podutils.NamespaceHasDoNotDisrupt(namespace, clk)
+			namespaces[po.Namespace] = doNotDisrupt
+		}
+		if doNotDisrupt {
+			return pods, NewPodBlockEvictionError(NewBlockedError(BlockedReasonDoNotDisrupt, serrors.Wrap(fmt.Errorf(`namespace has "karpenter.sh/do-not-disrupt" annotation`), "Pod", klog.KObj(po))))
 		}
 	}
-	if pdbKeys, ok := pdbs.CanEvictPods(pods); !ok {
+	if pdbKeys, ok := pdbs.CanEvictPods(pods, clk); !ok {
 		if len(pdbKeys) > 1 {
-			return pods, NewPodBlockEvictionError(serrors.Wrap(fmt.Errorf("eviction does not support multiple PDBs"), "PodDisruptionBudget(s)", pdbKeys))
+			return pods, NewPodBlockEvictionError(NewBlockedError(BlockedReasonPDB, serrors.Wrap(fmt.Errorf("eviction does not support multiple PDBs"), "PodDisruptionBudget(s)", pdbKeys)))
 		}
-		return pods, NewPodBlockEvictionError(serrors.Wrap(fmt.Errorf("pdb prevents pod evictions"), "PodDisruptionBudget", pdbKeys))
+		return pods, NewPodBlockEvictionError(NewBlockedError(BlockedReasonPDB, serrors.Wrap(fmt.Errorf("pdb prevents pod evictions"), "PodDisruptionBudget", pdbKeys)))
 	}
 
 	return pods, nil
 }
 
 // CurrentlyReschedulablePods gets the pods assigned to the Node that are currently reschedulable based on the kubernetes api-server bindings
-func (in *StateNode) CurrentlyReschedulablePods(ctx context.Context, kubeClient client.Client) ([]*corev1.Pod, error) {
+func (in *StateNode) CurrentlyReschedulablePods(ctx context.Context, kubeClient client.Client, clk clock.Clock) ([]*corev1.Pod, error) {
 	if in.Node == nil {
 		return nil, nil
 	}
-	return nodeutils.GetCurrentlyReschedulablePods(ctx, kubeClient, in.Node)
+	return nodeutils.GetCurrentlyReschedulablePods(ctx, kubeClient, clk, in.Node)
 }
 
 func (in *StateNode) HostName() string {
@@ -447,6 +543,7 @@ func (in *StateNode) updateForPod(ctx context.Context, kubeClient client.Client,
 	if err != nil {
 		return fmt.Errorf("tracking volume usage, %w", err)
 	}
+	in.copyOnWrite()
 	in.podRequests[podKey] = resources.RequestsForPods(pod)
 	in.podLimits[podKey] = resources.LimitsForPods(pod)
 	// if it's a daemonset, we track what it has requested separately
@@ -460,6 +557,7 @@ func (in *StateNode) updateForPod(ctx context.Context, kubeClient client.Client,
 }
 
 func (in *StateNode) cleanupForPod(podKey types.NamespacedName) {
+	in.copyOnWrite()
 	in.hostPortUsage.DeletePod(podKey)
 	in.volumeUsage.DeletePod(podKey)
 	delete(in.podRequests, podKey)
@@ -0,0 +1,74 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"time"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+type EventType string
+
+const (
+	NodeAdded             EventType = "NodeAdded"
+	NodeRemoved           EventType = "NodeRemoved"
+	NodeClaimPhaseChanged EventType = "NodeClaimPhaseChanged"
+	NodeClaimRemoved      EventType = "NodeClaimRemoved"
+	CommandStarted        EventType = "CommandStarted"
+	CommandSucceeded      EventType = "CommandSucceeded"
+	CommandFailed         EventType = "CommandFailed"
+)
+
+// Event is a single state-change notification delivered to external consumers. Fields that don't apply to the
+// event type are left empty.
+type Event struct {
+	Type       EventType         `json:"type"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Node       string            `json:"node,omitempty"`
+	NodeClaim  string            `json:"nodeClaim,omitempty"`
+	NodePool   string            `json:"nodePool,omitempty"`
+	ProviderID string            `json:"providerID,omitempty"`
+	Phase      string            `json:"phase,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+}
+
+// Publisher receives state-change notifications. Implementations must not block since events are published
+// while cluster state locks are held.
+type Publisher interface {
+	Publish(Event)
+}
+
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(Event) {}
+
+// NodeClaimPhase summarizes the lifecycle position of a NodeClaim from its status conditions
+func NodeClaimPhase(nodeClaim *v1.NodeClaim) string {
+	switch {
+	case !nodeClaim.DeletionTimestamp.IsZero():
+		return "Deleting"
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeInitialized).IsTrue():
+		return v1.ConditionTypeInitialized
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeRegistered).IsTrue():
+		return v1.ConditionTypeRegistered
+	case nodeClaim.StatusConditions().Get(v1.ConditionTypeLaunched).IsTrue():
+		return v1.ConditionTypeLaunched
+	default:
+		return "Pending"
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/karpenter/pkg/controllers/state/stream"
+	. "sigs.k8s.io/karpenter/pkg/test/expectations"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+
+func TestAPIs(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Stream")
+}
+
+var _ = Describe("Webhook", func() {
+	var server *httptest.Server
+	var mu sync.Mutex
+	var received [][]stream.Event
+	var statusCode int
+
+	BeforeEach(func() {
+		received = nil
+		statusCode = http.StatusOK
+		stream.EventsTotal.Reset()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			mu.Lock()
+			defer mu.Unlock()
+			var batch []stream.Event
+			Expect(json.NewDecoder(r.Body).Decode(&batch)).To(Succeed())
+			received = append(received, batch)
+			w.WriteHeader(statusCode)
+		}))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should deliver published events in a single batch", func() {
+		webhook := stream.NewWebhook(server.URL)
+		webhook.Publish(stream.Event{Type: stream.NodeAdded, Node: "node-a"})
+		webhook.Publish(stream.Event{Type: stream.NodeRemoved, Node: "node-b"})
+		ExpectSingletonReconciled(ctx, webhook)
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(received).To(HaveLen(1))
+		Expect(received[0]).To(HaveLen(2))
+		Expect(received[0][0].Type).To(Equal(stream.NodeAdded))
+		Expect(received[0][0].Node).To(Equal("node-a"))
+		Expect(received[0][1].Type).To(Equal(stream.NodeRemoved))
+		ExpectMetricCounterValue(stream.EventsTotal, 2, map[string]string{"result": "delivered"})
+	})
+	It("should not send a request when there are no events", func() {
+		webhook := stream.NewWebhook(server.URL)
+		ExpectSingletonReconciled(ctx, webhook)
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(received).To(BeEmpty())
+	})
+	It("should return an error and count failed events when the endpoint rejects the batch", func() {
+		statusCode = http.StatusInternalServerError
+		webhook := stream.NewWebhook(server.URL)
+		webhook.Publish(stream.Event{Type: stream.CommandStarted})
+		ExpectSingletonReconcileFailed(ctx, webhook)
+		ExpectMetricCounterValue(stream.EventsTotal, 1, map[string]string{"result": "failed"})
+	})
+})
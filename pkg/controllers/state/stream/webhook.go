@@ -0,0 +1,127 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/awslabs/operatorpkg/reconciler"
+	"github.com/awslabs/operatorpkg/serrors"
+	"github.com/awslabs/operatorpkg/singleton"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"sigs.k8s.io/karpenter/pkg/operator/injection"
+)
+
+const (
+	// bufferSize is the maximum number of undelivered events held in memory. Events published while the buffer is
+	// full are dropped so that publishing never blocks cluster state updates.
+	bufferSize = 10000
+	// maxBatchSize is the maximum number of events sent in a single webhook request
+	maxBatchSize = 500
+	flushPeriod  = time.Second
+)
+
+// Webhook is a Publisher that buffers events in memory and periodically delivers them in batches as a JSON array
+// to an HTTP endpoint. Delivery is best-effort: events that fail to deliver are dropped and counted in metrics.
+type Webhook struct {
+	url        string
+	httpClient *http.Client
+	events     chan Event
+}
+
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		events:     make(chan Event, bufferSize),
+	}
+}
+
+func (w *Webhook) Publish(evt Event) {
+	select {
+	case w.events <- evt:
+	default:
+		EventsTotal.Inc(map[string]string{resultLabel: "dropped"})
+	}
+}
+
+func (w *Webhook) Reconcile(ctx context.Context) (reconciler.Result, error) {
+	ctx = injection.WithControllerName(ctx, "state.stream")
+
+	batch := w.drain()
+	if len(batch) == 0 {
+		return reconciler.Result{RequeueAfter: flushPeriod}, nil
+	}
+	if err := w.send(ctx, batch); err != nil {
+		EventsTotal.Add(float64(len(batch)), map[string]string{resultLabel: "failed"})
+		return reconciler.Result{}, serrors.Wrap(fmt.Errorf("delivering cluster state events, %w", err), "count", len(batch))
+	}
+	EventsTotal.Add(float64(len(batch)), map[string]string{resultLabel: "delivered"})
+	// If the buffer still has events, flush again immediately rather than waiting for the next period
+	if len(w.events) > 0 {
+		return reconciler.Result{RequeueAfter: singleton.RequeueImmediately}, nil
+	}
+	return reconciler.Result{RequeueAfter: flushPeriod}, nil
+}
+
+func (w *Webhook) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("state.stream").
+		WatchesRawSource(singleton.Source()).
+		Complete(singleton.AsReconciler(w))
+}
+
+func (w *Webhook) drain() []Event {
+	var batch []Event
+	for len(batch) < maxBatchSize {
+		select {
+		case evt := <-w.events:
+			batch = append(batch, evt)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (w *Webhook) send(ctx context.Context, batch []Event) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("marshaling events, %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request, %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request, %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return serrors.Wrap(fmt.Errorf("unexpected response"), "status-code", resp.StatusCode)
+	}
+	return nil
+}
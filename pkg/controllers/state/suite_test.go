@@ -43,6 +43,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/cloudprovider/fake"
 	"sigs.k8s.io/karpenter/pkg/controllers/state"
 	"sigs.k8s.io/karpenter/pkg/controllers/state/informer"
+	"sigs.k8s.io/karpenter/pkg/controllers/state/stream"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/scheduling"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -149,6 +150,34 @@ var _ = Describe("Pod Healthy NodePool", func() {
 		Expect(cluster.PodSchedulingSuccessTimeRegistrationHealthyCheck(client.ObjectKeyFromObject(pod)).IsZero()).To(BeTrue())
 	})
 })
+var _ = Describe("Pod Scheduling Losses", func() {
+	It("should count rounds where a pod fails to schedule while other pods schedule", func() {
+		pod, other := test.Pod(), test.Pod()
+		ExpectApplied(ctx, env.Client, pod, other, nodePool)
+
+		for range 3 {
+			cluster.MarkPodSchedulingDecisions(ctx, map[*corev1.Pod]error{pod: fmt.Errorf("exceeds limits")}, map[string][]*corev1.Pod{nodePool.Name: {other}}, nil)
+		}
+		Expect(cluster.PodSchedulingLosses(client.ObjectKeyFromObject(pod))).To(Equal(3))
+		Expect(cluster.PodSchedulingLosses(client.ObjectKeyFromObject(other))).To(Equal(0))
+	})
+	It("should not count rounds where no pods schedule", func() {
+		pod := test.Pod()
+		ExpectApplied(ctx, env.Client, pod, nodePool)
+
+		cluster.MarkPodSchedulingDecisions(ctx, map[*corev1.Pod]error{pod: fmt.Errorf("exceeds limits")}, map[string][]*corev1.Pod{}, nil)
+		Expect(cluster.PodSchedulingLosses(client.ObjectKeyFromObject(pod))).To(Equal(0))
+	})
+	It("should reset the losses once the pod schedules", func() {
+		pod, other := test.Pod(), test.Pod()
+		ExpectApplied(ctx, env.Client, pod, other, nodePool)
+
+		cluster.MarkPodSchedulingDecisions(ctx, map[*corev1.Pod]error{pod: fmt.Errorf("exceeds limits")}, map[string][]*corev1.Pod{nodePool.Name: {other}}, nil)
+		Expect(cluster.PodSchedulingLosses(client.ObjectKeyFromObject(pod))).To(Equal(1))
+		cluster.MarkPodSchedulingDecisions(ctx, nil, map[string][]*corev1.Pod{nodePool.Name: {pod}}, nil)
+		Expect(cluster.PodSchedulingLosses(client.ObjectKeyFromObject(pod))).To(Equal(0))
+	})
+})
 
 var _ = Describe("Pod Ack", func() {
 	It("should only mark pods as schedulable once", func() {
@@ -449,6 +478,63 @@ var _ = Describe("Node Deletion", func() {
 	})
 })
 
+var _ = Describe("State Stream", func() {
+	var publisher *recordingPublisher
+	BeforeEach(func() {
+		publisher = &recordingPublisher{}
+		cluster.SetPublisher(publisher)
+	})
+	AfterEach(func() {
+		cluster.SetPublisher(stream.NopPublisher{})
+	})
+	It("should publish events when nodes are added and removed", func() {
+		node := test.Node(test.NodeOptions{ProviderID: test.RandomProviderID()})
+		ExpectApplied(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		// Updating an existing node shouldn't publish another event
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectDeleted(ctx, env.Client, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+
+		events := publisher.Events()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Type).To(Equal(stream.NodeAdded))
+		Expect(events[0].Node).To(Equal(node.Name))
+		Expect(events[0].ProviderID).To(Equal(node.Spec.ProviderID))
+		Expect(events[0].Timestamp).To(Equal(fakeClock.Now()))
+		Expect(events[1].Type).To(Equal(stream.NodeRemoved))
+		Expect(events[1].Node).To(Equal(node.Name))
+	})
+	It("should publish events when a nodeclaim changes phase", func() {
+		nodeClaim := test.NodeClaim(v1.NodeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{v1.NodePoolLabelKey: nodePool.Name},
+			},
+		})
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+
+		nodeClaim.StatusConditions().SetTrue(v1.ConditionTypeLaunched)
+		ExpectApplied(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+
+		ExpectDeleted(ctx, env.Client, nodeClaim)
+		ExpectReconcileSucceeded(ctx, nodeClaimController, client.ObjectKeyFromObject(nodeClaim))
+
+		events := publisher.Events()
+		Expect(lo.Map(events, func(e stream.Event, _ int) stream.EventType { return e.Type })).To(Equal([]stream.EventType{
+			stream.NodeClaimPhaseChanged,
+			stream.NodeClaimPhaseChanged,
+			stream.NodeClaimRemoved,
+		}))
+		Expect(events[0].Phase).To(Equal("Pending"))
+		Expect(events[0].NodePool).To(Equal(nodePool.Name))
+		Expect(events[1].Phase).To(Equal(v1.ConditionTypeLaunched))
+		Expect(events[2].NodeClaim).To(Equal(nodeClaim.Name))
+	})
+})
+
 var _ = Describe("Node Resource Level", func() {
 	It("should not count pods not bound to nodes", func() {
 		pod1 := test.UnschedulablePod(test.PodOptions{
@@ -523,6 +609,49 @@ var _ = Describe("Node Resource Level", func() {
 		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod2))
 		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3.5")}, ExpectStateNodeExists(cluster, node).PodRequests())
 	})
+	It("should not update snapshotted nodes when pods bind or are deleted", func() {
+		pod1 := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU: resource.MustParse("1.5"),
+				}},
+		})
+		pod2 := test.UnschedulablePod(test.PodOptions{
+			ResourceRequirements: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				}},
+		})
+		node := test.Node(test.NodeOptions{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				v1.NodePoolLabelKey:            nodePool.Name,
+				corev1.LabelInstanceTypeStable: cloudProvider.InstanceTypes[0].Name,
+			}},
+			Allocatable: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			ProviderID: test.RandomProviderID(),
+		})
+		ExpectApplied(ctx, env.Client, pod1, pod2, node)
+		ExpectReconcileSucceeded(ctx, nodeController, client.ObjectKeyFromObject(node))
+		ExpectManualBinding(ctx, env.Client, pod1, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod1))
+
+		snapshot := cluster.SnapshotNodes()
+		Expect(snapshot).To(HaveLen(1))
+
+		ExpectManualBinding(ctx, env.Client, pod2, node)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod2))
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3.5")}, ExpectStateNodeExists(cluster, node).PodRequests())
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1.5")}, snapshot[0].PodRequests())
+
+		// A snapshot taken after the node was copied on write is unaffected by later deletions as well
+		snapshot = cluster.SnapshotNodes()
+		ExpectDeleted(ctx, env.Client, pod1)
+		ExpectReconcileSucceeded(ctx, podController, client.ObjectKeyFromObject(pod1))
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}, ExpectStateNodeExists(cluster, node).PodRequests())
+		ExpectResources(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3.5")}, snapshot[0].PodRequests())
+	})
 	It("should count existing pods bound to nodes", func() {
 		pod1 := test.UnschedulablePod(test.PodOptions{
 			ResourceRequirements: corev1.ResourceRequirements{
@@ -2891,3 +3020,20 @@ func ExpectStateNodeCount(comparator string, count int) int {
 	Expect(c).To(BeNumerically(comparator, count))
 	return c
 }
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []stream.Event
+}
+
+func (r *recordingPublisher) Publish(evt stream.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, evt)
+}
+
+func (r *recordingPublisher) Events() []stream.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]stream.Event{}, r.events...)
+}
@@ -178,6 +178,7 @@ func HasNodePoolReplicaCountChanged(oldNP, newNP *v1.NodePool) bool {
 // 1. Empty nodes (nodes with no pods or only DaemonSet pods without do-not-disrupt annotation)
 // 2. If more nodes needed, nodes with lowest disruption cost (nodes with pods that have do-not-disrupt will have highest cost)
 func (c *Controller) getDeprovisioningCandidates(ctx context.Context, np *v1.NodePool, nodes []*state.StateNode, count int) []*state.StateNode {
+	hasDoNotDisrupt := func(p *corev1.Pod) bool { return pod.HasDoNotDisrupt(p, c.clock) }
 	// First get empty nodes
 	emptyNodes := lo.Filter(nodes, func(node *state.StateNode, _ int) bool {
 		pods, err := node.Pods(ctx, c.kubeClient)
@@ -185,7 +186,7 @@ func (c *Controller) getDeprovisioningCandidates(ctx context.Context, np *v1.Nod
 			log.FromContext(ctx).WithValues("node", node.Name()).Error(err, "unable to list pods, treating as non-empty")
 			return false
 		}
-		return len(pods) == 0 || lo.EveryBy(pods, pod.IsOwnedByDaemonSet) && lo.NoneBy(pods, pod.HasDoNotDisrupt)
+		return len(pods) == 0 || lo.EveryBy(pods, pod.IsOwnedByDaemonSet) && lo.NoneBy(pods, hasDoNotDisrupt)
 	})
 
 	candidates := lo.Slice(emptyNodes, 0, count)
@@ -217,7 +218,7 @@ func (c *Controller) getDeprovisioningCandidates(ctx context.Context, np *v1.Nod
 		return NonEmptyNode{
 			node:            node,
 			pods:            pods,
-			hasDoNotDisrupt: lo.SomeBy(pods, pod.HasDoNotDisrupt),
+			hasDoNotDisrupt: lo.SomeBy(pods, hasDoNotDisrupt),
 		}, true
 	})
 
@@ -20,7 +20,10 @@ package events
 const (
 	// disruption
 	DisruptionBlocked          = "DisruptionBlocked"
+	DisruptionDryRun           = "DisruptionDryRun"
 	DisruptionLaunching        = "DisruptionLaunching"
+	DisruptionRebooted         = "DisruptionRebooted"
+	DisruptionRolledBack       = "DisruptionRolledBack"
 	DisruptionTerminating      = "DisruptionTerminating"
 	DisruptionWaitingReadiness = "DisruptionWaitingReadiness"
 	Unconsolidatable           = "Unconsolidatable"
@@ -33,18 +36,51 @@ const (
 	// node/health
 	NodeRepairBlocked = "NodeRepairBlocked"
 
+	// node/adoption
+	Adopted        = "Adopted"
+	AdoptionFailed = "AdoptionFailed"
+
 	// node/termination/terminator
 	Disrupted                      = "Disrupted"
 	Evicted                        = "Evicted"
+	EvictionDeferred               = "EvictionDeferred"
+	EvictionStarted                = "EvictionStarted"
+	EvictionBlocked                = "EvictionBlocked"
 	FailedDraining                 = "FailedDraining"
+	DrainedWithStaticPods          = "DrainedWithStaticPods"
 	TerminationGracePeriodExpiring = "TerminationGracePeriodExpiring"
+	TerminationGracePeriodElapsed  = "TerminationGracePeriodElapsed"
+	ForceDeleted                   = "ForceDeleted"
+	ForcedDrainCompleted           = "ForcedDrainCompleted"
+	VolumeDetachmentTimedOut       = "VolumeDetachmentTimedOut"
+	FailedPreTermination           = "FailedPreTermination"
+	PreTerminationTimedOut         = "PreTerminationTimedOut"
+	AwaitingPreDrainHooks          = "AwaitingPreDrainHooks"
+	Decommissioning                = "Decommissioning"
+	AwaitingTerminationHolds       = "AwaitingTerminationHolds"
+	TerminationHoldsTimedOut       = "TerminationHoldsTimedOut"
 	TerminationFailed              = "FailedTermination"
 
 	// nodeclaim/consistency
 	FailedConsistencyCheck = "FailedConsistencyCheck"
 
+	// nodeclaim/expiration
+	Expiring = "Expiring"
+
+	// nodeclaim/interruption
+	Interrupted = "Interrupted"
+
 	// nodeclaim/lifecycle
-	InsufficientCapacityError = "InsufficientCapacityError"
-	UnregisteredTaintMissing  = "UnregisteredTaintMissing"
-	NodeClassNotReady         = "NodeClassNotReady"
+	InsufficientCapacityError      = "InsufficientCapacityError"
+	UnregisteredTaintMissing       = "UnregisteredTaintMissing"
+	NodeClassNotReady              = "NodeClassNotReady"
+	NominatedNodeClaimLaunchFailed = "NominatedNodeClaimLaunchFailed"
+	LaunchFallback                 = "LaunchFallback"
+	StartupTaintsTimedOut          = "StartupTaintsTimedOut"
+	StartupTaintsForceRemoved      = "StartupTaintsForceRemoved"
+	NodeVerificationFailed         = "NodeVerificationFailed"
+
+	// nodepool/provisioningfailure
+	ProvisioningRequirementsWidened = "ProvisioningRequirementsWidened"
+	ProvisioningEscalated           = "ProvisioningEscalated"
 )
@@ -35,6 +35,7 @@ const (
 	ProvisionedReason = "provisioned"
 	ExpiredReason     = "expired"
 	UnhealthyReason   = "unhealthy"
+	InterruptedReason = "interrupted"
 )
 
 // DurationBuckets returns a []float64 of default threshold values for duration histograms.
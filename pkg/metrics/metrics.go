@@ -71,6 +71,20 @@ var (
 			CapacityTypeLabel,
 		},
 	)
+	NodeClaimsGarbageCollectedTotal = opmetrics.NewPrometheusCounter(
+		crmetrics.Registry,
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: NodeClaimSubsystem,
+			Name:      "garbage_collected_total",
+			Help:      "Number of nodeclaims garbage collected in total by Karpenter because their node never became usable or their instance disappeared. Labeled by the root cause recorded on the GarbageCollected status condition and the owning nodepool.",
+		},
+		[]string{
+			ReasonLabel,
+			NodePoolLabel,
+			CapacityTypeLabel,
+		},
+	)
 	NodesCreatedTotal = opmetrics.NewPrometheusCounter(
 		crmetrics.Registry,
 		prometheus.CounterOpts{
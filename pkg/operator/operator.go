@@ -62,6 +62,7 @@ import (
 	"sigs.k8s.io/karpenter/pkg/operator/logging"
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/utils/env"
+	"sigs.k8s.io/karpenter/pkg/utils/readonly"
 )
 
 var AppName = "karpenter"
@@ -123,6 +124,10 @@ func NewOperator(o ...option.Function[Options]) (context.Context, *Operator) {
 	// Options
 	ctx = injection.WithOptionsOrDie(ctx, options.Injectables...)
 
+	// Read-only mode is cached across the controllers, since it's checked by every cloud provider mutation
+	readOnlyCache := readonly.NewCache(clock.RealClock{})
+	ctx = readonly.ToContext(ctx, readOnlyCache)
+
 	// Make the Karpenter binary aware of the container memory limit
 	// https://pkg.go.dev/runtime/debug#SetMemoryLimit
 	if options.FromContext(ctx).MemoryLimit > 0 {
@@ -170,6 +175,7 @@ func NewOperator(o ...option.Function[Options]) (context.Context, *Operator) {
 		BaseContext: func() context.Context {
 			ctx := log.IntoContext(context.Background(), logger)
 			ctx = injection.WithOptionsOrDie(ctx, options.Injectables...)
+			ctx = readonly.ToContext(ctx, readOnlyCache)
 			return ctx
 		},
 		Cache: cache.Options{
@@ -21,13 +21,19 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	cliflag "k8s.io/component-base/cli/flag"
 
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/utils/env"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 type PreferencePolicy string
@@ -44,8 +50,48 @@ const (
 	MinValuesPolicyBestEffort MinValuesPolicy = "BestEffort"
 )
 
+type LocalStoragePolicy string
+
+const (
+	LocalStoragePolicySkip         LocalStoragePolicy = "Skip"
+	LocalStoragePolicyDeprioritize LocalStoragePolicy = "Deprioritize"
+)
+
+type RequestlessPodPolicy string
+
+const (
+	RequestlessPodPolicyAllow      RequestlessPodPolicy = "Allow"
+	RequestlessPodPolicyReject     RequestlessPodPolicy = "Reject"
+	RequestlessPodPolicyDefault    RequestlessPodPolicy = "Default"
+	RequestlessPodPolicyLimitRange RequestlessPodPolicy = "LimitRange"
+)
+
+type StartupTaintTimeoutPolicy string
+
+const (
+	StartupTaintTimeoutPolicyRemove  StartupTaintTimeoutPolicy = "Remove"
+	StartupTaintTimeoutPolicyReplace StartupTaintTimeoutPolicy = "Replace"
+)
+
+type DriftOrdering string
+
+const (
+	DriftOrderingOldestFirst     DriftOrdering = "OldestFirst"
+	DriftOrderingFewestPodsFirst DriftOrdering = "FewestPodsFirst"
+	DriftOrderingCheapestFirst   DriftOrdering = "CheapestFirst"
+)
+
+// NodeRepairCondition is a Node condition that node repair treats as unhealthy, in addition to the RepairPolicies of the
+// cloud provider
+type NodeRepairCondition struct {
+	Type               corev1.NodeConditionType
+	Status             corev1.ConditionStatus
+	TolerationDuration time.Duration
+}
+
 var (
 	validLogLevels          = []string{"", "debug", "info", "error"}
+	validDisruptionReasons  = lo.Map(v1.WellKnownDisruptionReasons, func(r v1.DisruptionReason, _ int) string { return string(r) })
 	validPreferencePolicies = []PreferencePolicy{PreferencePolicyIgnore, PreferencePolicyRespect}
 
 	Injectables = []Injectable{&Options{}}
@@ -61,6 +107,8 @@ type FeatureGates struct {
 	SpotToSpotConsolidation bool
 	NodeOverlay             bool
 	StaticCapacity          bool
+	NodeRightsizing         bool
+	NominatedPods           bool
 }
 
 // Options contains all CLI flags / env vars for karpenter-core. It adheres to the options.Injectable interface.
@@ -87,6 +135,64 @@ type Options struct {
 	minValuesPolicyRaw               string
 	MinValuesPolicy                  MinValuesPolicy
 	IgnoreDRARequests                bool // NOTE: This flag will be removed once formal DRA support is GA in Karpenter.
+	RightsizingHeadroomPercent       int
+	StateStreamWebhookURL            string
+	localStorageThresholdRaw         string
+	LocalStorageThreshold            resource.Quantity
+	localStoragePolicyRaw            string
+	LocalStoragePolicy               LocalStoragePolicy
+	ProvisioningFailureThreshold     int
+	DisruptionDecisionRetention      time.Duration
+	DriftBatchSize                   int
+	driftOrderingRaw                 string
+	DriftOrdering                    DriftOrdering
+	EvictionPrecheck                 bool
+	NodePoolAPIQPS                   int
+	NodePoolAPIBurst                 int
+	NodeClaimGCMinAge                time.Duration
+	NodeClaimGCConfirmations         int
+	requestlessPodPolicyRaw          string
+	RequestlessPodPolicy             RequestlessPodPolicy
+	requestlessPodDefaultsRaw        string
+	RequestlessPodDefaultRequests    corev1.ResourceList
+	HighCardinalityMetrics           bool
+	ExpirationJitterPercent          int
+	ExpirationWarningDuration        time.Duration
+	ExpirationReplacement            bool
+	ReadOnlyModeFile                 string
+	WorkloadAffinityWeight           int
+	DisruptionCommandMaxFailures     int
+	DisruptionDeadLetterTTL          time.Duration
+	PriceChangeThreshold             int
+	PriceChangeMinInterval           time.Duration
+	InteractiveSessionGracePeriod    time.Duration
+	PreDrainHookTimeout              time.Duration
+	EvictionConcurrencyPerNode       int
+	EvictionIntervalPerNode          time.Duration
+	VolumeDetachmentWait             bool
+	VolumeDetachmentTimeout          time.Duration
+	PreTerminateTimeout              time.Duration
+	LaunchFallbackAttempts           int
+	StartupTaintTimeout              time.Duration
+	startupTaintTimeoutPolicyRaw     string
+	StartupTaintTimeoutPolicy        StartupTaintTimeoutPolicy
+	OrphanedInstanceDeletion         bool
+	OrphanedInstanceMinAge           time.Duration
+	PreDrainNotificationWindow       time.Duration
+	TerminationHoldTimeout           time.Duration
+	DisruptionDryRun                 bool
+	disruptionReasonPriorityRaw      string
+	DisruptionReasonPriority         []string
+	DisruptionReplacementTimeout     time.Duration
+	DisruptionRateLimit              int
+	DisruptionRateLimitPeriod        time.Duration
+	ShardCount                       int
+	ShardIndex                       int
+	ClusterAutoscalerCompatibility   bool
+	DisruptionCandidateLimit         int
+	nodeRepairConditionsRaw          string
+	NodeRepairConditions             []NodeRepairCondition
+	GracefulNodeRepair               bool
 	FeatureGates                     FeatureGates
 }
 
@@ -128,7 +234,57 @@ func (o *Options) AddFlags(fs *FlagSet) {
 	fs.StringVar(&o.preferencePolicyRaw, "preference-policy", env.WithDefaultString("PREFERENCE_POLICY", string(PreferencePolicyRespect)), "How the Karpenter scheduler should treat preferences. Preferences include preferredDuringSchedulingIgnoreDuringExecution node and pod affinities/anti-affinities and ScheduleAnyways topologySpreadConstraints. Can be one of 'Ignore' and 'Respect'")
 	fs.StringVar(&o.minValuesPolicyRaw, "min-values-policy", env.WithDefaultString("MIN_VALUES_POLICY", string(MinValuesPolicyStrict)), "Min values policy for scheduling. Options include 'Strict' for existing behavior where min values are strictly enforced or 'BestEffort' where Karpenter relaxes min values when it isn't satisfied.")
 	fs.BoolVarWithEnv(&o.IgnoreDRARequests, "ignore-dra-requests", "IGNORE_DRA_REQUESTS", true, "When set, Karpenter will ignore pods' DRA requests during scheduling simulations. NOTE: This flag will be removed once formal DRA support is GA in Karpenter.")
-	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "NodeRepair=false,ReservedCapacity=true,SpotToSpotConsolidation=false,NodeOverlay=false,StaticCapacity=false"), "Optional features can be enabled / disabled using feature gates. Current options are: NodeRepair, ReservedCapacity, SpotToSpotConsolidation, NodeOverlay, and StaticCapacity.")
+	fs.IntVar(&o.RightsizingHeadroomPercent, "rightsizing-headroom-percent", env.WithDefaultInt("RIGHTSIZING_HEADROOM_PERCENT", 10), "The percentage of headroom added on top of a node's current pod requests when computing rightsizing recommendations. Only used when the NodeRightsizing feature gate is enabled.")
+	fs.StringVar(&o.StateStreamWebhookURL, "state-stream-webhook-url", env.WithDefaultString("STATE_STREAM_WEBHOOK_URL", ""), "Optional HTTP(S) endpoint that receives batched JSON notifications of cluster state changes, such as nodes being added or removed, nodeclaim phase changes, and disruption command lifecycle. The stream is disabled when unset.")
+	fs.StringVar(&o.localStorageThresholdRaw, "consolidation-local-storage-threshold", env.WithDefaultString("CONSOLIDATION_LOCAL_STORAGE_THRESHOLD", ""), "Optional amount of node-local storage (e.g. 10Gi), summed across the emptyDir and hostPath backed pods on a node, above which the node is treated specially by consolidation according to the local storage policy. Disabled when unset.")
+	fs.StringVar(&o.localStoragePolicyRaw, "consolidation-local-storage-policy", env.WithDefaultString("CONSOLIDATION_LOCAL_STORAGE_POLICY", string(LocalStoragePolicySkip)), "How consolidation treats nodes whose pods exceed the local storage threshold. Can be one of 'Skip', where the nodes are never consolidated, or 'Deprioritize', where the nodes are only considered after all other candidates.")
+	fs.IntVar(&o.ProvisioningFailureThreshold, "provisioning-failure-threshold", env.WithDefaultInt("PROVISIONING_FAILURE_THRESHOLD", 0), "The number of consecutive provisioning failures on a NodePool after which Karpenter automatically widens its instance-type requirements, or raises the weight of its fallback NodePool when no requirement can be widened. Disabled when set to 0.")
+	fs.DurationVar(&o.DisruptionDecisionRetention, "disruption-decision-retention", env.WithDefaultDuration("DISRUPTION_DECISION_RETENTION", 0), "How long DisruptionDecision audit records of executed disruption commands are kept before they are garbage collected. Recording is disabled when set to 0.")
+	fs.IntVar(&o.DriftBatchSize, "drift-batch-size", env.WithDefaultInt("DRIFT_BATCH_SIZE", 1), "The maximum number of non-empty drifted nodes that Karpenter disrupts together in a single command, bounded by the NodePool disruption budgets. Increasing this rolls large fleets faster after a NodeClass change.")
+	fs.StringVar(&o.driftOrderingRaw, "drift-ordering", env.WithDefaultString("DRIFT_ORDERING", string(DriftOrderingOldestFirst)), "The order in which drifted nodes are disrupted. Can be one of 'OldestFirst', where the nodes that drifted earliest go first, 'FewestPodsFirst', where the nodes with the fewest reschedulable pods go first, or 'CheapestFirst', where the cheapest nodes go first. Empty drifted nodes are always disrupted before non-empty nodes.")
+	fs.BoolVarWithEnv(&o.EvictionPrecheck, "eviction-precheck", "EVICTION_PRECHECK", false, "Issue dry-run evictions for the pods of every disruption command before executing it. Candidates with pods that would be denied eviction by a PodDisruptionBudget or an admission webhook are removed from the command, or the command is skipped. Consolidation candidates with such pods also fail validation.")
+	fs.IntVar(&o.NodePoolAPIQPS, "nodepool-api-qps", env.WithDefaultInt("NODEPOOL_API_QPS", 0), "The smoothed rate of launch, describe and terminate cloud provider calls that each NodePool may make. Calls over this rate are throttled so that a single NodePool's churn can't consume the rate limits of the whole account. A value of 0 disables per-NodePool budgeting.")
+	fs.IntVar(&o.NodePoolAPIBurst, "nodepool-api-burst", env.WithDefaultInt("NODEPOOL_API_BURST", 10), "The maximum burst of launch, describe and terminate cloud provider calls that each NodePool may make. Only used when nodepool-api-qps is set.")
+	fs.DurationVar(&o.NodeClaimGCMinAge, "nodeclaim-gc-min-age", env.WithDefaultDuration("NODECLAIM_GC_MIN_AGE", 30*time.Second), "The minimum age of a NodeClaim before it can be garbage collected because its instance is missing from the cloud provider. Protects just-launched instances from eventually consistent cloud provider List responses.")
+	fs.IntVar(&o.NodeClaimGCConfirmations, "nodeclaim-gc-confirmations", env.WithDefaultInt("NODECLAIM_GC_CONFIRMATIONS", 2), "The number of consecutive garbage collection passes that must find a NodeClaim's instance missing from the cloud provider before the NodeClaim is garbage collected.")
+	fs.StringVar(&o.requestlessPodPolicyRaw, "requestless-pod-policy", env.WithDefaultString("REQUESTLESS_POD_POLICY", string(RequestlessPodPolicyAllow)), "How provisioning treats pods whose containers don't request cpu or memory. Can be one of 'Allow', where the pods are scheduled as if they need no resources, 'Reject', where the pods are ignored, 'Default', where the pods are assumed to request the namespace's karpenter.sh/default-pod-requests annotation or the requestless-pod-default-requests, or 'LimitRange', where the pods are assumed to request the default requests of the namespace's LimitRanges.")
+	fs.StringVar(&o.requestlessPodDefaultsRaw, "requestless-pod-default-requests", env.WithDefaultString("REQUESTLESS_POD_DEFAULT_REQUESTS", "cpu=100m,memory=128Mi"), "The requests assumed for each container of a pod without requests when the requestless-pod-policy is 'Default' or 'LimitRange' and the namespace doesn't define its own, as a comma separated list of name=quantity pairs.")
+	fs.BoolVarWithEnv(&o.HighCardinalityMetrics, "high-cardinality-metrics", "HIGH_CARDINALITY_METRICS", true, "Emit metrics that have a series per node or nodeclaim. Disable on large clusters to only emit the aggregated per-nodepool alternatives of these metrics.")
+	fs.IntVar(&o.ExpirationJitterPercent, "expiration-jitter-percent", env.WithDefaultInt("EXPIRATION_JITTER_PERCENT", 0), "The maximum percentage of a NodeClaim's expireAfter by which its expiration is brought forward. Each NodeClaim gets a stable jitter within this range so that nodes created together don't all expire at once. Must be between 0 and 100.")
+	fs.DurationVar(&o.ExpirationWarningDuration, "expiration-warning-duration", env.WithDefaultDuration("EXPIRATION_WARNING_DURATION", 0), "How long before a NodeClaim's expireAfter elapses that Karpenter emits Expiring events to the NodeClaim, its Node, and the pods running on the Node so that workloads can checkpoint. Warnings are disabled when set to 0.")
+	fs.BoolVarWithEnv(&o.ExpirationReplacement, "expiration-replacement", "EXPIRATION_REPLACEMENT", false, "Launch replacements for the reschedulable pods of expired NodeClaims and wait for them to register before deleting the expired NodeClaim, so that its pods don't drain into a capacity gap. Replacements are sized by the provisioning scheduler, and aren't launched for pods that fit on the rest of the cluster.")
+	fs.StringVar(&o.ReadOnlyModeFile, "read-only-mode-file", env.WithDefaultString("READ_ONLY_MODE_FILE", ""), "Optional path to a file that switches Karpenter into read-only mode while it contains 'true'. In read-only mode Karpenter maintains cluster state, detects drift and emptiness and emits metrics, but doesn't create or delete capacity. The file is re-read every 10 seconds so that the mode can be switched at runtime, e.g. by updating a mounted ConfigMap. A file that can't be read or doesn't contain 'true' or 'false' keeps Karpenter in read-only mode.")
+	fs.IntVar(&o.WorkloadAffinityWeight, "workload-affinity-weight", env.WithDefaultInt("WORKLOAD_AFFINITY_WEIGHT", 0), "How strongly provisioning prefers placing a pod on an existing node that already runs pods of the same workload (the pods' controller), to reuse warm image and cache state. Each pod of the workload on a node moves the node this many places forward in the order that existing nodes are tried in. Disabled when set to 0.")
+	fs.IntVar(&o.DisruptionCommandMaxFailures, "disruption-command-max-failures", env.WithDefaultInt("DISRUPTION_COMMAND_MAX_FAILURES", 0), "The number of failed disruption commands, e.g. because a replacement failed to launch or initialize, after which a candidate is moved to a dead-letter list and no longer disrupted. Dead-lettered candidates are exposed on the /debug/disruption/dead-letters endpoint of the metrics server and are retried when the karpenter.sh/disruption-retry-requested annotation on their NodeClaim is set to a new value. Disabled when set to 0.")
+	fs.DurationVar(&o.DisruptionDeadLetterTTL, "disruption-dead-letter-ttl", env.WithDefaultDuration("DISRUPTION_DEAD_LETTER_TTL", 0), "How long a candidate stays on the disruption dead-letter list before it's retried. When set to 0, candidates are only retried on request.")
+	fs.IntVar(&o.PriceChangeThreshold, "price-change-threshold", env.WithDefaultInt("PRICE_CHANGE_THRESHOLD", 0), "The percentage by which the price of an offering has to change for Karpenter to evaluate consolidation for the affected NodePools immediately, rather than waiting for the next periodic evaluation. Disabled when set to 0.")
+	fs.DurationVar(&o.PriceChangeMinInterval, "price-change-min-interval", env.WithDefaultDuration("PRICE_CHANGE_MIN_INTERVAL", time.Minute), "The minimum time between consolidation evaluations triggered by price changes, so that volatile pricing doesn't cause consolidation to thrash.")
+	fs.DurationVar(&o.InteractiveSessionGracePeriod, "interactive-session-grace-period", env.WithDefaultDuration("INTERACTIVE_SESSION_GRACE_PERIOD", 0), "How long draining a node defers the eviction of pods with active interactive sessions, e.g. running debug containers or pods whose karpenter.sh/interactive-session-until annotation is in the future. The window starts when the node begins terminating and never extends past the node's terminationGracePeriod. Disabled when set to 0.")
+	fs.DurationVar(&o.PreDrainHookTimeout, "pre-drain-hook-timeout", env.WithDefaultDuration("PRE_DRAIN_HOOK_TIMEOUT", 10*time.Minute), "How long the termination of a node waits for the pre-drain hooks registered on its NodeClaim through pre-drain.karpenter.sh/<hook> annotations to be removed before it starts evicting pods. The window starts when the node begins terminating and never extends past the node's terminationGracePeriod. When set to 0, hooks are waited on until the terminationGracePeriod elapses.")
+	fs.IntVar(&o.EvictionConcurrencyPerNode, "eviction-concurrency-per-node", env.WithDefaultInt("EVICTION_CONCURRENCY_PER_NODE", 0), "The maximum number of pods that draining a node evicts at a time. Pods count against the limit from when they're queued for eviction until they've terminated, so that draining large nodes doesn't overwhelm the scheduler and the image pulls of the nodes their pods move to. Disabled when set to 0.")
+	fs.DurationVar(&o.EvictionIntervalPerNode, "eviction-interval-per-node", env.WithDefaultDuration("EVICTION_INTERVAL_PER_NODE", 0), "The minimum time between two pod evictions when draining a node. Disabled when set to 0.")
+	fs.BoolVarWithEnv(&o.VolumeDetachmentWait, "volume-detachment-wait", "VOLUME_DETACHMENT_WAIT", true, "Wait for the VolumeAttachments of a drained node's pods to be deleted before terminating its instance, so that the volumes are cleanly detached before they're attached to the nodes the pods move to.")
+	fs.DurationVar(&o.VolumeDetachmentTimeout, "volume-detachment-timeout", env.WithDefaultDuration("VOLUME_DETACHMENT_TIMEOUT", 0), "How long the termination of a node waits for VolumeAttachments to be deleted after the node has drained before terminating its instance anyway. When set to 0, the termination waits until the node's terminationGracePeriod elapses, indefinitely for nodes without one.")
+	fs.DurationVar(&o.PreTerminateTimeout, "pre-terminate-timeout", env.WithDefaultDuration("PRE_TERMINATE_TIMEOUT", 5*time.Minute), "How long the termination of a node waits for the cloud provider to prepare its instance for termination, e.g. by deregistering it from load balancers, before terminating the instance anyway. Only used by cloud providers that implement pre-termination. When set to 0, the termination waits until the node's terminationGracePeriod elapses.")
+	fs.IntVar(&o.LaunchFallbackAttempts, "launch-fallback-attempts", env.WithDefaultInt("LAUNCH_FALLBACK_ATTEMPTS", 3), "The number of times a NodeClaim launch that fails with insufficient capacity is retried without the offerings that lacked capacity before the NodeClaim is deleted. Only used when the cloud provider reports which offerings lacked capacity. Disabled when set to 0.")
+	fs.DurationVar(&o.StartupTaintTimeout, "startup-taint-timeout", env.WithDefaultDuration("STARTUP_TAINT_TIMEOUT", 0), "How long after a node registers the agents that own its NodeClaim's startup taints have to remove them before Karpenter acts according to the startup-taint-timeout-policy. Disabled when set to 0.")
+	fs.StringVar(&o.startupTaintTimeoutPolicyRaw, "startup-taint-timeout-policy", env.WithDefaultString("STARTUP_TAINT_TIMEOUT_POLICY", string(StartupTaintTimeoutPolicyReplace)), "What Karpenter does with a node whose startup taints weren't removed within the startup-taint-timeout. Can be one of 'Remove', where Karpenter removes the startup taints itself and lets the node initialize, or 'Replace', where the NodeClaim is marked Unhealthy and replaced through node repair.")
+	fs.BoolVarWithEnv(&o.OrphanedInstanceDeletion, "orphaned-instance-deletion", "ORPHANED_INSTANCE_DELETION", false, "Delete the instances attributed to the cluster that have no corresponding NodeClaim or Node, e.g. instances leaked by a controller crash during a launch. When disabled, orphaned instances are only logged and reported through metrics. Only used by cloud providers that can list the instances of the cluster.")
+	fs.DurationVar(&o.OrphanedInstanceMinAge, "orphaned-instance-min-age", env.WithDefaultDuration("ORPHANED_INSTANCE_MIN_AGE", 10*time.Minute), "The minimum age of an instance without a NodeClaim or Node before it's considered orphaned. Protects instances whose launch hasn't been recorded on their NodeClaim yet.")
+	fs.DurationVar(&o.PreDrainNotificationWindow, "pre-drain-notification-window", env.WithDefaultDuration("PRE_DRAIN_NOTIFICATION_WINDOW", 0), "How long the termination of a node keeps its pods running after it's tainted with karpenter.sh/decommissioning:NoSchedule and before it starts evicting them, so that pods with graceful shutdown hooks and external systems can react to the upcoming drain. The window starts when the node begins terminating and never extends past the node's terminationGracePeriod. Disabled when set to 0.")
+	fs.DurationVar(&o.TerminationHoldTimeout, "termination-hold-timeout", env.WithDefaultDuration("TERMINATION_HOLD_TIMEOUT", time.Hour), "How long the termination of a drained node waits for the termination holds placed on its NodeClaim through termination-hold.karpenter.sh/<hold> finalizers to be removed before it terminates the instance. Holds that time out are removed by Karpenter. The window never extends past the node's terminationGracePeriod. When set to 0, holds are waited on until the terminationGracePeriod elapses.")
+	fs.BoolVarWithEnv(&o.DisruptionDryRun, "disruption-dry-run", "DISRUPTION_DRY_RUN", false, "Evaluate every disruption method, but record the resulting commands as events, metrics and DisruptionDecisions instead of executing them. NodePools can override this with spec.disruption.dryRun.")
+	fs.StringVar(&o.disruptionReasonPriorityRaw, "disruption-reason-priority", env.WithDefaultString("DISRUPTION_REASON_PRIORITY", ""), "Optional comma separated list of disruption reasons, e.g. 'Drifted,Empty,Underutilized', in the order that they're evaluated. While a reason's commands have exhausted a NodePool's budget for that reason, reasons later in the list don't disrupt the NodePool's nodes. Reasons that aren't listed are evaluated last in their default order. Can include 'Requested', 'Unhealthy', 'Empty', 'Drifted' and 'Underutilized'.")
+	fs.DurationVar(&o.DisruptionReplacementTimeout, "disruption-replacement-timeout", env.WithDefaultDuration("DISRUPTION_REPLACEMENT_TIMEOUT", 0), "How long a disruption command waits for its replacements to launch and initialize before it's rolled back. Rolling back a command untaints its candidates and deletes the replacements that didn't initialize. When set to 0, commands wait up to the orchestration queue's retry duration of 10 minutes to 1 hour.")
+	fs.IntVar(&o.DisruptionRateLimit, "disruption-rate-limit", env.WithDefaultInt("DISRUPTION_RATE_LIMIT", 0), "The maximum number of nodes that Karpenter disrupts per disruption-rate-limit-period across all NodePools and disruption reasons, on top of the NodePool disruption budgets. Protects infrastructure shared by the whole cluster, e.g. DNS and image registries, during large rollouts. The limit is shared by every shard and leader through the karpenter-disruption-rate-limit Lease in the leader election namespace. Disabled when set to 0.")
+	fs.DurationVar(&o.DisruptionRateLimitPeriod, "disruption-rate-limit-period", env.WithDefaultDuration("DISRUPTION_RATE_LIMIT_PERIOD", 5*time.Minute), "The period over which disruption-rate-limit nodes can be disrupted. Only used when disruption-rate-limit is set.")
+	fs.IntVar(&o.ShardCount, "shard-count", env.WithDefaultInt("SHARD_COUNT", 1), "The number of shards that the disruption and provisioning work is split across. NodePools are assigned to shards by the hash of their name. The provisioning and disruption loops of each shard are run by the leader of the shard's own lease, while every other controller is run by the leader of the leader-election-name lease, so run at least shard-count replicas with distinct shard-index values.")
+	fs.IntVar(&o.ShardIndex, "shard-index", env.WithDefaultInt("SHARD_INDEX", 0), "The shard that this replica evaluates, from 0 to shard-count - 1. Pods that don't require a single NodePool are assigned to shards by the hash of their owner.")
+	fs.BoolVarWithEnv(&o.ClusterAutoscalerCompatibility, "cluster-autoscaler-compatibility", "CLUSTER_AUTOSCALER_COMPATIBILITY", false, "Honor the cluster-autoscaler.kubernetes.io/safe-to-evict=false pod annotation and the cluster-autoscaler.kubernetes.io/scale-down-disabled=true node annotation like karpenter.sh/do-not-disrupt. Eases migrations from cluster-autoscaler.")
+	fs.IntVar(&o.DisruptionCandidateLimit, "disruption-candidate-limit", env.WithDefaultInt("DISRUPTION_CANDIDATE_LIMIT", 0), "The maximum number of nodes that each disruption method builds candidates for per evaluation. Methods take the next nodes in name order on each evaluation, wrapping around, so that every node is eventually evaluated while large clusters still finish an evaluation within the polling period. Disabled when set to 0.")
+	fs.StringVar(&o.nodeRepairConditionsRaw, "node-repair-conditions", env.WithDefaultString("NODE_REPAIR_CONDITIONS", ""), "Optional comma separated list of Node conditions that node repair treats as unhealthy, in addition to the repair policies of the cloud provider, as type=status:toleration entries, e.g. 'Ready=False:30m,DiskPressure=True:10m'. Nodes that have had one of the conditions for longer than its toleration are repaired. Only used when the NodeRepair feature gate is enabled.")
+	fs.BoolVarWithEnv(&o.GracefulNodeRepair, "graceful-node-repair", "GRACEFUL_NODE_REPAIR", false, "Replace unhealthy nodes through disruption before deleting them, bounded by the NodePool disruption budgets for the 'Unhealthy' reason and by karpenter.sh/do-not-disrupt. When disabled, node repair forcefully deletes unhealthy nodes regardless of budgets and do-not-disrupt. Only used when the NodeRepair feature gate is enabled.")
+	fs.StringVar(&o.FeatureGates.inputStr, "feature-gates", env.WithDefaultString("FEATURE_GATES", "NodeRepair=false,ReservedCapacity=true,SpotToSpotConsolidation=false,NodeOverlay=false,StaticCapacity=false,NodeRightsizing=false,NominatedPods=false"), "Optional features can be enabled / disabled using feature gates. Current options are: NodeRepair, ReservedCapacity, SpotToSpotConsolidation, NodeOverlay, StaticCapacity, NodeRightsizing, and NominatedPods.")
 }
 
 func (o *Options) Parse(fs *FlagSet, args ...string) error {
@@ -147,6 +303,142 @@ func (o *Options) Parse(fs *FlagSet, args ...string) error {
 	if !lo.Contains([]MinValuesPolicy{MinValuesPolicyStrict, MinValuesPolicyBestEffort}, MinValuesPolicy(o.minValuesPolicyRaw)) {
 		return fmt.Errorf("validating cli flags / env vars, invalid MIN_VALUES_POLICY %q", o.minValuesPolicyRaw)
 	}
+	if o.RightsizingHeadroomPercent < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid RIGHTSIZING_HEADROOM_PERCENT %d, must be non-negative", o.RightsizingHeadroomPercent)
+	}
+	if o.StateStreamWebhookURL != "" {
+		if u, err := url.ParseRequestURI(o.StateStreamWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("validating cli flags / env vars, invalid STATE_STREAM_WEBHOOK_URL %q, must be an http or https URL", o.StateStreamWebhookURL)
+		}
+	}
+	if o.localStorageThresholdRaw != "" {
+		threshold, err := resource.ParseQuantity(o.localStorageThresholdRaw)
+		if err != nil || threshold.Sign() < 0 {
+			return fmt.Errorf("validating cli flags / env vars, invalid CONSOLIDATION_LOCAL_STORAGE_THRESHOLD %q", o.localStorageThresholdRaw)
+		}
+		o.LocalStorageThreshold = threshold
+	}
+	if !lo.Contains([]LocalStoragePolicy{LocalStoragePolicySkip, LocalStoragePolicyDeprioritize}, LocalStoragePolicy(o.localStoragePolicyRaw)) {
+		return fmt.Errorf("validating cli flags / env vars, invalid CONSOLIDATION_LOCAL_STORAGE_POLICY %q", o.localStoragePolicyRaw)
+	}
+	if o.ProvisioningFailureThreshold < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid PROVISIONING_FAILURE_THRESHOLD %d, must be non-negative", o.ProvisioningFailureThreshold)
+	}
+	if o.DisruptionDecisionRetention < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_DECISION_RETENTION %s, must be non-negative", o.DisruptionDecisionRetention)
+	}
+	if o.DriftBatchSize < 1 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DRIFT_BATCH_SIZE %d, must be at least 1", o.DriftBatchSize)
+	}
+	if !lo.Contains([]DriftOrdering{DriftOrderingOldestFirst, DriftOrderingFewestPodsFirst, DriftOrderingCheapestFirst}, DriftOrdering(o.driftOrderingRaw)) {
+		return fmt.Errorf("validating cli flags / env vars, invalid DRIFT_ORDERING %q", o.driftOrderingRaw)
+	}
+	if o.NodePoolAPIQPS < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid NODEPOOL_API_QPS %d, must be non-negative", o.NodePoolAPIQPS)
+	}
+	if o.NodePoolAPIBurst < 1 {
+		return fmt.Errorf("validating cli flags / env vars, invalid NODEPOOL_API_BURST %d, must be at least 1", o.NodePoolAPIBurst)
+	}
+	if o.NodeClaimGCMinAge < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid NODECLAIM_GC_MIN_AGE %s, must be non-negative", o.NodeClaimGCMinAge)
+	}
+	if o.NodeClaimGCConfirmations < 1 {
+		return fmt.Errorf("validating cli flags / env vars, invalid NODECLAIM_GC_CONFIRMATIONS %d, must be at least 1", o.NodeClaimGCConfirmations)
+	}
+	if !lo.Contains([]RequestlessPodPolicy{RequestlessPodPolicyAllow, RequestlessPodPolicyReject, RequestlessPodPolicyDefault, RequestlessPodPolicyLimitRange}, RequestlessPodPolicy(o.requestlessPodPolicyRaw)) {
+		return fmt.Errorf("validating cli flags / env vars, invalid REQUESTLESS_POD_POLICY %q", o.requestlessPodPolicyRaw)
+	}
+	if o.ExpirationJitterPercent < 0 || o.ExpirationJitterPercent > 100 {
+		return fmt.Errorf("validating cli flags / env vars, invalid EXPIRATION_JITTER_PERCENT %d, must be between 0 and 100", o.ExpirationJitterPercent)
+	}
+	if o.WorkloadAffinityWeight < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid WORKLOAD_AFFINITY_WEIGHT %d, must be non-negative", o.WorkloadAffinityWeight)
+	}
+	if o.DisruptionCommandMaxFailures < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_COMMAND_MAX_FAILURES %d, must be non-negative", o.DisruptionCommandMaxFailures)
+	}
+	if o.DisruptionDeadLetterTTL < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_DEAD_LETTER_TTL %s, must be non-negative", o.DisruptionDeadLetterTTL)
+	}
+	if o.PriceChangeThreshold < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid PRICE_CHANGE_THRESHOLD %d, must be non-negative", o.PriceChangeThreshold)
+	}
+	if o.PriceChangeMinInterval < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid PRICE_CHANGE_MIN_INTERVAL %s, must be non-negative", o.PriceChangeMinInterval)
+	}
+	if o.InteractiveSessionGracePeriod < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid INTERACTIVE_SESSION_GRACE_PERIOD %s, must be non-negative", o.InteractiveSessionGracePeriod)
+	}
+	if o.PreDrainHookTimeout < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid PRE_DRAIN_HOOK_TIMEOUT %s, must be non-negative", o.PreDrainHookTimeout)
+	}
+	if o.EvictionConcurrencyPerNode < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid EVICTION_CONCURRENCY_PER_NODE %d, must be non-negative", o.EvictionConcurrencyPerNode)
+	}
+	if o.EvictionIntervalPerNode < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid EVICTION_INTERVAL_PER_NODE %s, must be non-negative", o.EvictionIntervalPerNode)
+	}
+	if o.VolumeDetachmentTimeout < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid VOLUME_DETACHMENT_TIMEOUT %s, must be non-negative", o.VolumeDetachmentTimeout)
+	}
+	if o.PreTerminateTimeout < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid PRE_TERMINATE_TIMEOUT %s, must be non-negative", o.PreTerminateTimeout)
+	}
+	if o.LaunchFallbackAttempts < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid LAUNCH_FALLBACK_ATTEMPTS %d, must be non-negative", o.LaunchFallbackAttempts)
+	}
+	if o.StartupTaintTimeout < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid STARTUP_TAINT_TIMEOUT %s, must be non-negative", o.StartupTaintTimeout)
+	}
+	if !lo.Contains([]StartupTaintTimeoutPolicy{StartupTaintTimeoutPolicyRemove, StartupTaintTimeoutPolicyReplace}, StartupTaintTimeoutPolicy(o.startupTaintTimeoutPolicyRaw)) {
+		return fmt.Errorf("validating cli flags / env vars, invalid STARTUP_TAINT_TIMEOUT_POLICY %q", o.startupTaintTimeoutPolicyRaw)
+	}
+	if o.OrphanedInstanceMinAge < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid ORPHANED_INSTANCE_MIN_AGE %s, must be non-negative", o.OrphanedInstanceMinAge)
+	}
+	if o.PreDrainNotificationWindow < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid PRE_DRAIN_NOTIFICATION_WINDOW %s, must be non-negative", o.PreDrainNotificationWindow)
+	}
+	if o.TerminationHoldTimeout < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid TERMINATION_HOLD_TIMEOUT %s, must be non-negative", o.TerminationHoldTimeout)
+	}
+	if o.disruptionReasonPriorityRaw != "" {
+		o.DisruptionReasonPriority = strings.Split(o.disruptionReasonPriorityRaw, ",")
+		if reasons := lo.Without(o.DisruptionReasonPriority, validDisruptionReasons...); len(reasons) > 0 || len(lo.Uniq(o.DisruptionReasonPriority)) != len(o.DisruptionReasonPriority) {
+			return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_REASON_PRIORITY %q, must be a list of unique disruption reasons", o.disruptionReasonPriorityRaw)
+		}
+	}
+	if o.DisruptionReplacementTimeout < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_REPLACEMENT_TIMEOUT %s, must be non-negative", o.DisruptionReplacementTimeout)
+	}
+	if o.DisruptionRateLimit < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_RATE_LIMIT %d, must be non-negative", o.DisruptionRateLimit)
+	}
+	if o.DisruptionRateLimitPeriod <= 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_RATE_LIMIT_PERIOD %s, must be positive", o.DisruptionRateLimitPeriod)
+	}
+	if o.ShardCount < 1 {
+		return fmt.Errorf("validating cli flags / env vars, invalid SHARD_COUNT %d, must be positive", o.ShardCount)
+	}
+	if o.ShardIndex < 0 || o.ShardIndex >= o.ShardCount {
+		return fmt.Errorf("validating cli flags / env vars, invalid SHARD_INDEX %d, must be between 0 and SHARD_COUNT - 1", o.ShardIndex)
+	}
+	if o.DisruptionCandidateLimit < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid DISRUPTION_CANDIDATE_LIMIT %d, must be non-negative", o.DisruptionCandidateLimit)
+	}
+	if o.ExpirationWarningDuration < 0 {
+		return fmt.Errorf("validating cli flags / env vars, invalid EXPIRATION_WARNING_DURATION %s, must be non-negative", o.ExpirationWarningDuration)
+	}
+	conditions, err := ParseNodeRepairConditions(o.nodeRepairConditionsRaw)
+	if err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid NODE_REPAIR_CONDITIONS %q, %w", o.nodeRepairConditionsRaw, err)
+	}
+	o.NodeRepairConditions = conditions
+	defaults, err := resources.Parse(o.requestlessPodDefaultsRaw)
+	if err != nil {
+		return fmt.Errorf("validating cli flags / env vars, invalid REQUESTLESS_POD_DEFAULT_REQUESTS %q, %w", o.requestlessPodDefaultsRaw, err)
+	}
+	o.RequestlessPodDefaultRequests = defaults
 	if o.CPURequests <= 0 {
 		o.CPURequests = 1000
 	}
@@ -157,6 +449,10 @@ func (o *Options) Parse(fs *FlagSet, args ...string) error {
 	o.FeatureGates = gates
 	o.PreferencePolicy = PreferencePolicy(o.preferencePolicyRaw)
 	o.MinValuesPolicy = MinValuesPolicy(o.minValuesPolicyRaw)
+	o.LocalStoragePolicy = LocalStoragePolicy(o.localStoragePolicyRaw)
+	o.DriftOrdering = DriftOrdering(o.driftOrderingRaw)
+	o.RequestlessPodPolicy = RequestlessPodPolicy(o.requestlessPodPolicyRaw)
+	o.StartupTaintTimeoutPolicy = StartupTaintTimeoutPolicy(o.startupTaintTimeoutPolicyRaw)
 	return nil
 }
 
@@ -164,6 +460,37 @@ func (o *Options) ToContext(ctx context.Context) context.Context {
 	return ToContext(ctx, o)
 }
 
+// ParseNodeRepairConditions parses a comma separated list of type=status:toleration entries, e.g. 'Ready=False:30m'
+func ParseNodeRepairConditions(str string) ([]NodeRepairCondition, error) {
+	var conditions []NodeRepairCondition
+	for _, entry := range strings.Split(str, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		conditionType, rest, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected type=status:toleration, got %q", entry)
+		}
+		status, toleration, ok := strings.Cut(rest, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected type=status:toleration, got %q", entry)
+		}
+		if !lo.Contains([]corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown}, corev1.ConditionStatus(status)) {
+			return nil, fmt.Errorf("invalid status %q for condition %q, must be one of True, False or Unknown", status, conditionType)
+		}
+		tolerationDuration, err := time.ParseDuration(toleration)
+		if err != nil || tolerationDuration < 0 {
+			return nil, fmt.Errorf("invalid toleration %q for condition %q, must be a non-negative duration", toleration, conditionType)
+		}
+		conditions = append(conditions, NodeRepairCondition{
+			Type:               corev1.NodeConditionType(strings.TrimSpace(conditionType)),
+			Status:             corev1.ConditionStatus(status),
+			TolerationDuration: tolerationDuration,
+		})
+	}
+	return conditions, nil
+}
+
 func DefaultFeatureGates() FeatureGates {
 	return FeatureGates{
 		NodeRepair:              false,
@@ -171,6 +498,8 @@ func DefaultFeatureGates() FeatureGates {
 		SpotToSpotConsolidation: false,
 		NodeOverlay:             false,
 		StaticCapacity:          false,
+		NodeRightsizing:         false,
+		NominatedPods:           false,
 	}
 }
 
@@ -198,6 +527,12 @@ func ParseFeatureGates(gateStr string) (FeatureGates, error) {
 	if val, ok := gateMap["StaticCapacity"]; ok {
 		gates.StaticCapacity = val
 	}
+	if val, ok := gateMap["NodeRightsizing"]; ok {
+		gates.NodeRightsizing = val
+	}
+	if val, ok := gateMap["NominatedPods"]; ok {
+		gates.NominatedPods = val
+	}
 
 	return gates, nil
 }
@@ -28,6 +28,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -63,6 +65,56 @@ var _ = Describe("Options", func() {
 		"BATCH_IDLE_DURATION",
 		"PREFERENCE_POLICY",
 		"MIN_VALUES_POLICY",
+		"RIGHTSIZING_HEADROOM_PERCENT",
+		"STATE_STREAM_WEBHOOK_URL",
+		"CONSOLIDATION_LOCAL_STORAGE_THRESHOLD",
+		"CONSOLIDATION_LOCAL_STORAGE_POLICY",
+		"PROVISIONING_FAILURE_THRESHOLD",
+		"DISRUPTION_DECISION_RETENTION",
+		"DRIFT_BATCH_SIZE",
+		"DRIFT_ORDERING",
+		"EVICTION_PRECHECK",
+		"NODEPOOL_API_QPS",
+		"NODEPOOL_API_BURST",
+		"NODECLAIM_GC_MIN_AGE",
+		"NODECLAIM_GC_CONFIRMATIONS",
+		"REQUESTLESS_POD_POLICY",
+		"REQUESTLESS_POD_DEFAULT_REQUESTS",
+		"HIGH_CARDINALITY_METRICS",
+		"EXPIRATION_JITTER_PERCENT",
+		"EXPIRATION_WARNING_DURATION",
+		"EXPIRATION_REPLACEMENT",
+		"READ_ONLY_MODE_FILE",
+		"WORKLOAD_AFFINITY_WEIGHT",
+		"DISRUPTION_COMMAND_MAX_FAILURES",
+		"DISRUPTION_DEAD_LETTER_TTL",
+		"PRICE_CHANGE_THRESHOLD",
+		"PRICE_CHANGE_MIN_INTERVAL",
+		"INTERACTIVE_SESSION_GRACE_PERIOD",
+		"DISRUPTION_DRY_RUN",
+		"DISRUPTION_REASON_PRIORITY",
+		"DISRUPTION_REPLACEMENT_TIMEOUT",
+		"DISRUPTION_RATE_LIMIT",
+		"DISRUPTION_RATE_LIMIT_PERIOD",
+		"SHARD_COUNT",
+		"SHARD_INDEX",
+		"CLUSTER_AUTOSCALER_COMPATIBILITY",
+		"DISRUPTION_CANDIDATE_LIMIT",
+		"NODE_REPAIR_CONDITIONS",
+		"GRACEFUL_NODE_REPAIR",
+		"PRE_DRAIN_HOOK_TIMEOUT",
+		"EVICTION_CONCURRENCY_PER_NODE",
+		"EVICTION_INTERVAL_PER_NODE",
+		"VOLUME_DETACHMENT_WAIT",
+		"VOLUME_DETACHMENT_TIMEOUT",
+		"PRE_TERMINATE_TIMEOUT",
+		"LAUNCH_FALLBACK_ATTEMPTS",
+		"STARTUP_TAINT_TIMEOUT",
+		"STARTUP_TAINT_TIMEOUT_POLICY",
+		"ORPHANED_INSTANCE_DELETION",
+		"ORPHANED_INSTANCE_MIN_AGE",
+		"PRE_DRAIN_NOTIFICATION_WINDOW",
+		"TERMINATION_HOLD_TIMEOUT",
 		"FEATURE_GATES",
 	}
 
@@ -327,6 +379,8 @@ var _ = Describe("Options", func() {
 			Entry("when SpotToSpotConsolidation is overridden", "SpotToSpotConsolidation"),
 			Entry("when NodeOverlay is overridden", "NodeOverlay"),
 			Entry("when StaticCapacity is overridden", "StaticCapacity"),
+			Entry("when NodeRightsizing is overridden", "NodeRightsizing"),
+			Entry("when NominatedPods is overridden", "NominatedPods"),
 		)
 	})
 
@@ -364,6 +418,208 @@ var _ = Describe("Options", func() {
 			Entry("zero is provided", "0"),
 			Entry("negative value is provided", "-50"),
 		)
+		It("should error with a negative rightsizing headroom percent", func() {
+			err := opts.Parse(fs, "--rightsizing-headroom-percent", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should parse a valid state stream webhook url", func() {
+			Expect(opts.Parse(fs, "--state-stream-webhook-url", "https://inventory.example.com/karpenter")).To(Succeed())
+			Expect(opts.StateStreamWebhookURL).To(Equal("https://inventory.example.com/karpenter"))
+		})
+		It("should error with a state stream webhook url that isn't http or https", func() {
+			err := opts.Parse(fs, "--state-stream-webhook-url", "grpc://inventory:9000")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should parse the consolidation local storage threshold and policy", func() {
+			Expect(opts.Parse(fs, "--consolidation-local-storage-threshold", "10Gi", "--consolidation-local-storage-policy", "Deprioritize")).To(Succeed())
+			Expect(opts.LocalStorageThreshold.Cmp(resource.MustParse("10Gi"))).To(Equal(0))
+			Expect(opts.LocalStoragePolicy).To(Equal(options.LocalStoragePolicyDeprioritize))
+		})
+		It("should error with an invalid consolidation local storage threshold", func() {
+			err := opts.Parse(fs, "--consolidation-local-storage-threshold", "lots")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid consolidation local storage policy", func() {
+			err := opts.Parse(fs, "--consolidation-local-storage-policy", "Ignore")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative provisioning failure threshold", func() {
+			err := opts.Parse(fs, "--provisioning-failure-threshold", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption decision retention", func() {
+			err := opts.Parse(fs, "--disruption-decision-retention", "-1h")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a drift batch size less than 1", func() {
+			err := opts.Parse(fs, "--drift-batch-size", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should parse the drift ordering", func() {
+			Expect(opts.Parse(fs, "--drift-ordering", "CheapestFirst")).To(Succeed())
+			Expect(opts.DriftOrdering).To(Equal(options.DriftOrderingCheapestFirst))
+		})
+		It("should error with an invalid drift ordering", func() {
+			err := opts.Parse(fs, "--drift-ordering", "NewestFirst")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative nodepool api qps", func() {
+			err := opts.Parse(fs, "--nodepool-api-qps", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a nodepool api burst less than 1", func() {
+			err := opts.Parse(fs, "--nodepool-api-burst", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative nodeclaim gc min age", func() {
+			err := opts.Parse(fs, "--nodeclaim-gc-min-age", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should parse the requestless pod policy and default requests", func() {
+			Expect(opts.Parse(fs, "--requestless-pod-policy", "Default", "--requestless-pod-default-requests", "cpu=250m,memory=1Gi")).To(Succeed())
+			Expect(opts.RequestlessPodPolicy).To(Equal(options.RequestlessPodPolicyDefault))
+			Expect(opts.RequestlessPodDefaultRequests.Cpu().String()).To(Equal("250m"))
+			Expect(opts.RequestlessPodDefaultRequests.Memory().String()).To(Equal("1Gi"))
+		})
+		It("should parse the disruption reason priority", func() {
+			Expect(opts.Parse(fs, "--disruption-reason-priority", "Drifted,Empty,Underutilized")).To(Succeed())
+			Expect(opts.DisruptionReasonPriority).To(Equal([]string{"Drifted", "Empty", "Underutilized"}))
+		})
+		It("should error with an unknown reason in the disruption reason priority", func() {
+			err := opts.Parse(fs, "--disruption-reason-priority", "Drifted,Expired")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a repeated reason in the disruption reason priority", func() {
+			err := opts.Parse(fs, "--disruption-reason-priority", "Drifted,Empty,Drifted")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption replacement timeout", func() {
+			err := opts.Parse(fs, "--disruption-replacement-timeout", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption rate limit", func() {
+			err := opts.Parse(fs, "--disruption-rate-limit", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a disruption rate limit period that isn't positive", func() {
+			err := opts.Parse(fs, "--disruption-rate-limit-period", "0s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a shard count that isn't positive", func() {
+			err := opts.Parse(fs, "--shard-count", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a shard index outside of the shard count", func() {
+			err := opts.Parse(fs, "--shard-count", "2", "--shard-index", "2")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption candidate limit", func() {
+			err := opts.Parse(fs, "--disruption-candidate-limit", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should parse the node repair conditions", func() {
+			Expect(opts.Parse(fs, "--node-repair-conditions", "Ready=False:30m,DiskPressure=True:10m")).To(Succeed())
+			Expect(opts.NodeRepairConditions).To(Equal([]options.NodeRepairCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, TolerationDuration: 30 * time.Minute},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, TolerationDuration: 10 * time.Minute},
+			}))
+		})
+		It("should error with a node repair condition that has an invalid status", func() {
+			err := opts.Parse(fs, "--node-repair-conditions", "Ready=Maybe:30m")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a node repair condition that has an invalid toleration", func() {
+			err := opts.Parse(fs, "--node-repair-conditions", "Ready=False")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative pre-drain hook timeout", func() {
+			err := opts.Parse(fs, "--pre-drain-hook-timeout", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative eviction concurrency per node", func() {
+			err := opts.Parse(fs, "--eviction-concurrency-per-node", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative eviction interval per node", func() {
+			err := opts.Parse(fs, "--eviction-interval-per-node", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative volume detachment timeout", func() {
+			err := opts.Parse(fs, "--volume-detachment-timeout", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative pre-terminate timeout", func() {
+			err := opts.Parse(fs, "--pre-terminate-timeout", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with negative launch fallback attempts", func() {
+			err := opts.Parse(fs, "--launch-fallback-attempts", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative startup taint timeout", func() {
+			err := opts.Parse(fs, "--startup-taint-timeout", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid startup taint timeout policy", func() {
+			err := opts.Parse(fs, "--startup-taint-timeout-policy", "Ignore")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative orphaned instance min age", func() {
+			err := opts.Parse(fs, "--orphaned-instance-min-age", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative pre-drain notification window", func() {
+			err := opts.Parse(fs, "--pre-drain-notification-window", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative termination hold timeout", func() {
+			err := opts.Parse(fs, "--termination-hold-timeout", "-1s")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an invalid requestless pod policy", func() {
+			err := opts.Parse(fs, "--requestless-pod-policy", "Guess")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with invalid requestless pod default requests", func() {
+			err := opts.Parse(fs, "--requestless-pod-default-requests", "cpu")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with nodeclaim gc confirmations less than 1", func() {
+			err := opts.Parse(fs, "--nodeclaim-gc-confirmations", "0")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with an expiration jitter percent greater than 100", func() {
+			err := opts.Parse(fs, "--expiration-jitter-percent", "101")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative expiration warning duration", func() {
+			err := opts.Parse(fs, "--expiration-warning-duration", "-1m")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative workload affinity weight", func() {
+			err := opts.Parse(fs, "--workload-affinity-weight", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption command max failures", func() {
+			err := opts.Parse(fs, "--disruption-command-max-failures", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative disruption dead-letter ttl", func() {
+			err := opts.Parse(fs, "--disruption-dead-letter-ttl", "-1h")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative price change threshold", func() {
+			err := opts.Parse(fs, "--price-change-threshold", "-1")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative price change min interval", func() {
+			err := opts.Parse(fs, "--price-change-min-interval", "-1m")
+			Expect(err).ToNot(BeNil())
+		})
+		It("should error with a negative interactive session grace period", func() {
+			err := opts.Parse(fs, "--interactive-session-grace-period", "-1m")
+			Expect(err).ToNot(BeNil())
+		})
 	})
 
 })
@@ -396,5 +652,57 @@ func expectOptionsMatch(optsA, optsB *options.Options) {
 	Expect(optsA.FeatureGates.NodeOverlay).To(Equal(optsB.FeatureGates.NodeOverlay))
 	Expect(optsA.FeatureGates.StaticCapacity).To(Equal(optsB.FeatureGates.StaticCapacity))
 	Expect(optsA.FeatureGates.SpotToSpotConsolidation).To(Equal(optsB.FeatureGates.SpotToSpotConsolidation))
+	Expect(optsA.FeatureGates.NodeRightsizing).To(Equal(optsB.FeatureGates.NodeRightsizing))
+	Expect(optsA.FeatureGates.NominatedPods).To(Equal(optsB.FeatureGates.NominatedPods))
 	Expect(optsA.IgnoreDRARequests).To(Equal(optsB.IgnoreDRARequests))
+	Expect(optsA.RightsizingHeadroomPercent).To(Equal(optsB.RightsizingHeadroomPercent))
+	Expect(optsA.StateStreamWebhookURL).To(Equal(optsB.StateStreamWebhookURL))
+	Expect(optsA.LocalStorageThreshold.Cmp(optsB.LocalStorageThreshold)).To(Equal(0))
+	Expect(optsA.LocalStoragePolicy).To(Equal(optsB.LocalStoragePolicy))
+	Expect(optsA.ProvisioningFailureThreshold).To(Equal(optsB.ProvisioningFailureThreshold))
+	Expect(optsA.DisruptionDecisionRetention).To(Equal(optsB.DisruptionDecisionRetention))
+	Expect(optsA.DriftBatchSize).To(Equal(optsB.DriftBatchSize))
+	Expect(optsA.DriftOrdering).To(Equal(optsB.DriftOrdering))
+	Expect(optsA.EvictionPrecheck).To(Equal(optsB.EvictionPrecheck))
+	Expect(optsA.NodePoolAPIQPS).To(Equal(optsB.NodePoolAPIQPS))
+	Expect(optsA.NodePoolAPIBurst).To(Equal(optsB.NodePoolAPIBurst))
+	Expect(optsA.NodeClaimGCMinAge).To(Equal(optsB.NodeClaimGCMinAge))
+	Expect(optsA.NodeClaimGCConfirmations).To(Equal(optsB.NodeClaimGCConfirmations))
+	Expect(optsA.RequestlessPodPolicy).To(Equal(optsB.RequestlessPodPolicy))
+	Expect(optsA.RequestlessPodDefaultRequests).To(Equal(optsB.RequestlessPodDefaultRequests))
+	Expect(optsA.HighCardinalityMetrics).To(Equal(optsB.HighCardinalityMetrics))
+	Expect(optsA.ExpirationJitterPercent).To(Equal(optsB.ExpirationJitterPercent))
+	Expect(optsA.ExpirationWarningDuration).To(Equal(optsB.ExpirationWarningDuration))
+	Expect(optsA.ExpirationReplacement).To(Equal(optsB.ExpirationReplacement))
+	Expect(optsA.ReadOnlyModeFile).To(Equal(optsB.ReadOnlyModeFile))
+	Expect(optsA.WorkloadAffinityWeight).To(Equal(optsB.WorkloadAffinityWeight))
+	Expect(optsA.DisruptionCommandMaxFailures).To(Equal(optsB.DisruptionCommandMaxFailures))
+	Expect(optsA.DisruptionDeadLetterTTL).To(Equal(optsB.DisruptionDeadLetterTTL))
+	Expect(optsA.PriceChangeThreshold).To(Equal(optsB.PriceChangeThreshold))
+	Expect(optsA.PriceChangeMinInterval).To(Equal(optsB.PriceChangeMinInterval))
+	Expect(optsA.InteractiveSessionGracePeriod).To(Equal(optsB.InteractiveSessionGracePeriod))
+	Expect(optsA.PreDrainHookTimeout).To(Equal(optsB.PreDrainHookTimeout))
+	Expect(optsA.EvictionConcurrencyPerNode).To(Equal(optsB.EvictionConcurrencyPerNode))
+	Expect(optsA.EvictionIntervalPerNode).To(Equal(optsB.EvictionIntervalPerNode))
+	Expect(optsA.VolumeDetachmentWait).To(Equal(optsB.VolumeDetachmentWait))
+	Expect(optsA.VolumeDetachmentTimeout).To(Equal(optsB.VolumeDetachmentTimeout))
+	Expect(optsA.PreTerminateTimeout).To(Equal(optsB.PreTerminateTimeout))
+	Expect(optsA.LaunchFallbackAttempts).To(Equal(optsB.LaunchFallbackAttempts))
+	Expect(optsA.StartupTaintTimeout).To(Equal(optsB.StartupTaintTimeout))
+	Expect(optsA.StartupTaintTimeoutPolicy).To(Equal(optsB.StartupTaintTimeoutPolicy))
+	Expect(optsA.OrphanedInstanceDeletion).To(Equal(optsB.OrphanedInstanceDeletion))
+	Expect(optsA.OrphanedInstanceMinAge).To(Equal(optsB.OrphanedInstanceMinAge))
+	Expect(optsA.PreDrainNotificationWindow).To(Equal(optsB.PreDrainNotificationWindow))
+	Expect(optsA.TerminationHoldTimeout).To(Equal(optsB.TerminationHoldTimeout))
+	Expect(optsA.DisruptionDryRun).To(Equal(optsB.DisruptionDryRun))
+	Expect(optsA.DisruptionReasonPriority).To(Equal(optsB.DisruptionReasonPriority))
+	Expect(optsA.DisruptionReplacementTimeout).To(Equal(optsB.DisruptionReplacementTimeout))
+	Expect(optsA.DisruptionRateLimit).To(Equal(optsB.DisruptionRateLimit))
+	Expect(optsA.DisruptionRateLimitPeriod).To(Equal(optsB.DisruptionRateLimitPeriod))
+	Expect(optsA.ShardCount).To(Equal(optsB.ShardCount))
+	Expect(optsA.ShardIndex).To(Equal(optsB.ShardIndex))
+	Expect(optsA.ClusterAutoscalerCompatibility).To(Equal(optsB.ClusterAutoscalerCompatibility))
+	Expect(optsA.DisruptionCandidateLimit).To(Equal(optsB.DisruptionCandidateLimit))
+	Expect(optsA.NodeRepairConditions).To(Equal(optsB.NodeRepairConditions))
+	Expect(optsA.GracefulNodeRepair).To(Equal(optsB.GracefulNodeRepair))
 }
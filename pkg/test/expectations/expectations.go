@@ -36,6 +36,7 @@ import (
 	prometheusmodel "github.com/prometheus/client_model/go"
 	"github.com/samber/lo"
 	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -253,10 +254,14 @@ func ExpectCleanedUp(ctx context.Context, c client.Client) {
 		&corev1.PersistentVolumeClaim{},
 		&corev1.PersistentVolume{},
 		&storagev1.StorageClass{},
+		&coordinationv1.Lease{},
 		&v1.NodePool{},
 		&testv1alpha1.TestNodeClass{},
 		&v1.NodeClaim{},
 		&v1alpha1.NodeOverlay{},
+		&v1alpha1.DisruptionCommand{},
+		&v1alpha1.DisruptionDecision{},
+		&v1alpha1.MaintenanceWindow{},
 	} {
 		for _, namespace := range namespaces.Items {
 			wg.Add(1)
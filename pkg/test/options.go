@@ -22,6 +22,8 @@ import (
 
 	"github.com/imdario/mergo"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"sigs.k8s.io/karpenter/pkg/operator/options"
 )
@@ -48,6 +50,56 @@ type OptionsFields struct {
 	BatchMaxDuration                 *time.Duration
 	BatchIdleDuration                *time.Duration
 	IgnoreDRARequests                *bool
+	RightsizingHeadroomPercent       *int
+	StateStreamWebhookURL            *string
+	LocalStorageThreshold            *resource.Quantity
+	LocalStoragePolicy               *options.LocalStoragePolicy
+	ProvisioningFailureThreshold     *int
+	DisruptionDecisionRetention      *time.Duration
+	DriftBatchSize                   *int
+	DriftOrdering                    *options.DriftOrdering
+	EvictionPrecheck                 *bool
+	NodePoolAPIQPS                   *int
+	NodePoolAPIBurst                 *int
+	NodeClaimGCMinAge                *time.Duration
+	NodeClaimGCConfirmations         *int
+	RequestlessPodPolicy             *options.RequestlessPodPolicy
+	RequestlessPodDefaultRequests    corev1.ResourceList
+	HighCardinalityMetrics           *bool
+	ExpirationJitterPercent          *int
+	ExpirationWarningDuration        *time.Duration
+	ExpirationReplacement            *bool
+	ReadOnlyModeFile                 *string
+	WorkloadAffinityWeight           *int
+	DisruptionCommandMaxFailures     *int
+	DisruptionDeadLetterTTL          *time.Duration
+	PriceChangeThreshold             *int
+	PriceChangeMinInterval           *time.Duration
+	InteractiveSessionGracePeriod    *time.Duration
+	PreDrainHookTimeout              *time.Duration
+	EvictionConcurrencyPerNode       *int
+	EvictionIntervalPerNode          *time.Duration
+	VolumeDetachmentWait             *bool
+	VolumeDetachmentTimeout          *time.Duration
+	PreTerminateTimeout              *time.Duration
+	LaunchFallbackAttempts           *int
+	StartupTaintTimeout              *time.Duration
+	StartupTaintTimeoutPolicy        *options.StartupTaintTimeoutPolicy
+	OrphanedInstanceDeletion         *bool
+	OrphanedInstanceMinAge           *time.Duration
+	PreDrainNotificationWindow       *time.Duration
+	TerminationHoldTimeout           *time.Duration
+	DisruptionDryRun                 *bool
+	DisruptionReasonPriority         []string
+	DisruptionReplacementTimeout     *time.Duration
+	DisruptionRateLimit              *int
+	DisruptionRateLimitPeriod        *time.Duration
+	ShardCount                       *int
+	ShardIndex                       *int
+	ClusterAutoscalerCompatibility   *bool
+	DisruptionCandidateLimit         *int
+	NodeRepairConditions             []options.NodeRepairCondition
+	GracefulNodeRepair               *bool
 	FeatureGates                     FeatureGates
 }
 
@@ -57,6 +109,8 @@ type FeatureGates struct {
 	SpotToSpotConsolidation *bool
 	NodeOverlay             *bool
 	StaticCapacity          *bool
+	NodeRightsizing         *bool
+	NominatedPods           *bool
 }
 
 func Options(overrides ...OptionsFields) *options.Options {
@@ -75,6 +129,8 @@ func Options(overrides ...OptionsFields) *options.Options {
 		KubeClientBurst:                  lo.FromPtrOr(opts.KubeClientBurst, 300),
 		EnableProfiling:                  lo.FromPtrOr(opts.EnableProfiling, false),
 		DisableLeaderElection:            lo.FromPtrOr(opts.DisableLeaderElection, false),
+		LeaderElectionName:               lo.FromPtrOr(opts.LeaderElectionName, "karpenter-leader-election"),
+		LeaderElectionNamespace:          lo.FromPtrOr(opts.LeaderElectionNamespace, ""),
 		DisableClusterStateObservability: lo.FromPtrOr(opts.DisableClusterStateObservability, false),
 		MemoryLimit:                      lo.FromPtrOr(opts.MemoryLimit, -1),
 		CPURequests:                      lo.FromPtrOr(opts.CPURequests, 5000), // use 5 threads to enforce parallelism
@@ -86,12 +142,64 @@ func Options(overrides ...OptionsFields) *options.Options {
 		PreferencePolicy:                 lo.FromPtrOr(opts.PreferencePolicy, options.PreferencePolicyRespect),
 		MinValuesPolicy:                  lo.FromPtrOr(opts.MinValuesPolicy, options.MinValuesPolicyStrict),
 		IgnoreDRARequests:                lo.FromPtrOr(opts.IgnoreDRARequests, true),
+		RightsizingHeadroomPercent:       lo.FromPtrOr(opts.RightsizingHeadroomPercent, 10),
+		StateStreamWebhookURL:            lo.FromPtrOr(opts.StateStreamWebhookURL, ""),
+		LocalStorageThreshold:            lo.FromPtrOr(opts.LocalStorageThreshold, resource.Quantity{}),
+		LocalStoragePolicy:               lo.FromPtrOr(opts.LocalStoragePolicy, options.LocalStoragePolicySkip),
+		ProvisioningFailureThreshold:     lo.FromPtrOr(opts.ProvisioningFailureThreshold, 0),
+		DisruptionDecisionRetention:      lo.FromPtrOr(opts.DisruptionDecisionRetention, 0),
+		DriftBatchSize:                   lo.FromPtrOr(opts.DriftBatchSize, 1),
+		DriftOrdering:                    lo.FromPtrOr(opts.DriftOrdering, options.DriftOrderingOldestFirst),
+		EvictionPrecheck:                 lo.FromPtrOr(opts.EvictionPrecheck, false),
+		NodePoolAPIQPS:                   lo.FromPtrOr(opts.NodePoolAPIQPS, 0),
+		NodePoolAPIBurst:                 lo.FromPtrOr(opts.NodePoolAPIBurst, 10),
+		NodeClaimGCMinAge:                lo.FromPtrOr(opts.NodeClaimGCMinAge, 30*time.Second),
+		NodeClaimGCConfirmations:         lo.FromPtrOr(opts.NodeClaimGCConfirmations, 2),
+		RequestlessPodPolicy:             lo.FromPtrOr(opts.RequestlessPodPolicy, options.RequestlessPodPolicyAllow),
+		RequestlessPodDefaultRequests:    lo.Ternary(opts.RequestlessPodDefaultRequests != nil, opts.RequestlessPodDefaultRequests, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")}),
+		HighCardinalityMetrics:           lo.FromPtrOr(opts.HighCardinalityMetrics, true),
+		ExpirationJitterPercent:          lo.FromPtrOr(opts.ExpirationJitterPercent, 0),
+		ExpirationWarningDuration:        lo.FromPtrOr(opts.ExpirationWarningDuration, 0),
+		ExpirationReplacement:            lo.FromPtrOr(opts.ExpirationReplacement, false),
+		ReadOnlyModeFile:                 lo.FromPtrOr(opts.ReadOnlyModeFile, ""),
+		WorkloadAffinityWeight:           lo.FromPtrOr(opts.WorkloadAffinityWeight, 0),
+		DisruptionCommandMaxFailures:     lo.FromPtrOr(opts.DisruptionCommandMaxFailures, 0),
+		DisruptionDeadLetterTTL:          lo.FromPtrOr(opts.DisruptionDeadLetterTTL, 0),
+		PriceChangeThreshold:             lo.FromPtrOr(opts.PriceChangeThreshold, 0),
+		PriceChangeMinInterval:           lo.FromPtrOr(opts.PriceChangeMinInterval, time.Minute),
+		InteractiveSessionGracePeriod:    lo.FromPtrOr(opts.InteractiveSessionGracePeriod, 0),
+		PreDrainHookTimeout:              lo.FromPtrOr(opts.PreDrainHookTimeout, 10*time.Minute),
+		EvictionConcurrencyPerNode:       lo.FromPtrOr(opts.EvictionConcurrencyPerNode, 0),
+		EvictionIntervalPerNode:          lo.FromPtrOr(opts.EvictionIntervalPerNode, 0),
+		VolumeDetachmentWait:             lo.FromPtrOr(opts.VolumeDetachmentWait, true),
+		VolumeDetachmentTimeout:          lo.FromPtrOr(opts.VolumeDetachmentTimeout, 0),
+		PreTerminateTimeout:              lo.FromPtrOr(opts.PreTerminateTimeout, 5*time.Minute),
+		LaunchFallbackAttempts:           lo.FromPtrOr(opts.LaunchFallbackAttempts, 3),
+		StartupTaintTimeout:              lo.FromPtrOr(opts.StartupTaintTimeout, 0),
+		StartupTaintTimeoutPolicy:        lo.FromPtrOr(opts.StartupTaintTimeoutPolicy, options.StartupTaintTimeoutPolicyReplace),
+		OrphanedInstanceDeletion:         lo.FromPtrOr(opts.OrphanedInstanceDeletion, false),
+		OrphanedInstanceMinAge:           lo.FromPtrOr(opts.OrphanedInstanceMinAge, 10*time.Minute),
+		PreDrainNotificationWindow:       lo.FromPtrOr(opts.PreDrainNotificationWindow, 0),
+		TerminationHoldTimeout:           lo.FromPtrOr(opts.TerminationHoldTimeout, time.Hour),
+		DisruptionDryRun:                 lo.FromPtrOr(opts.DisruptionDryRun, false),
+		DisruptionReasonPriority:         opts.DisruptionReasonPriority,
+		DisruptionReplacementTimeout:     lo.FromPtrOr(opts.DisruptionReplacementTimeout, 0),
+		DisruptionRateLimit:              lo.FromPtrOr(opts.DisruptionRateLimit, 0),
+		DisruptionRateLimitPeriod:        lo.FromPtrOr(opts.DisruptionRateLimitPeriod, 5*time.Minute),
+		ShardCount:                       lo.FromPtrOr(opts.ShardCount, 1),
+		ShardIndex:                       lo.FromPtrOr(opts.ShardIndex, 0),
+		ClusterAutoscalerCompatibility:   lo.FromPtrOr(opts.ClusterAutoscalerCompatibility, false),
+		DisruptionCandidateLimit:         lo.FromPtrOr(opts.DisruptionCandidateLimit, 0),
+		NodeRepairConditions:             opts.NodeRepairConditions,
+		GracefulNodeRepair:               lo.FromPtrOr(opts.GracefulNodeRepair, false),
 		FeatureGates: options.FeatureGates{
 			NodeRepair:              lo.FromPtrOr(opts.FeatureGates.NodeRepair, false),
 			ReservedCapacity:        lo.FromPtrOr(opts.FeatureGates.ReservedCapacity, true),
 			SpotToSpotConsolidation: lo.FromPtrOr(opts.FeatureGates.SpotToSpotConsolidation, false),
 			NodeOverlay:             lo.FromPtrOr(opts.FeatureGates.NodeOverlay, false),
 			StaticCapacity:          lo.FromPtrOr(opts.FeatureGates.StaticCapacity, false),
+			NodeRightsizing:         lo.FromPtrOr(opts.FeatureGates.NodeRightsizing, false),
+			NominatedPods:           lo.FromPtrOr(opts.FeatureGates.NominatedPods, false),
 		},
 	}
 }
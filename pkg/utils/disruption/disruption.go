@@ -24,11 +24,13 @@ import (
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/utils/resources"
 )
 
 // lifetimeRemaining calculates the fraction of node lifetime remaining in the range [0.0, 1.0].  If the ExpireAfter
@@ -76,3 +78,31 @@ func ReschedulingCost(ctx context.Context, pods []*corev1.Pod) float64 {
 	}
 	return cost
 }
+
+// LocalStorage returns the amount of node-local storage used by the given pods that would be lost if the pods were
+// moved to another node. Only pods that mount a disk-backed emptyDir or a hostPath volume are counted. The usage of
+// each pod is the larger of its ephemeral-storage requests and the sum of its emptyDir size limits.
+func LocalStorage(pods []*corev1.Pod) resource.Quantity {
+	total := resource.Quantity{}
+	for _, p := range pods {
+		hasLocalVolume := false
+		sizeLimits := resource.Quantity{}
+		for _, vol := range p.Spec.Volumes {
+			switch {
+			case vol.EmptyDir != nil && vol.EmptyDir.Medium != corev1.StorageMediumMemory:
+				hasLocalVolume = true
+				if vol.EmptyDir.SizeLimit != nil {
+					sizeLimits.Add(*vol.EmptyDir.SizeLimit)
+				}
+			case vol.HostPath != nil:
+				hasLocalVolume = true
+			}
+		}
+		if !hasLocalVolume {
+			continue
+		}
+		requests := resources.Ceiling(p).Requests[corev1.ResourceEphemeralStorage]
+		total.Add(lo.Ternary(requests.Cmp(sizeLimits) > 0, requests, sizeLimits))
+	}
+	return total
+}
@@ -21,9 +21,13 @@ import (
 	"regexp"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// inClusterNamespacePath is where the namespace of the pod is mounted when running in the cluster
+const inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 // WithDefaultInt returns the int value of the supplied environment variable or, if not present,
 // the supplied default value. If the int conversion fails, returns the default
 func WithDefaultInt(key string, def int) int {
@@ -90,6 +94,14 @@ func WithDefaultDuration(key string, def time.Duration) time.Duration {
 	return parsedVal
 }
 
+// Namespace returns the namespace that the binary runs in, or the default namespace when it runs outside of the cluster
+func Namespace() string {
+	if namespace, err := os.ReadFile(inClusterNamespacePath); err == nil {
+		return strings.TrimSpace(string(namespace))
+	}
+	return "default"
+}
+
 // GetRevision function is based on the function defined under https://pkg.go.dev/knative.dev/pkg@v0.0.0-20240815051656-89743d9bbf7c/changeset
 // at https://github.com/knative/pkg/blob/89743d9bbf7c/changeset/commit.go#L51
 func GetRevision() string {
@@ -28,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -147,7 +148,7 @@ func NodeClaimForNode(ctx context.Context, c client.Client, node *corev1.Node) (
 }
 
 // GetCurrentlyReschedulablePods grabs all pods from the passed nodes that satisfy the IsReschedulable criteria
-func GetCurrentlyReschedulablePods(ctx context.Context, kubeClient client.Client, nodes ...*corev1.Node) ([]*corev1.Pod, error) {
+func GetCurrentlyReschedulablePods(ctx context.Context, kubeClient client.Client, clk clock.Clock, nodes ...*corev1.Node) ([]*corev1.Pod, error) {
 	pods, err := GetPods(ctx, kubeClient, nodes...)
 	if err != nil {
 		return nil, fmt.Errorf("listing pods, %w", err)
@@ -159,7 +160,7 @@ func GetCurrentlyReschedulablePods(ctx context.Context, kubeClient client.Client
 	}
 
 	return lo.Filter(pods, func(p *corev1.Pod, _ int) bool {
-		return pdbs.IsCurrentlyReschedulable(p)
+		return pdbs.IsCurrentlyReschedulable(p, clk)
 	}), nil
 }
 
@@ -0,0 +1,80 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeclaim
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+// MaxNominatedPods bounds the number of pods recorded on a NodeClaim so that the annotation stays well under the
+// object size limit for NodeClaims created for large scheduling batches
+const MaxNominatedPods = 50
+
+// NominatedPods is the representation of the pods that a NodeClaim was created for, stored in the
+// karpenter.sh/nominated-pods annotation
+type NominatedPods struct {
+	// Pods are the pods that the NodeClaim was created for, up to MaxNominatedPods
+	Pods []NominatedPod `json:"pods"`
+	// Omitted is the number of pods that the NodeClaim was created for that weren't recorded
+	Omitted int `json:"omitted,omitempty"`
+}
+
+type NominatedPod struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+}
+
+// SetNominatedPods records the pods that the NodeClaim is being created for on the NodeClaim
+func SetNominatedPods(nodeClaim *v1.NodeClaim, pods []*corev1.Pod) error {
+	nominated := NominatedPods{
+		Pods: lo.Map(lo.Slice(pods, 0, MaxNominatedPods), func(p *corev1.Pod, _ int) NominatedPod {
+			return NominatedPod{Namespace: p.Namespace, Name: p.Name, UID: p.UID}
+		}),
+		Omitted: max(len(pods)-MaxNominatedPods, 0),
+	}
+	raw, err := json.Marshal(nominated)
+	if err != nil {
+		return fmt.Errorf("marshaling nominated pods, %w", err)
+	}
+	nodeClaim.Annotations = lo.Assign(nodeClaim.Annotations, map[string]string{v1.NodeClaimNominatedPodsAnnotationKey: string(raw)})
+	return nil
+}
+
+// GetNominatedPods returns the pods that the NodeClaim was created for. The returned pods only have their identifying
+// metadata populated, which is sufficient to publish events against them.
+func GetNominatedPods(nodeClaim *v1.NodeClaim) ([]*corev1.Pod, error) {
+	raw, ok := nodeClaim.Annotations[v1.NodeClaimNominatedPodsAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	nominated := NominatedPods{}
+	if err := json.Unmarshal([]byte(raw), &nominated); err != nil {
+		return nil, fmt.Errorf("unmarshaling nominated pods, %w", err)
+	}
+	return lo.Map(nominated.Pods, func(p NominatedPod, _ int) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name, UID: p.UID}}
+	}), nil
+}
@@ -222,4 +222,37 @@ var _ = Describe("NodeClaimUtils", func() {
 			Expect(res[0].Name).To(Equal(managed.Name))
 		})
 	})
+	Context("NominatedPods", func() {
+		It("should round trip the nominated pods", func() {
+			nodeClaim := test.NodeClaim()
+			pods := test.Pods(3, test.PodOptions{})
+			Expect(nodeclaimutils.SetNominatedPods(nodeClaim, pods)).To(Succeed())
+			nominated, err := nodeclaimutils.GetNominatedPods(nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nominated).To(HaveLen(3))
+			for i := range pods {
+				Expect(nominated[i].Namespace).To(Equal(pods[i].Namespace))
+				Expect(nominated[i].Name).To(Equal(pods[i].Name))
+				Expect(nominated[i].UID).To(Equal(pods[i].UID))
+			}
+		})
+		It("should bound the number of nominated pods", func() {
+			nodeClaim := test.NodeClaim()
+			Expect(nodeclaimutils.SetNominatedPods(nodeClaim, test.Pods(nodeclaimutils.MaxNominatedPods+10, test.PodOptions{}))).To(Succeed())
+			nominated, err := nodeclaimutils.GetNominatedPods(nodeClaim)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nominated).To(HaveLen(nodeclaimutils.MaxNominatedPods))
+			Expect(nodeClaim.Annotations[v1.NodeClaimNominatedPodsAnnotationKey]).To(ContainSubstring(`"omitted":10`))
+		})
+		It("should return no pods when the annotation doesn't exist", func() {
+			nominated, err := nodeclaimutils.GetNominatedPods(test.NodeClaim())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nominated).To(BeEmpty())
+		})
+		It("should return an error when the annotation is malformed", func() {
+			nodeClaim := test.NodeClaim(v1.NodeClaim{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1.NodeClaimNominatedPodsAnnotationKey: "{"}}})
+			_, err := nodeclaimutils.GetNominatedPods(nodeClaim)
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
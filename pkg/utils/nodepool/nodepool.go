@@ -18,12 +18,15 @@ package nodepool
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/awslabs/operatorpkg/object"
 	"github.com/awslabs/operatorpkg/option"
 	"github.com/awslabs/operatorpkg/status"
 	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -32,6 +35,7 @@ import (
 
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/cloudprovider"
+	"sigs.k8s.io/karpenter/pkg/utils/shard"
 )
 
 func IsManaged(nodePool *v1.NodePool, cp cloudprovider.CloudProvider) bool {
@@ -40,6 +44,12 @@ func IsManaged(nodePool *v1.NodePool, cp cloudprovider.CloudProvider) bool {
 	})
 }
 
+// InShard returns true if the named NodePool is owned by this replica's shard. NodePools are assigned to shards by the
+// hash of their name.
+func InShard(ctx context.Context, nodePoolName string) bool {
+	return shard.Owns(ctx, nodePoolName)
+}
+
 func IsStatic(np *v1.NodePool) bool {
 	return np.Spec.Replicas != nil
 }
@@ -154,14 +164,14 @@ func NodeClassEventHandler(c client.Client) handler.EventHandler {
 	})
 }
 
-// OrderByWeight orders the NodePools in the provided slice by their priority weight in-place. This priority evaluates
-// the following things in precedence order:
+// OrderByWeight orders the NodePools in the provided slice by their priority weight, including any escalated weight,
+// in-place. This priority evaluates the following things in precedence order:
 //  1. NodePools that have a larger weight are ordered first
 //  2. If two NodePools have the same weight, then the NodePool with the name later in the alphabet will come first
 func OrderByWeight(nps []*v1.NodePool) {
 	sort.Slice(nps, func(a, b int) bool {
-		weightA := lo.FromPtr(nps[a].Spec.Weight)
-		weightB := lo.FromPtr(nps[b].Spec.Weight)
+		weightA := nps[a].Weight()
+		weightB := nps[b].Weight()
 		if weightA == weightB {
 			// Order NodePools by name for a consistent ordering when sorting equal weight
 			return nps[a].Name > nps[b].Name
@@ -169,3 +179,55 @@ func OrderByWeight(nps []*v1.NodePool) {
 		return weightA > weightB
 	})
 }
+
+// RecordProvisioningFailure increments the count of consecutive provisioning failures on the named NodePool. The count is
+// only used as a heuristic, so we patch without an optimistic lock and accept that racing failures may be counted once.
+func RecordProvisioningFailure(ctx context.Context, c client.Client, nodePoolName string) error {
+	if nodePoolName == "" {
+		return nil
+	}
+	nodePool := &v1.NodePool{}
+	if err := c.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	stored := nodePool.DeepCopy()
+	nodePool.Status.ConsecutiveProvisioningFailures++
+	return client.IgnoreNotFound(c.Status().Patch(ctx, nodePool, client.MergeFrom(stored)))
+}
+
+// RecordDriftReplacements adds the drifted nodes of the named NodePool that were disrupted to the progress of the
+// NodePool's drift rollout. The remaining drifted nodes are computed by the nodepool.driftrollout controller. Like
+// RecordProvisioningFailure, the progress is only informational, so we patch without an optimistic lock.
+func RecordDriftReplacements(ctx context.Context, c client.Client, nodePoolName string, replaced int, now time.Time) error {
+	nodePool := &v1.NodePool{}
+	if err := c.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	stored := nodePool.DeepCopy()
+	rollout := lo.FromPtr(nodePool.Status.DriftRollout)
+	rollout.Replaced += int64(replaced)
+	rollout.Drifted = rollout.Replaced + rollout.Remaining
+	rollout.LastReplacementTime = lo.ToPtr(metav1.NewTime(now))
+	nodePool.Status.DriftRollout = &rollout
+	return client.IgnoreNotFound(c.Status().Patch(ctx, nodePool, client.MergeFrom(stored)))
+}
+
+// RecordDriftFailures adds drift replacements of the named NodePool that failed to launch or initialize to the progress
+// of the NodePool's drift rollout. Once the failures reach the NodePool's driftFailureThreshold, drift is paused for the
+// NodePool by setting the DriftPaused status condition, which the nodepool.driftrollout controller clears when the next
+// rollout starts.
+func RecordDriftFailures(ctx context.Context, c client.Client, nodePoolName string, failed int) error {
+	nodePool := &v1.NodePool{}
+	if err := c.Get(ctx, types.NamespacedName{Name: nodePoolName}, nodePool); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	stored := nodePool.DeepCopy()
+	rollout := lo.FromPtr(nodePool.Status.DriftRollout)
+	rollout.Failed += int64(failed)
+	nodePool.Status.DriftRollout = &rollout
+	if threshold := nodePool.Spec.Disruption.DriftFailureThreshold; threshold != nil && rollout.Failed >= int64(*threshold) {
+		nodePool.StatusConditions().SetTrueWithReason(v1.ConditionTypeDriftPaused, "FailureThresholdReached",
+			fmt.Sprintf("%d drift replacements failed to launch or initialize, reaching the threshold of %d", rollout.Failed, *threshold))
+	}
+	return client.IgnoreNotFound(c.Status().Patch(ctx, nodePool, client.MergeFrom(stored)))
+}
@@ -28,6 +28,7 @@ import (
 
 	"sigs.k8s.io/karpenter/pkg/apis"
 	v1 "sigs.k8s.io/karpenter/pkg/apis/v1"
+	"sigs.k8s.io/karpenter/pkg/operator/options"
 	"sigs.k8s.io/karpenter/pkg/test"
 	. "sigs.k8s.io/karpenter/pkg/test/expectations"
 	"sigs.k8s.io/karpenter/pkg/test/v1alpha1"
@@ -97,4 +98,43 @@ var _ = Describe("NodePoolUtils", func() {
 			}
 		})
 	})
+	Context("InShard", func() {
+		It("should assign every NodePool to exactly one shard", func() {
+			for _, name := range lo.Times(20, func(_ int) string { return test.RandomName() }) {
+				shards := lo.Filter(lo.Range(3), func(index int, _ int) bool {
+					return nodepoolutils.InShard(options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(3), ShardIndex: lo.ToPtr(index)})), name)
+				})
+				Expect(shards).To(HaveLen(1))
+			}
+		})
+		It("should assign every NodePool to the only shard when the work isn't sharded", func() {
+			Expect(nodepoolutils.InShard(options.ToContext(ctx, test.Options()), test.RandomName())).To(BeTrue())
+		})
+	})
+	Context("RecordDriftFailures", func() {
+		It("should add the failures to the drift rollout", func() {
+			nodePool := test.NodePool()
+			nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Remaining: 3, Failed: 1}
+			ExpectApplied(ctx, env.Client, nodePool)
+			Expect(nodepoolutils.RecordDriftFailures(ctx, env.Client, nodePool.Name, 2)).To(Succeed())
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.Status.DriftRollout.Failed).To(BeNumerically("==", 3))
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDriftPaused)).To(BeNil())
+		})
+		It("should pause drift once the failures reach the threshold", func() {
+			nodePool := test.NodePool()
+			nodePool.Spec.Disruption.DriftFailureThreshold = lo.ToPtr[int32](2)
+			nodePool.Status.DriftRollout = &v1.DriftRolloutStatus{Drifted: 3, Remaining: 3, Failed: 1}
+			ExpectApplied(ctx, env.Client, nodePool)
+			Expect(nodepoolutils.RecordDriftFailures(ctx, env.Client, nodePool.Name, 1)).To(Succeed())
+
+			nodePool = ExpectExists(ctx, env.Client, nodePool)
+			Expect(nodePool.Status.DriftRollout.Failed).To(BeNumerically("==", 2))
+			Expect(nodePool.StatusConditions().Get(v1.ConditionTypeDriftPaused).IsTrue()).To(BeTrue())
+		})
+		It("should ignore NodePools that don't exist", func() {
+			Expect(nodepoolutils.RecordDriftFailures(ctx, env.Client, "does-not-exist", 1)).To(Succeed())
+		})
+	})
 })
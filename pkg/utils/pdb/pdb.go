@@ -18,6 +18,7 @@ package pdb
 
 import (
 	"context"
+	"sync"
 
 	"github.com/samber/lo"
 	v1 "k8s.io/api/core/v1"
@@ -25,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	podutil "sigs.k8s.io/karpenter/pkg/utils/pod"
@@ -37,11 +39,12 @@ const (
 	fullyBlockingPDBs
 )
 
-// Limits is used to evaluate if evicting a list of pods is possible.
-type Limits []*pdbItem
+// Limits is used to evaluate if evicting a list of pods is possible. PDBs only select pods in their own namespace, so
+// they're indexed by namespace to avoid matching every pod against every PDB in the cluster.
+type Limits map[string][]*pdbItem
 
 func NewLimits(ctx context.Context, kubeClient client.Client) (Limits, error) {
-	pdbs := []*pdbItem{}
+	pdbs := Limits{}
 
 	var pdbList policyv1.PodDisruptionBudgetList
 	if err := kubeClient.List(ctx, &pdbList); err != nil {
@@ -52,18 +55,86 @@ func NewLimits(ctx context.Context, kubeClient client.Client) (Limits, error) {
 		if err != nil {
 			return nil, err
 		}
-		pdbs = append(pdbs, pi)
+		pdbs[pdb.Namespace] = append(pdbs[pdb.Namespace], pi)
 	}
 
 	return pdbs, nil
 }
 
+// Ignoring returns a copy of the Limits where pods that are only selected by ignored PDBs are evictable, regardless of
+// whether those PDBs allow disruptions
+func (l Limits) Ignoring(ignored func(*policyv1.PodDisruptionBudget) bool) Limits {
+	return lo.MapValues(l, func(pdbs []*pdbItem, _ string) []*pdbItem {
+		return lo.Map(pdbs, func(pdb *pdbItem, _ int) *pdbItem {
+			item := *pdb
+			item.ignored = ignored(pdb.pdb)
+			return &item
+		})
+	})
+}
+
+// Cache maintains the PDBs of the cluster incrementally as they're updated, so that Limits can be taken from it without
+// listing and parsing every PDB in the cluster.
+type Cache struct {
+	mu   sync.RWMutex
+	pdbs map[string]map[string]*pdbItem // namespace -> name -> pdb
+}
+
+func NewCache() *Cache {
+	return &Cache{pdbs: map[string]map[string]*pdbItem{}}
+}
+
+// Update tracks the PDB, replacing the version of it that was tracked previously
+func (c *Cache) Update(pdb *policyv1.PodDisruptionBudget) error {
+	pi, err := newPdb(*pdb)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pdbs[pdb.Namespace]; !ok {
+		c.pdbs[pdb.Namespace] = map[string]*pdbItem{}
+	}
+	c.pdbs[pdb.Namespace][pdb.Name] = pi
+	return nil
+}
+
+func (c *Cache) Delete(key client.ObjectKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pdbs[key.Namespace], key.Name)
+	if len(c.pdbs[key.Namespace]) == 0 {
+		delete(c.pdbs, key.Namespace)
+	}
+}
+
+// Has returns true if the PDB is tracked
+func (c *Cache) Has(key client.ObjectKey) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.pdbs[key.Namespace][key.Name]
+	return ok
+}
+
+// Limits returns the Limits of the tracked PDBs. Later updates to the Cache don't affect the returned Limits.
+func (c *Cache) Limits() Limits {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return lo.MapValues(c.pdbs, func(pdbs map[string]*pdbItem, _ string) []*pdbItem {
+		return lo.Values(pdbs)
+	})
+}
+
 // CanEvictPods returns true if every pod in the list is evictable. They may not all be evictable simultaneously, but
 // for every PDB that controls the pods at least one pod can be evicted.
 // nolint:gocyclo
-func (l Limits) CanEvictPods(pods []*v1.Pod) ([]client.ObjectKey, bool) {
+func (l Limits) CanEvictPods(pods []*v1.Pod, clk clock.Clock) ([]client.ObjectKey, bool) {
 	for _, pod := range pods {
-		pdbs, evictable := l.isEvictable(pod, zeroDisruptions)
+		pdbs, evictable := l.isEvictable(pod, zeroDisruptions, clk)
 
 		if !evictable {
 			return pdbs, false
@@ -73,8 +144,8 @@ func (l Limits) CanEvictPods(pods []*v1.Pod) ([]client.ObjectKey, bool) {
 }
 
 // isFullyBlocked returns true if the given pod is fully blocked by a PDB.
-func (l Limits) isFullyBlocked(pod *v1.Pod) ([]client.ObjectKey, bool) {
-	pdbs, evictable := l.isEvictable(pod, fullyBlockingPDBs)
+func (l Limits) isFullyBlocked(pod *v1.Pod, clk clock.Clock) ([]client.ObjectKey, bool) {
+	pdbs, evictable := l.isEvictable(pod, fullyBlockingPDBs, clk)
 
 	if !evictable {
 		return pdbs, true
@@ -83,16 +154,19 @@ func (l Limits) isFullyBlocked(pod *v1.Pod) ([]client.ObjectKey, bool) {
 }
 
 // nolint:gocyclo
-func (l Limits) isEvictable(pod *v1.Pod, evictionBlocker evictionBlocker) ([]client.ObjectKey, bool) {
+func (l Limits) isEvictable(pod *v1.Pod, evictionBlocker evictionBlocker, clk clock.Clock) ([]client.ObjectKey, bool) {
 	// If the pod isn't eligible for being evicted, then the predicate doesn't matter
 	// This is due to the fact that we won't call the eviction API on these pods when we are disrupting the node
-	if !podutil.IsEvictable(pod) {
+	if !podutil.IsEvictable(pod, clk) {
 		return []client.ObjectKey{}, true
 	}
 
-	matchingPDBs := lo.Filter(l, func(pdb *pdbItem, _ int) bool {
-		return pdb.key.Namespace == pod.Namespace && pdb.selector.Matches(labels.Set(pod.Labels))
+	matchingPDBs := lo.Filter(l[pod.Namespace], func(pdb *pdbItem, _ int) bool {
+		return pdb.selector.Matches(labels.Set(pod.Labels))
 	})
+	if len(matchingPDBs) > 0 && lo.EveryBy(matchingPDBs, func(pdb *pdbItem) bool { return pdb.ignored }) {
+		return []client.ObjectKey{}, true
+	}
 
 	// Regardless of whether the PDBs allow disruptions, Kubernetes doesn't support multiple PDBs on a single pod:
 	// https://github.com/kubernetes/kubernetes/blob/84cacae7046df93c1f6f8ea97c912d948e1ad06a/pkg/registry/core/pod/storage/eviction.go#L226
@@ -133,22 +207,24 @@ func (l Limits) isEvictable(pod *v1.Pod, evictionBlocker evictionBlocker) ([]cli
 // - Does not have fully blocking PDBs which would prevent the pod from being evicted
 // The way this is different from IsReschedulable is that this also considers non-permanent conditions which prevent a pod from being rescheduled
 // to a different node like the "do-not-disrupt" annotation or fully blocking PDBs.
-func (l Limits) IsCurrentlyReschedulable(pod *v1.Pod) bool {
+func (l Limits) IsCurrentlyReschedulable(pod *v1.Pod, clk clock.Clock) bool {
 	// Don't provision capacity for pods which will not get evicted due to fully blocking PDBs.
 	// Since Karpenter doesn't know when these pods will be successfully evicted, spinning up capacity until these pods are evicted is wasteful.
-	_, isFullyBlocked := l.isFullyBlocked(pod)
+	_, isFullyBlocked := l.isFullyBlocked(pod, clk)
 
 	return podutil.IsReschedulable(pod) &&
-		!podutil.HasDoNotDisrupt(pod) &&
+		!podutil.HasDoNotDisrupt(pod, clk) &&
 		!isFullyBlocked
 }
 
 type pdbItem struct {
+	pdb                         *policyv1.PodDisruptionBudget
 	key                         client.ObjectKey
 	selector                    labels.Selector
 	disruptionsAllowed          int32
 	isFullyBlocking             bool
 	canAlwaysEvictUnhealthyPods bool
+	ignored                     bool
 }
 
 // nolint:gocyclo
@@ -160,6 +236,7 @@ func newPdb(pdb policyv1.PodDisruptionBudget) (*pdbItem, error) {
 	canAlwaysEvictUnhealthyPods := pdb.Spec.UnhealthyPodEvictionPolicy != nil && *pdb.Spec.UnhealthyPodEvictionPolicy == policyv1.AlwaysAllow
 
 	return &pdbItem{
+		pdb:                &pdb,
 		key:                client.ObjectKeyFromObject(&pdb),
 		selector:           selector,
 		disruptionsAllowed: pdb.Status.DisruptionsAllowed,
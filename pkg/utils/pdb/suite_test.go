@@ -19,6 +19,7 @@ package pdb_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -34,6 +35,7 @@ import (
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	clock "k8s.io/utils/clock/testing"
 
 	karpenterv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 	"sigs.k8s.io/karpenter/pkg/test"
@@ -44,6 +46,7 @@ import (
 var (
 	ctx       context.Context
 	env       *test.Environment
+	fakeClock *clock.FakeClock
 	podLabels = map[string]string{"pdb-test": "value"}
 )
 
@@ -54,6 +57,7 @@ func Test(t *testing.T) {
 }
 
 var _ = BeforeSuite(func() {
+	fakeClock = clock.NewFakeClock(time.Now())
 	env = test.NewEnvironment(test.WithCRDs(apis.CRDs...), test.WithCRDs(v1alpha1.CRDs...), test.WithFieldIndexers(test.NodeClaimProviderIDFieldIndexer(ctx)))
 })
 
@@ -85,7 +89,7 @@ var _ = Describe("CanEvictPods", func() {
 		limits, err := pdb.NewLimits(ctx, env.Client)
 		Expect(err).NotTo(HaveOccurred())
 
-		violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod})
+		violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod}, fakeClock)
 		Expect(violatingPDBs).To(HaveLen(0))
 		Expect(canEvict).To(BeTrue())
 	})
@@ -104,7 +108,7 @@ var _ = Describe("CanEvictPods", func() {
 		limits, err := pdb.NewLimits(ctx, env.Client)
 		Expect(err).NotTo(HaveOccurred())
 
-		violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod})
+		violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod}, fakeClock)
 		Expect(violatingPDBs).To(HaveLen(1))
 		Expect(violatingPDBs).To(ContainElement(client.ObjectKeyFromObject(podDisruptionBudget)))
 		Expect(canEvict).To(BeFalse())
@@ -122,10 +126,52 @@ var _ = Describe("CanEvictPods", func() {
 		limits, err := pdb.NewLimits(ctx, env.Client)
 		Expect(err).NotTo(HaveOccurred())
 
-		violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod})
+		violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod}, fakeClock)
 		Expect(violatingPDBs).To(HaveLen(0))
 		Expect(canEvict).To(BeTrue())
 	})
+	It("can evict pods when every matching PDB is ignored", func() {
+		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
+			Labels:         podLabels,
+			MaxUnavailable: lo.ToPtr(intstr.FromInt32(0)),
+		})
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: podLabels,
+			}})
+		ExpectApplied(ctx, env.Client, podDisruptionBudget, pod)
+
+		limits, err := pdb.NewLimits(ctx, env.Client)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, canEvict := limits.CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(canEvict).To(BeFalse())
+		violatingPDBs, canEvict := limits.Ignoring(func(p *policyv1.PodDisruptionBudget) bool { return p.Name == podDisruptionBudget.Name }).CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(violatingPDBs).To(HaveLen(0))
+		Expect(canEvict).To(BeTrue())
+	})
+	It("can't evict pods when only some of the matching PDBs are ignored", func() {
+		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
+			Labels:         podLabels,
+			MaxUnavailable: lo.ToPtr(intstr.FromInt32(0)),
+		})
+		podDisruptionBudget2 := test.PodDisruptionBudget(test.PDBOptions{
+			Labels:         podLabels,
+			MaxUnavailable: lo.ToPtr(intstr.FromInt32(0)),
+		})
+		pod := test.Pod(test.PodOptions{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: podLabels,
+			}})
+		ExpectApplied(ctx, env.Client, podDisruptionBudget, podDisruptionBudget2, pod)
+
+		limits, err := pdb.NewLimits(ctx, env.Client)
+		Expect(err).NotTo(HaveOccurred())
+
+		violatingPDBs, canEvict := limits.Ignoring(func(p *policyv1.PodDisruptionBudget) bool { return p.Name == podDisruptionBudget.Name }).CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(violatingPDBs).To(HaveLen(2))
+		Expect(canEvict).To(BeFalse())
+	})
 	DescribeTable("can't evict pods when disruptions are not allowed for every pod in the list",
 		func(podDisruptionBudgets ...*policyv1.PodDisruptionBudget) {
 			pod1 := test.Pod(test.PodOptions{
@@ -144,7 +190,7 @@ var _ = Describe("CanEvictPods", func() {
 			limits, err := pdb.NewLimits(ctx, env.Client)
 			Expect(err).NotTo(HaveOccurred())
 
-			violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod1, pod2})
+			violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod1, pod2}, fakeClock)
 			Expect(violatingPDBs).To(HaveLen(len(podDisruptionBudgets)))
 			lo.ForEach(podDisruptionBudgets, func(pdb *policyv1.PodDisruptionBudget, _ int) {
 				Expect(violatingPDBs).To(ContainElement(client.ObjectKeyFromObject(pdb)))
@@ -190,7 +236,7 @@ var _ = Describe("CanEvictPods", func() {
 			limits, err := pdb.NewLimits(ctx, env.Client)
 			Expect(err).NotTo(HaveOccurred())
 
-			violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod1, pod2})
+			violatingPDBs, canEvict := limits.CanEvictPods([]*v1.Pod{pod1, pod2}, fakeClock)
 			Expect(violatingPDBs).To(HaveLen(len(podDisruptionBudgets)))
 			lo.ForEach(podDisruptionBudgets, func(pdb *policyv1.PodDisruptionBudget, _ int) {
 				Expect(violatingPDBs).To(ContainElement(client.ObjectKeyFromObject(pdb)))
@@ -244,7 +290,7 @@ var _ = Describe("IsCurrentlyReschedulable", func() {
 		limits, err := pdb.NewLimits(ctx, env.Client)
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(limits.IsCurrentlyReschedulable(pod)).To(BeTrue())
+		Expect(limits.IsCurrentlyReschedulable(pod, fakeClock)).To(BeTrue())
 	})
 	It("does not consider unhealthy pod as currently reschedulable when UnhealthyPodEvictionPolicy is not set", func() {
 		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
@@ -261,7 +307,7 @@ var _ = Describe("IsCurrentlyReschedulable", func() {
 		limits, err := pdb.NewLimits(ctx, env.Client)
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(limits.IsCurrentlyReschedulable(pod)).To(BeFalse())
+		Expect(limits.IsCurrentlyReschedulable(pod, fakeClock)).To(BeFalse())
 	})
 	It("considers pod as currently reschedulable when no PDBs match", func() {
 		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
@@ -276,7 +322,7 @@ var _ = Describe("IsCurrentlyReschedulable", func() {
 		limits, err := pdb.NewLimits(ctx, env.Client)
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(limits.IsCurrentlyReschedulable(pod)).To(BeTrue())
+		Expect(limits.IsCurrentlyReschedulable(pod, fakeClock)).To(BeTrue())
 	})
 	DescribeTable("pods which are not currently reschedulable due to PDBs",
 		func(podDisruptionBudgets ...*policyv1.PodDisruptionBudget) {
@@ -291,7 +337,7 @@ var _ = Describe("IsCurrentlyReschedulable", func() {
 			limits, err := pdb.NewLimits(ctx, env.Client)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(limits.IsCurrentlyReschedulable(pod)).To(BeFalse())
+			Expect(limits.IsCurrentlyReschedulable(pod, fakeClock)).To(BeFalse())
 		},
 		Entry("100% min available", test.PodDisruptionBudget(test.PDBOptions{
 			Labels:       podLabels,
@@ -330,6 +376,68 @@ var _ = Describe("IsCurrentlyReschedulable", func() {
 		limits, err := pdb.NewLimits(ctx, env.Client)
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(limits.IsCurrentlyReschedulable(pod)).To(BeFalse())
+		Expect(limits.IsCurrentlyReschedulable(pod, fakeClock)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Cache", func() {
+	var cache *pdb.Cache
+	BeforeEach(func() {
+		cache = pdb.NewCache()
+	})
+	It("should block evictions for tracked PDBs", func() {
+		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
+			Labels:         podLabels,
+			MaxUnavailable: lo.ToPtr(intstr.FromInt(0)),
+		})
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}})
+		Expect(cache.Update(podDisruptionBudget)).To(Succeed())
+		Expect(cache.Has(client.ObjectKeyFromObject(podDisruptionBudget))).To(BeTrue())
+
+		violatingPDBs, canEvict := cache.Limits().CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(violatingPDBs).To(ConsistOf(client.ObjectKeyFromObject(podDisruptionBudget)))
+		Expect(canEvict).To(BeFalse())
+	})
+	It("should replace the previous version of a PDB when it's updated", func() {
+		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
+			Labels: podLabels,
+			Status: &policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+		})
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}})
+		Expect(cache.Update(podDisruptionBudget)).To(Succeed())
+		limits := cache.Limits()
+
+		podDisruptionBudget.Status.DisruptionsAllowed = 1
+		Expect(cache.Update(podDisruptionBudget)).To(Succeed())
+		_, canEvict := cache.Limits().CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(canEvict).To(BeTrue())
+		// Limits taken before the update are unaffected by it
+		_, canEvict = limits.CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(canEvict).To(BeFalse())
+	})
+	It("should stop blocking evictions once a PDB is deleted", func() {
+		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
+			Labels:         podLabels,
+			MaxUnavailable: lo.ToPtr(intstr.FromInt(0)),
+		})
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}})
+		Expect(cache.Update(podDisruptionBudget)).To(Succeed())
+		cache.Delete(client.ObjectKeyFromObject(podDisruptionBudget))
+		Expect(cache.Has(client.ObjectKeyFromObject(podDisruptionBudget))).To(BeFalse())
+
+		_, canEvict := cache.Limits().CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(canEvict).To(BeTrue())
+	})
+	It("should only match PDBs against pods in their namespace", func() {
+		podDisruptionBudget := test.PodDisruptionBudget(test.PDBOptions{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "other"},
+			Labels:         podLabels,
+			MaxUnavailable: lo.ToPtr(intstr.FromInt(0)),
+		})
+		pod := test.Pod(test.PodOptions{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}})
+		Expect(cache.Update(podDisruptionBudget)).To(Succeed())
+
+		_, canEvict := cache.Limits().CanEvictPods([]*v1.Pod{pod}, fakeClock)
+		Expect(canEvict).To(BeTrue())
 	})
 })
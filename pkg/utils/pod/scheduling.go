@@ -45,7 +45,7 @@ func IsReschedulable(pod *corev1.Pod) bool {
 	// differently for higher availability by considering terminating pods for scheduling
 	return (IsActive(pod) || (IsOwnedByStatefulSet(pod) && IsTerminating(pod))) &&
 		!IsOwnedByDaemonSet(pod) &&
-		!IsOwnedByNode(pod)
+		!IsStatic(pod)
 }
 
 // IsEvictable checks if a pod is evictable by Karpenter by ensuring that the pod:
@@ -53,11 +53,11 @@ func IsReschedulable(pod *corev1.Pod) bool {
 // - Doesn't tolerate the "karpenter.sh/disruption=disrupting" taint
 // - Isn't a mirror pod (https://kubernetes.io/docs/tasks/configure-pod-container/static-pod/)
 // - Does not have the "karpenter.sh/do-not-disrupt=true" annotation (https://karpenter.sh/docs/concepts/disruption/#pod-level-controls)
-func IsEvictable(pod *corev1.Pod) bool {
+func IsEvictable(pod *corev1.Pod, clk clock.Clock) bool {
 	return IsActive(pod) &&
 		!ToleratesDisruptedNoScheduleTaint(pod) &&
-		!IsOwnedByNode(pod) &&
-		!HasDoNotDisrupt(pod)
+		!IsStatic(pod) &&
+		!HasDoNotDisrupt(pod, clk)
 }
 
 // IsWaitingEviction checks if this is a pod that we are waiting to be removed from the node by ensuring that the pod:
@@ -79,7 +79,7 @@ func IsDrainable(pod *corev1.Pod, clk clock.Clock) bool {
 		// Mirror pods cannot be deleted through the API server since they are created and managed by kubelet
 		// This means they are effectively read-only and can't be controlled by API server calls
 		// https://kubernetes.io/docs/reference/generated/kubectl/kubectl-commands#drain
-		!IsOwnedByNode(pod)
+		!IsStatic(pod)
 }
 
 // IsPodEligibleForForcedEviction checks if a pod needs to be deleted with a reduced grace period ensuring that the pod:
@@ -102,15 +102,15 @@ func IsProvisionable(pod *corev1.Pod) bool {
 		!IsScheduled(pod) &&
 		!IsPreempting(pod) &&
 		!IsOwnedByDaemonSet(pod) &&
-		!IsOwnedByNode(pod)
+		!IsStatic(pod)
 }
 
 // IsDisruptable checks if a pod can be disrupted based on validating the `karpenter.sh/do-not-disrupt` annotation on the pod.
 // It checks whether the following is true for the pod:
 // - Has the `karpenter.sh/do-not-disrupt` annotation
 // - Is an actively running pod
-func IsDisruptable(pod *corev1.Pod) bool {
-	return !IsActive(pod) || !HasDoNotDisrupt(pod)
+func IsDisruptable(pod *corev1.Pod, clk clock.Clock) bool {
+	return !IsActive(pod) || !HasDoNotDisrupt(pod, clk)
 }
 
 // FailedToSchedule ensures that the kube-scheduler has seen this pod and has intentionally
@@ -164,6 +164,17 @@ func IsOwnedByDaemonSet(pod *corev1.Pod) bool {
 	})
 }
 
+// IsStatic returns true if the pod is a static pod managed directly by the kubelet
+// (https://kubernetes.io/docs/tasks/configure-pod-container/static-pod/). The api-server only holds a read-only mirror of
+// these pods, so they can't be evicted or rescheduled and are terminated along with their node. Mirror pods are identified
+// by the mirror annotation set by the kubelet or by an owner reference to their node.
+func IsStatic(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return true
+	}
+	return IsOwnedByNode(pod)
+}
+
 // IsOwnedByNode returns true if the pod is a static pod owned by a specific node
 func IsOwnedByNode(pod *corev1.Pod) bool {
 	return IsOwnedBy(pod, []schema.GroupVersionKind{
@@ -182,11 +193,28 @@ func IsOwnedBy(pod *corev1.Pod, gvks []schema.GroupVersionKind) bool {
 	return false
 }
 
-func HasDoNotDisrupt(pod *corev1.Pod) bool {
+func HasDoNotDisrupt(pod *corev1.Pod, clk clock.Clock) bool {
 	if pod.Annotations == nil {
 		return false
 	}
-	return pod.Annotations[v1.DoNotDisruptAnnotationKey] == "true"
+	return IsDoNotDisrupt(pod.Annotations[v1.DoNotDisruptAnnotationKey], clk)
+}
+
+// NamespaceHasDoNotDisrupt returns true if the namespace protects all of its pods with the karpenter.sh/do-not-disrupt
+// annotation or label
+func NamespaceHasDoNotDisrupt(namespace *corev1.Namespace, clk clock.Clock) bool {
+	return IsDoNotDisrupt(namespace.Annotations[v1.DoNotDisruptAnnotationKey], clk) || IsDoNotDisrupt(namespace.Labels[v1.DoNotDisruptAnnotationKey], clk)
+}
+
+// IsDoNotDisrupt returns true if the value of a karpenter.sh/do-not-disrupt annotation or label blocks disruption. The
+// value is either "true", which blocks disruption until it's removed, or an RFC3339 timestamp until which disruption is
+// blocked, e.g. the expected completion time of a batch job. Other values don't block disruption.
+func IsDoNotDisrupt(value string, clk clock.Clock) bool {
+	if value == "true" {
+		return true
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	return err == nil && clk.Now().Before(until)
 }
 
 // ToleratesDisruptedNoScheduleTaint returns true if the pod tolerates karpenter.sh/disruption:NoSchedule taint
@@ -0,0 +1,99 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+)
+
+// CacheTTL is how long the read-only mode is cached for before the mode file is read again
+const CacheTTL = 10 * time.Second
+
+type cacheKey struct{}
+
+// Cache caches the read-only mode so that the mode file isn't read by every caller, e.g. on every cloud provider call
+type Cache struct {
+	clock clock.Clock
+
+	mu         sync.Mutex
+	path       string
+	enabled    bool
+	expiration time.Time
+}
+
+func NewCache(clk clock.Clock) *Cache {
+	return &Cache{clock: clk}
+}
+
+// ToContext returns a context whose read-only mode is served from the cache
+func ToContext(ctx context.Context, cache *Cache) context.Context {
+	return context.WithValue(ctx, cacheKey{}, cache)
+}
+
+// Enabled returns true if Karpenter is in read-only mode, where it doesn't create or delete capacity. The mode is read
+// from the --read-only-mode-file so that it can be switched at runtime, at most once per CacheTTL if the context
+// carries a Cache.
+func Enabled(ctx context.Context) bool {
+	path := options.FromContext(ctx).ReadOnlyModeFile
+	if path == "" {
+		return false
+	}
+	cache, ok := ctx.Value(cacheKey{}).(*Cache)
+	if !ok || cache == nil {
+		return read(ctx, path)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.path != path || !cache.clock.Now().Before(cache.expiration) {
+		cache.path = path
+		cache.enabled = read(ctx, path)
+		cache.expiration = cache.clock.Now().Add(CacheTTL)
+	}
+	return cache.enabled
+}
+
+// read returns the mode of the mode file. Since the file is only configured on clusters that may need to be switched
+// into read-only mode, a file that exists but can't be read or parsed fails closed into read-only mode.
+func read(ctx context.Context, path string) bool {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false
+		}
+		log.FromContext(ctx).Error(err, "failed reading read-only mode file, assuming read-only mode", "path", path)
+		return true
+	}
+	switch strings.TrimSpace(string(contents)) {
+	case "true":
+		return true
+	case "false", "":
+		return false
+	default:
+		log.FromContext(ctx).Error(fmt.Errorf("expected true or false, got %q", strings.TrimSpace(string(contents))), "failed parsing read-only mode file, assuming read-only mode", "path", path)
+		return true
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package resources
 
 import (
+	"fmt"
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	resourcehelper "k8s.io/component-helpers/resource"
@@ -169,3 +172,23 @@ func String(list v1.ResourceList) string {
 	}
 	return pretty.Concise(list)
 }
+
+// Parse returns the resource list described by a comma separated list of name=quantity pairs, e.g. "cpu=100m,memory=128Mi"
+func Parse(s string) (v1.ResourceList, error) {
+	list := v1.ResourceList{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=quantity, got %q", pair)
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("parsing quantity for %q, %w", name, err)
+		}
+		list[v1.ResourceName(strings.TrimSpace(name))] = quantity
+	}
+	return list, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+
+	"github.com/awslabs/operatorpkg/controller"
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/utils/env"
+)
+
+// The shard Leases are timed like the operator's Lease, which uses the controller-runtime defaults
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// Owns returns true if the key is owned by this replica's shard. Keys are assigned to shards by their hash, so replicas
+// agree on the assignment without coordinating with each other.
+func Owns(ctx context.Context, key string) bool {
+	shardCount := options.FromContext(ctx).ShardCount
+	if shardCount <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32()%uint32(shardCount)) == options.FromContext(ctx).ShardIndex //nolint:gosec
+}
+
+// LeaseName returns the name of the Lease that the replicas of this replica's shard compete for
+func LeaseName(ctx context.Context) string {
+	return fmt.Sprintf("%s-shard-%d", options.FromContext(ctx).LeaderElectionName, options.FromContext(ctx).ShardIndex)
+}
+
+// Elector elects the leader of this replica's shard. The operator elects a single leader that runs the controllers which
+// aren't sharded, while the sharded controllers are run by the leader of each shard so that every shard is evaluated.
+type Elector struct {
+	config  *rest.Config
+	elected chan struct{}
+}
+
+func NewElector(config *rest.Config) *Elector {
+	return &Elector{config: config, elected: make(chan struct{})}
+}
+
+func (e *Elector) Register(_ context.Context, m manager.Manager) error {
+	return m.Add(e)
+}
+
+// NeedLeaderElection is false since the shard is elected independently of the operator's leader
+func (e *Elector) NeedLeaderElection() bool {
+	return false
+}
+
+// Start competes for the shard's Lease until the context is cancelled. Losing the Lease returns an error so that the
+// manager exits, the same way it does when the operator's Lease is lost.
+func (e *Elector) Start(ctx context.Context) error {
+	id, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("getting hostname, %w", err)
+	}
+	lock, err := resourcelock.NewFromKubeconfig(resourcelock.LeasesResourceLock,
+		lo.CoalesceOrEmpty(options.FromContext(ctx).LeaderElectionNamespace, env.Namespace()), LeaseName(ctx),
+		resourcelock.ResourceLockConfig{Identity: id + "_" + string(uuid.NewUUID())}, e.config, renewDeadline)
+	if err != nil {
+		return fmt.Errorf("creating shard lock, %w", err)
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            LeaseName(ctx),
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.FromContext(ctx).WithValues("shard", options.FromContext(ctx).ShardIndex).Info("elected shard leader")
+				close(e.elected)
+			},
+			OnStoppedLeading: func() {},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating shard elector, %w", err)
+	}
+	elector.Run(ctx)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("lost the lease of shard %d", options.FromContext(ctx).ShardIndex)
+}
+
+// Sharded returns the controllers so that they're run by the leader of this replica's shard, rather than by the leader
+// of the operator
+func (e *Elector) Sharded(controllers ...controller.Controller) []controller.Controller {
+	return lo.Map(controllers, func(c controller.Controller, _ int) controller.Controller {
+		return wrapped{Controller: c, elected: e.elected}
+	})
+}
+
+// Unelected returns the controllers so that they're run by every replica, e.g. the controllers that populate the state
+// which the sharded controllers read
+func Unelected(controllers ...controller.Controller) []controller.Controller {
+	return lo.Map(controllers, func(c controller.Controller, _ int) controller.Controller {
+		return wrapped{Controller: c}
+	})
+}
+
+// wrapped registers a controller with a manager that starts its runnables on every replica, once the replica holds the
+// shard's Lease if elected is set
+type wrapped struct {
+	controller.Controller
+	elected <-chan struct{}
+}
+
+func (w wrapped) Register(ctx context.Context, m manager.Manager) error {
+	return w.Controller.Register(ctx, unelectedManager{Manager: m, elected: w.elected})
+}
+
+type unelectedManager struct {
+	manager.Manager
+	elected <-chan struct{}
+}
+
+func (m unelectedManager) Add(r manager.Runnable) error {
+	return m.Manager.Add(unelectedRunnable{Runnable: r, elected: m.elected})
+}
+
+type unelectedRunnable struct {
+	manager.Runnable
+	elected <-chan struct{}
+}
+
+func (r unelectedRunnable) NeedLeaderElection() bool {
+	return false
+}
+
+func (r unelectedRunnable) Start(ctx context.Context) error {
+	if r.elected != nil {
+		select {
+		case <-r.elected:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return r.Runnable.Start(ctx)
+}
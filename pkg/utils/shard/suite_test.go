@@ -0,0 +1,117 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/samber/lo"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"sigs.k8s.io/karpenter/pkg/operator/options"
+	"sigs.k8s.io/karpenter/pkg/test"
+	"sigs.k8s.io/karpenter/pkg/utils/shard"
+	. "sigs.k8s.io/karpenter/pkg/utils/testing"
+)
+
+var ctx context.Context
+
+func TestShard(t *testing.T) {
+	ctx = TestContextWithLogger(t)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Shard")
+}
+
+var _ = BeforeEach(func() {
+	ctx = options.ToContext(ctx, test.Options())
+})
+
+// fakeManager records the runnables that are added to it
+type fakeManager struct {
+	manager.Manager
+	runnables []manager.Runnable
+}
+
+func (m *fakeManager) Add(r manager.Runnable) error {
+	m.runnables = append(m.runnables, r)
+	return nil
+}
+
+// fakeController adds a runnable that records whether it was started
+type fakeController struct {
+	started bool
+}
+
+func (c *fakeController) Register(_ context.Context, m manager.Manager) error {
+	return m.Add(manager.RunnableFunc(func(context.Context) error {
+		c.started = true
+		return nil
+	}))
+}
+
+var _ = Describe("Shard", func() {
+	Context("Owns", func() {
+		It("should assign every key to exactly one shard", func() {
+			for _, key := range lo.Times(20, func(_ int) string { return test.RandomName() }) {
+				shards := lo.Filter(lo.Range(3), func(index int, _ int) bool {
+					return shard.Owns(options.ToContext(ctx, test.Options(test.OptionsFields{ShardCount: lo.ToPtr(3), ShardIndex: lo.ToPtr(index)})), key)
+				})
+				Expect(shards).To(HaveLen(1))
+			}
+		})
+		It("should assign every key to the only shard when the work isn't sharded", func() {
+			Expect(shard.Owns(ctx, test.RandomName())).To(BeTrue())
+		})
+	})
+	Context("LeaseName", func() {
+		It("should name the lease after the leader election lease and the shard", func() {
+			ctx = options.ToContext(ctx, test.Options(test.OptionsFields{LeaderElectionName: lo.ToPtr("karpenter-leader-election"), ShardCount: lo.ToPtr(3), ShardIndex: lo.ToPtr(2)}))
+			Expect(shard.LeaseName(ctx)).To(Equal("karpenter-leader-election-shard-2"))
+		})
+	})
+	Context("Unelected", func() {
+		It("should start the controllers on every replica", func() {
+			m, c := &fakeManager{}, &fakeController{}
+			for _, wrapped := range shard.Unelected(c) {
+				Expect(wrapped.Register(ctx, m)).To(Succeed())
+			}
+			Expect(m.runnables).To(HaveLen(1))
+			Expect(m.runnables[0].(manager.LeaderElectionRunnable).NeedLeaderElection()).To(BeFalse())
+			Expect(m.runnables[0].Start(ctx)).To(Succeed())
+			Expect(c.started).To(BeTrue())
+		})
+	})
+	Context("Sharded", func() {
+		It("should not start the controllers until the shard's leader is elected", func() {
+			m, c := &fakeManager{}, &fakeController{}
+			for _, wrapped := range shard.NewElector(&rest.Config{}).Sharded(c) {
+				Expect(wrapped.Register(ctx, m)).To(Succeed())
+			}
+			Expect(m.runnables).To(HaveLen(1))
+			Expect(m.runnables[0].(manager.LeaderElectionRunnable).NeedLeaderElection()).To(BeFalse())
+
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			Expect(m.runnables[0].Start(cancelled)).To(Succeed())
+			Expect(c.started).To(BeFalse())
+		})
+	})
+})